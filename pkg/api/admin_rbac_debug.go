@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AdminGetRBACDebugSession returns the access control decisions captured for a request sent with the
+// X-Grafana-RBAC-Debug header, until the session expires.
+func (hs *HTTPServer) AdminGetRBACDebugSession(c *contextmodel.ReqContext) response.Response {
+	session, ok := hs.rbacDebugSessions.Get(web.Params(c.Req)[":sessionId"])
+	if !ok {
+		return response.Error(http.StatusNotFound, "Debug session not found or expired", nil)
+	}
+	return response.JSON(http.StatusOK, session)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -66,6 +67,7 @@ func (hs *HTTPServer) AdminCreateUser(c *contextmodel.ReqContext) response.Respo
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create user", err)
 	}
 
+	hs.syncOrgRole(c.Req.Context(), usr.OrgID, usr.ID)
 	metrics.MApiAdminUserCreate.Inc()
 
 	result := user.AdminCreateUserResponse{
@@ -176,6 +178,12 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 		return response.Error(http.StatusInternalServerError, "Failed to update user permissions", err)
 	}
 
+	if syncer, ok := hs.accesscontrolService.(accesscontrol.OrgRoleSyncer); ok {
+		if err := syncer.GrafanaAdminChanged(c.Req.Context(), userID); err != nil {
+			hs.log.Error("Failed to sync Grafana Admin role assignment", "userId", userID, "error", err)
+		}
+	}
+
 	return response.Success("User permissions updated")
 }
 
@@ -202,6 +210,12 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 
 	cmd := user.DeleteUserCommand{UserID: userID}
 
+	// The user uid is needed to remove the tuples of the user once it is deleted
+	var userUID string
+	if usr, err := hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID}); err == nil && usr != nil {
+		userUID = usr.UID
+	}
+
 	if err := hs.userService.Delete(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
@@ -253,10 +267,7 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 		return nil
 	})
 	g.Go(func() error {
-		if err := hs.accesscontrolService.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, cmd.UserID); err != nil {
-			return err
-		}
-		return nil
+		return hs.deprovisionUser(ctx, cmd.UserID, userUID)
 	})
 	if err := g.Wait(); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete user", err)
@@ -265,6 +276,15 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 	return response.Success("User deleted")
 }
 
+// deprovisionUser removes the managed roles, role assignments and permissions of a deleted user in every org,
+// together with its tuples when the access control service supports it.
+func (hs *HTTPServer) deprovisionUser(ctx context.Context, userID int64, userUID string) error {
+	if deprovisioner, ok := hs.accesscontrolService.(accesscontrol.Deprovisioner); ok {
+		return deprovisioner.DeprovisionUser(ctx, userID, userUID)
+	}
+	return hs.accesscontrolService.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, userID)
+}
+
 // swagger:route POST /admin/users/{user_id}/disable admin_users adminDisableUser
 //
 // Disable user.
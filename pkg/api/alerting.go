@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func (hs *HTTPServer) GetAlertNotifiers() func(*contextmodel.ReqContext) response.Response {
@@ -13,3 +14,21 @@ func (hs *HTTPServer) GetAlertNotifiers() func(*contextmodel.ReqContext) respons
 		return response.JSON(http.StatusOK, channels_config.GetAvailableNotifiers())
 	}
 }
+
+// GetAlertNotifierSchemas returns the JSON schemas of the settings of all the integration types.
+func (hs *HTTPServer) GetAlertNotifierSchemas() func(*contextmodel.ReqContext) response.Response {
+	return func(_ *contextmodel.ReqContext) response.Response {
+		return response.JSON(http.StatusOK, channels_config.GetIntegrationSchemas())
+	}
+}
+
+// GetAlertNotifierSchema returns the JSON schema of the settings of an integration type.
+func (hs *HTTPServer) GetAlertNotifierSchema() func(*contextmodel.ReqContext) response.Response {
+	return func(c *contextmodel.ReqContext) response.Response {
+		schema, err := channels_config.GetIntegrationSchema(web.Params(c.Req)[":type"])
+		if err != nil {
+			return response.Error(http.StatusNotFound, err.Error(), nil)
+		}
+		return response.JSON(http.StatusOK, schema)
+	}
+}
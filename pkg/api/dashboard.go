@@ -584,11 +584,12 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	dashItem := &dashboards.SaveDashboardDTO{
-		Dashboard: dash,
-		Message:   cmd.Message,
-		OrgID:     c.SignedInUser.GetOrgID(),
-		User:      c.SignedInUser,
-		Overwrite: cmd.Overwrite,
+		Dashboard:                 dash,
+		Message:                   cmd.Message,
+		OrgID:                     c.SignedInUser.GetOrgID(),
+		User:                      c.SignedInUser,
+		Overwrite:                 cmd.Overwrite,
+		PruneRedundantPermissions: cmd.PruneRedundantPermissions,
 	}
 
 	dashboard, saveErr := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
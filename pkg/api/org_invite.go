@@ -161,6 +161,7 @@ func (hs *HTTPServer) inviteExistingUserToOrg(c *contextmodel.ReqContext, user *
 		}
 		return response.Error(http.StatusInternalServerError, "Error while trying to create org user", err)
 	}
+	hs.syncOrgRole(c.Req.Context(), createOrgUserCmd.OrgID, user.ID)
 
 	if inviteDto.SendEmail && util.IsEmail(user.Email) {
 		emailCmd := notifications.SendEmailCommand{
@@ -323,6 +324,8 @@ func (hs *HTTPServer) applyUserInvite(ctx context.Context, usr *user.User, invit
 		if !errors.Is(err, org.ErrOrgUserAlreadyAdded) {
 			return false, response.Error(http.StatusInternalServerError, "Error while trying to create org user", err)
 		}
+	} else {
+		hs.syncOrgRole(ctx, invite.OrgID, usr.ID)
 	}
 
 	// update temp user status
@@ -96,6 +96,8 @@ func (hs *HTTPServer) addOrgUserHelper(c *contextmodel.ReqContext, cmd org.AddOr
 		return response.Error(http.StatusInternalServerError, "Could not add user to organization", err)
 	}
 
+	hs.syncOrgRole(c.Req.Context(), cmd.OrgID, cmd.UserID)
+
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "User added to organization",
 		"userId":  cmd.UserID,
@@ -440,6 +442,8 @@ func (hs *HTTPServer) updateOrgUserHelper(c *contextmodel.ReqContext, cmd org.Up
 		return response.Error(http.StatusInternalServerError, "Failed update org user", err)
 	}
 
+	hs.syncOrgRole(c.Req.Context(), cmd.OrgID, cmd.UserID)
+
 	hs.accesscontrolService.ClearUserPermissionCache(&user.SignedInUser{
 		UserID: cmd.UserID,
 		OrgID:  cmd.OrgID,
@@ -503,6 +507,12 @@ func (hs *HTTPServer) RemoveOrgUser(c *contextmodel.ReqContext) response.Respons
 }
 
 func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOrgUserCommand) response.Response {
+	// The user uid is needed to remove the tuples of the user if it is deleted with its last membership
+	var userUID string
+	if usr, err := hs.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: cmd.UserID}); err == nil && usr != nil {
+		userUID = usr.UID
+	}
+
 	if err := hs.orgService.RemoveOrgUser(ctx, cmd); err != nil {
 		if errors.Is(err, org.ErrLastOrgAdmin) {
 			return response.Error(http.StatusBadRequest, "Cannot remove last organization admin", nil)
@@ -512,7 +522,7 @@ func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOr
 
 	if cmd.UserWasDeleted {
 		// This should be called from appropriate service when moved
-		if err := hs.accesscontrolService.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, cmd.UserID); err != nil {
+		if err := hs.deprovisionUser(ctx, cmd.UserID, userUID); err != nil {
 			hs.log.Warn("failed to delete permissions for user", "userID", cmd.UserID, "orgID", accesscontrol.GlobalOrgID, "err", err)
 		}
 		return response.Success("User deleted")
@@ -523,9 +533,23 @@ func (hs *HTTPServer) removeOrgUserHelper(ctx context.Context, cmd *org.RemoveOr
 		hs.log.Warn("failed to delete permissions for user", "userID", cmd.UserID, "orgID", cmd.OrgID, "err", err)
 	}
 
+	hs.syncOrgRole(ctx, cmd.OrgID, cmd.UserID)
+
 	return response.Success("User removed from organization")
 }
 
+// syncOrgRole syncs the zanzana basic role assignments of the user in the org once its membership was added, updated
+// or removed. Failures are logged, the basic role assignments are reconciled in the background.
+func (hs *HTTPServer) syncOrgRole(ctx context.Context, orgID, userID int64) {
+	syncer, ok := hs.accesscontrolService.(accesscontrol.OrgRoleSyncer)
+	if !ok {
+		return
+	}
+	if err := syncer.OrgRoleChanged(ctx, orgID, userID); err != nil {
+		hs.log.Error("Failed to sync basic role assignment", "userId", userID, "orgId", orgID, "error", err)
+	}
+}
+
 // swagger:parameters addOrgUserToCurrentOrg
 type AddOrgUserToCurrentOrgParams struct {
 	// in:body
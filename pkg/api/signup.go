@@ -112,6 +112,7 @@ func (hs *HTTPServer) SignUpStep2(c *contextmodel.ReqContext) response.Response
 
 		return response.Error(http.StatusInternalServerError, "Failed to create user", err)
 	}
+	hs.syncOrgRole(c.Req.Context(), usr.OrgID, usr.ID)
 
 	// publish signup event
 	if err := hs.bus.Publish(c.Req.Context(), &events.SignUpCompleted{
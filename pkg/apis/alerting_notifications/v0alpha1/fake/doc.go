@@ -0,0 +1,3 @@
+// Package fake provides generators of the resources of the notifications API group for tests. The generators are
+// not part of the API package, so that production binaries that import the API types do not carry them.
+package fake
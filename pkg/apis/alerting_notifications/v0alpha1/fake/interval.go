@@ -0,0 +1,219 @@
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type IntervalMutator func(spec *v0alpha1.Interval)
+
+type IntervalGenerator struct {
+	mutators []IntervalMutator
+}
+
+func (t IntervalGenerator) With(mutators ...IntervalMutator) IntervalGenerator {
+	return IntervalGenerator{
+		mutators: append(t.mutators, mutators...),
+	}
+}
+
+func (t IntervalGenerator) generateDaysOfMonth() string {
+	isRange := rand.Int()%2 == 0
+	if !isRange {
+		return fmt.Sprintf("%d", rand.Intn(30)+1)
+	}
+	from := rand.Intn(15) + 1
+	to := rand.Intn(31-from) + from + 1
+	return fmt.Sprintf("%d:%d", from, to)
+}
+
+func (t IntervalGenerator) generateTimeRange() v0alpha1.TimeRange {
+	from := rand.Int63n(1440 / 2)        // [0, 719]
+	to := from + rand.Int63n(1440/2) + 1 // from < ([0,719] + [1,720]) < 1440
+	return v0alpha1.TimeRange{
+		StartTime: time.Unix(from*60, 0).UTC().Format("15:04"),
+		EndTime:   time.Unix(to*60, 0).UTC().Format("15:04"),
+	}
+}
+
+func (t IntervalGenerator) generateWeekday() string {
+	day := rand.Intn(7)
+	return strings.ToLower(time.Weekday(day).String())
+}
+
+func (t IntervalGenerator) generateYear() string {
+	from := 1970 + rand.Intn(100)
+	if rand.Int()%3 == 0 {
+		to := 1970 + from + rand.Intn(10) + 1
+		return fmt.Sprintf("%d:%d", from, to)
+	}
+	return fmt.Sprintf("%d", from)
+}
+
+func (t IntervalGenerator) generateLocation() *string {
+	if rand.Int()%3 == 0 {
+		return nil
+	}
+	return util.Pointer("UTC")
+}
+
+func (t IntervalGenerator) generateMonth() string {
+	return fmt.Sprintf("%d", rand.Intn(12)+1)
+}
+
+func (t IntervalGenerator) GenerateMany(count int) []v0alpha1.Interval {
+	result := make([]v0alpha1.Interval, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, t.Generate())
+	}
+	return result
+}
+
+func (t IntervalGenerator) Generate() v0alpha1.Interval {
+	i := v0alpha1.Interval{
+		DaysOfMonth: generateMany(rand.Intn(6), true, t.generateDaysOfMonth),
+		Location:    t.generateLocation(),
+		Months:      generateMany(rand.Intn(3), true, t.generateMonth),
+		Times:       generateMany(rand.Intn(6), true, t.generateTimeRange),
+		Weekdays:    generateMany(rand.Intn(3), true, t.generateWeekday),
+		Years:       generateMany(rand.Intn(3), true, t.generateYear),
+	}
+	for _, mutator := range t.mutators {
+		mutator(&i)
+	}
+	return i
+}
+
+func generateMany[T comparable](repeatTimes int, unique bool, f func() T) []T {
+	qty := repeatTimes + 1
+	result := make([]T, 0, qty)
+	for i := 0; i < qty; i++ {
+		r := f()
+		if unique && slices.Contains(result, r) {
+			continue
+		}
+		result = append(result, f())
+	}
+	return result
+}
+
+func CopyWith(in v0alpha1.Interval, mutators ...IntervalMutator) v0alpha1.Interval {
+	r := *in.DeepCopy()
+	for _, mut := range mutators {
+		mut(&r)
+	}
+	return r
+}
+
+// IntervalCase is an interval of the corpus with a stable name and a description of the edge case it covers.
+type IntervalCase struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Interval    v0alpha1.Interval `json:"interval"`
+}
+
+// Corpus returns a deterministic corpus of edge-case intervals for golden-file tests, unlike Generate which returns
+// random intervals. The values are in the canonical form of Alertmanager, e.g. names of months and weekdays, so that
+// conversions keep them as is. The mutators of the generator are applied to every interval. New cases are appended
+// to keep the golden files of existing cases stable.
+func (t IntervalGenerator) Corpus() []IntervalCase {
+	cases := []IntervalCase{
+		{
+			Name:        "empty",
+			Description: "Interval without any range, it matches any time",
+			Interval:    v0alpha1.Interval{},
+		},
+		{
+			Name:        "max-ranges",
+			Description: "Widest ranges of every field",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"1:31"},
+				Location:    util.Pointer("UTC"),
+				Months:      []string{"january:december"},
+				Times:       []v0alpha1.TimeRange{{StartTime: "00:00", EndTime: "24:00"}},
+				Weekdays:    []string{"sunday:saturday"},
+				Years:       []string{"1:9999"},
+			},
+		},
+		{
+			Name:        "negative-days-of-month",
+			Description: "Days of month counted from the end of the month",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"-1", "-7:-2"},
+			},
+		},
+		{
+			Name:        "midnight-boundaries",
+			Description: "Times ranges touching the start and the end of the day",
+			Interval: v0alpha1.Interval{
+				Times: []v0alpha1.TimeRange{
+					{StartTime: "00:00", EndTime: "00:01"},
+					{StartTime: "23:59", EndTime: "24:00"},
+				},
+			},
+		},
+		{
+			Name:        "dst-spring-forward",
+			Description: "Hour skipped when daylight saving time starts in New York, second Sunday of March",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"8:14"},
+				Location:    util.Pointer("America/New_York"),
+				Months:      []string{"march"},
+				Times:       []v0alpha1.TimeRange{{StartTime: "02:00", EndTime: "03:00"}},
+				Weekdays:    []string{"sunday"},
+			},
+		},
+		{
+			Name:        "dst-fall-back",
+			Description: "Hour repeated when daylight saving time ends in New York, first Sunday of November",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"1:7"},
+				Location:    util.Pointer("America/New_York"),
+				Months:      []string{"november"},
+				Times:       []v0alpha1.TimeRange{{StartTime: "01:00", EndTime: "02:00"}},
+				Weekdays:    []string{"sunday"},
+			},
+		},
+		{
+			Name:        "half-hour-dst",
+			Description: "Location whose daylight saving time shifts by 30 minutes",
+			Interval: v0alpha1.Interval{
+				Location: util.Pointer("Australia/Lord_Howe"),
+				Months:   []string{"april"},
+				Times:    []v0alpha1.TimeRange{{StartTime: "01:30", EndTime: "02:30"}},
+			},
+		},
+		{
+			Name:        "leap-day",
+			Description: "February 29th of a leap year",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"29"},
+				Months:      []string{"february"},
+				Years:       []string{"2024"},
+			},
+		},
+		{
+			Name:        "multiple-ranges",
+			Description: "Several ranges of every field",
+			Interval: v0alpha1.Interval{
+				DaysOfMonth: []string{"1:5", "10", "-3:-1"},
+				Months:      []string{"january:march", "october"},
+				Times:       []v0alpha1.TimeRange{{StartTime: "08:00", EndTime: "12:00"}, {StartTime: "13:00", EndTime: "17:30"}},
+				Weekdays:    []string{"monday:friday", "sunday"},
+				Years:       []string{"2020:2022", "2030"},
+			},
+		},
+	}
+	for i := range cases {
+		for _, mutator := range t.mutators {
+			mutator(&cases[i].Interval)
+		}
+	}
+	return cases
+}
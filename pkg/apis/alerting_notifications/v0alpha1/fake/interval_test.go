@@ -0,0 +1,31 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+)
+
+func TestIntervalGenerator_Corpus(t *testing.T) {
+	corpus := IntervalGenerator{}.Corpus()
+
+	names := make(map[string]struct{}, len(corpus))
+	for _, c := range corpus {
+		require.NotEmpty(t, c.Description, c.Name)
+		require.NotContains(t, names, c.Name, "names of the corpus must be unique")
+		names[c.Name] = struct{}{}
+	}
+
+	require.Equal(t, corpus, IntervalGenerator{}.Corpus(), "the corpus must be deterministic")
+
+	t.Run("mutators are applied", func(t *testing.T) {
+		corpus := IntervalGenerator{}.With(func(spec *v0alpha1.Interval) {
+			spec.Location = nil
+		}).Corpus()
+		for _, c := range corpus {
+			require.Nil(t, c.Interval.Location, c.Name)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type TimeIntervalMutator func(interval *v0alpha1.TimeInterval)
+
+// TimeIntervalGenerator generates time interval resources in the default namespace with random intervals.
+type TimeIntervalGenerator struct {
+	Intervals IntervalGenerator
+	mutators  []TimeIntervalMutator
+}
+
+func (t TimeIntervalGenerator) With(mutators ...TimeIntervalMutator) TimeIntervalGenerator {
+	return TimeIntervalGenerator{
+		Intervals: t.Intervals,
+		mutators:  append(t.mutators, mutators...),
+	}
+}
+
+func (t TimeIntervalGenerator) Generate() v0alpha1.TimeInterval {
+	i := v0alpha1.TimeInterval{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+		Spec: v0alpha1.TimeIntervalSpec{
+			Name:          fmt.Sprintf("time-interval-%s", util.GenerateShortUID()),
+			TimeIntervals: t.Intervals.GenerateMany(rand.Intn(3) + 1),
+		},
+	}
+	for _, mutator := range t.mutators {
+		mutator(&i)
+	}
+	return i
+}
+
+func (t TimeIntervalGenerator) GenerateMany(count int) []v0alpha1.TimeInterval {
+	result := make([]v0alpha1.TimeInterval, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, t.Generate())
+	}
+	return result
+}
+
+type ReceiverMutator func(receiver *v0alpha1.Receiver)
+
+// ReceiverGenerator generates receiver resources in the default namespace with integrations of types that need no
+// secrets, so that they are valid without secure settings.
+type ReceiverGenerator struct {
+	mutators []ReceiverMutator
+}
+
+func (t ReceiverGenerator) With(mutators ...ReceiverMutator) ReceiverGenerator {
+	return ReceiverGenerator{
+		mutators: append(t.mutators, mutators...),
+	}
+}
+
+func (t ReceiverGenerator) generateIntegration() v0alpha1.Integration {
+	switch rand.Intn(3) {
+	case 0:
+		return v0alpha1.Integration{
+			Type: "email",
+			Settings: common.Unstructured{Object: map[string]any{
+				"addresses": fmt.Sprintf("%s@example.com", util.GenerateShortUID()),
+			}},
+		}
+	case 1:
+		return v0alpha1.Integration{
+			Type: "webhook",
+			Settings: common.Unstructured{Object: map[string]any{
+				"url": fmt.Sprintf("http://localhost/%s", util.GenerateShortUID()),
+			}},
+			DisableResolveMessage: util.Pointer(rand.Int()%2 == 0),
+		}
+	default:
+		return v0alpha1.Integration{
+			Type: "teams",
+			Settings: common.Unstructured{Object: map[string]any{
+				"url": fmt.Sprintf("http://localhost/%s", util.GenerateShortUID()),
+			}},
+		}
+	}
+}
+
+func (t ReceiverGenerator) Generate() v0alpha1.Receiver {
+	integrations := make([]v0alpha1.Integration, 0, 3)
+	for i := 0; i < rand.Intn(3)+1; i++ {
+		integrations = append(integrations, t.generateIntegration())
+	}
+	r := v0alpha1.Receiver{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+		Spec: v0alpha1.ReceiverSpec{
+			Title:        fmt.Sprintf("receiver-%s", util.GenerateShortUID()),
+			Integrations: integrations,
+		},
+	}
+	for _, mutator := range t.mutators {
+		mutator(&r)
+	}
+	return r
+}
+
+func (t ReceiverGenerator) GenerateMany(count int) []v0alpha1.Receiver {
+	result := make([]v0alpha1.Receiver, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, t.Generate())
+	}
+	return result
+}
+
+type TemplateGroupMutator func(template *v0alpha1.TemplateGroup)
+
+// TemplateGroupGenerator generates template group resources in the default namespace. Every group defines a template
+// with the title of the group.
+type TemplateGroupGenerator struct {
+	mutators []TemplateGroupMutator
+}
+
+func (t TemplateGroupGenerator) With(mutators ...TemplateGroupMutator) TemplateGroupGenerator {
+	return TemplateGroupGenerator{
+		mutators: append(t.mutators, mutators...),
+	}
+}
+
+func (t TemplateGroupGenerator) Generate() v0alpha1.TemplateGroup {
+	title := fmt.Sprintf("template-%s", util.GenerateShortUID())
+	g := v0alpha1.TemplateGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+		Spec: v0alpha1.TemplateGroupSpec{
+			Title:   title,
+			Content: fmt.Sprintf(`{{ define "%s" }}%s{{ end }}`, title, util.GenerateShortUID()),
+		},
+	}
+	for _, mutator := range t.mutators {
+		mutator(&g)
+	}
+	return g
+}
+
+func (t TemplateGroupGenerator) GenerateMany(count int) []v0alpha1.TemplateGroup {
+	result := make([]v0alpha1.TemplateGroup, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, t.Generate())
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+)
+
+func TestResourceGenerators(t *testing.T) {
+	t.Run("time intervals", func(t *testing.T) {
+		intervals := TimeIntervalGenerator{}.With(func(interval *v0alpha1.TimeInterval) {
+			interval.Spec.Name = "test-" + interval.Spec.Name
+		}).GenerateMany(5)
+		require.Len(t, intervals, 5)
+		for _, i := range intervals {
+			require.Contains(t, i.Spec.Name, "test-time-interval-")
+			require.NotEmpty(t, i.Spec.TimeIntervals)
+		}
+	})
+
+	t.Run("receivers", func(t *testing.T) {
+		for _, r := range (ReceiverGenerator{}).GenerateMany(5) {
+			require.NotEmpty(t, r.Spec.Title)
+			require.NotEmpty(t, r.Spec.Integrations)
+			for _, i := range r.Spec.Integrations {
+				require.NotEmpty(t, i.Type)
+				require.NotEmpty(t, i.Settings.Object)
+			}
+		}
+	})
+
+	t.Run("template groups", func(t *testing.T) {
+		for _, g := range (TemplateGroupGenerator{}).GenerateMany(5) {
+			require.Contains(t, g.Spec.Content, `{{ define "`+g.Spec.Title+`" }}`)
+		}
+	})
+}
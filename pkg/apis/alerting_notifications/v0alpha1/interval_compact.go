@@ -0,0 +1,226 @@
+package v0alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The compact form of an interval is a single line, similar to the calendar events of systemd, with up to four
+// space-separated components in any order:
+//
+//	[WEEKDAYS] [YEARS-MONTHS-DAYS] [TIMES] [LOCATION]
+//
+// For example "monday..friday *-*-1..5 09:00..17:00 Europe/Paris". Lists are separated by commas and ranges by "..",
+// "*" matches any value. Days counted from the end of the month are prefixed with "~", e.g. ~1 is the last day of
+// the month. The compact form of an interval without any range is "*".
+
+var (
+	weekdayNames = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	monthNames   = []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"}
+
+	compactTimeRegex = regexp.MustCompile(`^\d{1,2}:\d{2}\.\.\d{1,2}:\d{2}$`)
+	compactDayRegex  = regexp.MustCompile(`^~?\d{1,2}$`)
+	compactYearRegex = regexp.MustCompile(`^\d{1,4}$`)
+)
+
+// ParseCompactInterval parses the compact form of an interval. Names of weekdays and months can be abbreviated to
+// three letters, they are returned in full.
+func ParseCompactInterval(s string) (Interval, error) {
+	var result Interval
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return result, fmt.Errorf("empty interval, use * to match any time")
+	}
+	if len(fields) == 1 && fields[0] == "*" {
+		return result, nil
+	}
+	seen := map[string]bool{}
+	once := func(component, field string) error {
+		if seen[component] {
+			return fmt.Errorf("%s are defined twice, at %q", component, field)
+		}
+		seen[component] = true
+		return nil
+	}
+	for _, field := range fields {
+		var err error
+		switch {
+		case strings.Contains(field, "/"):
+			if err = once("location", field); err == nil {
+				result.Location, err = parseCompactLocation(field)
+			}
+		case strings.Contains(field, ":"):
+			if err = once("times", field); err == nil {
+				result.Times, err = parseCompactTimes(field)
+			}
+		case strings.Count(field, "-") == 2:
+			if err = once("dates", field); err == nil {
+				result.Years, result.Months, result.DaysOfMonth, err = parseCompactDate(field)
+			}
+		case isCompactWeekdays(field):
+			if err = once("weekdays", field); err == nil {
+				result.Weekdays, err = parseCompactList(field, parseWeekday)
+			}
+		default:
+			if err = once("location", field); err == nil {
+				result.Location, err = parseCompactLocation(field)
+			}
+		}
+		if err != nil {
+			return Interval{}, err
+		}
+	}
+	return result, nil
+}
+
+// FormatCompactInterval returns the compact form of the interval. The values are not validated, ranges of the interval
+// are expected to be in the form of Alertmanager, e.g. "1:5" or "monday:friday".
+func FormatCompactInterval(i Interval) string {
+	var parts []string
+	if len(i.Weekdays) > 0 {
+		parts = append(parts, formatCompactList(i.Weekdays, nil))
+	}
+	if len(i.Years) > 0 || len(i.Months) > 0 || len(i.DaysOfMonth) > 0 {
+		parts = append(parts, strings.Join([]string{
+			formatCompactList(i.Years, nil),
+			formatCompactList(i.Months, nil),
+			formatCompactList(i.DaysOfMonth, func(day string) string {
+				if strings.HasPrefix(day, "-") {
+					return "~" + day[1:]
+				}
+				return day
+			}),
+		}, "-"))
+	}
+	if len(i.Times) > 0 {
+		times := make([]string, 0, len(i.Times))
+		for _, t := range i.Times {
+			times = append(times, t.StartTime+".."+t.EndTime)
+		}
+		parts = append(parts, strings.Join(times, ","))
+	}
+	if i.Location != nil && *i.Location != "" {
+		parts = append(parts, *i.Location)
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatCompactList(values []string, formatValue func(string) string) string {
+	if len(values) == 0 {
+		return "*"
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		bounds := strings.SplitN(v, ":", 2)
+		if formatValue != nil {
+			for j := range bounds {
+				bounds[j] = formatValue(bounds[j])
+			}
+		}
+		result = append(result, strings.Join(bounds, ".."))
+	}
+	return strings.Join(result, ",")
+}
+
+// parseCompactList parses a list of values and ranges to the form of Alertmanager, e.g. "1..5" to "1:5".
+func parseCompactList(field string, parseValue func(string) (string, error)) ([]string, error) {
+	if field == "*" {
+		return nil, nil
+	}
+	var result []string
+	for _, item := range strings.Split(field, ",") {
+		bounds := strings.Split(item, "..")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid range %q", item)
+		}
+		for j, bound := range bounds {
+			v, err := parseValue(bound)
+			if err != nil {
+				return nil, err
+			}
+			bounds[j] = v
+		}
+		result = append(result, strings.Join(bounds, ":"))
+	}
+	return result, nil
+}
+
+func isCompactWeekdays(field string) bool {
+	_, err := parseCompactList(field, parseWeekday)
+	return err == nil
+}
+
+func parseWeekday(s string) (string, error) {
+	return parseName(s, weekdayNames, "weekday")
+}
+
+func parseMonth(s string) (string, error) {
+	if compactDayRegex.MatchString(s) && !strings.HasPrefix(s, "~") {
+		return s, nil
+	}
+	return parseName(s, monthNames, "month")
+}
+
+func parseName(s string, names []string, kind string) (string, error) {
+	lower := strings.ToLower(s)
+	for _, name := range names {
+		if lower == name || (len(lower) == 3 && strings.HasPrefix(name, lower)) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("invalid %s %q", kind, s)
+}
+
+func parseCompactDate(field string) (years, months, days []string, err error) {
+	parts := strings.Split(field, "-")
+	years, err = parseCompactList(parts[0], func(s string) (string, error) {
+		if !compactYearRegex.MatchString(s) {
+			return "", fmt.Errorf("invalid year %q", s)
+		}
+		return s, nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	months, err = parseCompactList(parts[1], parseMonth)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	days, err = parseCompactList(parts[2], func(s string) (string, error) {
+		if !compactDayRegex.MatchString(s) {
+			return "", fmt.Errorf("invalid day of month %q", s)
+		}
+		if strings.HasPrefix(s, "~") {
+			return "-" + s[1:], nil
+		}
+		return s, nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return years, months, days, nil
+}
+
+func parseCompactTimes(field string) ([]TimeRange, error) {
+	var result []TimeRange
+	for _, item := range strings.Split(field, ",") {
+		if !compactTimeRegex.MatchString(item) {
+			return nil, fmt.Errorf("invalid time range %q, expected HH:MM..HH:MM", item)
+		}
+		bounds := strings.Split(item, "..")
+		result = append(result, TimeRange{StartTime: bounds[0], EndTime: bounds[1]})
+	}
+	return result, nil
+}
+
+func parseCompactLocation(field string) (*string, error) {
+	if _, err := time.LoadLocation(field); err != nil {
+		return nil, fmt.Errorf("invalid location %q: %w", field, err)
+	}
+	return &field, nil
+}
@@ -0,0 +1,96 @@
+package v0alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestParseCompactInterval(t *testing.T) {
+	testCases := []struct {
+		name     string
+		compact  string
+		expected Interval
+		format   string
+	}{
+		{
+			name:     "any time",
+			compact:  "*",
+			expected: Interval{},
+		},
+		{
+			name:    "business hours",
+			compact: "mon..fri 09:00..17:00 Europe/Paris",
+			expected: Interval{
+				Weekdays: []string{"monday:friday"},
+				Times:    []TimeRange{{StartTime: "09:00", EndTime: "17:00"}},
+				Location: util.Pointer("Europe/Paris"),
+			},
+			format: "monday..friday 09:00..17:00 Europe/Paris",
+		},
+		{
+			name:    "dates",
+			compact: "2020..2022,2030-jan..mar,october-1..5,~7..~2",
+			expected: Interval{
+				Years:       []string{"2020:2022", "2030"},
+				Months:      []string{"january:march", "october"},
+				DaysOfMonth: []string{"1:5", "-7:-2"},
+			},
+			format: "2020..2022,2030-january..march,october-1..5,~7..~2",
+		},
+		{
+			name:    "any year and month",
+			compact: "*-*-~1 UTC",
+			expected: Interval{
+				DaysOfMonth: []string{"-1"},
+				Location:    util.Pointer("UTC"),
+			},
+		},
+		{
+			name:    "components in any order",
+			compact: "00:00..08:00,20:00..24:00 sunday,saturday *-12-*",
+			expected: Interval{
+				Weekdays: []string{"sunday", "saturday"},
+				Months:   []string{"12"},
+				Times:    []TimeRange{{StartTime: "00:00", EndTime: "08:00"}, {StartTime: "20:00", EndTime: "24:00"}},
+			},
+			format: "sunday,saturday *-12-* 00:00..08:00,20:00..24:00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interval, err := ParseCompactInterval(tc.compact)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, interval)
+
+			format := tc.format
+			if format == "" {
+				format = tc.compact
+			}
+			require.Equal(t, format, FormatCompactInterval(interval))
+
+			again, err := ParseCompactInterval(FormatCompactInterval(interval))
+			require.NoError(t, err)
+			require.Equal(t, interval, again)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, compact := range []string{
+			"",
+			"mon..fri tue",
+			"noday",
+			"9:00-17:00",
+			"2020-13th-1",
+			"*-*-32x",
+			"mon..tue..wed",
+			"Not/A_Location",
+		} {
+			_, err := ParseCompactInterval(compact)
+			require.Error(t, err, compact)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package v0alpha1
+
+import (
+	"fmt"
+
+	"github.com/grafana/authlib/claims"
+)
+
+// OrgIDForNamespace returns the organization of a namespace of the API group. Organizations are addressed with the
+// default and org-<id> namespaces. A Grafana Cloud stack has a single organization, which is addressed with the stack
+// namespace, stacks-<id>, or interchangeably with the default namespace.
+//
+// The mapper is the namespace mapper of the instance. Namespaces of other stacks, stack namespaces on an instance
+// that is not a stack, and other organizations on a stack, are rejected.
+func OrgIDForNamespace(namespace string, mapper func(orgID int64) string) (int64, error) {
+	if mapper == nil {
+		mapper = claims.OrgNamespaceFormatter
+	}
+	info, err := claims.ParseNamespace(namespace)
+	if err != nil {
+		return 0, err
+	}
+	if info.OrgID < 1 {
+		return 0, fmt.Errorf("namespace %q has no organization", namespace)
+	}
+	instance, err := claims.ParseNamespace(mapper(1))
+	if err != nil {
+		return 0, err
+	}
+	if instance.StackID > 0 {
+		if info.StackID > 0 && info.StackID != instance.StackID {
+			return 0, fmt.Errorf("namespace %q belongs to another stack", namespace)
+		}
+		if info.StackID <= 0 && info.OrgID != 1 {
+			return 0, fmt.Errorf("namespace %q is not an organization of the stack", namespace)
+		}
+		return info.OrgID, nil
+	}
+	if info.StackID > 0 {
+		return 0, fmt.Errorf("namespace %q is a stack namespace", namespace)
+	}
+	return info.OrgID, nil
+}
+
+// SameNamespace returns true if both namespaces address the same organization, e.g. default and stacks-<id> on a
+// stack.
+func SameNamespace(a, b string, mapper func(orgID int64) string) bool {
+	orgA, err := OrgIDForNamespace(a, mapper)
+	if err != nil {
+		return false
+	}
+	orgB, err := OrgIDForNamespace(b, mapper)
+	return err == nil && orgA == orgB
+}
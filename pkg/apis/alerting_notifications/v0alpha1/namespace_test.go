@@ -0,0 +1,53 @@
+package v0alpha1
+
+import (
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgIDForNamespace(t *testing.T) {
+	stack := func(_ int64) string { return "stacks-123" }
+
+	testCases := []struct {
+		name      string
+		namespace string
+		mapper    func(int64) string
+		orgID     int64
+		err       string
+	}{
+		{name: "default org", namespace: "default", mapper: claims.OrgNamespaceFormatter, orgID: 1},
+		{name: "other org", namespace: "org-3", mapper: claims.OrgNamespaceFormatter, orgID: 3},
+		{name: "stack namespace on an instance that is not a stack", namespace: "stacks-123", mapper: claims.OrgNamespaceFormatter, err: "is a stack namespace"},
+		{name: "stack namespace", namespace: "stacks-123", mapper: stack, orgID: 1},
+		{name: "default namespace on a stack", namespace: "default", mapper: stack, orgID: 1},
+		{name: "namespace of another stack", namespace: "stacks-456", mapper: stack, err: "belongs to another stack"},
+		{name: "other org on a stack", namespace: "org-3", mapper: stack, err: "is not an organization of the stack"},
+		{name: "nil mapper", namespace: "org-2", orgID: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orgID, err := OrgIDForNamespace(tc.namespace, tc.mapper)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.orgID, orgID)
+		})
+	}
+
+	t.Run("invalid namespace", func(t *testing.T) {
+		_, err := OrgIDForNamespace("", claims.OrgNamespaceFormatter)
+		require.Error(t, err)
+	})
+}
+
+func TestSameNamespace(t *testing.T) {
+	stack := func(_ int64) string { return "stacks-123" }
+	require.True(t, SameNamespace("default", "stacks-123", stack))
+	require.False(t, SameNamespace("stacks-456", "stacks-123", stack))
+	require.False(t, SameNamespace("default", "org-2", claims.OrgNamespaceFormatter))
+}
@@ -0,0 +1,41 @@
+package v0alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeProvisioned is True when the resource is managed by provisioning and cannot be changed via the API.
+	ConditionTypeProvisioned = "Provisioned"
+	// ConditionTypeApplied reports whether the last apply of the Alertmanager configuration of the organization
+	// succeeded. The message of a False condition is the last error.
+	ConditionTypeApplied = "Applied"
+)
+
+const (
+	ConditionReasonProvisioned    = "Provisioned"
+	ConditionReasonNotProvisioned = "NotProvisioned"
+	ConditionReasonApplySucceeded = "ApplySucceeded"
+	ConditionReasonApplyFailed    = "ApplyFailed"
+	ConditionReasonApplyUnknown   = "ApplyUnknown"
+)
+
+// ResourceStatus is the observed state of a notification resource.
+// +k8s:openapi-gen=true
+type ResourceStatus struct {
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+func (o *TimeInterval) GetStatus() *ResourceStatus {
+	return &o.Status
+}
+
+func (o *Receiver) GetStatus() *ResourceStatus {
+	return &o.Status
+}
+
+func (o *TemplateGroup) GetStatus() *ResourceStatus {
+	return &o.Status
+}
@@ -28,6 +28,10 @@ type TimeIntervalSpec struct {
 	Name string `json:"name"`
 	// +listType=atomic
 	TimeIntervals []Interval `json:"time_intervals"`
+	// CompactIntervals are intervals in compact form, e.g. "monday..friday 09:00..17:00 Europe/Paris", that are
+	// appended to the time intervals on write. They are not returned on read.
+	// +listType=atomic
+	CompactIntervals []string `json:"compact_intervals,omitempty"`
 }
 
 // TimeRange defines model for TimeRange.
@@ -16,6 +16,7 @@ type TimeInterval struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
 	Spec              TimeIntervalSpec `json:"spec"`
+	Status            ResourceStatus   `json:"status,omitempty"`
 }
 
 func (o *TimeInterval) GetSpec() any {
@@ -98,7 +99,8 @@ type TimeIntervalList struct {
 type Receiver struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
-	Spec              ReceiverSpec `json:"spec"`
+	Spec              ReceiverSpec   `json:"spec"`
+	Status            ResourceStatus `json:"status,omitempty"`
 }
 
 func (o *Receiver) GetSpec() any {
@@ -182,6 +184,7 @@ type TemplateGroup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
 	Spec              TemplateGroupSpec `json:"spec"`
+	Status            ResourceStatus    `json:"status,omitempty"`
 }
 
 func (o *TemplateGroup) GetSpec() any {
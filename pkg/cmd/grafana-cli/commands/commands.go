@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/zanzanabackup"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -144,6 +145,18 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "dashboard-acl",
+				Usage:  "Converts the legacy dashboard and folder ACL entries left in the database to managed permissions and reports the entries that can't be converted. Safe to execute multiple times.",
+				Action: runRunnerCommand(datamigrations.MigrateDashboardACL),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only report the entries that would be converted",
+						Value: false,
+					},
+				},
+			},
 		},
 	},
 	{
@@ -167,6 +180,22 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "zanzana",
+		Usage: "Backup and restore the zanzana tuples of the instance",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "export <file>: writes all tuples of the store to a JSON lines file",
+				Action: runRunnerCommand(zanzanabackup.ExportTuples),
+			},
+			{
+				Name:   "import",
+				Usage:  "import <file>: writes the tuples of a JSON lines file to the store. Safe to execute multiple times.",
+				Action: runRunnerCommand(zanzanabackup.ImportTuples),
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
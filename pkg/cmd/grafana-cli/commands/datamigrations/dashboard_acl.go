@@ -0,0 +1,71 @@
+package datamigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/dualwrite"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/authz"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+type legacyACLMigrator interface {
+	MigrateLegacyACL(ctx context.Context, dryRun bool) (*resourcepermissions.LegacyACLReport, error)
+}
+
+// MigrateDashboardACL converts the dashboard_acl entries that were not migrated to managed permissions
+// and reports the entries that can't be converted. Safe to execute multiple times.
+func MigrateDashboardACL(c utils.CommandLine, runner server.Runner) error {
+	ctx := context.Background()
+	dryRun := c.Bool("dry-run")
+
+	converted := 0
+	services := []struct {
+		resource string
+		service  any
+	}{
+		{resource: "folders", service: runner.FolderPermissions},
+		{resource: "dashboards", service: runner.DashboardPermissions},
+	}
+	for _, s := range services {
+		migrator, ok := s.service.(legacyACLMigrator)
+		if !ok {
+			return fmt.Errorf("%s permissions service doesn't support migrating legacy acl entries", s.resource)
+		}
+
+		report, err := migrator.MigrateLegacyACL(ctx, dryRun)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range report.Unconvertible {
+			logger.Warnf("Cannot convert dashboard_acl entry %d of dashboard %d in org %d: %s\n", entry.ID, entry.DashboardID, entry.OrgID, entry.Reason)
+		}
+		converted += report.Converted
+		logger.Infof("%s: %d entries convertible, %d already converted, %d unconvertible\n", s.resource, report.Converted, report.Existing, len(report.Unconvertible))
+	}
+
+	if dryRun {
+		logger.Infof("%s Dry run, %d entries would be converted\n", color.GreenString("✔"), converted)
+		return nil
+	}
+
+	if converted > 0 && runner.Features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		client, err := authz.ProvideZanzana(runner.Cfg, runner.SQLStore, runner.Features)
+		if err != nil {
+			return err
+		}
+		if err := dualwrite.NewZanzanaReconciler(runner.Cfg, client, runner.SQLStore, nil).Sync(ctx); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("%s Converted %d dashboard_acl entries\n", color.GreenString("✔"), converted)
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package zanzanabackup exports and imports the tuples of a zanzana store as JSON lines,
+// one tuple key per line. The embedded store data is not portable across instances, the
+// exported file can be imported into any other store.
+package zanzanabackup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/authz"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+const (
+	readPageSize = 100
+	// writeBatchSize is the default limit of tuples per write request of openfga
+	writeBatchSize = 100
+)
+
+// ExportTuples writes all tuples of the zanzana store to the file passed as first argument.
+func ExportTuples(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing export file path")
+	}
+
+	client, err := newClient(runner)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	count, err := Export(context.Background(), client, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%s Exported %d tuples to %s\n", color.GreenString("✔"), count, path)
+	return nil
+}
+
+// ImportTuples writes the tuples of the file passed as first argument to the zanzana store.
+// Tuples already present in the store are skipped so the import is safe to execute multiple times.
+func ImportTuples(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing import file path")
+	}
+
+	client, err := newClient(runner)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	count, err := Import(context.Background(), client, f)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("%s Imported %d tuples from %s\n", color.GreenString("✔"), count, path)
+	return nil
+}
+
+func newClient(runner server.Runner) (zanzana.Client, error) {
+	if !runner.Features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil, errors.New("zanzana is not enabled")
+	}
+	return authz.ProvideZanzana(runner.Cfg, runner.SQLStore, runner.Features)
+}
+
+// Export streams all tuples of the store to w and returns the number of exported tuples.
+func Export(ctx context.Context, client zanzana.Client, w io.Writer) (int, error) {
+	out := bufio.NewWriter(w)
+
+	count := 0
+	token := ""
+	for {
+		// Reading without a tuple key returns all tuples of the store
+		res, err := client.Read(ctx, &openfgav1.ReadRequest{
+			PageSize:          wrapperspb.Int32(readPageSize),
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return count, err
+		}
+
+		for _, t := range res.GetTuples() {
+			line, err := protojson.Marshal(t.GetKey())
+			if err != nil {
+				return count, err
+			}
+			if _, err := out.Write(append(line, '\n')); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		token = res.GetContinuationToken()
+		if token == "" {
+			break
+		}
+	}
+
+	return count, out.Flush()
+}
+
+// Import writes the tuples read from r to the store and returns the number of written tuples.
+func Import(ctx context.Context, client zanzana.Client, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	// conditions can make tuples larger than the default token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	count := 0
+	batch := make([]*openfgav1.TupleKey, 0, writeBatchSize)
+	flush := func() error {
+		written, err := write(ctx, client, batch)
+		count += written
+		batch = batch[:0]
+		return err
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		tuple := &openfgav1.TupleKey{}
+		if err := protojson.Unmarshal(scanner.Bytes(), tuple); err != nil {
+			return count, fmt.Errorf("invalid tuple on line %d: %w", line, err)
+		}
+		batch = append(batch, tuple)
+
+		if len(batch) == writeBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// write writes the tuples in a single request. A write request fails entirely when one of its tuples
+// already exists, the tuples are then written one by one skipping the existing ones.
+func write(ctx context.Context, client zanzana.Client, tuples []*openfgav1.TupleKey) (int, error) {
+	err := client.Write(ctx, &openfgav1.WriteRequest{Writes: &openfgav1.WriteRequestWrites{TupleKeys: tuples}})
+	if err == nil {
+		return len(tuples), nil
+	}
+	if !isAlreadyExists(err) {
+		return 0, err
+	}
+
+	written := 0
+	for _, t := range tuples {
+		err := client.Write(ctx, &openfgav1.WriteRequest{Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{t}}})
+		if err != nil {
+			if isAlreadyExists(err) {
+				continue
+			}
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+func isAlreadyExists(err error) bool {
+	return strings.Contains(err.Error(), "cannot write a tuple which already exists")
+}
@@ -0,0 +1,122 @@
+package jobstatus
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the status of a background job run by the instance.
+type Status struct {
+	Name string `json:"name"`
+	// Interval is the time between the runs of the job, zero if the job runs once
+	Interval     time.Duration `json:"interval"`
+	Running      bool          `json:"running"`
+	Runs         int64         `json:"runs"`
+	LastRun      time.Time     `json:"lastRun"`
+	LastDuration time.Duration `json:"lastDuration"`
+	LastError    string        `json:"lastError,omitempty"`
+	NextRun      time.Time     `json:"nextRun"`
+}
+
+// Registry tracks the status of the background jobs of the instance.
+type Registry struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+	now  func() time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		jobs: make(map[string]*Job),
+		now:  time.Now,
+	}
+}
+
+// Register returns the job with the given name, the job is created on first registration. The interval is the time
+// between the runs of the job, zero if the job runs once. A nil registry returns a nil job.
+func (r *Registry) Register(name string, interval time.Duration) *Job {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job, ok := r.jobs[name]; ok {
+		return job
+	}
+	job := &Job{now: r.now, status: Status{Name: name, Interval: interval}}
+	if interval > 0 {
+		job.status.NextRun = r.now().Add(interval)
+	}
+	r.jobs[name] = job
+	return job
+}
+
+// Statuses returns the status of the registered jobs sorted by name.
+func (r *Registry) Statuses() []Status {
+	if r == nil {
+		return []Status{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		statuses = append(statuses, job.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Job records the runs of a background job. A nil job records nothing, components can track their jobs whether or
+// not they were registered.
+type Job struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	status Status
+}
+
+// Start records the start of a run and returns the function recording its end with the error of the run.
+func (j *Job) Start() func(err error) {
+	if j == nil {
+		return func(error) {}
+	}
+
+	j.mu.Lock()
+	started := j.now()
+	j.status.Running = true
+	j.mu.Unlock()
+
+	return func(err error) {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		j.status.Running = false
+		j.status.Runs++
+		j.status.LastRun = started
+		j.status.LastDuration = j.now().Sub(started)
+		j.status.LastError = ""
+		if err != nil {
+			j.status.LastError = err.Error()
+		}
+		if j.status.Interval > 0 {
+			j.status.NextRun = started.Add(j.status.Interval)
+		}
+	}
+}
+
+// Track runs fn as a run of the job and returns its error.
+func (j *Job) Track(fn func() error) error {
+	finish := j.Start()
+	err := fn()
+	finish(err)
+	return err
+}
+
+// Status returns the status of the job.
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
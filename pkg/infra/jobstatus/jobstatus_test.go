@@ -0,0 +1,55 @@
+package jobstatus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewRegistry()
+	registry.now = func() time.Time { return now }
+
+	reconcile := registry.Register("reconcile", time.Hour)
+	syncJob := registry.Register("sync", 0)
+	require.Same(t, reconcile, registry.Register("reconcile", time.Minute), "jobs are registered once")
+
+	require.Equal(t, []Status{
+		{Name: "reconcile", Interval: time.Hour, NextRun: now.Add(time.Hour)},
+		{Name: "sync"},
+	}, registry.Statuses())
+
+	finish := reconcile.Start()
+	require.True(t, reconcile.Status().Running)
+	now = now.Add(time.Minute)
+	finish(errors.New("failed"))
+
+	require.Equal(t, Status{
+		Name:         "reconcile",
+		Interval:     time.Hour,
+		Runs:         1,
+		LastRun:      now.Add(-time.Minute),
+		LastDuration: time.Minute,
+		LastError:    "failed",
+		NextRun:      now.Add(-time.Minute).Add(time.Hour),
+	}, reconcile.Status())
+
+	require.NoError(t, syncJob.Track(func() error { return nil }))
+	status := syncJob.Status()
+	require.Equal(t, int64(1), status.Runs)
+	require.Empty(t, status.LastError)
+	require.True(t, status.NextRun.IsZero(), "jobs running once have no next run")
+}
+
+func TestJob_Nil(t *testing.T) {
+	var registry *Registry
+	job := registry.Register("sync", 0)
+	require.Nil(t, job)
+	require.Empty(t, registry.Statuses())
+
+	err := errors.New("failed")
+	require.ErrorIs(t, job.Track(func() error { return err }), err)
+}
@@ -102,6 +102,9 @@ var (
 	// MAccessSearchUserPermissionsCacheUsage is a metric counter for cache usage
 	MAccessSearchUserPermissionsCacheUsage *prometheus.CounterVec
 
+	// MAccessDuplicateBuiltinRoles is a metric counter for duplicated builtin role assignments found in the database
+	MAccessDuplicateBuiltinRoles prometheus.Counter
+
 	// MPublicDashboardRequestCount is a metric counter for public dashboards requests
 	MPublicDashboardRequestCount prometheus.Counter
 
@@ -630,6 +633,12 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"status"}, map[string][]string{"status": accesscontrol.CacheUsageStatuses})
 
+	MAccessDuplicateBuiltinRoles = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "access_duplicate_builtin_roles_total",
+		Help:      "number of duplicated builtin role assignments found and merged",
+		Namespace: ExporterName,
+	})
+
 	StatsTotalLibraryPanels = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_library_panels",
 		Help:      "total amount of library panels in the database",
@@ -748,6 +757,7 @@ func initMetricVars(reg prometheus.Registerer) {
 		MAccessEvaluationCount,
 		MAccessPermissionsCacheUsage,
 		MAccessSearchUserPermissionsCacheUsage,
+		MAccessDuplicateBuiltinRoles,
 		MAlertingActiveAlerts,
 		MStatTotalDashboards,
 		MStatTotalFolders,
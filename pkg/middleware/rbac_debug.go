@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/debugsession"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RBACDebugSession captures the access control decisions of signed in requests sent with the
+// X-Grafana-RBAC-Debug: true header and returns the id of the captured session in the same response header.
+// It needs to be after the context handler.
+func RBACDebugSession(store *debugsession.Store) web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqContext := contexthandler.FromContext(r.Context())
+			if store == nil || r.Header.Get(debugsession.HeaderName) != "true" || reqContext == nil || !reqContext.IsSignedIn {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session := store.Start(reqContext.SignedInUser.GetUID(), reqContext.SignedInUser.GetOrgID(), r.Method, r.URL.Path)
+			defer store.Save(session)
+
+			ctx := debugsession.WithSession(r.Context(), session)
+			// This modifies both r and reqContext.Req since they point to the same value
+			*reqContext.Req = *reqContext.Req.WithContext(ctx)
+			w.Header().Set(debugsession.HeaderName, session.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
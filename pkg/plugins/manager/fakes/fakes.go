@@ -413,6 +413,18 @@ func (f *FakeActionSetRegistry) RegisterActionSets(_ context.Context, _ string,
 	return f.ExpectedErr
 }
 
+type FakeResourcePermissionsRegistry struct {
+	ExpectedErr error
+}
+
+func NewFakeResourcePermissionsRegistry() *FakeResourcePermissionsRegistry {
+	return &FakeResourcePermissionsRegistry{}
+}
+
+func (f *FakeResourcePermissionsRegistry) RegisterResourcePermissions(_ context.Context, _ string, _ []plugins.ResourcePermissionRegistration) error {
+	return f.ExpectedErr
+}
+
 type FakePluginFS struct {
 	OpenFunc   func(name string) (fs.File, error)
 	RemoveFunc func() error
@@ -441,6 +441,27 @@ type ActionSet struct {
 	Actions []string `json:"actions"`
 }
 
+// ResourcePermissionRegistration is the model for a resource of an app plugin whose permissions
+// are managed by Grafana, the same way as the permissions of dashboards or folders.
+type ResourcePermissionRegistration struct {
+	// Resource is the action and scope prefix of the resource, prefixed with the plugin id (e.g. myorg-app.things)
+	Resource string `json:"resource"`
+	// ResourceAttribute is the attribute the scopes are based on, defaults to uid
+	ResourceAttribute string `json:"resourceAttribute,omitempty"`
+	// Permissions maps the permissions (View, Edit, Admin) to their actions, defaults to the action sets
+	// <resource>:view, <resource>:edit and <resource>:admin declared by the plugin
+	Permissions map[string][]string `json:"permissions,omitempty"`
+	Assignments ResourceAssignments `json:"assignments"`
+}
+
+// ResourceAssignments decides what the permissions of a plugin resource can be assigned to.
+type ResourceAssignments struct {
+	Users           bool `json:"users"`
+	Teams           bool `json:"teams"`
+	ServiceAccounts bool `json:"serviceAccounts"`
+	BuiltInRoles    bool `json:"builtInRoles"`
+}
+
 type QueryCachingConfig struct {
 	Enabled bool  `json:"enabled"`
 	TTLMS   int64 `json:"TTLMs"`
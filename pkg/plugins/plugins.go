@@ -102,8 +102,9 @@ type JSONData struct {
 	Routes       []*Route     `json:"routes"`
 
 	// AccessControl settings
-	Roles      []RoleRegistration `json:"roles,omitempty"`
-	ActionSets []ActionSet        `json:"actionSets,omitempty"`
+	Roles               []RoleRegistration               `json:"roles,omitempty"`
+	ActionSets          []ActionSet                      `json:"actionSets,omitempty"`
+	ResourcePermissions []ResourcePermissionRegistration `json:"resourcePermissions,omitempty"`
 
 	// Panel settings
 	SkipDataQuery bool `json:"skipDataQuery"`
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"context"
+
+	"github.com/grafana/authlib/claims"
+	"k8s.io/apimachinery/pkg/api/errors"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// NamespaceInfo returns the namespace of the request. Requests to namespaces that the instance does not serve, e.g.
+// of another stack, are forbidden.
+func NamespaceInfo(ctx context.Context, namespacer request.NamespaceMapper) (claims.NamespaceInfo, error) {
+	namespace := k8srequest.NamespaceValue(ctx)
+	orgID, err := notifications.OrgIDForNamespace(namespace, namespacer)
+	if err != nil {
+		return claims.NamespaceInfo{}, errors.NewForbidden(notifications.SchemeGroupVersion.WithResource("").GroupResource(), "", err)
+	}
+	info, err := claims.ParseNamespace(namespace)
+	if err != nil {
+		return claims.NamespaceInfo{}, err
+	}
+	info.OrgID = orgID
+	return info, nil
+}
+
+// OrgIDForList returns the organization of a list request. Lists across all namespaces are limited to the
+// organization of the requester.
+func OrgIDForList(ctx context.Context, namespacer request.NamespaceMapper) (int64, error) {
+	if k8srequest.NamespaceValue(ctx) == "" {
+		user, err := identity.GetRequester(ctx)
+		if user != nil {
+			return user.GetOrgID(), err
+		}
+		return -1, err
+	}
+	info, err := NamespaceInfo(ctx, namespacer)
+	return info.OrgID, err
+}
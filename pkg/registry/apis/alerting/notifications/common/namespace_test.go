@@ -0,0 +1,48 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	k8srequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+func TestNamespaceInfo(t *testing.T) {
+	stack := func(_ int64) string { return "stacks-123" }
+
+	t.Run("stack and default namespaces are the same org", func(t *testing.T) {
+		for _, ns := range []string{"stacks-123", "default"} {
+			info, err := NamespaceInfo(k8srequest.WithNamespace(context.Background(), ns), stack)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), info.OrgID)
+		}
+	})
+
+	t.Run("cross-namespace access is forbidden", func(t *testing.T) {
+		_, err := NamespaceInfo(k8srequest.WithNamespace(context.Background(), "stacks-456"), stack)
+		require.True(t, errors.IsForbidden(err))
+
+		_, err = NamespaceInfo(k8srequest.WithNamespace(context.Background(), "stacks-123"), claims.OrgNamespaceFormatter)
+		require.True(t, errors.IsForbidden(err))
+	})
+}
+
+func TestOrgIDForList(t *testing.T) {
+	t.Run("all namespaces use the org of the requester", func(t *testing.T) {
+		ctx := identity.WithRequester(context.Background(), &identity.StaticRequester{OrgID: 2})
+		orgID, err := OrgIDForList(ctx, claims.OrgNamespaceFormatter)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), orgID)
+	})
+
+	t.Run("namespace of another stack is forbidden", func(t *testing.T) {
+		ctx := k8srequest.WithNamespace(context.Background(), "stacks-456")
+		_, err := OrgIDForList(ctx, func(_ int64) string { return "stacks-123" })
+		require.True(t, errors.IsForbidden(err))
+	})
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// WriteLimiter limits the writes of each organization with a token bucket. Every write to a notification resource
+// saves and reloads the Alertmanager configuration of the organization, the limiter is shared by all the kinds.
+type WriteLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mtx      sync.Mutex
+	limiters map[int64]*rate.Limiter
+}
+
+// NewWriteLimiter creates a limiter that allows perSecond writes of each organization, with bursts of up to burst
+// writes. It returns nil, which allows all writes, if perSecond is 0. A burst below 1 would reject every write, it is
+// raised to 1.
+func NewWriteLimiter(perSecond float64, burst int) *WriteLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &WriteLimiter{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		limiters: make(map[int64]*rate.Limiter),
+	}
+}
+
+// Allow takes a token of the bucket of the organization. If the bucket is empty it returns false and the time until
+// the next token.
+func (l *WriteLimiter) Allow(orgID int64) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	l.mtx.Lock()
+	limiter, ok := l.limiters[orgID]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[orgID] = limiter
+	}
+	l.mtx.Unlock()
+
+	r := limiter.Reserve()
+	if !r.OK() {
+		return 0, false
+	}
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		return delay, false
+	}
+	return 0, true
+}
+
+var _ grafanarest.LegacyStorage = (*legacyStorage)(nil)
+
+type legacyStorage struct {
+	grafanarest.LegacyStorage
+	limiter *WriteLimiter
+}
+
+// WithWriteLimit wraps the legacy storage of a notification kind so that creates, updates and deletes are rejected
+// with 429 Too Many Requests when the organization exceeds the write limit. A delete of a collection is one write.
+func WithWriteLimit(store grafanarest.LegacyStorage, limiter *WriteLimiter) grafanarest.LegacyStorage {
+	if limiter == nil {
+		return store
+	}
+	return &legacyStorage{LegacyStorage: store, limiter: limiter}
+}
+
+func (s *legacyStorage) allow(ctx context.Context) error {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		// The wrapped storage rejects the request
+		return nil
+	}
+	delay, ok := s.limiter.Allow(info.OrgID)
+	if ok {
+		return nil
+	}
+	retryAfter := int(math.Ceil(delay.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	return errors.NewTooManyRequests(fmt.Sprintf("too many writes to the notification resources of namespace %s, retry in %d seconds", info.Value, retryAfter), retryAfter)
+}
+
+func (s *legacyStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, err
+	}
+	return s.LegacyStorage.Create(ctx, obj, createValidation, options)
+}
+
+func (s *legacyStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, false, err
+	}
+	return s.LegacyStorage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+}
+
+func (s *legacyStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, false, err
+	}
+	return s.LegacyStorage.Delete(ctx, name, deleteValidation, options)
+}
+
+func (s *legacyStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *internalversion.ListOptions) (runtime.Object, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, err
+	}
+	return s.LegacyStorage.DeleteCollection(ctx, deleteValidation, options, listOptions)
+}
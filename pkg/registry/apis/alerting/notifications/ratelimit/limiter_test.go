@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+)
+
+func TestWriteLimiter(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		limiter := NewWriteLimiter(0, 1)
+		require.Nil(t, limiter)
+		for i := 0; i < 10; i++ {
+			_, ok := limiter.Allow(1)
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("limits each org after the burst", func(t *testing.T) {
+		limiter := NewWriteLimiter(0.1, 2)
+		for i := 0; i < 2; i++ {
+			_, ok := limiter.Allow(1)
+			require.True(t, ok)
+		}
+		delay, ok := limiter.Allow(1)
+		require.False(t, ok)
+		require.Positive(t, delay)
+
+		_, ok = limiter.Allow(2)
+		require.True(t, ok)
+	})
+
+	t.Run("allows a write with a burst below 1", func(t *testing.T) {
+		for _, burst := range []int{0, -1} {
+			limiter := NewWriteLimiter(0.1, burst)
+			_, ok := limiter.Allow(1)
+			require.True(t, ok)
+			_, ok = limiter.Allow(1)
+			require.False(t, ok)
+		}
+	})
+}
+
+type fakeStorage struct {
+	grafanarest.LegacyStorage
+	creates int
+}
+
+func (f *fakeStorage) Create(_ context.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	f.creates++
+	return obj, nil
+}
+
+func TestWithWriteLimit(t *testing.T) {
+	inner := &fakeStorage{}
+	store := WithWriteLimit(inner, NewWriteLimiter(0.1, 1))
+	ctx := request.WithNamespace(context.Background(), "default")
+
+	_, err := store.Create(ctx, &notifications.TemplateGroup{}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = store.Create(ctx, &notifications.TemplateGroup{}, nil, nil)
+	require.True(t, errors.IsTooManyRequests(err))
+	retryAfter, ok := errors.SuggestsClientDelay(err)
+	require.True(t, ok)
+	require.Positive(t, retryAfter)
+	require.Equal(t, 1, inner.creates)
+}
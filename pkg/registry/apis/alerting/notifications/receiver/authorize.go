@@ -19,6 +19,7 @@ type AccessControlService interface {
 	AuthorizeCreate(context.Context, identity.Requester) error
 	AuthorizeUpdateByUID(context.Context, identity.Requester, string) error
 	AuthorizeDeleteByUID(context.Context, identity.Requester, string) error
+	AuthorizeDeleteSome(ctx context.Context, user identity.Requester) error
 }
 
 func Authorize(ctx context.Context, ac AccessControlService, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
@@ -74,6 +75,11 @@ func Authorize(ctx context.Context, ac AccessControlService, attr authorizer.Att
 		if err := ac.AuthorizeDeleteByUID(ctx, user, uid); err != nil {
 			return deny(err)
 		}
+	case "deletecollection":
+		// Every receiver of the collection is also authorized when it is deleted
+		if err := ac.AuthorizeDeleteSome(ctx, user); err != nil {
+			return deny(err)
+		}
 	default:
 		return authorizer.DecisionNoOpinion, "", nil
 	}
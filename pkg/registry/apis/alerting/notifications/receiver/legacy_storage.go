@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
 	grafanaRest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/common"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	alertingac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -70,7 +71,7 @@ func (s *legacyStorage) ConvertToTable(ctx context.Context, object runtime.Objec
 }
 
 func (s *legacyStorage) List(ctx context.Context, opts *internalversion.ListOptions) (runtime.Object, error) {
-	orgId, err := request.OrgIDForList(ctx)
+	orgId, err := common.OrgIDForList(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +115,7 @@ func (s *legacyStorage) List(ctx context.Context, opts *internalversion.ListOpti
 }
 
 func (s *legacyStorage) Get(ctx context.Context, uid string, _ *metav1.GetOptions) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -167,7 +168,7 @@ func (s *legacyStorage) Create(ctx context.Context,
 	createValidation rest.ValidateObjectFunc,
 	_ *metav1.CreateOptions,
 ) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +209,7 @@ func (s *legacyStorage) Update(ctx context.Context,
 	_ bool,
 	_ *metav1.UpdateOptions,
 ) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
@@ -251,7 +252,7 @@ func (s *legacyStorage) Update(ctx context.Context,
 
 // GracefulDeleter
 func (s *legacyStorage) Delete(ctx context.Context, uid string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
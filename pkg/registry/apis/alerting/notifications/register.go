@@ -11,8 +11,10 @@ import (
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
 	notificationsModels "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/ratelimit"
 	receiver "github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/receiver"
 	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/template_group"
 	timeInterval "github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/timeinterval"
@@ -23,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -32,6 +35,9 @@ var _ builder.APIGroupBuilder = (*NotificationsAPIBuilder)(nil)
 type NotificationsAPIBuilder struct {
 	authz        accesscontrol.AccessControl
 	receiverAuth receiver.AccessControlService
+	intervalAuth timeInterval.AccessControlService
+	prefs        pref.Service
+	writeLimiter *ratelimit.WriteLimiter
 	ng           *ngalert.AlertNG
 	namespacer   request.NamespaceMapper
 	gv           schema.GroupVersion
@@ -42,6 +48,7 @@ func RegisterAPIService(
 	apiregistration builder.APIRegistrar,
 	cfg *setting.Cfg,
 	ng *ngalert.AlertNG,
+	prefs pref.Service,
 ) *NotificationsAPIBuilder {
 	if ng.IsDisabled() || !features.IsEnabledGlobally(featuremgmt.FlagAlertingApiServer) {
 		return nil
@@ -52,6 +59,9 @@ func RegisterAPIService(
 		gv:           notificationsModels.SchemeGroupVersion,
 		authz:        ng.Api.AccessControl,
 		receiverAuth: ac.NewReceiverAccess[*ngmodels.Receiver](ng.Api.AccessControl, false),
+		intervalAuth: ac.NewTimeIntervalAccess(ng.Api.AccessControl),
+		prefs:        prefs,
+		writeLimiter: ratelimit.NewWriteLimiter(cfg.UnifiedAlerting.NotificationsAPIWriteRateLimit, cfg.UnifiedAlerting.NotificationsAPIWriteBurst),
 	}
 	apiregistration.RegisterAPI(builder)
 	return builder
@@ -74,17 +84,17 @@ func (t *NotificationsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiser
 	optsGetter := opts.OptsGetter
 	dualWriteBuilder := opts.DualWriteBuilder
 
-	intervals, err := timeInterval.NewStorage(t.ng.Api.MuteTimings, t.namespacer, scheme, optsGetter, dualWriteBuilder)
+	intervals, err := timeInterval.NewStorage(t.ng.Api.MuteTimings, t.intervalAuth, t.orgTimezone, t.namespacer, t.ng.MultiOrgAlertmanager, t.ng.KVStore, t.writeLimiter, scheme, optsGetter, dualWriteBuilder)
 	if err != nil {
 		return fmt.Errorf("failed to initialize time-interval storage: %w", err)
 	}
 
-	recvStorage, err := receiver.NewStorage(t.ng.Api.ReceiverService, t.namespacer, scheme, optsGetter, dualWriteBuilder, t.ng.Api.ReceiverService)
+	recvStorage, err := receiver.NewStorage(t.ng.Api.ReceiverService, t.namespacer, t.ng.MultiOrgAlertmanager, t.ng.KVStore, t.writeLimiter, scheme, optsGetter, dualWriteBuilder, t.ng.Api.ReceiverService)
 	if err != nil {
 		return fmt.Errorf("failed to initialize receiver storage: %w", err)
 	}
 
-	templ, err := template_group.NewStorage(t.ng.Api.Templates, t.namespacer, scheme, optsGetter, dualWriteBuilder)
+	templ, err := template_group.NewStorage(t.ng.Api.Templates, t.namespacer, t.ng.MultiOrgAlertmanager, t.ng.KVStore, t.writeLimiter, scheme, optsGetter, dualWriteBuilder)
 	if err != nil {
 		return fmt.Errorf("failed to initialize templates group storage: %w", err)
 	}
@@ -97,12 +107,51 @@ func (t *NotificationsAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiser
 	return nil
 }
 
+// orgTimezone returns the timezone preference of the organization, the default timezone when the organization has none.
+func (t *NotificationsAPIBuilder) orgTimezone(ctx context.Context, orgID int64) (string, error) {
+	prefs, err := t.prefs.GetWithDefaults(ctx, &pref.GetPreferenceWithDefaultsQuery{OrgID: orgID})
+	if err != nil {
+		return "", err
+	}
+	return prefs.Timezone, nil
+}
+
 func (t *NotificationsAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
 	return notificationsModels.GetOpenAPIDefinitions
 }
 
 func (t *NotificationsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
-	return nil
+	prefix := notificationsModels.TimeIntervalResourceInfo.GroupResource().Resource
+	return &builder.APIRoutes{
+		Namespace: []builder.APIRouteHandler{
+			{
+				Path: prefix + "/coverage",
+				Spec: &spec3.PathProps{
+					Post: &spec3.Operation{
+						OperationProps: spec3.OperationProps{
+							Tags:    []string{notificationsModels.TimeIntervalResourceInfo.GroupVersionKind().Kind},
+							Summary: "Analyze the coverage of time intervals",
+							Description: "Reports the overlaps of the time intervals, the gaps between them, and the percentage of a time window " +
+								"covered by any of them. All time intervals of the namespace are analyzed unless their names are given.",
+							Parameters: []*spec3.Parameter{
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "namespace",
+										In:          "path",
+										Required:    true,
+										Example:     "default",
+										Description: "workspace",
+										Schema:      spec.StringProperty(),
+									},
+								},
+							},
+						},
+					},
+				},
+				Handler: timeInterval.CoverageHandler(t.ng.Api.MuteTimings, t.intervalAuth, t.namespacer),
+			},
+		},
+	}
 }
 
 // PostProcessOpenAPI is a hook to alter OpenAPI3 specification of the API server.
@@ -133,7 +182,7 @@ func (t *NotificationsAPIBuilder) GetAuthorizer() authorizer.Authorizer {
 			case notificationsModels.TemplateGroupResourceInfo.GroupResource().Resource:
 				return template_group.Authorize(ctx, t.authz, a)
 			case notificationsModels.TimeIntervalResourceInfo.GroupResource().Resource:
-				return timeInterval.Authorize(ctx, t.authz, a)
+				return timeInterval.Authorize(ctx, t.intervalAuth, a)
 			case notificationsModels.ReceiverResourceInfo.GroupResource().Resource:
 				return receiver.Authorize(ctx, t.receiverAuth, a)
 			}
@@ -0,0 +1,333 @@
+package resourcelabels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// kvNamespace is the namespace of the labels in the kvstore. The keys are <resource>/<uid>.
+const kvNamespace = "alerting.notifications.labels"
+
+var _ grafanarest.LegacyStorage = (*legacyStorage)(nil)
+
+// legacyStorage keeps the metadata labels of the resources of the wrapped storage, which has no place for them in the
+// Alertmanager configuration, and filters lists by label selector.
+type legacyStorage struct {
+	grafanarest.LegacyStorage
+	kv       kvstore.KVStore
+	resource utils.ResourceInfo
+}
+
+// WithLabels wraps the legacy storage of a notification kind so that the metadata labels of its resources are stored
+// in the kvstore. Lists are filtered by the label selector and collections can be deleted by label selector.
+func WithLabels(store grafanarest.LegacyStorage, kv kvstore.KVStore, resource utils.ResourceInfo) grafanarest.LegacyStorage {
+	if kv == nil {
+		return store
+	}
+	return &legacyStorage{LegacyStorage: store, kv: kv, resource: resource}
+}
+
+func (s *legacyStorage) key(uid string) string {
+	return s.resource.GetName() + "/" + uid
+}
+
+// uidOf returns the key of the labels of the resource, its uid or its name when it has none.
+func uidOf(o metav1.Object) string {
+	if uid := string(o.GetUID()); uid != "" {
+		return uid
+	}
+	return o.GetName()
+}
+
+func (s *legacyStorage) get(ctx context.Context, orgID int64, uid string) (map[string]string, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, s.key(uid))
+	if err != nil || !ok {
+		return nil, err
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal labels of %s: %w", s.key(uid), err)
+	}
+	return result, nil
+}
+
+// getAll returns the labels of all the resources of the organization by uid.
+func (s *legacyStorage) getAll(ctx context.Context, orgID int64) (map[string]map[string]string, error) {
+	all, err := s.kv.GetAll(ctx, orgID, kvNamespace)
+	if err != nil {
+		return nil, err
+	}
+	prefix := s.resource.GetName() + "/"
+	result := make(map[string]map[string]string)
+	for key, value := range all[orgID] {
+		uid, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		var l map[string]string
+		if err := json.Unmarshal([]byte(value), &l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of %s: %w", key, err)
+		}
+		result[uid] = l
+	}
+	return result, nil
+}
+
+func (s *legacyStorage) set(ctx context.Context, orgID int64, uid string, l map[string]string) error {
+	if len(l) == 0 {
+		return s.kv.Del(ctx, orgID, kvNamespace, s.key(uid))
+	}
+	value, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, s.key(uid), string(value))
+}
+
+func (s *legacyStorage) validate(obj metav1.Object) error {
+	errs := metav1validation.ValidateLabels(obj.GetLabels(), field.NewPath("metadata", "labels"))
+	if len(errs) > 0 {
+		return errors.NewInvalid(s.resource.GroupVersionKind().GroupKind(), obj.GetName(), errs)
+	}
+	return nil
+}
+
+func (s *legacyStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	obj, err := s.LegacyStorage.Get(ctx, name, options)
+	if err != nil {
+		return obj, err
+	}
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	o, err := apimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.get(ctx, info.OrgID, uidOf(o))
+	if err != nil {
+		return nil, err
+	}
+	o.SetLabels(l)
+	return obj, nil
+}
+
+func (s *legacyStorage) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	list, err := s.LegacyStorage.List(ctx, options)
+	if err != nil {
+		return list, err
+	}
+	orgID, err := request.OrgIDForList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.getAll(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	selector := labels.Everything()
+	if options != nil && options.LabelSelector != nil {
+		selector = options.LabelSelector
+	}
+	items := make([]runtime.Object, 0)
+	listed := make(map[string]struct{})
+	err = apimeta.EachListItem(list, func(obj runtime.Object) error {
+		o, err := apimeta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		listed[uidOf(o)] = struct{}{}
+		o.SetLabels(all[uidOf(o)])
+		if selector.Matches(labels.Set(o.GetLabels())) {
+			items = append(items, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources can be deleted without this storage, e.g. by the provisioning API. When all the resources are listed
+	// the labels of the deleted ones are removed so they are not given to a new resource with the same uid.
+	if options == nil || options.FieldSelector == nil || options.FieldSelector.Empty() {
+		for uid := range all {
+			if _, ok := listed[uid]; ok {
+				continue
+			}
+			if err := s.set(ctx, orgID, uid, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return list, apimeta.SetList(list, items)
+}
+
+func (s *legacyStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	o, err := apimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(o); err != nil {
+		return nil, err
+	}
+	l := o.GetLabels()
+	created, err := s.LegacyStorage.Create(ctx, obj, createValidation, options)
+	if err != nil {
+		return created, err
+	}
+	c, err := apimeta.Accessor(created)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.set(ctx, info.OrgID, uidOf(c), l); err != nil {
+		return nil, err
+	}
+	c.SetLabels(l)
+	return created, nil
+}
+
+func (s *legacyStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, false, err
+	}
+	// The labels are keyed by the uid of the current resource, the name doesn't have to be its uid
+	var currentUID string
+	var current map[string]string
+	existing, err := s.LegacyStorage.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, false, err
+	}
+	if err == nil {
+		e, err := apimeta.Accessor(existing)
+		if err != nil {
+			return nil, false, err
+		}
+		currentUID = uidOf(e)
+		if current, err = s.get(ctx, info.OrgID, currentUID); err != nil {
+			return nil, false, err
+		}
+	}
+	withLabels := &updatedObjectInfo{UpdatedObjectInfo: objInfo, current: current, validate: s.validate}
+	updated, created, err := s.LegacyStorage.Update(ctx, name, withLabels, createValidation, updateValidation, forceAllowCreate, options)
+	if err != nil {
+		return updated, created, err
+	}
+	u, err := apimeta.Accessor(updated)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.set(ctx, info.OrgID, uidOf(u), withLabels.updated); err != nil {
+		return nil, false, err
+	}
+	if currentUID != "" && uidOf(u) != currentUID {
+		// The uid is derived from the title for some kinds, the labels move with the resource
+		if err := s.set(ctx, info.OrgID, currentUID, nil); err != nil {
+			return nil, false, err
+		}
+	}
+	u.SetLabels(withLabels.updated)
+	return updated, created, nil
+}
+
+func (s *legacyStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	info, err := request.NamespaceInfoFrom(ctx, true)
+	if err != nil {
+		return nil, false, err
+	}
+	deleted, async, err := s.LegacyStorage.Delete(ctx, name, deleteValidation, options)
+	if err != nil {
+		return deleted, async, err
+	}
+	uid := name
+	if deleted != nil {
+		if d, err := apimeta.Accessor(deleted); err == nil {
+			uid = uidOf(d)
+		}
+	}
+	// The labels are deleted with the resource, a resource created later with the same uid doesn't get them
+	return deleted, async, s.set(ctx, info.OrgID, uid, nil)
+}
+
+// DeleteCollection deletes the resources that match the list options one by one. It stops at the first error, the
+// resources deleted until then stay deleted.
+func (s *legacyStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *internalversion.ListOptions) (runtime.Object, error) {
+	list, err := s.List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	items, err := apimeta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		o, err := apimeta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := s.Delete(ctx, o.GetName(), deleteValidation, options); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		deleted = append(deleted, item)
+	}
+	return list, apimeta.SetList(list, deleted)
+}
+
+// updatedObjectInfo sets the stored labels on the old object, so that patches keep them, and captures the labels of
+// the updated object.
+type updatedObjectInfo struct {
+	rest.UpdatedObjectInfo
+	current  map[string]string
+	validate func(metav1.Object) error
+	updated  map[string]string
+}
+
+func (i *updatedObjectInfo) UpdatedObject(ctx context.Context, oldObj runtime.Object) (runtime.Object, error) {
+	if oldObj != nil {
+		oldObj = oldObj.DeepCopyObject()
+		o, err := apimeta.Accessor(oldObj)
+		if err != nil {
+			return nil, err
+		}
+		o.SetLabels(i.current)
+	}
+	obj, err := i.UpdatedObjectInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		return obj, err
+	}
+	o, err := apimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.validate(o); err != nil {
+		return nil, err
+	}
+	i.updated = o.GetLabels()
+	return obj, nil
+}
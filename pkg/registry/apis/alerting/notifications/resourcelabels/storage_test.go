@@ -0,0 +1,164 @@
+package resourcelabels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+// fakeStorage is an in-memory legacy storage of templates that, like the Alertmanager configuration, drops labels.
+type fakeStorage struct {
+	grafanarest.LegacyStorage
+	templates map[string]notifications.TemplateGroup
+}
+
+func (f *fakeStorage) strip(t notifications.TemplateGroup) *notifications.TemplateGroup {
+	t.Labels = nil
+	return &t
+}
+
+func (f *fakeStorage) Get(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	t, ok := f.templates[name]
+	if !ok {
+		return nil, notifications.TemplateGroupResourceInfo.NewNotFound(name)
+	}
+	return f.strip(t), nil
+}
+
+func (f *fakeStorage) List(_ context.Context, _ *internalversion.ListOptions) (runtime.Object, error) {
+	list := &notifications.TemplateGroupList{}
+	for _, t := range f.templates {
+		list.Items = append(list.Items, *f.strip(t))
+	}
+	return list, nil
+}
+
+func (f *fakeStorage) Create(_ context.Context, obj runtime.Object, _ rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	t := obj.(*notifications.TemplateGroup)
+	t.Name = t.Spec.Title
+	t.UID = types.UID("uid-" + t.Spec.Title)
+	f.templates[t.Name] = *t
+	return f.strip(*t), nil
+}
+
+func (f *fakeStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, _ rest.ValidateObjectFunc, _ rest.ValidateObjectUpdateFunc, _ bool, _ *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	old, err := f.Get(ctx, name, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	obj, err := objInfo.UpdatedObject(ctx, old)
+	if err != nil {
+		return nil, false, err
+	}
+	t := obj.(*notifications.TemplateGroup)
+	f.templates[name] = *t
+	return f.strip(*t), false, nil
+}
+
+func (f *fakeStorage) Delete(_ context.Context, name string, _ rest.ValidateObjectFunc, _ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	t, ok := f.templates[name]
+	if !ok {
+		return nil, false, notifications.TemplateGroupResourceInfo.NewNotFound(name)
+	}
+	delete(f.templates, name)
+	return f.strip(t), false, nil
+}
+
+func newTemplate(title string, l map[string]string) *notifications.TemplateGroup {
+	return &notifications.TemplateGroup{
+		ObjectMeta: metav1.ObjectMeta{Labels: l},
+		Spec:       notifications.TemplateGroupSpec{Title: title},
+	}
+}
+
+func TestWithLabels(t *testing.T) {
+	ctx := request.WithNamespace(context.Background(), "default")
+
+	newStore := func(t *testing.T) grafanarest.LegacyStorage {
+		return WithLabels(&fakeStorage{templates: map[string]notifications.TemplateGroup{}}, fakes.NewFakeKVStore(t), notifications.TemplateGroupResourceInfo)
+	}
+
+	t.Run("keys labels by uid and removes the labels of resources deleted outside of the storage", func(t *testing.T) {
+		fake := &fakeStorage{templates: map[string]notifications.TemplateGroup{}}
+		kv := fakes.NewFakeKVStore(t)
+		store := WithLabels(fake, kv, notifications.TemplateGroupResourceInfo)
+		_, err := store.Create(ctx, newTemplate("payments", map[string]string{"team": "payments"}), nil, nil)
+		require.NoError(t, err)
+		_, ok, err := kv.Get(ctx, 1, kvNamespace, notifications.TemplateGroupResourceInfo.GetName()+"/uid-payments")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		delete(fake.templates, "payments")
+		_, err = store.List(ctx, &internalversion.ListOptions{})
+		require.NoError(t, err)
+
+		recreated := newTemplate("payments", nil)
+		recreated.Name, recreated.UID = "payments", "uid-payments"
+		fake.templates["payments"] = *recreated
+		got, err := store.Get(ctx, "payments", nil)
+		require.NoError(t, err)
+		require.Empty(t, got.(*notifications.TemplateGroup).Labels)
+	})
+
+	t.Run("keeps labels of created and updated resources", func(t *testing.T) {
+		store := newStore(t)
+		created, err := store.Create(ctx, newTemplate("payments", map[string]string{"team": "payments"}), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments"}, created.(*notifications.TemplateGroup).Labels)
+
+		got, err := store.Get(ctx, "payments", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments"}, got.(*notifications.TemplateGroup).Labels)
+
+		updated := newTemplate("payments", map[string]string{"team": "payments", "env": "prod"})
+		updated.Name = "payments"
+		_, _, err = store.Update(ctx, "payments", rest.DefaultUpdatedObjectInfo(updated), nil, nil, false, nil)
+		require.NoError(t, err)
+		got, err = store.Get(ctx, "payments", nil)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"team": "payments", "env": "prod"}, got.(*notifications.TemplateGroup).Labels)
+	})
+
+	t.Run("rejects invalid labels", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.Create(ctx, newTemplate("invalid", map[string]string{"team": "not a valid value"}), nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("filters list and deletes collection by label selector", func(t *testing.T) {
+		store := newStore(t)
+		for title, env := range map[string]string{"a": "prod", "b": "prod", "c": "dev"} {
+			_, err := store.Create(ctx, newTemplate(title, map[string]string{"env": env}), nil, nil)
+			require.NoError(t, err)
+		}
+		selector, err := labels.Parse("env=prod")
+		require.NoError(t, err)
+
+		list, err := store.List(ctx, &internalversion.ListOptions{LabelSelector: selector})
+		require.NoError(t, err)
+		require.Len(t, list.(*notifications.TemplateGroupList).Items, 2)
+
+		deleted, err := store.DeleteCollection(ctx, nil, &metav1.DeleteOptions{}, &internalversion.ListOptions{LabelSelector: selector})
+		require.NoError(t, err)
+		require.Len(t, deleted.(*notifications.TemplateGroupList).Items, 2)
+
+		list, err = store.List(ctx, &internalversion.ListOptions{})
+		require.NoError(t, err)
+		items := list.(*notifications.TemplateGroupList).Items
+		require.Len(t, items, 1)
+		require.Equal(t, "c", items[0].Name)
+		require.Equal(t, map[string]string{"env": "dev"}, items[0].Labels)
+	})
+}
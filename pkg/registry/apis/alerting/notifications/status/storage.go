@@ -0,0 +1,140 @@
+package status
+
+import (
+	"context"
+	"fmt"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+// ApplyStatusProvider returns the status of the last apply of the Alertmanager configuration of an organization.
+type ApplyStatusProvider interface {
+	ApplyStatus(orgID int64) (notifier.ApplyStatus, bool)
+}
+
+// Object is a notification resource with status conditions.
+type Object interface {
+	metav1.Object
+	GetProvenanceStatus() string
+	GetStatus() *notifications.ResourceStatus
+}
+
+var _ grafanarest.LegacyStorage = (*legacyStorage)(nil)
+
+// legacyStorage sets the status conditions of the resources returned by the wrapped storage.
+type legacyStorage struct {
+	grafanarest.LegacyStorage
+	applyStatus ApplyStatusProvider
+}
+
+// WithConditions wraps the legacy storage of a notification kind so that the resources it returns have the Provisioned
+// and Applied conditions. The conditions are computed on read and are not persisted.
+func WithConditions(store grafanarest.LegacyStorage, applyStatus ApplyStatusProvider) grafanarest.LegacyStorage {
+	if applyStatus == nil {
+		return store
+	}
+	return &legacyStorage{LegacyStorage: store, applyStatus: applyStatus}
+}
+
+func (s *legacyStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	obj, err := s.LegacyStorage.Get(ctx, name, options)
+	if err != nil {
+		return obj, err
+	}
+	return obj, s.setConditions(ctx, obj)
+}
+
+func (s *legacyStorage) List(ctx context.Context, options *internalversion.ListOptions) (runtime.Object, error) {
+	list, err := s.LegacyStorage.List(ctx, options)
+	if err != nil {
+		return list, err
+	}
+	return list, apimeta.EachListItem(list, func(obj runtime.Object) error {
+		return s.setConditions(ctx, obj)
+	})
+}
+
+func (s *legacyStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	created, err := s.LegacyStorage.Create(ctx, obj, createValidation, options)
+	if err != nil {
+		return created, err
+	}
+	return created, s.setConditions(ctx, created)
+}
+
+func (s *legacyStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	updated, created, err := s.LegacyStorage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	if err != nil {
+		return updated, created, err
+	}
+	return updated, created, s.setConditions(ctx, updated)
+}
+
+func (s *legacyStorage) setConditions(ctx context.Context, obj runtime.Object) error {
+	o, ok := obj.(Object)
+	if !ok {
+		return fmt.Errorf("expected notification resource but got %T", obj)
+	}
+	SetProvisionedCondition(o)
+	orgID, err := request.OrgIDForList(ctx)
+	if err != nil {
+		// The condition is informational, the resource is returned without it.
+		SetAppliedCondition(o, notifier.ApplyStatus{}, false)
+		return nil
+	}
+	applied, found := s.applyStatus.ApplyStatus(orgID)
+	SetAppliedCondition(o, applied, found)
+	return nil
+}
+
+// SetProvisionedCondition sets the Provisioned condition from the provenance of the resource. Provisioned resources
+// cannot be changed via the API.
+func SetProvisionedCondition(obj Object) {
+	condition := metav1.Condition{
+		Type:               notifications.ConditionTypeProvisioned,
+		Status:             metav1.ConditionFalse,
+		Reason:             notifications.ConditionReasonNotProvisioned,
+		ObservedGeneration: obj.GetGeneration(),
+		LastTransitionTime: obj.GetCreationTimestamp(),
+	}
+	if provenance := obj.GetProvenanceStatus(); provenance != notifications.ProvenanceStatusNone && provenance != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = notifications.ConditionReasonProvisioned
+		condition.Message = fmt.Sprintf("The resource is managed by %s provisioning and is read-only.", provenance)
+	}
+	apimeta.SetStatusCondition(&obj.GetStatus().Conditions, condition)
+}
+
+// SetAppliedCondition sets the Applied condition from the status of the last apply of the Alertmanager configuration
+// of the organization. The condition is Unknown if the configuration was not applied yet.
+func SetAppliedCondition(obj Object, applied notifier.ApplyStatus, found bool) {
+	condition := metav1.Condition{
+		Type:               notifications.ConditionTypeApplied,
+		Status:             metav1.ConditionUnknown,
+		Reason:             notifications.ConditionReasonApplyUnknown,
+		Message:            "The Alertmanager configuration has not been applied yet.",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	if found {
+		condition.LastTransitionTime = metav1.NewTime(applied.Time)
+		if applied.Error == "" {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = notifications.ConditionReasonApplySucceeded
+			condition.Message = "The Alertmanager configuration was applied."
+		} else {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = notifications.ConditionReasonApplyFailed
+			condition.Message = applied.Error
+		}
+	}
+	apimeta.SetStatusCondition(&obj.GetStatus().Conditions, condition)
+}
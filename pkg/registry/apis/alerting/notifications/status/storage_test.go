@@ -0,0 +1,88 @@
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/require"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func TestSetProvisionedCondition(t *testing.T) {
+	t.Run("not provisioned", func(t *testing.T) {
+		obj := &notifications.TimeInterval{}
+		obj.SetProvenanceStatus("")
+		SetProvisionedCondition(obj)
+		cond := apimeta.FindStatusCondition(obj.Status.Conditions, notifications.ConditionTypeProvisioned)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, notifications.ConditionReasonNotProvisioned, cond.Reason)
+	})
+
+	t.Run("provisioned from file", func(t *testing.T) {
+		obj := &notifications.Receiver{}
+		obj.SetProvenanceStatus("file")
+		SetProvisionedCondition(obj)
+		cond := apimeta.FindStatusCondition(obj.Status.Conditions, notifications.ConditionTypeProvisioned)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionTrue, cond.Status)
+		require.Equal(t, "The resource is managed by file provisioning and is read-only.", cond.Message)
+	})
+}
+
+func TestSetAppliedCondition(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	testCases := []struct {
+		name    string
+		applied notifier.ApplyStatus
+		found   bool
+		status  metav1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{
+			name:    "not applied yet",
+			status:  metav1.ConditionUnknown,
+			reason:  notifications.ConditionReasonApplyUnknown,
+			message: "The Alertmanager configuration has not been applied yet.",
+		},
+		{
+			name:    "applied",
+			applied: notifier.ApplyStatus{ConfigID: 1, Time: now},
+			found:   true,
+			status:  metav1.ConditionTrue,
+			reason:  notifications.ConditionReasonApplySucceeded,
+			message: "The Alertmanager configuration was applied.",
+		},
+		{
+			name:    "failed",
+			applied: notifier.ApplyStatus{ConfigID: 1, Time: now, Error: errors.New("invalid template").Error()},
+			found:   true,
+			status:  metav1.ConditionFalse,
+			reason:  notifications.ConditionReasonApplyFailed,
+			message: "invalid template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &notifications.TemplateGroup{}
+			SetAppliedCondition(obj, tc.applied, tc.found)
+			cond := apimeta.FindStatusCondition(obj.Status.Conditions, notifications.ConditionTypeApplied)
+			require.NotNil(t, cond)
+			require.Equal(t, tc.status, cond.Status)
+			require.Equal(t, tc.reason, cond.Reason)
+			require.Equal(t, tc.message, cond.Message)
+			if tc.found {
+				require.True(t, cond.LastTransitionTime.Time.Equal(now))
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/common"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -64,7 +65,7 @@ func (s *legacyStorage) ConvertToTable(ctx context.Context, object runtime.Objec
 }
 
 func (s *legacyStorage) List(ctx context.Context, opts *internalversion.ListOptions) (runtime.Object, error) {
-	orgId, err := request.OrgIDForList(ctx)
+	orgId, err := common.OrgIDForList(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +79,7 @@ func (s *legacyStorage) List(ctx context.Context, opts *internalversion.ListOpti
 }
 
 func (s *legacyStorage) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +96,7 @@ func (s *legacyStorage) Create(ctx context.Context,
 	createValidation rest.ValidateObjectFunc,
 	_ *metav1.CreateOptions,
 ) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +127,7 @@ func (s *legacyStorage) Update(ctx context.Context,
 	_ bool,
 	_ *metav1.UpdateOptions,
 ) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
@@ -165,7 +166,7 @@ func (s *legacyStorage) Update(ctx context.Context,
 
 // GracefulDeleter
 func (s *legacyStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
@@ -14,6 +14,10 @@ import (
 	model "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
 	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
 	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/ratelimit"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/resourcelabels"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/status"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 )
 
@@ -31,6 +35,9 @@ func (s storage) Compare(storageObj, legacyObj runtime.Object) bool {
 func NewStorage(
 	legacySvc TemplateService,
 	namespacer request.NamespaceMapper,
+	applyStatus status.ApplyStatusProvider,
+	kv kvstore.KVStore,
+	limiter *ratelimit.WriteLimiter,
 	scheme *runtime.Scheme,
 	optsGetter generic.RESTOptionsGetter,
 	dualWriteBuilder grafanarest.DualWriteBuilder,
@@ -40,14 +47,15 @@ func NewStorage(
 		namespacer:     namespacer,
 		tableConverter: resourceInfo.TableConverter(),
 	}
+	legacy := status.WithConditions(ratelimit.WithWriteLimit(resourcelabels.WithLabels(legacyStore, kv, resourceInfo), limiter), applyStatus)
 	if optsGetter != nil && dualWriteBuilder != nil {
 		store, err := grafanaregistry.NewRegistryStore(scheme, resourceInfo, optsGetter)
 		if err != nil {
 			return nil, err
 		}
-		return dualWriteBuilder(resourceInfo.GroupResource(), legacyStore, store)
+		return dualWriteBuilder(resourceInfo.GroupResource(), legacy, store)
 	}
-	return legacyStore, nil
+	return legacy, nil
 }
 
 func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
@@ -2,13 +2,26 @@ package timeinterval
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
-	"github.com/grafana/grafana/pkg/apimachinery/identity"
-	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 )
 
-func Authorize(ctx context.Context, ac accesscontrol.AccessControl, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+// AccessControlService provides access control for time intervals.
+type AccessControlService interface {
+	AuthorizeRead(ctx context.Context, user identity.Requester) error
+	FilterRead(ctx context.Context, user identity.Requester, intervals ...definitions.MuteTimeInterval) ([]definitions.MuteTimeInterval, error)
+	AuthorizeWrite(ctx context.Context, user identity.Requester) error
+	AuthorizeDelete(ctx context.Context, user identity.Requester) error
+}
+
+func Authorize(ctx context.Context, ac AccessControlService, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
 	if attr.GetResource() != resourceInfo.GroupResource().Resource {
 		return authorizer.DecisionNoOpinion, "", nil
 	}
@@ -17,37 +30,34 @@ func Authorize(ctx context.Context, ac accesscontrol.AccessControl, attr authori
 		return authorizer.DecisionDeny, "valid user is required", err
 	}
 
-	var action accesscontrol.Evaluator
 	switch attr.GetVerb() {
 	case "patch":
 		fallthrough
 	case "create":
 		fallthrough
 	case "update":
-		action = accesscontrol.EvalAny(
-			accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsTimeIntervalsWrite),
-			accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsWrite),
-		)
+		err = ac.AuthorizeWrite(ctx, user)
 	case "deletecollection":
 		fallthrough
 	case "delete":
-		action = accesscontrol.EvalAny(
-			accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsTimeIntervalsDelete),
-			accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsWrite),
-		)
+		err = ac.AuthorizeDelete(ctx, user)
+	default:
+		// Listed time intervals are also filtered by the storage, the user needs to be able to read them
+		err = ac.AuthorizeRead(ctx, user)
 	}
-
-	eval := accesscontrol.EvalAny(
-		accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsTimeIntervalsRead),
-		accesscontrol.EvalPermission(accesscontrol.ActionAlertingNotificationsRead),
-	)
-	if action != nil {
-		eval = accesscontrol.EvalAll(eval, action)
+	if err != nil {
+		return deny(err)
 	}
+	return authorizer.DecisionAllow, "", nil
+}
 
-	ok, err := ac.Evaluate(ctx, user, eval)
-	if ok {
-		return authorizer.DecisionAllow, "", nil
+func deny(err error) (authorizer.Decision, string, error) {
+	var utilErr errutil.Error
+	if errors.As(err, &utilErr) && utilErr.Reason.Status() == errutil.StatusForbidden {
+		if errors.Is(err, accesscontrol.ErrAuthorizationBase) {
+			return authorizer.DecisionDeny, fmt.Sprintf("required permissions: %s", utilErr.PublicPayload["permissions"]), nil
+		}
+		return authorizer.DecisionDeny, utilErr.PublicMessage, nil
 	}
 	return authorizer.DecisionDeny, "", err
 }
@@ -2,7 +2,9 @@ package timeinterval
 
 import (
 	"encoding/json"
+	"fmt"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
@@ -66,8 +68,25 @@ func buildTimeInterval(orgID int64, interval definitions.MuteTimeInterval, spec
 	return i
 }
 
+// expandCompactIntervals appends the compact intervals of the spec to the time intervals and clears them.
+func expandCompactIntervals(spec *model.TimeIntervalSpec) error {
+	for _, compact := range spec.CompactIntervals {
+		interval, err := model.ParseCompactInterval(compact)
+		if err != nil {
+			return errors.NewBadRequest(fmt.Sprintf("invalid compact interval %q: %s", compact, err))
+		}
+		spec.TimeIntervals = append(spec.TimeIntervals, interval)
+	}
+	spec.CompactIntervals = nil
+	return nil
+}
+
 func convertToDomainModel(interval *model.TimeInterval) (definitions.MuteTimeInterval, error) {
-	b, err := json.Marshal(interval.Spec)
+	spec := *interval.Spec.DeepCopy()
+	if err := expandCompactIntervals(&spec); err != nil {
+		return definitions.MuteTimeInterval{}, err
+	}
+	b, err := json.Marshal(spec)
 	if err != nil {
 		return definitions.MuteTimeInterval{}, err
 	}
@@ -0,0 +1,92 @@
+package timeinterval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1/fake"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+// addIntervalSeeds adds the corpus of the generator as JSON seeds. Seeds run as regular tests, random intervals
+// would make them flaky.
+func addIntervalSeeds(f *testing.F) {
+	for _, c := range (fake.IntervalGenerator{}).Corpus() {
+		data, err := json.Marshal(c.Interval)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+}
+
+func FuzzIntervalRoundTrip(f *testing.F) {
+	addIntervalSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var interval model.Interval
+		if err := json.Unmarshal(data, &interval); err != nil {
+			t.Skip()
+		}
+
+		require.Equal(t, interval, *interval.DeepCopy())
+
+		encoded, err := json.Marshal(interval)
+		require.NoError(t, err)
+		var decoded model.Interval
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		// Empty lists are omitted, the encoded forms are compared
+		reencoded, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		require.JSONEq(t, string(encoded), string(reencoded))
+
+		ti := &model.TimeInterval{
+			Spec: model.TimeIntervalSpec{Name: "fuzz", TimeIntervals: []model.Interval{interval}},
+		}
+		domain, err := convertToDomainModel(ti)
+		if err != nil {
+			// Invalid intervals are covered by FuzzIntervalValidate
+			return
+		}
+
+		// The conversions normalize the interval once, e.g. month numbers to names, then it is stable
+		k8s, err := convertToK8sResource(1, domain, request.GetNamespaceMapper(nil))
+		require.NoError(t, err)
+		again, err := convertToDomainModel(k8s)
+		require.NoError(t, err)
+		require.Equal(t, domain.TimeIntervals, again.TimeIntervals)
+
+		k8sAgain, err := convertToK8sResource(1, again, request.GetNamespaceMapper(nil))
+		require.NoError(t, err)
+		require.Equal(t, k8s.Spec, k8sAgain.Spec)
+	})
+}
+
+func FuzzIntervalValidate(f *testing.F) {
+	addIntervalSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var interval model.Interval
+		if err := json.Unmarshal(data, &interval); err != nil {
+			t.Skip()
+		}
+
+		ti := &model.TimeInterval{
+			Spec: model.TimeIntervalSpec{Name: "fuzz", TimeIntervals: []model.Interval{interval}},
+		}
+		domain, err := convertToDomainModel(ti)
+		if err != nil {
+			return
+		}
+		require.NoError(t, domain.Validate())
+
+		// Valid intervals are encoded in a form that is valid again
+		encoded, err := json.Marshal(domain)
+		require.NoError(t, err)
+		var spec model.TimeIntervalSpec
+		require.NoError(t, json.Unmarshal(encoded, &spec))
+		_, err = convertToDomainModel(&model.TimeInterval{Spec: spec})
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package timeinterval
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1/fake"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// intervalConversion is the golden form of a time interval converted to the domain model and back.
+type intervalConversion struct {
+	Name  string                  `json:"name"`
+	Spec  *model.TimeIntervalSpec `json:"spec,omitempty"`
+	Error string                  `json:"error,omitempty"`
+}
+
+func TestConvertToDomainModel_Corpus(t *testing.T) {
+	var specs []model.TimeIntervalSpec
+	for _, c := range (fake.IntervalGenerator{}).Corpus() {
+		specs = append(specs, model.TimeIntervalSpec{Name: c.Name, TimeIntervals: []model.Interval{c.Interval}})
+	}
+	// Invalid intervals cover the validation errors
+	for _, invalid := range []struct{ name, compact string }{
+		{name: "invalid-time-range", compact: "09:00-17:00"},
+		{name: "invalid-day-of-month", compact: "*-*-100"},
+		{name: "duplicated-weekdays", compact: "mon mon"},
+	} {
+		specs = append(specs, model.TimeIntervalSpec{Name: invalid.name, CompactIntervals: []string{invalid.compact}})
+	}
+
+	conversions := make([]intervalConversion, 0, len(specs))
+	for _, spec := range specs {
+		conversion := intervalConversion{Name: spec.Name}
+		domain, err := convertToDomainModel(&model.TimeInterval{Spec: spec})
+		if err != nil {
+			conversion.Error = err.Error()
+		} else {
+			k8s, err := convertToK8sResource(1, domain, request.GetNamespaceMapper(nil))
+			require.NoError(t, err, spec.Name)
+			conversion.Spec = &k8s.Spec
+		}
+		conversions = append(conversions, conversion)
+	}
+
+	goldenFile := filepath.Join("testdata", "interval_corpus.json")
+	conversionsJSON, err := json.MarshalIndent(conversions, "", "  ")
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile(goldenFile, conversionsJSON, 0600))
+	}
+	// nolint:gosec
+	want, err := os.ReadFile(goldenFile)
+	require.NoError(t, err)
+	require.JSONEqf(t, string(want), string(conversionsJSON), "not matched with golden file")
+}
+
+func TestConvertToDomainModel_CompactIntervals(t *testing.T) {
+	interval := &model.TimeInterval{
+		Spec: model.TimeIntervalSpec{
+			Name:             "business-hours",
+			TimeIntervals:    []model.Interval{{Weekdays: []string{"saturday"}}},
+			CompactIntervals: []string{"mon..fri 09:00..17:00 Europe/Paris"},
+		},
+	}
+	domain, err := convertToDomainModel(interval)
+	require.NoError(t, err)
+	require.Len(t, domain.TimeIntervals, 2)
+	require.Equal(t, "Europe/Paris", domain.TimeIntervals[1].Location.String())
+	require.Len(t, interval.Spec.CompactIntervals, 1, "the resource is not modified")
+
+	interval.Spec.CompactIntervals = []string{"mon..someday"}
+	_, err = convertToDomainModel(interval)
+	require.ErrorContains(t, err, "invalid compact interval")
+}
@@ -0,0 +1,210 @@
+package timeinterval
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util/errhttp"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// MaxCoverageWindow is the longest time window that the coverage of time intervals can be analyzed over.
+const MaxCoverageWindow = 31 * 24 * time.Hour
+
+var (
+	errInvalidCoverageRequest = errutil.BadRequest("alerting.timeInterval.invalidCoverageRequest")
+	errCoverageNotFound       = errutil.NotFound("alerting.timeInterval.notFound")
+)
+
+// CoverageRequest is the body of a coverage analysis request. The time intervals are the names of the TimeInterval
+// resources to analyze, all time intervals of the namespace are analyzed if it is empty.
+type CoverageRequest struct {
+	TimeIntervals []string  `json:"time_intervals,omitempty"`
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+}
+
+// CoverageRange is a range of time, the end is exclusive.
+type CoverageRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CoverageOverlap is a range of time when several time intervals are active at once.
+type CoverageOverlap struct {
+	CoverageRange `json:",inline"`
+	// TimeIntervals are the names of the time intervals active in the range.
+	TimeIntervals []string `json:"time_intervals"`
+}
+
+// CoverageReport describes how the time intervals cover a time window.
+type CoverageReport struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Overlaps []CoverageOverlap `json:"overlaps"`
+	Gaps     []CoverageRange   `json:"gaps"`
+	// CoveragePercent is the percentage of the time window when at least one time interval is active.
+	CoveragePercent float64 `json:"coverage_percent"`
+}
+
+// AnalyzeCoverage reports the ranges of the time window [from, to) when several of the time intervals are active, the
+// ranges when none of them is, and the percentage of the window covered by any of them. Time intervals have a
+// resolution of a minute, the window is evaluated minute by minute starting from the beginning of the minute of from.
+func AnalyzeCoverage(intervals []definitions.MuteTimeInterval, from, to time.Time) CoverageReport {
+	report := CoverageReport{
+		From:     from,
+		To:       to,
+		Overlaps: []CoverageOverlap{},
+		Gaps:     []CoverageRange{},
+	}
+	if !from.Before(to) {
+		return report
+	}
+
+	var (
+		covered     time.Duration
+		active      []string
+		activeStart time.Time
+	)
+	closeRange := func(end time.Time) {
+		switch {
+		case len(active) == 0:
+			report.Gaps = append(report.Gaps, CoverageRange{Start: activeStart, End: end})
+		case len(active) > 1:
+			report.Overlaps = append(report.Overlaps, CoverageOverlap{
+				CoverageRange: CoverageRange{Start: activeStart, End: end},
+				TimeIntervals: active,
+			})
+		}
+	}
+
+	start := from.Truncate(time.Minute)
+	for t := start; t.Before(to); t = t.Add(time.Minute) {
+		current := activeIntervals(intervals, t)
+		rangeStart := t
+		if rangeStart.Before(from) {
+			rangeStart = from
+		}
+		rangeEnd := t.Add(time.Minute)
+		if rangeEnd.After(to) {
+			rangeEnd = to
+		}
+		if len(current) > 0 {
+			covered += rangeEnd.Sub(rangeStart)
+		}
+		if t.Equal(start) {
+			active, activeStart = current, rangeStart
+			continue
+		}
+		if !slices.Equal(active, current) {
+			closeRange(rangeStart)
+			active, activeStart = current, rangeStart
+		}
+	}
+	closeRange(to)
+
+	report.CoveragePercent = float64(covered) / float64(to.Sub(from)) * 100
+	return report
+}
+
+// activeIntervals returns the names of the time intervals that are active at the given time, in the order of the
+// time intervals.
+func activeIntervals(intervals []definitions.MuteTimeInterval, t time.Time) []string {
+	var result []string
+	for _, interval := range intervals {
+		for _, ti := range interval.TimeIntervals {
+			if ti.ContainsTime(t) {
+				result = append(result, interval.Name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// CoverageHandler returns the handler of the coverage analysis of the time intervals of a namespace. Only the time
+// intervals the user is allowed to read are analyzed.
+func CoverageHandler(service TimeIntervalService, authz AccessControlService, namespacer request.NamespaceMapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		user, err := identity.GetRequester(ctx)
+		if err != nil {
+			errhttp.Write(ctx, err, w)
+			return
+		}
+		orgID, err := notifications.OrgIDForNamespace(mux.Vars(r)["namespace"], namespacer)
+		if err != nil || orgID != user.GetOrgID() {
+			errhttp.Write(ctx, errInvalidCoverageRequest.Errorf("namespace does not match the organization of the user"), w)
+			return
+		}
+
+		cmd := CoverageRequest{}
+		if err := web.Bind(r, &cmd); err != nil {
+			errhttp.Write(ctx, errInvalidCoverageRequest.Errorf("invalid request body: %w", err), w)
+			return
+		}
+		if !cmd.From.Before(cmd.To) {
+			errhttp.Write(ctx, errInvalidCoverageRequest.Errorf("from must be before to"), w)
+			return
+		}
+		if cmd.To.Sub(cmd.From) > MaxCoverageWindow {
+			errhttp.Write(ctx, errInvalidCoverageRequest.Errorf("time window must not be longer than %s", MaxCoverageWindow), w)
+			return
+		}
+
+		intervals, err := service.GetMuteTimings(ctx, orgID)
+		if err != nil {
+			errhttp.Write(ctx, err, w)
+			return
+		}
+		intervals, err = authz.FilterRead(ctx, user, intervals...)
+		if err != nil {
+			errhttp.Write(ctx, err, w)
+			return
+		}
+		intervals, err = selectIntervals(intervals, cmd.TimeIntervals)
+		if err != nil {
+			errhttp.Write(ctx, err, w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(AnalyzeCoverage(intervals, cmd.From, cmd.To))
+	}
+}
+
+// selectIntervals returns the time intervals with the given resource names, in the order of the names. All time
+// intervals are returned if no name is given.
+func selectIntervals(intervals []definitions.MuteTimeInterval, names []string) ([]definitions.MuteTimeInterval, error) {
+	if len(names) == 0 {
+		return intervals, nil
+	}
+	result := make([]definitions.MuteTimeInterval, 0, len(names))
+	var missing []string
+	for _, name := range names {
+		idx := slices.IndexFunc(intervals, func(mt definitions.MuteTimeInterval) bool {
+			return mt.UID == name
+		})
+		if idx < 0 {
+			missing = append(missing, name)
+			continue
+		}
+		result = append(result, intervals[idx])
+	}
+	if len(missing) > 0 {
+		return nil, errCoverageNotFound.Errorf("time intervals not found: %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package timeinterval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	model "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestAnalyzeCoverage(t *testing.T) {
+	interval := func(name string, compact ...string) definitions.MuteTimeInterval {
+		t.Helper()
+		result, err := convertToDomainModel(&model.TimeInterval{
+			Spec: model.TimeIntervalSpec{Name: name, CompactIntervals: compact},
+		})
+		require.NoError(t, err)
+		return result
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.January, day, hour, 0, 0, 0, time.UTC) // January 1st 2024 is a Monday.
+	}
+
+	t.Run("reports overlaps and gaps over a week", func(t *testing.T) {
+		intervals := []definitions.MuteTimeInterval{
+			interval("business-hours", "mon..fri 09:00..17:00"),
+			interval("lunch", "mon..fri 12:00..13:00"),
+		}
+		report := AnalyzeCoverage(intervals, at(1, 0), at(8, 0))
+
+		require.Len(t, report.Overlaps, 5)
+		for day, overlap := range report.Overlaps {
+			assert.Equal(t, CoverageRange{Start: at(day+1, 12), End: at(day+1, 13)}, overlap.CoverageRange)
+			assert.Equal(t, []string{"business-hours", "lunch"}, overlap.TimeIntervals)
+		}
+
+		assert.Equal(t, []CoverageRange{
+			{Start: at(1, 0), End: at(1, 9)},
+			{Start: at(1, 17), End: at(2, 9)},
+			{Start: at(2, 17), End: at(3, 9)},
+			{Start: at(3, 17), End: at(4, 9)},
+			{Start: at(4, 17), End: at(5, 9)},
+			{Start: at(5, 17), End: at(8, 0)},
+		}, report.Gaps)
+		assert.InDelta(t, 40.0/168.0*100, report.CoveragePercent, 0.0001)
+	})
+
+	t.Run("reports full coverage when an interval is always active", func(t *testing.T) {
+		intervals := []definitions.MuteTimeInterval{
+			interval("always", "*"),
+			interval("weekends", "sat..sun"),
+		}
+		from := at(1, 0).Add(30 * time.Second)
+		report := AnalyzeCoverage(intervals[:1], from, at(8, 0))
+		assert.Empty(t, report.Gaps)
+		assert.Empty(t, report.Overlaps)
+		assert.Equal(t, 100.0, report.CoveragePercent)
+
+		report = AnalyzeCoverage(intervals, from, at(8, 0))
+		require.Len(t, report.Overlaps, 1)
+		assert.Equal(t, CoverageRange{Start: at(6, 0), End: at(8, 0)}, report.Overlaps[0].CoverageRange)
+	})
+
+	t.Run("reports the whole window as a gap without intervals", func(t *testing.T) {
+		report := AnalyzeCoverage(nil, at(1, 0), at(2, 0))
+		assert.Equal(t, []CoverageRange{{Start: at(1, 0), End: at(2, 0)}}, report.Gaps)
+		assert.Zero(t, report.CoveragePercent)
+	})
+}
+
+func TestSelectIntervals(t *testing.T) {
+	intervals := []definitions.MuteTimeInterval{{UID: "a"}, {UID: "b"}, {UID: "c"}}
+
+	result, err := selectIntervals(intervals, nil)
+	require.NoError(t, err)
+	require.Equal(t, intervals, result)
+
+	result, err = selectIntervals(intervals, []string{"c", "a"})
+	require.NoError(t, err)
+	require.Equal(t, []definitions.MuteTimeInterval{{UID: "c"}, {UID: "a"}}, result)
+
+	_, err = selectIntervals(intervals, []string{"a", "missing"})
+	require.ErrorIs(t, err, errCoverageNotFound)
+}
@@ -0,0 +1,44 @@
+package timeinterval
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+)
+
+// OrgTimezoneProvider returns the timezone preference of the organization, e.g. Europe/Paris, utc or browser.
+type OrgTimezoneProvider func(ctx context.Context, orgID int64) (string, error)
+
+// orgLocation returns the location of the timezone preference of an organization. Preferences that are not a location,
+// e.g. browser, have no location.
+func orgLocation(timezone string) (string, bool) {
+	switch tz := strings.TrimSpace(timezone); strings.ToLower(tz) {
+	case "", "browser":
+		return "", false
+	case "utc":
+		return "UTC", true
+	default:
+		if _, err := time.LoadLocation(tz); err != nil {
+			return "", false
+		}
+		return tz, true
+	}
+}
+
+// applyDefaults defaults the intervals of a time interval on create, so that clients can submit minimal specs. Intervals
+// without location are in the timezone of the organization. Empty ranges, e.g. years, already match any value and are
+// kept empty.
+func applyDefaults(interval *notifications.TimeInterval, timezone string) {
+	location, ok := orgLocation(timezone)
+	if !ok {
+		return
+	}
+	for i := range interval.Spec.TimeIntervals {
+		if interval.Spec.TimeIntervals[i].Location == nil {
+			loc := location
+			interval.Spec.TimeIntervals[i].Location = &loc
+		}
+	}
+}
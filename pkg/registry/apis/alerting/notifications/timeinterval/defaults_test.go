@@ -0,0 +1,46 @@
+package timeinterval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	newInterval := func() *notifications.TimeInterval {
+		return &notifications.TimeInterval{
+			Spec: notifications.TimeIntervalSpec{
+				Name: "test",
+				TimeIntervals: []notifications.Interval{
+					{Weekdays: []string{"monday"}},
+					{Weekdays: []string{"sunday"}, Location: util.Pointer("America/New_York")},
+				},
+			},
+		}
+	}
+
+	t.Run("defaults location to the org timezone", func(t *testing.T) {
+		interval := newInterval()
+		applyDefaults(interval, "Europe/Paris")
+		require.Equal(t, "Europe/Paris", *interval.Spec.TimeIntervals[0].Location)
+		require.Equal(t, "America/New_York", *interval.Spec.TimeIntervals[1].Location)
+		require.Empty(t, interval.Spec.TimeIntervals[0].Years)
+	})
+
+	t.Run("utc org timezone", func(t *testing.T) {
+		interval := newInterval()
+		applyDefaults(interval, "utc")
+		require.Equal(t, "UTC", *interval.Spec.TimeIntervals[0].Location)
+	})
+
+	for _, timezone := range []string{"", "browser", "Not/A_Location"} {
+		t.Run("no location for timezone "+timezone, func(t *testing.T) {
+			interval := newInterval()
+			applyDefaults(interval, timezone)
+			require.Nil(t, interval.Spec.TimeIntervals[0].Location)
+		})
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/registry/rest"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	notifications "github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
 	grafanaRest "github.com/grafana/grafana/pkg/apiserver/rest"
+	"github.com/grafana/grafana/pkg/registry/apis/alerting/notifications/common"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -32,10 +34,25 @@ type TimeIntervalService interface {
 
 type legacyStorage struct {
 	service        TimeIntervalService
+	authz          AccessControlService
+	orgTimezone    OrgTimezoneProvider
 	namespacer     request.NamespaceMapper
 	tableConverter rest.TableConvertor
 }
 
+// authorize enforces the time interval actions in the storage, requests that are not authorized by the API server,
+// e.g. the requests of the dual writer, are denied as well.
+func (s *legacyStorage) authorize(ctx context.Context, check func(context.Context, identity.Requester) error) error {
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return errors.NewUnauthorized("valid user is required")
+	}
+	if err := check(ctx, user); err != nil {
+		return errors.NewForbidden(resourceInfo.GroupResource(), "", err)
+	}
+	return nil
+}
+
 func (s *legacyStorage) New() runtime.Object {
 	return resourceInfo.NewFunc()
 }
@@ -59,24 +76,37 @@ func (s *legacyStorage) ConvertToTable(ctx context.Context, object runtime.Objec
 }
 
 func (s *legacyStorage) List(ctx context.Context, opts *internalversion.ListOptions) (runtime.Object, error) {
-	orgId, err := request.OrgIDForList(ctx)
+	orgId, err := common.OrgIDForList(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, errors.NewUnauthorized("valid user is required")
+	}
 
 	res, err := s.service.GetMuteTimings(ctx, orgId)
 	if err != nil {
 		return nil, err
 	}
 
+	// Only the time intervals the user is allowed to read are listed.
+	res, err = s.authz.FilterRead(ctx, user, res...)
+	if err != nil {
+		return nil, errors.NewForbidden(resourceInfo.GroupResource(), "", err)
+	}
+
 	return convertToK8sResources(orgId, res, s.namespacer, opts.FieldSelector)
 }
 
 func (s *legacyStorage) Get(ctx context.Context, uid string, _ *metav1.GetOptions) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authorize(ctx, s.authz.AuthorizeRead); err != nil {
+		return nil, err
+	}
 
 	timings, err := s.service.GetMuteTimings(ctx, info.OrgID)
 	if err != nil {
@@ -96,19 +126,33 @@ func (s *legacyStorage) Create(ctx context.Context,
 	createValidation rest.ValidateObjectFunc,
 	_ *metav1.CreateOptions,
 ) (runtime.Object, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, err
 	}
-	if createValidation != nil {
-		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
-			return nil, err
-		}
+	if err := s.authorize(ctx, s.authz.AuthorizeWrite); err != nil {
+		return nil, err
 	}
 	p, ok := obj.(*notifications.TimeInterval)
 	if !ok {
 		return nil, fmt.Errorf("expected time-interval but got %s", obj.GetObjectKind().GroupVersionKind())
 	}
+	// Defaults are applied before the validation, like a mutating admission
+	if err := expandCompactIntervals(&p.Spec); err != nil {
+		return nil, err
+	}
+	if s.orgTimezone != nil {
+		timezone, err := s.orgTimezone(ctx, info.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		applyDefaults(p, timezone)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
 	if p.ObjectMeta.Name != "" { // TODO remove when metadata.name can be defined by user
 		return nil, errors.NewBadRequest("object's metadata.name should be empty")
 	}
@@ -131,10 +175,13 @@ func (s *legacyStorage) Update(ctx context.Context,
 	_ bool,
 	_ *metav1.UpdateOptions,
 ) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
+	if err := s.authorize(ctx, s.authz.AuthorizeWrite); err != nil {
+		return nil, false, err
+	}
 
 	old, err := s.Get(ctx, uid, nil)
 	if err != nil {
@@ -173,10 +220,13 @@ func (s *legacyStorage) Update(ctx context.Context,
 
 // GracefulDeleter
 func (s *legacyStorage) Delete(ctx context.Context, uid string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
-	info, err := request.NamespaceInfoFrom(ctx, true)
+	info, err := common.NamespaceInfo(ctx, s.namespacer)
 	if err != nil {
 		return nil, false, err
 	}
+	if err := s.authorize(ctx, s.authz.AuthorizeDelete); err != nil {
+		return nil, false, err
+	}
 	old, err := s.Get(ctx, uid, nil)
 	if err != nil {
 		return old, false, err
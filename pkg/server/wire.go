@@ -38,9 +38,12 @@ import (
 	apiregistry "github.com/grafana/grafana/pkg/registry/apis"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/debugsession"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/ossaccesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/sharetoken"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/anonstore"
@@ -194,6 +197,7 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(supportbundles.Service), new(*bundleregistry.Service)),
 	updatechecker.ProvideGrafanaService,
 	updatechecker.ProvidePluginsService,
+	updatechecker.ProvideSummaryService,
 	uss.ProvideService,
 	wire.Bind(new(usagestats.Service), new(*uss.UsageStats)),
 	validator.ProvideService,
@@ -320,8 +324,17 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(accesscontrol.FolderPermissionsService), new(*ossaccesscontrol.FolderPermissionsService)),
 	ossaccesscontrol.ProvideDashboardPermissions,
 	wire.Bind(new(accesscontrol.DashboardPermissionsService), new(*ossaccesscontrol.DashboardPermissionsService)),
+	ossaccesscontrol.ProvideLibraryPanelPermissions,
+	wire.Bind(new(accesscontrol.LibraryPanelPermissionsService), new(*ossaccesscontrol.LibraryPanelPermissionsService)),
+	ossaccesscontrol.ProvideAlertRulePermissionsService,
+	wire.Bind(new(accesscontrol.AlertRulePermissionsService), new(*ossaccesscontrol.AlertRulePermissionsService)),
 	ossaccesscontrol.ProvideReceiverPermissionsService,
 	wire.Bind(new(accesscontrol.ReceiverPermissionsService), new(*ossaccesscontrol.ReceiverPermissionsService)),
+	ossaccesscontrol.ProvidePluginResourcePermissions,
+	wire.Bind(new(pluginaccesscontrol.ResourcePermissionsRegistry), new(*ossaccesscontrol.PluginResourcePermissionsService)),
+	debugsession.ProvideStore,
+	sharetoken.ProvideService,
+	wire.Bind(new(accesscontrol.ShareTokenRevoker), new(*sharetoken.Service)),
 	starimpl.ProvideService,
 	playlistimpl.ProvideService,
 	apikeyimpl.ProvideService,
@@ -353,10 +366,13 @@ var wireBasicSet = wire.NewSet(
 	secretsMigrations.ProvideSecretMigrationProvider,
 	wire.Bind(new(secretsMigrations.SecretMigrationProvider), new(*secretsMigrations.SecretMigrationProviderImpl)),
 	resourcepermissions.NewActionSetService,
+	resourcepermissions.ProvideDefaultPermissionsPolicy,
 	wire.Bind(new(accesscontrol.ActionResolver), new(resourcepermissions.ActionSetService)),
 	wire.Bind(new(pluginaccesscontrol.ActionSetRegistry), new(resourcepermissions.ActionSetService)),
 	permreg.ProvidePermissionRegistry,
-	acimpl.ProvideAccessControl,
+	webhook.ProvideNotifier,
+	accesscontrol.ProvideWildcardRegistry,
+	acimpl.ProvideAccessControlWithSettings,
 	navtreeimpl.ProvideService,
 	wire.Bind(new(accesscontrol.AccessControl), new(*acimpl.AccessControl)),
 	wire.Bind(new(notifications.TempUserStore), new(tempuser.Service)),
@@ -2,9 +2,12 @@ package accesscontrol
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/authlib/claims"
 	"go.opentelemetry.io/otel"
@@ -12,6 +15,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/jobstatus"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -33,6 +37,8 @@ type AccessControl interface {
 	WithoutResolvers() AccessControl
 	Check(ctx context.Context, req CheckRequest) (bool, error)
 	ListObjects(ctx context.Context, req ListObjectsRequest) ([]string, error)
+	// ListUsers returns the users having the relation on the object
+	ListUsers(ctx context.Context, req ListUsersRequest) ([]string, error)
 }
 
 type Service interface {
@@ -65,6 +71,154 @@ type Service interface {
 	SyncUserRoles(ctx context.Context, orgID int64, cmd SyncUserRolesCommand) error
 }
 
+// Deprovisioner is implemented by services that can synchronously remove the access control state of
+// users and teams deprovisioned by an external identity provider (e.g. SCIM).
+type Deprovisioner interface {
+	// DeprovisionUser removes the user managed roles, role assignments and permissions in every org,
+	// the permissions scoped to the user and the tuples where the user is the subject.
+	DeprovisionUser(ctx context.Context, userID int64, userUID string) error
+	// DeprovisionTeam removes the team managed role, role assignments and permissions,
+	// the permissions scoped to the team and the tuples of the team and its memberships.
+	DeprovisionTeam(ctx context.Context, orgID, teamID int64, teamUID string) error
+}
+
+// TeamMembershipPreviewer is implemented by services that can report the access a user would gain by joining a team,
+// so the membership can be reviewed before it is granted.
+type TeamMembershipPreviewer interface {
+	// PreviewTeamMembership returns the permissions granted by the roles of the team, including its managed role,
+	// that the user does not already have in the org.
+	PreviewTeamMembership(ctx context.Context, orgID, userID, teamID int64) ([]Permission, error)
+}
+
+// TeamResourceCounter is implemented by services and stores that can count the resources teams have managed
+// permissions on, so team listings can show the access of each team without a query per team.
+type TeamResourceCounter interface {
+	// GetTeamsResourceCounts returns the number of resources of each kind, e.g. dashboards, the teams of the org
+	// have managed permissions on, indexed by team id. All teams of the org are counted when teamIDs is empty.
+	GetTeamsResourceCounts(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]map[string]int64, error)
+}
+
+// QuotaChecker is implemented by services enforcing the org quotas of roles and role assignments, so permissions
+// are not granted past the limits configured for the org.
+type QuotaChecker interface {
+	// CheckOrgQuota returns ErrQuotaReached when the org has reached its quota of roles or role assignments.
+	CheckOrgQuota(ctx context.Context, orgID int64) error
+}
+
+// UsersPermissionsStreamer is implemented by services that can search the permissions of all users of an org without
+// holding them in memory at once, which can take gigabytes on very large orgs.
+type UsersPermissionsStreamer interface {
+	// StreamUsersPermissions calls fn with the permissions of each user matching the options, the order of the users
+	// is not defined. The search stops at the first error returned by fn.
+	StreamUsersPermissions(ctx context.Context, user identity.Requester, options SearchOptions, fn func(userID int64, permissions []Permission) error) error
+	// CountUsersPermissions returns the number of permissions of each user matching the options.
+	CountUsersPermissions(ctx context.Context, user identity.Requester, options SearchOptions) (map[int64]int, error)
+}
+
+// RoleTupleMaintainer is implemented by services that keep the zanzana tuples referencing a role consistent
+// with the role lifecycle, tuples referencing a role uid that does not exist anymore grant nothing.
+type RoleTupleMaintainer interface {
+	// RoleUIDChanged rewrites the tuples referencing the role with oldUID to reference newUID.
+	// It is used when a role is recreated or its uid is regenerated.
+	RoleUIDChanged(ctx context.Context, orgID int64, oldUID, newUID string) error
+	// RoleDeleted removes the tuples assigning the role and the tuples granting its permissions.
+	RoleDeleted(ctx context.Context, orgID int64, roleUID string) error
+}
+
+// ResourceContainerTupleMaintainer is implemented by services that keep the zanzana tuples relating a resource to the
+// folder containing it consistent when the resource moves.
+type ResourceContainerTupleMaintainer interface {
+	// ResourceMoved schedules the replacement of the tuple relating the resource to its folder with one relating it to
+	// the folder containing it once the transaction of the move on ctx, if any, is committed.
+	ResourceMoved(ctx context.Context, orgID int64, resource, resourceID string) error
+}
+
+// BackgroundJobRunner is implemented by services running the background jobs of other services under their lifecycle.
+type BackgroundJobRunner interface {
+	// RunBackgroundJob runs fn in the background with a context cancelled when the service stops, fn is started once
+	// the service runs when it isn't running yet. The runs of fn are reported as the job name.
+	RunBackgroundJob(name string, fn func(ctx context.Context) error)
+}
+
+// ResourceAssignment identifies the managed permission of an assignee on a resource, the assignee is either a user,
+// a team, a built-in role or an external group.
+type ResourceAssignment struct {
+	Resource    string
+	ResourceID  string
+	UserID      int64
+	TeamID      int64
+	BuiltInRole string
+	GroupID     string
+}
+
+// OrgRoleSyncer is implemented by services that keep the zanzana basic role assignments of users consistent
+// with their org role and Grafana Admin flag, the assignment of a previous role would otherwise keep granting
+// its permissions.
+type OrgRoleSyncer interface {
+	// OrgRoleChanged syncs the basic role assignments of the user in the org with its membership as stored once the
+	// transaction on ctx, when it has one, is committed. It is called whenever a membership is added, updated or removed.
+	OrgRoleChanged(ctx context.Context, orgID, userID int64) error
+	// GrafanaAdminChanged syncs the basic role assignments of the user in all its orgs once its Grafana Admin flag
+	// changed, see OrgRoleChanged.
+	GrafanaAdminChanged(ctx context.Context, userID int64) error
+}
+
+// ExternalGroupSyncer is implemented by services that store the external identity provider groups of users,
+// permissions granted to a group apply to its members without being copied to their managed roles.
+type ExternalGroupSyncer interface {
+	// SyncUserExternalGroups replaces the external groups the user is a member of and returns true when they changed.
+	// Memberships are only stored once a permission is granted to a group, users get the permissions of their groups
+	// on their next login.
+	SyncUserExternalGroups(ctx context.Context, userID int64, groups []string) (bool, error)
+}
+
+// TemporaryPermissionRevoker is implemented by stores that can revoke expired temporary permission grants.
+type TemporaryPermissionRevoker interface {
+	// RevokeExpiredTemporaryPermissions removes the permissions granted by temporary grants expired at now
+	// together with the grants and returns the revoked grants. onRevoke, when set, is called with the revoked grants
+	// in the transaction removing them, e.g. to enqueue the removal of their tuples.
+	RevokeExpiredTemporaryPermissions(ctx context.Context, now time.Time, onRevoke func(ctx context.Context, revoked []TemporaryPermission) error) ([]TemporaryPermission, error)
+}
+
+// ActionAliasRewriter is implemented by stores that can rewrite the permissions stored with the previous name of a
+// renamed action, see ActionAliases.
+type ActionAliasRewriter interface {
+	// RewriteActionAliases renames up to limit permissions to the current name of their action and returns the
+	// number of permissions rewritten.
+	RewriteActionAliases(ctx context.Context, limit int) (int, error)
+}
+
+// JobStatusReporter is implemented by services running background jobs that maintain the permissions, e.g. the
+// zanzana sync and reconciliation or the revocation of expired temporary permissions.
+type JobStatusReporter interface {
+	// JobStatuses returns the status of the background jobs run by the instance.
+	JobStatuses() []jobstatus.Status
+}
+
+// UserPermissionsRevoker is implemented by services that can revoke the access of a user to all the resources of a
+// type at once, e.g. to offboard a user from the data sources.
+type UserPermissionsRevoker interface {
+	// RevokeUserPermissionsByResourceType removes the managed and temporary permissions of the user in the org on
+	// the resources of the type, and the tuples granting them. Access granted by teams and roles is kept.
+	RevokeUserPermissionsByResourceType(ctx context.Context, orgID, userID int64, resource string) error
+}
+
+// UserResourcePermissionsRevoker is implemented by stores that can revoke the access of a user to all the resources
+// of a type at once.
+type UserResourcePermissionsRevoker interface {
+	// RevokeUserResourcePermissions removes the managed and temporary permissions of the user in the org on the
+	// resources of the type and returns the ids of the resources the user had a permission on. onRevoke, when set,
+	// is called with these ids in the transaction removing the permissions, e.g. to enqueue the removal of their tuples.
+	RevokeUserResourcePermissions(ctx context.Context, orgID, userID int64, resource string, onRevoke func(ctx context.Context, resourceIDs []string) error) ([]string, error)
+}
+
+// ShareTokenRevoker revokes the share tokens of the resources being deleted, so the tokens don't grant access to
+// a resource created later with the same uid.
+type ShareTokenRevoker interface {
+	// RevokeResourceTokens revokes all the tokens sharing the resource of the uid scope in the org.
+	RevokeResourceTokens(ctx context.Context, orgID int64, scope string) error
+}
+
 //go:generate  mockery --name Store --structname MockStore --outpkg actest --filename store_mock.go --output ./actest/
 type Store interface {
 	GetUserPermissions(ctx context.Context, query GetUserPermissionsQuery) ([]Permission, error)
@@ -92,7 +246,7 @@ type SearchOptions struct {
 	Action       string
 	ActionSets   []string
 	Scope        string
-	TypedID      string    // ID of the identity (ex: user:3, service-account:4)
+	TypedID      string    // ID of the identity (ex: user:3, service-account:4, render:0)
 	wildcards    Wildcards // private field computed based on the Scope
 	RolePrefixes []string
 }
@@ -112,8 +266,40 @@ func (s *SearchOptions) Wildcards() []string {
 	return s.wildcards
 }
 
-func (s *SearchOptions) ComputeUserID() (int64, error) {
+// ResolveWildcards computes the wildcard scopes that include the scope with the wildcards declared in the registry,
+// Wildcards only returns the wildcards derived from the scope otherwise.
+func (s *SearchOptions) ResolveWildcards(registry *WildcardRegistry) {
+	if s.Scope == "" {
+		return
+	}
+	s.wildcards = registry.ForScope(s.Scope)
+}
+
+// ComputeIdentity resolves TypedID into its identity type and numeric id.
+// Users and service accounts resolve to their user id. The render service is
+// not backed by a stored user and always resolves to 0.
+func (s *SearchOptions) ComputeIdentity() (claims.IdentityType, int64, error) {
 	typ, id, err := identity.ParseTypeAndID(s.TypedID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	switch {
+	case claims.IsIdentityType(typ, claims.TypeRenderService):
+		return typ, 0, nil
+	case claims.IsIdentityType(typ, claims.TypeUser, claims.TypeServiceAccount):
+		userID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return "", 0, err
+		}
+		return typ, userID, nil
+	default:
+		return "", 0, fmt.Errorf("invalid type: %s", typ)
+	}
+}
+
+func (s *SearchOptions) ComputeUserID() (int64, error) {
+	typ, id, err := s.ComputeIdentity()
 	if err != nil {
 		return 0, err
 	}
@@ -122,7 +308,7 @@ func (s *SearchOptions) ComputeUserID() (int64, error) {
 		return 0, fmt.Errorf("invalid type: %s", typ)
 	}
 
-	return strconv.ParseInt(id, 10, 64)
+	return id, nil
 }
 
 type SyncUserRolesCommand struct {
@@ -147,6 +333,10 @@ type DashboardPermissionsService interface {
 	PermissionsService
 }
 
+type LibraryPanelPermissionsService interface {
+	PermissionsService
+}
+
 type DatasourcePermissionsService interface {
 	PermissionsService
 }
@@ -159,6 +349,12 @@ type ReceiverPermissionsService interface {
 	PermissionsService
 	SetDefaultPermissions(ctx context.Context, orgID int64, user identity.Requester, uid string)
 	CopyPermissions(ctx context.Context, orgID int64, user identity.Requester, oldUID, newUID string) (int, error)
+	RenamePermissions(ctx context.Context, orgID int64, user identity.Requester, oldUID, newUID string) error
+}
+
+type AlertRulePermissionsService interface {
+	PermissionsService
+	SetDefaultPermissions(ctx context.Context, orgID int64, user identity.Requester, uid string)
 }
 
 type PermissionsService interface {
@@ -176,6 +372,10 @@ type PermissionsService interface {
 	MapActions(permission ResourcePermission) string
 	// DeleteResourcePermissions removes all permissions for a resource
 	DeleteResourcePermissions(ctx context.Context, orgID int64, resourceID string) error
+	// DefaultPermissions returns the permissions to set on a newly created resource
+	DefaultPermissions(ctx context.Context, orgID int64, attrs DefaultPermissionsAttributes) ([]SetResourcePermissionCommand, error)
+	// MoveResource is called once a resource moved to another folder, it returns the direct permissions pruned
+	MoveResource(ctx context.Context, orgID int64, cmd MoveResourceCommand) ([]ResourcePermission, error)
 }
 
 type User struct {
@@ -356,6 +556,17 @@ func ManagedBuiltInRoleName(builtInRole string) string {
 	return fmt.Sprintf("managed:builtins:%s:permissions", strings.ToLower(builtInRole))
 }
 
+func ManagedGroupRoleName(groupID string) string {
+	return fmt.Sprintf("managed:groups:%s:permissions", ExternalGroupUID(groupID))
+}
+
+// ExternalGroupUID returns a stable identifier for the name of an external group.
+// Group names can contain any character, the identifier is safe to use in role names and tuples.
+func ExternalGroupUID(groupID string) string {
+	sum := sha256.Sum256([]byte(groupID))
+	return hex.EncodeToString(sum[:16])
+}
+
 // GetOrgRoles returns legacy org roles for a user
 func GetOrgRoles(user identity.Requester) []string {
 	roles := []string{string(user.GetOrgRole())}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/grafana/authlib/claims"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -179,3 +180,33 @@ func BenchmarkGroupScopesByAction(b *testing.B) {
 		GroupScopesByActionContext(context.Background(), permissions)
 	}
 }
+
+func TestSearchOptions_ComputeIdentity(t *testing.T) {
+	tests := []struct {
+		name         string
+		typedID      string
+		expectedType claims.IdentityType
+		expectedID   int64
+		expectErr    bool
+	}{
+		{name: "user", typedID: "user:3", expectedType: claims.TypeUser, expectedID: 3},
+		{name: "service account", typedID: "service-account:4", expectedType: claims.TypeServiceAccount, expectedID: 4},
+		{name: "render service", typedID: "render:0", expectedType: claims.TypeRenderService, expectedID: 0},
+		{name: "api key", typedID: "api-key:1", expectErr: true},
+		{name: "invalid id", typedID: "user:abc", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := SearchOptions{TypedID: tt.typedID}
+			typ, id, err := options.ComputeIdentity()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedType, typ)
+			assert.Equal(t, tt.expectedID, id)
+		})
+	}
+}
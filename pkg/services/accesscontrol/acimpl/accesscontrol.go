@@ -3,12 +3,13 @@ package acimpl
 import (
 	"context"
 	"errors"
-	"strconv"
+	"strings"
 	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/authlib/claims"
 
@@ -16,10 +17,15 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/debugsession"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
+// batchCheckConcurrency is the number of checks of a batch sent to zanzana concurrently
+const batchCheckConcurrency = 10
+
 var (
 	errAccessNotImplemented = errors.New("access control not implemented for resource")
 	tracer                  = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/acimpl")
@@ -36,14 +42,22 @@ func ProvideAccessControl(features featuremgmt.FeatureToggles, zclient zanzana.C
 	}
 
 	return &AccessControl{
-		features,
-		logger,
-		accesscontrol.NewResolvers(logger),
-		zclient,
-		m,
+		features:  features,
+		log:       logger,
+		resolvers: accesscontrol.NewResolvers(logger),
+		zclient:   zclient,
+		metrics:   m,
 	}
 }
 
+// ProvideAccessControlWithSettings returns the access control that evaluates the permissions with zanzana only,
+// instead of comparing it with the permissions of the users, when zanzana_only_evaluation is enabled.
+func ProvideAccessControlWithSettings(cfg *setting.Cfg, features featuremgmt.FeatureToggles, zclient zanzana.Client) *AccessControl {
+	a := ProvideAccessControl(features, zclient)
+	a.zanzanaOnly = cfg.Zanzana.ZanzanaOnlyEvaluation
+	return a
+}
+
 func ProvideAccessControlTest() *AccessControl {
 	return ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
 }
@@ -54,17 +68,27 @@ type AccessControl struct {
 	resolvers accesscontrol.Resolvers
 	zclient   zanzana.Client
 	metrics   *acMetrics
+	// zanzanaOnly skips the comparison with the permissions of the users when zanzana is enabled
+	zanzanaOnly bool
 }
 
 func (a *AccessControl) Evaluate(ctx context.Context, user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.Evaluate")
 	defer span.End()
 
-	if a.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
-		return a.evaluateCompare(ctx, user, evaluator)
+	var allowed bool
+	var err error
+	switch {
+	case a.features.IsEnabledGlobally(featuremgmt.FlagZanzana) && a.zanzanaOnly:
+		allowed, err = a.evaluateZanzanaOnly(ctx, user, evaluator)
+	case a.features.IsEnabledGlobally(featuremgmt.FlagZanzana):
+		allowed, err = a.evaluateCompare(ctx, user, evaluator)
+	default:
+		allowed, err = a.evaluate(ctx, user, evaluator)
 	}
 
-	return a.evaluate(ctx, user, evaluator)
+	debugsession.RecordEvaluation(ctx, evaluator, allowed, err)
+	return allowed, err
 }
 
 func (a *AccessControl) evaluate(ctx context.Context, user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
@@ -122,7 +146,7 @@ func (a *AccessControl) evaluateZanzana(ctx context.Context, user identity.Reque
 
 	return eval.EvaluateCustom(func(action, scope string) (bool, error) {
 		kind, _, identifier := accesscontrol.SplitScope(scope)
-		tupleKey, ok := zanzana.TranslateToTuple(user.GetUID(), action, kind, identifier, user.GetOrgID())
+		tupleKey, ok := zanzana.TranslateToTuple(zanzanaSubject(user), action, kind, identifier, user.GetOrgID())
 		if !ok {
 			// unsupported translation
 			return false, errAccessNotImplemented
@@ -144,6 +168,132 @@ func (a *AccessControl) evaluateZanzana(ctx context.Context, user identity.Reque
 	})
 }
 
+// evaluateZanzanaOnly evaluates the permissions that translate to a relation with zanzana. The others, e.g. scopeless
+// or wildcard permissions and kinds that are not part of the schema, are evaluated against the permissions of the user
+// so middlewares and plugins keep the action and scope semantics of the evaluators.
+//
+// The checks of the evaluator are collected first and sent together with batchCheck. A folder scope following the scope
+// of a resource in its resolved scopes is its parent folder, it is checked through the resource. Checks that were not
+// collected, e.g. after the first permission of an EvalAll that isn't granted, are sent on their own.
+func (a *AccessControl) evaluateZanzanaOnly(ctx context.Context, user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.evaluateZanzanaOnly")
+	defer span.End()
+
+	if user == nil || user.IsNil() {
+		a.log.Warn("No entity set for access control evaluation")
+		return false, nil
+	}
+
+	permissions := user.GetPermissions()
+	if user.GetOrgID() == accesscontrol.NoOrgID {
+		permissions = user.GetGlobalPermissions()
+	}
+
+	eval, err := evaluator.MutateScopes(ctx, a.resolvers.GetScopeAttributeMutator(user.GetOrgID()))
+	if err != nil {
+		if !errors.Is(err, accesscontrol.ErrResolverNotFound) {
+			return false, err
+		}
+		eval = evaluator
+	}
+
+	subject := zanzanaSubject(user)
+	translate := func(action, scope string) (accesscontrol.CheckRequest, bool) {
+		kind, attribute, identifier := accesscontrol.SplitScope(scope)
+		if scope == "" || attribute == "*" || identifier == "*" {
+			return accesscontrol.CheckRequest{}, false
+		}
+		tupleKey, ok := zanzana.TranslateToTuple(subject, action, kind, identifier, user.GetOrgID())
+		if !ok {
+			return accesscontrol.CheckRequest{}, false
+		}
+		objectType, _ := zanzana.TranslateKindToType(kind)
+		return accesscontrol.CheckRequest{
+			Namespace:  claims.OrgNamespaceFormatter(user.GetOrgID()),
+			User:       tupleKey.User,
+			Relation:   tupleKey.Relation,
+			Object:     tupleKey.Object,
+			ObjectType: objectType,
+		}, true
+	}
+
+	// Collect the checks, the collector doesn't grant anything so every scope of the permissions is visited
+	checks := map[string]*zanzanaCheck{}
+	var batch []*zanzanaCheck
+	var last *zanzanaCheck
+	var lastAction string
+	_, _ = eval.EvaluateCustom(func(action, scope string) (bool, error) {
+		key := action + " " + scope
+		if _, ok := checks[key]; ok {
+			return false, nil
+		}
+		req, ok := translate(action, scope)
+		if !ok {
+			last = nil
+			return false, nil
+		}
+
+		kind, _, identifier := accesscontrol.SplitScope(scope)
+		if kind == zanzana.KindFolders && last != nil && last.req.Parent == "" && lastAction == action {
+			// The resource is granted the action through its parent folder
+			if _, ok := zanzana.TranslateToContainerTuple(subject, last.req.Relation, last.req.ObjectType, kind, identifier, user.GetOrgID()); ok {
+				last.req.Parent = identifier
+				checks[key] = last
+				return false, nil
+			}
+		}
+
+		c := &zanzanaCheck{req: req}
+		checks[key] = c
+		batch = append(batch, c)
+		last, lastAction = c, action
+		return false, nil
+	})
+
+	if err := a.batchCheck(ctx, batch); err != nil {
+		return false, err
+	}
+
+	return eval.EvaluateCustom(func(action, scope string) (bool, error) {
+		if c, ok := checks[action+" "+scope]; ok {
+			return c.allowed, nil
+		}
+		req, ok := translate(action, scope)
+		if !ok {
+			return accesscontrol.EvalPermission(action, scope).Evaluate(permissions), nil
+		}
+		return a.Check(ctx, req)
+	})
+}
+
+// zanzanaCheck is a check of an evaluation and its result.
+type zanzanaCheck struct {
+	req     accesscontrol.CheckRequest
+	allowed bool
+}
+
+// batchCheck runs the checks concurrently and sets their result.
+func (a *AccessControl) batchCheck(ctx context.Context, checks []*zanzanaCheck) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.batchCheck")
+	defer span.End()
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(batchCheckConcurrency)
+	for _, c := range checks {
+		g.Go(func() error {
+			allowed, err := a.Check(ctx, c.req)
+			c.allowed = allowed
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+// zanzanaSubject returns the subject of the tuples of the user, service accounts are users in zanzana.
+func zanzanaSubject(user identity.Requester) string {
+	return zanzana.NewTupleEntry(zanzana.TypeUser, user.GetRawIdentifier(), "")
+}
+
 type evalResult struct {
 	runner   string
 	decision bool
@@ -209,11 +359,12 @@ func (a *AccessControl) RegisterScopeAttributeResolver(prefix string, resolver a
 
 func (a *AccessControl) WithoutResolvers() accesscontrol.AccessControl {
 	return &AccessControl{
-		features:  a.features,
-		log:       a.log,
-		zclient:   a.zclient,
-		metrics:   a.metrics,
-		resolvers: accesscontrol.NewResolvers(a.log),
+		features:    a.features,
+		log:         a.log,
+		zclient:     a.zclient,
+		metrics:     a.metrics,
+		resolvers:   accesscontrol.NewResolvers(a.log),
+		zanzanaOnly: a.zanzanaOnly,
 	}
 }
 
@@ -237,6 +388,7 @@ func (a *AccessControl) Check(ctx context.Context, req accesscontrol.CheckReques
 
 	// Check direct access to resource first
 	res, err := a.zclient.Check(ctx, in)
+	debugsession.RecordCheck(ctx, checkEntry(key), res.GetAllowed(), err)
 	if err != nil {
 		return false, err
 	}
@@ -246,23 +398,46 @@ func (a *AccessControl) Check(ctx context.Context, req accesscontrol.CheckReques
 		return res.Allowed, nil
 	}
 
+	// Access inherited from the folder doesn't apply to denied subjects
+	if zanzana.HasDenyRelation(req.ObjectType) {
+		denyReq := &openfgav1.CheckRequest{
+			TupleKey: &openfgav1.CheckRequestTupleKey{
+				User:     req.User,
+				Relation: zanzana.RelationDeny,
+				Object:   req.Object,
+			},
+		}
+		denyRes, err := a.zclient.Check(ctx, denyReq)
+		debugsession.RecordCheck(ctx, checkEntry(denyReq.GetTupleKey()), denyRes.GetAllowed(), err)
+		if err != nil {
+			return false, err
+		}
+		if denyRes.Allowed {
+			return false, nil
+		}
+	}
+
 	// Check access through the parent folder
 	ns, err := claims.ParseNamespace(req.Namespace)
 	if err != nil {
 		return false, err
 	}
 
-	folderKey := &openfgav1.CheckRequestTupleKey{
-		User:     req.User,
-		Relation: zanzana.TranslateToFolderRelation(req.Relation, req.ObjectType),
-		Object:   zanzana.NewScopedTupleEntry(zanzana.TypeFolder, req.Parent, "", strconv.FormatInt(ns.OrgID, 10)),
+	folder, ok := zanzana.TranslateToContainerTuple(req.User, req.Relation, req.ObjectType, zanzana.KindFolders, req.Parent, ns.OrgID)
+	if !ok {
+		return false, nil
 	}
 
 	folderReq := &openfgav1.CheckRequest{
-		TupleKey: folderKey,
+		TupleKey: &openfgav1.CheckRequestTupleKey{
+			User:     folder.User,
+			Relation: folder.Relation,
+			Object:   folder.Object,
+		},
 	}
 
 	folderRes, err := a.zclient.Check(ctx, folderReq)
+	debugsession.RecordCheck(ctx, checkEntry(folderReq.GetTupleKey()), folderRes.GetAllowed(), err)
 	if err != nil {
 		return false, err
 	}
@@ -270,6 +445,10 @@ func (a *AccessControl) Check(ctx context.Context, req accesscontrol.CheckReques
 	return folderRes.Allowed, nil
 }
 
+func checkEntry(key *openfgav1.CheckRequestTupleKey) string {
+	return key.GetUser() + " " + key.GetRelation() + " " + key.GetObject()
+}
+
 func (a *AccessControl) ListObjects(ctx context.Context, req accesscontrol.ListObjectsRequest) ([]string, error) {
 	in := &openfgav1.ListObjectsRequest{
 		Type:     req.Type,
@@ -282,3 +461,24 @@ func (a *AccessControl) ListObjects(ctx context.Context, req accesscontrol.ListO
 	}
 	return res.Objects, err
 }
+
+func (a *AccessControl) ListUsers(ctx context.Context, req accesscontrol.ListUsersRequest) ([]string, error) {
+	objectType, objectID, _ := strings.Cut(req.Object, ":")
+	in := &openfgav1.ListUsersRequest{
+		Object:      &openfgav1.Object{Type: objectType, Id: objectID},
+		Relation:    req.Relation,
+		UserFilters: []*openfgav1.UserTypeFilter{{Type: zanzana.TypeUser}},
+	}
+	res, err := a.zclient.ListUsers(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0, len(res.GetUsers()))
+	for _, u := range res.GetUsers() {
+		if obj := u.GetObject(); obj != nil {
+			users = append(users, zanzana.NewTupleEntry(obj.GetType(), obj.GetId(), ""))
+		}
+	}
+	return users, nil
+}
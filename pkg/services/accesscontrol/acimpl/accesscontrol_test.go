@@ -4,13 +4,17 @@ import (
 	"context"
 	"testing"
 
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestAccessControl_Evaluate(t *testing.T) {
@@ -82,3 +86,110 @@ func TestAccessControl_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+type fakeZanzanaClient struct {
+	zanzana.Client
+	t *testing.T
+	// subject is the user expected in the checks
+	subject string
+	// allowed holds the objects and relations allowed by Check, as object#relation
+	allowed map[string]bool
+}
+
+func (c *fakeZanzanaClient) Check(ctx context.Context, in *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
+	assert.Equal(c.t, c.subject, in.GetTupleKey().GetUser())
+	return &openfgav1.CheckResponse{Allowed: c.allowed[in.GetTupleKey().GetObject()+"#"+in.GetTupleKey().GetRelation()]}, nil
+}
+
+func TestAccessControl_EvaluateZanzanaOnly(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.Zanzana.ZanzanaOnlyEvaluation = true
+	client := &fakeZanzanaClient{
+		Client:  zanzana.NewNoopClient(),
+		t:       t,
+		subject: "user:u1",
+		allowed: map[string]bool{"dashboard:1-d1#read": true, "folder:1-f1#dashboard_read": true, "dashboard:1-d4#deny": true},
+	}
+	ac := acimpl.ProvideAccessControlWithSettings(cfg, featuremgmt.WithFeatures(featuremgmt.FlagZanzana), client)
+	// d3 and d4 are in f1, the other dashboards in f2
+	ac.RegisterScopeAttributeResolver(dashboards.ScopeDashboardsProvider.GetResourceScopeUID(""), accesscontrol.ScopeAttributeResolverFunc(
+		func(ctx context.Context, orgID int64, scope string) ([]string, error) {
+			if scope == dashboards.ScopeDashboardsAll {
+				return []string{scope}, nil
+			}
+			parent := "f2"
+			if scope == dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d3") || scope == dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d4") {
+				parent = "f1"
+			}
+			return []string{scope, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent)}, nil
+		}))
+
+	usr := &user.SignedInUser{
+		UserID:  1,
+		UserUID: "u1",
+		OrgID:   1,
+		Permissions: map[int64]map[string][]string{
+			1: {
+				accesscontrol.ActionTeamsWrite:  {"teams:*"},
+				dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsAll},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc      string
+		evaluator accesscontrol.Evaluator
+		expected  bool
+	}{
+		{
+			desc:      "should use zanzana for actions translated to a relation",
+			evaluator: accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d1")),
+			expected:  true,
+		},
+		{
+			desc:      "should not use the permissions of the user for actions translated to a relation",
+			evaluator: accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d2")),
+			expected:  false,
+		},
+		{
+			desc:      "should use zanzana for resources granted through their parent folder",
+			evaluator: accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d3")),
+			expected:  true,
+		},
+		{
+			desc:      "should not inherit access from the parent folder of resources the user is denied",
+			evaluator: accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d4")),
+			expected:  false,
+		},
+		{
+			desc: "should batch the checks of all the permissions",
+			evaluator: accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d1")),
+				accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID("d2")),
+			),
+			expected: false,
+		},
+		{
+			desc:      "should use the permissions of the user for kinds that are not part of the schema",
+			evaluator: accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite, "teams:id:1"),
+			expected:  true,
+		},
+		{
+			desc:      "should use the permissions of the user for wildcard scopes",
+			evaluator: accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsAll),
+			expected:  true,
+		},
+		{
+			desc:      "should use the permissions of the user for scopeless actions",
+			evaluator: accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead),
+			expected:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			hasAccess, err := ac.Evaluate(context.Background(), usr, tt.evaluator)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hasAccess)
+		})
+	}
+}
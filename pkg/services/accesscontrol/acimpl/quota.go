@@ -0,0 +1,107 @@
+package acimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type roleCounter interface {
+	CountRoles(ctx context.Context, orgID int64) (roles int64, assignments int64, err error)
+}
+
+func (s *Service) registerQuota(cfg *setting.Cfg, quotaService quota.Service) error {
+	if quotaService == nil {
+		return nil
+	}
+
+	defaultLimits, err := readQuotaConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     accesscontrol.QuotaTargetSrv,
+		DefaultLimits: defaultLimits,
+		Reporter:      s.Usage,
+	}); err != nil {
+		return err
+	}
+
+	s.quotaService = quotaService
+	return nil
+}
+
+// Usage reports the number of roles and role assignments of the org, there are no global quotas.
+func (s *Service) Usage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	usage := &quota.Map{}
+	if scopeParams == nil || scopeParams.OrgID == 0 {
+		return usage, nil
+	}
+
+	store, ok := s.store.(roleCounter)
+	if !ok {
+		return usage, nil
+	}
+
+	roles, assignments, err := store.CountRoles(ctx, scopeParams.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rolesTag, err := quota.NewTag(accesscontrol.QuotaTargetSrv, accesscontrol.QuotaTargetRole, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+	assignmentsTag, err := quota.NewTag(accesscontrol.QuotaTargetSrv, accesscontrol.QuotaTargetRoleAssignment, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Set(rolesTag, roles)
+	usage.Set(assignmentsTag, assignments)
+	return usage, nil
+}
+
+var _ accesscontrol.QuotaChecker = &Service{}
+
+func (s *Service) CheckOrgQuota(ctx context.Context, orgID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.CheckOrgQuota")
+	defer span.End()
+
+	if s.quotaService == nil {
+		return nil
+	}
+
+	reached, err := s.quotaService.CheckQuotaReached(ctx, accesscontrol.QuotaTargetSrv, &quota.ScopeParameters{OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if reached {
+		return accesscontrol.ErrQuotaReached.Errorf("org %d reached its quota of roles or role assignments", orgID)
+	}
+	return nil
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	if cfg == nil {
+		return limits, nil
+	}
+
+	rolesTag, err := quota.NewTag(accesscontrol.QuotaTargetSrv, accesscontrol.QuotaTargetRole, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+	assignmentsTag, err := quota.NewTag(accesscontrol.QuotaTargetSrv, accesscontrol.QuotaTargetRoleAssignment, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(rolesTag, cfg.Quota.Org.Role)
+	limits.Set(assignmentsTag, cfg.Quota.Org.RoleAssignment)
+	return limits, nil
+}
@@ -0,0 +1,58 @@
+package acimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// seedBasicRoleGrants records the roles granted to each basic role. Stores track a version of the grants of each
+// basic role, the stored grants of the basic roles that didn't change since the previous startup are not read.
+// Failing to seed doesn't prevent the startup, the basic roles are served from memory. The basic roles of the orgs
+// that differ from their defaults are reported by GetBasicRoleOverrides, e.g. in support bundles, reading them
+// doesn't slow down the startup.
+func (s *Service) seedBasicRoleGrants(ctx context.Context, roles map[string][]accesscontrol.RoleDTO) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.seedBasicRoleGrants")
+	defer span.End()
+
+	seeder, ok := s.store.(accesscontrol.BasicRoleSeeder)
+	if !ok {
+		return
+	}
+
+	grants := make(map[string]accesscontrol.BasicRoleGrants, len(s.roles))
+	for br := range s.roles {
+		grants[br] = accesscontrol.NewBasicRoleGrants(roles[br])
+	}
+
+	report, err := seeder.SeedBasicRoleGrants(ctx, grants)
+	if err != nil {
+		s.log.Warn("Failed to seed basic role grants", "error", err)
+		return
+	}
+	for br, added := range report.Added {
+		s.log.Info("Seeded roles granted to basic role", "builtInRole", br, "roles", added)
+	}
+	for br, removed := range report.Removed {
+		s.log.Info("Removed roles no longer granted to basic role", "builtInRole", br, "roles", removed)
+	}
+	s.log.Debug("Seeded basic role grants", "unchanged", report.Unchanged)
+}
+
+// GetBasicRoleOverrides returns the basic roles stored in the orgs whose permissions differ from the defaults.
+func (s *Service) GetBasicRoleOverrides(ctx context.Context) ([]accesscontrol.BasicRoleOverride, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GetBasicRoleOverrides")
+	defer span.End()
+
+	seeder, ok := s.store.(accesscontrol.BasicRoleSeeder)
+	if !ok {
+		return nil, errors.New("store does not support basic role seeding")
+	}
+
+	defaults := make(map[string][]accesscontrol.Permission, len(s.roles))
+	for _, role := range s.roles {
+		defaults[role.Name] = role.Permissions
+	}
+	return seeder.GetBasicRoleOverrides(ctx, defaults)
+}
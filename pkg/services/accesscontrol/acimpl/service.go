@@ -2,25 +2,30 @@ package acimpl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/authlib/claims"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/jobstatus"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/slugify"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/api"
@@ -29,11 +34,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/migrator"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/pluginutils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/slowquery"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/supportbundles"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -42,6 +51,11 @@ var _ pluginaccesscontrol.RoleRegistry = &Service{}
 
 const (
 	cacheTTL = 60 * time.Second
+	// revokeInterval is how often expired temporary permissions are revoked
+	revokeInterval = time.Minute
+	// aliasRewriteBatchSize and aliasRewritePause pace the rewrite of the permissions stored with renamed actions
+	aliasRewriteBatchSize = 500
+	aliasRewritePause     = time.Second
 )
 
 var SharedWithMeFolderPermission = accesscontrol.Permission{
@@ -55,11 +69,15 @@ func ProvideService(
 	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
 	accessControl accesscontrol.AccessControl, actionResolver accesscontrol.ActionResolver,
 	features featuremgmt.FeatureToggles, tracer tracing.Tracer, zclient zanzana.Client, permRegistry permreg.PermissionRegistry,
-	lock *serverlock.ServerLockService,
+	lock *serverlock.ServerLockService, quotaService quota.Service, supportBundles supportbundles.Service,
+	usageStats usagestats.Service, notifier *webhook.Notifier, wildcards *accesscontrol.WildcardRegistry,
 ) (*Service, error) {
+	store := database.ProvideService(db).
+		WithWebhook(notifier).
+		WithSlowQueryLog(slowquery.ProvideLogger(cfg, db))
 	service := ProvideOSSService(
 		cfg,
-		database.ProvideService(db),
+		store,
 		actionResolver,
 		cache,
 		features,
@@ -69,6 +87,14 @@ func ProvideService(
 		permRegistry,
 		lock,
 	)
+	service.webhook = notifier
+	service.wildcards = wildcards
+
+	if err := service.registerQuota(cfg, quotaService); err != nil {
+		return nil, err
+	}
+	service.registerSupportBundleCollector(supportBundles)
+	service.registerUsageStats(store, usageStats)
 
 	api.NewAccessControlAPI(routeRegister, accessControl, service, features).RegisterAPIEndpoints()
 	if err := accesscontrol.DeclareFixedRoles(service, cfg); err != nil {
@@ -83,6 +109,17 @@ func ProvideService(
 		return nil, err
 	}
 
+	// Older databases can contain duplicated basic role assignments resulting in duplicated permissions. They are
+	// harmless to keep around, so a failure to remove them doesn't prevent the start-up and is retried on the next one.
+	removed, err := store.DeleteDuplicateBuiltinRoles(context.Background())
+	if err != nil {
+		service.log.Error("Failed to remove duplicated basic role assignments", "removed", removed, "error", err)
+	}
+	if removed > 0 {
+		service.log.Warn("Removed duplicated basic role assignments", "count", removed)
+		metrics.MAccessDuplicateBuiltinRoles.Add(float64(removed))
+	}
+
 	return service, nil
 }
 
@@ -99,8 +136,10 @@ func ProvideOSSService(
 		log:            log.New("accesscontrol.service"),
 		roles:          accesscontrol.BuildBasicRoleDefinitions(),
 		store:          store,
-		reconciler:     dualwrite.NewZanzanaReconciler(zclient, db, lock),
+		reconciler:     dualwrite.NewZanzanaReconciler(cfg, zclient, db, lock),
 		permRegistry:   permRegistry,
+		lock:           lock,
+		jobs:           jobstatus.NewRegistry(),
 	}
 
 	return s
@@ -118,20 +157,193 @@ type Service struct {
 	store          accesscontrol.Store
 	reconciler     *dualwrite.ZanzanaReconciler
 	permRegistry   permreg.PermissionRegistry
+	lock           *serverlock.ServerLockService
+	quotaService   quota.Service
+	webhook        *webhook.Notifier
+	// wildcards holds the wildcards declared by the resources, they are matched by the searches of permissions
+	wildcards *accesscontrol.WildcardRegistry
+	// jobs tracks the background jobs maintaining the permissions, e.g. the zanzana sync and reconciliation
+	jobs *jobstatus.Registry
+
+	// backgroundMu guards the context of the running service and the background jobs waiting for it to run
+	backgroundMu      sync.Mutex
+	backgroundCtx     context.Context
+	backgroundPending []func(ctx context.Context)
+}
+
+// Webhook returns the notifier of the permission webhook, the resource permissions services share it with the service.
+func (s *Service) Webhook() *webhook.Notifier {
+	return s.webhook
+}
+
+// WildcardRegistry returns the registry of the wildcards declared by the resources, the resource permissions services
+// share it with the service.
+func (s *Service) WildcardRegistry() *accesscontrol.WildcardRegistry {
+	return s.wildcards
 }
 
 // Run implements accesscontrol.Service.
 func (s *Service) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error { return s.revokeTemporaryPermissions(ctx) })
+	g.Go(func() error { return s.rewriteActionAliases(ctx) })
+
+	s.startBackgroundJobs(ctx)
+
 	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
-		if err := s.reconciler.Sync(context.Background()); err != nil {
+		s.reconciler.RegisterJobs(s.jobs)
+		if err := s.reconciler.Sync(ctx); err != nil {
 			s.log.Error("Failed to synchronise permissions to zanzana ", "err", err)
 		}
 
-		return s.reconciler.Reconcile(ctx)
+		g.Go(func() error { return s.reconciler.Reconcile(ctx) })
+		g.Go(func() error { return s.reconciler.DispatchOutbox(ctx) })
+	}
+	return g.Wait()
+}
+
+var _ accesscontrol.BackgroundJobRunner = &Service{}
+
+// RunBackgroundJob implements accesscontrol.BackgroundJobRunner.
+func (s *Service) RunBackgroundJob(name string, fn func(ctx context.Context) error) {
+	job := s.jobs.Register(name, 0)
+	run := func(ctx context.Context) {
+		if err := job.Track(func() error { return fn(ctx) }); err != nil {
+			s.log.Warn("Background job failed", "job", name, "err", err)
+		}
+	}
+
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+	if s.backgroundCtx == nil {
+		s.backgroundPending = append(s.backgroundPending, run)
+		return
+	}
+	if s.backgroundCtx.Err() == nil {
+		go run(s.backgroundCtx)
+	}
+}
+
+// startBackgroundJobs starts the background jobs submitted before the service ran, the jobs submitted later start
+// right away.
+func (s *Service) startBackgroundJobs(ctx context.Context) {
+	s.backgroundMu.Lock()
+	defer s.backgroundMu.Unlock()
+	s.backgroundCtx = ctx
+	for _, run := range s.backgroundPending {
+		go run(ctx)
+	}
+	s.backgroundPending = nil
+}
+
+// revokeTemporaryPermissions periodically revokes expired temporary permission grants until ctx is cancelled.
+func (s *Service) revokeTemporaryPermissions(ctx context.Context) error {
+	revoker, ok := s.store.(accesscontrol.TemporaryPermissionRevoker)
+	if !ok {
+		return nil
+	}
+
+	// The tuples granting the revoked permissions are removed through the outbox, like the permissions set by the
+	// resource permissions services
+	var onRevoke func(ctx context.Context, revoked []accesscontrol.TemporaryPermission) error
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		onRevoke = func(ctx context.Context, revoked []accesscontrol.TemporaryPermission) error {
+			for _, grant := range revoked {
+				err := s.reconciler.EnqueueResourceAssignments(ctx, grant.OrgID, accesscontrol.ResourceAssignment{
+					Resource: grant.Resource, ResourceID: grant.ResourceID, UserID: grant.UserID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	job := s.jobs.Register("rbac-revoke-temporary-permissions", revokeInterval)
+	revoke := func(ctx context.Context) {
+		finish := job.Start()
+		revoked, err := revoker.RevokeExpiredTemporaryPermissions(ctx, time.Now(), onRevoke)
+		finish(err)
+		if err != nil {
+			s.log.Warn("Failed to revoke expired temporary permissions", "err", err)
+			return
+		}
+		// The removals are recorded in the permission audit log by the store
+		for _, grant := range revoked {
+			s.clearUserPermissionCacheByID(grant.OrgID, grant.UserID)
+			s.log.Debug("Revoked expired temporary permission", "orgID", grant.OrgID, "userID", grant.UserID,
+				"scope", grant.Scope(), "permission", grant.Permission, "grantedBy", grant.GrantedBy, "expires", grant.Expires)
+		}
+	}
+
+	ticker := time.NewTicker(revokeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// in tests we can skip creating a lock
+			if s.lock == nil {
+				revoke(ctx)
+				continue
+			}
+			_ = s.lock.LockExecuteAndRelease(ctx, "rbac-revoke-temporary-permissions", revokeInterval, revoke)
+		case <-ctx.Done():
+			return nil
+		}
 	}
+}
+
+// rewriteActionAliases renames the permissions stored with the previous name of a renamed action once at startup.
+// Aliases are resolved when permissions are read, so permissions are rewritten in small batches in the background
+// instead of blocking the startup.
+func (s *Service) rewriteActionAliases(ctx context.Context) error {
+	rewriter, ok := s.store.(accesscontrol.ActionAliasRewriter)
+	if !ok {
+		return nil
+	}
+
+	job := s.jobs.Register("rbac-rewrite-action-aliases", 0)
+	rewrite := func(ctx context.Context) {
+		finish := job.Start()
+		var total int
+		var err error
+		for ctx.Err() == nil {
+			var rewritten int
+			rewritten, err = rewriter.RewriteActionAliases(ctx, aliasRewriteBatchSize)
+			total += rewritten
+			if err != nil || rewritten < aliasRewriteBatchSize {
+				break
+			}
+			select {
+			case <-time.After(aliasRewritePause):
+			case <-ctx.Done():
+			}
+		}
+		finish(err)
+		if err != nil {
+			s.log.Warn("Failed to rewrite permissions with renamed actions", "rewritten", total, "err", err)
+			return
+		}
+		if total > 0 {
+			s.log.Info("Rewrote permissions with renamed actions", "rewritten", total)
+		}
+	}
+
+	// in tests we can skip creating a lock
+	if s.lock == nil {
+		rewrite(ctx)
+		return nil
+	}
+	_ = s.lock.LockExecuteAndRelease(ctx, "rbac-rewrite-action-aliases", time.Hour, rewrite)
 	return nil
 }
 
+// JobStatuses returns the status of the background jobs maintaining the permissions.
+func (s *Service) JobStatuses() []jobstatus.Status {
+	return s.jobs.Statuses()
+}
+
 func (s *Service) GetUsageStats(_ context.Context) map[string]any {
 	return map[string]any{
 		"stats.oss.accesscontrol.enabled.count": 1,
@@ -182,6 +394,7 @@ func (s *Service) getUserPermissions(ctx context.Context, user identity.Requeste
 	if err != nil {
 		return nil, err
 	}
+	dbPermissions = accesscontrol.ResolveActionAliases(dbPermissions)
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
 		dbPermissions = s.actionResolver.ExpandActionSets(dbPermissions)
 	}
@@ -204,6 +417,7 @@ func (s *Service) getBasicRolePermissions(ctx context.Context, role string, orgI
 		OrgID:        orgID,
 		RolePrefixes: OSSRolesPrefixes,
 	})
+	dbPermissions = accesscontrol.ResolveActionAliases(dbPermissions)
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
 		dbPermissions = s.actionResolver.ExpandActionSets(dbPermissions)
 	}
@@ -221,6 +435,9 @@ func (s *Service) getTeamsPermissions(ctx context.Context, teamIDs []int64, orgI
 		RolePrefixes: OSSRolesPrefixes,
 	})
 
+	for teamID, permissions := range teamPermissions {
+		teamPermissions[teamID] = accesscontrol.ResolveActionAliases(permissions)
+	}
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
 		for teamID, permissions := range teamPermissions {
 			teamPermissions[teamID] = s.actionResolver.ExpandActionSets(permissions)
@@ -253,6 +470,7 @@ func (s *Service) getUserDirectPermissions(ctx context.Context, user identity.Re
 		return nil, err
 	}
 
+	permissions = accesscontrol.ResolveActionAliases(permissions)
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
 		permissions = s.actionResolver.ExpandActionSets(permissions)
 	}
@@ -418,6 +636,52 @@ func (s *Service) ClearUserPermissionCache(user identity.Requester) {
 	s.cache.Delete(accesscontrol.GetUserDirectPermissionCacheKey(user))
 }
 
+// clearUserPermissionCacheByID clears the cached permissions of the user or service account in the org, for changes
+// made without the identity of the user, e.g. in the background.
+func (s *Service) clearUserPermissionCacheByID(orgID, userID int64) {
+	s.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: userID})
+	s.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: userID, IsServiceAccount: true})
+}
+
+// clearUserPermissionCacheInAllOrgs clears the cached permissions of the user or service account in every org, for
+// changes made to all the orgs of the user at once, e.g. its deprovisioning.
+func (s *Service) clearUserPermissionCacheInAllOrgs(userID int64) {
+	suffixes := accesscontrol.GetUserPermissionCacheKeySuffixes(userID)
+	for key := range s.cache.Items() {
+		if !strings.HasPrefix(key, "rbac-permissions-") {
+			continue
+		}
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(key, suffix) {
+				s.cache.Delete(key)
+				break
+			}
+		}
+	}
+}
+
+// clearOrgPermissionCache clears the cached permissions of every identity, team and basic role of the org, for changes
+// replacing the roles of the org at once.
+func (s *Service) clearOrgPermissionCache(orgID int64) {
+	prefixes := accesscontrol.GetOrgPermissionCacheKeyPrefixes(orgID)
+	for key := range s.cache.Items() {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				s.cache.Delete(key)
+				break
+			}
+		}
+	}
+}
+
+// checkWritable returns ErrReadOnly when permission changes are disabled by the rbac permissions_read_only setting.
+func (s *Service) checkWritable() error {
+	if s.cfg != nil && s.cfg.RBAC.PermissionsReadOnly {
+		return accesscontrol.ErrReadOnly.Errorf("permission changes are disabled")
+	}
+	return nil
+}
+
 func (s *Service) DeleteUserPermissions(ctx context.Context, orgID int64, userID int64) error {
 	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.DeleteUserPermissions")
 	defer span.End()
@@ -432,6 +696,314 @@ func (s *Service) DeleteTeamPermissions(ctx context.Context, orgID int64, teamID
 	return s.store.DeleteTeamPermissions(ctx, orgID, teamID)
 }
 
+var _ accesscontrol.Snapshotter = &Service{}
+
+func (s *Service) ExportSnapshot(ctx context.Context, orgID int64) (*accesscontrol.Snapshot, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ExportSnapshot")
+	defer span.End()
+
+	store, ok := s.store.(accesscontrol.Snapshotter)
+	if !ok {
+		return nil, errors.New("store does not support snapshots")
+	}
+	return store.ExportSnapshot(ctx, orgID)
+}
+
+func (s *Service) ImportSnapshot(ctx context.Context, orgID int64, snapshot *accesscontrol.Snapshot) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ImportSnapshot")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	store, ok := s.store.(accesscontrol.Snapshotter)
+	if !ok {
+		return errors.New("store does not support snapshots")
+	}
+
+	previous, err := store.ExportSnapshot(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if err := store.ImportSnapshot(ctx, orgID, snapshot); err != nil {
+		return err
+	}
+	s.clearOrgPermissionCache(orgID)
+
+	// The roles of the org were recreated, the tuples of the previous ones are moved to the role with the same name
+	// or removed. Failures are left to the reconciliation, which removes the tuples of deleted roles.
+	imported := make(map[string]string, len(snapshot.Roles))
+	kept := make(map[string]struct{}, len(snapshot.Roles))
+	for _, r := range snapshot.Roles {
+		imported[r.Name] = r.UID
+		kept[r.UID] = struct{}{}
+	}
+	for _, r := range previous.Roles {
+		if _, ok := kept[r.UID]; ok {
+			continue
+		}
+
+		var err error
+		if uid, ok := imported[r.Name]; ok {
+			err = s.RoleUIDChanged(ctx, orgID, r.UID, uid)
+		} else {
+			err = s.RoleDeleted(ctx, orgID, r.UID)
+		}
+		if err != nil {
+			s.log.Warn("Failed to update the tuples of a role replaced by a snapshot", "orgID", orgID, "role", r.UID, "error", err)
+		}
+	}
+	return nil
+}
+
+var _ accesscontrol.OrgCopier = &Service{}
+
+func (s *Service) CopyOrgPermissions(ctx context.Context, srcOrgID, dstOrgID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.CopyOrgPermissions")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	store, ok := s.store.(accesscontrol.OrgCopier)
+	if !ok {
+		return errors.New("store does not support copying org permissions")
+	}
+	if err := store.CopyOrgPermissions(ctx, srcOrgID, dstOrgID); err != nil {
+		return err
+	}
+
+	s.clearOrgPermissionCache(dstOrgID)
+	return nil
+}
+
+var _ accesscontrol.TeamResourceCounter = &Service{}
+
+func (s *Service) GetTeamsResourceCounts(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]map[string]int64, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GetTeamsResourceCounts")
+	defer span.End()
+
+	store, ok := s.store.(accesscontrol.TeamResourceCounter)
+	if !ok {
+		return nil, errors.New("store does not support counting team resources")
+	}
+	return store.GetTeamsResourceCounts(ctx, orgID, teamIDs)
+}
+
+var _ accesscontrol.Deprovisioner = &Service{}
+
+func (s *Service) DeprovisionUser(ctx context.Context, userID int64, userUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.DeprovisionUser")
+	defer span.End()
+
+	if err := s.store.DeleteUserPermissions(ctx, accesscontrol.GlobalOrgID, userID); err != nil {
+		return err
+	}
+	s.clearUserPermissionCacheInAllOrgs(userID)
+
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) && userUID != "" {
+		return s.reconciler.DeleteUserTuples(ctx, userUID)
+	}
+	return nil
+}
+
+func (s *Service) DeprovisionTeam(ctx context.Context, orgID, teamID int64, teamUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.DeprovisionTeam")
+	defer span.End()
+
+	if err := s.store.DeleteTeamPermissions(ctx, orgID, teamID); err != nil {
+		return err
+	}
+
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) && teamUID != "" {
+		return s.reconciler.DeleteTeamTuples(ctx, teamUID)
+	}
+	return nil
+}
+
+var _ accesscontrol.UserPermissionsRevoker = &Service{}
+
+func (s *Service) RevokeUserPermissionsByResourceType(ctx context.Context, orgID, userID int64, resource string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.RevokeUserPermissionsByResourceType")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	store, ok := s.store.(accesscontrol.UserResourcePermissionsRevoker)
+	if !ok {
+		return errors.New("store does not support revoking user permissions")
+	}
+
+	// The tuples granting the revoked permissions are removed through the outbox, like the permissions set by the
+	// resource permissions services
+	var onRevoke func(ctx context.Context, resourceIDs []string) error
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		onRevoke = func(ctx context.Context, resourceIDs []string) error {
+			assignments := make([]accesscontrol.ResourceAssignment, 0, len(resourceIDs))
+			for _, resourceID := range resourceIDs {
+				assignments = append(assignments, accesscontrol.ResourceAssignment{Resource: resource, ResourceID: resourceID, UserID: userID})
+			}
+			return s.reconciler.EnqueueResourceAssignments(ctx, orgID, assignments...)
+		}
+	}
+
+	revoked, err := store.RevokeUserResourcePermissions(ctx, orgID, userID, resource, onRevoke)
+	if err != nil {
+		return err
+	}
+
+	// The removals are recorded in the permission audit log by the store
+	s.clearUserPermissionCacheByID(orgID, userID)
+	s.log.Debug("Revoked user permissions by resource type", "orgID", orgID, "userID", userID, "resource", resource, "resources", len(revoked))
+	return nil
+}
+
+var _ accesscontrol.UserDeletionPreviewer = &Service{}
+
+func (s *Service) PreviewDeleteUserPermissions(ctx context.Context, orgID, userID int64) (*accesscontrol.UserDeletionPreview, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.PreviewDeleteUserPermissions")
+	defer span.End()
+
+	store, ok := s.store.(accesscontrol.UserDeletionPreviewer)
+	if !ok {
+		return nil, errors.New("store does not support previewing user deletion")
+	}
+	preview, err := store.PreviewDeleteUserPermissions(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tuples are removed when the user is deprovisioned, their objects don't all belong to an org
+	if orgID == accesscontrol.GlobalOrgID && s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		tuples, err := s.reconciler.UserTuples(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		preview.Tuples = make([]accesscontrol.DeletedTuple, 0, len(tuples))
+		for _, t := range tuples {
+			preview.Tuples = append(preview.Tuples, accesscontrol.DeletedTuple{User: t.GetUser(), Relation: t.GetRelation(), Object: t.GetObject()})
+		}
+	}
+	return preview, nil
+}
+
+var _ accesscontrol.TeamMembershipPreviewer = &Service{}
+
+func (s *Service) PreviewTeamMembership(ctx context.Context, orgID, userID, teamID int64) ([]accesscontrol.Permission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.PreviewTeamMembership")
+	defer span.End()
+
+	teamPermissions, err := s.store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID:   orgID,
+		TeamIDs: []int64{teamID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is skipped so the preview reflects the current permissions of the user
+	userPermissions, err := s.searchUserPermissions(ctx, orgID, accesscontrol.SearchOptions{
+		TypedID: identity.NewTypedID(claims.TypeUser, userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+		teamPermissions = s.actionResolver.ExpandActionSets(teamPermissions)
+		userPermissions = s.actionResolver.ExpandActionSets(userPermissions)
+	}
+
+	granted := accesscontrol.GroupScopesByActionContext(ctx, userPermissions)
+	gained := make([]accesscontrol.Permission, 0)
+	seen := make(map[accesscontrol.Permission]bool)
+	for _, p := range teamPermissions {
+		// Deny permissions restrict access and are never gained
+		if p.Deny || seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		if !accesscontrol.EvalPermission(p.Action, p.Scope).Evaluate(granted) {
+			gained = append(gained, p)
+		}
+	}
+	return gained, nil
+}
+
+var _ accesscontrol.ResourceContainerTupleMaintainer = &Service{}
+
+func (s *Service) ResourceMoved(ctx context.Context, orgID int64, resource, resourceID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ResourceMoved")
+	defer span.End()
+
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil
+	}
+	return s.reconciler.EnqueueResourceContainerTuple(ctx, orgID, resource, resourceID)
+}
+
+var _ accesscontrol.RoleTupleMaintainer = &Service{}
+
+func (s *Service) RoleUIDChanged(ctx context.Context, orgID int64, oldUID, newUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.RoleUIDChanged")
+	defer span.End()
+
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) || oldUID == newUID {
+		return nil
+	}
+	return s.reconciler.RenameRoleTuples(ctx, orgID, oldUID, newUID)
+}
+
+func (s *Service) RoleDeleted(ctx context.Context, orgID int64, roleUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.RoleDeleted")
+	defer span.End()
+
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil
+	}
+	return s.reconciler.DeleteRoleTuples(ctx, orgID, roleUID)
+}
+
+var _ accesscontrol.OrgRoleSyncer = &Service{}
+
+func (s *Service) OrgRoleChanged(ctx context.Context, orgID, userID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.OrgRoleChanged")
+	defer span.End()
+
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil
+	}
+	return s.reconciler.EnqueueBasicRoleAssignments(ctx, orgID, userID)
+}
+
+func (s *Service) GrafanaAdminChanged(ctx context.Context, userID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.GrafanaAdminChanged")
+	defer span.End()
+
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil
+	}
+	return s.reconciler.EnqueueUserBasicRoleAssignments(ctx, userID)
+}
+
+var _ accesscontrol.ExternalGroupSyncer = &Service{}
+
+func (s *Service) SyncUserExternalGroups(ctx context.Context, userID int64, groups []string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.SyncUserExternalGroups")
+	defer span.End()
+
+	syncer, ok := s.store.(accesscontrol.ExternalGroupSyncer)
+	if !ok {
+		return false, errors.New("store does not support external groups")
+	}
+	return syncer.SyncUserExternalGroups(ctx, userID, groups)
+}
+
 // DeclareFixedRoles allow the caller to declare, to the service, fixed roles and their assignments
 // to organization roles ("Viewer", "Editor", "Admin") or "Grafana Admin"
 func (s *Service) DeclareFixedRoles(registrations ...accesscontrol.RoleRegistration) error {
@@ -460,13 +1032,15 @@ func (s *Service) DeclareFixedRoles(registrations ...accesscontrol.RoleRegistrat
 
 // RegisterFixedRoles registers all declared roles in RAM
 func (s *Service) RegisterFixedRoles(ctx context.Context) error {
-	_, span := tracer.Start(ctx, "accesscontrol.acimpl.RegisterFixedRoles")
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.RegisterFixedRoles")
 	defer span.End()
 
+	grants := map[string][]accesscontrol.RoleDTO{}
 	s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
 		for br := range accesscontrol.BuiltInRolesWithParents(registration.Grants) {
 			if basicRole, ok := s.roles[br]; ok {
 				basicRole.Permissions = append(basicRole.Permissions, registration.Role.Permissions...)
+				grants[br] = append(grants[br], registration.Role)
 			} else {
 				s.log.Error("Unknown builtin role", "builtInRole", br)
 			}
@@ -474,6 +1048,17 @@ func (s *Service) RegisterFixedRoles(ctx context.Context) error {
 		return true
 	})
 
+	s.seedBasicRoleGrants(ctx, grants)
+
+	// The reconciliation writes the tuples of the declared roles in the orgs where their version changed
+	if s.reconciler != nil {
+		var registrations []accesscontrol.RoleRegistration
+		s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+			registrations = append(registrations, registration)
+			return true
+		})
+		s.reconciler.SetFixedRoles(registrations)
+	}
 	return nil
 }
 
@@ -530,13 +1115,19 @@ func (s *Service) SearchUsersPermissions(ctx context.Context, usr identity.Reque
 
 	// Limit roles to available in OSS
 	options.RolePrefixes = OSSRolesPrefixes
+	options.ResolveWildcards(s.wildcards)
 	if options.TypedID != "" {
-		userID, err := options.ComputeUserID()
+		typ, userID, err := options.ComputeIdentity()
 		if err != nil {
 			s.log.Error("Failed to resolve user ID", "error", err)
 			return nil, err
 		}
 
+		// The render service is not backed by a user and has no stored permissions
+		if typ == claims.TypeRenderService {
+			return map[int64][]accesscontrol.Permission{}, nil
+		}
+
 		// Reroute to the user specific implementation of search permissions
 		// because it leverages the user permission cache.
 		userPerms, err := s.SearchUserPermissions(ctx, usr.GetOrgID(), options)
@@ -549,6 +1140,120 @@ func (s *Service) SearchUsersPermissions(ctx context.Context, usr identity.Reque
 	timer := prometheus.NewTimer(metrics.MAccessSearchPermissionsSummary)
 	defer timer.ObserveDuration()
 
+	search, err := s.newUsersPermissionsSearch(ctx, usr, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get managed permissions (DB)
+	usersPermissions, err := s.store.SearchUsersPermissions(ctx, usr.GetOrgID(), search.options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge stored (DB) and basic role permissions (RAM)
+	// Assumes that all users with stored permissions have org roles
+	res := map[int64][]accesscontrol.Permission{}
+	for userID := range search.usersRoles {
+		if perms := s.mergeUserPermissions(ctx, search, userID, usersPermissions[userID]); len(perms) > 0 {
+			res[userID] = perms
+		}
+	}
+
+	return res, nil
+}
+
+type usersPermissionsStreamer interface {
+	StreamUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions, fn func(userID int64, permissions []accesscontrol.Permission) error) error
+}
+
+var _ accesscontrol.UsersPermissionsStreamer = &Service{}
+
+// StreamUsersPermissions calls fn with the permissions of each user instead of returning the permissions of all users
+// at once like SearchUsersPermissions, the stored permissions are read one user at a time when the store supports it.
+func (s *Service) StreamUsersPermissions(ctx context.Context, usr identity.Requester, options accesscontrol.SearchOptions, fn func(userID int64, permissions []accesscontrol.Permission) error) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.StreamUsersPermissions")
+	defer span.End()
+
+	streamer, ok := s.store.(usersPermissionsStreamer)
+	if !ok || options.TypedID != "" {
+		res, err := s.SearchUsersPermissions(ctx, usr, options)
+		if err != nil {
+			return err
+		}
+		for userID, perms := range res {
+			if err := fn(userID, perms); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	timer := prometheus.NewTimer(metrics.MAccessSearchPermissionsSummary)
+	defer timer.ObserveDuration()
+
+	// Limit roles to available in OSS
+	options.RolePrefixes = OSSRolesPrefixes
+	search, err := s.newUsersPermissionsSearch(ctx, usr, options)
+	if err != nil {
+		return err
+	}
+
+	streamed := make(map[int64]struct{}, len(search.usersRoles))
+	err = streamer.StreamUsersPermissions(ctx, usr.GetOrgID(), search.options, func(userID int64, dbPerms []accesscontrol.Permission) error {
+		if _, ok := search.usersRoles[userID]; !ok {
+			return nil
+		}
+		streamed[userID] = struct{}{}
+		if perms := s.mergeUserPermissions(ctx, search, userID, dbPerms); len(perms) > 0 {
+			return fn(userID, perms)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Users without stored permissions only get the permissions of their basic roles
+	for userID := range search.usersRoles {
+		if _, ok := streamed[userID]; ok {
+			continue
+		}
+		if perms := s.mergeUserPermissions(ctx, search, userID, nil); len(perms) > 0 {
+			if err := fn(userID, perms); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CountUsersPermissions returns the number of permissions of each user without holding the permissions of all users.
+func (s *Service) CountUsersPermissions(ctx context.Context, usr identity.Requester, options accesscontrol.SearchOptions) (map[int64]int, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.CountUsersPermissions")
+	defer span.End()
+
+	counts := map[int64]int{}
+	err := s.StreamUsersPermissions(ctx, usr, options, func(userID int64, permissions []accesscontrol.Permission) error {
+		counts[userID] = len(permissions)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// usersPermissionsSearch holds what the searches of the permissions of all users of an org need to merge the stored
+// permissions of a user with the permissions of their basic roles.
+type usersPermissionsSearch struct {
+	options          accesscontrol.SearchOptions
+	basicPermissions map[string][]accesscontrol.Permission
+	usersRoles       map[int64][]string
+	canView          func(userID int64) bool
+}
+
+func (s *Service) newUsersPermissionsSearch(ctx context.Context, usr identity.Requester, options accesscontrol.SearchOptions) (*usersPermissionsSearch, error) {
 	// Filter ram permissions
 	basicPermissions := map[string][]accesscontrol.Permission{}
 	for role, basicRole := range s.roles {
@@ -570,12 +1275,6 @@ func (s *Service) SearchUsersPermissions(ctx context.Context, usr identity.Reque
 			s.actionResolver.ResolveActionPrefix(options.ActionPrefix)...)
 	}
 
-	// Get managed permissions (DB)
-	usersPermissions, err := s.store.SearchUsersPermissions(ctx, usr.GetOrgID(), options)
-	if err != nil {
-		return nil, err
-	}
-
 	// helper to filter out permissions the signed in users cannot see
 	canView := func() func(userID int64) bool {
 		siuPermissions := usr.GetPermissions()
@@ -606,36 +1305,35 @@ func (s *Service) SearchUsersPermissions(ctx context.Context, usr identity.Reque
 		return func(userID int64) bool { return ids[userID] }
 	}()
 
-	// Merge stored (DB) and basic role permissions (RAM)
-	// Assumes that all users with stored permissions have org roles
-	res := map[int64][]accesscontrol.Permission{}
-	for userID, roles := range usersRoles {
-		if !canView(userID) {
-			continue
-		}
-		perms := []accesscontrol.Permission{}
-		for i := range roles {
-			basicPermission, ok := basicPermissions[roles[i]]
-			if !ok {
-				continue
-			}
-			perms = append(perms, basicPermission...)
-		}
-		if dbPerms, ok := usersPermissions[userID]; ok {
-			perms = append(perms, dbPerms...)
-		}
-		if len(perms) > 0 {
-			res[userID] = perms
-		}
+	return &usersPermissionsSearch{
+		options:          options,
+		basicPermissions: basicPermissions,
+		usersRoles:       usersRoles,
+		canView:          canView,
+	}, nil
+}
+
+// mergeUserPermissions returns the permissions of the basic roles of the user together with their stored permissions,
+// nil when the signed in user can't view them.
+func (s *Service) mergeUserPermissions(ctx context.Context, search *usersPermissionsSearch, userID int64, dbPerms []accesscontrol.Permission) []accesscontrol.Permission {
+	if !search.canView(userID) {
+		return nil
 	}
 
-	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) && len(options.ActionSets) > 0 {
-		for id, perms := range res {
-			res[id] = s.actionResolver.ExpandActionSetsWithFilter(perms, GetActionFilter(options))
+	perms := []accesscontrol.Permission{}
+	for _, role := range search.usersRoles[userID] {
+		basicPermission, ok := search.basicPermissions[role]
+		if !ok {
+			continue
 		}
+		perms = append(perms, basicPermission...)
 	}
+	perms = append(perms, accesscontrol.ResolveActionAliases(dbPerms)...)
 
-	return res, nil
+	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) && len(search.options.ActionSets) > 0 {
+		perms = s.actionResolver.ExpandActionSetsWithFilter(perms, GetActionFilter(search.options))
+	}
+	return perms
 }
 
 func (s *Service) SearchUserPermissions(ctx context.Context, orgID int64, searchOptions accesscontrol.SearchOptions) ([]accesscontrol.Permission, error) {
@@ -648,6 +1346,7 @@ func (s *Service) SearchUserPermissions(ctx context.Context, orgID int64, search
 	if searchOptions.TypedID == "" {
 		return nil, fmt.Errorf("expected namespaced ID to be specified")
 	}
+	searchOptions.ResolveWildcards(s.wildcards)
 
 	if permissions, success := s.searchUserPermissionsFromCache(ctx, orgID, searchOptions); success {
 		return permissions, nil
@@ -696,7 +1395,7 @@ func (s *Service) searchUserPermissions(ctx context.Context, orgID int64, search
 	if err != nil {
 		return nil, err
 	}
-	permissions = append(permissions, dbPermissions[userID]...)
+	permissions = append(permissions, accesscontrol.ResolveActionAliases(dbPermissions[userID])...)
 
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) && len(searchOptions.ActionSets) != 0 {
 		permissions = s.actionResolver.ExpandActionSetsWithFilter(permissions, GetActionFilter(searchOptions))
@@ -777,7 +1476,12 @@ func (s *Service) DeleteExternalServiceRole(ctx context.Context, externalService
 
 	slug := slugify.Slugify(externalServiceID)
 
-	return s.store.DeleteExternalServiceRole(ctx, slug)
+	if err := s.store.DeleteExternalServiceRole(ctx, slug); err != nil {
+		return err
+	}
+
+	// External service roles are global
+	return s.RoleDeleted(ctx, accesscontrol.GlobalOrgID, accesscontrol.ExternalServiceRoleUID(slug))
 }
 
 func (*Service) SyncUserRoles(ctx context.Context, orgID int64, cmd accesscontrol.SyncUserRolesCommand) error {
@@ -16,6 +16,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -273,3 +275,87 @@ func benchSearchUserWithAction(b *testing.B, usersCount, resourceCount int) {
 }
 
 func BenchmarkSearchUserWithAction_1K_1k(b *testing.B) { benchSearchUserWithAction(b, 1000, 1000) } // ~0.6s/op (mysql)
+
+// setupSeededBenchEnv populates the database with actest.Seed, memberships and permissions are skewed towards the
+// first teams and dashboards like on real instances. Run with GRAFANA_TEST_DB to compare dialects.
+func setupSeededBenchEnv(b *testing.B, usersCount, teamsCount, resourceCount int) (*Service, db.DB, *actest.Seeded) {
+	if testing.Short() {
+		b.Skip("Skipping benchmark in short mode")
+	}
+
+	sqlStore := db.InitTestDB(b)
+	acService := &Service{
+		cfg:           setting.NewCfg(),
+		features:      featuremgmt.WithFeatures(),
+		log:           log.New("accesscontrol-test"),
+		registrations: accesscontrol.RegistrationList{},
+		store:         database.ProvideService(sqlStore),
+		roles:         accesscontrol.BuildBasicRoleDefinitions(),
+		cache:         localcache.New(1*time.Second, 1*time.Second),
+		permRegistry:  permreg.ProvidePermissionRegistry(),
+	}
+	require.NoError(b, acService.RegisterFixedRoles(context.Background()))
+
+	seeded := actest.Seed(b, sqlStore, actest.SeedOptions{
+		Users:                  usersCount,
+		Teams:                  teamsCount,
+		Resources:              resourceCount,
+		TeamsPerUser:           5,
+		PermissionsPerAssignee: 20,
+		Skew:                   1.2,
+		RandSeed:               1,
+	})
+	return acService, sqlStore, seeded
+}
+
+func benchSeededGetUserPermissions(b *testing.B, usersCount, teamsCount, resourceCount int) {
+	acService, sqlStore, seeded := setupSeededBenchEnv(b, usersCount, teamsCount, resourceCount)
+
+	// The first user is the one whose teams have the most members
+	userID := seeded.UserIDs[0]
+	var teamIDs []int64
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.Table("team_member").Where("user_id = ?", userID).Cols("team_id").Find(&teamIDs)
+	})
+	require.NoError(b, err)
+
+	usr := &user.SignedInUser{UserID: userID, OrgID: seeded.OrgID, OrgRole: org.RoleViewer, Teams: teamIDs}
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		permissions, err := acService.GetUserPermissions(context.Background(), usr, accesscontrol.Options{ReloadCache: true})
+		require.NoError(b, err)
+		require.NotEmpty(b, permissions)
+	}
+}
+
+func BenchmarkSeededGetUserPermissions_1K_100_1K(b *testing.B) {
+	benchSeededGetUserPermissions(b, 1000, 100, 1000)
+}
+func BenchmarkSeededGetUserPermissions_10K_1K_10K(b *testing.B) {
+	benchSeededGetUserPermissions(b, 10000, 1000, 10000)
+}
+
+func benchSeededSearchUsersPermissions(b *testing.B, usersCount, teamsCount, resourceCount int) {
+	acService, _, seeded := setupSeededBenchEnv(b, usersCount, teamsCount, resourceCount)
+	siu := &user.SignedInUser{OrgID: seeded.OrgID, Permissions: map[int64]map[string][]string{
+		seeded.OrgID: {accesscontrol.ActionUsersPermissionsRead: {accesscontrol.ScopeUsersAll}},
+	}}
+	// The first dashboard is the one with the most permissions
+	scope := accesscontrol.Scope(actest.SeedResource, "uid", seeded.ResourceIDs[0])
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		usersPermissions, err := acService.SearchUsersPermissions(context.Background(), siu,
+			accesscontrol.SearchOptions{Action: actest.SeedReadAction, Scope: scope})
+		require.NoError(b, err)
+		require.NotEmpty(b, usersPermissions)
+	}
+}
+
+func BenchmarkSeededSearchUsersPermissions_1K_100_1K(b *testing.B) {
+	benchSeededSearchUsersPermissions(b, 1000, 100, 1000)
+}
+func BenchmarkSeededSearchUsersPermissions_10K_1K_10K(b *testing.B) {
+	benchSeededSearchUsersPermissions(b, 10000, 1000, 10000)
+}
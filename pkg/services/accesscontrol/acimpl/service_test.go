@@ -2,8 +2,10 @@ package acimpl
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
@@ -598,6 +601,23 @@ func TestService_SearchUsersPermissions(t *testing.T) {
 
 				require.ElementsMatch(t, gotPerm, wantPerm)
 			}
+
+			streamed := map[int64][]accesscontrol.Permission{}
+			err = ac.StreamUsersPermissions(ctx, siu, tt.searchOption, func(userID int64, permissions []accesscontrol.Permission) error {
+				streamed[userID] = permissions
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, streamed, len(tt.want))
+			for userID, wantPerm := range tt.want {
+				require.ElementsMatch(t, streamed[userID], wantPerm)
+			}
+
+			counts, err := ac.CountUsersPermissions(ctx, siu, tt.searchOption)
+			require.NoError(t, err)
+			for userID, wantPerm := range tt.want {
+				require.Equal(t, len(wantPerm), counts[userID])
+			}
 		})
 	}
 }
@@ -972,3 +992,133 @@ func TestService_DeleteExternalServiceRole(t *testing.T) {
 		})
 	}
 }
+
+func TestService_DeprovisionUser(t *testing.T) {
+	ctx := context.Background()
+	ac := setupTestEnv(t)
+
+	for _, orgID := range []int64{1, 2} {
+		err := ac.SaveExternalServiceRole(ctx, accesscontrol.SaveExternalServiceRoleCommand{
+			AssignmentOrgID:   orgID,
+			ServiceAccountID:  2,
+			ExternalServiceID: fmt.Sprintf("App %d", orgID),
+			Permissions:       []accesscontrol.Permission{{Action: "users:read", Scope: "users:id:1"}},
+		})
+		require.NoError(t, err)
+	}
+
+	// The permissions cached before the deprovisioning are not used after it
+	for _, orgID := range []int64{1, 2} {
+		sa := &user.SignedInUser{OrgID: orgID, UserID: 2, IsServiceAccount: true}
+		ac.cache.Set(accesscontrol.GetUserPermissionCacheKey(sa), []accesscontrol.Permission{{Action: "users:read", Scope: "users:id:1"}}, cacheTTL)
+		ac.cache.Set(accesscontrol.GetUserDirectPermissionCacheKey(sa), []accesscontrol.Permission{{Action: "users:read", Scope: "users:id:1"}}, cacheTTL)
+	}
+	other := &user.SignedInUser{OrgID: 1, UserID: 12}
+	ac.cache.Set(accesscontrol.GetUserPermissionCacheKey(other), []accesscontrol.Permission{}, cacheTTL)
+
+	require.NoError(t, ac.DeprovisionUser(ctx, 2, "user-2"))
+
+	for _, orgID := range []int64{1, 2} {
+		perms, err := ac.getUserPermissions(ctx, &user.SignedInUser{OrgID: orgID, UserID: 2}, accesscontrol.Options{})
+		require.NoError(t, err)
+		assert.Empty(t, perms)
+
+		sa := &user.SignedInUser{OrgID: orgID, UserID: 2, IsServiceAccount: true}
+		_, ok := ac.cache.Get(accesscontrol.GetUserPermissionCacheKey(sa))
+		assert.False(t, ok)
+		_, ok = ac.cache.Get(accesscontrol.GetUserDirectPermissionCacheKey(sa))
+		assert.False(t, ok)
+	}
+	_, ok := ac.cache.Get(accesscontrol.GetUserPermissionCacheKey(other))
+	assert.True(t, ok)
+}
+
+func TestService_PreviewTeamMembership(t *testing.T) {
+	ctx := context.Background()
+	sql := db.InitTestDB(t)
+	ac := setupTestEnv(t)
+	ac.store = database.ProvideService(sql)
+
+	now := time.Now()
+	permission := func(roleID int64, action, scope string, deny bool) accesscontrol.Permission {
+		return accesscontrol.Permission{RoleID: roleID, Action: action, Scope: scope, Deny: deny, Created: now, Updated: now}
+	}
+	err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti([]any{
+			&org.OrgUser{OrgID: 1, UserID: 1, Role: org.RoleViewer, Created: now, Updated: now},
+			&accesscontrol.Role{ID: 1, OrgID: 1, UID: "user_1", Name: accesscontrol.ManagedUserRoleName(1), Created: now, Updated: now},
+			&accesscontrol.Role{ID: 2, OrgID: 1, UID: "team_1", Name: accesscontrol.ManagedTeamRoleName(1), Created: now, Updated: now},
+			&accesscontrol.UserRole{OrgID: 1, RoleID: 1, UserID: 1, Created: now},
+			&accesscontrol.TeamRole{OrgID: 1, RoleID: 2, TeamID: 1, Created: now},
+		}...)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.InsertMulti(&[]accesscontrol.Permission{
+			permission(1, "test:read", "test:id:1", false),
+			permission(2, "test:read", "test:id:1", false),
+			permission(2, "test:read", "test:*", false),
+			permission(2, "test:write", "test:id:1", false),
+			permission(2, "test:delete", "test:id:1", true),
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	gained, err := ac.PreviewTeamMembership(ctx, 1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"test:read":  {"test:*"},
+		"test:write": {"test:id:1"},
+	}, accesscontrol.GroupScopesByActionContext(ctx, gained))
+
+	// Users outside of the org can't be previewed
+	_, err = ac.PreviewTeamMembership(ctx, 1, 2, 1)
+	require.Error(t, err)
+}
+
+func TestService_ImportSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expect cached permissions of the org to be cleared", func(t *testing.T) {
+		ac := setupTestEnv(t)
+		signedInUser := &user.SignedInUser{OrgID: 1, UserID: 1}
+		ac.cache.Set(accesscontrol.GetUserPermissionCacheKey(signedInUser), []accesscontrol.Permission{}, cacheTTL)
+		ac.cache.Set(accesscontrol.GetTeamPermissionCacheKey(1, 1), []accesscontrol.Permission{}, cacheTTL)
+		ac.cache.Set(accesscontrol.GetTeamPermissionCacheKey(1, 2), []accesscontrol.Permission{}, cacheTTL)
+
+		require.NoError(t, ac.ImportSnapshot(ctx, 1, &accesscontrol.Snapshot{Version: accesscontrol.SnapshotVersion}))
+
+		_, ok := ac.cache.Get(accesscontrol.GetUserPermissionCacheKey(signedInUser))
+		assert.False(t, ok)
+		_, ok = ac.cache.Get(accesscontrol.GetTeamPermissionCacheKey(1, 1))
+		assert.False(t, ok)
+		_, ok = ac.cache.Get(accesscontrol.GetTeamPermissionCacheKey(1, 2))
+		assert.True(t, ok, "the cache of other orgs is kept")
+	})
+
+	t.Run("expect import to be rejected when permissions are read-only", func(t *testing.T) {
+		ac := setupTestEnv(t)
+		ac.cfg.RBAC.PermissionsReadOnly = true
+
+		err := ac.ImportSnapshot(ctx, 1, &accesscontrol.Snapshot{Version: accesscontrol.SnapshotVersion})
+		assert.ErrorIs(t, err, accesscontrol.ErrReadOnly)
+	})
+}
+
+func TestService_CopyOrgPermissions(t *testing.T) {
+	ac := setupTestEnv(t)
+	ac.cfg.RBAC.PermissionsReadOnly = true
+
+	err := ac.CopyOrgPermissions(context.Background(), 1, 2)
+	assert.ErrorIs(t, err, accesscontrol.ErrReadOnly)
+}
+
+func TestService_RevokeUserPermissionsByResourceType(t *testing.T) {
+	ac := setupTestEnv(t)
+	ac.cfg.RBAC.PermissionsReadOnly = true
+
+	err := ac.RevokeUserPermissionsByResourceType(context.Background(), 1, 2, "dashboards")
+	assert.ErrorIs(t, err, accesscontrol.ErrReadOnly)
+}
@@ -0,0 +1,86 @@
+package acimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/dualwrite"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/supportbundles"
+)
+
+// supportBundleStats are the access control statistics of the support bundles, they help debugging the performance
+// of access control without access to the database.
+type supportBundleStats struct {
+	*accesscontrol.Stats
+	// ActionSets are the actions of each action set of the action set registry
+	ActionSets map[string][]string `json:"actionSets,omitempty"`
+	// BasicRoleOverrides are the basic roles of the orgs whose permissions differ from the defaults
+	BasicRoleOverrides []accesscontrol.BasicRoleOverride `json:"basicRoleOverrides,omitempty"`
+	// Zanzana is the status of the sync of permissions to zanzana, nil when zanzana is disabled
+	Zanzana *dualwrite.ReconcilerStatus `json:"zanzana,omitempty"`
+}
+
+func (s *Service) registerSupportBundleCollector(supportBundles supportbundles.Service) {
+	if supportBundles == nil {
+		return
+	}
+	supportBundles.RegisterSupportItemCollector(s.supportBundleCollector())
+}
+
+func (s *Service) supportBundleCollector() supportbundles.Collector {
+	return supportbundles.Collector{
+		UID:               "accesscontrol-stats",
+		DisplayName:       "Access control information",
+		Description:       "Anonymized statistics of the roles, permissions and action sets of the Grafana instance",
+		IncludedByDefault: false,
+		Default:           true,
+		Fn: func(ctx context.Context) (*supportbundles.SupportItem, error) {
+			s.log.Info("Generating access control support bundle")
+			stats, err := s.getSupportBundleStats(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := json.MarshalIndent(stats, "", " ")
+			if err != nil {
+				return nil, err
+			}
+			return &supportbundles.SupportItem{
+				Filename:  "accesscontrol.json",
+				FileBytes: data,
+			}, nil
+		},
+	}
+}
+
+func (s *Service) getSupportBundleStats(ctx context.Context) (*supportBundleStats, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.getSupportBundleStats")
+	defer span.End()
+
+	reporter, ok := s.store.(accesscontrol.StatsReporter)
+	if !ok {
+		return nil, errors.New("store does not support statistics")
+	}
+	stats, err := reporter.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &supportBundleStats{Stats: stats}
+	if lister, ok := s.actionResolver.(accesscontrol.ActionSetLister); ok {
+		result.ActionSets = lister.ListActionSets()
+	}
+	if _, ok := s.store.(accesscontrol.BasicRoleSeeder); ok {
+		if result.BasicRoleOverrides, err = s.GetBasicRoleOverrides(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) && s.reconciler != nil {
+		status := s.reconciler.Status()
+		result.Zanzana = &status
+	}
+	return result, nil
+}
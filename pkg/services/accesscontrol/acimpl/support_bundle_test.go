@@ -0,0 +1,31 @@
+package acimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+func TestService_SupportBundleCollector(t *testing.T) {
+	ac := setupTestEnv(t)
+	actionSets := resourcepermissions.NewActionSetService(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSets.StoreActionSet("folders:view", []string{"folders:read"})
+	ac.actionResolver = actionSets
+
+	item, err := ac.supportBundleCollector().Fn(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "accesscontrol.json", item.Filename)
+
+	var stats supportBundleStats
+	require.NoError(t, json.Unmarshal(item.FileBytes, &stats))
+	assert.NotNil(t, stats.RolesByKind)
+	assert.Equal(t, map[string][]string{"folders:view": {"folders:read"}}, stats.ActionSets)
+	// Zanzana is disabled
+	assert.Nil(t, stats.Zanzana)
+}
@@ -0,0 +1,22 @@
+package acimpl
+
+import (
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+func (s *Service) registerUsageStats(store accesscontrol.StatsReporter, usageStats usagestats.Service) {
+	if usageStats == nil {
+		return
+	}
+
+	lister, _ := s.actionResolver.(accesscontrol.ActionSetLister)
+	// Zanzana tuples are only counted when permissions are written to zanzana
+	var tuples accesscontrol.TupleCounter
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		tuples = s.reconciler
+	}
+	usageStats.RegisterMetricsFunc(database.NewUsageStatsCollector(store, lister, tuples).Collect)
+}
@@ -83,6 +83,10 @@ func (f FakeAccessControl) ListObjects(ctx context.Context, in accesscontrol.Lis
 	return nil, nil
 }
 
+func (f FakeAccessControl) ListUsers(ctx context.Context, in accesscontrol.ListUsersRequest) ([]string, error) {
+	return nil, nil
+}
+
 func (f FakeAccessControl) WithoutResolvers() accesscontrol.AccessControl {
 	return f
 }
@@ -112,6 +116,18 @@ func (f FakeStore) SearchUsersPermissions(ctx context.Context, orgID int64, opti
 	return f.ExpectedUsersPermissions, f.ExpectedErr
 }
 
+func (f FakeStore) StreamUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions, fn func(userID int64, permissions []accesscontrol.Permission) error) error {
+	if f.ExpectedErr != nil {
+		return f.ExpectedErr
+	}
+	for userID, permissions := range f.ExpectedUsersPermissions {
+		if err := fn(userID, permissions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f FakeStore) GetUsersBasicRoles(ctx context.Context, userFilter []int64, orgID int64) (map[int64][]string, error) {
 	return f.ExpectedUsersRoles, f.ExpectedErr
 }
@@ -139,6 +155,10 @@ type FakePermissionsService struct {
 	ExpectedPermission   *accesscontrol.ResourcePermission
 	ExpectedPermissions  []accesscontrol.ResourcePermission
 	ExpectedMappedAction string
+	// ExpectedDefaultPermissions are returned by DefaultPermissions
+	ExpectedDefaultPermissions []accesscontrol.SetResourcePermissionCommand
+	// ExpectedPrunedPermissions are returned by MoveResource
+	ExpectedPrunedPermissions []accesscontrol.ResourcePermission
 }
 
 func (f *FakePermissionsService) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
@@ -168,3 +188,11 @@ func (f *FakePermissionsService) DeleteResourcePermissions(ctx context.Context,
 func (f *FakePermissionsService) MapActions(permission accesscontrol.ResourcePermission) string {
 	return f.ExpectedMappedAction
 }
+
+func (f *FakePermissionsService) DefaultPermissions(ctx context.Context, orgID int64, attrs accesscontrol.DefaultPermissionsAttributes) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	return f.ExpectedDefaultPermissions, f.ExpectedErr
+}
+
+func (f *FakePermissionsService) MoveResource(ctx context.Context, orgID int64, cmd accesscontrol.MoveResourceCommand) ([]accesscontrol.ResourcePermission, error) {
+	return f.ExpectedPrunedPermissions, f.ExpectedErr
+}
@@ -0,0 +1,206 @@
+package actest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	SeedResource          = "dashboards"
+	SeedReadAction        = "dashboards:read"
+	SeedWriteAction       = "dashboards:write"
+	seedIDOffset    int64 = 1000000
+)
+
+// SeedOptions describes the data populated by Seed.
+type SeedOptions struct {
+	Users     int
+	Teams     int
+	Resources int
+	// TeamsPerUser and PermissionsPerAssignee are upper bounds, the actual counts are drawn per user and team
+	TeamsPerUser           int
+	PermissionsPerAssignee int
+	// Skew is the exponent of the zipf distribution used to pick teams and resources, it must be greater than 1.
+	// The higher the skew the more memberships and permissions concentrate on the first teams and resources.
+	Skew float64
+	// RandSeed makes the generated data reproducible across runs
+	RandSeed int64
+}
+
+// Seeded holds the identifiers of the data populated by Seed.
+// The first team and resource are the ones with the most members and permissions.
+type Seeded struct {
+	OrgID       int64
+	UserIDs     []int64
+	TeamIDs     []int64
+	ResourceIDs []string
+}
+
+// Seed populates users, teams and managed permissions on dashboards in org 1 with a realistic skew: a few teams
+// have most members and a few dashboards have most permissions. Seeded rows have ids above seedIDOffset to not
+// conflict with the data of the test. The database used depends on GRAFANA_TEST_DB like any other integration test.
+func Seed(t testing.TB, sqlStore db.DB, opts SeedOptions) *Seeded {
+	t.Helper()
+
+	if opts.Skew <= 1 {
+		opts.Skew = 1.1
+	}
+	if opts.TeamsPerUser <= 0 {
+		opts.TeamsPerUser = 1
+	}
+	if opts.PermissionsPerAssignee <= 0 {
+		opts.PermissionsPerAssignee = 1
+	}
+
+	seeded := &Seeded{OrgID: 1}
+	for i := 0; i < opts.Users; i++ {
+		seeded.UserIDs = append(seeded.UserIDs, seedIDOffset+int64(i))
+	}
+	for i := 0; i < opts.Teams; i++ {
+		seeded.TeamIDs = append(seeded.TeamIDs, seedIDOffset+int64(i))
+	}
+	for i := 0; i < opts.Resources; i++ {
+		seeded.ResourceIDs = append(seeded.ResourceIDs, fmt.Sprintf("seed%d", i))
+	}
+
+	now := time.Now()
+	insert := func(beans ...any) error {
+		return sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for _, bean := range beans {
+				if reflect.ValueOf(bean).Elem().Len() == 0 {
+					continue
+				}
+				if _, err := sess.InsertMulti(bean); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// Teams get the role ids following the ones of the users
+	teamRoleID := func(i int) int64 { return seedIDOffset + int64(opts.Users+i) }
+
+	err := ConcurrentBatch(Concurrency, opts.Teams, BatchSize, func(start, end int) error {
+		r := rand.New(rand.NewSource(opts.RandSeed + int64(start)))
+		resources := rand.NewZipf(r, opts.Skew, 1, uint64(max(opts.Resources-1, 0)))
+
+		teams := make([]team.Team, 0, end-start)
+		roles := make([]accesscontrol.Role, 0, end-start)
+		teamRoles := make([]accesscontrol.TeamRole, 0, end-start)
+		var permissions []accesscontrol.Permission
+		for i := start; i < end; i++ {
+			teamID := seeded.TeamIDs[i]
+			teams = append(teams, team.Team{ID: teamID, UID: fmt.Sprintf("seed%d", i), OrgID: seeded.OrgID, Name: fmt.Sprintf("seed%d", i), Created: now, Updated: now})
+			roles = append(roles, seedRole(teamRoleID(i), accesscontrol.ManagedTeamRoleName(teamID), seeded.OrgID, now))
+			teamRoles = append(teamRoles, accesscontrol.TeamRole{OrgID: seeded.OrgID, RoleID: teamRoleID(i), TeamID: teamID, Created: now})
+			if opts.Resources > 0 {
+				permissions = append(permissions, seedPermissions(r, resources, seeded, teamRoleID(i), opts.PermissionsPerAssignee, now)...)
+			}
+		}
+		return insert(&teams, &roles, &teamRoles, &permissions)
+	})
+	require.NoError(t, err, "could not seed teams")
+
+	err = ConcurrentBatch(Concurrency, opts.Users, BatchSize, func(start, end int) error {
+		r := rand.New(rand.NewSource(opts.RandSeed + int64(opts.Teams+start)))
+		teams := rand.NewZipf(r, opts.Skew, 1, uint64(max(opts.Teams-1, 0)))
+		resources := rand.NewZipf(r, opts.Skew, 1, uint64(max(opts.Resources-1, 0)))
+
+		users := make([]user.User, 0, end-start)
+		orgUsers := make([]org.OrgUser, 0, end-start)
+		roles := make([]accesscontrol.Role, 0, end-start)
+		userRoles := make([]accesscontrol.UserRole, 0, end-start)
+		var members []team.TeamMember
+		var permissions []accesscontrol.Permission
+		for i := start; i < end; i++ {
+			userID := seeded.UserIDs[i]
+			users = append(users, user.User{
+				ID:      userID,
+				UID:     fmt.Sprintf("seed%d", i),
+				Login:   fmt.Sprintf("seed%d", i),
+				Email:   fmt.Sprintf("seed%d@example.org", i),
+				Name:    fmt.Sprintf("seed%d", i),
+				OrgID:   seeded.OrgID,
+				Created: now,
+				Updated: now,
+			})
+			orgUsers = append(orgUsers, org.OrgUser{OrgID: seeded.OrgID, UserID: userID, Role: org.RoleViewer, Created: now, Updated: now})
+			roles = append(roles, seedRole(userID, accesscontrol.ManagedUserRoleName(userID), seeded.OrgID, now))
+			userRoles = append(userRoles, accesscontrol.UserRole{OrgID: seeded.OrgID, RoleID: userID, UserID: userID, Created: now})
+
+			if opts.Teams > 0 {
+				joined := map[int64]bool{}
+				for n := r.Intn(opts.TeamsPerUser) + 1; n > 0; n-- {
+					teamID := seeded.TeamIDs[teams.Uint64()]
+					if joined[teamID] {
+						continue
+					}
+					joined[teamID] = true
+					members = append(members, team.TeamMember{OrgID: seeded.OrgID, TeamID: teamID, UserID: userID, Permission: team.PermissionTypeMember, Created: now, Updated: now})
+				}
+			}
+			if opts.Resources > 0 {
+				permissions = append(permissions, seedPermissions(r, resources, seeded, userID, opts.PermissionsPerAssignee, now)...)
+			}
+		}
+		return insert(&users, &orgUsers, &roles, &userRoles, &members, &permissions)
+	})
+	require.NoError(t, err, "could not seed users")
+
+	return seeded
+}
+
+func seedRole(id int64, name string, orgID int64, now time.Time) accesscontrol.Role {
+	return accesscontrol.Role{
+		ID:      id,
+		UID:     fmt.Sprintf("seed_%d", id),
+		Name:    name,
+		OrgID:   orgID,
+		Version: 1,
+		Created: now,
+		Updated: now,
+	}
+}
+
+// seedPermissions grants read, and sometimes write, on up to count resources drawn from the zipf distribution.
+func seedPermissions(r *rand.Rand, resources *rand.Zipf, seeded *Seeded, roleID int64, count int, now time.Time) []accesscontrol.Permission {
+	granted := map[string]bool{}
+	var permissions []accesscontrol.Permission
+	for n := r.Intn(count) + 1; n > 0; n-- {
+		resourceID := seeded.ResourceIDs[resources.Uint64()]
+		if granted[resourceID] {
+			continue
+		}
+		granted[resourceID] = true
+
+		actions := []string{SeedReadAction}
+		if r.Intn(4) == 0 {
+			actions = append(actions, SeedWriteAction)
+		}
+		for _, action := range actions {
+			p := accesscontrol.Permission{
+				RoleID:  roleID,
+				Action:  action,
+				Scope:   accesscontrol.Scope(SeedResource, "uid", resourceID),
+				Created: now,
+				Updated: now,
+			}
+			p.Kind, p.Attribute, p.Identifier = p.SplitScope()
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
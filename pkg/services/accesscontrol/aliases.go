@@ -0,0 +1,81 @@
+package accesscontrol
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// actionAliases maps the previous names of renamed actions to their current name. Permissions and tuples stored
+// before a rename keep the previous name until they are rewritten, resolving the alias keeps them granting the
+// renamed action. Renamed actions are added here instead of being migrated in place.
+var actionAliases = map[string]string{
+	"licensing:update":         "licensing:write",
+	"reports.admin:create":     "reports:create",
+	"reports.admin:write":      "reports:write",
+	"org.users.role:update":    ActionOrgUsersWrite,
+	"users.authtoken:update":   ActionUsersAuthTokenUpdate,
+	"users.password:update":    ActionUsersPasswordUpdate,
+	"users.permissions:update": ActionUsersPermissionsUpdate,
+	"users.quotas:update":      ActionUsersQuotasUpdate,
+	"roles:list":               "roles:read",
+	"teams.roles:list":         "teams.roles:read",
+	"users.roles:list":         "users.roles:read",
+	"users.authtoken:list":     ActionUsersAuthTokenList,
+	"users.quotas:list":        ActionUsersQuotasList,
+	"users.permissions:list":   "users.permissions:read",
+	"alert.instances:update":   ActionAlertingInstanceUpdate,
+	"alert.rules:update":       ActionAlertingRuleUpdate,
+}
+
+// ActionAliases returns the previous names of renamed actions mapped to their current name.
+func ActionAliases() map[string]string {
+	return maps.Clone(actionAliases)
+}
+
+// ResolveActionAlias returns the current name of the action, the action itself when it wasn't renamed. The deny
+// prefix of actions loaded for evaluation is kept.
+func ResolveActionAlias(action string) string {
+	if name, ok := actionAliases[action]; ok {
+		return name
+	}
+	if denied, ok := strings.CutPrefix(action, DenyActionPrefix); ok {
+		if name, ok := actionAliases[denied]; ok {
+			return DenyAction(name)
+		}
+	}
+	return action
+}
+
+// ResolveActionAliases replaces the previous name of renamed actions with their current name in the permissions.
+func ResolveActionAliases(permissions []Permission) []Permission {
+	for i := range permissions {
+		permissions[i].Action = ResolveActionAlias(permissions[i].Action)
+	}
+	return permissions
+}
+
+// ActionAliasesOf returns the previous names of the action, sorted. Searches for the action need to match the
+// permissions stored with them as well.
+func ActionAliasesOf(action string) []string {
+	var aliases []string
+	for alias, name := range actionAliases {
+		if name == action {
+			aliases = append(aliases, alias)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases
+}
+
+// ActionAliasesWithPrefix returns the previous names of the actions whose current name has the prefix, sorted.
+func ActionAliasesWithPrefix(prefix string) []string {
+	var aliases []string
+	for alias, name := range actionAliases {
+		if strings.HasPrefix(name, prefix) && !strings.HasPrefix(alias, prefix) {
+			aliases = append(aliases, alias)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases
+}
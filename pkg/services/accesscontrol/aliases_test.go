@@ -0,0 +1,46 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveActionAlias(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		expected string
+	}{
+		{name: "should resolve a renamed action", action: "roles:list", expected: "roles:read"},
+		{name: "should keep the deny prefix", action: DenyAction("users.quotas:list"), expected: DenyAction(ActionUsersQuotasList)},
+		{name: "should keep an action that wasn't renamed", action: "dashboards:read", expected: "dashboards:read"},
+		{name: "should keep a denied action that wasn't renamed", action: DenyAction("dashboards:read"), expected: DenyAction("dashboards:read")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveActionAlias(tt.action))
+		})
+	}
+}
+
+func TestResolveActionAliases(t *testing.T) {
+	permissions := ResolveActionAliases([]Permission{
+		{Action: "users.permissions:list", Scope: "users:*"},
+		{Action: "teams:read", Scope: "teams:*"},
+	})
+	assert.Equal(t, []Permission{
+		{Action: "users.permissions:read", Scope: "users:*"},
+		{Action: "teams:read", Scope: "teams:*"},
+	}, permissions)
+}
+
+func TestActionAliasesOf(t *testing.T) {
+	assert.Equal(t, []string{"reports.admin:write"}, ActionAliasesOf("reports:write"))
+	assert.Empty(t, ActionAliasesOf("dashboards:read"))
+}
+
+func TestActionAliasesWithPrefix(t *testing.T) {
+	assert.Equal(t, []string{"reports.admin:create", "reports.admin:write"}, ActionAliasesWithPrefix("reports:"))
+	assert.Empty(t, ActionAliasesWithPrefix("roles:"), "aliases sharing the prefix are already matched by it")
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -10,6 +12,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/web"
 	"go.opentelemetry.io/otel"
 )
 
@@ -41,6 +44,23 @@ func (api *AccessControlAPI) RegisterAPIEndpoints() {
 		if api.features.IsEnabledGlobally(featuremgmt.FlagAccessControlOnCall) {
 			rr.Get("/users/permissions/search", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.searchUsersPermissions))
 		}
+		if _, ok := api.Service.(ac.Snapshotter); ok {
+			rr.Get("/snapshot", authorize(ac.EvalPermission(ac.ActionRolesSnapshotRead)), routing.Wrap(api.exportSnapshot))
+			rr.Post("/snapshot", authorize(ac.EvalPermission(ac.ActionRolesSnapshotWrite)), routing.Wrap(api.importSnapshot))
+		}
+		if _, ok := api.Service.(ac.JobStatusReporter); ok {
+			rr.Get("/jobs", middleware.ReqGrafanaAdmin, routing.Wrap(api.getJobStatuses))
+		}
+		if _, ok := api.Service.(ac.TeamMembershipPreviewer); ok {
+			rr.Get("/teams/:teamId/members/:userId/preview", authorize(ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsID)), routing.Wrap(api.previewTeamMembership))
+		}
+		if _, ok := api.Service.(ac.UserDeletionPreviewer); ok {
+			rr.Get("/users/:userId/deletion-preview", authorize(ac.EvalPermission(ac.ActionOrgUsersRemove, ac.Scope("users", "id", ac.Parameter(":userId")))), routing.Wrap(api.previewUserDeletion(false)))
+			rr.Get("/global/users/:userId/deletion-preview", authorize(ac.EvalPermission(ac.ActionUsersDelete, ac.Scope("global.users", "id", ac.Parameter(":userId")))), routing.Wrap(api.previewUserDeletion(true)))
+		}
+		if _, ok := api.Service.(ac.UserPermissionsRevoker); ok {
+			rr.Delete("/users/:userId/permissions/:resource", authorize(ac.EvalPermission(ac.ActionOrgUsersRemove, ac.Scope("users", "id", ac.Parameter(":userId")))), routing.Wrap(api.revokeUserPermissions))
+		}
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
 }
 
@@ -106,3 +126,124 @@ func (api *AccessControlAPI) searchUsersPermissions(c *contextmodel.ReqContext)
 
 	return response.JSON(http.StatusOK, permsByAction)
 }
+
+// GET /api/access-control/snapshot
+func (api *AccessControlAPI) exportSnapshot(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.exportSnapshot")
+	defer span.End()
+
+	snapshot, err := api.Service.(ac.Snapshotter).ExportSnapshot(ctx, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "could not export snapshot", err)
+	}
+
+	return response.JSON(http.StatusOK, snapshot)
+}
+
+// POST /api/access-control/snapshot
+func (api *AccessControlAPI) importSnapshot(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.importSnapshot")
+	defer span.End()
+
+	snapshot := ac.Snapshot{}
+	if err := web.Bind(c.Req, &snapshot); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	// The snapshot replaces every role of the org, it cannot grant permissions the signed in user doesn't hold
+	permissions, err := api.Service.GetUserPermissions(ctx, c.SignedInUser, ac.Options{})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "could not get user permissions", err)
+	}
+	held := ac.GroupScopesByActionContext(ctx, permissions)
+	for _, r := range snapshot.Roles {
+		for _, p := range r.Permissions {
+			evaluator := ac.EvalPermission(p.Action)
+			if p.Scope != "" {
+				evaluator = ac.EvalPermission(p.Action, p.Scope)
+			}
+			if !evaluator.Evaluate(held) {
+				return response.Error(http.StatusForbidden, fmt.Sprintf("cannot import role %s: permission %s on %q is not held by the user", r.UID, p.Action, p.Scope), nil)
+			}
+		}
+	}
+
+	if err := api.Service.(ac.Snapshotter).ImportSnapshot(ctx, c.SignedInUser.GetOrgID(), &snapshot); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not import snapshot", err)
+	}
+
+	return response.Success("Snapshot imported")
+}
+
+// GET /api/access-control/teams/:teamId/members/:userId/preview
+func (api *AccessControlAPI) previewTeamMembership(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.previewTeamMembership")
+	defer span.End()
+
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	permissions, err := api.Service.(ac.TeamMembershipPreviewer).PreviewTeamMembership(ctx, c.SignedInUser.GetOrgID(), userID, teamID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not preview team membership", err)
+	}
+
+	return response.JSON(http.StatusOK, ac.GroupScopesByActionContext(ctx, permissions))
+}
+
+// GET /api/access-control/users/:userId/deletion-preview
+// GET /api/access-control/global/users/:userId/deletion-preview
+func (api *AccessControlAPI) previewUserDeletion(global bool) func(c *contextmodel.ReqContext) response.Response {
+	return func(c *contextmodel.ReqContext) response.Response {
+		ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.previewUserDeletion")
+		defer span.End()
+
+		userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "userId is invalid", err)
+		}
+
+		// Removing a user from the org only removes its access in the org, deleting the user removes it everywhere
+		orgID := c.SignedInUser.GetOrgID()
+		if global {
+			orgID = ac.GlobalOrgID
+		}
+
+		preview, err := api.Service.(ac.UserDeletionPreviewer).PreviewDeleteUserPermissions(ctx, orgID, userID)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "could not preview user deletion", err)
+		}
+
+		return response.JSON(http.StatusOK, preview)
+	}
+}
+
+// DELETE /api/access-control/users/:userId/permissions/:resource
+func (api *AccessControlAPI) revokeUserPermissions(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.revokeUserPermissions")
+	defer span.End()
+
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "userId is invalid", err)
+	}
+
+	resource := web.Params(c.Req)[":resource"]
+	err = api.Service.(ac.UserPermissionsRevoker).RevokeUserPermissionsByResourceType(ctx, c.SignedInUser.GetOrgID(), userID, resource)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not revoke user permissions", err)
+	}
+
+	return response.Success("User permissions revoked")
+}
+
+// GET /api/access-control/jobs
+func (api *AccessControlAPI) getJobStatuses(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, api.Service.(ac.JobStatusReporter).JobStatuses())
+}
@@ -1,17 +1,21 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/jobstatus"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -199,3 +203,163 @@ func TestAccessControlAPI_searchUsersPermissions(t *testing.T) {
 		})
 	}
 }
+
+type fakeTeamMembershipPreviewer struct {
+	actest.FakeService
+	expectedPermissions []ac.Permission
+}
+
+func (f fakeTeamMembershipPreviewer) PreviewTeamMembership(ctx context.Context, orgID, userID, teamID int64) ([]ac.Permission, error) {
+	return f.expectedPermissions, nil
+}
+
+func TestAPI_previewTeamMembership(t *testing.T) {
+	type testCase struct {
+		desc           string
+		url            string
+		expectedOutput map[string][]string
+		expectedCode   int
+	}
+
+	tests := []testCase{
+		{
+			desc:           "Should return the permissions gained by joining the team",
+			url:            "/api/access-control/teams/1/members/2/preview",
+			expectedOutput: map[string][]string{"dashboards:read": {"dashboards:uid:1"}},
+			expectedCode:   http.StatusOK,
+		},
+		{
+			desc:         "Should fail on invalid user id",
+			url:          "/api/access-control/teams/1/members/abc/preview",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			acSvc := fakeTeamMembershipPreviewer{expectedPermissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:1"}}}
+			accessControl := actest.FakeAccessControl{ExpectedEvaluate: true} // Always allow access to the endpoint
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, featuremgmt.WithFeatures())
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewGetRequest(tt.url)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{},
+			})
+			res, err := server.Send(req)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var output map[string][]string
+				err := json.NewDecoder(res.Body).Decode(&output)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+type fakeUserPermissionsRevoker struct {
+	actest.FakeService
+	revoked *[]string
+}
+
+func (f fakeUserPermissionsRevoker) RevokeUserPermissionsByResourceType(ctx context.Context, orgID, userID int64, resource string) error {
+	*f.revoked = append(*f.revoked, resource)
+	return nil
+}
+
+func TestAPI_revokeUserPermissions(t *testing.T) {
+	tests := []struct {
+		desc            string
+		url             string
+		expectedRevoked []string
+		expectedCode    int
+	}{
+		{
+			desc:            "Should revoke the permissions of the user on the resources of the type",
+			url:             "/api/access-control/users/2/permissions/datasources",
+			expectedRevoked: []string{"datasources"},
+			expectedCode:    http.StatusOK,
+		},
+		{
+			desc:         "Should fail on invalid user id",
+			url:          "/api/access-control/users/abc/permissions/datasources",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var revoked []string
+			acSvc := fakeUserPermissionsRevoker{revoked: &revoked}
+			accessControl := actest.FakeAccessControl{ExpectedEvaluate: true} // Always allow access to the endpoint
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, featuremgmt.WithFeatures())
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewRequest(http.MethodDelete, tt.url, nil)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{}})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+			require.Equal(t, tt.expectedRevoked, revoked)
+		})
+	}
+}
+
+type fakeJobStatusReporter struct {
+	actest.FakeService
+	expectedStatuses []jobstatus.Status
+}
+
+func (f fakeJobStatusReporter) JobStatuses() []jobstatus.Status {
+	return f.expectedStatuses
+}
+
+func TestAPI_getJobStatuses(t *testing.T) {
+	tests := []struct {
+		desc         string
+		user         *user.SignedInUser
+		expectedCode int
+	}{
+		{
+			desc:         "Should return the status of the background jobs to server admins",
+			user:         &user.SignedInUser{OrgID: 1, IsGrafanaAdmin: true},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "Should be forbidden to other users",
+			user:         &user.SignedInUser{OrgID: 1, OrgRole: org.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			statuses := []jobstatus.Status{{Name: "zanzana-reconciliation", Interval: time.Hour, Runs: 2, LastError: "failed"}}
+			acSvc := fakeJobStatusReporter{expectedStatuses: statuses}
+			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, featuremgmt.WithFeatures())
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewGetRequest("/api/access-control/jobs")
+			webtest.RequestWithSignedInUser(req, tt.user)
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var output []jobstatus.Status
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&output))
+				require.Equal(t, statuses, output)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/grafana/authlib/claims"
+
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 )
 
@@ -41,3 +43,22 @@ func GetBasicRolePermissionCacheKey(role string, orgID int64) string {
 func GetTeamPermissionCacheKey(teamID int64, orgID int64) string {
 	return fmt.Sprintf("rbac-permissions-team-%d-%d", orgID, teamID)
 }
+
+// GetUserPermissionCacheKeySuffixes returns the suffixes of the keys caching the permissions of the user or service
+// account with the id, in any org.
+func GetUserPermissionCacheKeySuffixes(userID int64) []string {
+	return []string{
+		fmt.Sprintf("-%s-%d", claims.TypeUser, userID),
+		fmt.Sprintf("-%s-%d", claims.TypeServiceAccount, userID),
+	}
+}
+
+// GetOrgPermissionCacheKeyPrefixes returns the prefixes of the keys caching permissions resolved in the org.
+func GetOrgPermissionCacheKeyPrefixes(orgID int64) []string {
+	return []string{
+		fmt.Sprintf("rbac-permissions-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-direct-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-basic-role-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-team-%d-", orgID),
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var _ accesscontrol.ActionAliasRewriter = &AccessControlStore{}
+
+// RewriteActionAliases renames up to limit permissions stored with the previous name of a renamed action and returns
+// the number of permissions rewritten. Permissions whose role already has the renamed action on the scope are removed
+// instead, the role can't hold the same permission twice.
+func (s *AccessControlStore) RewriteActionAliases(ctx context.Context, limit int) (int, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.RewriteActionAliases")
+	defer span.End()
+
+	aliases := accesscontrol.ActionAliases()
+	if len(aliases) == 0 || limit <= 0 {
+		return 0, nil
+	}
+	previousNames := make([]any, 0, len(aliases))
+	for alias := range aliases {
+		previousNames = append(previousNames, alias)
+	}
+
+	var rewritten int
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rewritten = 0
+		var permissions []accesscontrol.Permission
+		err := sess.Table("permission").Where("action IN (?"+strings.Repeat(",?", len(previousNames)-1)+")", previousNames...).
+			Asc("id").Limit(limit).Find(&permissions)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, p := range permissions {
+			name := aliases[p.Action]
+			exists, err := sess.Table("permission").Where("role_id = ? AND action = ? AND scope = ? AND deny = ?", p.RoleID, name, p.Scope, p.Deny).Exist()
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				_, err = sess.Exec("DELETE FROM permission WHERE id = ?", p.ID)
+			} else {
+				_, err = sess.Exec("UPDATE permission SET action = ?, updated = ? WHERE id = ?", name, now, p.ID)
+			}
+			if err != nil {
+				return err
+			}
+			rewritten++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rewritten, nil
+}
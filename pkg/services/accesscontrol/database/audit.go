@@ -0,0 +1,97 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// PermissionAuditFilter selects the managed permissions recorded as removed in the permission audit log.
+// Zero fields are ignored, the permissions of every org are selected when OrgID is the global org.
+type PermissionAuditFilter struct {
+	OrgID      int64
+	Resource   string
+	ResourceID string
+	UserID     int64
+	TeamID     int64
+}
+
+type permissionAuditRow struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	OrgID       int64     `xorm:"org_id"`
+	Resource    string    `xorm:"resource"`
+	ResourceID  string    `xorm:"resource_id"`
+	UserID      int64     `xorm:"user_id"`
+	TeamID      int64     `xorm:"team_id"`
+	BuiltInRole string    `xorm:"builtin_role"`
+	GroupID     string    `xorm:"group_id"`
+	Permission  string    `xorm:"permission"`
+	Actions     string    `xorm:"actions"`
+	Created     time.Time `xorm:"created"`
+}
+
+func (permissionAuditRow) TableName() string {
+	return "permission_audit"
+}
+
+// RecordPermissionRemovals adds an entry without permission to the permission audit log for every assignee and
+// resource matching the filter whose last recorded permission was not removed yet, so permissions removed in bulk
+// (deleted resources, users and teams, revocations) show in the permission diffs.
+// It must run in the session removing the permissions.
+func RecordPermissionRemovals(sess *db.Session, filter PermissionAuditFilter) error {
+	var conditions []string
+	var args []any
+	if filter.OrgID != 0 {
+		conditions = append(conditions, "org_id = ?")
+		args = append(args, filter.OrgID)
+	}
+	if filter.Resource != "" {
+		conditions = append(conditions, "resource = ?")
+		args = append(args, filter.Resource)
+	}
+	if filter.ResourceID != "" {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, filter.ResourceID)
+	}
+	if filter.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.TeamID != 0 {
+		conditions = append(conditions, "team_id = ?")
+		args = append(args, filter.TeamID)
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	var latest []permissionAuditRow
+	err := sess.SQL(
+		"SELECT * FROM permission_audit WHERE id IN (SELECT MAX(id) FROM permission_audit WHERE "+strings.Join(conditions, " AND ")+
+			" GROUP BY org_id, resource, resource_id, user_id, team_id, builtin_role, group_id)",
+		args...,
+	).Find(&latest)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	removals := make([]permissionAuditRow, 0, len(latest))
+	for _, e := range latest {
+		if e.Permission == "" {
+			continue
+		}
+		e.ID = 0
+		e.Permission = ""
+		e.Actions = ""
+		e.Created = now
+		removals = append(removals, e)
+	}
+	if len(removals) == 0 {
+		return nil
+	}
+
+	_, err = sess.InsertMulti(&removals)
+	return err
+}
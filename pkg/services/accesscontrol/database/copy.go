@@ -0,0 +1,272 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ accesscontrol.OrgCopier = &AccessControlStore{}
+
+const (
+	managedTeamRolePrefix = "managed:teams:"
+	managedUserRolePrefix = "managed:users:"
+	managedRoleSuffix     = ":permissions"
+)
+
+func (s *AccessControlStore) CopyOrgPermissions(ctx context.Context, srcOrgID, dstOrgID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.CopyOrgPermissions")
+	defer span.End()
+
+	if srcOrgID == dstOrgID || srcOrgID == accesscontrol.GlobalOrgID || dstOrgID == accesscontrol.GlobalOrgID {
+		return errors.New("source and destination orgs must be different non global orgs")
+	}
+
+	snapshot, err := s.ExportSnapshot(ctx, srcOrgID)
+	if err != nil {
+		return err
+	}
+
+	var events []webhook.Event
+	err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		events = nil
+		teams, err := teamIDMapping(sess, srcOrgID, dstOrgID)
+		if err != nil {
+			return err
+		}
+
+		// Permissions are read from the source roles rather than the snapshot, so every stored column is copied
+		permissions, err := permissionsByRoleUID(sess, srcOrgID)
+		if err != nil {
+			return err
+		}
+
+		var memberIDs []int64
+		if err := sess.SQL("SELECT user_id FROM org_user WHERE org_id = ?", dstOrgID).Find(&memberIDs); err != nil {
+			return err
+		}
+		members := toSet(memberIDs)
+
+		now := time.Now()
+		for _, r := range snapshot.Roles {
+			name, ok := remapManagedRoleName(r.Name, teams, members)
+			if !ok {
+				continue
+			}
+
+			role := accesscontrol.Role{}
+			has, err := sess.Where("org_id = ? AND name = ?", dstOrgID, name).Get(&role)
+			if err != nil {
+				return err
+			}
+			if !has {
+				role = accesscontrol.Role{
+					OrgID:       dstOrgID,
+					Version:     r.Version,
+					UID:         util.GenerateShortUID(),
+					Name:        name,
+					DisplayName: r.DisplayName,
+					Description: r.Description,
+					Group:       r.Group,
+					Hidden:      r.Hidden,
+					Created:     now,
+					Updated:     now,
+				}
+				if _, err := sess.Insert(&role); err != nil {
+					return err
+				}
+			}
+
+			if err := copyPermissions(ctx, sess, role.ID, permissions[r.UID], now); err != nil {
+				return err
+			}
+
+			for _, userID := range r.Users {
+				if _, ok := members[userID]; !ok {
+					continue
+				}
+				if err := insertMissing(sess, &accesscontrol.UserRole{OrgID: dstOrgID, RoleID: role.ID, UserID: userID, Created: now},
+					"org_id = ? AND role_id = ? AND user_id = ?", dstOrgID, role.ID, userID); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(dstOrgID, role.Name, webhook.Event{UserID: userID}))
+			}
+			for _, teamID := range r.Teams {
+				dstTeamID, ok := teams[teamID]
+				if !ok {
+					continue
+				}
+				if err := insertMissing(sess, &accesscontrol.TeamRole{OrgID: dstOrgID, RoleID: role.ID, TeamID: dstTeamID, Created: now},
+					"org_id = ? AND role_id = ? AND team_id = ?", dstOrgID, role.ID, dstTeamID); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(dstOrgID, role.Name, webhook.Event{TeamID: dstTeamID}))
+			}
+			for _, builtIn := range r.BuiltInRoles {
+				if err := insertMissing(sess, &accesscontrol.BuiltinRole{OrgID: dstOrgID, RoleID: role.ID, Role: builtIn, Created: now, Updated: now},
+					"org_id = ? AND role_id = ? AND role = ?", dstOrgID, role.ID, builtIn); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(dstOrgID, role.Name, webhook.Event{BuiltInRole: builtIn}))
+			}
+			for _, group := range r.Groups {
+				if err := insertMissing(sess, &accesscontrol.GroupRole{OrgID: dstOrgID, RoleID: role.ID, GroupID: group, Created: now},
+					"org_id = ? AND role_id = ? AND group_id = ?", dstOrgID, role.ID, group); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(dstOrgID, role.Name, webhook.Event{GroupID: group}))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.webhook.Notify(ctx, events...)
+	return nil
+}
+
+// permissionsByRoleUID returns the permissions of the roles stored in the org, grouped by role uid.
+func permissionsByRoleUID(sess *db.Session, orgID int64) (map[string][]accesscontrol.Permission, error) {
+	var roles []accesscontrol.Role
+	if err := sess.Where("org_id = ?", orgID).Cols("id", "uid").Find(&roles); err != nil {
+		return nil, err
+	}
+	uids := make(map[int64]string, len(roles))
+	for _, r := range roles {
+		uids[r.ID] = r.UID
+	}
+
+	var permissions []accesscontrol.Permission
+	if err := sess.SQL("SELECT p.* FROM permission AS p INNER JOIN role AS r ON r.id = p.role_id WHERE r.org_id = ? ORDER BY p.id", orgID).Find(&permissions); err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[string][]accesscontrol.Permission, len(roles))
+	for _, p := range permissions {
+		byRole[uids[p.RoleID]] = append(byRole[uids[p.RoleID]], p)
+	}
+	return byRole, nil
+}
+
+// teamIDMapping maps the ids of the teams in the source org to the ids of the teams with the same name in the destination org.
+func teamIDMapping(sess *db.Session, srcOrgID, dstOrgID int64) (map[int64]int64, error) {
+	type team struct {
+		ID    int64 `xorm:"id"`
+		OrgID int64 `xorm:"org_id"`
+		Name  string
+	}
+
+	var teams []team
+	if err := sess.SQL("SELECT id, org_id, name FROM team WHERE org_id IN (?, ?)", srcOrgID, dstOrgID).Find(&teams); err != nil {
+		return nil, err
+	}
+
+	dst := make(map[string]int64)
+	for _, t := range teams {
+		if t.OrgID == dstOrgID {
+			dst[t.Name] = t.ID
+		}
+	}
+
+	mapping := make(map[int64]int64)
+	for _, t := range teams {
+		if t.OrgID != srcOrgID {
+			continue
+		}
+		if id, ok := dst[t.Name]; ok {
+			mapping[t.ID] = id
+		}
+	}
+	return mapping, nil
+}
+
+// remapManagedRoleName returns the name of the role in the destination org.
+// Managed team and user roles embed the id of their assignee, false is returned when the assignee doesn't exist there.
+func remapManagedRoleName(name string, teams map[int64]int64, members map[int64]struct{}) (string, bool) {
+	if id, ok := managedRoleID(name, managedTeamRolePrefix); ok {
+		dstID, ok := teams[id]
+		if !ok {
+			return "", false
+		}
+		return accesscontrol.ManagedTeamRoleName(dstID), true
+	}
+
+	if id, ok := managedRoleID(name, managedUserRolePrefix); ok {
+		_, ok := members[id]
+		return name, ok
+	}
+
+	return name, true
+}
+
+func managedRoleID(name, prefix string) (int64, bool) {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, managedRoleSuffix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// copyPermissions adds the permissions missing from the role, existing permissions are kept.
+// A deny and a grant of the same action and scope are different permissions.
+func copyPermissions(ctx context.Context, sess *db.Session, roleID int64, permissions []accesscontrol.Permission, now time.Time) error {
+	stored, err := getRolePermissions(ctx, sess, roleID)
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		Action, Scope string
+		Deny          bool
+	}
+	existing := make(map[key]struct{}, len(stored))
+	for _, p := range stored {
+		existing[key{p.Action, p.Scope, p.Deny}] = struct{}{}
+	}
+
+	added := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		if _, ok := existing[key{p.Action, p.Scope, p.Deny}]; ok {
+			continue
+		}
+		existing[key{p.Action, p.Scope, p.Deny}] = struct{}{}
+
+		permission := accesscontrol.Permission{RoleID: roleID, Action: p.Action, Scope: p.Scope, Deny: p.Deny, Created: now, Updated: now}
+		permission.Kind, permission.Attribute, permission.Identifier = permission.SplitScope()
+		added = append(added, permission)
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+	_, err = sess.Insert(&added)
+	return err
+}
+
+// insertMissing inserts bean unless a row matching the condition already exists.
+func insertMissing(sess *db.Session, bean any, query string, args ...any) error {
+	exists, err := sess.Table(bean).Where(query, args...).Exist()
+	if err != nil || exists {
+		return err
+	}
+	_, err = sess.Insert(bean)
+	return err
+}
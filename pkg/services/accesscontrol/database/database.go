@@ -3,11 +3,16 @@ package database
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/slowquery"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+	"github.com/grafana/grafana/pkg/services/org"
 	"go.opentelemetry.io/otel"
 )
 
@@ -40,11 +45,27 @@ const (
 )
 
 func ProvideService(sql db.DB) *AccessControlStore {
-	return &AccessControlStore{sql}
+	return &AccessControlStore{sql: sql}
 }
 
 type AccessControlStore struct {
 	sql db.DB
+	// webhook is notified about committed role assignment changes, nil when no webhook is configured
+	webhook *webhook.Notifier
+	// slowQueries logs the plan of slow permission searches, nil when disabled
+	slowQueries *slowquery.Logger
+}
+
+// WithWebhook configures the store to notify n about role assignment changes.
+func (s *AccessControlStore) WithWebhook(n *webhook.Notifier) *AccessControlStore {
+	s.webhook = n
+	return s
+}
+
+// WithSlowQueryLog configures the store to log the plan of slow permission searches with l.
+func (s *AccessControlStore) WithSlowQueryLog(l *slowquery.Logger) *AccessControlStore {
+	s.slowQueries = l
+	return s
 }
 
 func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) ([]accesscontrol.Permission, error) {
@@ -63,7 +84,8 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 		q := `
 		SELECT
 			permission.action,
-			permission.scope
+			permission.scope,
+			permission.deny
 			FROM permission
 			INNER JOIN role ON role.id = permission.role_id
 		` + filter
@@ -81,6 +103,7 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 		return nil
 	})
 
+	accesscontrol.PrefixDenyActions(result)
 	return result, err
 }
 
@@ -96,13 +119,19 @@ type teamPermission struct {
 	TeamID int64 `xorm:"team_id"`
 	Action string
 	Scope  string
+	Deny   bool
 }
 
 func (p teamPermission) Permission() accesscontrol.Permission {
-	return accesscontrol.Permission{
+	permission := accesscontrol.Permission{
 		Action: p.Action,
 		Scope:  p.Scope,
+		Deny:   p.Deny,
 	}
+	if p.Deny {
+		permission.Action = accesscontrol.DenyAction(p.Action)
+	}
+	return permission
 }
 
 func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) (map[int64][]accesscontrol.Permission, error) {
@@ -123,6 +152,7 @@ func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query acce
 		SELECT
 			permission.action,
 			permission.scope,
+			permission.deny,
 			all_role.team_id
 		FROM permission
 		INNER JOIN role ON role.id = permission.role_id
@@ -163,129 +193,290 @@ func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query acce
 	return teamPermissions, err
 }
 
+var _ accesscontrol.TeamResourceCounter = &AccessControlStore{}
+
+func (s *AccessControlStore) GetTeamsResourceCounts(ctx context.Context, orgID int64, teamIDs []int64) (map[int64]map[string]int64, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetTeamsResourceCounts")
+	defer span.End()
+
+	type resourceCount struct {
+		TeamID int64  `xorm:"team_id"`
+		Kind   string `xorm:"kind"`
+		Count  int64  `xorm:"count"`
+	}
+	var counts []resourceCount
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		// Wildcard scopes grant access to every resource of a kind and are not counted
+		q := `
+		SELECT tr.team_id, p.kind, COUNT(DISTINCT p.identifier) AS count
+		FROM team_role AS tr
+		INNER JOIN role AS r ON r.id = tr.role_id
+		INNER JOIN permission AS p ON p.role_id = r.id
+		WHERE tr.org_id = ? AND r.name LIKE 'managed:teams:%' AND p.deny = ` + s.sql.GetDialect().BooleanStr(false) + `
+			AND p.identifier <> '' AND p.identifier <> '*'
+		`
+		params := []any{orgID}
+		if len(teamIDs) > 0 {
+			q += ` AND tr.team_id IN (?` + strings.Repeat(", ?", len(teamIDs)-1) + `)`
+			for _, id := range teamIDs {
+				params = append(params, id)
+			}
+		}
+		q += ` GROUP BY tr.team_id, p.kind`
+
+		return sess.SQL(q, params...).Find(&counts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]map[string]int64)
+	for _, c := range counts {
+		if result[c.TeamID] == nil {
+			result[c.TeamID] = make(map[string]int64)
+		}
+		result[c.TeamID][c.Kind] = c.Count
+	}
+	return result, nil
+}
+
+// CountRoles returns the number of roles and role assignments of the org. The roles and assignments declared
+// for all orgs are not counted.
+func (s *AccessControlStore) CountRoles(ctx context.Context, orgID int64) (roles int64, assignments int64, err error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.CountRoles")
+	defer span.End()
+
+	var counts struct {
+		Roles       int64 `xorm:"roles"`
+		Assignments int64 `xorm:"assignments"`
+	}
+	err = s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		q := `
+		SELECT
+			(SELECT COUNT(*) FROM role WHERE org_id = ?) AS roles,
+			(SELECT COUNT(*) FROM user_role WHERE org_id = ?) +
+			(SELECT COUNT(*) FROM team_role WHERE org_id = ?) +
+			(SELECT COUNT(*) FROM builtin_role WHERE org_id = ?) AS assignments
+		`
+		_, err := sess.SQL(q, orgID, orgID, orgID, orgID).Get(&counts)
+		return err
+	})
+	return counts.Roles, counts.Assignments, err
+}
+
+// userRBACPermission is a permission of a user returned by the search of the users permissions.
+type userRBACPermission struct {
+	UserID int64  `xorm:"user_id"`
+	Action string `xorm:"action"`
+	Scope  string `xorm:"scope"`
+	Deny   bool   `xorm:"deny"`
+}
+
 // SearchUsersPermissions returns the list of user permissions in specific organization indexed by UserID
 func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions) (map[int64][]accesscontrol.Permission, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.database.SearchUsersPermissions")
 	defer span.End()
 
-	type UserRBACPermission struct {
-		UserID int64  `xorm:"user_id"`
-		Action string `xorm:"action"`
-		Scope  string `xorm:"scope"`
+	dbPerms := make([]userRBACPermission, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		q, params, ok, err := s.usersPermissionsQuery(orgID, options)
+		if err != nil || !ok {
+			return err
+		}
+		return s.slowQueries.Find(ctx, sess, "SearchUsersPermissions", &dbPerms, q, params...)
+	})
+	if err != nil {
+		return nil, err
 	}
-	dbPerms := make([]UserRBACPermission, 0)
 
-	userID := int64(-1)
-	if options.TypedID != "" {
-		var err error
-		userID, err = options.ComputeUserID()
-		if err != nil {
-			return nil, err
-		}
+	// The permissions of all users are sliced from a single allocation instead of growing a slice per user
+	counts := map[int64]int{}
+	for i := range dbPerms {
+		counts[dbPerms[i].UserID]++
+	}
+	slab := make([]accesscontrol.Permission, len(dbPerms))
+	mapped := make(map[int64][]accesscontrol.Permission, len(counts))
+	offset := 0
+	for userID, count := range counts {
+		mapped[userID] = slab[offset : offset : offset+count]
+		offset += count
+	}
+	for i := range dbPerms {
+		mapped[dbPerms[i].UserID] = append(mapped[dbPerms[i].UserID], accesscontrol.Permission{Action: dbPerms[i].Action, Scope: dbPerms[i].Scope, Deny: dbPerms[i].Deny})
+	}
+	for _, permissions := range mapped {
+		accesscontrol.PrefixDenyActions(permissions)
 	}
 
-	if err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
-		roleNameFilterJoin := ""
-		if len(options.RolePrefixes) > 0 {
-			roleNameFilterJoin = "INNER JOIN role AS r ON up.role_id = r.id"
+	return mapped, nil
+}
+
+// StreamUsersPermissions calls fn with the permissions of each user of the organization ordered by UserID, only the
+// permissions of a single user are held in memory.
+func (s *AccessControlStore) StreamUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions, fn func(userID int64, permissions []accesscontrol.Permission) error) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.StreamUsersPermissions")
+	defer span.End()
+
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		q, params, ok, err := s.usersPermissionsQuery(orgID, options)
+		if err != nil || !ok {
+			return err
 		}
 
-		params := []any{}
+		rows, err := sess.SQL(q+" ORDER BY user_id", params...).Rows(new(userRBACPermission))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
 
-		direct := userAssignsSQL
-		if userID >= 0 {
-			direct += " WHERE ur.user_id = ?"
-			params = append(params, userID)
+		userID := int64(-1)
+		var permissions []accesscontrol.Permission
+		flush := func() error {
+			if len(permissions) == 0 {
+				return nil
+			}
+			accesscontrol.PrefixDenyActions(permissions)
+			return fn(userID, permissions)
 		}
 
-		team := teamAssignsSQL
-		if userID >= 0 {
-			team += " WHERE tm.user_id = ?"
-			params = append(params, userID)
+		for rows.Next() {
+			var p userRBACPermission
+			if err := rows.Scan(&p); err != nil {
+				return err
+			}
+			if p.UserID != userID {
+				if err := flush(); err != nil {
+					return err
+				}
+				userID, permissions = p.UserID, nil
+			}
+			permissions = append(permissions, accesscontrol.Permission{Action: p.Action, Scope: p.Scope, Deny: p.Deny})
 		}
+		return flush()
+	})
+}
 
-		basic := basicRoleAssignsSQL
-		if userID >= 0 {
-			basic += " WHERE ou.user_id = ?"
-			params = append(params, userID)
+// usersPermissionsQuery returns the query of the permissions of the users matching options, ok is false when the
+// identity of options has no stored permissions.
+func (s *AccessControlStore) usersPermissionsQuery(orgID int64, options accesscontrol.SearchOptions) (string, []any, bool, error) {
+	userID := int64(-1)
+	identityJoin := ""
+	if options.TypedID != "" {
+		identityType, id, err := options.ComputeIdentity()
+		if err != nil {
+			return "", nil, false, err
 		}
 
-		grafanaAdmin := fmt.Sprintf(grafanaAdminAssignsSQL, s.sql.Quote("user"))
-		params = append(params, accesscontrol.RoleGrafanaAdmin)
-		if userID >= 0 {
-			grafanaAdmin += " AND sa.user_id = ?"
-			params = append(params, userID)
+		// The render service has no stored permissions
+		if identityType == claims.TypeRenderService {
+			return "", nil, false, nil
 		}
 
-		// Find permissions
-		q := `
-		SELECT
-			user_id,
-			p.action,
-			p.scope
-		FROM (
-			` + direct + `
-			UNION ALL
-			` + team + `
-			UNION ALL
-			` + basic + `
-			UNION ALL
-			` + grafanaAdmin + `
-		) AS up ` + roleNameFilterJoin + `
-		INNER JOIN permission AS p ON up.role_id = p.role_id
-		WHERE (up.org_id = ? OR up.org_id = ?)
-		`
-		params = append(params, orgID, accesscontrol.GlobalOrgID)
+		// The typed id must match the kind of identity stored, so that a user id is never resolved as a service
+		// account or the other way around. It is checked by the query rather than looked up beforehand.
+		identityJoin = "INNER JOIN " + s.sql.Quote("user") + " AS iu ON iu.id = up.user_id AND iu.is_service_account = " +
+			s.sql.GetDialect().BooleanStr(identityType == claims.TypeServiceAccount)
+		userID = id
+	}
 
-		if options.ActionPrefix != "" {
+	roleNameFilterJoin := ""
+	if len(options.RolePrefixes) > 0 {
+		roleNameFilterJoin = "INNER JOIN role AS r ON up.role_id = r.id"
+	}
+
+	params := []any{}
+
+	direct := userAssignsSQL
+	if userID >= 0 {
+		direct += " WHERE ur.user_id = ?"
+		params = append(params, userID)
+	}
+
+	team := teamAssignsSQL
+	if userID >= 0 {
+		team += " WHERE tm.user_id = ?"
+		params = append(params, userID)
+	}
+
+	basic := basicRoleAssignsSQL
+	if userID >= 0 {
+		basic += " WHERE ou.user_id = ?"
+		params = append(params, userID)
+	}
+
+	grafanaAdmin := fmt.Sprintf(grafanaAdminAssignsSQL, s.sql.Quote("user"))
+	params = append(params, accesscontrol.RoleGrafanaAdmin)
+	if userID >= 0 {
+		grafanaAdmin += " AND sa.user_id = ?"
+		params = append(params, userID)
+	}
+
+	// Find permissions
+	q := `
+	SELECT
+		user_id,
+		p.action,
+		p.scope,
+		p.deny
+	FROM (
+		` + direct + `
+		UNION ALL
+		` + team + `
+		UNION ALL
+		` + basic + `
+		UNION ALL
+		` + grafanaAdmin + `
+	) AS up ` + roleNameFilterJoin + `
+	` + identityJoin + `
+	INNER JOIN permission AS p ON up.role_id = p.role_id
+	WHERE (up.org_id = ? OR up.org_id = ?)
+	`
+	params = append(params, orgID, accesscontrol.GlobalOrgID)
+
+	if options.ActionPrefix != "" {
+		params = append(params, options.ActionPrefix+"%")
+		// Permissions stored with the previous name of a renamed action are matched by the current name
+		actions := append(slices.Clone(options.ActionSets), accesscontrol.ActionAliasesWithPrefix(options.ActionPrefix)...)
+		if len(actions) == 0 {
 			q += ` AND p.action LIKE ?`
-			params = append(params, options.ActionPrefix+"%")
-			if len(options.ActionSets) > 0 {
-				q += ` OR p.action IN ( ? ` + strings.Repeat(", ?", len(options.ActionSets)-1) + ")"
-				for _, a := range options.ActionSets {
-					params = append(params, a)
-				}
-			}
-		}
-		if options.Action != "" {
-			if len(options.ActionSets) == 0 {
-				q += ` AND p.action = ?`
-				params = append(params, options.Action)
-			} else {
-				actions := append(options.ActionSets, options.Action)
-				q += ` AND p.action IN ( ? ` + strings.Repeat(", ?", len(actions)-1) + ")"
-				for _, a := range actions {
-					params = append(params, a)
-				}
+		} else {
+			// The alternatives are grouped, the organization filter above applies to all of them
+			q += ` AND (p.action LIKE ? OR p.action IN ( ? ` + strings.Repeat(", ?", len(actions)-1) + "))"
+			for _, a := range actions {
+				params = append(params, a)
 			}
 		}
-		if options.Scope != "" {
-			// Search for scope and wildcard that include the scope
-			scopes := append(options.Wildcards(), options.Scope)
-			q += ` AND p.scope IN ( ? ` + strings.Repeat(", ?", len(scopes)-1) + ")"
-			for i := range scopes {
-				params = append(params, scopes[i])
+	}
+	if options.Action != "" {
+		actions := append(slices.Clone(options.ActionSets), accesscontrol.ActionAliasesOf(options.Action)...)
+		if len(actions) == 0 {
+			q += ` AND p.action = ?`
+			params = append(params, options.Action)
+		} else {
+			actions = append(actions, options.Action)
+			q += ` AND p.action IN ( ? ` + strings.Repeat(", ?", len(actions)-1) + ")"
+			for _, a := range actions {
+				params = append(params, a)
 			}
 		}
-		if len(options.RolePrefixes) > 0 {
-			q += " AND ( " + strings.Repeat("r.name LIKE ? OR ", len(options.RolePrefixes)-1)
-			q += "r.name LIKE ? )"
-			for _, prefix := range options.RolePrefixes {
-				params = append(params, prefix+"%")
-			}
+	}
+	if options.Scope != "" {
+		// Search for scope and wildcard that include the scope
+		scopes := append(options.Wildcards(), options.Scope)
+		q += ` AND p.scope IN ( ? ` + strings.Repeat(", ?", len(scopes)-1) + ")"
+		for i := range scopes {
+			params = append(params, scopes[i])
 		}
-
-		return sess.SQL(q, params...).Find(&dbPerms)
-	}); err != nil {
-		return nil, err
 	}
-
-	mapped := map[int64][]accesscontrol.Permission{}
-	for i := range dbPerms {
-		mapped[dbPerms[i].UserID] = append(mapped[dbPerms[i].UserID], accesscontrol.Permission{Action: dbPerms[i].Action, Scope: dbPerms[i].Scope})
+	if len(options.RolePrefixes) > 0 {
+		q += " AND ( " + strings.Repeat("r.name LIKE ? OR ", len(options.RolePrefixes)-1)
+		q += "r.name LIKE ? )"
+		for _, prefix := range options.RolePrefixes {
+			params = append(params, prefix+"%")
+		}
 	}
 
-	return mapped, nil
+	return q, params, true, nil
 }
 
 // GetUsersBasicRoles returns the list of user basic roles (Admin, Editor, Viewer, Grafana Admin) indexed by UserID
@@ -355,6 +546,15 @@ func (s *AccessControlStore) DeleteUserPermissions(ctx context.Context, orgID, u
 			if _, err := sess.Exec("DELETE FROM permission WHERE scope = ?", accesscontrol.Scope("users", "id", strconv.FormatInt(userID, 10))); err != nil {
 				return err
 			}
+
+			// External group memberships are not scoped to an org
+			if _, err := sess.Exec("DELETE FROM user_external_group WHERE user_id = ?", userID); err != nil {
+				return err
+			}
+		}
+
+		if err := RecordPermissionRemovals(sess, PermissionAuditFilter{OrgID: orgID, UserID: userID}); err != nil {
+			return err
 		}
 
 		roleQuery := "SELECT id FROM role WHERE name = ?"
@@ -364,6 +564,15 @@ func (s *AccessControlStore) DeleteUserPermissions(ctx context.Context, orgID, u
 			roleParams = []any{accesscontrol.ManagedUserRoleName(userID), orgID}
 		}
 
+		// Delete the metadata of the managed user permissions
+		metadataDeleteParams := []any{"DELETE FROM permission_metadata WHERE role_name = ?", accesscontrol.ManagedUserRoleName(userID)}
+		if orgID != accesscontrol.GlobalOrgID {
+			metadataDeleteParams = []any{"DELETE FROM permission_metadata WHERE role_name = ? AND org_id = ?", accesscontrol.ManagedUserRoleName(userID), orgID}
+		}
+		if _, err := sess.Exec(metadataDeleteParams...); err != nil {
+			return err
+		}
+
 		var roleIDs []int64
 		if err := sess.SQL(roleQuery, roleParams...).Find(&roleIDs); err != nil {
 			return err
@@ -397,6 +606,9 @@ func (s *AccessControlStore) DeleteUserPermissions(ctx context.Context, orgID, u
 
 		return nil
 	})
+	if err == nil {
+		s.webhook.Notify(ctx, webhook.Event{Type: webhook.EventRoleAssignmentDeleted, OrgID: orgID, UserID: userID})
+	}
 	return err
 }
 
@@ -414,7 +626,23 @@ func (s *AccessControlStore) DeleteTeamPermissions(ctx context.Context, orgID, t
 		}
 
 		// Delete permissions that are scoped to the team
-		if _, err := sess.Exec("DELETE FROM permission WHERE scope = ?", accesscontrol.Scope("teams", "id", strconv.FormatInt(teamID, 10))); err != nil {
+		teamScope := accesscontrol.Scope("teams", "id", strconv.FormatInt(teamID, 10))
+		if _, err := sess.Exec("DELETE FROM permission WHERE scope = ?", teamScope); err != nil {
+			return err
+		}
+
+		if err := RecordPermissionRemovals(sess, PermissionAuditFilter{OrgID: orgID, TeamID: teamID}); err != nil {
+			return err
+		}
+		if err := RecordPermissionRemovals(sess, PermissionAuditFilter{OrgID: orgID, Resource: "teams", ResourceID: strconv.FormatInt(teamID, 10)}); err != nil {
+			return err
+		}
+
+		// Delete the metadata of the managed team permissions and of the permissions on the team
+		if _, err := sess.Exec(
+			"DELETE FROM permission_metadata WHERE org_id = ? AND (role_name = ? OR scope = ?)",
+			orgID, accesscontrol.ManagedTeamRoleName(teamID), teamScope,
+		); err != nil {
 			return err
 		}
 
@@ -455,5 +683,92 @@ func (s *AccessControlStore) DeleteTeamPermissions(ctx context.Context, orgID, t
 
 		return nil
 	})
+	if err == nil {
+		s.webhook.Notify(ctx, webhook.Event{Type: webhook.EventRoleAssignmentDeleted, OrgID: orgID, TeamID: teamID})
+	}
 	return err
 }
+
+// deleteDuplicateBuiltinRolesBatchSize bounds the number of rows removed by each statement of DeleteDuplicateBuiltinRoles.
+const deleteDuplicateBuiltinRolesBatchSize = 500
+
+// DeleteDuplicateBuiltinRoles removes builtin_role rows assigning the same role to the same basic role of an org more than once.
+// Older versions didn't normalize the basic role, so rows are compared case-insensitively. The row with the canonical
+// casing of the basic role is kept, or the oldest row which is then normalized. Only the duplicated rows are loaded and
+// they are removed in batches, each in its own transaction. It returns the number of removed rows.
+func (s *AccessControlStore) DeleteDuplicateBuiltinRoles(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.DeleteDuplicateBuiltinRoles")
+	defer span.End()
+
+	var assignments []accesscontrol.BuiltinRole
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(`SELECT br.id, br.role_id, br.org_id, br.role FROM builtin_role AS br
+			INNER JOIN (
+				SELECT org_id, role_id, LOWER(role) AS lower_role FROM builtin_role
+				GROUP BY org_id, role_id, LOWER(role) HAVING COUNT(*) > 1
+			) AS d ON d.org_id = br.org_id AND d.role_id = br.role_id AND d.lower_role = LOWER(br.role)
+			ORDER BY br.id`).Find(&assignments)
+	})
+	if err != nil || len(assignments) == 0 {
+		return 0, err
+	}
+
+	type key struct {
+		orgID, roleID int64
+		role          string
+	}
+	kept := make(map[key]accesscontrol.BuiltinRole, len(assignments))
+	duplicates := make([]int64, 0, len(assignments))
+	for _, a := range assignments {
+		k := key{a.OrgID, a.RoleID, strings.ToLower(a.Role)}
+		current, ok := kept[k]
+		switch {
+		case !ok:
+			kept[k] = a
+		case current.Role != canonicalBasicRole(current.Role) && a.Role == canonicalBasicRole(a.Role):
+			duplicates = append(duplicates, current.ID)
+			kept[k] = a
+		default:
+			duplicates = append(duplicates, a.ID)
+		}
+	}
+
+	var removed int64
+	for start := 0; start < len(duplicates); start += deleteDuplicateBuiltinRolesBatchSize {
+		batch := duplicates[start:min(start+deleteDuplicateBuiltinRolesBatchSize, len(duplicates))]
+		err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			res, err := sess.In("id", batch).Delete(&accesscontrol.BuiltinRole{})
+			removed += res
+			return err
+		})
+		if err != nil {
+			return removed, err
+		}
+	}
+
+	for _, a := range kept {
+		if canonical := canonicalBasicRole(a.Role); canonical != a.Role {
+			err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.Exec("UPDATE builtin_role SET role = ? WHERE id = ?", canonical, a.ID)
+				return err
+			})
+			if err != nil {
+				return removed, err
+			}
+		}
+	}
+
+	return removed, nil
+}
+
+// canonicalBasicRole returns the basic role with its canonical casing, unknown roles are returned as is.
+func canonicalBasicRole(role string) string {
+	for _, basicRole := range []string{
+		string(org.RoleNone), string(org.RoleViewer), string(org.RoleEditor), string(org.RoleAdmin), accesscontrol.RoleGrafanaAdmin,
+	} {
+		if strings.EqualFold(role, basicRole) {
+			return basicRole
+		}
+	}
+	return role
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
 	rs "github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	acsuite "github.com/grafana/grafana/pkg/services/accesscontrol/testsuite"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
@@ -35,6 +37,16 @@ func TestMain(m *testing.M) {
 	testsuite.Run(m)
 }
 
+func TestIntegrationAccessControlStore_Conformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	acsuite.RunStoreSuite(t, func(t *testing.T) accesscontrol.Store {
+		return database.ProvideService(db.InitTestDB(t))
+	})
+}
+
 type getUserPermissionsTestCase struct {
 	desc               string
 	anonymousUser      bool
@@ -393,6 +405,396 @@ func TestAccessControlStore_DeleteTeamPermissions(t *testing.T) {
 	})
 }
 
+func TestAccessControlStore_GetTeamsResourceCounts(t *testing.T) {
+	ctx := context.Background()
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	_, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+	other, err := teamSvc.CreateTeam(ctx, "other", "", 1)
+	require.NoError(t, err)
+
+	set := func(teamID int64, resource, resourceID string, deny bool) {
+		_, err := permissionsStore.SetTeamResourcePermission(ctx, 1, teamID, rs.SetResourcePermissionCommand{
+			Actions:           []string{resource + ":read", resource + ":write"},
+			Resource:          resource,
+			ResourceAttribute: "uid",
+			ResourceID:        resourceID,
+			Deny:              deny,
+		}, nil)
+		require.NoError(t, err)
+	}
+	set(team.ID, "dashboards", "a", false)
+	set(team.ID, "dashboards", "b", false)
+	set(team.ID, "dashboards", "*", false)
+	set(team.ID, "dashboards", "c", true)
+	set(team.ID, "datasources", "a", false)
+	set(other.ID, "folders", "a", false)
+
+	counts, err := store.GetTeamsResourceCounts(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]map[string]int64{
+		team.ID:  {"dashboards": 2, "datasources": 1},
+		other.ID: {"folders": 1},
+	}, counts)
+
+	counts, err = store.GetTeamsResourceCounts(ctx, 1, []int64{other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]map[string]int64{other.ID: {"folders": 1}}, counts)
+
+	counts, err = store.GetTeamsResourceCounts(ctx, 2, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestAccessControlStore_CountRoles(t *testing.T) {
+	ctx := context.Background()
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	roles, assignments, err := store.CountRoles(ctx, 1)
+	require.NoError(t, err)
+
+	cmd := rs.SetResourcePermissionCommand{Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: "1"}
+	_, err = permissionsStore.SetUserResourcePermission(ctx, 1, accesscontrol.User{ID: user.ID}, cmd, nil)
+	require.NoError(t, err)
+	_, err = permissionsStore.SetTeamResourcePermission(ctx, 1, team.ID, cmd, nil)
+	require.NoError(t, err)
+	cmd.ResourceID = "2"
+	_, err = permissionsStore.SetTeamResourcePermission(ctx, 1, team.ID, cmd, nil)
+	require.NoError(t, err)
+
+	newRoles, newAssignments, err := store.CountRoles(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, roles+2, newRoles)
+	assert.Equal(t, assignments+2, newAssignments)
+
+	roles, assignments, err = store.CountRoles(ctx, 2)
+	require.NoError(t, err)
+	assert.Zero(t, roles)
+	assert.Zero(t, assignments)
+}
+
+func TestAccessControlStore_GetStats(t *testing.T) {
+	ctx := context.Background()
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	cmd := rs.SetResourcePermissionCommand{Actions: []string{"dashboards:read", "dashboards:write"}, Resource: "dashboards", ResourceID: "1"}
+	_, err := permissionsStore.SetUserResourcePermission(ctx, 1, accesscontrol.User{ID: user.ID}, cmd, nil)
+	require.NoError(t, err)
+	cmd.Actions = []string{"folders:read"}
+	cmd.Resource = "folders"
+	_, err = permissionsStore.SetTeamResourcePermission(ctx, 1, team.ID, cmd, nil)
+	require.NoError(t, err)
+
+	stats, err := store.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.RolesByKind["managed"])
+	assert.Equal(t, int64(1), stats.Assignments["users"])
+	assert.Equal(t, int64(1), stats.Assignments["teams"])
+	assert.Equal(t, int64(2), stats.PermissionsByKind["dashboards"])
+	assert.Equal(t, int64(1), stats.PermissionsByKind["folders"])
+	assert.Equal(t, []accesscontrol.ManagedRoleStats{
+		{OrgID: 1, Assignee: "users", Permissions: 2},
+		{OrgID: 1, Assignee: "teams", Permissions: 1},
+	}, stats.LargestManagedRoles)
+	assert.GreaterOrEqual(t, stats.LargestRolePermissions, int64(2))
+}
+
+func TestAccessControlStore_RevokeUserResourcePermissions(t *testing.T) {
+	ctx := context.Background()
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	for _, cmd := range []rs.SetResourcePermissionCommand{
+		{Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: "1"},
+		{Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: "2"},
+		{Actions: []string{"folders:read"}, Resource: "folders", ResourceID: "1"},
+	} {
+		_, err := permissionsStore.SetUserResourcePermission(ctx, 1, accesscontrol.User{ID: user.ID}, cmd, nil)
+		require.NoError(t, err)
+	}
+	_, err := permissionsStore.SetTeamResourcePermission(ctx, 1, team.ID, rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:write"}, Resource: "dashboards", ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+
+	var enqueued []string
+	revoked, err := store.RevokeUserResourcePermissions(ctx, 1, user.ID, "dashboards", func(ctx context.Context, resourceIDs []string) error {
+		enqueued = resourceIDs
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2"}, revoked)
+	assert.ElementsMatch(t, revoked, enqueued)
+
+	permissions, err := store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID:   1,
+		UserID:  user.ID,
+		TeamIDs: []int64{team.ID},
+	})
+	require.NoError(t, err)
+	actions := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		actions = append(actions, p.Action)
+	}
+	// The access granted by the team is kept
+	assert.ElementsMatch(t, []string{"folders:read", "dashboards:write"}, actions)
+}
+
+func TestAccessControlStore_Snapshot(t *testing.T) {
+	t.Run("expect exported snapshot to be restored", func(t *testing.T) {
+		store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+		user, team := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+		_, err := permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+			Actions:    []string{"dashboards:write"},
+			Resource:   "dashboards",
+			ResourceID: "1",
+		}, nil)
+		require.NoError(t, err)
+		_, err = permissionsStore.SetTeamResourcePermission(context.Background(), 1, team.ID, rs.SetResourcePermissionCommand{
+			Actions:    []string{"dashboards:read"},
+			Resource:   "dashboards",
+			ResourceID: "1",
+		}, nil)
+		require.NoError(t, err)
+
+		snapshot, err := store.ExportSnapshot(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, snapshot.Roles, 2)
+
+		require.NoError(t, store.DeleteUserPermissions(context.Background(), 1, user.ID))
+		require.NoError(t, store.ImportSnapshot(context.Background(), 1, snapshot))
+
+		permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+			OrgID:   1,
+			UserID:  user.ID,
+			TeamIDs: []int64{team.ID},
+		})
+		require.NoError(t, err)
+		assert.Len(t, permissions, 2)
+
+		restored, err := store.ExportSnapshot(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, snapshot.Roles, restored.Roles)
+	})
+
+	t.Run("expect snapshot with unknown users to be rejected", func(t *testing.T) {
+		store, _, _, _, _, _ := setupTestEnv(t)
+
+		err := store.ImportSnapshot(context.Background(), 1, &accesscontrol.Snapshot{
+			Version: accesscontrol.SnapshotVersion,
+			Roles: []accesscontrol.SnapshotRole{
+				{UID: "role", Name: "managed:users:1000:permissions", Users: []int64{1000}},
+			},
+		})
+		assert.ErrorIs(t, err, accesscontrol.ErrInvalidSnapshot)
+	})
+
+	t.Run("expect deny permissions and group assignments to be restored", func(t *testing.T) {
+		store, _, _, _, _, _ := setupTestEnv(t)
+
+		snapshot := &accesscontrol.Snapshot{
+			Version: accesscontrol.SnapshotVersion,
+			Roles: []accesscontrol.SnapshotRole{
+				{
+					UID:  "group",
+					Name: accesscontrol.ManagedGroupRoleName("editors"),
+					Permissions: []accesscontrol.SnapshotPermission{
+						{Action: "dashboards:read", Scope: "dashboards:uid:1"},
+						{Action: "dashboards:write", Scope: "dashboards:uid:1", Deny: true},
+					},
+					Groups: []string{"editors"},
+				},
+			},
+		}
+		require.NoError(t, store.ImportSnapshot(context.Background(), 1, snapshot))
+
+		restored, err := store.ExportSnapshot(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, restored.Roles, 1)
+		assert.Equal(t, snapshot.Roles[0].Permissions, restored.Roles[0].Permissions)
+		assert.Equal(t, []string{"editors"}, restored.Roles[0].Groups)
+	})
+
+	t.Run("expect snapshot assigning Grafana Admin or named after a global role to be rejected", func(t *testing.T) {
+		store, _, _, _, _, _ := setupTestEnv(t)
+
+		err := store.ImportSnapshot(context.Background(), 1, &accesscontrol.Snapshot{
+			Version: accesscontrol.SnapshotVersion,
+			Roles: []accesscontrol.SnapshotRole{
+				{UID: "role", Name: "managed:builtins:grafanaadmin:permissions", BuiltInRoles: []string{accesscontrol.RoleGrafanaAdmin}},
+			},
+		})
+		assert.ErrorIs(t, err, accesscontrol.ErrInvalidSnapshot)
+
+		err = store.ImportSnapshot(context.Background(), 1, &accesscontrol.Snapshot{
+			Version: accesscontrol.SnapshotVersion,
+			Roles:   []accesscontrol.SnapshotRole{{UID: "role", Name: "fixed:users:writer"}},
+		})
+		assert.ErrorIs(t, err, accesscontrol.ErrInvalidSnapshot)
+	})
+
+	t.Run("expect snapshot with unsupported version to be rejected", func(t *testing.T) {
+		store, _, _, _, _, _ := setupTestEnv(t)
+
+		err := store.ImportSnapshot(context.Background(), 1, &accesscontrol.Snapshot{Version: 42})
+		assert.ErrorIs(t, err, accesscontrol.ErrInvalidSnapshot)
+	})
+}
+
+func TestAccessControlStore_CopyOrgPermissions(t *testing.T) {
+	store, permissionsStore, usrSvc, teamSvc, orgSvc, sql := setupTestEnv(t)
+	user, srcTeam := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	dstOrgID, err := orgSvc.GetOrCreate(context.Background(), "destination")
+	require.NoError(t, err)
+	require.NoError(t, orgSvc.AddOrgUser(context.Background(), &org.AddOrgUserCommand{Role: org.RoleViewer, OrgID: dstOrgID, UserID: user.ID}))
+	dstTeam, err := teamSvc.CreateTeam(context.Background(), srcTeam.Name, "", dstOrgID)
+	require.NoError(t, err)
+
+	_, err = permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions:    []string{"dashboards:write"},
+		Resource:   "dashboards",
+		ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+	_, err = permissionsStore.SetTeamResourcePermission(context.Background(), 1, srcTeam.ID, rs.SetResourcePermissionCommand{
+		Actions:    []string{"dashboards:read"},
+		Resource:   "dashboards",
+		ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		role := accesscontrol.Role{}
+		if _, err := sess.Where("org_id = ? AND name = ?", 1, accesscontrol.ManagedUserRoleName(user.ID)).Get(&role); err != nil {
+			return err
+		}
+		_, err := sess.Insert(&accesscontrol.Permission{RoleID: role.ID, Action: "dashboards:delete", Scope: "dashboards:uid:1", Deny: true, Created: time.Now(), Updated: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.CopyOrgPermissions(context.Background(), 1, dstOrgID))
+	// copying twice doesn't duplicate anything
+	require.NoError(t, store.CopyOrgPermissions(context.Background(), 1, dstOrgID))
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+		OrgID:   dstOrgID,
+		UserID:  user.ID,
+		TeamIDs: []int64{dstTeam.ID},
+	})
+	require.NoError(t, err)
+	assert.Len(t, permissions, 3)
+
+	src, err := store.ExportSnapshot(context.Background(), 1)
+	require.NoError(t, err)
+	dst, err := store.ExportSnapshot(context.Background(), dstOrgID)
+	require.NoError(t, err)
+	require.Len(t, dst.Roles, len(src.Roles))
+	for i := range dst.Roles {
+		assert.NotEqual(t, src.Roles[i].UID, dst.Roles[i].UID)
+	}
+	assert.Contains(t, []string{dst.Roles[0].Name, dst.Roles[1].Name}, accesscontrol.ManagedTeamRoleName(dstTeam.ID))
+
+	// deny permissions are copied as denies
+	for _, r := range dst.Roles {
+		if r.Name == accesscontrol.ManagedUserRoleName(user.ID) {
+			assert.Contains(t, r.Permissions, accesscontrol.SnapshotPermission{Action: "dashboards:delete", Scope: "dashboards:uid:1", Deny: true})
+		}
+	}
+}
+
+func TestAccessControlStore_DeleteDuplicateBuiltinRoles(t *testing.T) {
+	store, _, _, _, _, sql := setupTestEnv(t)
+
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(
+			&accesscontrol.BuiltinRole{OrgID: 1, RoleID: 1, Role: "viewer", Created: time.Now(), Updated: time.Now()},
+			&accesscontrol.BuiltinRole{OrgID: 1, RoleID: 1, Role: "Viewer", Created: time.Now(), Updated: time.Now()},
+			&accesscontrol.BuiltinRole{OrgID: 1, RoleID: 2, Role: "editor", Created: time.Now(), Updated: time.Now()},
+			&accesscontrol.BuiltinRole{OrgID: 1, RoleID: 2, Role: "EDITOR", Created: time.Now(), Updated: time.Now()},
+			&accesscontrol.BuiltinRole{OrgID: 2, RoleID: 1, Role: "Viewer", Created: time.Now(), Updated: time.Now()},
+		)
+		return err
+	})
+	require.NoError(t, err)
+
+	removed, err := store.DeleteDuplicateBuiltinRoles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	removed, err = store.DeleteDuplicateBuiltinRoles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), removed)
+
+	// The rows with the canonical casing are kept, the others are normalized
+	var roles []string
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.SQL("SELECT role FROM builtin_role WHERE org_id = 1 ORDER BY role_id").Find(&roles)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Viewer", "Editor"}, roles)
+}
+
+func TestAccessControlStore_ExternalGroupPermissions(t *testing.T) {
+	store, permissionStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	_, err := permissionStore.SetGroupResourcePermission(context.Background(), 1, "cn=oncall,ou=groups", rs.SetResourcePermissionCommand{
+		Actions:           []string{"dashboards:write"},
+		Resource:          "dashboards",
+		ResourceID:        "1",
+		ResourceAttribute: "uid",
+	}, nil)
+	require.NoError(t, err)
+
+	getPermissions := func() []accesscontrol.Permission {
+		permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+			OrgID:        1,
+			UserID:       user.ID,
+			RolePrefixes: []string{accesscontrol.ManagedRolePrefix},
+		})
+		require.NoError(t, err)
+		return permissions
+	}
+
+	assert.Empty(t, getPermissions())
+
+	changed, err := store.SyncUserExternalGroups(context.Background(), user.ID, []string{"cn=oncall,ou=groups", "cn=other,ou=groups"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	permissions := getPermissions()
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "dashboards:uid:1", permissions[0].Scope)
+
+	changed, err = store.SyncUserExternalGroups(context.Background(), user.ID, []string{"cn=other,ou=groups"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, getPermissions())
+
+	changed, err = store.SyncUserExternalGroups(context.Background(), user.ID, []string{"cn=other,ou=groups"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestAccessControlStore_SyncUserExternalGroupsWithoutGrants(t *testing.T) {
+	store, _, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	changed, err := store.SyncUserExternalGroups(context.Background(), user.ID, []string{"cn=oncall,ou=groups"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		count, err := sess.Count(&accesscontrol.UserExternalGroup{})
+		assert.Zero(t, count)
+		return err
+	})
+	require.NoError(t, err)
+}
+
 func createUserAndTeam(t *testing.T, store db.DB, userSrv user.Service, teamSvc team.Service, orgID int64) (*user.User, team.Team) {
 	t.Helper()
 
@@ -765,6 +1167,18 @@ func TestIntegrationAccessControlStore_SearchUsersPermissions(t *testing.T) {
 				require.True(t, ok, "expected permissions for user", userID)
 				require.ElementsMatch(t, expectedUserPerms, dbUserPerms)
 			}
+
+			streamed := map[int64][]accesscontrol.Permission{}
+			err = acStore.StreamUsersPermissions(ctx, 1, tt.options, func(userID int64, permissions []accesscontrol.Permission) error {
+				require.NotContains(t, streamed, userID, "expected the permissions of a user to be streamed once")
+				streamed[userID] = permissions
+				return nil
+			})
+			require.NoError(t, err)
+			require.Len(t, streamed, len(dbPermissions))
+			for userID, dbUserPerms := range dbPermissions {
+				require.ElementsMatch(t, dbUserPerms, streamed[userID])
+			}
 		})
 	}
 }
@@ -836,3 +1250,153 @@ func TestAccessControlStore_GetUsersBasicRoles(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessControlStore_SeedBasicRoleGrants(t *testing.T) {
+	ctx := context.Background()
+	store, _, _, _, _, _ := setupTestEnv(t)
+
+	viewer := accesscontrol.NewBasicRoleGrants([]accesscontrol.RoleDTO{{Name: "fixed:dashboards:reader"}, {Name: "fixed:folders:reader"}})
+	editor := accesscontrol.NewBasicRoleGrants([]accesscontrol.RoleDTO{{Name: "fixed:dashboards:writer"}})
+
+	report, err := store.SeedBasicRoleGrants(ctx, map[string]accesscontrol.BasicRoleGrants{"Viewer": viewer, "Editor": editor})
+	require.NoError(t, err)
+	assert.Empty(t, report.Unchanged)
+	assert.Equal(t, map[string][]string{
+		"Viewer": {"fixed:dashboards:reader", "fixed:folders:reader"},
+		"Editor": {"fixed:dashboards:writer"},
+	}, report.Added)
+
+	// Only the basic roles whose grants changed are reconciled
+	viewer = accesscontrol.NewBasicRoleGrants([]accesscontrol.RoleDTO{{Name: "fixed:dashboards:reader"}, {Name: "fixed:teams:reader"}})
+	report, err = store.SeedBasicRoleGrants(ctx, map[string]accesscontrol.BasicRoleGrants{"Viewer": viewer, "Editor": editor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Editor"}, report.Unchanged)
+	assert.Equal(t, map[string][]string{"Viewer": {"fixed:teams:reader"}}, report.Added)
+	assert.Equal(t, map[string][]string{"Viewer": {"fixed:folders:reader"}}, report.Removed)
+
+	report, err = store.SeedBasicRoleGrants(ctx, map[string]accesscontrol.BasicRoleGrants{"Viewer": viewer, "Editor": editor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Editor", "Viewer"}, report.Unchanged)
+	assert.Empty(t, report.Added)
+	assert.Empty(t, report.Removed)
+}
+
+func TestAccessControlStore_GetBasicRoleOverrides(t *testing.T) {
+	ctx := context.Background()
+	store, _, _, _, _, sql := setupTestEnv(t)
+
+	err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+		role := accesscontrol.Role{OrgID: 1, UID: "basic_viewer", Name: "basic:viewer", Created: time.Now(), Updated: time.Now()}
+		if _, err := sess.Table("role").Insert(&role); err != nil {
+			return err
+		}
+		_, err := sess.Table("permission").Insert(&accesscontrol.Permission{
+			RoleID: role.ID, Action: "teams:read", Scope: "teams:*", Created: time.Now(), Updated: time.Now(),
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	overrides, err := store.GetBasicRoleOverrides(ctx, map[string][]accesscontrol.Permission{
+		"basic:viewer": {{Action: "dashboards:read", Scope: "dashboards:*"}},
+		"basic:editor": {{Action: "dashboards:write", Scope: "dashboards:*"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []accesscontrol.BasicRoleOverride{{
+		OrgID:     1,
+		BasicRole: "basic:viewer",
+		Added:     []accesscontrol.Permission{{Action: "teams:read", Scope: "teams:*"}},
+		Removed:   []accesscontrol.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}},
+	}}, overrides)
+}
+
+func TestAccessControlStore_PreviewDeleteUserPermissions(t *testing.T) {
+	ctx := context.Background()
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	_, err := permissionsStore.SetUserResourcePermission(ctx, 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+
+	preview, err := store.PreviewDeleteUserPermissions(ctx, 1, user.ID)
+	require.NoError(t, err)
+	require.Len(t, preview.ManagedRoles, 1)
+	assert.Equal(t, accesscontrol.ManagedUserRoleName(user.ID), preview.ManagedRoles[0].Name)
+	require.Len(t, preview.ManagedRoles[0].Permissions, 1)
+	assert.Equal(t, "dashboards:read", preview.ManagedRoles[0].Permissions[0].Action)
+	require.Len(t, preview.Assignments, 1)
+	assert.Equal(t, preview.ManagedRoles[0].UID, preview.Assignments[0].RoleUID)
+	// Scoped permissions are only removed with the user
+	assert.Empty(t, preview.ScopedPermissions)
+
+	// The preview doesn't remove anything
+	permissions, err := store.GetUserPermissions(ctx, accesscontrol.GetUserPermissionsQuery{
+		OrgID: 1, UserID: user.ID, RolePrefixes: []string{accesscontrol.ManagedRolePrefix},
+	})
+	require.NoError(t, err)
+	assert.Len(t, permissions, 1)
+
+	// Nothing is removed in other orgs
+	preview, err = store.PreviewDeleteUserPermissions(ctx, 2, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, preview.ManagedRoles)
+	assert.Empty(t, preview.Assignments)
+}
+
+func TestAccessControlStore_RewriteActionAliases(t *testing.T) {
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	_, err := permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions:    []string{"roles:list", "roles:read", "users.quotas:list"},
+		Resource:   "users",
+		ResourceID: "1",
+	}, nil)
+	require.NoError(t, err)
+
+	rewritten, err := store.RewriteActionAliases(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rewritten)
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+		OrgID:  1,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	actions := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		actions = append(actions, p.Action)
+	}
+	assert.ElementsMatch(t, []string{"roles:read", accesscontrol.ActionUsersQuotasList}, actions)
+
+	rewritten, err = store.RewriteActionAliases(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Zero(t, rewritten)
+
+	// A deny permission stored with the previous name is rewritten, the grant of the renamed action on the same
+	// scope doesn't make it redundant
+	var granted accesscontrol.Permission
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		if _, err := sess.Table("permission").Where("action = ?", "roles:read").Get(&granted); err != nil {
+			return err
+		}
+		_, err := sess.Exec("INSERT INTO permission (role_id, action, scope, deny, created, updated) VALUES (?, ?, ?, ?, ?, ?)",
+			granted.RoleID, "roles:list", granted.Scope, true, time.Now(), time.Now())
+		return err
+	})
+	require.NoError(t, err)
+
+	rewritten, err = store.RewriteActionAliases(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rewritten)
+
+	var denied []accesscontrol.Permission
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.Table("permission").Where("role_id = ? AND deny = ?", granted.RoleID, true).Find(&denied)
+	})
+	require.NoError(t, err)
+	require.Len(t, denied, 1)
+	assert.Equal(t, "roles:read", denied[0].Action)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var _ accesscontrol.UserDeletionPreviewer = &AccessControlStore{}
+
+// PreviewDeleteUserPermissions reads what DeleteUserPermissions removes with the same conditions, without
+// removing it.
+func (s *AccessControlStore) PreviewDeleteUserPermissions(ctx context.Context, orgID, userID int64) (*accesscontrol.UserDeletionPreview, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.PreviewDeleteUserPermissions")
+	defer span.End()
+
+	preview := &accesscontrol.UserDeletionPreview{
+		OrgID:             orgID,
+		UserID:            userID,
+		ManagedRoles:      []accesscontrol.DeletedManagedRole{},
+		Assignments:       []accesscontrol.DeletedRoleAssignment{},
+		ScopedPermissions: []accesscontrol.Permission{},
+		ExternalGroups:    []string{},
+	}
+
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		assignmentQuery := `
+		SELECT ur.org_id, r.uid AS role_uid, r.name AS role_name
+		FROM user_role AS ur
+		INNER JOIN role AS r ON r.id = ur.role_id
+		WHERE ur.user_id = ?`
+		assignmentParams := []any{userID}
+		if orgID != accesscontrol.GlobalOrgID {
+			assignmentQuery += " AND ur.org_id = ?"
+			assignmentParams = append(assignmentParams, orgID)
+		}
+		if err := sess.SQL(assignmentQuery+" ORDER BY ur.org_id, r.name", assignmentParams...).Find(&preview.Assignments); err != nil {
+			return err
+		}
+
+		// Permissions scoped to the user and external groups are only removed with the user
+		if orgID == accesscontrol.GlobalOrgID {
+			scope := accesscontrol.Scope("users", "id", strconv.FormatInt(userID, 10))
+			if err := sess.SQL("SELECT action, scope, deny FROM permission WHERE scope = ? ORDER BY action", scope).Find(&preview.ScopedPermissions); err != nil {
+				return err
+			}
+			if err := sess.SQL("SELECT group_id FROM user_external_group WHERE user_id = ? ORDER BY group_id", userID).Find(&preview.ExternalGroups); err != nil {
+				return err
+			}
+		}
+
+		roleQuery := "SELECT id, org_id, uid, name FROM role WHERE name = ?"
+		roleParams := []any{accesscontrol.ManagedUserRoleName(userID)}
+		if orgID != accesscontrol.GlobalOrgID {
+			roleQuery += " AND org_id = ?"
+			roleParams = append(roleParams, orgID)
+		}
+		var roles []struct {
+			ID    int64  `xorm:"id"`
+			OrgID int64  `xorm:"org_id"`
+			UID   string `xorm:"uid"`
+			Name  string `xorm:"name"`
+		}
+		if err := sess.SQL(roleQuery+" ORDER BY org_id", roleParams...).Find(&roles); err != nil {
+			return err
+		}
+
+		for _, r := range roles {
+			role := accesscontrol.DeletedManagedRole{OrgID: r.OrgID, UID: r.UID, Name: r.Name, Permissions: []accesscontrol.Permission{}}
+			if err := sess.SQL("SELECT action, scope, deny FROM permission WHERE role_id = ? ORDER BY action, scope", r.ID).Find(&role.Permissions); err != nil {
+				return err
+			}
+			preview.ManagedRoles = append(preview.ManagedRoles, role)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
 )
 
 func extServiceRoleName(externalServiceID string) string {
@@ -20,8 +21,10 @@ func (s *AccessControlStore) DeleteExternalServiceRole(ctx context.Context, exte
 	ctx, span := tracer.Start(ctx, "accesscontrol.database.DeleteExternalServiceRole")
 	defer span.End()
 
-	uid := accesscontrol.PrefixedRoleUID(extServiceRoleName(externalServiceID))
-	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+	name := extServiceRoleName(externalServiceID)
+	uid := accesscontrol.PrefixedRoleUID(name)
+	deleted := false
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
 		stored, errGet := getRoleByUID(ctx, sess, uid)
 		if errGet != nil {
 			// Role not found, nothing to do
@@ -50,8 +53,13 @@ func (s *AccessControlStore) DeleteExternalServiceRole(ctx context.Context, exte
 
 		// Delete the role
 		_, errDel = sess.Exec("DELETE FROM role WHERE id = ?", stored.ID)
+		deleted = errDel == nil
 		return errDel
 	})
+	if err == nil && deleted {
+		s.webhook.Notify(ctx, webhook.Event{Type: webhook.EventRoleAssignmentDeleted, OrgID: accesscontrol.GlobalOrgID, Role: name})
+	}
+	return err
 }
 
 func (s *AccessControlStore) SaveExternalServiceRole(ctx context.Context, cmd accesscontrol.SaveExternalServiceRoleCommand) error {
@@ -61,7 +69,7 @@ func (s *AccessControlStore) SaveExternalServiceRole(ctx context.Context, cmd ac
 	role := genExternalServiceRole(cmd)
 	assignment := genExternalServiceAssignment(cmd)
 
-	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
 		// Create or update the role
 		existingRole, errSaveRole := s.saveRole(ctx, sess, &role)
 		if errSaveRole != nil {
@@ -80,6 +88,22 @@ func (s *AccessControlStore) SaveExternalServiceRole(ctx context.Context, cmd ac
 		// Update permissions
 		return s.savePermissions(ctx, sess, existingRole.ID, cmd.Permissions)
 	})
+	if err != nil {
+		return err
+	}
+
+	actions := make([]string, 0, len(cmd.Permissions))
+	for _, p := range cmd.Permissions {
+		actions = append(actions, p.Action)
+	}
+	s.webhook.Notify(ctx, webhook.Event{
+		Type:    webhook.EventRoleAssignmentSet,
+		OrgID:   cmd.AssignmentOrgID,
+		UserID:  cmd.ServiceAccountID,
+		Role:    role.Name,
+		Actions: actions,
+	})
+	return nil
 }
 
 func genExternalServiceRole(cmd accesscontrol.SaveExternalServiceRoleCommand) accesscontrol.Role {
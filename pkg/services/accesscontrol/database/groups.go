@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var _ accesscontrol.ExternalGroupSyncer = &AccessControlStore{}
+
+func (s *AccessControlStore) SyncUserExternalGroups(ctx context.Context, userID int64, groups []string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.SyncUserExternalGroups")
+	defer span.End()
+
+	var changed bool
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		// Memberships are only used to resolve the permissions granted to groups
+		granted, err := sess.Table("group_role").Exist()
+		if err != nil || !granted {
+			return err
+		}
+
+		var current []accesscontrol.UserExternalGroup
+		if err := sess.Where("user_id = ?", userID).Find(&current); err != nil {
+			return err
+		}
+
+		missing := toSet(groups)
+		var remove []int64
+		for _, g := range current {
+			if _, ok := missing[g.GroupID]; ok {
+				delete(missing, g.GroupID)
+			} else {
+				remove = append(remove, g.ID)
+			}
+		}
+
+		if len(remove) > 0 {
+			if _, err := sess.In("id", remove).Delete(&accesscontrol.UserExternalGroup{}); err != nil {
+				return err
+			}
+			changed = true
+		}
+
+		if len(missing) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		added := make([]accesscontrol.UserExternalGroup, 0, len(missing))
+		for group := range missing {
+			if group == "" {
+				continue
+			}
+			added = append(added, accesscontrol.UserExternalGroup{UserID: userID, GroupID: group, Created: now})
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		if _, err := sess.Insert(&added); err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
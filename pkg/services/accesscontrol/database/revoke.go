@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+)
+
+var _ accesscontrol.UserResourcePermissionsRevoker = &AccessControlStore{}
+
+func (s *AccessControlStore) RevokeUserResourcePermissions(
+	ctx context.Context, orgID, userID int64, resource string, onRevoke func(ctx context.Context, resourceIDs []string) error,
+) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.RevokeUserResourcePermissions")
+	defer span.End()
+
+	var resourceIDs []string
+	err := s.sql.InTransaction(ctx, func(ctx context.Context) error {
+		resourceIDs = nil
+		if err := s.revokeUserResourcePermissions(ctx, orgID, userID, resource, &resourceIDs); err != nil {
+			return err
+		}
+		if onRevoke == nil || len(resourceIDs) == 0 {
+			return nil
+		}
+		return onRevoke(ctx, resourceIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]webhook.Event, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		events = append(events, webhook.Event{
+			Type:       webhook.EventResourcePermissionDeleted,
+			OrgID:      orgID,
+			Resource:   resource,
+			ResourceID: resourceID,
+			UserID:     userID,
+		})
+	}
+	s.webhook.Notify(ctx, events...)
+
+	return resourceIDs, nil
+}
+
+func (s *AccessControlStore) revokeUserResourcePermissions(ctx context.Context, orgID, userID int64, resource string, resourceIDs *[]string) error {
+	roleName := accesscontrol.ManagedUserRoleName(userID)
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		// Only the permissions of the user managed role are removed, access granted by other roles is kept
+		var revoked []string
+		if err := sess.SQL(
+			"SELECT DISTINCT identifier FROM permission WHERE kind = ? AND role_id IN (SELECT id FROM role WHERE org_id = ? AND name = ?)",
+			resource, orgID, roleName,
+		).Find(&revoked); err != nil {
+			return err
+		}
+
+		// The permissions of the temporary grants are removed with the managed ones, the grants would otherwise
+		// be revoked again
+		if _, err := sess.Exec("DELETE FROM temporary_permission WHERE org_id = ? AND resource = ? AND user_id = ?", orgID, resource, userID); err != nil {
+			return err
+		}
+		if len(revoked) == 0 {
+			return nil
+		}
+
+		if _, err := sess.Exec(
+			"DELETE FROM permission WHERE kind = ? AND role_id IN (SELECT id FROM role WHERE org_id = ? AND name = ?)",
+			resource, orgID, roleName,
+		); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec(
+			"DELETE FROM permission_metadata WHERE org_id = ? AND role_name = ? AND scope LIKE ?",
+			orgID, roleName, resource+":%",
+		); err != nil {
+			return err
+		}
+
+		for _, resourceID := range revoked {
+			if resourceID != "" {
+				*resourceIDs = append(*resourceIDs, resourceID)
+			}
+		}
+
+		return RecordPermissionRemovals(sess, PermissionAuditFilter{OrgID: orgID, Resource: resource, UserID: userID})
+	})
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+)
+
+var _ accesscontrol.BasicRoleSeeder = &AccessControlStore{}
+
+// SeedBasicRoleGrants stores the roles granted to the basic roles in seed_grant. The grants of a basic role are
+// only compared to the stored grants when its version changed, then only the difference is written.
+func (s *AccessControlStore) SeedBasicRoleGrants(ctx context.Context, grants map[string]accesscontrol.BasicRoleGrants) (*accesscontrol.SeedReport, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.SeedBasicRoleGrants")
+	defer span.End()
+
+	report := &accesscontrol.SeedReport{
+		Unchanged: []string{},
+		Added:     map[string][]string{},
+		Removed:   map[string][]string{},
+	}
+
+	basicRoles := make([]string, 0, len(grants))
+	for br := range grants {
+		basicRoles = append(basicRoles, br)
+	}
+	sort.Strings(basicRoles)
+
+	var events []webhook.Event
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		events = nil
+		var stored []struct {
+			BuiltinRole string `xorm:"builtin_role"`
+			Version     string `xorm:"version"`
+		}
+		if err := sess.SQL("SELECT builtin_role, version FROM seed_version").Find(&stored); err != nil {
+			return err
+		}
+		versions := make(map[string]string, len(stored))
+		for _, v := range stored {
+			versions[v.BuiltinRole] = v.Version
+		}
+
+		now := time.Now()
+		for _, br := range basicRoles {
+			version, ok := versions[br]
+			if ok && version == grants[br].Version {
+				report.Unchanged = append(report.Unchanged, br)
+				continue
+			}
+
+			var seeded []string
+			if err := sess.SQL("SELECT role_name FROM seed_grant WHERE builtin_role = ?", br).Find(&seeded); err != nil {
+				return err
+			}
+
+			for _, role := range grants[br].Roles {
+				if slices.Contains(seeded, role) {
+					continue
+				}
+				if _, err := sess.Exec("INSERT INTO seed_grant (builtin_role, role_name, created) VALUES (?, ?, ?)", br, role, now); err != nil {
+					return err
+				}
+				report.Added[br] = append(report.Added[br], role)
+				events = append(events, roleAssignmentSetEvent(accesscontrol.GlobalOrgID, role, webhook.Event{BuiltInRole: br}))
+			}
+			for _, role := range seeded {
+				if slices.Contains(grants[br].Roles, role) {
+					continue
+				}
+				if _, err := sess.Exec("DELETE FROM seed_grant WHERE builtin_role = ? AND role_name = ?", br, role); err != nil {
+					return err
+				}
+				report.Removed[br] = append(report.Removed[br], role)
+				events = append(events, webhook.Event{Type: webhook.EventRoleAssignmentDeleted, OrgID: accesscontrol.GlobalOrgID, BuiltInRole: br, Role: role})
+			}
+
+			var err error
+			if ok {
+				_, err = sess.Exec("UPDATE seed_version SET version = ?, updated = ? WHERE builtin_role = ?", grants[br].Version, now, br)
+			} else {
+				_, err = sess.Exec("INSERT INTO seed_version (builtin_role, version, updated) VALUES (?, ?, ?)", br, grants[br].Version, now)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.webhook.Notify(ctx, events...)
+	return report, nil
+}
+
+// GetBasicRoleOverrides compares the permissions of the basic roles stored in each org, e.g. basic:viewer, to the
+// defaults of the basic roles with the same name. Stored basic roles without defaults are ignored.
+func (s *AccessControlStore) GetBasicRoleOverrides(ctx context.Context, defaults map[string][]accesscontrol.Permission) ([]accesscontrol.BasicRoleOverride, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetBasicRoleOverrides")
+	defer span.End()
+
+	var rows []struct {
+		OrgID  int64  `xorm:"org_id"`
+		Name   string `xorm:"name"`
+		Action string `xorm:"action"`
+		Scope  string `xorm:"scope"`
+	}
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		// Basic roles without permissions remove all their defaults
+		q := `
+		SELECT r.org_id, r.name, COALESCE(p.action, '') AS action, COALESCE(p.scope, '') AS scope
+		FROM role AS r
+		LEFT JOIN permission AS p ON p.role_id = r.id
+		WHERE r.name LIKE ?
+		ORDER BY r.org_id, r.name
+		`
+		return sess.SQL(q, accesscontrol.BasicRolePrefix+"%").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ action, scope string }
+	overrides := []accesscontrol.BasicRoleOverride{}
+	compare := func(orgID int64, name string, stored map[key]bool) {
+		override := accesscontrol.BasicRoleOverride{OrgID: orgID, BasicRole: name}
+		expected := make(map[key]bool, len(defaults[name]))
+		for _, p := range defaults[name] {
+			k := key{p.Action, p.Scope}
+			expected[k] = true
+			if !stored[k] {
+				override.Removed = append(override.Removed, accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+			}
+		}
+		for k := range stored {
+			if !expected[k] {
+				override.Added = append(override.Added, accesscontrol.Permission{Action: k.action, Scope: k.scope})
+			}
+		}
+		if len(override.Added) > 0 || len(override.Removed) > 0 {
+			sort.Slice(override.Added, func(i, j int) bool {
+				return override.Added[i].Action+override.Added[i].Scope < override.Added[j].Action+override.Added[j].Scope
+			})
+			overrides = append(overrides, override)
+		}
+	}
+
+	var (
+		orgID  int64
+		name   string
+		stored map[key]bool
+	)
+	for _, r := range rows {
+		if stored != nil && (r.OrgID != orgID || r.Name != name) {
+			compare(orgID, name, stored)
+			stored = nil
+		}
+		if _, ok := defaults[r.Name]; !ok {
+			continue
+		}
+		if stored == nil {
+			orgID, name, stored = r.OrgID, r.Name, map[key]bool{}
+		}
+		if r.Action != "" {
+			stored[key{r.Action, r.Scope}] = true
+		}
+	}
+	if stored != nil {
+		compare(orgID, name, stored)
+	}
+	return overrides, nil
+}
@@ -0,0 +1,310 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var _ accesscontrol.Snapshotter = &AccessControlStore{}
+
+func (s *AccessControlStore) ExportSnapshot(ctx context.Context, orgID int64) (*accesscontrol.Snapshot, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.ExportSnapshot")
+	defer span.End()
+
+	snapshot := &accesscontrol.Snapshot{
+		Version: accesscontrol.SnapshotVersion,
+		OrgID:   orgID,
+		Created: time.Now(),
+		Roles:   []accesscontrol.SnapshotRole{},
+	}
+
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var roles []accesscontrol.Role
+		if err := sess.Where("org_id = ?", orgID).Asc("id").Find(&roles); err != nil {
+			return err
+		}
+
+		var permissions []accesscontrol.Permission
+		if err := sess.SQL("SELECT p.* FROM permission AS p INNER JOIN role AS r ON r.id = p.role_id WHERE r.org_id = ? ORDER BY p.id", orgID).Find(&permissions); err != nil {
+			return err
+		}
+
+		var users []accesscontrol.UserRole
+		if err := sess.SQL("SELECT ur.* FROM user_role AS ur INNER JOIN role AS r ON r.id = ur.role_id WHERE r.org_id = ? AND ur.org_id = ?", orgID, orgID).Find(&users); err != nil {
+			return err
+		}
+
+		var teams []accesscontrol.TeamRole
+		if err := sess.SQL("SELECT tr.* FROM team_role AS tr INNER JOIN role AS r ON r.id = tr.role_id WHERE r.org_id = ? AND tr.org_id = ?", orgID, orgID).Find(&teams); err != nil {
+			return err
+		}
+
+		var builtIns []accesscontrol.BuiltinRole
+		if err := sess.SQL("SELECT br.* FROM builtin_role AS br INNER JOIN role AS r ON r.id = br.role_id WHERE r.org_id = ? AND br.org_id = ?", orgID, orgID).Find(&builtIns); err != nil {
+			return err
+		}
+
+		var groups []accesscontrol.GroupRole
+		if err := sess.SQL("SELECT gr.* FROM group_role AS gr INNER JOIN role AS r ON r.id = gr.role_id WHERE r.org_id = ? AND gr.org_id = ? ORDER BY gr.id", orgID, orgID).Find(&groups); err != nil {
+			return err
+		}
+
+		index := make(map[int64]int, len(roles))
+		for i, r := range roles {
+			index[r.ID] = i
+			snapshot.Roles = append(snapshot.Roles, accesscontrol.SnapshotRole{
+				UID:         r.UID,
+				Name:        r.Name,
+				DisplayName: r.DisplayName,
+				Description: r.Description,
+				Group:       r.Group,
+				Hidden:      r.Hidden,
+				Version:     r.Version,
+			})
+		}
+
+		for _, p := range permissions {
+			role := &snapshot.Roles[index[p.RoleID]]
+			role.Permissions = append(role.Permissions, accesscontrol.SnapshotPermission{Action: p.Action, Scope: p.Scope, Deny: p.Deny})
+		}
+		for _, a := range users {
+			role := &snapshot.Roles[index[a.RoleID]]
+			role.Users = append(role.Users, a.UserID)
+		}
+		for _, a := range teams {
+			role := &snapshot.Roles[index[a.RoleID]]
+			role.Teams = append(role.Teams, a.TeamID)
+		}
+		for _, a := range builtIns {
+			role := &snapshot.Roles[index[a.RoleID]]
+			role.BuiltInRoles = append(role.BuiltInRoles, a.Role)
+		}
+		for _, a := range groups {
+			role := &snapshot.Roles[index[a.RoleID]]
+			role.Groups = append(role.Groups, a.GroupID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (s *AccessControlStore) ImportSnapshot(ctx context.Context, orgID int64, snapshot *accesscontrol.Snapshot) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.ImportSnapshot")
+	defer span.End()
+
+	if err := validateSnapshot(snapshot); err != nil {
+		return err
+	}
+
+	var events []webhook.Event
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		events = nil
+		if err := validateSnapshotReferences(sess, orgID, snapshot); err != nil {
+			return err
+		}
+
+		// Remove the current state of the org, global roles and their assignments are left untouched
+		deletes := []string{
+			"DELETE FROM permission WHERE role_id IN (SELECT id FROM role WHERE org_id = ?)",
+			"DELETE FROM user_role WHERE role_id IN (SELECT id FROM role WHERE org_id = ?)",
+			"DELETE FROM team_role WHERE role_id IN (SELECT id FROM role WHERE org_id = ?)",
+			"DELETE FROM builtin_role WHERE role_id IN (SELECT id FROM role WHERE org_id = ?)",
+			"DELETE FROM group_role WHERE role_id IN (SELECT id FROM role WHERE org_id = ?)",
+			"DELETE FROM role WHERE org_id = ?",
+		}
+		for _, query := range deletes {
+			if _, err := sess.Exec(query, orgID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, r := range snapshot.Roles {
+			role := accesscontrol.Role{
+				OrgID:       orgID,
+				Version:     r.Version,
+				UID:         r.UID,
+				Name:        r.Name,
+				DisplayName: r.DisplayName,
+				Description: r.Description,
+				Group:       r.Group,
+				Hidden:      r.Hidden,
+				Created:     now,
+				Updated:     now,
+			}
+			if _, err := sess.Insert(&role); err != nil {
+				return err
+			}
+
+			if len(r.Permissions) > 0 {
+				permissions := make([]accesscontrol.Permission, 0, len(r.Permissions))
+				for _, p := range r.Permissions {
+					permission := accesscontrol.Permission{RoleID: role.ID, Action: p.Action, Scope: p.Scope, Deny: p.Deny, Created: now, Updated: now}
+					permission.Kind, permission.Attribute, permission.Identifier = permission.SplitScope()
+					permissions = append(permissions, permission)
+				}
+				if _, err := sess.Insert(&permissions); err != nil {
+					return err
+				}
+			}
+
+			for _, userID := range r.Users {
+				if _, err := sess.Insert(&accesscontrol.UserRole{OrgID: orgID, RoleID: role.ID, UserID: userID, Created: now}); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(orgID, role.Name, webhook.Event{UserID: userID}))
+			}
+			for _, teamID := range r.Teams {
+				if _, err := sess.Insert(&accesscontrol.TeamRole{OrgID: orgID, RoleID: role.ID, TeamID: teamID, Created: now}); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(orgID, role.Name, webhook.Event{TeamID: teamID}))
+			}
+			for _, builtIn := range r.BuiltInRoles {
+				if _, err := sess.Insert(&accesscontrol.BuiltinRole{OrgID: orgID, RoleID: role.ID, Role: builtIn, Created: now, Updated: now}); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(orgID, role.Name, webhook.Event{BuiltInRole: builtIn}))
+			}
+			for _, group := range r.Groups {
+				if _, err := sess.Insert(&accesscontrol.GroupRole{OrgID: orgID, RoleID: role.ID, GroupID: group, Created: now}); err != nil {
+					return err
+				}
+				events = append(events, roleAssignmentSetEvent(orgID, role.Name, webhook.Event{GroupID: group}))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.webhook.Notify(ctx, events...)
+	return nil
+}
+
+// roleAssignmentSetEvent returns the event of the assignment of the role to the assignee set in assignee.
+func roleAssignmentSetEvent(orgID int64, role string, assignee webhook.Event) webhook.Event {
+	assignee.Type = webhook.EventRoleAssignmentSet
+	assignee.OrgID = orgID
+	assignee.Role = role
+	return assignee
+}
+
+// validateSnapshot checks the snapshot is self-consistent.
+func validateSnapshot(snapshot *accesscontrol.Snapshot) error {
+	if snapshot == nil {
+		return accesscontrol.ErrInvalidSnapshot.Errorf("missing snapshot")
+	}
+	if snapshot.Version != accesscontrol.SnapshotVersion {
+		return accesscontrol.ErrInvalidSnapshot.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	uids := make(map[string]struct{}, len(snapshot.Roles))
+	names := make(map[string]struct{}, len(snapshot.Roles))
+	for _, r := range snapshot.Roles {
+		if r.UID == "" || r.Name == "" {
+			return accesscontrol.ErrInvalidSnapshot.Errorf("role is missing uid or name")
+		}
+		if _, ok := uids[r.UID]; ok {
+			return accesscontrol.ErrInvalidSnapshot.Errorf("duplicated role uid %s", r.UID)
+		}
+		if _, ok := names[r.Name]; ok {
+			return accesscontrol.ErrInvalidSnapshot.Errorf("duplicated role name %s", r.Name)
+		}
+		if isGlobalRoleName(r.Name) {
+			return accesscontrol.ErrInvalidSnapshot.Errorf("role %s is named after a global role", r.UID)
+		}
+		uids[r.UID] = struct{}{}
+		names[r.Name] = struct{}{}
+
+		for _, p := range r.Permissions {
+			if p.Action == "" {
+				return accesscontrol.ErrInvalidSnapshot.Errorf("role %s has a permission without action", r.UID)
+			}
+		}
+		// Grafana Admin is a server wide role, an org snapshot cannot grant permissions to it
+		for _, builtIn := range r.BuiltInRoles {
+			if !org.RoleType(builtIn).IsValid() {
+				return accesscontrol.ErrInvalidSnapshot.Errorf("role %s is assigned to invalid basic role %s", r.UID, builtIn)
+			}
+		}
+		for _, group := range r.Groups {
+			if group == "" {
+				return accesscontrol.ErrInvalidSnapshot.Errorf("role %s is assigned to an empty group", r.UID)
+			}
+		}
+	}
+	return nil
+}
+
+// isGlobalRoleName returns true when the name is reserved to the roles declared on start-up, which are not part of
+// the snapshot of an org.
+func isGlobalRoleName(name string) bool {
+	for _, prefix := range []string{
+		accesscontrol.FixedRolePrefix, accesscontrol.BasicRolePrefix,
+		accesscontrol.PluginRolePrefix, accesscontrol.ExternalServiceRolePrefix,
+	} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSnapshotReferences checks that the users and teams assigned in the snapshot exist in the org
+// and that role uids are not used by roles of other orgs.
+func validateSnapshotReferences(sess *db.Session, orgID int64, snapshot *accesscontrol.Snapshot) error {
+	var userIDs []int64
+	if err := sess.SQL("SELECT user_id FROM org_user WHERE org_id = ?", orgID).Find(&userIDs); err != nil {
+		return err
+	}
+	var teamIDs []int64
+	if err := sess.SQL("SELECT id FROM team WHERE org_id = ?", orgID).Find(&teamIDs); err != nil {
+		return err
+	}
+	var foreignUIDs []string
+	if err := sess.SQL("SELECT uid FROM role WHERE org_id <> ?", orgID).Find(&foreignUIDs); err != nil {
+		return err
+	}
+
+	users := toSet(userIDs)
+	teams := toSet(teamIDs)
+	foreign := toSet(foreignUIDs)
+	for _, r := range snapshot.Roles {
+		if _, ok := foreign[r.UID]; ok {
+			return accesscontrol.ErrInvalidSnapshot.Errorf("role uid %s is used by another org", r.UID)
+		}
+		for _, id := range r.Users {
+			if _, ok := users[id]; !ok {
+				return accesscontrol.ErrInvalidSnapshot.Errorf("role %s is assigned to user %d who is not a member of the org", r.UID, id)
+			}
+		}
+		for _, id := range r.Teams {
+			if _, ok := teams[id]; !ok {
+				return accesscontrol.ErrInvalidSnapshot.Errorf("role %s is assigned to team %d that does not exist in the org", r.UID, id)
+			}
+		}
+	}
+	return nil
+}
+
+func toSet[T comparable](values []T) map[T]struct{} {
+	set := make(map[T]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// largestManagedRolesLimit is the number of managed roles reported by GetStats
+const largestManagedRolesLimit = 10
+
+var _ accesscontrol.StatsReporter = &AccessControlStore{}
+
+// GetStats returns the statistics of the roles and permissions of all orgs. Managed roles are reported with the
+// kind of their assignee only, their name contains the id of the user or team.
+func (s *AccessControlStore) GetStats(ctx context.Context) (*accesscontrol.Stats, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetStats")
+	defer span.End()
+
+	stats := &accesscontrol.Stats{
+		RolesByKind:         map[string]int64{},
+		Assignments:         map[string]int64{},
+		PermissionsByKind:   map[string]int64{},
+		LargestManagedRoles: []accesscontrol.ManagedRoleStats{},
+	}
+
+	type count struct {
+		Kind  string `xorm:"kind"`
+		Count int64  `xorm:"count"`
+	}
+
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var roles []count
+		q := `
+		SELECT
+			CASE
+				WHEN name LIKE ? THEN 'managed'
+				WHEN name LIKE ? THEN 'fixed'
+				WHEN name LIKE ? THEN 'basic'
+				WHEN name LIKE ? THEN 'plugins'
+				WHEN name LIKE ? THEN 'extsvc'
+				ELSE 'custom'
+			END AS kind,
+			COUNT(*) AS count
+		FROM role
+		GROUP BY kind
+		`
+		if err := sess.SQL(q,
+			accesscontrol.ManagedRolePrefix+"%",
+			accesscontrol.FixedRolePrefix+"%",
+			accesscontrol.BasicRolePrefix+"%",
+			accesscontrol.PluginRolePrefix+"%",
+			accesscontrol.ExternalServiceRolePrefix+"%",
+		).Find(&roles); err != nil {
+			return err
+		}
+		for _, c := range roles {
+			stats.RolesByKind[c.Kind] = c.Count
+		}
+
+		var assignments struct {
+			Users      int64 `xorm:"users"`
+			Teams      int64 `xorm:"teams"`
+			BasicRoles int64 `xorm:"basic_roles"`
+		}
+		q = `
+		SELECT
+			(SELECT COUNT(*) FROM user_role) AS users,
+			(SELECT COUNT(*) FROM team_role) AS teams,
+			(SELECT COUNT(*) FROM builtin_role) AS basic_roles
+		`
+		if _, err := sess.SQL(q).Get(&assignments); err != nil {
+			return err
+		}
+		stats.Assignments["users"] = assignments.Users
+		stats.Assignments["teams"] = assignments.Teams
+		stats.Assignments["basicRoles"] = assignments.BasicRoles
+
+		var permissions []count
+		if err := sess.SQL("SELECT kind, COUNT(*) AS count FROM permission GROUP BY kind").Find(&permissions); err != nil {
+			return err
+		}
+		for _, c := range permissions {
+			kind := c.Kind
+			if kind == "" {
+				// Permissions without scope
+				kind = "none"
+			}
+			stats.PermissionsByKind[kind] += c.Count
+		}
+
+		var managed []struct {
+			Name        string `xorm:"name"`
+			OrgID       int64  `xorm:"org_id"`
+			Permissions int64  `xorm:"permissions"`
+		}
+		q = `
+		SELECT r.name, r.org_id, COUNT(*) AS permissions
+		FROM role AS r
+		INNER JOIN permission AS p ON p.role_id = r.id
+		WHERE r.name LIKE ?
+		GROUP BY r.id, r.name, r.org_id
+		ORDER BY permissions DESC, r.id
+		` + s.sql.GetDialect().Limit(largestManagedRolesLimit)
+		if err := sess.SQL(q, accesscontrol.ManagedRolePrefix+"%").Find(&managed); err != nil {
+			return err
+		}
+		for _, r := range managed {
+			// Managed roles are named managed:<assignee>:<id>:permissions
+			assignee := strings.Split(strings.TrimPrefix(r.Name, accesscontrol.ManagedRolePrefix), ":")[0]
+			stats.LargestManagedRoles = append(stats.LargestManagedRoles, accesscontrol.ManagedRoleStats{
+				OrgID:       r.OrgID,
+				Assignee:    assignee,
+				Permissions: r.Permissions,
+			})
+		}
+
+		var largest []int64
+		q = `SELECT COUNT(*) AS permissions FROM permission GROUP BY role_id ORDER BY permissions DESC` + s.sql.GetDialect().Limit(1)
+		if err := sess.SQL(q).Find(&largest); err != nil {
+			return err
+		}
+		if len(largest) > 0 {
+			stats.LargestRolePermissions = largest[0]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
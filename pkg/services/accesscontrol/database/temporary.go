@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+)
+
+var _ accesscontrol.TemporaryPermissionRevoker = &AccessControlStore{}
+
+func (s *AccessControlStore) RevokeExpiredTemporaryPermissions(
+	ctx context.Context, now time.Time, onRevoke func(ctx context.Context, revoked []accesscontrol.TemporaryPermission) error,
+) ([]accesscontrol.TemporaryPermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.RevokeExpiredTemporaryPermissions")
+	defer span.End()
+
+	var expired []accesscontrol.TemporaryPermission
+	err := s.sql.InTransaction(ctx, func(ctx context.Context) error {
+		expired = nil
+		if err := s.revokeExpiredTemporaryPermissions(ctx, now, &expired); err != nil {
+			return err
+		}
+		if onRevoke == nil || len(expired) == 0 {
+			return nil
+		}
+		return onRevoke(ctx, expired)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]webhook.Event, 0, len(expired))
+	for _, grant := range expired {
+		events = append(events, webhook.Event{
+			Type:       webhook.EventResourcePermissionDeleted,
+			OrgID:      grant.OrgID,
+			Resource:   grant.Resource,
+			ResourceID: grant.ResourceID,
+			UserID:     grant.UserID,
+			Permission: grant.Permission,
+		})
+	}
+	s.webhook.Notify(ctx, events...)
+
+	return expired, nil
+}
+
+func (s *AccessControlStore) revokeExpiredTemporaryPermissions(ctx context.Context, now time.Time, expired *[]accesscontrol.TemporaryPermission) error {
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		if err := sess.Where("expires <= ?", now).Asc("id").Find(expired); err != nil {
+			return err
+		}
+
+		for _, grant := range *expired {
+			// Only the permissions of the user managed role are removed, access granted by other roles is kept
+			_, err := sess.Exec(
+				"DELETE FROM permission WHERE scope = ? AND role_id IN (SELECT id FROM role WHERE org_id = ? AND name = ?)",
+				grant.Scope(), grant.OrgID, accesscontrol.ManagedUserRoleName(grant.UserID),
+			)
+			if err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec(
+				"DELETE FROM permission_metadata WHERE org_id = ? AND role_name = ? AND scope = ?",
+				grant.OrgID, accesscontrol.ManagedUserRoleName(grant.UserID), grant.Scope(),
+			); err != nil {
+				return err
+			}
+			if err := RecordPermissionRemovals(sess, PermissionAuditFilter{
+				OrgID: grant.OrgID, Resource: grant.Resource, ResourceID: grant.ResourceID, UserID: grant.UserID,
+			}); err != nil {
+				return err
+			}
+
+			if _, err := sess.Exec("DELETE FROM temporary_permission WHERE id = ?", grant.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
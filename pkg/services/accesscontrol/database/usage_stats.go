@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// UsageStatsCollector collects the usage statistics of the roles and permissions of the instance, they are reported
+// for capacity planning.
+type UsageStatsCollector struct {
+	store accesscontrol.StatsReporter
+	// actionSets lists the action sets of the instance, nil if action sets are not reported
+	actionSets accesscontrol.ActionSetLister
+	// tuples counts the tuples stored in zanzana, nil if zanzana is disabled
+	tuples accesscontrol.TupleCounter
+	log    log.Logger
+}
+
+func NewUsageStatsCollector(store accesscontrol.StatsReporter, actionSets accesscontrol.ActionSetLister, tuples accesscontrol.TupleCounter) *UsageStatsCollector {
+	return &UsageStatsCollector{
+		store:      store,
+		actionSets: actionSets,
+		tuples:     tuples,
+		log:        log.New("accesscontrol.stats"),
+	}
+}
+
+// Collect returns the usage statistics of the roles and permissions, it is registered as a metrics function of the
+// usage stats service.
+func (c *UsageStatsCollector) Collect(ctx context.Context) (map[string]any, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.UsageStatsCollector.Collect")
+	defer span.End()
+
+	stats, err := c.store.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]any{}
+	for kind, count := range stats.RolesByKind {
+		m["stats.accesscontrol.roles."+kind+".count"] = count
+	}
+	// Custom and managed roles are always reported, the kinds without roles are not returned by the store
+	for _, kind := range []string{"custom", "managed"} {
+		if _, ok := stats.RolesByKind[kind]; !ok {
+			m["stats.accesscontrol.roles."+kind+".count"] = int64(0)
+		}
+	}
+
+	var permissions int64
+	for _, count := range stats.PermissionsByKind {
+		permissions += count
+	}
+	m["stats.accesscontrol.permissions.count"] = permissions
+	m["stats.accesscontrol.roles.largest.permissions.count"] = stats.LargestRolePermissions
+
+	if c.actionSets != nil {
+		m["stats.accesscontrol.action_sets.count"] = len(c.actionSets.ListActionSets())
+	}
+
+	if c.tuples != nil {
+		// Zanzana being unavailable must not prevent reporting the statistics of the database
+		tuples, err := c.tuples.CountTuples(ctx)
+		switch {
+		case errors.Is(err, accesscontrol.ErrTupleCountUnavailable):
+		case err != nil:
+			c.log.Warn("Failed to count zanzana tuples", "error", err)
+		default:
+			m["stats.accesscontrol.zanzana.tuples.count"] = tuples
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,78 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+)
+
+type fakeStatsReporter struct {
+	stats *accesscontrol.Stats
+}
+
+func (f fakeStatsReporter) GetStats(context.Context) (*accesscontrol.Stats, error) {
+	return f.stats, nil
+}
+
+type fakeActionSetLister map[string][]string
+
+func (f fakeActionSetLister) ListActionSets() map[string][]string {
+	return f
+}
+
+type fakeTupleCounter struct {
+	count int64
+	err   error
+}
+
+func (f fakeTupleCounter) CountTuples(context.Context) (int64, error) {
+	return f.count, f.err
+}
+
+func TestUsageStatsCollector_Collect(t *testing.T) {
+	store := fakeStatsReporter{stats: &accesscontrol.Stats{
+		RolesByKind:            map[string]int64{"managed": 4, "fixed": 10},
+		PermissionsByKind:      map[string]int64{"dashboards": 5, "folders": 3},
+		LargestRolePermissions: 6,
+	}}
+	actionSets := fakeActionSetLister{"dashboards:view": {"dashboards:read"}, "folders:view": {"folders:read"}}
+
+	t.Run("should report the statistics of the roles and permissions", func(t *testing.T) {
+		m, err := database.NewUsageStatsCollector(store, actionSets, fakeTupleCounter{count: 42}).Collect(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{
+			"stats.accesscontrol.roles.managed.count":             int64(4),
+			"stats.accesscontrol.roles.fixed.count":               int64(10),
+			"stats.accesscontrol.roles.custom.count":              int64(0),
+			"stats.accesscontrol.permissions.count":               int64(8),
+			"stats.accesscontrol.roles.largest.permissions.count": int64(6),
+			"stats.accesscontrol.action_sets.count":               2,
+			"stats.accesscontrol.zanzana.tuples.count":            int64(42),
+		}, m)
+	})
+
+	t.Run("should skip the statistics of disabled components", func(t *testing.T) {
+		m, err := database.NewUsageStatsCollector(store, nil, nil).Collect(context.Background())
+		require.NoError(t, err)
+		require.NotContains(t, m, "stats.accesscontrol.action_sets.count")
+		require.NotContains(t, m, "stats.accesscontrol.zanzana.tuples.count")
+	})
+
+	t.Run("should report the statistics of the database when zanzana is unavailable", func(t *testing.T) {
+		m, err := database.NewUsageStatsCollector(store, actionSets, fakeTupleCounter{err: errors.New("unavailable")}).Collect(context.Background())
+		require.NoError(t, err)
+		require.NotContains(t, m, "stats.accesscontrol.zanzana.tuples.count")
+		require.Equal(t, int64(8), m["stats.accesscontrol.permissions.count"])
+	})
+
+	t.Run("should skip the tuples when the zanzana store cannot count them", func(t *testing.T) {
+		m, err := database.NewUsageStatsCollector(store, actionSets, fakeTupleCounter{err: accesscontrol.ErrTupleCountUnavailable}).Collect(context.Background())
+		require.NoError(t, err)
+		require.NotContains(t, m, "stats.accesscontrol.zanzana.tuples.count")
+	})
+}
@@ -0,0 +1,164 @@
+// Package debugsession captures the access control decisions made while serving requests opting in with the
+// X-Grafana-RBAC-Debug header, so admins can see why a permission filtered response contains what it contains.
+package debugsession
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// HeaderName is the request header opting in to a debug session and the response header holding its id.
+const HeaderName = "X-Grafana-RBAC-Debug"
+
+// maxEntries bounds the memory used by a session, search requests can evaluate thousands of permissions.
+const maxEntries = 1000
+
+// maxSessions bounds the number of saved sessions, the least recently used ones are evicted first. Together with
+// maxEntries it bounds the memory used by debug sessions whatever the number of requests opting in.
+const maxSessions = 100
+
+const (
+	KindEvaluation = "evaluation"
+	KindSQLFilter  = "sql_filter"
+	KindCheck      = "check"
+)
+
+type Entry struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+	Args   []any  `json:"args,omitempty"`
+	Result string `json:"result,omitempty"`
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserUID   string    `json:"userUid"`
+	OrgID     int64     `json:"orgId"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Started   time.Time `json:"started"`
+	Entries   []Entry   `json:"entries"`
+	Truncated bool      `json:"truncated"`
+
+	mu sync.Mutex
+}
+
+func (s *Session) record(e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.Entries) >= maxEntries {
+		s.Truncated = true
+		return
+	}
+	s.Entries = append(s.Entries, e)
+}
+
+func (s *Session) snapshot() *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &Session{
+		ID:        s.ID,
+		UserUID:   s.UserUID,
+		OrgID:     s.OrgID,
+		Method:    s.Method,
+		Path:      s.Path,
+		Started:   s.Started,
+		Entries:   append([]Entry{}, s.Entries...),
+		Truncated: s.Truncated,
+	}
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context recording the access control decisions into session.
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// FromContext returns the session of the request, nil when the request did not opt in.
+func FromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// RecordEvaluation records the evaluation of evaluator, described with its actions and scopes, for the request of ctx.
+func RecordEvaluation(ctx context.Context, evaluator fmt.GoStringer, allowed bool, err error) {
+	if session := FromContext(ctx); session != nil {
+		session.record(Entry{Kind: KindEvaluation, Detail: evaluator.GoString(), Result: result(allowed, err)})
+	}
+}
+
+// RecordSQLFilter records the where clause and arguments a query of the request of ctx was filtered with.
+func RecordSQLFilter(ctx context.Context, name, where string, args []any) {
+	if session := FromContext(ctx); session != nil {
+		session.record(Entry{Kind: KindSQLFilter, Detail: name + ":" + where, Args: args})
+	}
+}
+
+// RecordCheck records a zanzana check of the request of ctx, tuple is written as "<user> <relation> <object>".
+func RecordCheck(ctx context.Context, tuple string, allowed bool, err error) {
+	if session := FromContext(ctx); session != nil {
+		session.record(Entry{Kind: KindCheck, Detail: tuple, Result: result(allowed, err)})
+	}
+}
+
+func result(allowed bool, err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+// ProvideStore returns the store of debug sessions, it returns nil when debug sessions are disabled.
+// A nil store is valid and never starts a session.
+func ProvideStore(cfg *setting.Cfg) *Store {
+	if cfg == nil || !cfg.RBAC.DebugSessions {
+		return nil
+	}
+	return NewStore(cfg.RBAC.DebugSessionTTL)
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{cache: expirable.NewLRU[string, *Session](maxSessions, nil, ttl)}
+}
+
+// Store keeps the sessions of finished requests in memory until they expire, up to maxSessions.
+type Store struct {
+	cache *expirable.LRU[string, *Session]
+}
+
+// Start returns a new session for a request of the user.
+func (s *Store) Start(userUID string, orgID int64, method, path string) *Session {
+	if s == nil {
+		return nil
+	}
+	return &Session{ID: util.GenerateShortUID(), UserUID: userUID, OrgID: orgID, Method: method, Path: path, Started: time.Now()}
+}
+
+// Save keeps session retrievable by its id until it expires.
+func (s *Store) Save(session *Session) {
+	if s == nil || session == nil {
+		return
+	}
+	s.cache.Add(session.ID, session.snapshot())
+}
+
+// Get returns the session with id, false when it does not exist or has expired.
+func (s *Store) Get(id string) (*Session, bool) {
+	if s == nil {
+		return nil, false
+	}
+	return s.cache.Get(id)
+}
@@ -0,0 +1,74 @@
+package debugsession
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestProvideStore(t *testing.T) {
+	cfg := setting.NewCfg()
+	assert.Nil(t, ProvideStore(cfg))
+
+	cfg.RBAC.DebugSessions = true
+	cfg.RBAC.DebugSessionTTL = time.Minute
+	assert.NotNil(t, ProvideStore(cfg))
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(time.Minute)
+	session := store.Start("user:1", 1, "GET", "/api/search")
+	ctx := WithSession(context.Background(), session)
+
+	RecordEvaluation(ctx, accesscontrol.EvalPermission("dashboards:read", "dashboards:uid:1"), true, nil)
+	RecordSQLFilter(ctx, "search", "dashboard.uid IN (?)", []any{"1"})
+	RecordCheck(ctx, "user:1 read dashboard:1", false, errors.New("unavailable"))
+
+	_, ok := store.Get(session.ID)
+	require.False(t, ok, "session should only be retrievable once saved")
+
+	store.Save(session)
+	saved, ok := store.Get(session.ID)
+	require.True(t, ok)
+	assert.Equal(t, []Entry{
+		{Kind: KindEvaluation, Detail: "action:dashboards:read scopes:dashboards:uid:1", Result: "allowed"},
+		{Kind: KindSQLFilter, Detail: "search:dashboard.uid IN (?)", Args: []any{"1"}},
+		{Kind: KindCheck, Detail: "user:1 read dashboard:1", Result: "error: unavailable"},
+	}, saved.Entries)
+}
+
+func TestSession_Truncated(t *testing.T) {
+	var store *Store
+	assert.Nil(t, store.Start("user:1", 1, "GET", "/"))
+
+	session := NewStore(time.Minute).Start("user:1", 1, "GET", "/")
+	ctx := WithSession(context.Background(), session)
+	for i := 0; i < maxEntries+1; i++ {
+		RecordCheck(ctx, "user:1 read dashboard:1", true, nil)
+	}
+	assert.Len(t, session.Entries, maxEntries)
+	assert.True(t, session.Truncated)
+
+	// Requests without a session record nothing
+	RecordCheck(context.Background(), "user:1 read dashboard:1", true, nil)
+}
+
+func TestStore_MaxSessions(t *testing.T) {
+	store := NewStore(time.Minute)
+	first := store.Start("user:1", 1, "GET", "/")
+	store.Save(first)
+	for i := 0; i < maxSessions; i++ {
+		store.Save(store.Start("user:1", 1, "GET", "/"))
+	}
+
+	_, ok := store.Get(first.ID)
+	assert.False(t, ok, "the oldest session should be evicted")
+	assert.Equal(t, maxSessions, store.cache.Len())
+}
@@ -0,0 +1,48 @@
+package accesscontrol
+
+import "context"
+
+// UserDeletionPreviewer is implemented by services and stores that can report what deleting the permissions of a
+// user removes, so admin tooling can show the impact of deleting a user before it is deleted.
+type UserDeletionPreviewer interface {
+	// PreviewDeleteUserPermissions returns what DeleteUserPermissions would remove for the user in the org, or in
+	// every org when orgID is GlobalOrgID. Nothing is removed.
+	PreviewDeleteUserPermissions(ctx context.Context, orgID, userID int64) (*UserDeletionPreview, error)
+}
+
+// UserDeletionPreview lists the access control state removed with the permissions of a user.
+type UserDeletionPreview struct {
+	OrgID  int64 `json:"orgId"`
+	UserID int64 `json:"userId"`
+	// ManagedRoles are the managed roles of the user, they are removed together with their permissions
+	ManagedRoles []DeletedManagedRole `json:"managedRoles"`
+	// Assignments are the roles assigned to the user, including its managed roles
+	Assignments []DeletedRoleAssignment `json:"assignments"`
+	// ScopedPermissions are the permissions of other roles on the user, e.g. users:read on users:id:<id>.
+	// They are only removed when the user is removed from every org.
+	ScopedPermissions []Permission `json:"scopedPermissions"`
+	// ExternalGroups are the external groups the user is a member of, only removed with the scoped permissions
+	ExternalGroups []string `json:"externalGroups"`
+	// Tuples are the zanzana tuples where the user is the subject, they are only listed when zanzana is enabled
+	// and the user is removed from every org
+	Tuples []DeletedTuple `json:"tuples,omitempty"`
+}
+
+type DeletedManagedRole struct {
+	OrgID       int64        `json:"orgId"`
+	UID         string       `json:"uid"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type DeletedRoleAssignment struct {
+	OrgID    int64  `json:"orgId" xorm:"org_id"`
+	RoleUID  string `json:"roleUid" xorm:"role_uid"`
+	RoleName string `json:"roleName" xorm:"role_name"`
+}
+
+type DeletedTuple struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
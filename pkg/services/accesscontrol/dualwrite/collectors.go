@@ -4,20 +4,22 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 )
 
 func teamMembershipCollector(store db.DB) legacyTupleCollector {
-	return func(ctx context.Context) (map[string]map[string]*openfgav1.TupleKey, error) {
+	return func(ctx context.Context, shard orgShard) (map[string]map[string]*openfgav1.TupleKey, error) {
 		query := `
-			SELECT t.uid as team_uid, u.uid as user_uid, tm.permission
+			SELECT t.org_id, t.uid as team_uid, u.uid as user_uid, tm.permission
 			FROM team_member tm
 			INNER JOIN team t ON tm.team_id = t.id
 			INNER JOIN ` + store.GetDialect().Quote("user") + ` u ON tm.user_id = u.id
 		`
 
 		type membership struct {
+			OrgID      int64  `xorm:"org_id"`
 			TeamUID    string `xorm:"team_uid"`
 			UserUID    string `xorm:"user_uid"`
 			Permission int
@@ -35,6 +37,10 @@ func teamMembershipCollector(store db.DB) legacyTupleCollector {
 		tuples := make(map[string]map[string]*openfgav1.TupleKey)
 
 		for _, m := range memberships {
+			if !shard.owns(m.OrgID) {
+				continue
+			}
+
 			tuple := &openfgav1.TupleKey{
 				User:   zanzana.NewTupleEntry(zanzana.TypeUser, m.UserUID, ""),
 				Object: zanzana.NewTupleEntry(zanzana.TypeTeam, m.TeamUID, ""),
@@ -107,3 +113,49 @@ func zanzanaCollector(client zanzana.Client, relations []string) zanzanaTupleCol
 		return out, nil
 	}
 }
+
+func externalGroupMembershipCollector(store db.DB) legacyTupleCollector {
+	return func(ctx context.Context, shard orgShard) (map[string]map[string]*openfgav1.TupleKey, error) {
+		tuples := make(map[string]map[string]*openfgav1.TupleKey)
+		// External groups don't belong to an org, they are reconciled by the owner of the global org
+		if !shard.owns(accesscontrol.GlobalOrgID) {
+			return tuples, nil
+		}
+
+		query := `
+			SELECT ueg.group_id, u.uid as user_uid
+			FROM user_external_group ueg
+			INNER JOIN ` + store.GetDialect().Quote("user") + ` u ON ueg.user_id = u.id
+		`
+
+		type membership struct {
+			GroupID string `xorm:"group_id"`
+			UserUID string `xorm:"user_uid"`
+		}
+
+		var memberships []membership
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL(query).Find(&memberships)
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range memberships {
+			tuple := &openfgav1.TupleKey{
+				User:     zanzana.NewTupleEntry(zanzana.TypeUser, m.UserUID, ""),
+				Relation: zanzana.RelationGroupMember,
+				Object:   zanzana.NewTupleEntry(zanzana.TypeGroup, accesscontrol.ExternalGroupUID(m.GroupID), ""),
+			}
+
+			if tuples[tuple.Object] == nil {
+				tuples[tuple.Object] = make(map[string]*openfgav1.TupleKey)
+			}
+
+			tuples[tuple.Object][tuple.String()] = tuple
+		}
+
+		return tuples, nil
+	}
+}
@@ -0,0 +1,119 @@
+package dualwrite
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+// subjectObjectTypes are all object types a user or team can be related to.
+var subjectObjectTypes = []string{
+	zanzana.TypeTeam,
+	zanzana.TypeGroup,
+	zanzana.TypeRole,
+	zanzana.TypeFolder,
+	zanzana.TypeDashboard,
+	zanzana.TypeOrg,
+	zanzana.TypeAlertRule,
+	zanzana.TypeLibraryPanel,
+	zanzana.TypePluginResource,
+	zanzana.TypeShareToken,
+}
+
+// DeleteUserTuples removes every tuple where the user is the subject.
+// It is used to synchronously clean up users that are deprovisioned instead of waiting for the reconciliation.
+func (r *ZanzanaReconciler) DeleteUserTuples(ctx context.Context, userUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.DeleteUserTuples")
+	defer span.End()
+
+	return r.deleteTuples(ctx, subjectReadRequests(zanzana.NewTupleEntry(zanzana.TypeUser, userUID, "")))
+}
+
+// UserTuples returns every tuple where the user is the subject, i.e. the tuples DeleteUserTuples removes.
+func (r *ZanzanaReconciler) UserTuples(ctx context.Context, userID int64) ([]*openfgav1.TupleKey, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.UserTuples")
+	defer span.End()
+
+	var uid string
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT uid FROM "+r.store.GetDialect().Quote("user")+" WHERE id = ?", userID).Get(&uid)
+		return err
+	})
+	if err != nil || uid == "" {
+		return nil, err
+	}
+
+	return r.readTuples(ctx, subjectReadRequests(zanzana.NewTupleEntry(zanzana.TypeUser, uid, "")))
+}
+
+// DeleteTeamTuples removes every tuple where the team members are the subject together with the team memberships.
+// It is used to synchronously clean up teams that are deprovisioned instead of waiting for the reconciliation.
+func (r *ZanzanaReconciler) DeleteTeamTuples(ctx context.Context, teamUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.DeleteTeamTuples")
+	defer span.End()
+
+	team := zanzana.NewTupleEntry(zanzana.TypeTeam, teamUID, "")
+	requests := subjectReadRequests(zanzana.NewTupleEntry(zanzana.TypeTeam, teamUID, zanzana.RelationTeamMember))
+	requests = append(requests,
+		&openfgav1.ReadRequestTupleKey{Object: team, Relation: zanzana.RelationTeamMember},
+		&openfgav1.ReadRequestTupleKey{Object: team, Relation: zanzana.RelationTeamAdmin},
+	)
+
+	return r.deleteTuples(ctx, requests)
+}
+
+func subjectReadRequests(subject string) []*openfgav1.ReadRequestTupleKey {
+	requests := make([]*openfgav1.ReadRequestTupleKey, 0, len(subjectObjectTypes))
+	for _, objectType := range subjectObjectTypes {
+		// An object with only a type and no id matches all objects of that type
+		requests = append(requests, &openfgav1.ReadRequestTupleKey{User: subject, Object: objectType + ":"})
+	}
+	return requests
+}
+
+func (r *ZanzanaReconciler) deleteTuples(ctx context.Context, requests []*openfgav1.ReadRequestTupleKey) error {
+	tuples, err := r.readTuples(ctx, requests)
+	if err != nil {
+		return err
+	}
+	return r.writeDeletes(ctx, withoutCondition(tuples))
+}
+
+// readTuples returns the tuples matching any of the requests, a nil request matches every tuple of the store.
+func (r *ZanzanaReconciler) readTuples(ctx context.Context, requests []*openfgav1.ReadRequestTupleKey) ([]*openfgav1.TupleKey, error) {
+	tuples := []*openfgav1.TupleKey{}
+	for _, key := range requests {
+		token := ""
+		for {
+			res, err := r.client.Read(ctx, &openfgav1.ReadRequest{TupleKey: key, ContinuationToken: token})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, t := range res.GetTuples() {
+				tuples = append(tuples, t.GetKey())
+			}
+
+			token = res.GetContinuationToken()
+			if token == "" {
+				break
+			}
+		}
+	}
+	return tuples, nil
+}
+
+func (r *ZanzanaReconciler) writeDeletes(ctx context.Context, deletes []*openfgav1.TupleKeyWithoutCondition) error {
+	if len(deletes) == 0 {
+		return nil
+	}
+
+	return batch(deletes, 100, func(items []*openfgav1.TupleKeyWithoutCondition) error {
+		return r.client.Write(ctx, &openfgav1.WriteRequest{
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: items},
+		})
+	})
+}
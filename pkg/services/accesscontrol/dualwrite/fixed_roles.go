@@ -0,0 +1,352 @@
+package dualwrite
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+var basicRoles = []string{zanzana.RoleGrafanaAdmin, zanzana.RoleAdmin, zanzana.RoleEditor, zanzana.RoleViewer, zanzana.RoleNone}
+
+// fixedRoleObjectTypes are the object types the permissions of fixed roles can be translated to, they can be related
+// to every object type a subject can be related to.
+var fixedRoleObjectTypes = subjectObjectTypes
+
+// pluginRoleUIDPrefix prefixes the translated names of plugin roles, e.g. plugins_grafana-oncall-app_reader
+var pluginRoleUIDPrefix = zanzana.TranslateFixedRole(accesscontrol.PluginRolePrefix)
+
+// SetFixedRoles sets the fixed and plugin roles declared by the instance. The reconciliation writes their
+// permissions and their assignments to basic roles in every org, and removes the tuples of the fixed roles and
+// assignments that are not declared anymore, e.g. after an upgrade.
+func (r *ZanzanaReconciler) SetFixedRoles(registrations []accesscontrol.RoleRegistration) {
+	version := accesscontrol.FixedRolesVersion(registrations)
+
+	r.fixedRolesMu.Lock()
+	defer r.fixedRolesMu.Unlock()
+	r.fixedRoles = registrations
+	r.fixedRolesVersion = version
+}
+
+func (r *ZanzanaReconciler) getFixedRoles() ([]accesscontrol.RoleRegistration, string) {
+	r.fixedRolesMu.RLock()
+	defer r.fixedRolesMu.RUnlock()
+	return r.fixedRoles, r.fixedRolesVersion
+}
+
+// reconcileFixedRoles reconciles the tuples of the fixed roles in the orgs owned by the shard. The version of the
+// roles reconciled in each org is stored, the orgs already at the version of the declared roles are skipped and the
+// others are updated with the changes between the roles declared at their version and the declared roles.
+func (r *ZanzanaReconciler) reconcileFixedRoles(ctx context.Context, shard orgShard) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.reconcileFixedRoles")
+	defer span.End()
+
+	registrations, version := r.getFixedRoles()
+	// Nothing is removed before the roles are declared
+	if len(registrations) == 0 {
+		return nil
+	}
+
+	var orgs []fixedRolesOrg
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(`SELECT o.id, COALESCE(v.version, '') AS version FROM org AS o
+			LEFT JOIN zanzana_fixed_role_version AS v ON v.org_id = o.id`).Find(&orgs)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(orgs, func(org fixedRolesOrg) bool { return shard.owns(org.ID) && org.Version != version }) {
+		return nil
+	}
+	if err := r.storeFixedRolesDeclaration(ctx, version, registrations); err != nil {
+		return err
+	}
+
+	declarations := map[string][]accesscontrol.RoleRegistration{}
+	for _, org := range orgs {
+		if !shard.owns(org.ID) || org.Version == version {
+			continue
+		}
+
+		previous, ok := declarations[org.Version]
+		if !ok && org.Version != "" {
+			if previous, err = r.getFixedRolesDeclaration(ctx, org.Version); err != nil {
+				return err
+			}
+			declarations[org.Version] = previous
+		}
+
+		// The tuples of the org are updated with the changes since the version it was reconciled at, they are read
+		// and compared to the declared roles when the previous roles are unknown or the update fails
+		if previous == nil || r.writeOrgFixedRolesChanges(ctx, previous, registrations, org.ID) != nil {
+			if err := r.reconcileOrgFixedRoles(ctx, registrations, org.ID); err != nil {
+				return err
+			}
+		}
+		if err := r.storeFixedRolesVersion(ctx, org.ID, org.Version != "", version); err != nil {
+			return err
+		}
+	}
+
+	// The declarations no org is reconciled at anymore are removed
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(
+			"DELETE FROM zanzana_fixed_role_declaration WHERE version <> ? AND version NOT IN (SELECT version FROM zanzana_fixed_role_version)",
+			version,
+		)
+		return err
+	})
+}
+
+type fixedRolesOrg struct {
+	ID      int64  `xorm:"id"`
+	Version string `xorm:"version"`
+}
+
+// declaredFixedRole is the part of a fixed role registration its tuples are generated from.
+type declaredFixedRole struct {
+	Name        string                    `json:"name"`
+	Grants      []string                  `json:"grants,omitempty"`
+	Permissions []declaredFixedPermission `json:"permissions,omitempty"`
+}
+
+type declaredFixedPermission struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope,omitempty"`
+}
+
+// storeFixedRolesDeclaration records the roles declared at the version, unless another replica already did.
+func (r *ZanzanaReconciler) storeFixedRolesDeclaration(ctx context.Context, version string, registrations []accesscontrol.RoleRegistration) error {
+	declared := make([]declaredFixedRole, 0, len(registrations))
+	for _, reg := range registrations {
+		role := declaredFixedRole{Name: reg.Role.Name, Grants: reg.Grants}
+		for _, p := range reg.Role.Permissions {
+			role.Permissions = append(role.Permissions, declaredFixedPermission{Action: p.Action, Scope: p.Scope})
+		}
+		declared = append(declared, role)
+	}
+	roles, err := json.Marshal(declared)
+	if err != nil {
+		return err
+	}
+
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.SQL("SELECT 1 FROM zanzana_fixed_role_declaration WHERE version = ?", version).Exist()
+		if err != nil || exists {
+			return err
+		}
+		_, err = sess.Exec("INSERT INTO zanzana_fixed_role_declaration (version, roles, created) VALUES (?, ?, ?)", version, string(roles), time.Now())
+		if err != nil && r.store.GetDialect().IsUniqueConstraintViolation(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// getFixedRolesDeclaration returns the roles declared at the version, or nil when they were not recorded.
+func (r *ZanzanaReconciler) getFixedRolesDeclaration(ctx context.Context, version string) ([]accesscontrol.RoleRegistration, error) {
+	var roles string
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT roles FROM zanzana_fixed_role_declaration WHERE version = ?", version).Get(&roles)
+		return err
+	})
+	if err != nil || roles == "" {
+		return nil, err
+	}
+
+	var declared []declaredFixedRole
+	if err := json.Unmarshal([]byte(roles), &declared); err != nil {
+		return nil, err
+	}
+	registrations := make([]accesscontrol.RoleRegistration, 0, len(declared))
+	for _, role := range declared {
+		reg := accesscontrol.RoleRegistration{Role: accesscontrol.RoleDTO{Name: role.Name}, Grants: role.Grants}
+		for _, p := range role.Permissions {
+			reg.Role.Permissions = append(reg.Role.Permissions, accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+		}
+		registrations = append(registrations, reg)
+	}
+	return registrations, nil
+}
+
+// writeOrgFixedRolesChanges writes the tuples of the declared roles that the previous roles didn't have and removes
+// the ones of the previous roles that the declared roles don't have, without reading the tuples of the org.
+func (r *ZanzanaReconciler) writeOrgFixedRolesChanges(ctx context.Context, previous, registrations []accesscontrol.RoleRegistration, orgID int64) error {
+	before, after := fixedRoleTuples(previous, orgID), fixedRoleTuples(registrations, orgID)
+
+	var writes []*openfgav1.TupleKey
+	for key, t := range after {
+		if _, ok := before[key]; !ok {
+			writes = append(writes, t)
+		}
+	}
+	var deletes []*openfgav1.TupleKey
+	for key, t := range before {
+		if _, ok := after[key]; !ok {
+			deletes = append(deletes, t)
+		}
+	}
+
+	if len(writes) > 0 || len(deletes) > 0 {
+		r.log.Debug("Updating fixed role tuples", "orgID", orgID, "writes", len(writes), "deletes", len(deletes))
+	}
+	return r.writeFixedRoleTuples(ctx, writes, deletes)
+}
+
+// storeFixedRolesVersion records the version of the fixed roles reconciled in the org.
+func (r *ZanzanaReconciler) storeFixedRolesVersion(ctx context.Context, orgID int64, exists bool, version string) error {
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		if exists {
+			_, err = sess.Exec("UPDATE zanzana_fixed_role_version SET version = ?, updated = ? WHERE org_id = ?", version, time.Now(), orgID)
+		} else {
+			_, err = sess.Exec("INSERT INTO zanzana_fixed_role_version (org_id, version, updated) VALUES (?, ?, ?)", orgID, version, time.Now())
+		}
+		return err
+	})
+}
+
+func (r *ZanzanaReconciler) reconcileOrgFixedRoles(ctx context.Context, registrations []accesscontrol.RoleRegistration, orgID int64) error {
+	expected := fixedRoleTuples(registrations, orgID)
+
+	org := zanzana.NewTupleEntry(zanzana.TypeOrg, strconv.FormatInt(orgID, 10), "")
+	requests := make([]*openfgav1.ReadRequestTupleKey, 0, 2*len(basicRoles))
+	for _, br := range basicRoles {
+		subject, ok := zanzana.GenerateBasicRoleResource(br, orgID, zanzana.RelationAssignee)
+		if !ok {
+			continue
+		}
+		requests = append(requests,
+			&openfgav1.ReadRequestTupleKey{User: subject, Object: zanzana.TypeRole + ":"},
+			&openfgav1.ReadRequestTupleKey{User: subject, Object: org},
+		)
+	}
+	assignments, err := r.readTuples(ctx, requests)
+	if err != nil {
+		return err
+	}
+
+	// The permissions of the declared roles and of the roles still assigned are read, the roles that are not
+	// declared anymore are removed together with their assignments.
+	roles := map[string]struct{}{}
+	for _, reg := range registrations {
+		if !zanzana.IsSchemaFixedRole(reg.Role.Name) {
+			roles[zanzana.GenerateFixedRoleResource(reg.Role.Name, orgID, zanzana.RelationAssignee)] = struct{}{}
+		}
+	}
+
+	actual := map[string]*openfgav1.TupleKey{}
+	for _, t := range assignments {
+		switch {
+		case t.GetObject() == org && strings.HasPrefix(t.GetRelation(), accesscontrol.FixedRoleUIDPrefix):
+			actual[t.String()] = t
+		case isFixedRoleEntry(t.GetObject(), orgID):
+			actual[t.String()] = t
+			roles[t.GetObject()+"#"+zanzana.RelationAssignee] = struct{}{}
+		}
+	}
+
+	for role := range roles {
+		permissions, err := r.readTuples(ctx, fixedRoleReadRequests(role))
+		if err != nil {
+			return err
+		}
+		for _, t := range permissions {
+			actual[t.String()] = t
+		}
+	}
+
+	var writes []*openfgav1.TupleKey
+	for key, t := range expected {
+		if _, ok := actual[key]; !ok {
+			writes = append(writes, t)
+		}
+	}
+	var deletes []*openfgav1.TupleKey
+	for key, t := range actual {
+		if _, ok := expected[key]; !ok {
+			deletes = append(deletes, t)
+		}
+	}
+
+	if len(writes) > 0 || len(deletes) > 0 {
+		r.log.Debug("Reconciling fixed role tuples", "orgID", orgID, "writes", len(writes), "deletes", len(deletes))
+	}
+	return r.writeFixedRoleTuples(ctx, writes, deletes)
+}
+
+func (r *ZanzanaReconciler) writeFixedRoleTuples(ctx context.Context, writes, deletes []*openfgav1.TupleKey) error {
+	if err := batch(writes, 100, func(items []*openfgav1.TupleKey) error {
+		return r.client.Write(ctx, &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: items},
+		})
+	}); err != nil {
+		return err
+	}
+	return r.writeDeletes(ctx, withoutCondition(deletes))
+}
+
+// fixedRoleTuples returns the tuples of the fixed roles in the org indexed by key: the assignments of the roles to
+// basic roles and the permissions of the roles that are not part of the schema.
+func fixedRoleTuples(registrations []accesscontrol.RoleRegistration, orgID int64) map[string]*openfgav1.TupleKey {
+	tuples := map[string]*openfgav1.TupleKey{}
+	for _, reg := range registrations {
+		for br := range accesscontrol.BuiltInRolesWithParents(reg.Grants) {
+			subject, ok := zanzana.GenerateBasicRoleResource(br, orgID, zanzana.RelationAssignee)
+			if !ok {
+				continue
+			}
+			tuple := zanzana.TranslateFixedRoleAssignment(subject, reg.Role.Name, orgID)
+			tuples[tuple.String()] = tuple
+		}
+
+		if zanzana.IsSchemaFixedRole(reg.Role.Name) {
+			continue
+		}
+
+		subject := zanzana.GenerateFixedRoleResource(reg.Role.Name, orgID, zanzana.RelationAssignee)
+		for _, p := range reg.Role.Permissions {
+			var (
+				tuple *openfgav1.TupleKey
+				ok    bool
+			)
+			kind, _, identifier := accesscontrol.SplitScope(p.Scope)
+			if identifier == "" || identifier == "*" {
+				tuple, ok = zanzana.TranslateToOrgTuple(subject, p.Action, orgID)
+			} else {
+				tuple, ok = zanzana.TranslateToTuple(subject, p.Action, kind, identifier, orgID)
+			}
+			// Permissions on kinds that are not part of the schema are not checked by zanzana
+			if ok {
+				tuples[tuple.String()] = tuple
+			}
+		}
+	}
+	return tuples
+}
+
+// isFixedRoleEntry returns true for the entries of fixed and plugin roles of the org, e.g. role:1-fixed_teams_reader.
+func isFixedRoleEntry(entry string, orgID int64) bool {
+	uid, ok := strings.CutPrefix(entry, zanzana.TypeRole+":"+strconv.FormatInt(orgID, 10)+"-")
+	if !ok || strings.Contains(uid, "#") {
+		return false
+	}
+	return strings.HasPrefix(uid, accesscontrol.FixedRoleUIDPrefix) || strings.HasPrefix(uid, pluginRoleUIDPrefix)
+}
+
+func fixedRoleReadRequests(subject string) []*openfgav1.ReadRequestTupleKey {
+	requests := make([]*openfgav1.ReadRequestTupleKey, 0, len(fixedRoleObjectTypes))
+	for _, objectType := range fixedRoleObjectTypes {
+		requests = append(requests, &openfgav1.ReadRequestTupleKey{User: subject, Object: objectType + ":"})
+	}
+	return requests
+}
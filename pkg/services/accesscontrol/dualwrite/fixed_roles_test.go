@@ -0,0 +1,93 @@
+package dualwrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestFixedRoleTuples(t *testing.T) {
+	registrations := []accesscontrol.RoleRegistration{
+		{
+			Role: accesscontrol.RoleDTO{Name: "fixed:dashboards:writer"},
+			// Editors are granted the role, and Admins through them
+			Grants: []string{"Editor"},
+		},
+		{
+			Role: accesscontrol.RoleDTO{Name: "fixed:teams:reader", Permissions: []accesscontrol.Permission{
+				{Action: "folders:read", Scope: "folders:*"},
+				{Action: "dashboards:read", Scope: "dashboards:uid:abc"},
+				// Not part of the schema
+				{Action: "datasources:query", Scope: "datasources:*"},
+			}},
+			Grants: []string{"Grafana Admin"},
+		},
+	}
+
+	tuples := fixedRoleTuples(registrations, 1)
+	var keys []*openfgav1.TupleKey
+	for _, tuple := range tuples {
+		keys = append(keys, tuple)
+	}
+	assert.ElementsMatch(t, []*openfgav1.TupleKey{
+		{User: "role:1-basic_editor#assignee", Relation: "fixed_dashboards_writer", Object: "org:1"},
+		{User: "role:1-basic_admin#assignee", Relation: "fixed_dashboards_writer", Object: "org:1"},
+		{User: "role:1-basic_grafana_admin#assignee", Relation: "assignee", Object: "role:1-fixed_teams_reader"},
+		{User: "role:1-fixed_teams_reader#assignee", Relation: "folder_read", Object: "org:1"},
+		{User: "role:1-fixed_teams_reader#assignee", Relation: "read", Object: "dashboard:1-abc"},
+	}, keys)
+}
+
+func TestIsFixedRoleEntry(t *testing.T) {
+	assert.True(t, isFixedRoleEntry("role:1-fixed_teams_reader", 1))
+	assert.True(t, isFixedRoleEntry("role:1-plugins_grafana-oncall-app_reader", 1))
+	assert.False(t, isFixedRoleEntry("role:2-fixed_teams_reader", 1))
+	assert.False(t, isFixedRoleEntry("role:1-basic_viewer", 1))
+	assert.False(t, isFixedRoleEntry("role:1-custom", 1))
+	assert.False(t, isFixedRoleEntry("role:1-fixed_teams_reader#assignee", 1))
+}
+
+func TestIntegrationReconcileFixedRoles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := db.InitTestDB(t)
+	client := &fakeOutboxClient{}
+	r := newOutboxReconciler(store, client)
+	createOutboxOrg(t, store)
+
+	writer := accesscontrol.RoleDTO{Name: "fixed:dashboards:writer"}
+	r.SetFixedRoles([]accesscontrol.RoleRegistration{{Role: writer, Grants: []string{"Editor"}}})
+	require.NoError(t, r.reconcileFixedRoles(context.Background(), orgShard{}))
+	require.NotEmpty(t, client.writtenTuples())
+
+	t.Run("should only write the changes of the declared roles", func(t *testing.T) {
+		client.writes = nil
+		r.SetFixedRoles([]accesscontrol.RoleRegistration{{Role: writer, Grants: []string{"Viewer"}}})
+		require.NoError(t, r.reconcileFixedRoles(context.Background(), orgShard{}))
+
+		written := client.writtenTuples()
+		require.NotEmpty(t, written)
+		for _, tuple := range written {
+			assert.True(t, strings.HasSuffix(tuple.GetUser(), "-basic_viewer#assignee"), tuple.GetUser())
+		}
+	})
+
+	t.Run("should remove the declarations no org is reconciled at", func(t *testing.T) {
+		var versions []string
+		err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.SQL("SELECT version FROM zanzana_fixed_role_declaration").Find(&versions)
+		})
+		require.NoError(t, err)
+		_, version := r.getFixedRoles()
+		assert.Equal(t, []string{version}, versions)
+	})
+}
@@ -0,0 +1,30 @@
+package dualwrite
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsSubSystem = "authz"
+	metricsNamespace = "grafana"
+)
+
+var (
+	// mUnmappedActionsTotal counts the permissions skipped by the sync because their action has no relation in the schema
+	mUnmappedActionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "zanzana_unmapped_actions_total",
+		Help:      "Number of permissions not synced to zanzana because their action is not mapped to a relation of the resource kind.",
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubSystem,
+	}, []string{"kind"})
+
+	metricsOnce sync.Once
+)
+
+func initMetrics() {
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(mUnmappedActionsTotal)
+	})
+}
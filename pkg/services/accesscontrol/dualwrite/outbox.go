@@ -0,0 +1,351 @@
+package dualwrite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+const (
+	// outboxPollInterval is how often the outbox is checked for tuple writes to dispatch
+	outboxPollInterval = 5 * time.Second
+	// outboxBatchSize is the number of outbox entries dispatched together
+	outboxBatchSize = 100
+	// outboxClaimDuration is how long an entry claimed by a replica is hidden from the others, entries claimed by
+	// a replica stopped while dispatching them are dispatched again once it expires
+	outboxClaimDuration = time.Minute
+	// outboxBaseBackoff and outboxMaxBackoff bound the delay before an entry that failed to be dispatched is retried
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+	// outboxBasicRoles is the resource of the entries syncing the basic role assignments of their user in their org
+	outboxBasicRoles = "basic_roles"
+)
+
+// OutboxEntry is a managed permission of an assignee on a resource whose zanzana tuples need to be written. Entries
+// are added in the transaction setting the permission, so tuples are only written for committed permissions and a
+// zanzana outage doesn't fail the write of the permission.
+type OutboxEntry struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	Resource    string `xorm:"resource"`
+	ResourceID  string `xorm:"resource_id"`
+	UserID      int64  `xorm:"user_id"`
+	TeamID      int64  `xorm:"team_id"`
+	BuiltInRole string `xorm:"builtin_role"`
+	GroupID     string `xorm:"group_id"`
+	// Container entries have no assignee, the tuple relating the resource to the folder containing it is written
+	// instead of the tuples of a permission. Basic role entries have no resource, see EnqueueBasicRoleAssignments.
+	Container   bool      `xorm:"container"`
+	Attempts    int       `xorm:"attempts"`
+	LastError   string    `xorm:"last_error"`
+	NextAttempt time.Time `xorm:"next_attempt"`
+	Created     time.Time `xorm:"created"`
+}
+
+func (OutboxEntry) TableName() string {
+	return "zanzana_outbox"
+}
+
+// EnqueueResourcePermissionTuples adds the assignments to the outbox using the session of the transaction setting
+// their permissions. Their tuples are written by DispatchOutbox once the transaction is committed.
+func EnqueueResourcePermissionTuples(sess *db.Session, orgID int64, assignments ...accesscontrol.ResourceAssignment) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]*OutboxEntry, 0, len(assignments))
+	for _, a := range assignments {
+		entries = append(entries, &OutboxEntry{
+			OrgID:       orgID,
+			Resource:    a.Resource,
+			ResourceID:  a.ResourceID,
+			UserID:      a.UserID,
+			TeamID:      a.TeamID,
+			BuiltInRole: a.BuiltInRole,
+			GroupID:     a.GroupID,
+			NextAttempt: now,
+			Created:     now,
+		})
+	}
+	_, err := sess.InsertMulti(entries)
+	return err
+}
+
+// EnqueueResourceAssignments adds the assignments to the outbox using the transaction on ctx when it has one, e.g.
+// the transaction removing their permissions outside of the resource permissions services.
+func (r *ZanzanaReconciler) EnqueueResourceAssignments(ctx context.Context, orgID int64, assignments ...accesscontrol.ResourceAssignment) error {
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return EnqueueResourcePermissionTuples(sess, orgID, assignments...)
+	})
+}
+
+// EnqueueResourceContainerTuple adds the resource to the outbox once it moved to another folder, using the
+// transaction of the move when ctx has one. The tuple relating it to the folder containing it once the move is
+// committed is written by DispatchOutbox, only folders are related to their container.
+func (r *ZanzanaReconciler) EnqueueResourceContainerTuple(ctx context.Context, orgID int64, kind, resourceID string) error {
+	if kind != zanzana.KindFolders {
+		return nil
+	}
+
+	now := time.Now()
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&OutboxEntry{
+			OrgID:       orgID,
+			Resource:    kind,
+			ResourceID:  resourceID,
+			Container:   true,
+			NextAttempt: now,
+			Created:     now,
+		})
+		return err
+	})
+}
+
+// DispatchOutbox writes the tuples of the outbox entries to zanzana until ctx is cancelled. Entries are removed once
+// their tuples are written, failed ones are retried with an exponential backoff. Replicas dispatch the outbox
+// concurrently, each entry is claimed by a single replica at a time.
+func (r *ZanzanaReconciler) DispatchOutbox(ctx context.Context) error {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			finish := r.outboxJob.Start()
+			// Keep dispatching while full batches are found, so a backlog doesn't wait for the next tick
+			var err error
+			for {
+				var dispatched int
+				dispatched, err = r.dispatchOutbox(ctx, time.Now())
+				if err != nil {
+					r.log.Warn("Failed to dispatch zanzana outbox", "err", err)
+					break
+				}
+				if dispatched < outboxBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+			finish(err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// dispatchOutbox claims a batch of the entries due at now and writes their tuples, it returns the number of entries
+// claimed.
+func (r *ZanzanaReconciler) dispatchOutbox(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.dispatchOutbox")
+	defer span.End()
+
+	claimed, err := r.claimOutboxEntries(ctx, now)
+	if err != nil || len(claimed) == 0 {
+		return 0, err
+	}
+
+	// Entries of deleted orgs or of resources that can't be translated would fail the write of the whole batch of
+	// their org on every attempt, they are dropped instead
+	entries, unresolved, err := r.resolveOutboxEntries(ctx, claimed)
+	if err != nil {
+		return len(claimed), err
+	}
+	if len(unresolved) > 0 {
+		r.log.Debug("Dropping unresolved zanzana outbox entries", "entries", len(unresolved))
+		if err := r.deleteOutboxEntries(ctx, unresolved); err != nil {
+			return len(claimed), err
+		}
+	}
+
+	for orgID, orgEntries := range groupOutboxEntries(entries) {
+		var assignments []accesscontrol.ResourceAssignment
+		var containers, basicRoles []OutboxEntry
+		for _, e := range orgEntries {
+			if e.Container {
+				containers = append(containers, e)
+				continue
+			}
+			if e.Resource == outboxBasicRoles {
+				basicRoles = append(basicRoles, e)
+				continue
+			}
+			assignments = append(assignments, accesscontrol.ResourceAssignment{
+				Resource:    e.Resource,
+				ResourceID:  e.ResourceID,
+				UserID:      e.UserID,
+				TeamID:      e.TeamID,
+				BuiltInRole: e.BuiltInRole,
+				GroupID:     e.GroupID,
+			})
+		}
+
+		writeErr := r.WriteResourcePermissionTuples(ctx, orgID, assignments)
+		if writeErr == nil {
+			writeErr = r.writeContainerTuples(ctx, orgID, containers)
+		}
+		if writeErr == nil {
+			writeErr = r.writeBasicRoleAssignments(ctx, orgID, basicRoles)
+		}
+		if writeErr != nil {
+			r.log.Warn("Failed to write tuples of zanzana outbox entries", "orgID", orgID, "entries", len(orgEntries), "err", writeErr)
+			if err := r.retryOutboxEntries(ctx, orgEntries, now, writeErr); err != nil {
+				return len(claimed), err
+			}
+			continue
+		}
+
+		if err := r.deleteOutboxEntries(ctx, orgEntries); err != nil {
+			return len(claimed), err
+		}
+	}
+
+	return len(claimed), nil
+}
+
+// writeContainerTuples relates the folders of the entries to the folder containing them, as stored once their move
+// was committed. Entries of folders deleted since are skipped, the deletion removes their tuples.
+func (r *ZanzanaReconciler) writeContainerTuples(ctx context.Context, orgID int64, entries []OutboxEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	uids := make([]any, 0, len(entries))
+	for _, e := range entries {
+		uids = append(uids, e.ResourceID)
+	}
+
+	type folder struct {
+		UID       string `xorm:"uid"`
+		ParentUID string `xorm:"parent_uid"`
+	}
+	var folders []folder
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		query := "SELECT uid, COALESCE(parent_uid, '') AS parent_uid FROM folder WHERE org_id = ? AND uid IN (?" + strings.Repeat(",?", len(uids)-1) + ")"
+		return sess.SQL(query, append([]any{orgID}, uids...)...).Find(&folders)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range folders {
+		if err := r.MoveResourceTuples(ctx, orgID, zanzana.KindFolders, f.UID, f.ParentUID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveOutboxEntries splits the entries into the ones whose org exists and whose resource translates to a zanzana
+// object, and the unresolved ones.
+func (r *ZanzanaReconciler) resolveOutboxEntries(ctx context.Context, entries []OutboxEntry) ([]OutboxEntry, []OutboxEntry, error) {
+	orgIDs := make([]any, 0, len(entries))
+	seen := make(map[int64]struct{}, len(entries))
+	for _, e := range entries {
+		if _, ok := seen[e.OrgID]; ok {
+			continue
+		}
+		seen[e.OrgID] = struct{}{}
+		orgIDs = append(orgIDs, e.OrgID)
+	}
+
+	var existing []int64
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT id FROM org WHERE id IN (?"+strings.Repeat(",?", len(orgIDs)-1)+")", orgIDs...).Find(&existing)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	orgs := make(map[int64]struct{}, len(existing))
+	for _, id := range existing {
+		orgs[id] = struct{}{}
+	}
+
+	var resolved, unresolved []OutboxEntry
+	for _, e := range entries {
+		_, orgExists := orgs[e.OrgID]
+		_, ok := zanzana.TranslateToObject(e.Resource, e.ResourceID, e.OrgID)
+		if !orgExists || (!ok && e.Resource != outboxBasicRoles) {
+			unresolved = append(unresolved, e)
+			continue
+		}
+		resolved = append(resolved, e)
+	}
+	return resolved, unresolved, nil
+}
+
+// claimOutboxEntries returns the entries due at now that this replica claimed. An entry is claimed by incrementing
+// its attempts, a replica claiming it concurrently finds the attempts changed and skips it.
+func (r *ZanzanaReconciler) claimOutboxEntries(ctx context.Context, now time.Time) ([]OutboxEntry, error) {
+	var claimed []OutboxEntry
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var due []OutboxEntry
+		if err := sess.Where("next_attempt <= ?", now).Asc("id").Limit(outboxBatchSize).Find(&due); err != nil {
+			return err
+		}
+
+		for _, e := range due {
+			res, err := sess.Exec("UPDATE zanzana_outbox SET attempts = ?, next_attempt = ? WHERE id = ? AND attempts = ?",
+				e.Attempts+1, now.Add(outboxClaimDuration), e.ID, e.Attempts)
+			if err != nil {
+				return err
+			}
+			if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+				continue
+			}
+			e.Attempts++
+			claimed = append(claimed, e)
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// retryOutboxEntries schedules the next attempt of the entries that failed to be dispatched.
+func (r *ZanzanaReconciler) retryOutboxEntries(ctx context.Context, entries []OutboxEntry, now time.Time, cause error) error {
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, e := range entries {
+			if _, err := sess.Exec("UPDATE zanzana_outbox SET last_error = ?, next_attempt = ? WHERE id = ?",
+				cause.Error(), now.Add(outboxBackoff(e.Attempts)), e.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ZanzanaReconciler) deleteOutboxEntries(ctx context.Context, entries []OutboxEntry) error {
+	ids := make([]any, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(append([]any{"DELETE FROM zanzana_outbox WHERE id IN (?" + strings.Repeat(",?", len(ids)-1) + ")"}, ids...)...)
+		return err
+	})
+}
+
+// groupOutboxEntries groups the entries by organization, tuples are written per organization.
+func groupOutboxEntries(entries []OutboxEntry) map[int64][]OutboxEntry {
+	grouped := make(map[int64][]OutboxEntry)
+	for _, e := range entries {
+		grouped[e.OrgID] = append(grouped[e.OrgID], e)
+	}
+	return grouped
+}
+
+// outboxBackoff returns the delay before the next attempt of an entry that failed attempts times, it doubles with
+// each attempt up to outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,257 @@
+package dualwrite
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 5 * time.Second},
+		{attempts: 2, expected: 10 * time.Second},
+		{attempts: 5, expected: 80 * time.Second},
+		{attempts: 7, expected: 320 * time.Second},
+		{attempts: 8, expected: outboxMaxBackoff},
+		{attempts: 100, expected: outboxMaxBackoff},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, outboxBackoff(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
+
+func TestGroupOutboxEntries(t *testing.T) {
+	entries := []OutboxEntry{
+		{ID: 1, OrgID: 1, Resource: "folders", ResourceID: "f1", UserID: 1},
+		{ID: 2, OrgID: 2, Resource: "folders", ResourceID: "f1", TeamID: 1},
+		{ID: 3, OrgID: 1, Resource: "dashboards", ResourceID: "d1", BuiltInRole: "Viewer"},
+	}
+
+	grouped := groupOutboxEntries(entries)
+	assert.Equal(t, map[int64][]OutboxEntry{
+		1: {entries[0], entries[2]},
+		2: {entries[1]},
+	}, grouped)
+}
+
+func TestIntegrationOutbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	viewer := accesscontrol.ResourceAssignment{Resource: "folders", ResourceID: "f1", BuiltInRole: "Viewer"}
+
+	t.Run("should not enqueue entries of a rolled back transaction", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		errRollback := errors.New("rollback")
+		err := store.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+			require.NoError(t, EnqueueResourcePermissionTuples(sess, 1, viewer))
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+		assert.Empty(t, outboxEntries(t, store))
+	})
+
+	t.Run("should claim an entry once until its claim expires", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		r := newOutboxReconciler(store, &fakeOutboxClient{})
+		enqueue(t, store, 1, viewer)
+
+		now := time.Now()
+		claimed, err := r.claimOutboxEntries(context.Background(), now)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 1, claimed[0].Attempts)
+
+		claimed, err = r.claimOutboxEntries(context.Background(), now)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		claimed, err = r.claimOutboxEntries(context.Background(), now.Add(outboxClaimDuration))
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, 2, claimed[0].Attempts)
+	})
+
+	t.Run("should write the tuples of an entry and remove it", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		client := &fakeOutboxClient{}
+		r := newOutboxReconciler(store, client)
+		orgID := createOutboxOrg(t, store)
+		createManagedPermission(t, store, orgID, accesscontrol.ManagedBuiltInRoleName("Viewer"), "folders:read", "folders", "f1")
+		enqueue(t, store, orgID, viewer)
+
+		dispatched, err := r.dispatchOutbox(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+		assert.Empty(t, outboxEntries(t, store))
+
+		subject, _ := zanzana.GenerateBasicRoleResource("Viewer", orgID, zanzana.RelationAssignee)
+		object, _ := zanzana.TranslateToObject("folders", "f1", orgID)
+		writes := client.writtenTuples()
+		require.Len(t, writes, 1)
+		assert.Equal(t, subject, writes[0].GetUser())
+		assert.Equal(t, object, writes[0].GetObject())
+	})
+
+	t.Run("should retry an entry that failed to be dispatched", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		r := newOutboxReconciler(store, &fakeOutboxClient{err: errors.New("unavailable")})
+		orgID := createOutboxOrg(t, store)
+		createManagedPermission(t, store, orgID, accesscontrol.ManagedBuiltInRoleName("Viewer"), "folders:read", "folders", "f1")
+		enqueue(t, store, orgID, viewer)
+
+		now := time.Now()
+		_, err := r.dispatchOutbox(context.Background(), now)
+		require.NoError(t, err)
+
+		entries := outboxEntries(t, store)
+		require.Len(t, entries, 1)
+		assert.Equal(t, 1, entries[0].Attempts)
+		assert.Equal(t, "unavailable", entries[0].LastError)
+		assert.WithinDuration(t, now.Add(outboxBackoff(1)), entries[0].NextAttempt, time.Second)
+	})
+
+	t.Run("should sync the basic role assignments of the users of an entry", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		client := &fakeOutboxClient{}
+		r := newOutboxReconciler(store, client)
+		orgID := createOutboxOrg(t, store)
+		now := time.Now()
+		u := &user.User{UID: "u1", Login: "u1", Email: "u1@example.org", OrgID: orgID, IsAdmin: true, Created: now, Updated: now}
+		err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			if _, err := sess.Insert(u); err != nil {
+				return err
+			}
+			_, err := sess.Insert(&org.OrgUser{OrgID: orgID, UserID: u.ID, Role: org.RoleEditor, Created: now, Updated: now})
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, r.EnqueueBasicRoleAssignments(context.Background(), orgID, u.ID))
+
+		dispatched, err := r.dispatchOutbox(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+		assert.Empty(t, outboxEntries(t, store))
+
+		editor, _ := zanzana.GenerateBasicRoleResource(string(org.RoleEditor), orgID, "")
+		admin, _ := zanzana.GenerateBasicRoleResource(zanzana.RoleGrafanaAdmin, orgID, "")
+		assert.Equal(t, []*openfgav1.TupleKey{
+			{User: "user:u1", Relation: zanzana.RelationAssignee, Object: editor},
+			{User: "user:u1", Relation: zanzana.RelationAssignee, Object: admin},
+		}, client.writtenTuples())
+	})
+
+	t.Run("should drop entries of deleted orgs and of resources that can't be translated", func(t *testing.T) {
+		store := db.InitTestDB(t)
+		client := &fakeOutboxClient{}
+		r := newOutboxReconciler(store, client)
+		orgID := createOutboxOrg(t, store)
+		enqueue(t, store, orgID+1, viewer)
+		enqueue(t, store, orgID, accesscontrol.ResourceAssignment{Resource: "unknown", ResourceID: "1", BuiltInRole: "Viewer"})
+
+		dispatched, err := r.dispatchOutbox(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 2, dispatched)
+		assert.Empty(t, outboxEntries(t, store))
+		assert.Empty(t, client.writtenTuples())
+	})
+}
+
+func newOutboxReconciler(store db.DB, client zanzana.Client) *ZanzanaReconciler {
+	return &ZanzanaReconciler{client: client, store: store, log: log.NewNopLogger()}
+}
+
+func enqueue(t *testing.T, store db.DB, orgID int64, assignments ...accesscontrol.ResourceAssignment) {
+	t.Helper()
+	err := store.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+		return EnqueueResourcePermissionTuples(sess, orgID, assignments...)
+	})
+	require.NoError(t, err)
+}
+
+func outboxEntries(t *testing.T, store db.DB) []OutboxEntry {
+	t.Helper()
+	var entries []OutboxEntry
+	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.Asc("id").Find(&entries)
+	})
+	require.NoError(t, err)
+	return entries
+}
+
+func createOutboxOrg(t *testing.T, store db.DB) int64 {
+	t.Helper()
+	o := &org.Org{Name: "outbox", Created: time.Now(), Updated: time.Now()}
+	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(o)
+		return err
+	})
+	require.NoError(t, err)
+	return o.ID
+}
+
+func createManagedPermission(t *testing.T, store db.DB, orgID int64, roleName, action, kind, identifier string) {
+	t.Helper()
+	now := time.Now()
+	err := store.WithDbSession(context.Background(), func(sess *db.Session) error {
+		role := &accesscontrol.Role{OrgID: orgID, UID: roleName, Name: roleName, Created: now, Updated: now}
+		if _, err := sess.Insert(role); err != nil {
+			return err
+		}
+		_, err := sess.Insert(&accesscontrol.Permission{
+			RoleID: role.ID, Action: action, Scope: kind + ":uid:" + identifier,
+			Kind: kind, Attribute: "uid", Identifier: identifier, Created: now, Updated: now,
+		})
+		return err
+	})
+	require.NoError(t, err)
+}
+
+type fakeOutboxClient struct {
+	zanzana.Client
+	err    error
+	mu     sync.Mutex
+	writes []*openfgav1.TupleKey
+}
+
+func (c *fakeOutboxClient) Read(ctx context.Context, in *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
+	return &openfgav1.ReadResponse{}, nil
+}
+
+func (c *fakeOutboxClient) Write(ctx context.Context, in *openfgav1.WriteRequest) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes = append(c.writes, in.GetWrites().GetTupleKeys()...)
+	return nil
+}
+
+func (c *fakeOutboxClient) writtenTuples() []*openfgav1.TupleKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writes
+}
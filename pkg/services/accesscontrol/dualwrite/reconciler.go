@@ -2,23 +2,32 @@ package dualwrite
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"go.opentelemetry.io/otel"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/jobstatus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/accesscontrol/migrator")
 
+// reconcileInterval is how often permissions are reconciled to zanzana
+const reconcileInterval = time.Hour
+
 // A TupleCollector is responsible to build and store [openfgav1.TupleKey] into provided tuple map.
 // They key used should be a unique group key for the collector so we can skip over an already synced group.
 type TupleCollector func(ctx context.Context, tuples map[string][]*openfgav1.TupleKey) error
@@ -30,6 +39,9 @@ type ZanzanaReconciler struct {
 	lock   *serverlock.ServerLockService
 	log    log.Logger
 	client zanzana.Client
+	store  db.DB
+	// embeddedStore is set when the zanzana server is embedded and stores its tuples in the grafana database
+	embeddedStore bool
 	// collectors are one time best effort migrations that gives up on first conflict.
 	// These are deprecated and everything should move be resourceReconcilers that are periodically synced
 	// between grafana db and zanzana store.
@@ -37,27 +49,63 @@ type ZanzanaReconciler struct {
 	// reconcilers are migrations that tries to reconcile the state of grafana db to zanzana store.
 	// These are run periodically to try to maintain a consistent state.
 	reconcilers []resourceReconciler
+	// sharding splits the reconciliation between the replicas, nil when a single replica reconciles all orgs
+	sharding *orgSharding
+	status   reconcilerStatus
+	// fixedRoles are the fixed and plugin roles declared by the instance, their tuples are reconciled in every org
+	// until the org is at fixedRolesVersion
+	fixedRoles        []accesscontrol.RoleRegistration
+	fixedRolesVersion string
+	fixedRolesMu      sync.RWMutex
+	// jobs record the runs of the sync and of the periodic reconciliation, nil when they are not registered
+	syncJob       *jobstatus.Job
+	reconcileJob  *jobstatus.Job
+	roleTuplesJob *jobstatus.Job
+	outboxJob     *jobstatus.Job
+	// roleTuplesToken is where the next check of the tuples referencing deleted roles continues reading the store
+	roleTuplesToken string
+}
+
+// uncachedWriter sends the writes of the reconciler whatever the write cache of the client, they are computed from
+// the tuples read from the store and a tuple removed by another instance would otherwise not be written again.
+type uncachedWriter struct {
+	zanzana.Client
 }
 
-func NewZanzanaReconciler(client zanzana.Client, store db.DB, lock *serverlock.ServerLockService, collectors ...TupleCollector) *ZanzanaReconciler {
+func (c uncachedWriter) Write(ctx context.Context, in *openfgav1.WriteRequest) error {
+	return c.Client.Write(zanzana.WithoutWriteCache(ctx), in)
+}
+
+func NewZanzanaReconciler(cfg *setting.Cfg, client zanzana.Client, store db.DB, lock *serverlock.ServerLockService, collectors ...TupleCollector) *ZanzanaReconciler {
+	initMetrics()
+	client = uncachedWriter{client}
+
 	// Append shared collectors that is used by both enterprise and oss
 	collectors = append(
 		collectors,
-		managedPermissionsCollector(store),
+		managedPermissionsCollector(store, cfg.Zanzana.StrictTranslation),
 		folderTreeCollector(store),
-		basicRolesCollector(store),
-		customRolesCollector(store),
+		basicRolesCollector(store, cfg.Zanzana.StrictTranslation),
+		customRolesCollector(store, cfg.Zanzana.StrictTranslation),
 		basicRoleAssignemtCollector(store),
 		userRoleAssignemtCollector(store),
 		teamRoleAssignemtCollector(store),
 		fixedRoleTuplesCollector(store),
 	)
 
+	var sharding *orgSharding
+	if cfg.Zanzana.ShardedReconciliation {
+		sharding = newOrgSharding(cfg.InstanceName, store)
+	}
+
 	return &ZanzanaReconciler{
-		client:     client,
-		lock:       lock,
-		log:        log.New("zanzana.reconciler"),
-		collectors: collectors,
+		sharding:      sharding,
+		client:        client,
+		store:         store,
+		embeddedStore: cfg.Zanzana.Mode == setting.ZanzanaModeEmbedded,
+		lock:          lock,
+		log:           log.New("zanzana.reconciler"),
+		collectors:    collectors,
 		reconcilers: []resourceReconciler{
 			newResourceReconciler(
 				"team memberships",
@@ -65,23 +113,50 @@ func NewZanzanaReconciler(client zanzana.Client, store db.DB, lock *serverlock.S
 				zanzanaCollector(client, []string{zanzana.RelationTeamMember, zanzana.RelationTeamAdmin}),
 				client,
 			),
+			newResourceReconciler(
+				"external group memberships",
+				externalGroupMembershipCollector(store),
+				zanzanaCollector(client, []string{zanzana.RelationGroupMember}),
+				client,
+			),
 		},
 	}
 }
 
+// RegisterJobs registers the sync, the reconciliation and the outbox dispatch jobs with the registry of the background
+// jobs.
+func (r *ZanzanaReconciler) RegisterJobs(registry *jobstatus.Registry) {
+	r.syncJob = registry.Register("zanzana-sync", 0)
+	r.reconcileJob = registry.Register("zanzana-reconciliation", reconcileInterval)
+	r.roleTuplesJob = registry.Register("zanzana-role-tuples-cleanup", reconcileInterval)
+	r.outboxJob = registry.Register("zanzana-outbox-dispatch", outboxPollInterval)
+}
+
 // Sync runs all collectors and tries to write all collected tuples.
 // It will skip over any "sync group" that has already been written.
-func (r *ZanzanaReconciler) Sync(ctx context.Context) error {
+func (r *ZanzanaReconciler) Sync(ctx context.Context) (err error) {
 	r.log.Info("Starting zanzana permissions sync")
 	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.Sync")
 	defer span.End()
-
-	tuplesMap := make(map[string][]*openfgav1.TupleKey)
-
-	for _, c := range r.collectors {
-		if err := c(ctx, tuplesMap); err != nil {
-			return fmt.Errorf("failed to collect permissions: %w", err)
+	finish := r.syncJob.Start()
+	defer func() {
+		r.syncFinished(err)
+		finish(err)
+	}()
+
+	var tuplesMap map[string][]*openfgav1.TupleKey
+	err = inSnapshot(ctx, r.store, func(ctx context.Context) error {
+		// The transaction can be retried, collect from scratch
+		tuplesMap = make(map[string][]*openfgav1.TupleKey)
+		for _, c := range r.collectors {
+			if err := c(ctx, tuplesMap); err != nil {
+				return fmt.Errorf("failed to collect permissions: %w", err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	for key, tuples := range tuplesMap {
@@ -105,6 +180,25 @@ func (r *ZanzanaReconciler) Sync(ctx context.Context) error {
 	return nil
 }
 
+// inSnapshot runs fn in a read transaction placed on the context, the collectors then read all tables from a single
+// snapshot and the changes made during the sync can't produce tuples that are inconsistent with each other.
+func inSnapshot(ctx context.Context, store db.DB, fn func(ctx context.Context) error) error {
+	return store.InTransaction(ctx, func(ctx context.Context) error {
+		// MySQL transactions are repeatable read by default and SQLite transactions are serializable, Postgres
+		// transactions are read committed and each statement would see the changes committed since the previous one.
+		if store.GetDialect().DriverName() == migrator.Postgres {
+			err := store.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY")
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return fn(ctx)
+	})
+}
+
 // Reconcile schedules as job that will run and reconcile resources between
 // legacy access control and zanzana.
 func (r *ZanzanaReconciler) Reconcile(ctx context.Context) error {
@@ -114,12 +208,36 @@ func (r *ZanzanaReconciler) Reconcile(ctx context.Context) error {
 	// FIXME:
 	// 1. We should be a bit graceful about reconciliations so we are not hammering dbs
 	// 2. We should be able to configure reconciliation interval
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	// Replicas not sending heartbeats lose their orgs, a nil channel never fires when sharding is disabled
+	var heartbeat <-chan time.Time
+	if r.sharding != nil {
+		heartbeatTicker := time.NewTicker(instanceHeartbeatInterval)
+		defer heartbeatTicker.Stop()
+		heartbeat = heartbeatTicker.C
+
+		if err := r.sharding.heartbeat(ctx); err != nil {
+			r.log.Warn("Failed to register instance for sharded reconciliation", "err", err)
+		}
+	}
+
 	for {
 		select {
 		case <-ticker.C:
 			r.reconcile(ctx)
+		case <-heartbeat:
+			if err := r.sharding.heartbeat(ctx); err != nil {
+				r.log.Warn("Failed to send reconciliation heartbeat", "err", err)
+			}
 		case <-ctx.Done():
+			if r.sharding != nil {
+				// The context is done, leaving must not be canceled with it
+				if err := r.sharding.leave(context.WithoutCancel(ctx)); err != nil {
+					r.log.Warn("Failed to leave sharded reconciliation", "err", err)
+				}
+			}
 			return ctx.Err()
 		}
 	}
@@ -127,13 +245,36 @@ func (r *ZanzanaReconciler) Reconcile(ctx context.Context) error {
 
 func (r *ZanzanaReconciler) reconcile(ctx context.Context) {
 	run := func(ctx context.Context) {
+		// The shard is computed under the lock, replicas joining or leaving change the owners of orgs between two
+		// runs but never make two replicas reconcile an org at the same time
+		var shard orgShard
+		if r.sharding != nil {
+			var err error
+			if shard, err = r.sharding.shard(ctx); err != nil {
+				r.log.Warn("Failed to get the orgs to reconcile", "err", err)
+				return
+			}
+		}
+
 		now := time.Now()
+		finish := r.reconcileJob.Start()
+		var errs []error
 		for _, reconciler := range r.reconcilers {
-			if err := reconciler.reconcile(ctx); err != nil {
+			if err := reconciler.reconcile(ctx, shard); err != nil {
 				r.log.Warn("Failed to perform reconciliation for resource", "err", err)
+				errs = append(errs, err)
 			}
 		}
-		r.log.Debug("Finished reconciliation", "elapsed", time.Since(now))
+		if err := r.roleTuplesJob.Track(func() error { return r.checkRoleTuples(ctx, shard) }); err != nil {
+			r.log.Warn("Failed to remove tuples referencing deleted roles", "err", err)
+		}
+		if err := r.reconcileFixedRoles(ctx, shard); err != nil {
+			r.log.Warn("Failed to reconcile fixed roles", "err", err)
+			errs = append(errs, err)
+		}
+		r.log.Debug("Finished reconciliation", "elapsed", time.Since(now), "instances", len(shard.instances))
+		r.reconciliationFinished(now, shard)
+		finish(errors.Join(errs...))
 	}
 
 	// in tests we can skip creating a lock
@@ -142,20 +283,69 @@ func (r *ZanzanaReconciler) reconcile(ctx context.Context) {
 		return
 	}
 
+	// Sharded replicas reconcile their orgs one after the other, they wait for the lock instead of skipping the run
+	// so the orgs they own are not left behind until the next reconciliation
+	if r.sharding != nil {
+		_ = r.lock.LockExecuteAndReleaseWithRetries(ctx, "zanzana-reconciliation", serverlock.LockTimeConfig{
+			MaxInterval: 10 * time.Hour,
+			MinWait:     time.Second,
+			MaxWait:     10 * time.Second,
+		}, run, func(int) error {
+			return ctx.Err()
+		})
+		return
+	}
+
 	// We ignore the error for now
 	_ = r.lock.LockExecuteAndRelease(ctx, "zanzana-reconciliation", 10*time.Hour, func(ctx context.Context) {
 		run(ctx)
 	})
 }
 
+// validateAction counts the permissions skipped because their action is not mapped to a relation of a kind that is
+// part of the schema. Unlike the kinds zanzana doesn't handle yet these are gaps in the model, in strict mode they
+// fail the sync so they are found during the rollout instead of leaving permissions that are never checked. The
+// actions known to be left out, see skippedAction, are not validated.
+func validateAction(strict bool, action, kind string, orgWide bool) error {
+	if skippedAction(action) {
+		return nil
+	}
+
+	validate := zanzana.ValidateAction
+	if orgWide {
+		validate = zanzana.ValidateOrgAction
+	}
+
+	err := validate(action, kind)
+	if !errors.Is(err, zanzana.ErrUnmappedAction) {
+		return nil
+	}
+
+	mUnmappedActionsTotal.WithLabelValues(kind).Inc()
+	if strict {
+		return err
+	}
+	return nil
+}
+
+// skippedAction returns true for the actions deliberately not synced. Action sets are stored along with the actions
+// they grant, which are synced, and annotations are checked against the relations of their dashboard.
+func skippedAction(action string) bool {
+	if strings.HasPrefix(action, "annotations:") {
+		return true
+	}
+	_, level, ok := strings.Cut(action, ":")
+	return ok && (level == "view" || level == "edit" || level == "admin")
+}
+
 // managedPermissionsCollector collects managed permissions into provided tuple map.
 // It will only store actions that are supported by our schema. Managed permissions can
 // be directly mapped to user/team/role without having to write an intermediate role.
-func managedPermissionsCollector(store db.DB) TupleCollector {
+func managedPermissionsCollector(store db.DB, strict bool) TupleCollector {
 	return func(ctx context.Context, tuples map[string][]*openfgav1.TupleKey) error {
 		const collectorID = "managed"
 		query := `
-			SELECT u.uid as user_uid, t.uid as team_uid, p.action, p.kind, p.identifier, r.org_id
+			SELECT u.uid as user_uid, t.uid as team_uid, gr.group_id, br.role as builtin_role, p.action, p.kind, p.identifier, p.deny, r.org_id
 			FROM permission p
 			INNER JOIN role r ON p.role_id = r.id
 			LEFT JOIN user_role ur ON r.id = ur.role_id
@@ -163,16 +353,20 @@ func managedPermissionsCollector(store db.DB) TupleCollector {
 			LEFT JOIN team_role tr ON r.id = tr.role_id
 			LEFT JOIN team t ON tr.team_id = t.id
 			LEFT JOIN builtin_role br ON r.id  = br.role_id
+			LEFT JOIN group_role gr ON r.id = gr.role_id
 			WHERE r.name LIKE 'managed:%'
 		`
 		type Permission struct {
-			RoleName   string `xorm:"role_name"`
-			OrgID      int64  `xorm:"org_id"`
-			Action     string `xorm:"action"`
-			Kind       string
-			Identifier string
-			UserUID    string `xorm:"user_uid"`
-			TeamUID    string `xorm:"team_uid"`
+			RoleName    string `xorm:"role_name"`
+			OrgID       int64  `xorm:"org_id"`
+			Action      string `xorm:"action"`
+			Kind        string
+			Identifier  string
+			UserUID     string `xorm:"user_uid"`
+			TeamUID     string `xorm:"team_uid"`
+			GroupID     string `xorm:"group_id"`
+			BuiltInRole string `xorm:"builtin_role"`
+			Deny        bool   `xorm:"deny"`
 		}
 
 		var permissions []Permission
@@ -184,14 +378,27 @@ func managedPermissionsCollector(store db.DB) TupleCollector {
 			return err
 		}
 
+		denies := make(map[string]struct{})
 		for _, p := range permissions {
+			p.Action = accesscontrol.ResolveActionAlias(p.Action)
+			// Deny permissions are translated to a single tuple per dashboard whatever their action
+			if !p.Deny {
+				if err := validateAction(strict, p.Action, p.Kind, false); err != nil {
+					return err
+				}
+			}
+
 			var subject string
 			if len(p.UserUID) > 0 {
 				subject = zanzana.NewTupleEntry(zanzana.TypeUser, p.UserUID, "")
 			} else if len(p.TeamUID) > 0 {
 				subject = zanzana.NewTupleEntry(zanzana.TypeTeam, p.TeamUID, "member")
+			} else if len(p.GroupID) > 0 {
+				subject = zanzana.NewTupleEntry(zanzana.TypeGroup, accesscontrol.ExternalGroupUID(p.GroupID), zanzana.RelationGroupMember)
+			} else if basicRole, ok := zanzana.GenerateBasicRoleResource(p.BuiltInRole, p.OrgID, zanzana.RelationAssignee); ok {
+				// Managed permissions for basic roles (including None and Grafana Admin) are bound to the basic role assignees
+				subject = basicRole
 			} else {
-				// FIXME(kalleep): Unsuported role binding (org role). We need to have basic roles in place
 				continue
 			}
 
@@ -200,6 +407,23 @@ func managedPermissionsCollector(store db.DB) TupleCollector {
 				continue
 			}
 
+			if p.Deny {
+				// Deny permissions are only supported by the schema of some kinds and all
+				// actions of a subject on a resource translate to a single deny tuple
+				if !zanzana.SupportsDeny(p.Kind) {
+					continue
+				}
+				tuple.Relation = zanzana.RelationDeny
+				if _, ok := denies[tuple.String()]; ok {
+					continue
+				}
+				denies[tuple.String()] = struct{}{}
+
+				key := fmt.Sprintf("%s-%s", collectorID, zanzana.RelationDeny)
+				tuples[key] = append(tuples[key], tuple)
+				continue
+			}
+
 			// our "sync key" is a combination of collectorID and action so we can run this
 			// sync new data when more actions are supported
 			key := fmt.Sprintf("%s-%s", collectorID, p.Action)
@@ -236,30 +460,33 @@ func folderTreeCollector(store db.DB) TupleCollector {
 		}
 
 		for _, f := range folders {
-			var tuple *openfgav1.TupleKey
-			if f.ParentUID != "" {
-				tuple = &openfgav1.TupleKey{
-					Object:   zanzana.NewScopedTupleEntry(zanzana.TypeFolder, f.FolderUID, "", strconv.FormatInt(f.OrgID, 10)),
-					Relation: zanzana.RelationParent,
-					User:     zanzana.NewScopedTupleEntry(zanzana.TypeFolder, f.ParentUID, "", strconv.FormatInt(f.OrgID, 10)),
-				}
-			} else {
-				// Map root folders to org
-				tuple = &openfgav1.TupleKey{
-					Object:   zanzana.NewScopedTupleEntry(zanzana.TypeFolder, f.FolderUID, "", strconv.FormatInt(f.OrgID, 10)),
-					Relation: zanzana.RelationOrg,
-					User:     zanzana.NewTupleEntry(zanzana.TypeOrg, strconv.FormatInt(f.OrgID, 10), ""),
-				}
-			}
-			tuples[collectorID] = append(tuples[collectorID], tuple)
+			tuples[collectorID] = append(tuples[collectorID], folderContainerTuple(f.OrgID, f.FolderUID, f.ParentUID))
 		}
 
 		return nil
 	}
 }
 
+// folderContainerTuple returns the tuple relating the folder to its parent folder, or to the org for root folders.
+func folderContainerTuple(orgID int64, folderUID, parentUID string) *openfgav1.TupleKey {
+	if parentUID != "" {
+		return &openfgav1.TupleKey{
+			Object:   zanzana.NewScopedTupleEntry(zanzana.TypeFolder, folderUID, "", strconv.FormatInt(orgID, 10)),
+			Relation: zanzana.RelationParent,
+			User:     zanzana.NewScopedTupleEntry(zanzana.TypeFolder, parentUID, "", strconv.FormatInt(orgID, 10)),
+		}
+	}
+
+	// Map root folders to org
+	return &openfgav1.TupleKey{
+		Object:   zanzana.NewScopedTupleEntry(zanzana.TypeFolder, folderUID, "", strconv.FormatInt(orgID, 10)),
+		Relation: zanzana.RelationOrg,
+		User:     zanzana.NewTupleEntry(zanzana.TypeOrg, strconv.FormatInt(orgID, 10), ""),
+	}
+}
+
 // basicRolesCollector migrates basic roles to OpenFGA tuples
-func basicRolesCollector(store db.DB) TupleCollector {
+func basicRolesCollector(store db.DB, strict bool) TupleCollector {
 	return func(ctx context.Context, tuples map[string][]*openfgav1.TupleKey) error {
 		const collectorID = "basic_role"
 		const query = `
@@ -287,6 +514,11 @@ func basicRolesCollector(store db.DB) TupleCollector {
 		}
 
 		for _, p := range permissions {
+			p.Action = accesscontrol.ResolveActionAlias(p.Action)
+			if err := validateAction(strict, p.Action, p.Kind, p.Identifier == "" || p.Identifier == "*"); err != nil {
+				return err
+			}
+
 			type Org struct {
 				Id   int64
 				Name string
@@ -335,7 +567,7 @@ func basicRolesCollector(store db.DB) TupleCollector {
 }
 
 // customRolesCollector migrates custom roles to OpenFGA tuples
-func customRolesCollector(store db.DB) TupleCollector {
+func customRolesCollector(store db.DB, strict bool) TupleCollector {
 	return func(ctx context.Context, tuples map[string][]*openfgav1.TupleKey) error {
 		const collectorID = "custom_role"
 		const query = `
@@ -365,6 +597,11 @@ func customRolesCollector(store db.DB) TupleCollector {
 		}
 
 		for _, p := range permissions {
+			p.Action = accesscontrol.ResolveActionAlias(p.Action)
+			if err := validateAction(strict, p.Action, p.Kind, p.Identifier == "" || p.Identifier == "*"); err != nil {
+				return err
+			}
+
 			var subject string
 			if p.RoleUID != "" {
 				subject = zanzana.NewScopedTupleEntry(zanzana.TypeRole, p.RoleUID, "assignee", strconv.FormatInt(p.OrgID, 10))
@@ -428,16 +665,14 @@ func basicRoleAssignemtCollector(store db.DB) TupleCollector {
 				continue
 			}
 
-			roleUID := zanzana.TranslateBasicRole(a.OrgRole)
-
-			tuple := &openfgav1.TupleKey{
-				User:     subject,
-				Relation: zanzana.RelationAssignee,
-				Object:   zanzana.NewScopedTupleEntry(zanzana.TypeRole, roleUID, "", strconv.FormatInt(a.OrgID, 10)),
+			key := fmt.Sprintf("%s-%s", collectorID, zanzana.RelationAssignee)
+			if role, ok := zanzana.GenerateBasicRoleResource(a.OrgRole, a.OrgID, ""); ok {
+				tuples[key] = append(tuples[key], &openfgav1.TupleKey{User: subject, Relation: zanzana.RelationAssignee, Object: role})
 			}
 
-			key := fmt.Sprintf("%s-%s", collectorID, zanzana.RelationAssignee)
-			tuples[key] = append(tuples[key], tuple)
+			if role, ok := zanzana.GenerateBasicRoleResource(zanzana.RoleGrafanaAdmin, a.OrgID, ""); ok && a.IsAdmin {
+				tuples[key] = append(tuples[key], &openfgav1.TupleKey{User: subject, Relation: zanzana.RelationAssignee, Object: role})
+			}
 		}
 
 		return nil
@@ -476,16 +711,11 @@ func userRoleAssignemtCollector(store db.DB) TupleCollector {
 			}
 
 			subject := zanzana.NewTupleEntry(zanzana.TypeUser, a.UserUID, "")
-			if strings.HasPrefix(a.RoleUID, "fixed_") {
-				// Fixed roles are defined in shema, so they are relations itself. Assignment should look like:
-				// user:<uid> fixed_folders_reader org:1
-				relation := zanzana.TranslateFixedRole(a.RoleName)
-				tuple := &openfgav1.TupleKey{
-					User:     subject,
-					Relation: relation,
-					Object:   zanzana.NewTupleEntry(zanzana.TypeOrg, strconv.FormatInt(a.OrgID, 10), ""),
-				}
-				key := fmt.Sprintf("%s-%s", collectorID, relation)
+			if strings.HasPrefix(a.RoleUID, accesscontrol.FixedRoleUIDPrefix) {
+				// Fixed roles of the schema are relations of the org, e.g. user:<uid> fixed_folders_reader org:1,
+				// the other ones are roles of the org, e.g. user:<uid> assignee role:1-fixed_teams_reader
+				tuple := zanzana.TranslateFixedRoleAssignment(subject, a.RoleName, a.OrgID)
+				key := fmt.Sprintf("%s-%s", collectorID, tuple.Relation)
 				tuples[key] = append(tuples[key], tuple)
 			} else {
 				tuple := &openfgav1.TupleKey{
@@ -535,16 +765,11 @@ func teamRoleAssignemtCollector(store db.DB) TupleCollector {
 			}
 
 			subject := zanzana.NewTupleEntry(zanzana.TypeTeam, a.TeamUID, "member")
-			if strings.HasPrefix(a.RoleUID, "fixed_") {
-				// Fixed roles are defined in shema, so they are relations itself. Assignment should look like:
-				// team:<uid> fixed_folders_reader org:1
-				relation := zanzana.TranslateFixedRole(a.RoleName)
-				tuple := &openfgav1.TupleKey{
-					User:     subject,
-					Relation: relation,
-					Object:   zanzana.NewTupleEntry(zanzana.TypeOrg, strconv.FormatInt(a.OrgID, 10), ""),
-				}
-				key := fmt.Sprintf("%s-%s", collectorID, relation)
+			if strings.HasPrefix(a.RoleUID, accesscontrol.FixedRoleUIDPrefix) {
+				// Fixed roles of the schema are relations of the org, e.g. team:<uid> fixed_folders_reader org:1,
+				// the other ones are roles of the org, e.g. team:<uid> assignee role:1-fixed_teams_reader
+				tuple := zanzana.TranslateFixedRoleAssignment(subject, a.RoleName, a.OrgID)
+				key := fmt.Sprintf("%s-%s", collectorID, tuple.Relation)
 				tuples[key] = append(tuples[key], tuple)
 			} else {
 				tuple := &openfgav1.TupleKey{
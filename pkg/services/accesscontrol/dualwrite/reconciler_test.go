@@ -0,0 +1,48 @@
+package dualwrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+func TestValidateAction(t *testing.T) {
+	tests := []struct {
+		desc     string
+		action   string
+		kind     string
+		orgWide  bool
+		unmapped bool
+	}{
+		{desc: "mapped action", action: "dashboards:read", kind: "dashboards"},
+		{desc: "mapped org wide action", action: "folders:create", kind: "folders", orgWide: true},
+		{desc: "kind not part of the schema", action: "datasources:query", kind: "datasources"},
+		{desc: "org wide action of kind not part of the schema", action: "datasources:query", kind: "datasources", orgWide: true},
+		{desc: "unmapped action", action: "dashboards:unknown", kind: "dashboards", unmapped: true},
+		{desc: "unmapped org wide action", action: "folders:unknown", kind: "folders", orgWide: true, unmapped: true},
+		{desc: "action set", action: "folders:edit", kind: "folders"},
+		{desc: "plugin action set", action: "test-app.projects:admin", kind: "folders"},
+		{desc: "annotation action", action: "annotations:write", kind: "dashboards"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			counter := mUnmappedActionsTotal.WithLabelValues(tt.kind)
+			before := testutil.ToFloat64(counter)
+
+			require.NoError(t, validateAction(false, tt.action, tt.kind, tt.orgWide))
+
+			err := validateAction(true, tt.action, tt.kind, tt.orgWide)
+			if tt.unmapped {
+				assert.ErrorIs(t, err, zanzana.ErrUnmappedAction)
+				assert.Equal(t, before+2, testutil.ToFloat64(counter))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, before, testutil.ToFloat64(counter))
+			}
+		})
+	}
+}
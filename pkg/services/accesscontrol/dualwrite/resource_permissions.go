@@ -0,0 +1,258 @@
+package dualwrite
+
+import (
+	"context"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+// maxWriteTuples is the number of tuples OpenFGA accepts in a single write request
+const maxWriteTuples = 100
+
+// WriteResourcePermissionTuples replaces the tuples of each assignee on each resource with the tuples translated from
+// the managed permissions stored for them. The changes of all the assignments are written in a single request when
+// they fit in one, so permission rollouts across many resources don't need a request per resource.
+func (r *ZanzanaReconciler) WriteResourcePermissionTuples(ctx context.Context, orgID int64, assignments []accesscontrol.ResourceAssignment) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.WriteResourcePermissionTuples")
+	defer span.End()
+
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	subjects, err := r.assigneeSubjects(ctx, orgID, assignments)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]*openfgav1.ReadRequestTupleKey, 0, len(assignments))
+	seen := make(map[string]struct{}, len(assignments))
+	for _, a := range assignments {
+		subject, ok := subjects[assigneeRoleName(a)]
+		if !ok {
+			continue
+		}
+		object, ok := zanzana.TranslateToObject(a.Resource, a.ResourceID, orgID)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[subject+"@"+object]; ok {
+			continue
+		}
+		seen[subject+"@"+object] = struct{}{}
+		requests = append(requests, &openfgav1.ReadRequestTupleKey{User: subject, Object: object})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	desired, err := r.managedPermissionTuples(ctx, orgID, assignments, subjects)
+	if err != nil {
+		return err
+	}
+
+	existing, err := r.readTuples(ctx, requests)
+	if err != nil {
+		return err
+	}
+
+	writes, deletes := diffTuples(desired, existing)
+	return r.writeChanges(ctx, writes, deletes)
+}
+
+// assigneeSubjects returns the zanzana subject of each assignee keyed by the name of its managed role, assignees
+// that don't exist anymore or can't be translated are left out.
+func (r *ZanzanaReconciler) assigneeSubjects(ctx context.Context, orgID int64, assignments []accesscontrol.ResourceAssignment) (map[string]string, error) {
+	subjects := make(map[string]string, len(assignments))
+	var userIDs, teamIDs []any
+	for _, a := range assignments {
+		switch {
+		case a.UserID != 0:
+			userIDs = append(userIDs, a.UserID)
+		case a.TeamID != 0:
+			teamIDs = append(teamIDs, a.TeamID)
+		case a.BuiltInRole != "":
+			if subject, ok := zanzana.GenerateBasicRoleResource(a.BuiltInRole, orgID, zanzana.RelationAssignee); ok {
+				subjects[accesscontrol.ManagedBuiltInRoleName(a.BuiltInRole)] = subject
+			}
+		case a.GroupID != "":
+			subjects[accesscontrol.ManagedGroupRoleName(a.GroupID)] = zanzana.NewTupleEntry(zanzana.TypeGroup, accesscontrol.ExternalGroupUID(a.GroupID), zanzana.RelationGroupMember)
+		}
+	}
+
+	type assignee struct {
+		ID  int64  `xorm:"id"`
+		UID string `xorm:"uid"`
+	}
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		err := batch(userIDs, 100, func(ids []any) error {
+			var users []assignee
+			query := "SELECT id, uid FROM " + r.store.GetDialect().Quote("user") + " WHERE id IN (?" + strings.Repeat(",?", len(ids)-1) + ")"
+			if err := sess.SQL(query, ids...).Find(&users); err != nil {
+				return err
+			}
+			for _, u := range users {
+				subjects[accesscontrol.ManagedUserRoleName(u.ID)] = zanzana.NewTupleEntry(zanzana.TypeUser, u.UID, "")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return batch(teamIDs, 100, func(ids []any) error {
+			var teams []assignee
+			query := "SELECT id, uid FROM team WHERE org_id = ? AND id IN (?" + strings.Repeat(",?", len(ids)-1) + ")"
+			if err := sess.SQL(query, append([]any{orgID}, ids...)...).Find(&teams); err != nil {
+				return err
+			}
+			for _, t := range teams {
+				subjects[accesscontrol.ManagedTeamRoleName(t.ID)] = zanzana.NewTupleEntry(zanzana.TypeTeam, t.UID, zanzana.RelationTeamMember)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subjects, nil
+}
+
+// managedPermissionTuples returns the tuples translated from the managed permissions of the assignees on the
+// resources of the assignments, keyed by their string representation.
+func (r *ZanzanaReconciler) managedPermissionTuples(
+	ctx context.Context, orgID int64, assignments []accesscontrol.ResourceAssignment, subjects map[string]string,
+) (map[string]*openfgav1.TupleKey, error) {
+	type permission struct {
+		RoleName   string `xorm:"role_name"`
+		Action     string `xorm:"action"`
+		Kind       string `xorm:"kind"`
+		Identifier string `xorm:"identifier"`
+		Deny       bool   `xorm:"deny"`
+	}
+
+	// Only the pairs of assignee and resource of the assignments are kept, the assignees can have permissions on
+	// other resources of the same batch that are not part of it
+	wanted := make(map[string]struct{}, len(assignments))
+	for _, a := range assignments {
+		wanted[assigneeRoleName(a)+"@"+a.Resource+":"+a.ResourceID] = struct{}{}
+	}
+
+	tuples := make(map[string]*openfgav1.TupleKey)
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return batch(assignments, 100, func(items []accesscontrol.ResourceAssignment) error {
+			roleNames := make([]any, 0, len(items))
+			filters := make([]string, 0, len(items))
+			args := []any{orgID}
+			for _, a := range items {
+				roleNames = append(roleNames, assigneeRoleName(a))
+				filters = append(filters, "(p.kind = ? AND p.identifier = ?)")
+				args = append(args, a.Resource, a.ResourceID)
+			}
+			args = append(args, roleNames...)
+
+			query := `SELECT r.name AS role_name, p.action, p.kind, p.identifier, p.deny
+				FROM permission p INNER JOIN role r ON p.role_id = r.id
+				WHERE r.org_id = ? AND (` + strings.Join(filters, " OR ") + `)
+				AND r.name IN (?` + strings.Repeat(",?", len(roleNames)-1) + `)`
+
+			var permissions []permission
+			if err := sess.SQL(query, args...).Find(&permissions); err != nil {
+				return err
+			}
+
+			for _, p := range permissions {
+				if _, ok := wanted[p.RoleName+"@"+p.Kind+":"+p.Identifier]; !ok {
+					continue
+				}
+				subject, ok := subjects[p.RoleName]
+				if !ok {
+					continue
+				}
+				tuple, ok := zanzana.TranslateToTuple(subject, accesscontrol.ResolveActionAlias(p.Action), p.Kind, p.Identifier, orgID)
+				if !ok {
+					continue
+				}
+				if p.Deny {
+					// Deny permissions are only supported by the schema of some kinds and all
+					// actions of a subject on a resource translate to a single deny tuple
+					if !zanzana.SupportsDeny(p.Kind) {
+						continue
+					}
+					tuple.Relation = zanzana.RelationDeny
+				}
+				tuples[tuple.String()] = tuple
+			}
+			return nil
+		})
+	})
+	return tuples, err
+}
+
+// diffTuples returns the desired tuples missing from existing and the existing tuples that are not desired.
+func diffTuples(desired map[string]*openfgav1.TupleKey, existing []*openfgav1.TupleKey) ([]*openfgav1.TupleKey, []*openfgav1.TupleKey) {
+	found := make(map[string]struct{}, len(existing))
+	var deletes []*openfgav1.TupleKey
+	for _, t := range existing {
+		key := (&openfgav1.TupleKey{User: t.GetUser(), Relation: t.GetRelation(), Object: t.GetObject()}).String()
+		found[key] = struct{}{}
+		if _, ok := desired[key]; !ok {
+			deletes = append(deletes, t)
+		}
+	}
+
+	var writes []*openfgav1.TupleKey
+	for key, t := range desired {
+		if _, ok := found[key]; !ok {
+			writes = append(writes, t)
+		}
+	}
+	return writes, deletes
+}
+
+// writeChanges writes and deletes the tuples in a single request when they fit in one, otherwise the writes are
+// applied before the deletes so assignees don't lose the access they keep in between.
+func (r *ZanzanaReconciler) writeChanges(ctx context.Context, writes, deletes []*openfgav1.TupleKey) error {
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	if len(writes)+len(deletes) <= maxWriteTuples {
+		req := &openfgav1.WriteRequest{}
+		if len(writes) > 0 {
+			req.Writes = &openfgav1.WriteRequestWrites{TupleKeys: writes}
+		}
+		if len(deletes) > 0 {
+			req.Deletes = &openfgav1.WriteRequestDeletes{TupleKeys: withoutCondition(deletes)}
+		}
+		return r.client.Write(ctx, req)
+	}
+
+	if err := batch(writes, maxWriteTuples, func(items []*openfgav1.TupleKey) error {
+		return r.client.Write(ctx, &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: items},
+		})
+	}); err != nil {
+		return err
+	}
+	return r.writeDeletes(ctx, withoutCondition(deletes))
+}
+
+func assigneeRoleName(a accesscontrol.ResourceAssignment) string {
+	switch {
+	case a.UserID != 0:
+		return accesscontrol.ManagedUserRoleName(a.UserID)
+	case a.TeamID != 0:
+		return accesscontrol.ManagedTeamRoleName(a.TeamID)
+	case a.GroupID != "":
+		return accesscontrol.ManagedGroupRoleName(a.GroupID)
+	default:
+		return accesscontrol.ManagedBuiltInRoleName(a.BuiltInRole)
+	}
+}
@@ -0,0 +1,59 @@
+package dualwrite
+
+import (
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTuples(t *testing.T) {
+	view := &openfgav1.TupleKey{User: "user:u1", Relation: "view", Object: "folder:1-f1"}
+	edit := &openfgav1.TupleKey{User: "user:u1", Relation: "edit", Object: "folder:1-f1"}
+	admin := &openfgav1.TupleKey{User: "user:u1", Relation: "admin", Object: "folder:1-f1"}
+
+	tests := []struct {
+		name            string
+		desired         []*openfgav1.TupleKey
+		existing        []*openfgav1.TupleKey
+		expectedWrites  []*openfgav1.TupleKey
+		expectedDeletes []*openfgav1.TupleKey
+	}{
+		{
+			name:           "should write missing tuples",
+			desired:        []*openfgav1.TupleKey{view, edit},
+			existing:       []*openfgav1.TupleKey{view},
+			expectedWrites: []*openfgav1.TupleKey{edit},
+		},
+		{
+			name:            "should delete tuples that are not desired",
+			desired:         []*openfgav1.TupleKey{view},
+			existing:        []*openfgav1.TupleKey{view, admin},
+			expectedDeletes: []*openfgav1.TupleKey{admin},
+		},
+		{
+			name:            "should replace tuples",
+			desired:         []*openfgav1.TupleKey{edit},
+			existing:        []*openfgav1.TupleKey{view},
+			expectedWrites:  []*openfgav1.TupleKey{edit},
+			expectedDeletes: []*openfgav1.TupleKey{view},
+		},
+		{
+			name:     "should ignore the condition of existing tuples",
+			desired:  []*openfgav1.TupleKey{view},
+			existing: []*openfgav1.TupleKey{{User: view.User, Relation: view.Relation, Object: view.Object, Condition: &openfgav1.RelationshipCondition{Name: "cond"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := make(map[string]*openfgav1.TupleKey, len(tt.desired))
+			for _, d := range tt.desired {
+				desired[d.String()] = d
+			}
+
+			writes, deletes := diffTuples(desired, tt.existing)
+			assert.ElementsMatch(t, tt.expectedWrites, writes)
+			assert.ElementsMatch(t, tt.expectedDeletes, deletes)
+		})
+	}
+}
@@ -8,8 +8,8 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 )
 
-// legacyTupleCollector collects tuples groupd by object and tupleKey
-type legacyTupleCollector func(ctx context.Context) (map[string]map[string]*openfgav1.TupleKey, error)
+// legacyTupleCollector collects tuples groupd by object and tupleKey, for the orgs owned by the shard
+type legacyTupleCollector func(ctx context.Context, shard orgShard) (map[string]map[string]*openfgav1.TupleKey, error)
 
 // zanzanaTupleCollector collects tuples from zanzana for given object
 type zanzanaTupleCollector func(ctx context.Context, client zanzana.Client, object string) (map[string]*openfgav1.TupleKey, error)
@@ -25,9 +25,9 @@ func newResourceReconciler(name string, legacy legacyTupleCollector, zanzana zan
 	return resourceReconciler{name, legacy, zanzana, client}
 }
 
-func (r resourceReconciler) reconcile(ctx context.Context) error {
+func (r resourceReconciler) reconcile(ctx context.Context, shard orgShard) error {
 	// 1. Fetch grafana resources stored in grafana db.
-	res, err := r.legacy(ctx)
+	res, err := r.legacy(ctx, shard)
 	if err != nil {
 		return fmt.Errorf("failed to collect legacy tuples for %s: %w", r.name, err)
 	}
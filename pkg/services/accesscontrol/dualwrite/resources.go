@@ -0,0 +1,34 @@
+package dualwrite
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+// MoveResourceTuples replaces the tuples relating the resource of kind to its container with the tuple relating it
+// to the folder with newParentUID, or to the org when it's empty, in a single write so the resource is never
+// related to both or to none. Only folders are related to their container, the other resources are granted access
+// through the tuples of their folder.
+func (r *ZanzanaReconciler) MoveResourceTuples(ctx context.Context, orgID int64, kind, resourceID, newParentUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.MoveResourceTuples")
+	defer span.End()
+
+	if kind != zanzana.KindFolders {
+		return nil
+	}
+
+	container := folderContainerTuple(orgID, resourceID, newParentUID)
+	tuples, err := r.readTuples(ctx, []*openfgav1.ReadRequestTupleKey{
+		{Object: container.GetObject(), Relation: zanzana.RelationParent},
+		{Object: container.GetObject(), Relation: zanzana.RelationOrg},
+	})
+	if err != nil {
+		return err
+	}
+
+	writes, deletes := diffTuples(map[string]*openfgav1.TupleKey{container.String(): container}, tuples)
+	return r.writeChanges(ctx, writes, deletes)
+}
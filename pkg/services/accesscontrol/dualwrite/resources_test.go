@@ -0,0 +1,19 @@
+package dualwrite
+
+import (
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFolderContainerTuple(t *testing.T) {
+	assert.Equal(t,
+		&openfgav1.TupleKey{User: "folder:1-parent", Relation: "parent", Object: "folder:1-child"},
+		folderContainerTuple(1, "child", "parent"),
+	)
+	assert.Equal(t,
+		&openfgav1.TupleKey{User: "org:1", Relation: "org", Object: "folder:1-root"},
+		folderContainerTuple(1, "root", ""),
+	)
+}
@@ -0,0 +1,350 @@
+package dualwrite
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+// RenameRoleTuples rewrites the tuples referencing the role with oldUID, either as assigned object
+// or as subject of permissions, so they reference newUID instead.
+func (r *ZanzanaReconciler) RenameRoleTuples(ctx context.Context, orgID int64, oldUID, newUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.RenameRoleTuples")
+	defer span.End()
+
+	tuples, err := r.readTuples(ctx, roleReadRequests(orgID, oldUID))
+	if err != nil {
+		return err
+	}
+
+	oldRole := roleEntry(orgID, oldUID, "")
+	newRole := roleEntry(orgID, newUID, "")
+	writes := make([]*openfgav1.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		renamed := &openfgav1.TupleKey{User: t.GetUser(), Relation: t.GetRelation(), Object: t.GetObject(), Condition: t.GetCondition()}
+		if renamed.Object == oldRole {
+			renamed.Object = newRole
+		} else {
+			renamed.User = roleEntry(orgID, newUID, zanzana.RelationAssignee)
+		}
+		writes = append(writes, renamed)
+	}
+
+	// New tuples are written before the old ones are removed so the role assignees never lose access
+	if err := batch(writes, 100, func(items []*openfgav1.TupleKey) error {
+		return r.client.Write(ctx, &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: items},
+		})
+	}); err != nil {
+		return err
+	}
+
+	return r.writeDeletes(ctx, withoutCondition(tuples))
+}
+
+// DeleteRoleTuples removes the tuples referencing the role, either as assigned object or as subject of permissions.
+func (r *ZanzanaReconciler) DeleteRoleTuples(ctx context.Context, orgID int64, roleUID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.DeleteRoleTuples")
+	defer span.End()
+
+	return r.deleteTuples(ctx, roleReadRequests(orgID, roleUID))
+}
+
+// EnqueueBasicRoleAssignments adds the users to the outbox using the transaction on ctx when it has one, e.g. the
+// transaction changing their membership of the org. Their basic role assignments in the org are synced with their
+// membership by DispatchOutbox once it is committed.
+func (r *ZanzanaReconciler) EnqueueBasicRoleAssignments(ctx context.Context, orgID int64, userIDs ...int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]*OutboxEntry, 0, len(userIDs))
+	for _, userID := range userIDs {
+		entries = append(entries, &OutboxEntry{
+			OrgID:       orgID,
+			Resource:    outboxBasicRoles,
+			UserID:      userID,
+			NextAttempt: now,
+			Created:     now,
+		})
+	}
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti(entries)
+		return err
+	})
+}
+
+// EnqueueUserBasicRoleAssignments adds the user to the outbox for every org it is a member of, e.g. once its Grafana
+// Admin flag changed, see EnqueueBasicRoleAssignments.
+func (r *ZanzanaReconciler) EnqueueUserBasicRoleAssignments(ctx context.Context, userID int64) error {
+	return r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var orgIDs []int64
+		if err := sess.SQL("SELECT org_id FROM org_user WHERE user_id = ?", userID).Find(&orgIDs); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		entries := make([]*OutboxEntry, 0, len(orgIDs))
+		for _, orgID := range orgIDs {
+			entries = append(entries, &OutboxEntry{
+				OrgID:       orgID,
+				Resource:    outboxBasicRoles,
+				UserID:      userID,
+				NextAttempt: now,
+				Created:     now,
+			})
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		_, err := sess.InsertMulti(entries)
+		return err
+	})
+}
+
+// SyncBasicRoleAssignment replaces the basic role assignments of the user in the org so they match its membership as
+// stored: the basic role of its org role, and the Grafana Admin role for server admins. Assignments are removed when
+// the user isn't a member anymore. Stale assignments are removed in the same write as the new ones are added, the user
+// is never assigned two basic roles.
+func (r *ZanzanaReconciler) SyncBasicRoleAssignment(ctx context.Context, orgID, userID int64) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.SyncBasicRoleAssignment")
+	defer span.End()
+
+	var m struct {
+		UID     string `xorm:"uid"`
+		Role    string `xorm:"role"`
+		IsAdmin bool   `xorm:"is_admin"`
+	}
+	var found bool
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.SQL(
+			"SELECT u.uid, COALESCE(ou.role, '') AS role, u.is_admin FROM "+r.store.GetDialect().Quote("user")+
+				" u LEFT JOIN org_user ou ON ou.user_id = u.id AND ou.org_id = ? WHERE u.id = ?",
+			orgID, userID,
+		).Get(&m)
+		return err
+	})
+	// The tuples of deleted users are removed when they are deprovisioned
+	if err != nil || !found || m.UID == "" {
+		return err
+	}
+
+	subject := zanzana.NewTupleEntry(zanzana.TypeUser, m.UID, "")
+	tuples, err := r.readTuples(ctx, []*openfgav1.ReadRequestTupleKey{
+		{User: subject, Relation: zanzana.RelationAssignee, Object: zanzana.TypeRole + ":"},
+	})
+	if err != nil {
+		return err
+	}
+
+	writes, deletes := basicRoleAssignmentChanges(tuples, orgID, subject, m.Role, m.IsAdmin)
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	req := &openfgav1.WriteRequest{}
+	if len(writes) > 0 {
+		req.Writes = &openfgav1.WriteRequestWrites{TupleKeys: writes}
+	}
+	if len(deletes) > 0 {
+		req.Deletes = &openfgav1.WriteRequestDeletes{TupleKeys: withoutCondition(deletes)}
+	}
+	return r.client.Write(ctx, req)
+}
+
+// writeBasicRoleAssignments syncs the basic role assignments of the users of the outbox entries in the org.
+func (r *ZanzanaReconciler) writeBasicRoleAssignments(ctx context.Context, orgID int64, entries []OutboxEntry) error {
+	synced := make(map[int64]struct{}, len(entries))
+	for _, e := range entries {
+		if _, ok := synced[e.UserID]; ok {
+			continue
+		}
+		synced[e.UserID] = struct{}{}
+		if err := r.SyncBasicRoleAssignment(ctx, orgID, e.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// basicRoleAssignmentChanges returns the tuples to write and the tuples to delete so subject is only assigned the
+// basic role matching role in the org, along with the Grafana Admin role when isGrafanaAdmin is set. A user without
+// role in the org isn't a member and has no basic role there.
+func basicRoleAssignmentChanges(tuples []*openfgav1.TupleKey, orgID int64, subject, role string, isGrafanaAdmin bool) ([]*openfgav1.TupleKey, []*openfgav1.TupleKey) {
+	var wanted []string
+	if basicRole := zanzana.TranslateBasicRole(role); basicRole != "" {
+		wanted = append(wanted, roleEntry(orgID, basicRole, ""))
+	}
+	if isGrafanaAdmin && role != "" {
+		wanted = append(wanted, roleEntry(orgID, zanzana.TranslateBasicRole(zanzana.RoleGrafanaAdmin), ""))
+	}
+
+	found := make(map[string]bool, len(wanted))
+	for _, w := range wanted {
+		found[w] = false
+	}
+
+	prefix := roleEntry(orgID, zanzana.BasicRoleUIDPrefix, "")
+	var deletes []*openfgav1.TupleKey
+	for _, t := range tuples {
+		if t.GetUser() != subject || t.GetRelation() != zanzana.RelationAssignee || !strings.HasPrefix(t.GetObject(), prefix) {
+			continue
+		}
+		if _, ok := found[t.GetObject()]; ok {
+			found[t.GetObject()] = true
+			continue
+		}
+		deletes = append(deletes, t)
+	}
+
+	var writes []*openfgav1.TupleKey
+	for _, w := range wanted {
+		if !found[w] {
+			writes = append(writes, &openfgav1.TupleKey{User: subject, Relation: zanzana.RelationAssignee, Object: w})
+		}
+	}
+	return writes, deletes
+}
+
+// roleTupleCheckPages is the number of pages of tuples checked for references to deleted roles by a reconciliation
+const roleTupleCheckPages = 50
+
+// checkRoleTuples removes the tuples referencing roles that don't exist in the grafana db anymore. Roles have no
+// single object type we can list, so the store is read in windows of roleTupleCheckPages pages: each reconciliation
+// continues where the previous one stopped and the whole store is covered over successive reconciliations. Only the
+// roles referenced by the window and owned by the shard are looked up.
+func (r *ZanzanaReconciler) checkRoleTuples(ctx context.Context, shard orgShard) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.checkRoleTuples")
+	defer span.End()
+
+	// Tuples are read before roles so the tuples of a role created in between are not seen as dead
+	var tuples []*openfgav1.TupleKey
+	token := r.roleTuplesToken
+	for i := 0; i < roleTupleCheckPages; i++ {
+		res, err := r.client.Read(ctx, &openfgav1.ReadRequest{ContinuationToken: token})
+		if err != nil {
+			return err
+		}
+		for _, t := range res.GetTuples() {
+			tuples = append(tuples, t.GetKey())
+		}
+		token = res.GetContinuationToken()
+		if token == "" {
+			break
+		}
+	}
+	r.roleTuplesToken = token
+
+	type roleRef struct {
+		orgID int64
+		uid   string
+	}
+	refs := make(map[string]roleRef)
+	for _, t := range tuples {
+		for _, entry := range []string{t.GetObject(), strings.TrimSuffix(t.GetUser(), "#"+zanzana.RelationAssignee)} {
+			orgID, ok := checkedRoleEntryOrg(entry)
+			if !ok || !shard.owns(orgID) {
+				continue
+			}
+			_, uid, _ := strings.Cut(strings.TrimPrefix(entry, zanzana.TypeRole+":"), "-")
+			refs[entry] = roleRef{orgID: orgID, uid: uid}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	uids := make([]any, 0, len(refs))
+	for _, ref := range refs {
+		uids = append(uids, ref.uid)
+	}
+	existing := make(map[string]struct{}, len(refs))
+	err := batch(uids, 500, func(items []any) error {
+		type role struct {
+			OrgID int64  `xorm:"org_id"`
+			UID   string `xorm:"uid"`
+		}
+		var roles []role
+		err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL("SELECT org_id, uid FROM role WHERE uid IN (?"+strings.Repeat(",?", len(items)-1)+")", items...).Find(&roles)
+		})
+		for _, role := range roles {
+			existing[roleEntry(role.OrgID, role.UID, "")] = struct{}{}
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var dead []*openfgav1.TupleKey
+	for _, t := range tuples {
+		for _, entry := range []string{t.GetObject(), strings.TrimSuffix(t.GetUser(), "#"+zanzana.RelationAssignee)} {
+			if _, ok := refs[entry]; !ok {
+				continue
+			}
+			if _, ok := existing[entry]; !ok {
+				dead = append(dead, t)
+				break
+			}
+		}
+	}
+
+	if len(dead) > 0 {
+		r.log.Info("Removing tuples referencing deleted roles", "count", len(dead))
+	}
+	return r.writeDeletes(ctx, withoutCondition(dead))
+}
+
+// isCheckedRoleEntry returns true for entries of roles stored per org, written as role:<org id>-<role uid>.
+// Basic, fixed and plugin roles are part of the schema or written for every org and are never removed.
+func isCheckedRoleEntry(entry string) bool {
+	_, ok := checkedRoleEntryOrg(entry)
+	return ok
+}
+
+// checkedRoleEntryOrg returns the org of the role entry when it is checked, see isCheckedRoleEntry.
+func checkedRoleEntryOrg(entry string) (int64, bool) {
+	id, ok := strings.CutPrefix(entry, zanzana.TypeRole+":")
+	if !ok || strings.Contains(id, "#") {
+		return 0, false
+	}
+
+	org, uid, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, false
+	}
+	orgID, err := strconv.ParseInt(org, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return orgID, !strings.HasPrefix(uid, zanzana.BasicRoleUIDPrefix) && !strings.HasPrefix(uid, accesscontrol.FixedRoleUIDPrefix) &&
+		!strings.HasPrefix(uid, pluginRoleUIDPrefix)
+}
+
+func roleEntry(orgID int64, roleUID, relation string) string {
+	return zanzana.NewScopedTupleEntry(zanzana.TypeRole, roleUID, relation, strconv.FormatInt(orgID, 10))
+}
+
+func roleReadRequests(orgID int64, roleUID string) []*openfgav1.ReadRequestTupleKey {
+	requests := subjectReadRequests(roleEntry(orgID, roleUID, zanzana.RelationAssignee))
+	return append(requests, &openfgav1.ReadRequestTupleKey{Object: roleEntry(orgID, roleUID, ""), Relation: zanzana.RelationAssignee})
+}
+
+func withoutCondition(tuples []*openfgav1.TupleKey) []*openfgav1.TupleKeyWithoutCondition {
+	keys := make([]*openfgav1.TupleKeyWithoutCondition, 0, len(tuples))
+	for _, t := range tuples {
+		keys = append(keys, &openfgav1.TupleKeyWithoutCondition{User: t.GetUser(), Relation: t.GetRelation(), Object: t.GetObject()})
+	}
+	return keys
+}
@@ -0,0 +1,108 @@
+package dualwrite
+
+import (
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+func TestIsCheckedRoleEntry(t *testing.T) {
+	tests := []struct {
+		entry    string
+		expected bool
+	}{
+		{entry: "role:1-custom", expected: true},
+		{entry: "role:0-extsvc_aB-c_d", expected: true},
+		{entry: "role:1-basic_viewer", expected: false},
+		{entry: "role:1-fixed_dashboards_reader", expected: false},
+		{entry: "role:1-plugins_grafana-oncall-app_reader", expected: false},
+		{entry: "role:fixed_dashboards_creator", expected: false},
+		{entry: "role:1-custom#assignee", expected: false},
+		{entry: "team:1-custom", expected: false},
+		{entry: "role:abc-custom", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isCheckedRoleEntry(tt.entry))
+		})
+	}
+}
+
+func TestBasicRoleAssignmentChanges(t *testing.T) {
+	assignee := func(user, object string) *openfgav1.TupleKey {
+		return &openfgav1.TupleKey{User: user, Relation: zanzana.RelationAssignee, Object: object}
+	}
+
+	tests := []struct {
+		name           string
+		tuples         []*openfgav1.TupleKey
+		role           string
+		isGrafanaAdmin bool
+		writes         []*openfgav1.TupleKey
+		deletes        []*openfgav1.TupleKey
+	}{
+		{
+			name:   "should write the assignment when the user has none",
+			role:   "Viewer",
+			writes: []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer")},
+		},
+		{
+			name:    "should replace the assignment of the previous role",
+			tuples:  []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_admin")},
+			role:    "Viewer",
+			writes:  []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer")},
+			deletes: []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_admin")},
+		},
+		{
+			name:   "should do nothing when the assignment is up to date",
+			tuples: []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer")},
+			role:   "Viewer",
+		},
+		{
+			name: "should keep assignments of other orgs and roles",
+			tuples: []*openfgav1.TupleKey{
+				assignee("user:u1", "role:12-basic_admin"),
+				assignee("user:u1", "role:1-custom"),
+				assignee("user:u1", "role:1-basic_grafana_admin"),
+				assignee("user:u1", "role:1-basic_editor"),
+			},
+			role:           "Editor",
+			isGrafanaAdmin: true,
+		},
+		{
+			name:           "should assign the Grafana Admin role to server admins",
+			tuples:         []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer")},
+			role:           "Viewer",
+			isGrafanaAdmin: true,
+			writes:         []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_grafana_admin")},
+		},
+		{
+			name:    "should remove the Grafana Admin role of other users",
+			tuples:  []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer"), assignee("user:u1", "role:1-basic_grafana_admin")},
+			role:    "Viewer",
+			deletes: []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_grafana_admin")},
+		},
+		{
+			name:           "should remove every basic role of users that are not members",
+			tuples:         []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer"), assignee("user:u1", "role:1-basic_grafana_admin")},
+			isGrafanaAdmin: true,
+			deletes:        []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_viewer"), assignee("user:u1", "role:1-basic_grafana_admin")},
+		},
+		{
+			name:    "should only remove the assignment for unknown roles",
+			tuples:  []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_editor")},
+			role:    "Unknown",
+			deletes: []*openfgav1.TupleKey{assignee("user:u1", "role:1-basic_editor")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writes, deletes := basicRoleAssignmentChanges(tt.tuples, 1, "user:u1", tt.role, tt.isGrafanaAdmin)
+			assert.Equal(t, tt.writes, writes)
+			assert.Equal(t, tt.deletes, deletes)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package dualwrite
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	instancesNamespace        = "zanzana.reconciler.instances"
+	instanceHeartbeatInterval = time.Minute
+	// instanceTTL is how long an instance missing heartbeats keeps its orgs before they are rebalanced
+	instanceTTL = 3 * instanceHeartbeatInterval
+)
+
+// orgSharding splits the reconciliation of orgs between the replicas. Each replica registers itself in the kvstore
+// and reconciles the orgs it owns among the live replicas, replicas joining or leaving rebalance the orgs on the
+// next reconciliation.
+type orgSharding struct {
+	instanceID string
+	kv         *kvstore.NamespacedKVStore
+}
+
+func newOrgSharding(instanceName string, store db.DB) *orgSharding {
+	return &orgSharding{
+		// The instance name is not guaranteed to be unique across replicas
+		instanceID: instanceName + "-" + util.GenerateShortUID(),
+		kv:         kvstore.WithNamespace(kvstore.ProvideService(store), accesscontrol.GlobalOrgID, instancesNamespace),
+	}
+}
+
+func (s *orgSharding) heartbeat(ctx context.Context) error {
+	return s.kv.Set(ctx, s.instanceID, time.Now().UTC().Format(time.RFC3339))
+}
+
+// leave removes the instance from the live instances so its orgs are rebalanced without waiting for the ttl.
+func (s *orgSharding) leave(ctx context.Context) error {
+	return s.kv.Del(ctx, s.instanceID)
+}
+
+// shard returns the shard of the instance among the live instances, instances missing heartbeats are removed.
+func (s *orgSharding) shard(ctx context.Context) (orgShard, error) {
+	if err := s.heartbeat(ctx); err != nil {
+		return orgShard{}, err
+	}
+
+	all, err := s.kv.GetAll(ctx)
+	if err != nil {
+		return orgShard{}, err
+	}
+
+	shard := orgShard{instanceID: s.instanceID}
+	for id, value := range all[accesscontrol.GlobalOrgID] {
+		seen, err := time.Parse(time.RFC3339, value)
+		if err != nil || time.Since(seen) > instanceTTL {
+			if err := s.kv.Del(ctx, id); err != nil {
+				return orgShard{}, err
+			}
+			continue
+		}
+		shard.instances = append(shard.instances, id)
+	}
+	return shard, nil
+}
+
+// orgShard decides which orgs an instance reconciles, the zero value owns all orgs.
+type orgShard struct {
+	instanceID string
+	instances  []string
+}
+
+// owns returns true when the instance has the highest rendezvous hash for the org among the live instances.
+// Unlike a modulo over the instances, an instance joining or leaving only moves the orgs it takes or owned.
+func (s orgShard) owns(orgID int64) bool {
+	if len(s.instances) == 0 {
+		return true
+	}
+
+	var (
+		owner string
+		best  uint64
+	)
+	for _, id := range s.instances {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(id + "/" + strconv.FormatInt(orgID, 10)))
+		if weight := h.Sum64(); owner == "" || weight > best || (weight == best && id < owner) {
+			owner, best = id, weight
+		}
+	}
+	return owner == s.instanceID
+}
@@ -0,0 +1,41 @@
+package dualwrite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgShard_Owns(t *testing.T) {
+	instances := []string{"a", "b", "c"}
+	owners := func(instances []string) map[int64]string {
+		owners := map[int64]string{}
+		for orgID := int64(1); orgID <= 100; orgID++ {
+			for _, id := range instances {
+				if (orgShard{instanceID: id, instances: instances}).owns(orgID) {
+					assert.Empty(t, owners[orgID], "org %d is owned by several instances", orgID)
+					owners[orgID] = id
+				}
+			}
+			assert.NotEmpty(t, owners[orgID], "org %d is not owned", orgID)
+		}
+		return owners
+	}
+
+	before := owners(instances)
+	counts := map[string]int{}
+	for _, id := range before {
+		counts[id]++
+	}
+	assert.Len(t, counts, len(instances))
+
+	// Only the orgs of the instance leaving are moved
+	after := owners([]string{"a", "c"})
+	for orgID, owner := range before {
+		if owner != "b" {
+			assert.Equal(t, owner, after[orgID])
+		}
+	}
+
+	assert.True(t, orgShard{}.owns(1))
+}
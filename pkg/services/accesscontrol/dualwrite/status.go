@@ -0,0 +1,75 @@
+package dualwrite
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// ReconcilerStatus reports the last runs of the sync and the reconciliation of permissions to zanzana.
+type ReconcilerStatus struct {
+	LastSync      time.Time `json:"lastSync"`
+	LastSyncError string    `json:"lastSyncError,omitempty"`
+	// LastReconciliation is when the last reconciliation run by the instance finished
+	LastReconciliation         time.Time     `json:"lastReconciliation"`
+	LastReconciliationDuration time.Duration `json:"lastReconciliationDuration"`
+	Sharded                    bool          `json:"sharded"`
+	// Instances is the number of live instances sharing the reconciliation during the last reconciliation
+	Instances int `json:"instances,omitempty"`
+}
+
+type reconcilerStatus struct {
+	mu     sync.Mutex
+	status ReconcilerStatus
+}
+
+// Status returns the status of the last sync and reconciliation run by the instance.
+func (r *ZanzanaReconciler) Status() ReconcilerStatus {
+	r.status.mu.Lock()
+	defer r.status.mu.Unlock()
+
+	status := r.status.status
+	status.Sharded = r.sharding != nil
+	return status
+}
+
+func (r *ZanzanaReconciler) syncFinished(err error) {
+	r.status.mu.Lock()
+	defer r.status.mu.Unlock()
+
+	r.status.status.LastSync = time.Now()
+	r.status.status.LastSyncError = ""
+	if err != nil {
+		r.status.status.LastSyncError = err.Error()
+	}
+}
+
+func (r *ZanzanaReconciler) reconciliationFinished(started time.Time, shard orgShard) {
+	r.status.mu.Lock()
+	defer r.status.mu.Unlock()
+
+	r.status.status.LastReconciliation = time.Now()
+	r.status.status.LastReconciliationDuration = time.Since(started)
+	r.status.status.Instances = len(shard.instances)
+}
+
+// CountTuples returns the number of tuples in the zanzana store. The tuples are counted in the database of the
+// embedded server, [accesscontrol.ErrTupleCountUnavailable] is returned when zanzana runs as a remote server.
+func (r *ZanzanaReconciler) CountTuples(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.migrator.CountTuples")
+	defer span.End()
+
+	if !r.embeddedStore {
+		return 0, accesscontrol.ErrTupleCountUnavailable
+	}
+
+	var count int64
+	err := r.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT COUNT(*) FROM " + r.store.GetDialect().Quote("tuple")).Get(&count)
+		return err
+	})
+	return count, err
+}
@@ -18,6 +18,8 @@ var (
 	ErrNoneRoleAssignment       = errutil.BadRequest("accesscontrol.noneRoleAssignment", errutil.WithPublicMessage("none role cannot receive permissions"))
 	ErrAssignmentEntityNotFound = errutil.BadRequest("accesscontrol.assignmentEntityNotFound").
 					MustTemplate(assignmentEntityNotFoundMessage, errutil.WithPublic(assignmentEntityNotFoundMessage))
+	ErrQuotaReached = errutil.Forbidden("accesscontrol.quotaReached", errutil.WithPublicMessage("quota reached for roles or role assignments"))
+	ErrReadOnly     = errutil.Conflict("accesscontrol.readOnly", errutil.WithPublicMessage("Permissions are read-only during maintenance"))
 
 	// Note: these are intended to be replaced by equivalent errutil implementations.
 	// Avoid creating new errors with errors.New and prefer errutil
@@ -28,7 +30,10 @@ var (
 	ErrPluginIDRequired       = errors.New("plugin ID is required")
 	ErrRoleNotFound           = errors.New("role not found")
 
-	ErrActionSetValidationFailed = errutil.ValidationFailed("accesscontrol.actionSetInvalid")
+	ErrActionSetValidationFailed           = errutil.ValidationFailed("accesscontrol.actionSetInvalid")
+	ErrInvalidSnapshot                     = errutil.ValidationFailed("accesscontrol.invalidSnapshot")
+	ErrResourcePermissionsValidationFailed = errutil.ValidationFailed("accesscontrol.resourcePermissionsInvalid")
+	ErrTupleCountUnavailable               = errutil.NotImplemented("accesscontrol.tupleCountUnavailable")
 )
 
 func ErrInvalidBuiltinRoleData(builtInRole string) errutil.TemplateData {
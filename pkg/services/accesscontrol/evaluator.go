@@ -47,6 +47,15 @@ func (p permissionEvaluator) Evaluate(permissions map[string][]string) bool {
 		return true
 	}
 
+	// Deny permissions override the granted ones
+	for _, target := range p.Scopes {
+		for _, scope := range permissions[DenyAction(p.Action)] {
+			if match(scope, target) {
+				return false
+			}
+		}
+	}
+
 	for _, target := range p.Scopes {
 		for _, scope := range userScopes {
 			if match(scope, target) {
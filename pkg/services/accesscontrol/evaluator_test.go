@@ -48,6 +48,24 @@ func TestPermission_Evaluate(t *testing.T) {
 				"reports:read": {"reports:9", "reports:10"},
 			},
 		},
+		{
+			desc:      "should evaluate to false when a scope is denied",
+			expected:  false,
+			evaluator: EvalPermission("reports:read", "reports:1", "folders:1"),
+			permissions: map[string][]string{
+				"reports:read":  {"folders:1"},
+				"!reports:read": {"reports:1"},
+			},
+		},
+		{
+			desc:      "should evaluate to true when another scope is denied",
+			expected:  true,
+			evaluator: EvalPermission("reports:read", "reports:1"),
+			permissions: map[string][]string{
+				"reports:read":  {"reports:*"},
+				"!reports:read": {"reports:2"},
+			},
+		},
 	}
 
 	for _, test := range tests {
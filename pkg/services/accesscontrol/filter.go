@@ -42,6 +42,18 @@ func Filter(user identity.Requester, sqlID, prefix string, actions ...string) (S
 		return denyQuery, errors.New("missing permissions")
 	}
 
+	// Deny permissions on any of the actions exclude the ids
+	denied := make(map[any]struct{})
+	for _, a := range actions {
+		ids, hasWildcard := ParseScopes(prefix, user.GetPermissions()[DenyAction(a)])
+		if hasWildcard {
+			return denyQuery, nil
+		}
+		for id := range ids {
+			denied[id] = struct{}{}
+		}
+	}
+
 	wildcards := 0
 	result := make(map[any]int)
 	for _, a := range actions {
@@ -60,11 +72,21 @@ func Filter(user identity.Requester, sqlID, prefix string, actions ...string) (S
 
 	// return early if every action has wildcard scope
 	if wildcards == len(actions) {
-		return allowAllQuery, nil
+		if len(denied) == 0 {
+			return allowAllQuery, nil
+		}
+		ids := make([]any, 0, len(denied))
+		for id := range denied {
+			ids = append(ids, id)
+		}
+		return SQLFilter{" " + sqlID + " NOT IN (?" + strings.Repeat(",?", len(ids)-1) + ")", ids}, nil
 	}
 
 	var ids []any
 	for id, count := range result {
+		if _, ok := denied[id]; ok {
+			continue
+		}
 		// if an id exist for every action include it in the filter
 		if count+wildcards == len(actions) {
 			ids = append(ids, id)
@@ -139,8 +161,14 @@ func UserRolesFilter(orgID, userID int64, teamIDs []int64, roles []string) (stri
 			FROM user_role AS ur
 			WHERE ur.user_id = ?
 			AND (ur.org_id = ? OR ur.org_id = ?)
+			UNION
+			SELECT gr.role_id
+			FROM group_role AS gr
+			INNER JOIN user_external_group AS ueg ON ueg.group_id = gr.group_id
+			WHERE ueg.user_id = ?
+			AND gr.org_id = ?
 		`)
-		params = []any{userID, orgID, GlobalOrgID}
+		params = []any{userID, orgID, GlobalOrgID, userID, orgID}
 	}
 
 	if len(teamIDs) > 0 {
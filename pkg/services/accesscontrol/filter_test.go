@@ -82,6 +82,28 @@ func TestFilter_Datasources(t *testing.T) {
 			},
 			expectedDataSources: []string{"ds:3", "ds:7", "ds:8"},
 		},
+		{
+			desc:    "expect denied data sources to be excluded from wildcard scope",
+			sqlID:   "data_source.id",
+			prefix:  "datasources:id:",
+			actions: []string{"datasources:read"},
+			permissions: map[string][]string{
+				"datasources:read":  {"datasources:*"},
+				"!datasources:read": {"datasources:id:2", "datasources:id:5"},
+			},
+			expectedDataSources: []string{"ds:1", "ds:3", "ds:4", "ds:6", "ds:7", "ds:8", "ds:9", "ds:10"},
+		},
+		{
+			desc:    "expect denied data sources to be excluded",
+			sqlID:   "data_source.id",
+			prefix:  "datasources:id:",
+			actions: []string{"datasources:read"},
+			permissions: map[string][]string{
+				"datasources:read":  {"datasources:id:3", "datasources:id:7", "datasources:id:8"},
+				"!datasources:read": {"datasources:id:7"},
+			},
+			expectedDataSources: []string{"ds:3", "ds:8"},
+		},
 		{
 			desc:    "expect no data sources to be returned for malformed scope",
 			sqlID:   "data_source.id",
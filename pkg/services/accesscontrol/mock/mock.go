@@ -278,6 +278,10 @@ func (m *Mock) ListObjects(ctx context.Context, in accesscontrol.ListObjectsRequ
 	return nil, nil
 }
 
+func (m *Mock) ListUsers(ctx context.Context, in accesscontrol.ListUsersRequest) ([]string, error) {
+	return nil, nil
+}
+
 // WithoutResolvers implements fullAccessControl.
 func (m *Mock) WithoutResolvers() accesscontrol.AccessControl {
 	return m
@@ -53,3 +53,26 @@ func (m *MockPermissionsService) MapActions(permission accesscontrol.ResourcePer
 	mockedArgs := m.Called(permission)
 	return mockedArgs.Get(0).(string)
 }
+
+// DefaultPermissions returns no permission unless the call is expected, tests of the creation of resources usually
+// only expect the permissions to be set.
+func (m *MockPermissionsService) DefaultPermissions(ctx context.Context, orgID int64, attrs accesscontrol.DefaultPermissionsAttributes) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == "DefaultPermissions" {
+			mockedArgs := m.Called(ctx, orgID, attrs)
+			return mockedArgs.Get(0).([]accesscontrol.SetResourcePermissionCommand), mockedArgs.Error(1)
+		}
+	}
+	return nil, nil
+}
+
+// MoveResource prunes no permission unless the call is expected, tests of moving resources usually don't expect it.
+func (m *MockPermissionsService) MoveResource(ctx context.Context, orgID int64, cmd accesscontrol.MoveResourceCommand) ([]accesscontrol.ResourcePermission, error) {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == "MoveResource" {
+			mockedArgs := m.Called(ctx, orgID, cmd)
+			return mockedArgs.Get(0).([]accesscontrol.ResourcePermission), mockedArgs.Error(1)
+		}
+	}
+	return nil, nil
+}
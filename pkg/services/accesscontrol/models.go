@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/slugify"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota"
 )
 
 const (
@@ -18,6 +19,12 @@ const (
 	CacheMiss = "miss"
 )
 
+const (
+	QuotaTargetSrv            quota.TargetSrv = "access_control"
+	QuotaTargetRole           quota.Target    = "role"
+	QuotaTargetRoleAssignment quota.Target    = "role_assignment"
+)
+
 var (
 	ErrInternal        = errutil.Internal("accesscontrol.internal")
 	CacheUsageStatuses = []string{CacheHit, CacheMiss}
@@ -172,6 +179,25 @@ type TeamRole struct {
 	Created time.Time
 }
 
+// GroupRole assigns a role to the members of an external identity provider group.
+type GroupRole struct {
+	ID      int64  `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID   int64  `json:"orgId" xorm:"org_id"`
+	RoleID  int64  `json:"roleId" xorm:"role_id"`
+	GroupID string `json:"groupId" xorm:"group_id"`
+
+	Created time.Time
+}
+
+// UserExternalGroup is the membership of a user to an external identity provider group, it is synced on login.
+type UserExternalGroup struct {
+	ID      int64  `json:"id" xorm:"pk autoincr 'id'"`
+	UserID  int64  `json:"userId" xorm:"user_id"`
+	GroupID string `json:"groupId" xorm:"group_id"`
+
+	Created time.Time
+}
+
 type UserRole struct {
 	ID              int64  `json:"id" xorm:"pk autoincr 'id'"`
 	OrgID           int64  `json:"orgId" xorm:"org_id"`
@@ -202,6 +228,9 @@ type Permission struct {
 	Kind       string `json:"-"`
 	Attribute  string `json:"-"`
 	Identifier string `json:"-"`
+	// Deny excludes the scope from the permissions granted by other roles.
+	// When loaded for evaluation the action of deny permissions is prefixed with DenyActionPrefix.
+	Deny bool `json:"deny,omitempty" xorm:"deny"`
 
 	Updated time.Time `json:"updated"`
 	Created time.Time `json:"created"`
@@ -219,6 +248,24 @@ func (p Permission) SplitScope() (string, string, string) {
 	return SplitScope(p.Scope)
 }
 
+// DenyActionPrefix prefixes the action of deny permissions loaded for evaluation.
+// Consumers unaware of deny permissions never mistake them for a grant of the action.
+const DenyActionPrefix = "!"
+
+// DenyAction returns the action deny permissions on action are evaluated with.
+func DenyAction(action string) string {
+	return DenyActionPrefix + action
+}
+
+// PrefixDenyActions prefixes the action of deny permissions with DenyActionPrefix.
+func PrefixDenyActions(permissions []Permission) {
+	for i := range permissions {
+		if permissions[i].Deny && !strings.HasPrefix(permissions[i].Action, DenyActionPrefix) {
+			permissions[i].Action = DenyAction(permissions[i].Action)
+		}
+	}
+}
+
 type GetUserPermissionsQuery struct {
 	OrgID        int64
 	UserID       int64
@@ -233,6 +280,7 @@ type ResourcePermission struct {
 	ID               int64
 	RoleName         string
 	Actions          []string
+	Deny             bool
 	Scope            string
 	UserId           int64
 	UserLogin        string
@@ -241,11 +289,59 @@ type ResourcePermission struct {
 	TeamEmail        string
 	Team             string
 	BuiltInRole      string
+	GroupID          string
 	IsManaged        bool
 	IsInherited      bool
 	IsServiceAccount bool
 	Created          time.Time
 	Updated          time.Time
+	// ActionSets maps each action set found in Actions to the actions it grants.
+	// It is only populated when expansion of action sets is requested.
+	ActionSets map[string][]string
+	// Permission is the permission the managed permission was stored with, when an action set recorded it. The actions
+	// can't tell it apart from a larger permission granting the same actions, e.g. Edit in the orgs where it shares.
+	Permission string
+	// Expires is set when the permission is a temporary grant, see TemporaryPermission.
+	Expires time.Time
+	// Metadata holds the key/value pairs attached to a managed permission, e.g. the ticket that requested it.
+	Metadata map[string]string
+}
+
+// TemporaryPermission records a managed user permission that is revoked once it expires.
+type TemporaryPermission struct {
+	ID                int64     `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID             int64     `json:"orgId" xorm:"org_id"`
+	Resource          string    `json:"resource"`
+	ResourceAttribute string    `json:"resourceAttribute" xorm:"resource_attribute"`
+	ResourceID        string    `json:"resourceId" xorm:"resource_id"`
+	UserID            int64     `json:"userId" xorm:"user_id"`
+	Permission        string    `json:"permission"`
+	GrantedBy         int64     `json:"grantedBy" xorm:"granted_by"`
+	Expires           time.Time `json:"expires"`
+	Created           time.Time `json:"created"`
+}
+
+func (p TemporaryPermission) Scope() string {
+	return Scope(p.Resource, p.ResourceAttribute, p.ResourceID)
+}
+
+// StableID identifies the permission by its scope and assignee. Unlike ID, which is the id of one of the
+// permission rows, it does not change when the actions of the permission are rewritten.
+func (p *ResourcePermission) StableID() string {
+	var assignee string
+	switch {
+	case p.UserId != 0:
+		assignee = fmt.Sprintf("user:%d", p.UserId)
+	case p.TeamId != 0:
+		assignee = fmt.Sprintf("team:%d", p.TeamId)
+	case p.BuiltInRole != "":
+		assignee = "builtInRole:" + p.BuiltInRole
+	case p.GroupID != "":
+		assignee = "group:" + p.GroupID
+	default:
+		assignee = "role:" + p.RoleName
+	}
+	return p.Scope + "/" + assignee
 }
 
 func (p *ResourcePermission) Contains(targetActions []string) bool {
@@ -271,11 +367,39 @@ func (p *ResourcePermission) Contains(targetActions []string) bool {
 	return true
 }
 
+// SetResourcePermissionCommand sets the permission of a user, a team or a basic role on a resource.
+// One assignee must be set, an empty permission removes the permission of the assignee.
 type SetResourcePermissionCommand struct {
-	UserID      int64  `json:"userId,omitempty"`
-	TeamID      int64  `json:"teamId,omitempty"`
+	// User or service account to set the permission for
+	UserID int64 `json:"userId,omitempty"`
+	// Team to set the permission for
+	TeamID int64 `json:"teamId,omitempty"`
+	// Basic role to set the permission for
 	BuiltinRole string `json:"builtInRole,omitempty"`
-	Permission  string `json:"permission"`
+	// One of the permissions listed by the description endpoint of the resource
+	Permission string `json:"permission"`
+}
+
+// DefaultPermissionsAttributes are the attributes of a newly created resource its default permissions are decided on.
+type DefaultPermissionsAttributes struct {
+	// CreatorID is the id of the user creating the resource, zero when the resource isn't created by a user
+	CreatorID int64
+	// ParentUID is the uid of the folder the resource is created in, empty when the resource doesn't inherit the
+	// permissions of a folder
+	ParentUID string
+	// Provisioned is set for resources created by provisioning, their creator isn't granted permissions
+	Provisioned bool
+}
+
+// MoveResourceCommand describes the move of a resource from one folder to another.
+type MoveResourceCommand struct {
+	ResourceID string
+	// OldParentUID and NewParentUID are the uids of the folders the resource moved from and to, empty for the root
+	OldParentUID string
+	NewParentUID string
+	// PruneRedundant removes the direct permissions of the resource granting nothing more than what their assignees
+	// inherit from the new parent folders
+	PruneRedundant bool
 }
 
 type SaveExternalServiceRoleCommand struct {
@@ -369,6 +493,10 @@ const (
 	ActionOrgUsersRemove = "org.users:remove"
 	ActionOrgUsersWrite  = "org.users:write"
 
+	// Access control snapshot actions
+	ActionRolesSnapshotRead  = "roles.snapshot:read"
+	ActionRolesSnapshotWrite = "roles.snapshot:write"
+
 	// LDAP actions
 	ActionLDAPUsersRead    = "ldap.user:read"
 	ActionLDAPUsersSync    = "ldap.user:sync"
@@ -426,6 +554,10 @@ const (
 	ActionAlertingRuleUpdate = "alert.rules:write"
 	ActionAlertingRuleDelete = "alert.rules:delete"
 
+	// Alerting rule permissions actions
+	ActionAlertingRulePermissionsRead  = "alert.rules.permissions:read"
+	ActionAlertingRulePermissionsWrite = "alert.rules.permissions:write"
+
 	// Alerting instances (+silences) actions
 	ActionAlertingInstanceCreate = "alert.instances:create"
 	ActionAlertingInstanceUpdate = "alert.instances:write"
@@ -602,3 +734,8 @@ type ListObjectsRequest struct {
 	Relation string
 	User     string
 }
+
+type ListUsersRequest struct {
+	Object   string
+	Relation string
+}
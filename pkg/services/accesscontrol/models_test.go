@@ -127,3 +127,28 @@ func TestPermission_ScopeSplit(t *testing.T) {
 		})
 	}
 }
+
+func TestResourcePermission_StableID(t *testing.T) {
+	tests := []struct {
+		desc       string
+		permission ResourcePermission
+		expected   string
+	}{
+		{desc: "user permission", permission: ResourcePermission{ID: 1, UserId: 2, Scope: "dashboards:uid:1"}, expected: "dashboards:uid:1/user:2"},
+		{desc: "team permission", permission: ResourcePermission{ID: 3, TeamId: 2, Scope: "dashboards:uid:1"}, expected: "dashboards:uid:1/team:2"},
+		{desc: "basic role permission", permission: ResourcePermission{ID: 4, BuiltInRole: "Viewer", Scope: "dashboards:uid:1"}, expected: "dashboards:uid:1/builtInRole:Viewer"},
+		{desc: "role permission", permission: ResourcePermission{ID: 5, RoleName: "fixed:dashboards:reader", Scope: "dashboards:*"}, expected: "dashboards:*/role:fixed:dashboards:reader"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.permission.StableID())
+		})
+	}
+
+	t.Run("should not depend on the row id and actions", func(t *testing.T) {
+		a := ResourcePermission{ID: 1, UserId: 2, Scope: "dashboards:uid:1", Actions: []string{"dashboards:read"}}
+		b := ResourcePermission{ID: 7, UserId: 2, Scope: "dashboards:uid:1", Actions: []string{"dashboards:read", "dashboards:write"}}
+		assert.Equal(t, a.StableID(), b.StableID())
+	})
+}
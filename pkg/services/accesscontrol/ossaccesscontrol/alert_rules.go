@@ -0,0 +1,147 @@
+package ossaccesscontrol
+
+import (
+	"context"
+	"slices"
+
+	"github.com/grafana/authlib/claims"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	alertingac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var AlertRuleViewActions = []string{accesscontrol.ActionAlertingRuleRead}
+var AlertRuleEditActions = append(AlertRuleViewActions, []string{accesscontrol.ActionAlertingRuleUpdate, accesscontrol.ActionAlertingRuleDelete}...)
+var AlertRuleAdminActions = append(AlertRuleEditActions, []string{accesscontrol.ActionAlertingRulePermissionsRead, accesscontrol.ActionAlertingRulePermissionsWrite}...)
+
+func ProvideAlertRulePermissionsService(
+	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
+	license licensing.Licensing, folderStore folder.Store, service accesscontrol.Service,
+	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
+) (*AlertRulePermissionsService, error) {
+	getFolderUID := func(ctx context.Context, orgID int64, resourceID string) (string, error) {
+		var folderUID string
+		err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+			found, err := sess.SQL("SELECT namespace_uid FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, resourceID).Get(&folderUID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return ngmodels.ErrAlertRuleNotFound
+			}
+			return nil
+		})
+		return folderUID, err
+	}
+
+	options := resourcepermissions.Options{
+		Resource:          alertingac.ScopeRulesRoot,
+		ResourceAttribute: "uid",
+		// Permissions on every folder include the alert rules they contain
+		InheritedWildcards: accesscontrol.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix),
+		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
+			ctx, span := tracer.Start(ctx, "accesscontrol.ossaccesscontrol.ProvideAlertRulePermissionsService.ResourceValidator")
+			defer span.End()
+
+			_, err := getFolderUID(ctx, orgID, resourceID)
+			return err
+		},
+		InheritedScopesSolver: func(ctx context.Context, orgID int64, resourceID string) ([]string, error) {
+			folderUID, err := getFolderUID(ctx, orgID, resourceID)
+			if err != nil {
+				return nil, err
+			}
+
+			scopes := []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID)}
+			nestedScopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, folderStore)
+			if err != nil {
+				return nil, err
+			}
+			return append(scopes, nestedScopes...), nil
+		},
+		Assignments: resourcepermissions.Assignments{
+			Users:           true,
+			Teams:           true,
+			BuiltInRoles:    true,
+			ServiceAccounts: true,
+		},
+		AllowGrafanaAdmin: true,
+		PermissionsToActions: map[string][]string{
+			string(alertingac.RulePermissionView):  append([]string{}, AlertRuleViewActions...),
+			string(alertingac.RulePermissionEdit):  append([]string{}, AlertRuleEditActions...),
+			string(alertingac.RulePermissionAdmin): append([]string{}, AlertRuleAdminActions...),
+		},
+		ReaderRoleName: "Alerting rule permission reader",
+		WriterRoleName: "Alerting rule permission writer",
+		RoleGroup:      ngalert.AlertRolesGroup,
+	}
+
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertRulePermissionsService{Service: srv, ac: service, log: log.New("resourcepermissions.alertrules")}, nil
+}
+
+var _ accesscontrol.AlertRulePermissionsService = new(AlertRulePermissionsService)
+
+type AlertRulePermissionsService struct {
+	*resourcepermissions.Service
+	ac  accesscontrol.Service
+	log log.Logger
+}
+
+// SetDefaultPermissions sets the default permissions for a newly created alert rule.
+func (r AlertRulePermissionsService) SetDefaultPermissions(ctx context.Context, orgID int64, user identity.Requester, uid string) {
+	r.log.Debug("Setting default permissions for alert rule", "rule_uid", uid)
+	var attrs accesscontrol.DefaultPermissionsAttributes
+	clearCache := false
+	if user != nil && user.IsIdentityType(claims.TypeUser) {
+		userID, err := user.GetInternalID()
+		if err != nil {
+			r.log.Error("Could not make user admin", "rule_uid", uid, "id", user.GetID(), "error", err)
+		} else {
+			attrs.CreatorID = userID
+			clearCache = true
+		}
+	}
+
+	permissions, err := r.DefaultPermissions(ctx, orgID, attrs)
+	if err != nil {
+		r.log.Error("Could not get default permissions", "rule_uid", uid, "error", err)
+		return
+	}
+	// Basic roles are granted access to the rule through its folder, seeding their permissions would add rows for
+	// every rule created
+	permissions = slices.DeleteFunc(permissions, func(p accesscontrol.SetResourcePermissionCommand) bool {
+		return p.BuiltinRole != ""
+	})
+	if len(permissions) == 0 {
+		return
+	}
+
+	if _, err := r.SetPermissions(ctx, orgID, uid, permissions...); err != nil {
+		r.log.Error("Could not set default permissions", "rule_uid", uid, "error", err)
+	}
+
+	if clearCache {
+		// Clear permission cache for the user who created the alert rule, so that new permissions are fetched for their next call
+		r.ac.ClearUserPermissionCache(user)
+	}
+}
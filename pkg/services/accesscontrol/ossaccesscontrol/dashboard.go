@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
@@ -95,6 +96,7 @@ func ProvideDashboardPermissions(
 	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
 	license licensing.Licensing, dashboardStore dashboards.Store, folderStore folder.Store, service accesscontrol.Service,
 	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
 ) (*DashboardPermissionsService, error) {
 	getDashboard := func(ctx context.Context, orgID int64, resourceID string) (*dashboards.Dashboard, error) {
 		query := &dashboards.GetDashboardQuery{UID: resourceID, OrgID: orgID}
@@ -112,6 +114,8 @@ func ProvideDashboardPermissions(
 	options := resourcepermissions.Options{
 		Resource:          "dashboards",
 		ResourceAttribute: "uid",
+		// Permissions on every folder include the dashboards they contain
+		InheritedWildcards: accesscontrol.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix),
 		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
 			ctx, span := tracer.Start(ctx, "accesscontrol.ossaccesscontrol.ProvideDashboardPermissions.ResourceValidator")
 			defer span.End()
@@ -128,8 +132,7 @@ func ProvideDashboardPermissions(
 			return nil
 		},
 		InheritedScopesSolver: func(ctx context.Context, orgID int64, resourceID string) ([]string, error) {
-			wildcards := accesscontrol.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix)
-			scopes := []string(wildcards)
+			var scopes []string
 
 			dashboard, err := getDashboard(ctx, orgID, resourceID)
 			if err != nil {
@@ -161,18 +164,21 @@ func ProvideDashboardPermissions(
 			Teams:           true,
 			BuiltInRoles:    true,
 			ServiceAccounts: true,
+			Groups:          true,
 		},
+		AllowGrafanaAdmin: true,
 		PermissionsToActions: map[string][]string{
 			"View":  getDashboardViewActions(features),
 			"Edit":  getDashboardEditActions(features),
 			"Admin": getDashboardAdminActions(features),
 		},
+		AllowDeny:      true,
 		ReaderRoleName: "Dashboard permission reader",
 		WriterRoleName: "Dashboard permission writer",
 		RoleGroup:      "Dashboards",
 	}
 
-	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService)
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
 	if err != nil {
 		return nil, err
 	}
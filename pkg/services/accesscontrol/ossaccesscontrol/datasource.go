@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -22,16 +23,23 @@ var DatasourceQueryActions = []string{
 	datasources.ActionQuery,
 }
 
-func ProvideDatasourcePermissionsService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, db db.DB) *DatasourcePermissionsService {
-	return &DatasourcePermissionsService{
-		store: resourcepermissions.NewStore(cfg, db, features),
+func ProvideDatasourcePermissionsService(
+	cfg *setting.Cfg, features featuremgmt.FeatureToggles, db db.DB, defaultPolicy *resourcepermissions.DefaultPermissionsPolicy,
+) (*DatasourcePermissionsService, error) {
+	if defaultPolicy.GrantsCreatorTeams(datasources.ScopeRoot) {
+		log.New("resourcepermissions.datasources").Warn("The default permissions policy grants data source permissions to the teams of the creator, these grants are ignored in OSS")
 	}
+	return &DatasourcePermissionsService{
+		store:         resourcepermissions.NewStore(cfg, db, features),
+		defaultPolicy: defaultPolicy,
+	}, nil
 }
 
 var _ accesscontrol.DatasourcePermissionsService = new(DatasourcePermissionsService)
 
 type DatasourcePermissionsService struct {
-	store resourcepermissions.Store
+	store         resourcepermissions.Store
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy
 }
 
 func (e DatasourcePermissionsService) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
@@ -94,3 +102,15 @@ func (e DatasourcePermissionsService) DeleteResourcePermissions(ctx context.Cont
 func (e DatasourcePermissionsService) MapActions(permission accesscontrol.ResourcePermission) string {
 	return ""
 }
+
+// DefaultPermissions returns the permissions the default permissions policy grants on a new data source, only the
+// Query permission of basic roles is set in OSS. The teams of the creator aren't resolved, see
+// ProvideDatasourcePermissionsService.
+func (e DatasourcePermissionsService) DefaultPermissions(ctx context.Context, orgID int64, attrs accesscontrol.DefaultPermissionsAttributes) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	return e.defaultPolicy.Commands(ctx, orgID, datasources.ScopeRoot, attrs, nil)
+}
+
+// MoveResource does nothing, data sources aren't stored in folders.
+func (e DatasourcePermissionsService) MoveResource(ctx context.Context, orgID int64, cmd accesscontrol.MoveResourceCommand) ([]accesscontrol.ResourcePermission, error) {
+	return nil, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -86,6 +87,7 @@ func ProvideFolderPermissions(
 	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, accesscontrol accesscontrol.AccessControl,
 	license licensing.Licensing, dashboardStore dashboards.Store, folderStore folder.Store, service accesscontrol.Service,
 	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
 ) (*FolderPermissionsService, error) {
 	if err := registerFolderRoles(cfg, features, service); err != nil {
 		return nil, err
@@ -118,7 +120,9 @@ func ProvideFolderPermissions(
 			Teams:           true,
 			BuiltInRoles:    true,
 			ServiceAccounts: true,
+			Groups:          true,
 		},
+		AllowGrafanaAdmin: true,
 		PermissionsToActions: map[string][]string{
 			"View":  append(getDashboardViewActions(features), FolderViewActions...),
 			"Edit":  append(getDashboardEditActions(features), FolderEditActions...),
@@ -128,7 +132,7 @@ func ProvideFolderPermissions(
 		WriterRoleName: "Folder permission writer",
 		RoleGroup:      "Folders",
 	}
-	srv, err := resourcepermissions.New(cfg, options, features, router, license, accesscontrol, service, sql, teamService, userService, actionSetService)
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, accesscontrol, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
 	if err != nil {
 		return nil, err
 	}
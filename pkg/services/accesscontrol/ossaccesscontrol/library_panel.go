@@ -0,0 +1,106 @@
+package ossaccesscontrol
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type LibraryPanelPermissionsService struct {
+	*resourcepermissions.Service
+}
+
+var LibraryPanelViewActions = []string{libraryelements.ActionLibraryPanelsRead}
+var LibraryPanelEditActions = append(LibraryPanelViewActions, []string{libraryelements.ActionLibraryPanelsWrite, libraryelements.ActionLibraryPanelsDelete}...)
+var LibraryPanelAdminActions = append(LibraryPanelEditActions, []string{libraryelements.ActionLibraryPanelsPermissionsRead, libraryelements.ActionLibraryPanelsPermissionsWrite}...)
+
+func ProvideLibraryPanelPermissions(
+	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
+	license licensing.Licensing, folderStore folder.Store, service accesscontrol.Service,
+	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
+) (*LibraryPanelPermissionsService, error) {
+	// The library elements service can't be used, it sets the permissions of the library panels it creates
+	getFolderUID := func(ctx context.Context, orgID int64, resourceID string) (string, error) {
+		var folderUID string
+		err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+			found, err := sess.SQL("SELECT COALESCE(folder_uid, '') FROM library_element WHERE org_id = ? AND uid = ?", orgID, resourceID).Get(&folderUID)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return model.ErrLibraryElementNotFound
+			}
+			return nil
+		})
+		return folderUID, err
+	}
+
+	options := resourcepermissions.Options{
+		Resource:          libraryelements.ScopeLibraryPanelsRoot,
+		ResourceAttribute: "uid",
+		// Permissions on every folder include the library panels they contain
+		InheritedWildcards: accesscontrol.WildcardsFromPrefix(dashboards.ScopeFoldersPrefix),
+		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
+			ctx, span := tracer.Start(ctx, "accesscontrol.ossaccesscontrol.ProvideLibraryPanelPermissions.ResourceValidator")
+			defer span.End()
+
+			_, err := getFolderUID(ctx, orgID, resourceID)
+			return err
+		},
+		InheritedScopesSolver: func(ctx context.Context, orgID int64, resourceID string) ([]string, error) {
+			folderUID, err := getFolderUID(ctx, orgID, resourceID)
+			if err != nil {
+				return nil, err
+			}
+			if folderUID == "" {
+				folderUID = folder.GeneralFolderUID
+			}
+
+			scopes := []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID)}
+			if folderUID == folder.GeneralFolderUID {
+				return scopes, nil
+			}
+
+			nestedScopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, folderStore)
+			if err != nil {
+				return nil, err
+			}
+			return append(scopes, nestedScopes...), nil
+		},
+		Assignments: resourcepermissions.Assignments{
+			Users:           true,
+			Teams:           true,
+			BuiltInRoles:    true,
+			ServiceAccounts: true,
+		},
+		AllowGrafanaAdmin: true,
+		PermissionsToActions: map[string][]string{
+			"View":  append([]string{}, LibraryPanelViewActions...),
+			"Edit":  append([]string{}, LibraryPanelEditActions...),
+			"Admin": append([]string{}, LibraryPanelAdminActions...),
+		},
+		ReaderRoleName: "Library panel permission reader",
+		WriterRoleName: "Library panel permission writer",
+		RoleGroup:      "Library panels",
+	}
+
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
+	if err != nil {
+		return nil, err
+	}
+	return &LibraryPanelPermissionsService{srv}, nil
+}
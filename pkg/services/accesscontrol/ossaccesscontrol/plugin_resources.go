@@ -0,0 +1,167 @@
+package ossaccesscontrol
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/pluginutils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Permissions of plugin resources managed with the action sets declared by the plugin, from the lowest level of access
+var pluginResourcePermissions = []string{"View", "Edit", "Admin"}
+
+var _ pluginaccesscontrol.ResourcePermissionsRegistry = new(PluginResourcePermissionsService)
+
+func ProvidePluginResourcePermissions(
+	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
+	license licensing.Licensing, service accesscontrol.Service,
+	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
+) *PluginResourcePermissionsService {
+	return &PluginResourcePermissionsService{
+		cfg:              cfg,
+		features:         features,
+		router:           router,
+		sql:              sql,
+		ac:               ac,
+		license:          license,
+		service:          service,
+		teamService:      teamService,
+		userService:      userService,
+		actionSetService: actionSetService,
+		defaultPolicy:    defaultPolicy,
+		kv:               kv,
+		services:         map[string]*resourcepermissions.Service{},
+		log:              log.New("resourcepermissions.plugins"),
+	}
+}
+
+// PluginResourcePermissionsService manages the permissions of the resources registered by app plugins. Each resource
+// gets the standard resource permissions endpoints (/api/access-control/<resource>/...) and its permissions are
+// mapped to the plugin_resource type of the Zanzana schema.
+type PluginResourcePermissionsService struct {
+	cfg              *setting.Cfg
+	features         featuremgmt.FeatureToggles
+	router           routing.RouteRegister
+	sql              db.DB
+	ac               accesscontrol.AccessControl
+	license          licensing.Licensing
+	service          accesscontrol.Service
+	teamService      team.Service
+	userService      user.Service
+	actionSetService resourcepermissions.ActionSetService
+	defaultPolicy    *resourcepermissions.DefaultPermissionsPolicy
+	kv               kvstore.KVStore
+	log              log.Logger
+
+	mu       sync.RWMutex
+	services map[string]*resourcepermissions.Service
+}
+
+// RegisterResourcePermissions registers the resources of the plugin with managed permissions.
+// Resources are registered while the plugins are loaded, a resource registered once Grafana is serving requests
+// gets its endpoints on the next restart.
+func (p *PluginResourcePermissionsService) RegisterResourcePermissions(ctx context.Context, pluginID string, registrations []plugins.ResourcePermissionRegistration) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.ossaccesscontrol.RegisterResourcePermissions")
+	defer span.End()
+
+	if !p.features.IsEnabled(ctx, featuremgmt.FlagAccessControlOnCall) {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, reg := range registrations {
+		if err := pluginutils.ValidatePluginResourcePermissions(pluginID, reg); err != nil {
+			return err
+		}
+		// The resource is already registered when the plugin is reloaded, its endpoints can't be registered twice
+		if _, ok := p.services[reg.Resource]; ok {
+			continue
+		}
+
+		permissionsToActions := reg.Permissions
+		if len(permissionsToActions) == 0 {
+			permissionsToActions = resourcepermissions.PermissionsToActionsFromActionSets(p.actionSetService, reg.Resource, pluginResourcePermissions...)
+		}
+		if len(permissionsToActions) == 0 {
+			return accesscontrol.ErrResourcePermissionsValidationFailed.Errorf("resource %s has neither permissions nor action sets", reg.Resource)
+		}
+
+		resourceAttribute := reg.ResourceAttribute
+		if resourceAttribute == "" {
+			resourceAttribute = "uid"
+		}
+
+		srv, err := resourcepermissions.New(p.cfg, resourcepermissions.Options{
+			Resource:          reg.Resource,
+			ResourceAttribute: resourceAttribute,
+			Assignments: resourcepermissions.Assignments{
+				Users:           reg.Assignments.Users,
+				Teams:           reg.Assignments.Teams,
+				ServiceAccounts: reg.Assignments.ServiceAccounts,
+				BuiltInRoles:    reg.Assignments.BuiltInRoles,
+			},
+			PermissionsToActions: permissionsToActions,
+			ReaderRoleName:       fmt.Sprintf("%s permission reader", reg.Resource),
+			WriterRoleName:       fmt.Sprintf("%s permission writer", reg.Resource),
+			RoleGroup:            pluginID,
+		}, p.features, p.router, p.license, p.ac, p.service, p.sql, p.teamService, p.userService, p.actionSetService, p.defaultPolicy, p.kv)
+		if err != nil {
+			return err
+		}
+
+		if err := zanzana.RegisterPluginResourceKind(reg.Resource, zanzanaRelations(permissionsToActions)); err != nil {
+			return err
+		}
+
+		p.services[reg.Resource] = srv
+	}
+
+	return nil
+}
+
+// GetResourcePermissionsService returns the service managing the permissions of the plugin resource.
+func (p *PluginResourcePermissionsService) GetResourcePermissionsService(resource string) (*resourcepermissions.Service, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	srv, ok := p.services[resource]
+	return srv, ok
+}
+
+// zanzanaRelations maps each action to the relation of the lowest permission granting it. Actions granted by other
+// permissions than View, Edit and Admin have no relation in the plugin_resource type.
+func zanzanaRelations(permissionsToActions map[string][]string) map[string]string {
+	relations := map[string]string{}
+	for _, permission := range pluginResourcePermissions {
+		for name, actions := range permissionsToActions {
+			if !strings.EqualFold(name, permission) {
+				continue
+			}
+			for _, action := range actions {
+				if _, ok := relations[action]; !ok {
+					relations[action] = strings.ToLower(permission)
+				}
+			}
+		}
+	}
+	return relations
+}
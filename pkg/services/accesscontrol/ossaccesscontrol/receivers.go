@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
@@ -26,18 +27,11 @@ var ReceiversViewActions = []string{accesscontrol.ActionAlertingReceiversRead}
 var ReceiversEditActions = append(ReceiversViewActions, []string{accesscontrol.ActionAlertingReceiversUpdate, accesscontrol.ActionAlertingReceiversDelete}...)
 var ReceiversAdminActions = append(ReceiversEditActions, []string{accesscontrol.ActionAlertingReceiversReadSecrets, accesscontrol.ActionAlertingReceiversPermissionsRead, accesscontrol.ActionAlertingReceiversPermissionsWrite}...)
 
-// defaultPermissions returns the default permissions for a newly created receiver.
-func defaultPermissions() []accesscontrol.SetResourcePermissionCommand {
-	return []accesscontrol.SetResourcePermissionCommand{
-		{BuiltinRole: string(org.RoleEditor), Permission: string(alertingac.ReceiverPermissionEdit)},
-		{BuiltinRole: string(org.RoleViewer), Permission: string(alertingac.ReceiverPermissionView)},
-	}
-}
-
 func ProvideReceiverPermissionsService(
 	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB, ac accesscontrol.AccessControl,
 	license licensing.Licensing, service accesscontrol.Service,
 	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
 ) (*ReceiverPermissionsService, error) {
 	options := resourcepermissions.Options{
 		Resource:          "receivers",
@@ -48,6 +42,7 @@ func ProvideReceiverPermissionsService(
 			BuiltInRoles:    true,
 			ServiceAccounts: true,
 		},
+		AllowGrafanaAdmin: true,
 		PermissionsToActions: map[string][]string{
 			string(alertingac.ReceiverPermissionView):  append([]string{}, ReceiversViewActions...),
 			string(alertingac.ReceiverPermissionEdit):  append([]string{}, ReceiversEditActions...),
@@ -58,7 +53,7 @@ func ProvideReceiverPermissionsService(
 		RoleGroup:      ngalert.AlertRolesGroup,
 	}
 
-	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService)
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
 	if err != nil {
 		return nil, err
 	}
@@ -76,20 +71,24 @@ type ReceiverPermissionsService struct {
 // SetDefaultPermissions sets the default permissions for a newly created receiver.
 func (r ReceiverPermissionsService) SetDefaultPermissions(ctx context.Context, orgID int64, user identity.Requester, uid string) {
 	r.log.Debug("Setting default permissions for receiver", "receiver_uid", uid)
-	permissions := defaultPermissions()
+	var attrs accesscontrol.DefaultPermissionsAttributes
 	clearCache := false
 	if user != nil && user.IsIdentityType(claims.TypeUser) {
 		userID, err := user.GetInternalID()
 		if err != nil {
 			r.log.Error("Could not make user admin", "receiver_uid", uid, "id", user.GetID(), "error", err)
 		} else {
-			permissions = append(permissions, accesscontrol.SetResourcePermissionCommand{
-				UserID: userID, Permission: string(alertingac.ReceiverPermissionAdmin),
-			})
+			attrs.CreatorID = userID
 			clearCache = true
 		}
 	}
 
+	permissions, err := r.DefaultPermissions(ctx, orgID, attrs)
+	if err != nil {
+		r.log.Error("Could not get default permissions", "receiver_uid", uid, "error", err)
+		return
+	}
+
 	if _, err := r.SetPermissions(ctx, orgID, uid, permissions...); err != nil {
 		r.log.Error("Could not set default permissions", "receiver_uid", uid, "error", err)
 	}
@@ -130,20 +129,41 @@ func (r ReceiverPermissionsService) CopyPermissions(ctx context.Context, orgID i
 		return 0, err
 	}
 
-	// Clear permission cache for the user who updated the receiver, so that new permissions are fetched for their next call
-	// Required for cases when caller wants to immediately interact with the newly updated object
-	if user != nil && user.IsIdentityType(claims.TypeUser) {
-		// A more comprehensive means of clearing the user's permissions cache than ClearUserPermissionCache.
-		// It also clears the cache for basic roles and teams, which is required for the user to not have temporarily
-		// broken UI permissions when their source of elevated permissions comes from a cached team or basic role
-		// permission.
-		_, err = r.ac.GetUserPermissions(ctx, user, accesscontrol.Options{ReloadCache: true})
-		if err != nil {
-			r.log.Debug("Failed to clear user permissions cache", "error", err)
-		}
+	r.reloadUserPermissions(ctx, user)
+
+	// The permissions of a receiver created outside of a user request are not custom permissions
+	defaults, err := r.DefaultPermissions(ctx, orgID, accesscontrol.DefaultPermissionsAttributes{})
+	if err != nil {
+		return 0, err
+	}
+	return countCustomPermissions(defaults, setPermissionCommands), nil
+}
+
+// RenamePermissions moves the permissions of a renamed receiver from its old uid to its new one.
+func (r ReceiverPermissionsService) RenamePermissions(ctx context.Context, orgID int64, user identity.Requester, oldUID, newUID string) error {
+	r.log.Debug("Moving permissions of renamed receiver", "old_uid", oldUID, "new_uid", newUID)
+	if err := r.RenameResourcePermissions(ctx, orgID, oldUID, newUID); err != nil {
+		return err
 	}
 
-	return countCustomPermissions(setPermissionCommands), nil
+	r.reloadUserPermissions(ctx, user)
+	return nil
+}
+
+// reloadUserPermissions clears the permission cache of the user who updated the receiver, so that new permissions are
+// fetched for their next call. Required for cases when caller wants to immediately interact with the updated object.
+func (r ReceiverPermissionsService) reloadUserPermissions(ctx context.Context, user identity.Requester) {
+	if user == nil || !user.IsIdentityType(claims.TypeUser) {
+		return
+	}
+
+	// A more comprehensive means of clearing the user's permissions cache than ClearUserPermissionCache.
+	// It also clears the cache for basic roles and teams, which is required for the user to not have temporarily
+	// broken UI permissions when their source of elevated permissions comes from a cached team or basic role
+	// permission.
+	if _, err := r.ac.GetUserPermissions(ctx, user, accesscontrol.Options{ReloadCache: true}); err != nil {
+		r.log.Debug("Failed to clear user permissions cache", "error", err)
+	}
 }
 
 // toSetResourcePermissionCommands converts a list of resource permissions to a list of set resource permission commands.
@@ -169,8 +189,8 @@ func (r ReceiverPermissionsService) toSetResourcePermissionCommands(permissions
 }
 
 // countCustomPermissions counts the number of custom permissions in a list of set resource permission commands. A
-// custom permission is a permission that is not a default permission for a receiver.
-func countCustomPermissions(permissions []accesscontrol.SetResourcePermissionCommand) int {
+// custom permission is a permission that is not one of the default permissions of a receiver.
+func countCustomPermissions(defaults, permissions []accesscontrol.SetResourcePermissionCommand) int {
 	cacheKey := func(p accesscontrol.SetResourcePermissionCommand) accesscontrol.SetResourcePermissionCommand {
 		return accesscontrol.SetResourcePermissionCommand{
 			Permission:  "",
@@ -179,8 +199,8 @@ func countCustomPermissions(permissions []accesscontrol.SetResourcePermissionCom
 			UserID:      p.UserID,
 		}
 	}
-	missingDefaults := make(map[accesscontrol.SetResourcePermissionCommand]string, 2)
-	for _, p := range defaultPermissions() {
+	missingDefaults := make(map[accesscontrol.SetResourcePermissionCommand]string, len(defaults))
+	for _, p := range defaults {
 		missingDefaults[cacheKey(p)] = p.Permission
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -39,6 +40,7 @@ func ProvideTeamPermissions(
 	cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister, sql db.DB,
 	ac accesscontrol.AccessControl, license licensing.Licensing, service accesscontrol.Service,
 	teamService team.Service, userService user.Service, actionSetService resourcepermissions.ActionSetService,
+	defaultPolicy *resourcepermissions.DefaultPermissionsPolicy, kv kvstore.KVStore,
 ) (*TeamPermissionsService, error) {
 	options := resourcepermissions.Options{
 		Resource:          "teams",
@@ -95,9 +97,16 @@ func ProvideTeamPermissions(
 				return fmt.Errorf("invalid team permission type %s", permission)
 			}
 		},
+		PostCommitHooks: resourcepermissions.PostCommitHooks{
+			// The permissions granted to the team apply to the member once the membership is committed
+			User: func(ctx context.Context, orgID int64, u accesscontrol.User, resourceID, permission string) error {
+				service.ClearUserPermissionCache(&user.SignedInUser{UserID: u.ID, OrgID: orgID})
+				return nil
+			},
+		},
 	}
 
-	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService)
+	srv, err := resourcepermissions.New(cfg, options, features, router, license, ac, service, sql, teamService, userService, actionSetService, defaultPolicy, kv)
 	if err != nil {
 		return nil, err
 	}
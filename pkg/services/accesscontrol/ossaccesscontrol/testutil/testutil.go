@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
@@ -66,6 +67,11 @@ func ProvideFolderPermissions(
 		return nil, err
 	}
 
+	defaultPolicy, err := resourcepermissions.ProvideDefaultPermissionsPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return ossaccesscontrol.ProvideFolderPermissions(
 		cfg,
 		features,
@@ -79,5 +85,7 @@ func ProvideFolderPermissions(
 		teamSvc,
 		userSvc,
 		actionSets,
+		defaultPolicy,
+		kvstore.ProvideService(sqlStore),
 	)
 }
@@ -57,10 +57,11 @@ func ValidatePluginAction(pluginID, action string) error {
 
 // ValidatePluginActionSet errors when a actionset does not match expected pattern for plugins
 // - action set should be one of the allow-listed action sets (currently only folder action sets are supported for plugins)
+// or an action set of the plugin resources, prefixed with the pluginID (e.g. "test-app.projects:edit")
 // - actions should have the pluginID prefix
 func ValidatePluginActionSet(pluginID string, actionSet plugins.ActionSet) error {
-	if !slices.Contains(allowedActionSets, actionSet.Action) {
-		return ac.ErrActionSetValidationFailed.Errorf("currently only folder and dashboard action sets are supported, provided action set %s is not a folder or dashboard action set", actionSet.Action)
+	if !slices.Contains(allowedActionSets, actionSet.Action) && !IsPluginActionSet(pluginID, actionSet.Action) {
+		return ac.ErrActionSetValidationFailed.Errorf("plugins can only extend folder action sets or declare action sets prefixed with their id, provided action set %s is neither", actionSet.Action)
 	}
 
 	// verify that actions have the pluginID prefix, plugins are only allowed to register actions for the plugin
@@ -73,6 +74,32 @@ func ValidatePluginActionSet(pluginID string, actionSet plugins.ActionSet) error
 	return nil
 }
 
+// IsPluginActionSet returns true when the action set is declared for a resource of the plugin,
+// its name is written as "<pluginID>.<resource>:<level>".
+func IsPluginActionSet(pluginID, actionSet string) bool {
+	resource, level, ok := strings.Cut(actionSet, ":")
+	return ok && level != "" && strings.HasPrefix(resource, pluginID+".") && len(resource) > len(pluginID)+1
+}
+
+// ValidatePluginResourcePermissions errors when a plugin resource with managed permissions does not match expected pattern
+// - resource should be prefixed with the pluginID (e.g. "test-app.projects")
+// - actions of the permissions should have the pluginID prefix
+func ValidatePluginResourcePermissions(pluginID string, reg plugins.ResourcePermissionRegistration) error {
+	if !strings.HasPrefix(reg.Resource, pluginID+".") || len(reg.Resource) == len(pluginID)+1 || strings.Contains(reg.Resource, ":") {
+		return ac.ErrResourcePermissionsValidationFailed.Errorf("plugins can only manage the permissions of resources prefixed with their id, provided resource %s is not", reg.Resource)
+	}
+
+	for _, actions := range reg.Permissions {
+		for _, action := range actions {
+			if err := ValidatePluginAction(pluginID, action); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // ValidatePluginRole errors when a plugin role does not match expected pattern
 // or doesn't have permissions matching the expected pattern.
 func ValidatePluginRole(pluginID string, role ac.RoleDTO) error {
@@ -184,3 +184,102 @@ func TestValidatePluginRole(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePluginActionSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		actionSet plugins.ActionSet
+		wantErr   bool
+	}{
+		{
+			name:      "extends a folder action set",
+			actionSet: plugins.ActionSet{Action: "folders:view", Actions: []string{"test-app.projects:read"}},
+		},
+		{
+			name:      "declares an action set of a plugin resource",
+			actionSet: plugins.ActionSet{Action: "test-app.projects:edit", Actions: []string{"test-app.projects:view", "test-app.projects:write"}},
+		},
+		{
+			name:      "declares an action set of another plugin",
+			actionSet: plugins.ActionSet{Action: "other-app.projects:edit", Actions: []string{"test-app.projects:write"}},
+			wantErr:   true,
+		},
+		{
+			name:      "declares an action set without resource",
+			actionSet: plugins.ActionSet{Action: "test-app.:edit", Actions: []string{"test-app.projects:write"}},
+			wantErr:   true,
+		},
+		{
+			name:      "extends a core action set",
+			actionSet: plugins.ActionSet{Action: "dashboards:edit", Actions: []string{"test-app.projects:write"}},
+			wantErr:   true,
+		},
+		{
+			name:      "includes a core action",
+			actionSet: plugins.ActionSet{Action: "test-app.projects:edit", Actions: []string{"users:read"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePluginActionSet("test-app", tt.actionSet)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidatePluginResourcePermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		reg     plugins.ResourcePermissionRegistration
+		wantErr bool
+	}{
+		{
+			name: "resource of the plugin",
+			reg: plugins.ResourcePermissionRegistration{Resource: "test-app.projects", Permissions: map[string][]string{
+				"View": {"test-app.projects:read"},
+				"Edit": {"test-app.projects:read", "test-app.projects:write"},
+			}},
+		},
+		{
+			name: "resource of the plugin using its action sets",
+			reg:  plugins.ResourcePermissionRegistration{Resource: "test-app.projects"},
+		},
+		{
+			name:    "resource of another plugin",
+			reg:     plugins.ResourcePermissionRegistration{Resource: "other-app.projects"},
+			wantErr: true,
+		},
+		{
+			name:    "resource without name",
+			reg:     plugins.ResourcePermissionRegistration{Resource: "test-app."},
+			wantErr: true,
+		},
+		{
+			name:    "core resource",
+			reg:     plugins.ResourcePermissionRegistration{Resource: "dashboards"},
+			wantErr: true,
+		},
+		{
+			name: "permission with a core action",
+			reg: plugins.ResourcePermissionRegistration{Resource: "test-app.projects", Permissions: map[string][]string{
+				"View": {"dashboards:read"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePluginResourcePermissions("test-app", tt.reg)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
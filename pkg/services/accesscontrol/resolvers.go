@@ -27,6 +27,12 @@ type ActionResolver interface {
 	ResolveActionPrefix(prefix string) []string
 }
 
+// ActionSetLister is implemented by action resolvers that can list the action sets they resolve.
+type ActionSetLister interface {
+	// ListActionSets returns the actions of each action set, included action sets are resolved to their actions.
+	ListActionSets() map[string][]string
+}
+
 // ScopeAttributeResolverFunc is an adapter to allow functions to implement ScopeAttributeResolver interface
 type ScopeAttributeResolverFunc func(ctx context.Context, orgID int64, scope string) ([]string, error)
 
@@ -0,0 +1,230 @@
+package resourcepermissions
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// UserWithAccess is a user that can perform an action on a resource
+type UserWithAccess struct {
+	ID    int64  `json:"id" xorm:"id"`
+	UID   string `json:"uid" xorm:"uid"`
+	Login string `json:"login" xorm:"login"`
+	Email string `json:"email" xorm:"email"`
+	Name  string `json:"name" xorm:"name"`
+}
+
+// ListUsersWithAccess returns the users of the organization that can perform action on the resource, whether the
+// permission is granted to them directly, through a team, an external group, their basic role or a parent resource.
+// OpenFGA is queried when zanzana is enabled and the resource is supported, the permissions stored in SQL otherwise.
+func (s *Service) ListUsersWithAccess(ctx context.Context, orgID int64, resourceID, action string) ([]UserWithAccess, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.ListUsersWithAccess")
+	defer span.End()
+
+	if !slices.Contains(s.actions, action) {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("action", nil))
+	}
+
+	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
+		return nil, err
+	}
+
+	var inheritedScopes []string
+	if s.options.InheritedScopesSolver != nil {
+		var err error
+		inheritedScopes, err = s.options.InheritedScopesSolver(ctx, orgID, resourceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		uids, ok, err := s.listZanzanaUsersWithAccess(ctx, orgID, resourceID, action, inheritedScopes)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return getOrgUsers(ctx, s.sqlStore, orgID, "u.uid", uids)
+		}
+	}
+
+	ids, err := s.listSQLUsersWithAccess(ctx, orgID, resourceID, action, inheritedScopes)
+	if err != nil {
+		return nil, err
+	}
+	return getOrgUsers(ctx, s.sqlStore, orgID, "u.id", ids)
+}
+
+// listZanzanaUsersWithAccess returns the uid of the users having the relation translated from action on the resource.
+// It returns false when the resource or the action has no translation.
+func (s *Service) listZanzanaUsersWithAccess(ctx context.Context, orgID int64, resourceID, action string, inheritedScopes []string) ([]string, bool, error) {
+	tuple, ok := zanzana.TranslateToTuple("", action, s.options.Resource, resourceID, orgID)
+	if !ok {
+		return nil, false, nil
+	}
+
+	requests := []accesscontrol.ListUsersRequest{{Object: tuple.Object, Relation: tuple.Relation}}
+	// Access granted on the containers of the resource is not part of its relations, the containers are queried separately
+	objectType, _ := zanzana.TranslateKindToType(s.options.Resource)
+	for _, scope := range inheritedScopes {
+		kind, _, identifier := accesscontrol.SplitScope(scope)
+		container, ok := zanzana.TranslateToContainerTuple("", tuple.Relation, objectType, kind, identifier, orgID)
+		if !ok {
+			continue
+		}
+		requests = append(requests, accesscontrol.ListUsersRequest{Object: container.Object, Relation: container.Relation})
+	}
+
+	users := map[string]struct{}{}
+	for _, req := range requests {
+		res, err := s.ac.ListUsers(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, u := range res {
+			users[strings.TrimPrefix(u, zanzana.TypeUser+":")] = struct{}{}
+		}
+	}
+
+	if len(requests) > 1 {
+		// Deny on the dashboard also excludes the users granted access on a parent folder
+		denied, err := s.ac.ListUsers(ctx, accesscontrol.ListUsersRequest{Object: tuple.Object, Relation: "deny"})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, u := range denied {
+			delete(users, strings.TrimPrefix(u, zanzana.TypeUser+":"))
+		}
+	}
+
+	uids := make([]string, 0, len(users))
+	for uid := range users {
+		uids = append(uids, uid)
+	}
+	return uids, true, nil
+}
+
+// listSQLUsersWithAccess returns the id of the users granted action on the resource by a role stored in the database.
+// Users denied the action through any of their roles are excluded.
+func (s *Service) listSQLUsersWithAccess(ctx context.Context, orgID int64, resourceID, action string, inheritedScopes []string) ([]int64, error) {
+	actions := []string{action}
+	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+		actions = append(actions, s.actionSetSvc.ResolveAction(action)...)
+	}
+
+	scope := accesscontrol.Scope(s.options.Resource, s.options.ResourceAttribute, resourceID)
+	scopes := append(s.wildcards.ForScope(scope), scope)
+	scopes = append(scopes, inheritedScopes...)
+
+	where := `WHERE (r.org_id = ? OR r.org_id = 0) AND p.scope IN (?` + strings.Repeat(",?", len(scopes)-1) + `)` +
+		` AND p.action IN (?` + strings.Repeat(",?", len(actions)-1) + `)`
+	whereArgs := make([]any, 0, 1+len(scopes)+len(actions))
+	whereArgs = append(whereArgs, orgID)
+	for _, sc := range scopes {
+		whereArgs = append(whereArgs, sc)
+	}
+	for _, a := range actions {
+		whereArgs = append(whereArgs, a)
+	}
+
+	from := `
+	FROM permission p
+		INNER JOIN role r ON p.role_id = r.id
+	`
+	userQuery := `SELECT ur.user_id AS user_id, p.deny AS deny` + from + `
+		INNER JOIN user_role ur ON r.id = ur.role_id AND (ur.org_id = 0 OR ur.org_id = ?)
+	` + where
+	teamQuery := `SELECT tm.user_id AS user_id, p.deny AS deny` + from + `
+		INNER JOIN team_role tr ON r.id = tr.role_id AND tr.org_id = ?
+		INNER JOIN team_member tm ON tr.team_id = tm.team_id
+	` + where
+	builtinQuery := `SELECT ou.user_id AS user_id, p.deny AS deny` + from + `
+		INNER JOIN builtin_role br ON r.id = br.role_id AND (br.org_id = 0 OR br.org_id = ?)
+		INNER JOIN org_user ou ON br.role = ou.role AND ou.org_id = ?
+	` + where
+	serverAdminQuery := `SELECT u.id AS user_id, p.deny AS deny` + from + `
+		INNER JOIN builtin_role br ON r.id = br.role_id AND br.role = ?
+		INNER JOIN ` + s.sqlStore.GetDialect().Quote("user") + ` u ON u.is_admin = ` + s.sqlStore.GetDialect().BooleanStr(true) + `
+	` + where
+	groupQuery := `SELECT ueg.user_id AS user_id, p.deny AS deny` + from + `
+		INNER JOIN group_role gr ON r.id = gr.role_id AND gr.org_id = ?
+		INNER JOIN user_external_group ueg ON gr.group_id = ueg.group_id
+	` + where
+
+	args := append([]any{orgID}, whereArgs...)
+	args = append(args, orgID)
+	args = append(args, whereArgs...)
+	args = append(args, orgID, orgID)
+	args = append(args, whereArgs...)
+	args = append(args, accesscontrol.RoleGrafanaAdmin)
+	args = append(args, whereArgs...)
+	args = append(args, orgID)
+	args = append(args, whereArgs...)
+
+	sql := userQuery + " UNION " + teamQuery + " UNION " + builtinQuery + " UNION " + serverAdminQuery + " UNION " + groupQuery
+
+	var assignments []struct {
+		UserID int64 `xorm:"user_id"`
+		Deny   bool  `xorm:"deny"`
+	}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql, args...).Find(&assignments)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	granted := map[int64]struct{}{}
+	denied := map[int64]struct{}{}
+	for _, a := range assignments {
+		if a.Deny {
+			denied[a.UserID] = struct{}{}
+		} else {
+			granted[a.UserID] = struct{}{}
+		}
+	}
+
+	ids := make([]int64, 0, len(granted))
+	for id := range granted {
+		if _, ok := denied[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// getOrgUsers returns the members of the organization having one of values in column, sorted by login.
+func getOrgUsers[T int64 | string](ctx context.Context, sqlStore db.DB, orgID int64, column string, values []T) ([]UserWithAccess, error) {
+	users := make([]UserWithAccess, 0, len(values))
+	if len(values) == 0 {
+		return users, nil
+	}
+
+	args := make([]any, 0, len(values)+1)
+	args = append(args, orgID)
+	for _, v := range values {
+		args = append(args, v)
+	}
+
+	sql := `SELECT u.id, u.uid, u.login, u.email, u.name FROM ` + sqlStore.GetDialect().Quote("user") + ` u
+		INNER JOIN org_user ou ON u.id = ou.user_id AND ou.org_id = ?
+		WHERE ` + column + ` IN (?` + strings.Repeat(",?", len(values)-1) + `)`
+
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql, args...).Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Login < users[j].Login })
+	return users, nil
+}
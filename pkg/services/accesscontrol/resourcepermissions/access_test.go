@@ -0,0 +1,83 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_ListUsersWithAccess(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true, Teams: true, BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write"},
+		},
+		InheritedScopesSolver: func(ctx context.Context, orgID int64, resourceID string) ([]string, error) {
+			return []string{"folders:uid:parent"}, nil
+		},
+	})
+
+	direct, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "direct", OrgID: 1})
+	require.NoError(t, err)
+	member, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "member", OrgID: 1})
+	require.NoError(t, err)
+	_, err = usrSvc.Create(ctx, &user.CreateUserCommand{Login: "other", OrgID: 1})
+	require.NoError(t, err)
+
+	tm, err := teamSvc.CreateTeam(ctx, "team", "", 1)
+	require.NoError(t, err)
+	err = service.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return teamimpl.AddOrUpdateTeamMemberHook(sess, member.ID, 1, tm.ID, false, team.PermissionTypeMember)
+	})
+	require.NoError(t, err)
+
+	_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: direct.ID}, "dash", "Edit")
+	require.NoError(t, err)
+	_, err = service.SetTeamPermission(ctx, 1, tm.ID, "dash", "View")
+	require.NoError(t, err)
+
+	logins := func(users []UserWithAccess) []string {
+		result := make([]string, 0, len(users))
+		for _, u := range users {
+			result = append(result, u.Login)
+		}
+		return result
+	}
+
+	t.Run("should return users with access directly and through teams", func(t *testing.T) {
+		users, err := service.ListUsersWithAccess(ctx, 1, "dash", "dashboards:read")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"direct", "member"}, logins(users))
+
+		users, err = service.ListUsersWithAccess(ctx, 1, "dash", "dashboards:write")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"direct"}, logins(users))
+	})
+
+	t.Run("should return users with access through their basic role", func(t *testing.T) {
+		// users are created with the Admin role
+		_, err := service.SetBuiltInRolePermission(ctx, 1, "Admin", "dash", "Edit")
+		require.NoError(t, err)
+
+		users, err := service.ListUsersWithAccess(ctx, 1, "dash", "dashboards:write")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"direct", "member", "other"}, logins(users))
+	})
+
+	t.Run("should fail for an action not managed by the service", func(t *testing.T) {
+		_, err := service.ListUsersWithAccess(ctx, 1, "dash", "dashboards:delete")
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
@@ -1,9 +1,13 @@
 package resourcepermissions
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -33,6 +37,9 @@ func newApi(cfg *setting.Cfg, ac accesscontrol.AccessControl, router routing.Rou
 	for i := len(manager.permissions) - 1; i >= 0; i-- {
 		permissions = append(permissions, manager.permissions[i])
 	}
+	if manager.options.AllowDeny {
+		permissions = append(permissions, DenyPermission)
+	}
 	return &api{cfg, ac, router, manager, permissions}
 }
 
@@ -54,10 +61,16 @@ func (a *api) registerEndpoints() {
 		actionWrite := fmt.Sprintf("%s.permissions:write", a.service.options.Resource)
 		scope := accesscontrol.Scope(a.service.options.Resource, a.service.options.ResourceAttribute, accesscontrol.Parameter(":resourceID"))
 		r.Get("/description", auth(accesscontrol.EvalPermission(actionRead)), routing.Wrap(a.getDescription))
+		r.Post("/bulk", licenseMW, auth(accesscontrol.EvalPermission(actionWrite)), routing.Wrap(a.setBulkPermissions))
+		r.Post("/jobs", licenseMW, auth(accesscontrol.EvalPermission(actionWrite)), routing.Wrap(a.startPermissionJob))
+		r.Get("/jobs/:jobID", auth(accesscontrol.EvalPermission(actionRead)), routing.Wrap(a.getPermissionJob))
 		r.Get("/:resourceID", teamUIDResolverResource, auth(accesscontrol.EvalPermission(actionRead, scope)), routing.Wrap(a.getPermissions))
+		r.Get("/:resourceID/diff", teamUIDResolverResource, auth(accesscontrol.EvalPermission(actionRead, scope)), routing.Wrap(a.getPermissionDiff))
 		r.Post("/:resourceID", teamUIDResolverResource, licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setPermissions))
+		r.Put("/:resourceID/metadata", teamUIDResolverResource, licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setPermissionMetadata))
 		if a.service.options.Assignments.Users {
 			r.Post("/:resourceID/users/:userID", licenseMW, teamUIDResolverResource, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setUserPermission))
+			r.Post("/:resourceID/users/:userID/temporary", licenseMW, teamUIDResolverResource, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.grantTemporaryUserPermission))
 		}
 		if a.service.options.Assignments.Teams {
 			r.Post("/:resourceID/teams/:teamID", licenseMW, teamUIDResolverResource, teamUIDResolver, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setTeamPermission))
@@ -65,6 +78,9 @@ func (a *api) registerEndpoints() {
 		if a.service.options.Assignments.BuiltInRoles {
 			r.Post("/:resourceID/builtInRoles/:builtInRole", teamUIDResolverResource, licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setBuiltinRolePermission))
 		}
+		if a.service.options.Assignments.Groups {
+			r.Post("/:resourceID/groups", teamUIDResolverResource, licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setGroupPermission))
+		}
 	})
 }
 
@@ -73,6 +89,8 @@ type Assignments struct {
 	ServiceAccounts bool `json:"serviceAccounts"`
 	Teams           bool `json:"teams"`
 	BuiltInRoles    bool `json:"builtInRoles"`
+	// Groups are the external groups of the identity provider, see accesscontrol.ExternalGroupSyncer
+	Groups bool `json:"groups"`
 }
 
 // swagger:parameters getResourceDescription
@@ -110,7 +128,10 @@ func (a *api) getDescription(c *contextmodel.ReqContext) response.Response {
 }
 
 type resourcePermissionDTO struct {
-	ID               int64    `json:"id"`
+	// ID is the id of one of the rows of the permission, it changes when the permission is updated
+	ID int64 `json:"id"`
+	// StableID identifies the permission by its scope and assignee and does not change when the permission is updated
+	StableID         string   `json:"stableId"`
 	RoleName         string   `json:"roleName"`
 	IsManaged        bool     `json:"isManaged"`
 	IsInherited      bool     `json:"isInherited"`
@@ -122,8 +143,38 @@ type resourcePermissionDTO struct {
 	TeamID           int64    `json:"teamId,omitempty"`
 	TeamAvatarUrl    string   `json:"teamAvatarUrl,omitempty"`
 	BuiltInRole      string   `json:"builtInRole,omitempty"`
+	GroupID          string   `json:"groupId,omitempty"`
 	Actions          []string `json:"actions"`
 	Permission       string   `json:"permission"`
+	// ActionSets maps action sets to the actions they grant, only included when requested
+	ActionSets map[string][]string `json:"actionSets,omitempty"`
+	// IsTemporary is set for permissions that are revoked once Expires is reached
+	IsTemporary bool `json:"isTemporary,omitempty"`
+	// Expires is the time a temporary permission is revoked
+	Expires *time.Time `json:"expires,omitempty"`
+	// Metadata is the key/value pairs attached to the permission
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type assigneePermissionsDTO struct {
+	UserID           int64  `json:"userId,omitempty"`
+	UserLogin        string `json:"userLogin,omitempty"`
+	UserAvatarUrl    string `json:"userAvatarUrl,omitempty"`
+	Team             string `json:"team,omitempty"`
+	TeamID           int64  `json:"teamId,omitempty"`
+	TeamAvatarUrl    string `json:"teamAvatarUrl,omitempty"`
+	BuiltInRole      string `json:"builtInRole,omitempty"`
+	GroupID          string `json:"groupId,omitempty"`
+	IsServiceAccount bool   `json:"isServiceAccount"`
+	// Scopes maps the scope of the resource and the scopes it inherits from to the permissions of the assignee
+	Scopes map[string]*assigneeScopeDTO `json:"scopes"`
+}
+
+type assigneeScopeDTO struct {
+	// Actions are the actions granted to the assignee on the scope
+	Actions []string `json:"actions"`
+	// Deny is set when the assignee is denied access on the scope, whatever the actions granted by other permissions
+	Deny bool `json:"deny,omitempty"`
 }
 
 // swagger:parameters getResourcePermissions
@@ -135,6 +186,32 @@ type GetResourcePermissionsParams struct {
 	// in:path
 	// required:true
 	ResourceID string `json:"resourceID"`
+
+	// Include the actions granted by action sets in the response
+	// in:query
+	// required:false
+	ExpandActionSets bool `json:"expandActionSets"`
+
+	// Only include the assignments granting at least this permission, e.g. Edit also includes Admin assignments
+	// in:query
+	// required:false
+	Permission string `json:"permission"`
+
+	// Return one entry per assignee with the actions granted on each scope instead of one entry per permission
+	// in:query
+	// required:false
+	GroupByAssignee bool `json:"groupByAssignee"`
+
+	// Maximum number of permissions returned, all permissions are returned when omitted. The total number of
+	// permissions is returned in the X-Total-Count header.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+
+	// Number of permissions skipped before the first one returned
+	// in:query
+	// required:false
+	Offset int `json:"offset"`
 }
 
 // swagger:response getResourcePermissionsResponse
@@ -146,6 +223,7 @@ type getResourcePermissionsResponse []resourcePermissionDTO
 //
 // Responses:
 // 200: getResourcePermissionsResponse
+// 400: badRequestError
 // 403: forbiddenError
 // 404: notFoundError
 // 500: internalServerError
@@ -156,17 +234,40 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 
 	resourceID := web.Params(c.Req)[":resourceID"]
 
-	permissions, err := a.service.GetPermissions(c.Req.Context(), c.SignedInUser, resourceID)
+	// Clients polling the permissions send the ETag of their last response, the permissions are only loaded when
+	// their version changed. The version is best effort, when it can't be computed the permissions are returned.
+	etag := ""
+	if version, err := a.service.getPermissionsVersion(c.Req.Context(), c.SignedInUser.GetOrgID(), resourceID); err != nil {
+		a.service.log.Warn("Failed to get permissions version", "resource", a.service.options.Resource, "resourceID", resourceID, "error", err)
+	} else {
+		etag = permissionsETag(version, c.SignedInUser.GetUID(), c.Req.URL.RawQuery)
+		if c.Req.Header.Get("If-None-Match") == etag {
+			return response.Empty(http.StatusNotModified).SetHeader("ETag", etag)
+		}
+	}
+
+	limit, offset := c.QueryInt("limit"), c.QueryInt("offset")
+	page, err := a.service.getPermissionsPage(c.Req.Context(), c.SignedInUser, resourceID, c.QueryBool("expandActionSets"), c.Query("permission"), true, limit, offset)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get permissions", err)
 	}
+	permissions, totalCount := page.Permissions, page.TotalCount
 
+	// The implicit Admin permission comes after the stored permissions, it's on the page following the last one
+	// when the last stored permission fills a page.
 	if a.service.options.Assignments.BuiltInRoles && !a.service.license.FeatureEnabled("accesscontrol.enforcement") {
-		permissions = append(permissions, accesscontrol.ResourcePermission{
-			Actions:     a.service.actions,
-			Scope:       "*",
-			BuiltInRole: string(org.RoleAdmin),
-		})
+		if limit == 0 || (len(permissions) < limit && int64(offset) <= totalCount) {
+			permissions = append(permissions, accesscontrol.ResourcePermission{
+				Actions:     a.service.actions,
+				Scope:       "*",
+				BuiltInRole: string(org.RoleAdmin),
+			})
+		}
+		totalCount++
+	}
+
+	if c.QueryBool("groupByAssignee") {
+		return withTotalCount(withETag(response.JSON(http.StatusOK, a.groupByAssignee(permissions)), etag), totalCount)
 	}
 
 	dto := make(getResourcePermissionsResponse, 0, len(permissions))
@@ -177,8 +278,14 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 				teamAvatarUrl = dtos.GetGravatarUrlWithDefault(a.cfg, p.TeamEmail, p.Team)
 			}
 
+			var expires *time.Time
+			if !p.Expires.IsZero() {
+				expires = &p.Expires
+			}
+
 			dto = append(dto, resourcePermissionDTO{
 				ID:               p.ID,
+				StableID:         p.StableID(),
 				RoleName:         p.RoleName,
 				UserID:           p.UserId,
 				UserLogin:        p.UserLogin,
@@ -187,22 +294,175 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 				TeamID:           p.TeamId,
 				TeamAvatarUrl:    teamAvatarUrl,
 				BuiltInRole:      p.BuiltInRole,
+				GroupID:          p.GroupID,
 				Actions:          p.Actions,
 				Permission:       permission,
 				IsManaged:        p.IsManaged,
 				IsInherited:      p.IsInherited,
 				IsServiceAccount: p.IsServiceAccount,
+				ActionSets:       p.ActionSets,
+				IsTemporary:      expires != nil,
+				Expires:          expires,
+				Metadata:         p.Metadata,
 			})
 		}
 	}
 
-	return response.JSON(http.StatusOK, dto)
+	return withTotalCount(withETag(response.JSON(http.StatusOK, dto), etag), totalCount)
+}
+
+// permissionsETag returns the ETag of the permissions with version, the response also depends on the user, whose
+// access can filter the assignees, and on the query parameters.
+func permissionsETag(version, userUID, rawQuery string) string {
+	hash := sha256.Sum256([]byte(version + "\x00" + userUID + "\x00" + rawQuery))
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// TotalCountHeader is the header of the resource permissions API carrying the number of permissions across all pages
+const TotalCountHeader = "X-Total-Count"
+
+// withTotalCount sets the number of permissions across all pages, clients paging through the permissions with the
+// limit and offset parameters use it to know when they reached the last page.
+func withTotalCount(resp *response.NormalResponse, totalCount int64) *response.NormalResponse {
+	return resp.SetHeader(TotalCountHeader, strconv.FormatInt(totalCount, 10))
+}
+
+func withETag(resp *response.NormalResponse, etag string) *response.NormalResponse {
+	if etag == "" {
+		return resp
+	}
+	return resp.SetHeader("ETag", etag)
+}
+
+// groupByAssignee merges the permissions of each assignee, the order of the assignees is the order of their first permission.
+func (a *api) groupByAssignee(permissions []accesscontrol.ResourcePermission) []*assigneePermissionsDTO {
+	type assignee struct {
+		userID      int64
+		teamID      int64
+		builtInRole string
+		groupID     string
+	}
+
+	dto := make([]*assigneePermissionsDTO, 0, len(permissions))
+	byAssignee := make(map[assignee]*assigneePermissionsDTO, len(permissions))
+	for _, p := range permissions {
+		if a.service.MapActions(p) == "" {
+			continue
+		}
+
+		key := assignee{userID: p.UserId, teamID: p.TeamId, builtInRole: p.BuiltInRole, groupID: p.GroupID}
+		entry, ok := byAssignee[key]
+		if !ok {
+			teamAvatarUrl := ""
+			if p.TeamId != 0 {
+				teamAvatarUrl = dtos.GetGravatarUrlWithDefault(a.cfg, p.TeamEmail, p.Team)
+			}
+
+			entry = &assigneePermissionsDTO{
+				UserID:           p.UserId,
+				UserLogin:        p.UserLogin,
+				UserAvatarUrl:    dtos.GetGravatarUrl(a.cfg, p.UserEmail),
+				Team:             p.Team,
+				TeamID:           p.TeamId,
+				TeamAvatarUrl:    teamAvatarUrl,
+				BuiltInRole:      p.BuiltInRole,
+				GroupID:          p.GroupID,
+				IsServiceAccount: p.IsServiceAccount,
+				Scopes:           map[string]*assigneeScopeDTO{},
+			}
+			byAssignee[key] = entry
+			dto = append(dto, entry)
+		}
+
+		scope, ok := entry.Scopes[p.Scope]
+		if !ok {
+			scope = &assigneeScopeDTO{Actions: []string{}}
+			entry.Scopes[p.Scope] = scope
+		}
+		// The actions of deny permissions are not granted
+		if p.Deny {
+			scope.Deny = true
+			continue
+		}
+		actions := append(scope.Actions, p.Actions...)
+		slices.Sort(actions)
+		scope.Actions = slices.Compact(actions)
+	}
+
+	return dto
+}
+
+// swagger:parameters getResourcePermissionsDiff
+type GetResourcePermissionsDiffParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	ResourceID string `json:"resourceID"`
+
+	// Start of the period in epoch milliseconds
+	// in:query
+	// required:true
+	From int64 `json:"from"`
+
+	// End of the period in epoch milliseconds, defaults to now
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+}
+
+// swagger:response getResourcePermissionsDiffResponse
+type getResourcePermissionsDiffResponse []PermissionDiff
+
+// swagger:route GET /access-control/{resource}/{resourceID}/diff access_control getResourcePermissionsDiff
+//
+// Get the changes of resource permissions over a period.
+//
+// Returns the assignees whose permission on the resource changed between `from` and `to`,
+// with the actions added and removed. Only changes made since the audit log was introduced are returned,
+// the period can not be longer than 90 days.
+//
+// Responses:
+// 200: getResourcePermissionsDiffResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 500: internalServerError
+func (a *api) getPermissionDiff(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.getPermissionDiff")
+	defer span.End()
+
+	resourceID := web.Params(c.Req)[":resourceID"]
+
+	from := c.QueryInt64("from")
+	if from <= 0 {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("from", nil)))
+	}
+	to := time.Now()
+	if ms := c.QueryInt64("to"); ms > 0 {
+		to = time.UnixMilli(ms)
+	}
+
+	diff, err := a.service.GetPermissionDiff(ctx, c.SignedInUser.GetOrgID(), resourceID, time.UnixMilli(from), to)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, diff)
 }
 
 type setPermissionCommand struct {
 	Permission string `json:"permission"`
 }
 
+// setGroupPermissionCommand sets the permission of an external group, its id is part of the body as the group names
+// of identity providers can hold any character.
+type setGroupPermissionCommand struct {
+	GroupID    string `json:"groupId"`
+	Permission string `json:"permission"`
+}
+
 type setPermissionsCommand struct {
 	Permissions []accesscontrol.SetResourcePermissionCommand `json:"permissions"`
 }
@@ -264,6 +524,125 @@ func (a *api) setUserPermission(c *contextmodel.ReqContext) response.Response {
 	return permissionSetResponse(cmd)
 }
 
+type grantTemporaryPermissionCommand struct {
+	Permission string `json:"permission"`
+	// Duration of the grant, e.g. 1h
+	Duration string `json:"duration"`
+}
+
+// swagger:parameters grantTemporaryResourcePermissionsForUser
+type GrantTemporaryResourcePermissionsForUserParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	ResourceID string `json:"resourceID"`
+
+	// in:path
+	// required:true
+	UserID int64 `json:"userID"`
+
+	// in:body
+	// required:true
+	Body grantTemporaryPermissionCommand
+}
+
+// swagger:route POST /access-control/{resource}/{resourceID}/users/{userID}/temporary access_control grantTemporaryResourcePermissionsForUser
+//
+// Temporarily grant resource permissions to a user.
+//
+// Assigns permissions for a resource to a user for a limited duration, the permission is revoked automatically once expired.
+// The duration can't exceed the `temporary_permission_max_duration` setting.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (a *api) grantTemporaryUserPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.grantTemporaryUserPermission")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":userID"], 10, 64)
+	if err != nil {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("userID", err)))
+	}
+	resourceID := web.Params(c.Req)[":resourceID"]
+
+	var cmd grantTemporaryPermissionCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	duration, err := time.ParseDuration(cmd.Duration)
+	if err != nil {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("duration", err)))
+	}
+
+	grantedBy, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Err(err)
+	}
+
+	_, err = a.service.GrantTemporaryUserPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), accesscontrol.User{ID: userID}, resourceID, cmd.Permission, duration, grantedBy)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Temporary permission granted")
+}
+
+// swagger:parameters setResourcePermissionMetadata
+type SetResourcePermissionMetadataParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	ResourceID string `json:"resourceID"`
+
+	// in:body
+	// required:true
+	Body SetPermissionMetadataCommand
+}
+
+// swagger:route PUT /access-control/{resource}/{resourceID}/metadata access_control setResourcePermissionMetadata
+//
+// Set the metadata of a resource permission.
+//
+// Replaces the key/value pairs attached to the permission of a user, team or basic role on the resource.
+// At most 10 pairs can be attached to a permission, they are removed together with the permission.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (a *api) setPermissionMetadata(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.setPermissionMetadata")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	resourceID := web.Params(c.Req)[":resourceID"]
+
+	var cmd SetPermissionMetadataCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := a.service.SetPermissionMetadata(c.Req.Context(), c.SignedInUser.GetOrgID(), resourceID, cmd); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Permission metadata updated")
+}
+
 // swagger:parameters setResourcePermissionsForTeam
 type SetResourcePermissionsForTeamParams struct {
 	// in:path
@@ -375,6 +754,58 @@ func (a *api) setBuiltinRolePermission(c *contextmodel.ReqContext) response.Resp
 	return permissionSetResponse(cmd)
 }
 
+// swagger:parameters setResourcePermissionsForGroup
+type SetResourcePermissionsForGroupParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	ResourceID string `json:"resourceID"`
+
+	// in:body
+	// required:true
+	Body setGroupPermissionCommand
+}
+
+// swagger:route POST /access-control/{resource}/{resourceID}/groups access_control setResourcePermissionsForGroup
+//
+// Set resource permissions for an external group.
+//
+// Assigns permissions for a resource by a given type (`:resource`) and `:resourceID` to the members of an external
+// group of the identity provider. Allowed resources are `dashboards` and `folders`.
+// Refer to the `/access-control/{resource}/description` endpoint for allowed Permissions.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (a *api) setGroupPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.setGroupPermission")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	resourceID := web.Params(c.Req)[":resourceID"]
+
+	cmd := setGroupPermissionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.GroupID == "" {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("groupId", fmt.Errorf("missing group"))))
+	}
+
+	_, err := a.service.SetGroupPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.GroupID, resourceID, cmd.Permission)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return permissionSetResponse(setPermissionCommand{Permission: cmd.Permission})
+}
+
 // swagger:parameters setResourcePermissions
 type SetResourcePermissionsParams struct {
 	// in:path
@@ -385,6 +816,10 @@ type SetResourcePermissionsParams struct {
 	// required:true
 	ResourceID string `json:"resourceID"`
 
+	// Retries of the request with the same key return the result of the first request without applying the permissions again
+	// in:header
+	IdempotencyKey string `json:"Idempotency-Key"`
+
 	// in:body
 	// required:true
 	Body setPermissionsCommand
@@ -403,6 +838,7 @@ type SetResourcePermissionsParams struct {
 // 400: badRequestError
 // 403: forbiddenError
 // 404: notFoundError
+// 422: unprocessableEntityError
 // 500: internalServerError
 func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.setPermissions")
@@ -415,6 +851,7 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
 	}
 
+	ctx = WithIdempotencyKey(ctx, c.Req.Header.Get(IdempotencyKeyHeader))
 	_, err := a.service.SetPermissions(ctx, c.SignedInUser.GetOrgID(), resourceID, cmd.Permissions...)
 	if err != nil {
 		return response.Err(err)
@@ -423,6 +860,191 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 	return response.Success("Permissions updated")
 }
 
+// maxBulkPermissionsResources is the number of resources whose permissions can be set in a single transaction,
+// jobs set the permissions of more resources
+const maxBulkPermissionsResources = 100
+
+type setBulkPermissionsCommand struct {
+	ResourceIDs []string                                     `json:"resourceIds"`
+	Permissions []accesscontrol.SetResourcePermissionCommand `json:"permissions"`
+}
+
+// swagger:parameters setResourcePermissionsBulk
+type SetResourcePermissionsBulkParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:body
+	// required:true
+	Body setBulkPermissionsCommand
+}
+
+// swagger:route POST /access-control/{resource}/bulk access_control setResourcePermissionsBulk
+//
+// Set resource permissions on many resources at once.
+//
+// Assigns the same permissions to every resource in a single transaction, the permissions of all the resources are
+// set or none of them are. Refer to the `/access-control/{resource}/jobs` endpoint for more resources.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 500: internalServerError
+func (a *api) setBulkPermissions(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.setBulkPermissions")
+	defer span.End()
+
+	cmd := setBulkPermissionsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
+	}
+	if len(cmd.ResourceIDs) == 0 || len(cmd.ResourceIDs) > maxBulkPermissionsResources {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("resourceIds",
+			fmt.Errorf("between 1 and %d resources can be set at once", maxBulkPermissionsResources))))
+	}
+
+	if resp := a.authorizeResources(c, cmd.ResourceIDs); resp != nil {
+		return resp
+	}
+
+	targets := make([]BulkPermissionsTarget, 0, len(cmd.ResourceIDs))
+	for _, resourceID := range cmd.ResourceIDs {
+		targets = append(targets, BulkPermissionsTarget{Service: a.service, ResourceID: resourceID, Commands: cmd.Permissions})
+	}
+	if _, err := SetBulkPermissions(ctx, c.SignedInUser.GetOrgID(), targets...); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Permissions updated")
+}
+
+type startPermissionJobCommand struct {
+	ResourceIDs []string                                     `json:"resourceIds"`
+	Permissions []accesscontrol.SetResourcePermissionCommand `json:"permissions"`
+}
+
+// swagger:parameters startResourcePermissionsJob
+type StartResourcePermissionsJobParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:body
+	// required:true
+	Body startPermissionJobCommand
+}
+
+// swagger:route POST /access-control/{resource}/jobs access_control startResourcePermissionsJob
+//
+// Set resource permissions on many resources asynchronously.
+//
+// Starts a job assigning the same permissions to every resource, the job is executed in the background.
+// Refer to the `/access-control/{resource}/jobs/{jobID}` endpoint for the progress of the job.
+//
+// Responses:
+// 202: startResourcePermissionsJobResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 500: internalServerError
+func (a *api) startPermissionJob(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.startPermissionJob")
+	defer span.End()
+
+	cmd := startPermissionJobCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
+	}
+
+	if resp := a.authorizeResources(c, cmd.ResourceIDs); resp != nil {
+		return resp
+	}
+
+	createdBy, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Err(err)
+	}
+
+	job, err := a.service.StartPermissionJob(ctx, c.SignedInUser.GetOrgID(), createdBy, cmd.ResourceIDs, cmd.Permissions...)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusAccepted, job)
+}
+
+// authorizeResources returns an error response unless the user is allowed to write the permissions of every
+// resource, the routes setting the permissions of many resources only check the action.
+func (a *api) authorizeResources(c *contextmodel.ReqContext, resourceIDs []string) response.Response {
+	actionWrite := fmt.Sprintf("%s.permissions:write", a.service.options.Resource)
+	for _, resourceID := range resourceIDs {
+		scope := accesscontrol.Scope(a.service.options.Resource, a.service.options.ResourceAttribute, resourceID)
+		ok, err := a.ac.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(actionWrite, scope))
+		if err != nil {
+			return response.Err(err)
+		}
+		if !ok {
+			return response.Error(http.StatusForbidden, fmt.Sprintf("You'll need additional permissions to set the permissions of %s", resourceID), nil)
+		}
+	}
+	return nil
+}
+
+// swagger:response startResourcePermissionsJobResponse
+type StartResourcePermissionsJobResponse struct {
+	// in:body
+	// required:true
+	Body PermissionJob `json:"body"`
+}
+
+// swagger:parameters getResourcePermissionsJob
+type GetResourcePermissionsJobParams struct {
+	// in:path
+	// required:true
+	Resource string `json:"resource"`
+
+	// in:path
+	// required:true
+	JobID int64 `json:"jobID"`
+}
+
+// swagger:route GET /access-control/{resource}/jobs/{jobID} access_control getResourcePermissionsJob
+//
+// Get the progress of a resource permissions job.
+//
+// Returns the number of processed and failed resources of the job and the error of each failed resource.
+//
+// Responses:
+// 200: getResourcePermissionsJobResponse
+// 400: badRequestError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (a *api) getPermissionJob(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.resourcepermissions.getPermissionJob")
+	defer span.End()
+
+	jobID, err := strconv.ParseInt(web.Params(c.Req)[":jobID"], 10, 64)
+	if err != nil {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("jobID", err)))
+	}
+
+	job, err := a.service.GetPermissionJob(ctx, c.SignedInUser.GetOrgID(), jobID)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:response getResourcePermissionsJobResponse
+type GetResourcePermissionsJobResponse struct {
+	// in:body
+	// required:true
+	Body PermissionJob `json:"body"`
+}
+
 func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	message := "Permission updated"
 	if cmd.Permission == "" {
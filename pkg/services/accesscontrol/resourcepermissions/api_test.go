@@ -13,12 +13,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -172,6 +174,114 @@ func TestApi_getPermissions(t *testing.T) {
 	}
 }
 
+func TestApi_getPermissionsGroupedByAssignee(t *testing.T) {
+	service, usrSvc, teamSvc := setupTestEnvironment(t, testOptions)
+	server := setupTestServer(t, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), []accesscontrol.Permission{
+		{Action: "dashboards.permissions:read", Scope: "dashboards:id:1"},
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+	})}}, service)
+
+	seedPermissions(t, "1", usrSvc, teamSvc, service)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/access-control/dashboards/1?groupByAssignee=true", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var assignees []assigneePermissionsDTO
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&assignees))
+	require.Len(t, assignees, 3, "expected three assignees: user, team, builtin")
+	for _, a := range assignees {
+		switch {
+		case a.UserID != 0:
+			assert.Equal(t, map[string]*assigneeScopeDTO{"dashboards:id:1": {Actions: []string{"dashboards:read"}}}, a.Scopes)
+		case a.TeamID != 0, a.BuiltInRole != "":
+			assert.Equal(t, map[string]*assigneeScopeDTO{"dashboards:id:1": {Actions: []string{"dashboards:delete", "dashboards:read", "dashboards:write"}}}, a.Scopes)
+		default:
+			t.Fatalf("unexpected assignee %+v", a)
+		}
+	}
+}
+
+func TestApi_groupByAssigneeDeny(t *testing.T) {
+	a := &api{cfg: setting.NewCfg(), service: &Service{
+		options:     Options{PermissionsToActions: map[string][]string{"View": {"dashboards:read"}}},
+		permissions: []string{"View"},
+	}}
+
+	assignees := a.groupByAssignee([]accesscontrol.ResourcePermission{
+		{UserId: 1, Scope: "folders:uid:parent", Actions: []string{"dashboards:read"}, IsInherited: true},
+		{UserId: 1, Scope: "dashboards:uid:1", Actions: []string{"dashboards:read"}, Deny: true},
+	})
+	require.Len(t, assignees, 1)
+	assert.Equal(t, map[string]*assigneeScopeDTO{
+		"folders:uid:parent": {Actions: []string{"dashboards:read"}},
+		"dashboards:uid:1":   {Actions: []string{}, Deny: true},
+	}, assignees[0].Scopes)
+}
+
+func TestApi_getPermissionsETag(t *testing.T) {
+	service, usrSvc, teamSvc := setupTestEnvironment(t, testOptions)
+	server := setupTestServer(t, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), []accesscontrol.Permission{
+		{Action: "dashboards.permissions:read", Scope: "dashboards:id:1"},
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+	})}}, service)
+
+	seedPermissions(t, "1", usrSvc, teamSvc, service)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/api/access-control/dashboards/1", nil)
+		require.NoError(t, err)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	first := get("")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("should answer not modified while the permissions are unchanged", func(t *testing.T) {
+		recorder := get(etag)
+		assert.Equal(t, http.StatusNotModified, recorder.Code)
+		assert.Empty(t, recorder.Body.Bytes())
+	})
+
+	t.Run("should return the permissions once they changed", func(t *testing.T) {
+		_, err := service.SetBuiltInRolePermission(context.Background(), 1, "Viewer", "1", "View")
+		require.NoError(t, err)
+
+		recorder := get(etag)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, etag, recorder.Header().Get("ETag"))
+	})
+
+	t.Run("should return the permissions once a role assignment was removed", func(t *testing.T) {
+		current := get("").Header().Get("ETag")
+		err := service.sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("DELETE FROM user_role WHERE org_id = ?", 1)
+			return err
+		})
+		require.NoError(t, err)
+
+		recorder := get(current)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.NotEqual(t, current, recorder.Header().Get("ETag"))
+	})
+
+	t.Run("should not share the ETag between query parameters", func(t *testing.T) {
+		assert.NotEqual(t, permissionsETag("v", "u", ""), permissionsETag("v", "u", "groupByAssignee=true"))
+		assert.NotEqual(t, permissionsETag("v", "u1", ""), permissionsETag("v", "u2", ""))
+	})
+}
+
 type setBuiltinPermissionTestCase struct {
 	desc           string
 	resourceID     string
@@ -439,6 +549,77 @@ func TestApi_setUserPermission(t *testing.T) {
 	}
 }
 
+func TestApi_setBulkPermissions(t *testing.T) {
+	tests := []struct {
+		desc           string
+		resourceIDs    []string
+		permissions    []accesscontrol.Permission
+		expectedStatus int
+	}{
+		{
+			desc:           "should set the permissions of every resource",
+			resourceIDs:    []string{"1", "2"},
+			expectedStatus: http.StatusOK,
+			permissions: []accesscontrol.Permission{
+				{Action: "dashboards.permissions:read", Scope: "dashboards:id:*"},
+				{Action: "dashboards.permissions:write", Scope: "dashboards:id:*"},
+			},
+		},
+		{
+			desc:           "should return http 403 when missing permissions on one of the resources",
+			resourceIDs:    []string{"1", "2"},
+			expectedStatus: http.StatusForbidden,
+			permissions: []accesscontrol.Permission{
+				{Action: "dashboards.permissions:read", Scope: "dashboards:id:*"},
+				{Action: "dashboards.permissions:write", Scope: "dashboards:id:1"},
+			},
+		},
+		{
+			desc:           "should return http 400 without resources",
+			expectedStatus: http.StatusBadRequest,
+			permissions: []accesscontrol.Permission{
+				{Action: "dashboards.permissions:write", Scope: "dashboards:id:*"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			service, _, _ := setupTestEnvironment(t, testOptions)
+			server := setupTestServer(t, &user.SignedInUser{
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)},
+			}, service)
+
+			body, err := json.Marshal(setBulkPermissionsCommand{
+				ResourceIDs: tt.resourceIDs,
+				Permissions: []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Viewer", Permission: "View"}},
+			})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/access-control/%s/bulk", testOptions.Resource), strings.NewReader(string(body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+
+			for _, resourceID := range []string{"1", "2"} {
+				permissions, err := service.GetPermissions(context.Background(), &user.SignedInUser{
+					OrgID:       1,
+					Permissions: map[int64]map[string][]string{1: {"dashboards.permissions:read": {"dashboards:id:*"}}},
+				}, resourceID)
+				require.NoError(t, err)
+				if tt.expectedStatus == http.StatusOK {
+					require.Len(t, permissions, 1)
+					assert.Equal(t, "Viewer", permissions[0].BuiltInRole)
+				} else {
+					assert.Empty(t, permissions)
+				}
+			}
+		})
+	}
+}
+
 func setupTestServer(t *testing.T, user *user.SignedInUser, service *Service) *web.Mux {
 	server := web.New()
 	server.UseMiddleware(web.Renderer("views", "[[", "]]"))
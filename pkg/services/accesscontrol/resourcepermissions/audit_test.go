@@ -0,0 +1,109 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_GetPermissionDiff(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write"},
+		},
+	})
+
+	t.Run("should record permission changes", func(t *testing.T) {
+		usr, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "user", OrgID: 1})
+		require.NoError(t, err)
+		_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "recorded", "Edit")
+		require.NoError(t, err)
+
+		diff, err := service.GetPermissionDiff(ctx, 1, "recorded", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, diff, 1)
+		assert.Equal(t, PermissionDiff{
+			UserID:       usr.ID,
+			To:           "Edit",
+			AddedActions: []string{"dashboards:read", "dashboards:write"},
+		}, diff[0])
+	})
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []PermissionAuditEntry{
+		{UserID: 1, Permission: "View", Actions: "dashboards:read", Created: start},
+		{TeamID: 1, Permission: "View", Actions: "dashboards:read", Created: start},
+		{BuiltInRole: "Viewer", Permission: "View", Actions: "dashboards:read", Created: start},
+		{UserID: 1, Permission: "Edit", Actions: "dashboards:read,dashboards:write", Created: start.Add(2 * time.Hour)},
+		{TeamID: 1, Permission: "", Actions: "", Created: start.Add(2 * time.Hour)},
+		{GroupID: "group", Permission: "View", Actions: "dashboards:read", Created: start.Add(2 * time.Hour)},
+		{BuiltInRole: "Viewer", Permission: "", Actions: "", Created: start.Add(2 * time.Hour)},
+		{BuiltInRole: "Viewer", Permission: "View", Actions: "dashboards:read", Created: start.Add(3 * time.Hour)},
+		{UserID: 2, Permission: "View", Actions: "dashboards:read", Created: start.Add(10 * time.Hour)},
+	}
+	err := service.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for i := range entries {
+			entries[i].OrgID = 1
+			entries[i].Resource = "dashboards"
+			entries[i].ResourceID = "dash"
+			if _, err := sess.Insert(&entries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("should return the changes of the period", func(t *testing.T) {
+		diff, err := service.GetPermissionDiff(ctx, 1, "dash", start.Add(time.Hour), start.Add(5*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []PermissionDiff{
+			{UserID: 1, From: "View", To: "Edit", AddedActions: []string{"dashboards:write"}},
+			{TeamID: 1, From: "View", To: "", RemovedActions: []string{"dashboards:read"}},
+			{GroupID: "group", From: "", To: "View", AddedActions: []string{"dashboards:read"}},
+		}, diff)
+	})
+
+	t.Run("should reject an empty period", func(t *testing.T) {
+		_, err := service.GetPermissionDiff(ctx, 1, "dash", start, start)
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("should reject a period longer than the maximum", func(t *testing.T) {
+		_, err := service.GetPermissionDiff(ctx, 1, "dash", start, start.Add(maxPermissionDiffPeriod+time.Hour))
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("should record the removal of the permissions of a deleted resource", func(t *testing.T) {
+		usr, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "deleted", OrgID: 1})
+		require.NoError(t, err)
+		_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "deleted", "View")
+		require.NoError(t, err)
+
+		// Timestamps are stored with a second precision, the permission is set before the period starts
+		err = service.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE permission_audit SET created = ? WHERE resource_id = ?", time.Now().Add(-2*time.Hour), "deleted")
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, service.DeleteResourcePermissions(ctx, 1, "deleted"))
+
+		diff, err := service.GetPermissionDiff(ctx, 1, "deleted", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []PermissionDiff{
+			{UserID: usr.ID, From: "View", To: "", RemovedActions: []string{"dashboards:read"}},
+		}, diff)
+	})
+}
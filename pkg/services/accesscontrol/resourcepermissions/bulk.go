@@ -0,0 +1,63 @@
+package resourcepermissions
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// BulkPermissionsTarget is a resource whose permissions are set by SetBulkPermissions.
+type BulkPermissionsTarget struct {
+	// Service manages the permissions of the kind of the resource, e.g. the folder or the dashboard permissions service
+	Service    *Service
+	ResourceID string
+	Commands   []accesscontrol.SetResourcePermissionCommand
+}
+
+// SetBulkPermissions sets the permissions of many resources, possibly of different kinds, in a single transaction
+// so rollouts across a folder tree are applied completely or not at all. Every target is validated by its service
+// before anything is written. Once committed, the post commit hooks of each service are called, their errors are
+// logged.
+func SetBulkPermissions(ctx context.Context, orgID int64, targets ...BulkPermissionsTarget) ([]accesscontrol.ResourcePermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetBulkPermissions")
+	defer span.End()
+
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	batches := make([]ResourcePermissionsBatch, 0, len(targets))
+	for _, t := range targets {
+		if t.Service == nil {
+			return nil, ErrInvalidParam.Build(ErrInvalidParamData("service", errors.New("targets need the service managing their resource")))
+		}
+		if err := t.Service.checkWritable(); err != nil {
+			return nil, err
+		}
+		if err := t.Service.validateResource(ctx, orgID, t.ResourceID); err != nil {
+			return nil, err
+		}
+
+		commands, err := t.Service.toDBCommands(ctx, orgID, t.ResourceID, t.Commands)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, ResourcePermissionsBatch{Commands: commands, Hooks: t.Service.resourceHooks()})
+	}
+
+	// The services of the targets share the database, the store of any of them sets the permissions of all of them
+	first := targets[0].Service
+	resourcePermissions, err := first.store.SetResourcePermissionsBatches(ctx, orgID, batches)
+	if err != nil {
+		return nil, err
+	}
+
+	// The permissions are committed, failing hooks can't undo them and are only logged
+	for i, t := range targets {
+		if err := t.Service.options.PostCommitHooks.run(ctx, orgID, batches[i].Commands, t.Service.options.PostCommitHookConcurrency); err != nil {
+			t.Service.log.Warn("Post commit hooks failed", "resource", t.Service.options.Resource, "resourceID", t.ResourceID, "error", err)
+		}
+	}
+	return resourcePermissions, nil
+}
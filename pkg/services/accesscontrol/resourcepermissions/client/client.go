@@ -0,0 +1,419 @@
+// Package client is a typed client of the resource permissions HTTP API, `/api/access-control/{resource}`.
+// It is meant for services and tools managing the permissions of Grafana resources, e.g. the Terraform provider,
+// instead of hand-rolled HTTP calls. The API has no dry-run of writes, callers previewing a change compare the
+// permissions returned by Get with the commands they are about to send.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// idempotencyKeyHeader is the header carrying the idempotency key of a write, see SetOptions
+	idempotencyKeyHeader = "Idempotency-Key"
+	orgIDHeader          = "X-Grafana-Org-Id"
+	// totalCountHeader is the header carrying the number of assignments across all pages
+	totalCountHeader = "X-Total-Count"
+)
+
+// ErrNotModified is returned when the permissions didn't change since the ETag of GetOptions.
+var ErrNotModified = errors.New("permissions not modified")
+
+// APIError is the error returned by the API, its message is the message of the response.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// MessageID identifies the kind of error, e.g. resourcePermissions.invalidParam, it is not set by every endpoint
+	MessageID string
+}
+
+func (e *APIError) Error() string {
+	if e.MessageID != "" {
+		return fmt.Sprintf("resource permissions API error %d (%s): %s", e.StatusCode, e.MessageID, e.Message)
+	}
+	return fmt.Sprintf("resource permissions API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if err is an APIError with a 404 status.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type Config struct {
+	// URL of the Grafana instance, including the sub path it is served from
+	URL string
+	// Token is a service account token, it is sent as a bearer token
+	Token string
+	// BasicAuth is used when Token is empty
+	BasicAuth *url.Userinfo
+	// OrgID is the organization of the requests, the default organization of the identity is used when zero
+	OrgID int64
+	// HTTPClient defaults to a client with a 30 seconds timeout
+	HTTPClient *http.Client
+}
+
+// Client calls the resource permissions API of a Grafana instance.
+type Client struct {
+	url        *url.URL
+	token      string
+	basicAuth  *url.Userinfo
+	orgID      int64
+	httpClient *http.Client
+}
+
+func New(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: scheme and host are required", cfg.URL)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		url:        u,
+		token:      cfg.Token,
+		basicAuth:  cfg.BasicAuth,
+		orgID:      cfg.OrgID,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Resource returns the client of the permissions of a kind of resource, e.g. dashboards, folders or datasources.
+func (c *Client) Resource(resource string) *ResourceClient {
+	return &ResourceClient{client: c, resource: resource}
+}
+
+// ResourceClient manages the permissions of a kind of resource.
+type ResourceClient struct {
+	client   *Client
+	resource string
+}
+
+// Describe returns the assignments and permissions supported by the resource.
+func (r *ResourceClient) Describe(ctx context.Context) (*Description, error) {
+	var description Description
+	if _, err := r.client.do(ctx, http.MethodGet, r.path("description"), nil, nil, nil, &description); err != nil {
+		return nil, err
+	}
+	return &description, nil
+}
+
+type GetOptions struct {
+	// ExpandActionSets includes the actions granted by action sets
+	ExpandActionSets bool
+	// Permission only includes the assignments granting at least this permission
+	Permission string
+	// ETag of a previous response, ErrNotModified is returned when the permissions didn't change
+	ETag string
+	// Limit is the maximum number of assignments returned, all assignments are returned when zero
+	Limit int
+	// Offset is the number of assignments skipped before the first one returned
+	Offset int
+}
+
+func (o GetOptions) query() url.Values {
+	query := url.Values{}
+	if o.ExpandActionSets {
+		query.Set("expandActionSets", "true")
+	}
+	if o.Permission != "" {
+		query.Set("permission", o.Permission)
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query
+}
+
+func (o GetOptions) header() http.Header {
+	header := http.Header{}
+	if o.ETag != "" {
+		header.Set("If-None-Match", o.ETag)
+	}
+	return header
+}
+
+// Get returns the permissions of the resource and the ETag of the response.
+func (r *ResourceClient) Get(ctx context.Context, resourceID string, opts GetOptions) ([]ResourcePermission, string, error) {
+	page, err := r.GetPage(ctx, resourceID, opts)
+	if err != nil {
+		return nil, page.ETag, err
+	}
+	return page.Permissions, page.ETag, nil
+}
+
+// GetPage returns the page of the permissions of the resource selected by the limit and offset of the options,
+// together with the number of assignments across all pages.
+func (r *ResourceClient) GetPage(ctx context.Context, resourceID string, opts GetOptions) (*PermissionsPage, error) {
+	page := &PermissionsPage{}
+	res, err := r.client.do(ctx, http.MethodGet, r.path(resourceID), opts.query(), opts.header(), nil, &page.Permissions)
+	page.ETag = etag(res)
+	if err != nil {
+		return page, err
+	}
+	if page.TotalCount, err = totalCount(res); err != nil {
+		return page, err
+	}
+	return page, nil
+}
+
+// GetByAssignee returns the actions granted to each assignee on the scopes of the resource and the ETag of the response.
+func (r *ResourceClient) GetByAssignee(ctx context.Context, resourceID string, opts GetOptions) ([]AssigneePermissions, string, error) {
+	query := opts.query()
+	query.Set("groupByAssignee", "true")
+
+	var permissions []AssigneePermissions
+	res, err := r.client.do(ctx, http.MethodGet, r.path(resourceID), query, opts.header(), nil, &permissions)
+	if err != nil {
+		return nil, etag(res), err
+	}
+	return permissions, etag(res), nil
+}
+
+// Diff returns the changes of the permissions of the resource between from and to, to defaults to now when zero.
+func (r *ResourceClient) Diff(ctx context.Context, resourceID string, from, to time.Time) ([]PermissionDiff, error) {
+	query := url.Values{}
+	query.Set("from", strconv.FormatInt(from.UnixMilli(), 10))
+	if !to.IsZero() {
+		query.Set("to", strconv.FormatInt(to.UnixMilli(), 10))
+	}
+
+	var diff []PermissionDiff
+	if _, err := r.client.do(ctx, http.MethodGet, r.path(resourceID, "diff"), query, nil, nil, &diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+type SetOptions struct {
+	// IdempotencyKey makes retries of the write return the result of the first write instead of applying it again
+	IdempotencyKey string
+}
+
+// Set sets the permissions of many assignees on the resource.
+func (r *ResourceClient) Set(ctx context.Context, resourceID string, opts SetOptions, commands ...SetPermissionCommand) error {
+	header := http.Header{}
+	if opts.IdempotencyKey != "" {
+		header.Set(idempotencyKeyHeader, opts.IdempotencyKey)
+	}
+	body := struct {
+		Permissions []SetPermissionCommand `json:"permissions"`
+	}{Permissions: commands}
+
+	_, err := r.client.do(ctx, http.MethodPost, r.path(resourceID), nil, header, body, nil)
+	return err
+}
+
+// SetUser sets the permission of a user or service account on the resource, an empty permission removes it.
+func (r *ResourceClient) SetUser(ctx context.Context, resourceID string, userID int64, permission string) error {
+	return r.setAssignee(ctx, r.path(resourceID, "users", strconv.FormatInt(userID, 10)), permission)
+}
+
+// SetTeam sets the permission of a team on the resource, an empty permission removes it.
+func (r *ResourceClient) SetTeam(ctx context.Context, resourceID string, teamID int64, permission string) error {
+	return r.setAssignee(ctx, r.path(resourceID, "teams", strconv.FormatInt(teamID, 10)), permission)
+}
+
+// SetBuiltInRole sets the permission of a basic role on the resource, an empty permission removes it.
+func (r *ResourceClient) SetBuiltInRole(ctx context.Context, resourceID, builtInRole, permission string) error {
+	return r.setAssignee(ctx, r.path(resourceID, "builtInRoles", builtInRole), permission)
+}
+
+func (r *ResourceClient) setAssignee(ctx context.Context, path, permission string) error {
+	body := struct {
+		Permission string `json:"permission"`
+	}{Permission: permission}
+	_, err := r.client.do(ctx, http.MethodPost, path, nil, nil, body, nil)
+	return err
+}
+
+// GrantTemporaryUser grants the permission to a user until the duration elapsed.
+func (r *ResourceClient) GrantTemporaryUser(ctx context.Context, resourceID string, userID int64, permission string, duration time.Duration) error {
+	body := struct {
+		Permission string `json:"permission"`
+		Duration   string `json:"duration"`
+	}{Permission: permission, Duration: duration.String()}
+	_, err := r.client.do(ctx, http.MethodPost, r.path(resourceID, "users", strconv.FormatInt(userID, 10), "temporary"), nil, nil, body, nil)
+	return err
+}
+
+// SetMetadata replaces the metadata of the permission of an assignee on the resource.
+func (r *ResourceClient) SetMetadata(ctx context.Context, resourceID string, cmd SetPermissionMetadataCommand) error {
+	_, err := r.client.do(ctx, http.MethodPut, r.path(resourceID, "metadata"), nil, nil, cmd, nil)
+	return err
+}
+
+// StartJob starts a job setting the same permissions on every resource, the job runs in the background.
+func (r *ResourceClient) StartJob(ctx context.Context, resourceIDs []string, commands ...SetPermissionCommand) (*PermissionJob, error) {
+	body := struct {
+		ResourceIDs []string               `json:"resourceIds"`
+		Permissions []SetPermissionCommand `json:"permissions"`
+	}{ResourceIDs: resourceIDs, Permissions: commands}
+
+	var job PermissionJob
+	if _, err := r.client.do(ctx, http.MethodPost, r.path("jobs"), nil, nil, body, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob returns the progress of a job.
+func (r *ResourceClient) GetJob(ctx context.Context, jobID int64) (*PermissionJob, error) {
+	var job PermissionJob
+	if _, err := r.client.do(ctx, http.MethodGet, r.path("jobs", strconv.FormatInt(jobID, 10)), nil, nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitJob polls the job every interval until it is done or ctx is done.
+func (r *ResourceClient) WaitJob(ctx context.Context, jobID int64, interval time.Duration) (*PermissionJob, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s: the interval must be positive", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := r.GetJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if job.Done() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ResourceClient) path(elems ...string) string {
+	escaped := make([]string, 0, len(elems)+1)
+	escaped = append(escaped, url.PathEscape(r.resource))
+	for _, e := range elems {
+		escaped = append(escaped, url.PathEscape(e))
+	}
+	return "api/access-control/" + strings.Join(escaped, "/")
+}
+
+func etag(res *http.Response) string {
+	if res == nil {
+		return ""
+	}
+	return res.Header.Get("ETag")
+}
+
+// totalCount returns the number of assignments across all pages of the response, -1 when the API doesn't send it.
+func totalCount(res *http.Response) (int64, error) {
+	value := res.Header.Get(totalCountHeader)
+	if value == "" {
+		return -1, nil
+	}
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s header %q: %w", totalCountHeader, value, err)
+	}
+	return count, nil
+}
+
+// do sends the request and decodes the response into out unless it is nil. The response is returned with its body
+// closed for the callers reading its headers.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out any) (*http.Response, error) {
+	u := c.url.JoinPath(path)
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.orgID != 0 {
+		req.Header.Set(orgIDHeader, strconv.FormatInt(c.orgID, 10))
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.basicAuth != nil {
+		password, _ := c.basicAuth.Password()
+		req.SetBasicAuth(c.basicAuth.Username(), password)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusNotModified {
+		return res, ErrNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, readError(res)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return res, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return res, nil
+}
+
+func readError(res *http.Response) error {
+	apiErr := &APIError{StatusCode: res.StatusCode}
+
+	var body struct {
+		Message   string `json:"message"`
+		MessageID string `json:"messageId"`
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err == nil && json.Unmarshal(data, &body) == nil {
+		apiErr.Message = body.Message
+		apiErr.MessageID = body.MessageID
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(res.StatusCode)
+	}
+	return apiErr
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *ResourceClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(Config{URL: server.URL + "/grafana", Token: "token", OrgID: 2})
+	require.NoError(t, err)
+	return c.Resource("dashboards")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{URL: "localhost:3000"})
+	require.Error(t, err)
+
+	_, err = New(Config{URL: "http://localhost:3000"})
+	require.NoError(t, err)
+}
+
+func TestResourceClient_Get(t *testing.T) {
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/grafana/api/access-control/dashboards/dash%2F1", req.URL.EscapedPath())
+		assert.Equal(t, "true", req.URL.Query().Get("expandActionSets"))
+		assert.Equal(t, "Edit", req.URL.Query().Get("permission"))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		assert.Equal(t, "2", req.Header.Get(orgIDHeader))
+
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode([]ResourcePermission{{UserID: 1, Permission: "Edit", Actions: []string{"dashboards:write"}}})
+	})
+
+	permissions, etag, err := r.Get(context.Background(), "dash/1", GetOptions{ExpandActionSets: true, Permission: "Edit"})
+	require.NoError(t, err)
+	require.Equal(t, `"v1"`, etag)
+	require.Len(t, permissions, 1)
+	require.Equal(t, "Edit", permissions[0].Permission)
+
+	_, _, err = r.Get(context.Background(), "dash/1", GetOptions{ExpandActionSets: true, Permission: "Edit", ETag: etag})
+	require.ErrorIs(t, err, ErrNotModified)
+}
+
+func TestResourceClient_GetPage(t *testing.T) {
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "2", req.URL.Query().Get("limit"))
+		assert.Equal(t, "4", req.URL.Query().Get("offset"))
+		w.Header().Set(totalCountHeader, "5")
+		_ = json.NewEncoder(w).Encode([]ResourcePermission{{BuiltInRole: "Viewer", Permission: "View"}})
+	})
+
+	page, err := r.GetPage(context.Background(), "1", GetOptions{Limit: 2, Offset: 4})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), page.TotalCount)
+	require.Len(t, page.Permissions, 1)
+}
+
+func TestResourceClient_Set(t *testing.T) {
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/grafana/api/access-control/dashboards/dash1", req.URL.Path)
+		assert.Equal(t, "key", req.Header.Get(idempotencyKeyHeader))
+
+		var body struct {
+			Permissions []SetPermissionCommand `json:"permissions"`
+		}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, []SetPermissionCommand{{TeamID: 1, Permission: "View"}, {BuiltinRole: "Viewer"}}, body.Permissions)
+		_, _ = w.Write([]byte(`{"message":"Permissions updated"}`))
+	})
+
+	err := r.Set(context.Background(), "dash1", SetOptions{IdempotencyKey: "key"},
+		SetPermissionCommand{TeamID: 1, Permission: "View"},
+		SetPermissionCommand{BuiltinRole: "Viewer"},
+	)
+	require.NoError(t, err)
+}
+
+func TestResourceClient_Diff(t *testing.T) {
+	from := time.UnixMilli(1000)
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/grafana/api/access-control/dashboards/dash1/diff", req.URL.Path)
+		assert.Equal(t, "1000", req.URL.Query().Get("from"))
+		assert.False(t, req.URL.Query().Has("to"))
+		_ = json.NewEncoder(w).Encode([]PermissionDiff{{UserID: 1, From: "View", To: "Edit"}})
+	})
+
+	diff, err := r.Diff(context.Background(), "dash1", from, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []PermissionDiff{{UserID: 1, From: "View", To: "Edit"}}, diff)
+}
+
+func TestResourceClient_WaitJob(t *testing.T) {
+	polls := 0
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/grafana/api/access-control/dashboards/jobs/3", req.URL.Path)
+		polls++
+		status := PermissionJobRunning
+		if polls == 2 {
+			status = PermissionJobCompleted
+		}
+		_ = json.NewEncoder(w).Encode(PermissionJob{ID: 3, Status: status})
+	})
+
+	job, err := r.WaitJob(context.Background(), 3, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, PermissionJobCompleted, job.Status)
+	require.Equal(t, 2, polls)
+
+	_, err = r.WaitJob(context.Background(), 3, 0)
+	require.Error(t, err)
+	require.Equal(t, 2, polls)
+}
+
+func TestResourceClient_Error(t *testing.T) {
+	r := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Dashboard not found","messageId":"dashboards.notFound"}`))
+	})
+
+	err := r.SetUser(context.Background(), "dash1", 1, "View")
+	require.Error(t, err)
+	require.True(t, IsNotFound(err))
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "Dashboard not found", apiErr.Message)
+	require.Equal(t, "dashboards.notFound", apiErr.MessageID)
+}
@@ -0,0 +1,133 @@
+package client
+
+import "time"
+
+// The types mirror the payloads of the resource permissions API, they are declared here so the client can be used
+// without depending on the packages of the server.
+
+type Assignments struct {
+	Users           bool `json:"users"`
+	ServiceAccounts bool `json:"serviceAccounts"`
+	Teams           bool `json:"teams"`
+	BuiltInRoles    bool `json:"builtInRoles"`
+	Groups          bool `json:"groups"`
+}
+
+// Description lists the assignments and the permissions supported by a kind of resource.
+type Description struct {
+	Assignments Assignments `json:"assignments"`
+	Permissions []string    `json:"permissions"`
+}
+
+type ResourcePermission struct {
+	// ID is the id of one of the rows of the permission, it changes when the permission is updated
+	ID int64 `json:"id"`
+	// StableID identifies the permission by its scope and assignee and does not change when the permission is updated
+	StableID         string              `json:"stableId"`
+	RoleName         string              `json:"roleName"`
+	IsManaged        bool                `json:"isManaged"`
+	IsInherited      bool                `json:"isInherited"`
+	IsServiceAccount bool                `json:"isServiceAccount"`
+	UserID           int64               `json:"userId,omitempty"`
+	UserLogin        string              `json:"userLogin,omitempty"`
+	UserAvatarURL    string              `json:"userAvatarUrl,omitempty"`
+	Team             string              `json:"team,omitempty"`
+	TeamID           int64               `json:"teamId,omitempty"`
+	TeamAvatarURL    string              `json:"teamAvatarUrl,omitempty"`
+	BuiltInRole      string              `json:"builtInRole,omitempty"`
+	Actions          []string            `json:"actions"`
+	Permission       string              `json:"permission"`
+	ActionSets       map[string][]string `json:"actionSets,omitempty"`
+	IsTemporary      bool                `json:"isTemporary,omitempty"`
+	Expires          *time.Time          `json:"expires,omitempty"`
+	Metadata         map[string]string   `json:"metadata,omitempty"`
+}
+
+// PermissionsPage is a page of the permissions of a resource.
+type PermissionsPage struct {
+	Permissions []ResourcePermission
+	// TotalCount is the number of assignments across all pages, -1 when the API didn't return it
+	TotalCount int64
+	// ETag of the response, see GetOptions
+	ETag string
+}
+
+// AssigneePermissions are the actions granted to an assignee on each scope, they are returned when the permissions
+// are grouped by assignee.
+type AssigneePermissions struct {
+	UserID           int64               `json:"userId,omitempty"`
+	UserLogin        string              `json:"userLogin,omitempty"`
+	UserAvatarURL    string              `json:"userAvatarUrl,omitempty"`
+	Team             string              `json:"team,omitempty"`
+	TeamID           int64               `json:"teamId,omitempty"`
+	TeamAvatarURL    string              `json:"teamAvatarUrl,omitempty"`
+	BuiltInRole      string              `json:"builtInRole,omitempty"`
+	IsServiceAccount bool                `json:"isServiceAccount"`
+	Scopes           map[string][]string `json:"scopes"`
+}
+
+// PermissionDiff is the change of the permission of an assignee over a period.
+type PermissionDiff struct {
+	UserID         int64    `json:"userId,omitempty"`
+	TeamID         int64    `json:"teamId,omitempty"`
+	BuiltInRole    string   `json:"builtInRole,omitempty"`
+	GroupID        string   `json:"groupId,omitempty"`
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	AddedActions   []string `json:"addedActions,omitempty"`
+	RemovedActions []string `json:"removedActions,omitempty"`
+}
+
+// SetPermissionCommand sets the permission of a user, team or basic role, an empty permission removes it.
+type SetPermissionCommand struct {
+	UserID      int64  `json:"userId,omitempty"`
+	TeamID      int64  `json:"teamId,omitempty"`
+	BuiltinRole string `json:"builtInRole,omitempty"`
+	Permission  string `json:"permission"`
+}
+
+// SetPermissionMetadataCommand replaces the metadata of the permission of a user, team or basic role.
+type SetPermissionMetadataCommand struct {
+	UserID      int64             `json:"userId,omitempty"`
+	TeamID      int64             `json:"teamId,omitempty"`
+	BuiltinRole string            `json:"builtInRole,omitempty"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+type PermissionJobStatus string
+
+const (
+	PermissionJobPending   PermissionJobStatus = "pending"
+	PermissionJobRunning   PermissionJobStatus = "running"
+	PermissionJobCompleted PermissionJobStatus = "completed"
+	PermissionJobFailed    PermissionJobStatus = "failed"
+)
+
+// PermissionJob is the progress of a job setting the permissions of many resources.
+type PermissionJob struct {
+	ID        int64               `json:"id"`
+	OrgID     int64               `json:"orgId"`
+	Resource  string              `json:"resource"`
+	Status    PermissionJobStatus `json:"status"`
+	Total     int                 `json:"total"`
+	Processed int                 `json:"processed"`
+	Failed    int                 `json:"failed"`
+	CreatedBy int64               `json:"createdBy"`
+	Created   time.Time           `json:"created"`
+	Updated   time.Time           `json:"updated"`
+
+	Errors []PermissionJobItem `json:"errors,omitempty"`
+}
+
+// Done returns true once the job stopped processing resources.
+func (j *PermissionJob) Done() bool {
+	return j.Status == PermissionJobCompleted || j.Status == PermissionJobFailed
+}
+
+// PermissionJobItem is the progress of a job on a single resource.
+type PermissionJobItem struct {
+	ResourceID string              `json:"resourceId"`
+	Status     PermissionJobStatus `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Updated    time.Time           `json:"updated"`
+}
@@ -0,0 +1,192 @@
+package resourcepermissions
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+//go:embed default_permissions.yaml
+var builtinDefaultPermissionsPolicy []byte
+
+// DefaultPermissionsPolicy decides the permissions set on resources when they are created. The grants of the first
+// rule matching the created resource are set.
+type DefaultPermissionsPolicy struct {
+	Rules []DefaultPermissionsRule `yaml:"rules"`
+}
+
+type DefaultPermissionsRule struct {
+	// Resource is the kind of resource the rule applies to, e.g. dashboards or datasources
+	Resource string `yaml:"resource"`
+	// InFolder restricts the rule to resources created in a folder when true and outside of a folder when false
+	InFolder *bool `yaml:"inFolder"`
+	// Provisioned restricts the rule to provisioned resources when true and to other resources when false
+	Provisioned *bool `yaml:"provisioned"`
+	// Grants are the permissions set on the resource, an empty list sets no permission
+	Grants []DefaultPermissionsGrant `yaml:"grants"`
+}
+
+// DefaultPermissionsGrant grants a permission to one kind of assignee.
+type DefaultPermissionsGrant struct {
+	// Creator grants the permission to the user creating the resource
+	Creator bool `yaml:"creator"`
+	// CreatorTeams grants the permission to every team of the user creating the resource
+	CreatorTeams bool   `yaml:"creatorTeams"`
+	BuiltInRole  string `yaml:"builtInRole"`
+	Permission   string `yaml:"permission"`
+}
+
+// ProvideDefaultPermissionsPolicy loads the default permissions policy once for the permission services of every
+// resource.
+func ProvideDefaultPermissionsPolicy(cfg *setting.Cfg) (*DefaultPermissionsPolicy, error) {
+	return LoadDefaultPermissionsPolicy(cfg.RBAC.DefaultPermissionsPolicyFile)
+}
+
+// LoadDefaultPermissionsPolicy returns the built-in policy preceded by the rules of the policy file, the built-in
+// policy is returned when path is empty.
+func LoadDefaultPermissionsPolicy(path string) (*DefaultPermissionsPolicy, error) {
+	policy, err := parseDefaultPermissionsPolicy(builtinDefaultPermissionsPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid built-in default permissions policy: %w", err)
+	}
+	if path == "" {
+		return policy, nil
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path comes from the Grafana configuration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default permissions policy: %w", err)
+	}
+	configured, err := parseDefaultPermissionsPolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default permissions policy %s: %w", path, err)
+	}
+
+	return &DefaultPermissionsPolicy{Rules: append(configured.Rules, policy.Rules...)}, nil
+}
+
+func parseDefaultPermissionsPolicy(data []byte) (*DefaultPermissionsPolicy, error) {
+	var policy DefaultPermissionsPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, err
+	}
+	for i, rule := range policy.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &policy, nil
+}
+
+func (r DefaultPermissionsRule) validate() error {
+	if r.Resource == "" {
+		return errors.New("resource is required")
+	}
+	for _, g := range r.Grants {
+		assignees := 0
+		for _, set := range []bool{g.Creator, g.CreatorTeams, g.BuiltInRole != ""} {
+			if set {
+				assignees++
+			}
+		}
+		if assignees != 1 {
+			return errors.New("grants must have exactly one of creator, creatorTeams or builtInRole")
+		}
+		if g.Permission == "" {
+			return errors.New("grants must have a permission")
+		}
+	}
+	return nil
+}
+
+func (r DefaultPermissionsRule) matches(resource string, attrs accesscontrol.DefaultPermissionsAttributes) bool {
+	if r.Resource != resource {
+		return false
+	}
+	if r.InFolder != nil && *r.InFolder != (attrs.ParentUID != "") {
+		return false
+	}
+	if r.Provisioned != nil && *r.Provisioned != attrs.Provisioned {
+		return false
+	}
+	return true
+}
+
+// GrantsCreatorTeams returns true when a rule of the resource grants a permission to the teams of the creator.
+func (p *DefaultPermissionsPolicy) GrantsCreatorTeams(resource string) bool {
+	if p == nil {
+		return false
+	}
+	for _, rule := range p.Rules {
+		if rule.Resource == resource && slices.ContainsFunc(rule.Grants, func(g DefaultPermissionsGrant) bool { return g.CreatorTeams }) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatorTeamsFunc returns the teams of the creator of a resource.
+type CreatorTeamsFunc func(ctx context.Context, orgID, userID int64) ([]int64, error)
+
+// Commands returns the permissions granted by the first rule matching the created resource. The creator and its teams
+// are only granted permissions on resources created by a user that aren't provisioned, the grants to the teams of the
+// creator are skipped when creatorTeams is nil.
+func (p *DefaultPermissionsPolicy) Commands(
+	ctx context.Context, orgID int64, resource string, attrs accesscontrol.DefaultPermissionsAttributes, creatorTeams CreatorTeamsFunc,
+) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	if p == nil {
+		return nil, nil
+	}
+	for _, rule := range p.Rules {
+		if !rule.matches(resource, attrs) {
+			continue
+		}
+
+		hasCreator := attrs.CreatorID != 0 && !attrs.Provisioned
+		commands := make([]accesscontrol.SetResourcePermissionCommand, 0, len(rule.Grants))
+		for _, g := range rule.Grants {
+			switch {
+			case g.Creator && hasCreator:
+				commands = append(commands, accesscontrol.SetResourcePermissionCommand{UserID: attrs.CreatorID, Permission: g.Permission})
+			case g.CreatorTeams && hasCreator && creatorTeams != nil:
+				teamIDs, err := creatorTeams(ctx, orgID, attrs.CreatorID)
+				if err != nil {
+					return nil, err
+				}
+				for _, teamID := range teamIDs {
+					commands = append(commands, accesscontrol.SetResourcePermissionCommand{TeamID: teamID, Permission: g.Permission})
+				}
+			case g.BuiltInRole != "":
+				commands = append(commands, accesscontrol.SetResourcePermissionCommand{BuiltinRole: g.BuiltInRole, Permission: g.Permission})
+			}
+		}
+		return commands, nil
+	}
+	return nil, nil
+}
+
+// DefaultPermissions returns the permissions the default permissions policy grants on a newly created resource.
+func (s *Service) DefaultPermissions(ctx context.Context, orgID int64, attrs accesscontrol.DefaultPermissionsAttributes) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.DefaultPermissions")
+	defer span.End()
+
+	return s.defaultPolicy.Commands(ctx, orgID, s.options.Resource, attrs, s.creatorTeams)
+}
+
+func (s *Service) creatorTeams(ctx context.Context, orgID, userID int64) ([]int64, error) {
+	if s.teamService == nil {
+		return nil, nil
+	}
+	return s.teamService.GetTeamIDsByUser(ctx, &team.GetTeamIDsByUserQuery{OrgID: orgID, UserID: userID})
+}
@@ -0,0 +1,159 @@
+package resourcepermissions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestLoadDefaultPermissionsPolicy(t *testing.T) {
+	writePolicy := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("should load the built-in policy without a policy file", func(t *testing.T) {
+		policy, err := LoadDefaultPermissionsPolicy("")
+		require.NoError(t, err)
+		require.NotEmpty(t, policy.Rules)
+	})
+
+	t.Run("should evaluate the rules of the policy file before the built-in rules", func(t *testing.T) {
+		policy, err := LoadDefaultPermissionsPolicy(writePolicy(t, `
+rules:
+  - resource: datasources
+    grants:
+      - creator: true
+        permission: Admin
+`))
+		require.NoError(t, err)
+
+		commands, err := policy.Commands(context.Background(), 1, "datasources", accesscontrol.DefaultPermissionsAttributes{CreatorID: 2}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{{UserID: 2, Permission: "Admin"}}, commands)
+	})
+
+	t.Run("should report the grants to the teams of the creator", func(t *testing.T) {
+		policy, err := LoadDefaultPermissionsPolicy(writePolicy(t, `
+rules:
+  - resource: datasources
+    grants:
+      - creatorTeams: true
+        permission: Query
+`))
+		require.NoError(t, err)
+		require.True(t, policy.GrantsCreatorTeams("datasources"))
+		require.False(t, policy.GrantsCreatorTeams("dashboards"))
+	})
+
+	t.Run("should fail for invalid rules", func(t *testing.T) {
+		for _, content := range []string{
+			"rules:\n  - grants: []",
+			"rules:\n  - resource: dashboards\n    grants:\n      - permission: View",
+			"rules:\n  - resource: dashboards\n    grants:\n      - creator: true\n        builtInRole: Viewer\n        permission: View",
+			"rules:\n  - resource: dashboards\n    grants:\n      - creator: true",
+		} {
+			_, err := LoadDefaultPermissionsPolicy(writePolicy(t, content))
+			require.Error(t, err, content)
+		}
+	})
+
+	t.Run("should fail for missing policy files", func(t *testing.T) {
+		_, err := LoadDefaultPermissionsPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+	})
+}
+
+func TestDefaultPermissionsPolicy_Commands(t *testing.T) {
+	ctx := context.Background()
+	policy, err := LoadDefaultPermissionsPolicy("")
+	require.NoError(t, err)
+
+	type testCase struct {
+		desc     string
+		resource string
+		attrs    accesscontrol.DefaultPermissionsAttributes
+		expected []accesscontrol.SetResourcePermissionCommand
+	}
+
+	tests := []testCase{
+		{
+			desc:     "should grant the creator and basic roles access to dashboards at the root",
+			resource: "dashboards",
+			attrs:    accesscontrol.DefaultPermissionsAttributes{CreatorID: 1},
+			expected: []accesscontrol.SetResourcePermissionCommand{
+				{UserID: 1, Permission: "Admin"},
+				{BuiltinRole: "Editor", Permission: "Edit"},
+				{BuiltinRole: "Viewer", Permission: "View"},
+			},
+		},
+		{
+			desc:     "should only grant the creator access to dashboards in a folder",
+			resource: "dashboards",
+			attrs:    accesscontrol.DefaultPermissionsAttributes{CreatorID: 1, ParentUID: "folder"},
+			expected: []accesscontrol.SetResourcePermissionCommand{{UserID: 1, Permission: "Admin"}},
+		},
+		{
+			desc:     "should not grant access to the creator of provisioned folders",
+			resource: "folders",
+			attrs:    accesscontrol.DefaultPermissionsAttributes{CreatorID: 1, Provisioned: true},
+			expected: []accesscontrol.SetResourcePermissionCommand{
+				{BuiltinRole: "Editor", Permission: "Edit"},
+				{BuiltinRole: "Viewer", Permission: "View"},
+			},
+		},
+		{
+			desc:     "should grant basic roles query access to data sources",
+			resource: "datasources",
+			attrs:    accesscontrol.DefaultPermissionsAttributes{},
+			expected: []accesscontrol.SetResourcePermissionCommand{
+				{BuiltinRole: "Editor", Permission: "Query"},
+				{BuiltinRole: "Viewer", Permission: "Query"},
+			},
+		},
+		{
+			desc:     "should not grant access to resources without rules",
+			resource: "serviceaccounts",
+			attrs:    accesscontrol.DefaultPermissionsAttributes{CreatorID: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			commands, err := policy.Commands(ctx, 1, tt.resource, tt.attrs, nil)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.expected, commands)
+		})
+	}
+
+	t.Run("should grant access to the teams of the creator", func(t *testing.T) {
+		inFolder := true
+		policy := &DefaultPermissionsPolicy{Rules: []DefaultPermissionsRule{{
+			Resource: "dashboards",
+			InFolder: &inFolder,
+			Grants:   []DefaultPermissionsGrant{{CreatorTeams: true, Permission: "Edit"}},
+		}}}
+		teams := func(ctx context.Context, orgID, userID int64) ([]int64, error) {
+			require.Equal(t, int64(1), userID)
+			return []int64{10, 11}, nil
+		}
+
+		commands, err := policy.Commands(ctx, 1, "dashboards", accesscontrol.DefaultPermissionsAttributes{CreatorID: 1, ParentUID: "folder"}, teams)
+		require.NoError(t, err)
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{
+			{TeamID: 10, Permission: "Edit"},
+			{TeamID: 11, Permission: "Edit"},
+		}, commands)
+
+		commands, err = policy.Commands(ctx, 1, "dashboards", accesscontrol.DefaultPermissionsAttributes{CreatorID: 1}, teams)
+		require.NoError(t, err)
+		require.Empty(t, commands, "rules only apply to the resources matching their attributes")
+	})
+}
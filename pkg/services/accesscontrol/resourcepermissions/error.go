@@ -9,6 +9,7 @@ const (
 	invalidAssignmentMessage = `Assignment [{{ .Public.assignment }}] is invalid for this resource type`
 	invalidParamMessage      = `Param [{{ .Public.param }}] is invalid`
 	invalidRequestBody       = `Request body is invalid: {{ .Public.reason }}`
+	readOnlyMessage          = `Permissions are read-only during maintenance`
 )
 
 var (
@@ -20,6 +21,19 @@ var (
 				MustTemplate(invalidPermissionMessage, errutil.WithPublic(invalidPermissionMessage))
 	ErrInvalidAssignment = errutil.BadRequest("resourcePermissions.invalidAssignment").
 				MustTemplate(invalidAssignmentMessage, errutil.WithPublic(invalidAssignmentMessage))
+	ErrReadOnly                  = errutil.Conflict("resourcePermissions.readOnly", errutil.WithPublicMessage(readOnlyMessage))
+	ErrPermissionRequestNotFound = errutil.NotFound("resourcePermissions.permissionRequestNotFound",
+		errutil.WithPublicMessage("Permission request not found"))
+	ErrPermissionRequestNotPending = errutil.Conflict("resourcePermissions.permissionRequestNotPending",
+		errutil.WithPublicMessage("Permission request has already been reviewed"))
+	ErrPermissionJobNotFound = errutil.NotFound("resourcePermissions.permissionJobNotFound",
+		errutil.WithPublicMessage("Permission job not found"))
+	ErrPermissionNotFound = errutil.NotFound("resourcePermissions.permissionNotFound",
+		errutil.WithPublicMessage("Permission not found"))
+	ErrIdempotencyKeyReused = errutil.UnprocessableEntity("resourcePermissions.idempotencyKeyReused",
+		errutil.WithPublicMessage("Idempotency key was already used for other permissions"))
+	ErrIdempotentWriteInProgress = errutil.Conflict("resourcePermissions.idempotentWriteInProgress",
+		errutil.WithPublicMessage("A write with the same idempotency key is in progress"))
 )
 
 func ErrInvalidParamData(param string, err error) errutil.TemplateData {
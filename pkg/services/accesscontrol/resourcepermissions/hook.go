@@ -1,19 +1,120 @@
 package resourcepermissions
 
 import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 )
 
+// defaultPostCommitHookConcurrency is the number of post commit hooks running at once when the options don't set it
+const defaultPostCommitHookConcurrency = 10
+
 type ResourceHooks struct {
 	User        UserResourceHookFunc
 	Team        TeamResourceHookFunc
 	BuiltInRole BuiltinResourceHookFunc
+	Group       GroupResourceHookFunc
 }
 
 type UserResourceHookFunc func(session *db.Session, orgID int64, user accesscontrol.User, resourceID, permission string) error
 type TeamResourceHookFunc func(session *db.Session, orgID, teamID int64, resourceID, permission string) error
 type BuiltinResourceHookFunc func(session *db.Session, orgID int64, builtInRole, resourceID, permission string) error
+type GroupResourceHookFunc func(session *db.Session, orgID int64, groupID, resourceID, permission string) error
+
+// ResourceHooksV2 are called in the transaction setting a permission, after ResourceHooks, with the change applied
+// to the managed role of the assignee so they don't need to query the permissions again.
+type ResourceHooksV2 struct {
+	User        UserResourceHookFuncV2
+	Team        TeamResourceHookFuncV2
+	BuiltInRole BuiltinResourceHookFuncV2
+	Group       GroupResourceHookFuncV2
+}
+
+// PermissionChange is the change applied to the permissions of an assignee on a resource.
+type PermissionChange struct {
+	ResourceID string
+	Permission string
+	// Role is the managed role of the assignee holding the permissions
+	Role accesscontrol.Role
+	// Added are the actions granted on the resource, Removed the actions revoked, both are sorted.
+	// An action whose deny flag changed is part of both.
+	Added   []string
+	Removed []string
+}
+
+type UserResourceHookFuncV2 func(session *db.Session, orgID int64, user accesscontrol.User, change PermissionChange) error
+type TeamResourceHookFuncV2 func(session *db.Session, orgID, teamID int64, change PermissionChange) error
+type BuiltinResourceHookFuncV2 func(session *db.Session, orgID int64, builtInRole string, change PermissionChange) error
+type GroupResourceHookFuncV2 func(session *db.Session, orgID int64, groupID string, change PermissionChange) error
+
+// PostCommitHooks are called once the permissions set with SetPermissions are committed, with each permission set.
+// Unlike ResourceHooks they run outside of the transaction and concurrently, so hooks calling external services
+// don't hold the transaction of large batches. Their errors don't roll the permissions back, they are logged.
+type PostCommitHooks struct {
+	User        func(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) error
+	Team        func(ctx context.Context, orgID, teamID int64, resourceID, permission string) error
+	BuiltInRole func(ctx context.Context, orgID int64, builtInRole, resourceID, permission string) error
+	Group       func(ctx context.Context, orgID int64, groupID, resourceID, permission string) error
+}
+
+// run calls the hooks of the commands with at most concurrency hooks running at once. Every hook is called even
+// when others fail, the errors are joined.
+func (h PostCommitHooks) run(ctx context.Context, orgID int64, commands []SetResourcePermissionsCommand, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = defaultPostCommitHookConcurrency
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	g := errgroup.Group{}
+	g.SetLimit(concurrency)
+	for _, cmd := range commands {
+		hook := h.hook(orgID, cmd)
+		if hook == nil {
+			continue
+		}
+		g.Go(func() error {
+			if err := hook(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errs...)
+}
+
+// hook returns the hook of the assignee of the command, nil when none is configured.
+func (h PostCommitHooks) hook(orgID int64, cmd SetResourcePermissionsCommand) func(ctx context.Context) error {
+	switch {
+	case cmd.User.ID != 0 && h.User != nil:
+		return func(ctx context.Context) error {
+			return h.User(ctx, orgID, cmd.User, cmd.ResourceID, cmd.Permission)
+		}
+	case cmd.TeamID != 0 && h.Team != nil:
+		return func(ctx context.Context) error {
+			return h.Team(ctx, orgID, cmd.TeamID, cmd.ResourceID, cmd.Permission)
+		}
+	case cmd.BuiltinRole != "" && h.BuiltInRole != nil:
+		return func(ctx context.Context) error {
+			return h.BuiltInRole(ctx, orgID, cmd.BuiltinRole, cmd.ResourceID, cmd.Permission)
+		}
+	case cmd.GroupID != "" && h.Group != nil:
+		return func(ctx context.Context) error {
+			return h.Group(ctx, orgID, cmd.GroupID, cmd.ResourceID, cmd.Permission)
+		}
+	}
+	return nil
+}
 
 type User struct {
 	ID         int64
@@ -0,0 +1,201 @@
+package resourcepermissions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the resource permissions API carrying the idempotency key of a write
+	IdempotencyKeyHeader = "Idempotency-Key"
+	idempotencyNamespace = "resourcepermissions.idempotency"
+	// idempotencyCleanupInterval is how often the expired idempotency keys are removed
+	idempotencyCleanupInterval = time.Hour
+	// idempotencyPendingLease is how long a write holds its claim on a key, the claim of a write that didn't complete
+	// within it, e.g. because the instance applying it stopped, is taken over by the next write with the same key
+	idempotencyPendingLease = time.Minute
+)
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey returns a context making the permission writes done with it idempotent. A write repeated with
+// the same key and commands within the rbac permission_idempotency_window returns the result of the first write
+// without applying the permissions again, so hooks don't run and audit entries are not written twice. A write repeated
+// while the first one is still applied fails with ErrIdempotentWriteInProgress. Keys are scoped to the requester of the
+// context, so the writes of different callers never share a result.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+type idempotentResult struct {
+	// Hash of the commands of the write, the key can't be reused for other commands
+	Hash    string    `json:"hash"`
+	Created time.Time `json:"created"`
+	// Pending is set while the write claiming the key is applied
+	Pending     bool                               `json:"pending,omitempty"`
+	Permissions []accesscontrol.ResourcePermission `json:"permissions"`
+}
+
+// idempotency returns the kvstore key of the write and the hash of its commands, the key is empty when the write
+// is not idempotent.
+func (s *Service) idempotency(ctx context.Context, resourceID string, commands []accesscontrol.SetResourcePermissionCommand) (string, string, error) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" || s.cfg == nil || s.cfg.RBAC.PermissionIdempotencyWindow <= 0 {
+		return "", "", nil
+	}
+
+	body, err := json.Marshal(commands)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Keys are chosen by the clients, they are scoped to the caller and hashed to fit the kvstore
+	caller := ""
+	if requester, err := identity.GetRequester(ctx); err == nil {
+		caller = requester.GetID()
+	}
+	storeKey := sha256.Sum256([]byte(caller + "/" + resourceID + "/" + key))
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(storeKey[:]), hex.EncodeToString(hash[:]), nil
+}
+
+// idempotencyNamespace is the kvstore namespace of the idempotency keys of the resource.
+func (s *Service) idempotencyNamespace() string {
+	return idempotencyNamespace + "." + s.options.Resource
+}
+
+// claimIdempotencyKey claims the key for the write of the commands hashed to hash. The key is claimed by inserting it
+// in the kvstore table, whose unique index lets a single one of concurrent writes with the same key claim it. When the
+// key was already used, the result of the previous write is returned and found is set. The key of an expired write and
+// the pending claim of a write that outlived idempotencyPendingLease are claimed again.
+func (s *Service) claimIdempotencyKey(ctx context.Context, orgID int64, key, hash string) ([]accesscontrol.ResourcePermission, bool, error) {
+	now := time.Now()
+	claim, err := json.Marshal(idempotentResult{Hash: hash, Created: now, Pending: true})
+	if err != nil {
+		return nil, false, err
+	}
+
+	namespace := s.idempotencyNamespace()
+	err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&kvstore.Item{OrgId: &orgID, Namespace: &namespace, Key: &key, Value: string(claim), Created: now, Updated: now})
+		return err
+	})
+	if err == nil {
+		return nil, false, nil
+	}
+	if !s.sqlStore.GetDialect().IsUniqueConstraintViolation(err) {
+		return nil, false, err
+	}
+
+	value, ok, err := s.kv.Get(ctx, orgID, namespace, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		// The write holding the key failed in between, the client can retry
+		return nil, false, ErrIdempotentWriteInProgress.Errorf("idempotency key was released")
+	}
+
+	var result idempotentResult
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, false, err
+	}
+
+	expired := now.Sub(result.Created) > s.cfg.RBAC.PermissionIdempotencyWindow
+	stale := result.Pending && now.Sub(result.Created) > idempotencyPendingLease
+	if expired || stale {
+		// Only one of the writes finding the expired key or the stale claim replaces it
+		var affected int64
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			res, err := sess.Exec("UPDATE kv_store SET value = ?, created = ?, updated = ? WHERE org_id = ? AND namespace = ? AND "+
+				s.sqlStore.GetDialect().Quote("key")+" = ? AND value = ?", string(claim), now, now, orgID, namespace, key, value)
+			if err != nil {
+				return err
+			}
+			affected, err = res.RowsAffected()
+			return err
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if affected == 0 {
+			return nil, false, ErrIdempotentWriteInProgress.Errorf("idempotency key was claimed by another write")
+		}
+		return nil, false, nil
+	}
+
+	if result.Hash != hash {
+		return nil, false, ErrIdempotencyKeyReused.Errorf("idempotency key was used for other permissions")
+	}
+	if result.Pending {
+		return nil, false, ErrIdempotentWriteInProgress.Errorf("idempotency key is claimed by another write")
+	}
+	return result.Permissions, true, nil
+}
+
+// releaseIdempotencyKey removes the claim of a write that failed so it can be retried with the same key.
+func (s *Service) releaseIdempotencyKey(ctx context.Context, orgID int64, key string) {
+	if err := s.kv.Del(ctx, orgID, s.idempotencyNamespace(), key); err != nil {
+		s.log.Warn("Failed to release the idempotency key of a failed permission write", "resource", s.options.Resource, "error", err)
+	}
+}
+
+func (s *Service) setIdempotentResult(ctx context.Context, orgID int64, key, hash string, permissions []accesscontrol.ResourcePermission) error {
+	value, err := json.Marshal(idempotentResult{Hash: hash, Created: time.Now(), Permissions: permissions})
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, s.idempotencyNamespace(), key, string(value))
+}
+
+// scheduleIdempotencyCleanup removes the expired idempotency keys of the resource every idempotencyCleanupInterval
+// once the access control service runs.
+func (s *Service) scheduleIdempotencyCleanup() {
+	if s.cfg == nil || s.cfg.RBAC.PermissionIdempotencyWindow <= 0 {
+		return
+	}
+	runner, ok := s.service.(accesscontrol.BackgroundJobRunner)
+	if !ok {
+		return
+	}
+
+	runner.RunBackgroundJob("rbac-idempotency-cleanup-"+s.options.Resource, func(ctx context.Context) error {
+		ticker := time.NewTicker(idempotencyCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.deleteExpiredIdempotencyKeys(ctx, time.Now()); err != nil {
+					s.log.Warn("Failed to remove expired idempotency keys", "resource", s.options.Resource, "error", err)
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// deleteExpiredIdempotencyKeys removes the keys of the resource last updated before the idempotency window.
+func (s *Service) deleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM kv_store WHERE namespace = ? AND updated < ?",
+			s.idempotencyNamespace(), now.Add(-s.cfg.RBAC.PermissionIdempotencyWindow))
+		return err
+	})
+}
@@ -0,0 +1,382 @@
+package resourcepermissions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+type PermissionJobStatus string
+
+const (
+	PermissionJobPending   PermissionJobStatus = "pending"
+	PermissionJobRunning   PermissionJobStatus = "running"
+	PermissionJobCompleted PermissionJobStatus = "completed"
+	PermissionJobFailed    PermissionJobStatus = "failed"
+)
+
+// PermissionJob applies the same permission commands to many resources in the background.
+// Processed and Failed count the items already handled, Errors is only set when reading a job.
+type PermissionJob struct {
+	ID        int64               `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID     int64               `json:"orgId" xorm:"org_id"`
+	Resource  string              `json:"resource"`
+	Status    PermissionJobStatus `json:"status"`
+	Total     int                 `json:"total"`
+	Processed int                 `json:"processed"`
+	Failed    int                 `json:"failed"`
+	CreatedBy int64               `json:"createdBy" xorm:"created_by"`
+	Created   time.Time           `json:"created"`
+	Updated   time.Time           `json:"updated"`
+	// Commands are the JSON encoded commands of the job, they are read when the job is resumed
+	Commands string `json:"-" xorm:"commands"`
+
+	Errors []PermissionJobItem `json:"errors,omitempty" xorm:"-"`
+}
+
+func (PermissionJob) TableName() string {
+	return "permission_job"
+}
+
+// PermissionJobItem is the progress of a job on a single resource.
+type PermissionJobItem struct {
+	ID         int64               `json:"-" xorm:"pk autoincr 'id'"`
+	JobID      int64               `json:"-" xorm:"job_id"`
+	ResourceID string              `json:"resourceId" xorm:"resource_id"`
+	Status     PermissionJobStatus `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Updated    time.Time           `json:"updated"`
+}
+
+func (PermissionJobItem) TableName() string {
+	return "permission_job_item"
+}
+
+// StartPermissionJob records a job setting the permissions on each of the resources and starts executing it in the background.
+// The progress of the job and the error of every failed resource are available with GetPermissionJob.
+func (s *Service) StartPermissionJob(
+	ctx context.Context, orgID, createdBy int64, resourceIDs []string,
+	commands ...accesscontrol.SetResourcePermissionCommand,
+) (*PermissionJob, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.StartPermissionJob")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if len(resourceIDs) == 0 {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("resourceIds", nil))
+	}
+	if len(commands) == 0 {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("permissions", nil))
+	}
+	for _, cmd := range commands {
+		if _, err := s.mapPermission(orgID, cmd.Permission); err != nil {
+			return nil, err
+		}
+	}
+
+	encoded, err := json.Marshal(commands)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &PermissionJob{
+		OrgID:     orgID,
+		Resource:  s.options.Resource,
+		Status:    PermissionJobPending,
+		Total:     len(resourceIDs),
+		CreatedBy: createdBy,
+		Created:   now,
+		Updated:   now,
+		Commands:  string(encoded),
+	}
+
+	newItems := func(resourceIDs []string) []PermissionJobItem {
+		items := make([]PermissionJobItem, 0, len(resourceIDs))
+		for _, resourceID := range resourceIDs {
+			items = append(items, PermissionJobItem{JobID: job.ID, ResourceID: resourceID, Status: PermissionJobPending, Updated: now})
+		}
+		return items
+	}
+
+	bulk := sqlstore.NativeSettingsForDialect(s.sqlStore.GetDialect())
+	if s.cfg != nil && s.cfg.RBAC.SQLiteCommitPerChunk && s.sqlStore.GetDialect().DriverName() == migrator.SQLite {
+		// The items are committed chunk by chunk, a job missing some of its items is marked as failed
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Insert(job)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = sqlstore.InBatches(resourceIDs, bulk, func(batch any) error {
+			return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.BulkInsert("permission_job_item", newItems(batch.([]string)), bulk)
+				return err
+			})
+		})
+		if err != nil {
+			if statusErr := s.setPermissionJobStatus(ctx, job.ID, PermissionJobFailed); statusErr != nil {
+				s.log.FromContext(ctx).Error("Failed to update permission job status", "jobID", job.ID, "error", statusErr)
+			}
+			return nil, err
+		}
+	} else {
+		err := s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(job); err != nil {
+				return err
+			}
+			_, err := sess.BulkInsert("permission_job_item", newItems(resourceIDs), bulk)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.runInBackground(ctx, s.permissionJobsName(), func(ctx context.Context) error {
+		return s.runPermissionJob(ctx, job, commands)
+	})
+
+	return job, nil
+}
+
+// permissionJobStaleAfter is the time after which a job whose progress wasn't updated is resumed, the progress of a
+// running job is updated after each of its resources.
+const permissionJobStaleAfter = 5 * time.Minute
+
+// permissionJobsName is the name reporting the runs of the permission jobs of the resource in the job statuses.
+func (s *Service) permissionJobsName() string {
+	return "rbac-permission-jobs-" + s.options.Resource
+}
+
+// runInBackground runs fn under the lifecycle of the access control service, the job outlives the request that
+// started it.
+func (s *Service) runInBackground(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	if runner, ok := s.service.(accesscontrol.BackgroundJobRunner); ok {
+		runner.RunBackgroundJob(name, fn)
+		return
+	}
+
+	go func() {
+		if err := fn(context.WithoutCancel(ctx)); err != nil {
+			s.log.Warn("Background job failed", "job", name, "error", err)
+		}
+	}()
+}
+
+// scheduleResumePermissionJobs resumes the jobs of the resource interrupted by a restart once the access control
+// service runs.
+func (s *Service) scheduleResumePermissionJobs() {
+	if runner, ok := s.service.(accesscontrol.BackgroundJobRunner); ok {
+		runner.RunBackgroundJob("rbac-resume-permission-jobs-"+s.options.Resource, s.resumePermissionJobs)
+	}
+}
+
+// resumePermissionJobs resumes the pending and running jobs of the resource whose progress is stale. Jobs updated
+// recently may still be run by another instance, they are checked again once they would be stale. Jobs created
+// without their commands or missing some of their resources can't be resumed, they are marked as failed.
+func (s *Service) resumePermissionJobs(ctx context.Context) error {
+	for {
+		var jobs []*PermissionJob
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Where("resource = ? AND status IN (?, ?)", s.options.Resource, PermissionJobPending, PermissionJobRunning).
+				Asc("id").Find(&jobs)
+		})
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		now := time.Now()
+		var wait time.Duration
+		for _, job := range jobs {
+			if staleIn := job.Updated.Add(permissionJobStaleAfter).Sub(now); staleIn > 0 {
+				if wait == 0 || staleIn < wait {
+					wait = staleIn
+				}
+				continue
+			}
+
+			claimed, err := s.claimPermissionJob(ctx, job, now)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+
+			var commands []accesscontrol.SetResourcePermissionCommand
+			if err := s.checkResumable(ctx, job, &commands); err != nil {
+				s.log.FromContext(ctx).Warn("Failed to resume permission job", "jobID", job.ID, "error", err)
+				if err := s.setPermissionJobStatus(ctx, job.ID, PermissionJobFailed); err != nil {
+					return err
+				}
+				continue
+			}
+
+			s.log.FromContext(ctx).Info("Resuming permission job", "jobID", job.ID, "processed", job.Processed, "total", job.Total)
+			s.runInBackground(ctx, s.permissionJobsName(), func(ctx context.Context) error {
+				return s.runPermissionJob(ctx, job, commands)
+			})
+		}
+
+		if wait == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// claimPermissionJob marks the stale job as running, it isn't claimed when another instance updated it since it was read.
+func (s *Service) claimPermissionJob(ctx context.Context, job *PermissionJob, now time.Time) (bool, error) {
+	var claimed bool
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE permission_job SET status = ?, updated = ? WHERE id = ? AND status = ? AND updated = ?",
+			PermissionJobRunning, now, job.ID, job.Status, job.Updated)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		claimed = rows == 1
+		return err
+	})
+	return claimed, err
+}
+
+// checkResumable decodes the commands of the job and checks every resource of the job was recorded.
+func (s *Service) checkResumable(ctx context.Context, job *PermissionJob, commands *[]accesscontrol.SetResourcePermissionCommand) error {
+	if job.Commands == "" {
+		return errors.New("the commands of the job were not stored")
+	}
+	if err := json.Unmarshal([]byte(job.Commands), commands); err != nil {
+		return fmt.Errorf("invalid commands: %w", err)
+	}
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		items, err := sess.Table("permission_job_item").Where("job_id = ?", job.ID).Count()
+		if err != nil {
+			return err
+		}
+		if int(items) != job.Total {
+			return fmt.Errorf("%d of the %d resources of the job were recorded", items, job.Total)
+		}
+		return nil
+	})
+}
+
+// GetPermissionJob returns the progress of the job with the errors of the resources that failed.
+func (s *Service) GetPermissionJob(ctx context.Context, orgID, jobID int64) (*PermissionJob, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetPermissionJob")
+	defer span.End()
+
+	job := &PermissionJob{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("id = ? AND org_id = ? AND resource = ?", jobID, orgID, s.options.Resource).Get(job)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrPermissionJobNotFound.Errorf("permission job %d not found", jobID)
+		}
+		return sess.Where("job_id = ? AND status = ?", jobID, PermissionJobFailed).Asc("id").Find(&job.Errors)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// runPermissionJob sets the permissions on the pending resources of the job. A job interrupted because ctx is done is
+// left running, it is resumed by the next start, see resumePermissionJobs.
+func (s *Service) runPermissionJob(ctx context.Context, job *PermissionJob, commands []accesscontrol.SetResourcePermissionCommand) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.runPermissionJob")
+	defer span.End()
+
+	logger := s.log.FromContext(ctx).New("jobID", job.ID)
+	if err := s.setPermissionJobStatus(ctx, job.ID, PermissionJobRunning); err != nil {
+		return fmt.Errorf("failed to start permission job %d: %w", job.ID, err)
+	}
+
+	fail := func(err error) error {
+		if ctx.Err() != nil {
+			logger.Info("Permission job interrupted, it is resumed on the next start")
+			return nil
+		}
+		if statusErr := s.setPermissionJobStatus(ctx, job.ID, PermissionJobFailed); statusErr != nil {
+			logger.Error("Failed to update permission job status", "error", statusErr)
+		}
+		return err
+	}
+
+	var items []PermissionJobItem
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("job_id = ? AND status = ?", job.ID, PermissionJobPending).Asc("id").Find(&items)
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to load the resources of permission job %d: %w", job.ID, err))
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return fail(ctx.Err())
+		}
+
+		item.Status = PermissionJobCompleted
+		if _, err := s.SetPermissions(ctx, job.OrgID, item.ResourceID, commands...); err != nil {
+			if ctx.Err() != nil {
+				return fail(err)
+			}
+			item.Status = PermissionJobFailed
+			item.Error = err.Error()
+		}
+
+		if err := s.recordPermissionJobItem(ctx, item); err != nil {
+			return fail(fmt.Errorf("failed to record the progress of permission job %d on %s: %w", job.ID, item.ResourceID, err))
+		}
+	}
+
+	if err := s.setPermissionJobStatus(ctx, job.ID, PermissionJobCompleted); err != nil {
+		return fmt.Errorf("failed to complete permission job %d: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (s *Service) recordPermissionJobItem(ctx context.Context, item PermissionJobItem) error {
+	failed := 0
+	if item.Status == PermissionJobFailed {
+		failed = 1
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now()
+		if _, err := sess.Exec("UPDATE permission_job_item SET status = ?, error = ?, updated = ? WHERE id = ?",
+			item.Status, item.Error, now, item.ID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("UPDATE permission_job SET processed = processed + 1, failed = failed + ?, updated = ? WHERE id = ?",
+			failed, now, item.JobID)
+		return err
+	})
+}
+
+func (s *Service) setPermissionJobStatus(ctx context.Context, jobID int64, status PermissionJobStatus) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE permission_job SET status = ?, updated = ? WHERE id = ?", status, time.Now(), jobID)
+		return err
+	})
+}
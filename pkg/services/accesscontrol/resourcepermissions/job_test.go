@@ -0,0 +1,110 @@
+package resourcepermissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_StartPermissionJob(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		ResourceAttribute:    "uid",
+		Assignments:          Assignments{BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+		ResourceValidator: func(ctx context.Context, orgID int64, resourceID string) error {
+			if resourceID == "missing" {
+				return errors.New("not found")
+			}
+			return nil
+		},
+	})
+
+	t.Run("should reject unknown permissions", func(t *testing.T) {
+		_, err := service.StartPermissionJob(ctx, 1, 1, []string{"a"}, accesscontrol.SetResourcePermissionCommand{BuiltinRole: "Viewer", Permission: "Unknown"})
+		assert.ErrorIs(t, err, ErrInvalidPermission)
+	})
+
+	t.Run("should set the permissions of every resource and record failures", func(t *testing.T) {
+		job, err := service.StartPermissionJob(ctx, 1, 1, []string{"a", "missing", "b"},
+			accesscontrol.SetResourcePermissionCommand{BuiltinRole: "Viewer", Permission: "View"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, job.Total)
+
+		require.Eventually(t, func() bool {
+			job, err = service.GetPermissionJob(ctx, 1, job.ID)
+			require.NoError(t, err)
+			return job.Status == PermissionJobCompleted
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, 3, job.Processed)
+		assert.Equal(t, 1, job.Failed)
+		require.Len(t, job.Errors, 1)
+		assert.Equal(t, "missing", job.Errors[0].ResourceID)
+		assert.NotEmpty(t, job.Errors[0].Error)
+
+		for _, resourceID := range []string{"a", "b"} {
+			permissions, err := service.store.GetResourcePermissions(ctx, 1, GetResourcePermissionsQuery{
+				User:    &user.SignedInUser{OrgID: 1},
+				Actions: []string{"dashboards:read"}, Resource: "dashboards", ResourceID: resourceID, ResourceAttribute: "uid",
+			})
+			require.NoError(t, err)
+			require.Len(t, permissions, 1)
+			assert.Equal(t, "Viewer", permissions[0].BuiltInRole)
+		}
+	})
+
+	t.Run("should not return jobs of other organizations", func(t *testing.T) {
+		job, err := service.StartPermissionJob(ctx, 1, 1, []string{"c"}, accesscontrol.SetResourcePermissionCommand{BuiltinRole: "Viewer", Permission: "View"})
+		require.NoError(t, err)
+
+		_, err = service.GetPermissionJob(ctx, 2, job.ID)
+		assert.ErrorIs(t, err, ErrPermissionJobNotFound)
+	})
+	t.Run("should resume stale jobs and fail the jobs without commands", func(t *testing.T) {
+		stale := time.Now().Add(-2 * permissionJobStaleAfter).Truncate(time.Second)
+		insertJob := func(commands string, resourceIDs ...string) int64 {
+			job := &PermissionJob{
+				OrgID: 1, Resource: "dashboards", Status: PermissionJobRunning, Total: len(resourceIDs),
+				Created: stale, Updated: stale, Commands: commands,
+			}
+			err := service.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+				if _, err := sess.Insert(job); err != nil {
+					return err
+				}
+				for _, resourceID := range resourceIDs {
+					item := &PermissionJobItem{JobID: job.ID, ResourceID: resourceID, Status: PermissionJobPending, Updated: stale}
+					if _, err := sess.Insert(item); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			return job.ID
+		}
+
+		resumed := insertJob(`[{"builtInRole":"Viewer","permission":"View"}]`, "d")
+		withoutCommands := insertJob("", "e")
+
+		require.NoError(t, service.resumePermissionJobs(ctx))
+		require.Eventually(t, func() bool {
+			job, err := service.GetPermissionJob(ctx, 1, resumed)
+			require.NoError(t, err)
+			return job.Status == PermissionJobCompleted && job.Processed == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		job, err := service.GetPermissionJob(ctx, 1, withoutCommands)
+		require.NoError(t, err)
+		assert.Equal(t, PermissionJobFailed, job.Status)
+	})
+}
@@ -0,0 +1,207 @@
+package resourcepermissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+)
+
+// LegacyACLReport is the outcome of MigrateLegacyACL. Existing counts the entries skipped because their
+// assignee already has a managed permission on the dashboard or folder.
+type LegacyACLReport struct {
+	Converted     int
+	Existing      int
+	Unconvertible []UnconvertibleACL
+}
+
+// UnconvertibleACL is a dashboard_acl entry that can't be converted to a managed permission.
+type UnconvertibleACL struct {
+	ID          int64
+	OrgID       int64
+	DashboardID int64
+	Reason      string
+}
+
+type legacyACL struct {
+	ID          int64                          `xorm:"id"`
+	OrgID       int64                          `xorm:"org_id"`
+	DashboardID int64                          `xorm:"dashboard_id"`
+	UserID      int64                          `xorm:"user_id"`
+	TeamID      int64                          `xorm:"team_id"`
+	Role        string                         `xorm:"role"`
+	Permission  dashboardaccess.PermissionType `xorm:"permission"`
+	UID         string                         `xorm:"uid"`
+}
+
+type legacyACLAssignee struct {
+	userID int64
+	teamID int64
+	role   string
+}
+
+// MigrateLegacyACL converts the dashboard_acl entries of the dashboards or folders managed by the service that
+// were never migrated to managed permissions. Entries whose assignee already has a managed permission on the
+// resource are skipped so it's safe to execute multiple times, the zanzana tuples of the converted permissions
+// are written by the next sync. With dryRun nothing is written and the report only verifies the entries.
+func (s *Service) MigrateLegacyACL(ctx context.Context, dryRun bool) (*LegacyACLReport, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.MigrateLegacyACL")
+	defer span.End()
+
+	if s.options.Resource != dashboards.ScopeDashboardsRoot && s.options.Resource != dashboards.ScopeFoldersRoot {
+		return nil, fmt.Errorf("legacy acl entries don't exist for %s", s.options.Resource)
+	}
+	if !dryRun {
+		if err := s.checkWritable(); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := s.getLegacyACL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LegacyACLReport{Unconvertible: []UnconvertibleACL{}}
+	assigned := make(map[string]map[legacyACLAssignee]bool)
+	for _, entry := range entries {
+		unconvertible := func(reason string) {
+			report.Unconvertible = append(report.Unconvertible, UnconvertibleACL{
+				ID:          entry.ID,
+				OrgID:       entry.OrgID,
+				DashboardID: entry.DashboardID,
+				Reason:      reason,
+			})
+		}
+
+		if entry.UID == "" {
+			unconvertible(fmt.Sprintf("%s not found", s.options.Resource))
+			continue
+		}
+
+		key := fmt.Sprintf("%d-%s", entry.OrgID, entry.UID)
+		if assigned[key] == nil {
+			assigned[key], err = s.getManagedAssignees(ctx, entry.OrgID, entry.UID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		assignee := legacyACLAssignee{userID: entry.UserID, teamID: entry.TeamID, role: entry.Role}
+		if assigned[key][assignee] {
+			report.Existing++
+			continue
+		}
+
+		cmd, err := s.legacyACLCommand(ctx, entry)
+		if err != nil {
+			unconvertible(err.Error())
+			continue
+		}
+
+		if !dryRun {
+			if err := s.setLegacyACLPermission(ctx, entry, cmd); err != nil {
+				return nil, err
+			}
+		}
+		assigned[key][assignee] = true
+		report.Converted++
+	}
+
+	return report, nil
+}
+
+// getLegacyACL returns the dashboard_acl entries of the resource type of the service, the entries of deleted
+// dashboards are returned with an empty uid. The highest permission of an assignee comes first so it is the one
+// converted when the same basic role has several entries on a dashboard.
+func (s *Service) getLegacyACL(ctx context.Context) ([]legacyACL, error) {
+	isFolder := s.options.Resource == dashboards.ScopeFoldersRoot
+	rawSQL := `
+		SELECT acl.id, acl.org_id, acl.dashboard_id, COALESCE(acl.user_id, 0) AS user_id, COALESCE(acl.team_id, 0) AS team_id,
+			COALESCE(acl.role, '') AS role, acl.permission, COALESCE(d.uid, '') AS uid
+		FROM dashboard_acl AS acl
+		LEFT JOIN dashboard AS d ON d.id = acl.dashboard_id AND d.org_id = acl.org_id
+		WHERE acl.dashboard_id > 0 AND (d.is_folder = ?`
+	if !isFolder {
+		// entries of deleted resources can't be told apart, they are reported once by the dashboards service
+		rawSQL += " OR d.id IS NULL"
+	}
+	rawSQL += ") ORDER BY acl.permission DESC, acl.id"
+
+	var entries []legacyACL
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(rawSQL, isFolder).Find(&entries)
+	})
+	return entries, err
+}
+
+func (s *Service) getManagedAssignees(ctx context.Context, orgID int64, resourceID string) (map[legacyACLAssignee]bool, error) {
+	permissions, err := s.store.GetResourcePermissions(ctx, orgID, GetResourcePermissionsQuery{
+		Actions:           s.actions,
+		Resource:          s.options.Resource,
+		ResourceID:        resourceID,
+		ResourceAttribute: s.options.ResourceAttribute,
+		OnlyManaged:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	assignees := make(map[legacyACLAssignee]bool, len(permissions))
+	for _, p := range permissions {
+		assignees[legacyACLAssignee{userID: p.UserId, teamID: p.TeamId, role: p.BuiltInRole}] = true
+	}
+	return assignees, nil
+}
+
+// legacyACLCommand validates the entry and returns the command setting the equivalent managed permission.
+func (s *Service) legacyACLCommand(ctx context.Context, entry legacyACL) (SetResourcePermissionCommand, error) {
+	permission := entry.Permission.String()
+	actions, err := s.mapPermission(entry.OrgID, permission)
+	if err != nil || permission == "" {
+		return SetResourcePermissionCommand{}, fmt.Errorf("unknown permission %d", entry.Permission)
+	}
+
+	switch {
+	case entry.UserID != 0:
+		err = s.validateUser(ctx, entry.OrgID, entry.UserID)
+	case entry.TeamID != 0:
+		err = s.validateTeam(ctx, entry.OrgID, entry.TeamID)
+	case entry.Role != "":
+		err = s.validateBuiltinRole(ctx, entry.Role)
+	default:
+		err = errors.New("no assignee")
+	}
+	if err != nil {
+		return SetResourcePermissionCommand{}, err
+	}
+
+	if err := s.validateResource(ctx, entry.OrgID, entry.UID); err != nil {
+		return SetResourcePermissionCommand{}, err
+	}
+
+	return SetResourcePermissionCommand{
+		Actions:           actions,
+		Permission:        permission,
+		Resource:          s.options.Resource,
+		ResourceID:        entry.UID,
+		ResourceAttribute: s.options.ResourceAttribute,
+	}, nil
+}
+
+func (s *Service) setLegacyACLPermission(ctx context.Context, entry legacyACL, cmd SetResourcePermissionCommand) error {
+	var err error
+	switch {
+	case entry.UserID != 0:
+		_, err = s.store.SetUserResourcePermission(ctx, entry.OrgID, accesscontrol.User{ID: entry.UserID}, cmd, s.options.OnSetUser)
+	case entry.TeamID != 0:
+		_, err = s.store.SetTeamResourcePermission(ctx, entry.OrgID, entry.TeamID, cmd, s.options.OnSetTeam)
+	default:
+		_, err = s.store.SetBuiltInResourcePermission(ctx, entry.OrgID, entry.Role, cmd, s.options.OnSetBuiltInRole)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_MigrateLegacyACL(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true, Teams: true, BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{
+			"View":  {"dashboards:read"},
+			"Edit":  {"dashboards:read", "dashboards:write"},
+			"Admin": {"dashboards:read", "dashboards:write", "dashboards.permissions:write"},
+		},
+	})
+
+	usr, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "legacy", OrgID: 1})
+	require.NoError(t, err)
+
+	now := time.Now()
+	viewer, editor := org.RoleViewer, org.RoleEditor
+	err = service.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("INSERT INTO dashboard (id, version, slug, title, data, org_id, created, updated, uid, is_folder) VALUES (1, 1, 'dash', 'dash', '{}', 1, ?, ?, 'dash', ?)", now, now, false)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.InsertMulti([]dashboards.DashboardACL{
+			{OrgID: 1, DashboardID: 1, UserID: usr.ID, Permission: dashboardaccess.PERMISSION_VIEW, Created: now, Updated: now},
+			{OrgID: 1, DashboardID: 1, TeamID: 42, Permission: dashboardaccess.PERMISSION_EDIT, Created: now, Updated: now},
+			{OrgID: 1, DashboardID: 1, Role: &viewer, Permission: dashboardaccess.PERMISSION_VIEW, Created: now, Updated: now},
+			{OrgID: 1, DashboardID: 1, Role: &editor, Permission: dashboardaccess.PERMISSION_ADMIN, Created: now, Updated: now},
+			{OrgID: 1, DashboardID: 99, Role: &viewer, Permission: dashboardaccess.PERMISSION_VIEW, Created: now, Updated: now},
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	reader := &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+		},
+	}
+
+	t.Run("should only verify the entries on dry run", func(t *testing.T) {
+		report, err := service.MigrateLegacyACL(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Converted)
+		assert.Len(t, report.Unconvertible, 2)
+
+		permissions, err := service.GetPermissions(ctx, reader, "dash")
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+	})
+
+	t.Run("should convert the entries and report the unconvertible ones", func(t *testing.T) {
+		report, err := service.MigrateLegacyACL(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Converted)
+		assert.Equal(t, 0, report.Existing)
+		require.Len(t, report.Unconvertible, 2)
+		assert.ElementsMatch(t, []int64{1, 99}, []int64{report.Unconvertible[0].DashboardID, report.Unconvertible[1].DashboardID})
+
+		permissions, err := service.GetPermissions(ctx, reader, "dash")
+		require.NoError(t, err)
+		assert.Len(t, permissions, 3)
+	})
+
+	t.Run("should skip the entries already converted", func(t *testing.T) {
+		report, err := service.MigrateLegacyACL(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Converted)
+		assert.Equal(t, 3, report.Existing)
+		assert.Len(t, report.Unconvertible, 2)
+	})
+}
@@ -0,0 +1,159 @@
+package resourcepermissions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	maxMetadataEntries     = 10
+	maxMetadataKeyLength   = 40
+	maxMetadataValueLength = 190
+)
+
+// PermissionMetadata is a key/value pair attached to the managed permission of an assignee on a resource.
+type PermissionMetadata struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"org_id"`
+	RoleName string    `xorm:"role_name"`
+	Scope    string    `xorm:"scope"`
+	Key      string    `xorm:"key"`
+	Value    string    `xorm:"value"`
+	Updated  time.Time `xorm:"updated"`
+}
+
+func (PermissionMetadata) TableName() string {
+	return "permission_metadata"
+}
+
+// SetPermissionMetadataCommand replaces the metadata of the permission of one assignee.
+type SetPermissionMetadataCommand struct {
+	UserID      int64             `json:"userId,omitempty"`
+	TeamID      int64             `json:"teamId,omitempty"`
+	BuiltinRole string            `json:"builtInRole,omitempty"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// SetPermissionMetadata replaces the metadata of the permission of the assignee on the resource.
+// The assignee must have a permission on the resource, the metadata is removed together with the permission.
+func (s *Service) SetPermissionMetadata(ctx context.Context, orgID int64, resourceID string, cmd SetPermissionMetadataCommand) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetPermissionMetadata")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	if err := validateMetadata(cmd.Metadata); err != nil {
+		return err
+	}
+
+	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
+		return err
+	}
+
+	var roleName string
+	switch {
+	case cmd.UserID != 0:
+		if err := s.validateUser(ctx, orgID, cmd.UserID); err != nil {
+			return err
+		}
+		roleName = accesscontrol.ManagedUserRoleName(cmd.UserID)
+	case cmd.TeamID != 0:
+		if err := s.validateTeam(ctx, orgID, cmd.TeamID); err != nil {
+			return err
+		}
+		roleName = accesscontrol.ManagedTeamRoleName(cmd.TeamID)
+	case cmd.BuiltinRole != "":
+		if err := s.validateBuiltinRole(ctx, cmd.BuiltinRole); err != nil {
+			return err
+		}
+		roleName = accesscontrol.ManagedBuiltInRoleName(cmd.BuiltinRole)
+	default:
+		return ErrInvalidParam.Build(ErrInvalidParamData("assignee", nil))
+	}
+
+	scope := accesscontrol.Scope(s.options.Resource, s.options.ResourceAttribute, resourceID)
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Table("permission").
+			Join("INNER", "role", "role.id = permission.role_id").
+			Where("role.org_id = ? AND role.name = ? AND permission.scope = ?", orgID, roleName, scope).
+			Exist()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrPermissionNotFound.Errorf("no permission for %s on %s", roleName, scope)
+		}
+
+		if err := deletePermissionMetadata(sess, orgID, roleName, scope); err != nil {
+			return err
+		}
+		if len(cmd.Metadata) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		entries := make([]PermissionMetadata, 0, len(cmd.Metadata))
+		for key, value := range cmd.Metadata {
+			entries = append(entries, PermissionMetadata{OrgID: orgID, RoleName: roleName, Scope: scope, Key: key, Value: value, Updated: now})
+		}
+		_, err = sess.InsertMulti(&entries)
+		return err
+	})
+}
+
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataEntries {
+		return ErrInvalidParam.Build(ErrInvalidParamData("metadata", fmt.Errorf("more than %d entries", maxMetadataEntries)))
+	}
+	for key, value := range metadata {
+		if key == "" || len(key) > maxMetadataKeyLength {
+			return ErrInvalidParam.Build(ErrInvalidParamData("metadata", fmt.Errorf("invalid key %q", key)))
+		}
+		if len(value) > maxMetadataValueLength {
+			return ErrInvalidParam.Build(ErrInvalidParamData("metadata", fmt.Errorf("value of %q is too long", key)))
+		}
+	}
+	return nil
+}
+
+func deletePermissionMetadata(sess *db.Session, orgID int64, roleName, scope string) error {
+	_, err := sess.Where("org_id = ? AND role_name = ? AND scope = ?", orgID, roleName, scope).Delete(&PermissionMetadata{})
+	return err
+}
+
+// setPermissionMetadata sets the metadata of the managed permissions set directly on the resource.
+func (s *Service) setPermissionMetadata(ctx context.Context, orgID int64, resourceID string, permissions []accesscontrol.ResourcePermission) error {
+	scope := accesscontrol.Scope(s.options.Resource, s.options.ResourceAttribute, resourceID)
+
+	var entries []PermissionMetadata
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND scope = ?", orgID, scope).Find(&entries)
+	})
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	metadata := make(map[string]map[string]string)
+	for _, e := range entries {
+		if metadata[e.RoleName] == nil {
+			metadata[e.RoleName] = make(map[string]string)
+		}
+		metadata[e.RoleName][e.Key] = e.Value
+	}
+
+	for i, p := range permissions {
+		if !p.IsManaged || p.IsInherited || p.Scope != scope {
+			continue
+		}
+		if m, ok := metadata[p.RoleName]; ok {
+			permissions[i].Metadata = m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package resourcepermissions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_SetPermissionMetadata(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		ResourceAttribute:    "uid",
+		Assignments:          Assignments{Users: true, BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+	})
+
+	usr, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "user", OrgID: 1})
+	require.NoError(t, err)
+
+	reader := &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+		},
+	}
+
+	metadata := func(t *testing.T) map[string]string {
+		permissions, err := service.GetPermissions(ctx, reader, "dash")
+		require.NoError(t, err)
+		for _, p := range permissions {
+			if p.UserId == usr.ID {
+				return p.Metadata
+			}
+		}
+		return nil
+	}
+
+	t.Run("should fail when the assignee has no permission on the resource", func(t *testing.T) {
+		err := service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: map[string]string{"ticket": "OPS-1"}})
+		assert.ErrorIs(t, err, ErrPermissionNotFound)
+	})
+
+	_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "View")
+	require.NoError(t, err)
+
+	t.Run("should return the metadata with the permission", func(t *testing.T) {
+		err := service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: map[string]string{"ticket": "OPS-1"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"ticket": "OPS-1"}, metadata(t))
+
+		err = service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: map[string]string{"reason": "on-call"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"reason": "on-call"}, metadata(t))
+	})
+
+	t.Run("should reject invalid metadata", func(t *testing.T) {
+		tooMany := map[string]string{}
+		for i := 0; i <= maxMetadataEntries; i++ {
+			tooMany[fmt.Sprintf("key%d", i)] = "value"
+		}
+		err := service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: tooMany})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+
+		err = service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: map[string]string{"": "value"}})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+
+		err = service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{Metadata: map[string]string{"ticket": "OPS-1"}})
+		assert.ErrorIs(t, err, ErrInvalidParam)
+	})
+
+	t.Run("should remove the metadata with the permission", func(t *testing.T) {
+		_, err := service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "")
+		require.NoError(t, err)
+		_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "View")
+		require.NoError(t, err)
+		assert.Nil(t, metadata(t))
+	})
+
+	t.Run("should remove the metadata with the permissions of the resource", func(t *testing.T) {
+		err := service.SetPermissionMetadata(ctx, 1, "dash", SetPermissionMetadataCommand{UserID: usr.ID, Metadata: map[string]string{"ticket": "OPS-1"}})
+		require.NoError(t, err)
+
+		require.NoError(t, service.DeleteResourcePermissions(ctx, 1, "dash"))
+		_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "View")
+		require.NoError(t, err)
+		assert.Nil(t, metadata(t))
+	})
+}
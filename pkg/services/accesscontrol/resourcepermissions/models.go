@@ -11,16 +11,25 @@ type SetResourcePermissionCommand struct {
 	ResourceID        string
 	ResourceAttribute string
 	Permission        string
+	// Deny stores the actions as deny permissions, excluding the assignee from access granted by other roles
+	Deny bool
 }
 
 type SetResourcePermissionsCommand struct {
 	User        accesscontrol.User
 	TeamID      int64
 	BuiltinRole string
+	GroupID     string
 
 	SetResourcePermissionCommand
 }
 
+// ResourcePermissionsBatch is a set of permissions with the hooks of the service managing their resource.
+type ResourcePermissionsBatch struct {
+	Commands []SetResourcePermissionsCommand
+	Hooks    ResourceHooks
+}
+
 type GetResourcePermissionsQuery struct {
 	Actions              []string
 	Resource             string
@@ -29,5 +38,26 @@ type GetResourcePermissionsQuery struct {
 	OnlyManaged          bool
 	InheritedScopes      []string
 	EnforceAccessControl bool
-	User                 identity.Requester
+	// ExpandActionSets will resolve action sets found in the result and return their actions
+	// together with the action set in ResourcePermission.ActionSets
+	ExpandActionSets bool
+	// Permission filters the result to the assignments granting at least this permission, e.g. Edit also returns
+	// the assignments granting Admin. The actions of the permission are resolved from the action set of the resource,
+	// the result is not filtered for resources without action sets.
+	Permission string
+	// GrantingOneOf filters the result to the denied assignments and the assignments granting every action of at
+	// least one of the lists, action sets included. The result is not filtered when empty.
+	GrantingOneOf [][]string
+	User          identity.Requester
+	// Limit is the maximum number of assignments returned, every assignment is returned when zero
+	Limit int
+	// Offset is the number of assignments skipped before the first one returned
+	Offset int
+}
+
+// ResourcePermissionsPage is a page of the assignments of a resource.
+type ResourcePermissionsPage struct {
+	Permissions []accesscontrol.ResourcePermission
+	// TotalCount is the number of assignments matching the query across all pages
+	TotalCount int64
 }
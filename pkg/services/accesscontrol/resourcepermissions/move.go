@@ -0,0 +1,138 @@
+package resourcepermissions
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+// MoveResource is called by the services storing resources in folders once a resource moved to another folder,
+// within the transaction of the move when there is one. The zanzana tuples relating the resource to its folder are
+// rewritten once the move is committed. When requested, the inherited scopes of the resource are resolved again from
+// its new folders and the direct permissions granting nothing more than what their assignees inherit are removed in
+// a single transaction. It returns the direct permissions removed.
+func (s *Service) MoveResource(ctx context.Context, orgID int64, cmd accesscontrol.MoveResourceCommand) ([]accesscontrol.ResourcePermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.MoveResource")
+	defer span.End()
+
+	if cmd.OldParentUID != cmd.NewParentUID {
+		if maintainer, ok := s.service.(accesscontrol.ResourceContainerTupleMaintainer); ok {
+			if err := maintainer.ResourceMoved(ctx, orgID, s.options.Resource, cmd.ResourceID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !cmd.PruneRedundant {
+		return nil, nil
+	}
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+	return s.pruneRedundantPermissions(ctx, orgID, cmd.ResourceID)
+}
+
+// pruneRedundantPermissions removes the managed permissions set directly on the resource whose actions are all
+// granted to the same assignee by the permissions it inherits from the folders of the resource. Deny permissions
+// are never removed.
+func (s *Service) pruneRedundantPermissions(ctx context.Context, orgID int64, resourceID string) ([]accesscontrol.ResourcePermission, error) {
+	if s.options.InheritedScopesSolver == nil {
+		return nil, nil
+	}
+	inheritedScopes, err := s.options.InheritedScopesSolver(ctx, orgID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.store.GetResourcePermissions(ctx, orgID, GetResourcePermissionsQuery{
+		Actions:           s.queryActions(ctx),
+		Resource:          s.options.Resource,
+		ResourceID:        resourceID,
+		ResourceAttribute: s.options.ResourceAttribute,
+		InheritedScopes:   inheritedScopes,
+		OnlyManaged:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type assignee struct {
+		userID      int64
+		teamID      int64
+		builtInRole string
+	}
+	inherited := map[assignee]map[string]bool{}
+	for _, p := range permissions {
+		if !p.IsInherited || p.Deny {
+			continue
+		}
+		key := assignee{userID: p.UserId, teamID: p.TeamId, builtInRole: p.BuiltInRole}
+		if inherited[key] == nil {
+			inherited[key] = map[string]bool{}
+		}
+		for action := range s.resolveActions(ctx, p.Actions) {
+			inherited[key][action] = true
+		}
+	}
+
+	var pruned []accesscontrol.ResourcePermission
+	var commands []SetResourcePermissionsCommand
+	for _, p := range permissions {
+		if p.IsInherited || p.Deny {
+			continue
+		}
+		granted := inherited[assignee{userID: p.UserId, teamID: p.TeamId, builtInRole: p.BuiltInRole}]
+		if len(granted) == 0 {
+			continue
+		}
+
+		redundant := true
+		for action := range s.resolveActions(ctx, p.Actions) {
+			if !granted[action] {
+				redundant = false
+				break
+			}
+		}
+		if !redundant {
+			continue
+		}
+
+		pruned = append(pruned, p)
+		commands = append(commands, SetResourcePermissionsCommand{
+			User:        accesscontrol.User{ID: p.UserId},
+			TeamID:      p.TeamId,
+			BuiltinRole: p.BuiltInRole,
+			SetResourcePermissionCommand: SetResourcePermissionCommand{
+				Resource:          s.options.Resource,
+				ResourceID:        resourceID,
+				ResourceAttribute: s.options.ResourceAttribute,
+			},
+		})
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.store.SetResourcePermissions(ctx, orgID, commands, s.resourceHooks()); err != nil {
+		return nil, err
+	}
+	return pruned, nil
+}
+
+// resolveActions returns the actions with the action sets among them replaced by the actions they grant.
+func (s *Service) resolveActions(ctx context.Context, actions []string) map[string]bool {
+	resolved := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+			if actionSetActions := s.actionSetSvc.ResolveActionSet(action); len(actionSetActions) > 0 {
+				for _, a := range actionSetActions {
+					resolved[a] = true
+				}
+				continue
+			}
+		}
+		resolved[action] = true
+	}
+	return resolved
+}
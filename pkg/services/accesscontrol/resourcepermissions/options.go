@@ -26,6 +26,12 @@ type Options struct {
 	// PermissionsToAction is a map of friendly named permissions and what access control actions they should generate.
 	// E.g. Edit permissions should generate dashboards:read, dashboards:write and dashboards:delete
 	PermissionsToActions map[string][]string
+	// AllowGrafanaAdmin enables assigning permissions to the Grafana Admin built-in role when BuiltInRoles assignments
+	// are enabled. The assignment is stored in the org of the resource and applies to the server admins of that org.
+	AllowGrafanaAdmin bool
+	// AllowDeny enables the Deny permission. It stores all actions of the resource as deny permissions
+	// so that an assignee can be excluded from the access granted by other roles (e.g. a team).
+	AllowDeny bool
 	// ReaderRoleName is the display name for the generated fixed reader role
 	ReaderRoleName string
 	// WriterRoleName is the display name for the generated fixed writer role
@@ -38,8 +44,19 @@ type Options struct {
 	OnSetTeam func(session *db.Session, orgID, teamID int64, resourceID, permission string) error
 	// OnSetBuiltInRole if configured will be called each time a permission is set for a built-in role
 	OnSetBuiltInRole func(session *db.Session, orgID int64, builtInRole, resourceID, permission string) error
+	// OnSetGroup if configured will be called each time a permission is set for an external group
+	OnSetGroup func(session *db.Session, orgID int64, groupID, resourceID, permission string) error
+	// HooksV2 if configured are called each time a permission is set, with the actions added and removed
+	HooksV2 ResourceHooksV2
+	// PostCommitHooks if configured are called concurrently once the permissions set by SetPermissions are committed
+	PostCommitHooks PostCommitHooks
+	// PostCommitHookConcurrency bounds the number of post commit hooks running at once, 10 when not set
+	PostCommitHookConcurrency int
 	// InheritedScopesSolver if configured can generate additional scopes that will be used when fetching permissions for a resource
 	InheritedScopesSolver InheritedScopesSolver
+	// InheritedWildcards are the wildcards of the parents of the resources that include every resource, e.g. the
+	// wildcards of the folders for the dashboards. They are declared in the wildcard registry of the service.
+	InheritedWildcards []string
 	// LicenseMV if configured is applied to endpoints that can modify permissions
 	LicenseMW web.Handler
 }
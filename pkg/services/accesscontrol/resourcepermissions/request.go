@@ -0,0 +1,187 @@
+package resourcepermissions
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+type PermissionRequestStatus string
+
+const (
+	PermissionRequestPending  PermissionRequestStatus = "pending"
+	PermissionRequestApproved PermissionRequestStatus = "approved"
+	PermissionRequestRejected PermissionRequestStatus = "rejected"
+)
+
+// PermissionRequest is a request from a user to be granted a permission on a resource.
+// Approving it sets the permission for the user, ReviewerID records who approved or rejected it.
+type PermissionRequest struct {
+	ID                int64                   `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID             int64                   `json:"orgId" xorm:"org_id"`
+	Resource          string                  `json:"resource"`
+	ResourceAttribute string                  `json:"resourceAttribute" xorm:"resource_attribute"`
+	ResourceID        string                  `json:"resourceId" xorm:"resource_id"`
+	UserID            int64                   `json:"userId" xorm:"user_id"`
+	Permission        string                  `json:"permission"`
+	Reason            string                  `json:"reason"`
+	Status            PermissionRequestStatus `json:"status"`
+	ReviewerID        int64                   `json:"reviewerId,omitempty" xorm:"reviewer_id"`
+	Created           time.Time               `json:"created"`
+	Updated           time.Time               `json:"updated"`
+}
+
+func (PermissionRequest) TableName() string {
+	return "permission_request"
+}
+
+// CreatePermissionRequest records a pending request from the user for permission on the resource.
+func (s *Service) CreatePermissionRequest(ctx context.Context, orgID, userID int64, resourceID, permission, reason string) (*PermissionRequest, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.CreatePermissionRequest")
+	defer span.End()
+
+	if permission == "" || permission == DenyPermission {
+		return nil, ErrInvalidPermission.Build(ErrInvalidPermissionData(permission))
+	}
+	if _, err := s.mapPermission(orgID, permission); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateUser(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request := &PermissionRequest{
+		OrgID:             orgID,
+		Resource:          s.options.Resource,
+		ResourceAttribute: s.options.ResourceAttribute,
+		ResourceID:        resourceID,
+		UserID:            userID,
+		Permission:        permission,
+		Reason:            reason,
+		Status:            PermissionRequestPending,
+		Created:           now,
+		Updated:           now,
+	}
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ApprovePermissionRequest grants the requested permission to the user and marks the request as approved by the reviewer.
+func (s *Service) ApprovePermissionRequest(ctx context.Context, orgID, requestID, reviewerID int64) (*PermissionRequest, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.ApprovePermissionRequest")
+	defer span.End()
+
+	request, err := s.getPendingPermissionRequest(ctx, orgID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The request is marked as reviewed before the permission is granted in the same transaction, a concurrent review
+	// fails on the status of the request before granting anything
+	err = s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.reviewPermissionRequest(ctx, request, PermissionRequestApproved, reviewerID); err != nil {
+			return err
+		}
+		_, err := s.SetUserPermission(ctx, orgID, accesscontrol.User{ID: request.UserID}, request.ResourceID, request.Permission)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// RejectPermissionRequest marks the request as rejected by the reviewer, no permission is granted.
+func (s *Service) RejectPermissionRequest(ctx context.Context, orgID, requestID, reviewerID int64) (*PermissionRequest, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RejectPermissionRequest")
+	defer span.End()
+
+	request, err := s.getPendingPermissionRequest(ctx, orgID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewPermissionRequest(ctx, request, PermissionRequestRejected, reviewerID); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ListPendingPermissionRequests returns the requests waiting for review for the resource, oldest first.
+func (s *Service) ListPendingPermissionRequests(ctx context.Context, orgID int64, resourceID string) ([]PermissionRequest, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.ListPendingPermissionRequests")
+	defer span.End()
+
+	requests := []PermissionRequest{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND resource = ? AND resource_id = ? AND status = ?",
+			orgID, s.options.Resource, resourceID, PermissionRequestPending).
+			Asc("id").
+			Find(&requests)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (s *Service) getPendingPermissionRequest(ctx context.Context, orgID, requestID int64) (*PermissionRequest, error) {
+	request := &PermissionRequest{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("id = ? AND org_id = ? AND resource = ?", requestID, orgID, s.options.Resource).Get(request)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrPermissionRequestNotFound.Errorf("permission request %d not found", requestID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != PermissionRequestPending {
+		return nil, ErrPermissionRequestNotPending.Errorf("permission request %d is %s", requestID, request.Status)
+	}
+	return request, nil
+}
+
+// reviewPermissionRequest updates the status of a pending request, it fails if the request was reviewed concurrently.
+func (s *Service) reviewPermissionRequest(ctx context.Context, request *PermissionRequest, status PermissionRequestStatus, reviewerID int64) error {
+	request.Status = status
+	request.ReviewerID = reviewerID
+	request.Updated = time.Now()
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND status = ?", request.ID, PermissionRequestPending).
+			Cols("status", "reviewer_id", "updated").
+			Update(request)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrPermissionRequestNotPending.Errorf("permission request %d has already been reviewed", request.ID)
+		}
+		return nil
+	})
+}
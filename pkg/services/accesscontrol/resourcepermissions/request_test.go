@@ -0,0 +1,90 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_PermissionRequests(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		ResourceAttribute:    "uid",
+		Assignments:          Assignments{Users: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+	})
+
+	requester, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "requester", OrgID: 1})
+	require.NoError(t, err)
+	reviewer, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "reviewer", OrgID: 1})
+	require.NoError(t, err)
+
+	_, err = service.CreatePermissionRequest(ctx, 1, requester.ID, "dash", "Admin", "")
+	assert.ErrorIs(t, err, ErrInvalidPermission)
+
+	approved, err := service.CreatePermissionRequest(ctx, 1, requester.ID, "dash", "View", "need to debug")
+	require.NoError(t, err)
+	rejected, err := service.CreatePermissionRequest(ctx, 1, requester.ID, "dash", "View", "")
+	require.NoError(t, err)
+
+	pending, err := service.ListPendingPermissionRequests(ctx, 1, "dash")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "need to debug", pending[0].Reason)
+
+	t.Run("should grant the permission and record the reviewer on approval", func(t *testing.T) {
+		request, err := service.ApprovePermissionRequest(ctx, 1, approved.ID, reviewer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, PermissionRequestApproved, request.Status)
+		assert.Equal(t, reviewer.ID, request.ReviewerID)
+
+		permissions, err := service.GetPermissions(ctx, &user.SignedInUser{
+			OrgID: 1,
+			Permissions: map[int64]map[string][]string{
+				1: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+			},
+		}, "dash")
+		require.NoError(t, err)
+		require.Len(t, permissions, 1)
+		assert.Equal(t, requester.ID, permissions[0].UserId)
+	})
+
+	t.Run("should not grant the permission on rejection", func(t *testing.T) {
+		request, err := service.RejectPermissionRequest(ctx, 1, rejected.ID, reviewer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, PermissionRequestRejected, request.Status)
+	})
+
+	t.Run("should not review a request twice", func(t *testing.T) {
+		_, err := service.ApprovePermissionRequest(ctx, 1, rejected.ID, reviewer.ID)
+		assert.ErrorIs(t, err, ErrPermissionRequestNotPending)
+
+		_, err = service.RejectPermissionRequest(ctx, 2, approved.ID, reviewer.ID)
+		assert.ErrorIs(t, err, ErrPermissionRequestNotFound)
+
+		pending, err := service.ListPendingPermissionRequests(ctx, 1, "dash")
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+	t.Run("should leave the request pending when the permission can't be granted", func(t *testing.T) {
+		removed, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "removed", OrgID: 1})
+		require.NoError(t, err)
+		request, err := service.CreatePermissionRequest(ctx, 1, removed.ID, "dash", "View", "")
+		require.NoError(t, err)
+		require.NoError(t, usrSvc.Delete(ctx, &user.DeleteUserCommand{UserID: removed.ID}))
+
+		_, err = service.ApprovePermissionRequest(ctx, 1, request.ID, reviewer.ID)
+		assert.ErrorIs(t, err, accesscontrol.ErrAssignmentEntityNotFound)
+
+		pending, err := service.ListPendingPermissionRequests(ctx, 1, "dash")
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, request.ID, pending[0].ID)
+	})
+}
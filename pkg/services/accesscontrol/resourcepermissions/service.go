@@ -4,20 +4,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/exp/slices"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/pluginutils"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
@@ -51,24 +58,76 @@ type Store interface {
 		hook BuiltinResourceHookFunc,
 	) (*accesscontrol.ResourcePermission, error)
 
+	// SetGroupResourcePermission sets permissions for managed external group role on a resource
+	SetGroupResourcePermission(
+		ctx context.Context, orgID int64, groupID string,
+		cmd SetResourcePermissionCommand,
+		hook GroupResourceHookFunc,
+	) (*accesscontrol.ResourcePermission, error)
+
 	SetResourcePermissions(
 		ctx context.Context, orgID int64,
 		commands []SetResourcePermissionsCommand,
 		hooks ResourceHooks,
 	) ([]accesscontrol.ResourcePermission, error)
 
+	// SetResourcePermissionsBatches sets the permissions of all the batches in a single transaction, calling the
+	// hooks of each batch
+	SetResourcePermissionsBatches(ctx context.Context, orgID int64, batches []ResourcePermissionsBatch) ([]accesscontrol.ResourcePermission, error)
+
 	// GetResourcePermissions will return all permission for supplied resource id
 	GetResourcePermissions(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) ([]accesscontrol.ResourcePermission, error)
 
+	// GetResourcePermissionsPage returns the page of the permissions of the resource selected by the limit and offset
+	// of the query with the total number of permissions
+	GetResourcePermissionsPage(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) (*ResourcePermissionsPage, error)
+
 	// DeleteResourcePermissions will delete all permissions for supplied resource id
 	DeleteResourcePermissions(ctx context.Context, orgID int64, cmd *DeleteResourcePermissionsCmd) error
+
+	// RenameResourcePermissions moves all permissions of a resource from its old id to its new one
+	RenameResourcePermissions(ctx context.Context, orgID int64, cmd *RenameResourcePermissionsCmd) error
+
+	// GetResourcePermissionsVersion returns a version of the permissions of a resource that changes when they change
+	GetResourcePermissionsVersion(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) (string, error)
+
+	// HasMissingManagedRoles returns true when one of the managed roles doesn't exist in the org yet
+	HasMissingManagedRoles(ctx context.Context, orgID int64, names []string) (bool, error)
+}
+
+// DenyPermission is the permission excluding an assignee from the access granted by other roles, see Options.AllowDeny.
+const DenyPermission = "Deny"
+
+const (
+	// adminPermission is the permission granting the management of the permissions of the resource
+	adminPermission = "Admin"
+	// editPermission also grants the management of the permissions in the orgs configured with the rbac
+	// edit_permission_can_share_orgs setting
+	editPermission = "Edit"
+)
+
+// webhookNotifier is implemented by the access control services owning the notifier of the permission webhook.
+type webhookNotifier interface {
+	Webhook() *webhook.Notifier
+}
+
+// wildcardRegistryOwner is implemented by the access control services owning the registry of the declared wildcards.
+type wildcardRegistryOwner interface {
+	WildcardRegistry() *accesscontrol.WildcardRegistry
 }
 
 func New(cfg *setting.Cfg,
 	options Options, features featuremgmt.FeatureToggles, router routing.RouteRegister, license licensing.Licensing,
 	ac accesscontrol.AccessControl, service accesscontrol.Service, sqlStore db.DB,
-	teamService team.Service, userService user.Service, actionSetService ActionSetService,
+	teamService team.Service, userService user.Service, actionSetService ActionSetService, defaultPolicy *DefaultPermissionsPolicy,
+	kv kvstore.KVStore,
 ) (*Service, error) {
+	if options.AllowDeny && !zanzana.SupportsDeny(options.Resource) {
+		return nil, fmt.Errorf("deny permissions are not supported on %s", options.Resource)
+	}
+
+	options.PermissionsToActions = withPermissionsManagementActions(options.Resource, options.PermissionsToActions)
+
 	permissions := make([]string, 0, len(options.PermissionsToActions))
 	actionSet := make(map[string]struct{})
 	for permission, actions := range options.PermissionsToActions {
@@ -76,7 +135,10 @@ func New(cfg *setting.Cfg,
 		for _, a := range actions {
 			actionSet[a] = struct{}{}
 		}
-		if features.IsEnabled(context.Background(), featuremgmt.FlagAccessActionSets) {
+	}
+
+	if features.IsEnabled(context.Background(), featuremgmt.FlagAccessActionSets) {
+		for permission, actions := range composeActionSets(options.Resource, options.PermissionsToActions) {
 			actionSetService.StoreActionSet(GetActionSetName(options.Resource, permission), actions)
 		}
 	}
@@ -91,20 +153,41 @@ func New(cfg *setting.Cfg,
 		actions = append(actions, action)
 	}
 
+	permissionStore := NewStore(cfg, sqlStore, features)
+	permissionStore.actionSets = actionSetService
+	permissionStore.hooksV2 = options.HooksV2
+	if n, ok := service.(webhookNotifier); ok {
+		permissionStore.webhook = n.Webhook()
+	}
+	if o, ok := service.(wildcardRegistryOwner); ok {
+		permissionStore.wildcards = o.WildcardRegistry()
+	}
+	if len(options.InheritedWildcards) > 0 {
+		if permissionStore.wildcards == nil {
+			// The service doesn't share a registry, the wildcards are only matched by the resource permissions
+			permissionStore.wildcards = accesscontrol.NewWildcardRegistry()
+		}
+		permissionStore.wildcards.Register(accesscontrol.Scope(options.Resource, options.ResourceAttribute, ""), options.InheritedWildcards...)
+	}
+
 	s := &Service{
-		ac:           ac,
-		features:     features,
-		store:        NewStore(cfg, sqlStore, features),
-		options:      options,
-		license:      license,
-		log:          log.New("resourcepermissions"),
-		permissions:  permissions,
-		actions:      actions,
-		sqlStore:     sqlStore,
-		service:      service,
-		teamService:  teamService,
-		userService:  userService,
-		actionSetSvc: actionSetService,
+		cfg:           cfg,
+		ac:            ac,
+		features:      features,
+		store:         permissionStore,
+		wildcards:     permissionStore.wildcards,
+		options:       options,
+		license:       license,
+		log:           log.New("resourcepermissions"),
+		permissions:   permissions,
+		actions:       actions,
+		sqlStore:      sqlStore,
+		service:       service,
+		teamService:   teamService,
+		userService:   userService,
+		actionSetSvc:  actionSetService,
+		kv:            kv,
+		defaultPolicy: defaultPolicy,
 	}
 
 	s.api = newApi(cfg, ac, router, s)
@@ -114,12 +197,15 @@ func New(cfg *setting.Cfg,
 	}
 
 	s.api.registerEndpoints()
+	s.scheduleResumePermissionJobs()
+	s.scheduleIdempotencyCleanup()
 
 	return s, nil
 }
 
 // Service is used to create access control sub system including api / and service for managed resource permission
 type Service struct {
+	cfg      *setting.Cfg
 	ac       accesscontrol.AccessControl
 	features featuremgmt.FeatureToggles
 	service  accesscontrol.Service
@@ -135,12 +221,71 @@ type Service struct {
 	teamService  team.Service
 	userService  user.Service
 	actionSetSvc ActionSetService
+	// kv stores the results of idempotent permission writes, it is backed by the kv_store table of sqlStore where the
+	// idempotency keys are claimed
+	kv kvstore.KVStore
+	// defaultPolicy decides the permissions set on newly created resources
+	defaultPolicy *DefaultPermissionsPolicy
+	// wildcards holds the wildcards declared by the resources, shared with the access control service
+	wildcards *accesscontrol.WildcardRegistry
 }
 
 func (s *Service) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
+	return s.getPermissions(ctx, user, resourceID, false, "")
+}
+
+// GetPermissionsWithActionSets works like GetPermissions but also returns the actions granted by each
+// action set found in the permissions, so callers don't need to resolve the action sets themselves.
+func (s *Service) GetPermissionsWithActionSets(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
+	return s.getPermissions(ctx, user, resourceID, true, "")
+}
+
+// getPermissionsVersion returns a version of the permissions of the resource, it changes whenever a permission on
+// the resource or on the resources it inherits from is added, removed or updated.
+func (s *Service) getPermissionsVersion(ctx context.Context, orgID int64, resourceID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.getPermissionsVersion")
+	defer span.End()
+
+	var inheritedScopes []string
+	if s.options.InheritedScopesSolver != nil {
+		var err error
+		inheritedScopes, err = s.options.InheritedScopesSolver(ctx, orgID, resourceID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return s.store.GetResourcePermissionsVersion(ctx, orgID, GetResourcePermissionsQuery{
+		Resource:          s.options.Resource,
+		ResourceID:        resourceID,
+		ResourceAttribute: s.options.ResourceAttribute,
+		InheritedScopes:   inheritedScopes,
+	})
+}
+
+// getPermissions returns the permissions of the resource, when permission is set only the assignments granting
+// at least that permission are returned.
+func (s *Service) getPermissions(ctx context.Context, user identity.Requester, resourceID string, expandActionSets bool, permission string) ([]accesscontrol.ResourcePermission, error) {
+	page, err := s.getPermissionsPage(ctx, user, resourceID, expandActionSets, permission, false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Permissions, nil
+}
+
+// getPermissionsPage works like getPermissions but only returns the page of the permissions selected by limit and
+// offset, every permission is returned when both are zero. When onlyMappable is set the assignments that MapActions
+// can't map to a permission are left out before paging, so they don't leave holes in the pages.
+func (s *Service) getPermissionsPage(
+	ctx context.Context, user identity.Requester, resourceID string, expandActionSets bool, permission string, onlyMappable bool, limit, offset int,
+) (*ResourcePermissionsPage, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetPermissions")
 	defer span.End()
 
+	if _, ok := s.options.PermissionsToActions[permission]; permission != "" && !ok {
+		return nil, ErrInvalidPermission.Build(ErrInvalidPermissionData(permission))
+	}
+
 	var inheritedScopes []string
 	if s.options.InheritedScopesSolver != nil {
 		var err error
@@ -150,38 +295,52 @@ func (s *Service) GetPermissions(ctx context.Context, user identity.Requester, r
 		}
 	}
 
-	actions := s.actions
-	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
-		for _, action := range s.actions {
-			actionSets := s.actionSetSvc.ResolveAction(action)
-			for _, actionSet := range actionSets {
-				if !slices.Contains(actions, actionSet) {
-					actions = append(actions, actionSet)
-				}
-			}
+	var grantingOneOf [][]string
+	if onlyMappable {
+		grantingOneOf = make([][]string, 0, len(s.permissions))
+		for _, p := range s.permissions {
+			grantingOneOf = append(grantingOneOf, s.options.PermissionsToActions[p])
 		}
 	}
 
-	resourcePermissions, err := s.store.GetResourcePermissions(ctx, user.GetOrgID(), GetResourcePermissionsQuery{
+	page, err := s.store.GetResourcePermissionsPage(ctx, user.GetOrgID(), GetResourcePermissionsQuery{
 		User:                 user,
-		Actions:              actions,
+		Actions:              s.queryActions(ctx),
 		Resource:             s.options.Resource,
 		ResourceID:           resourceID,
 		ResourceAttribute:    s.options.ResourceAttribute,
 		InheritedScopes:      inheritedScopes,
 		OnlyManaged:          s.options.OnlyManaged,
 		EnforceAccessControl: s.license.FeatureEnabled("accesscontrol.enforcement"),
+		ExpandActionSets:     expandActionSets && s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets),
+		Permission:           permission,
+		GrantingOneOf:        grantingOneOf,
+		Limit:                limit,
+		Offset:               offset,
 	})
 	if err != nil {
 		return nil, err
 	}
+	resourcePermissions := page.Permissions
+
+	if err := s.setTemporaryPermissionExpiry(ctx, user.GetOrgID(), resourceID, resourcePermissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.setPermissionMetadata(ctx, user.GetOrgID(), resourceID, resourcePermissions); err != nil {
+		return nil, err
+	}
+
+	for i := range resourcePermissions {
+		resourcePermissions[i].Permission = s.storedPermission(resourcePermissions[i].Actions)
+	}
 
 	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
 		for i := range resourcePermissions {
 			actions := resourcePermissions[i].Actions
 			var expandedActions []string
 			for _, action := range actions {
-				if isFolderOrDashboardAction(action) {
+				if isActionSetResourceAction(action) {
 					actionSetActions := s.actionSetSvc.ResolveActionSet(action)
 					if len(actionSetActions) > 0 {
 						// Add all actions for folder
@@ -205,14 +364,35 @@ func (s *Service) GetPermissions(ctx context.Context, user identity.Requester, r
 		}
 	}
 
-	return resourcePermissions, nil
+	return page, nil
+}
+
+// queryActions returns the actions of the resource together with the action sets granting them when action sets
+// are enabled, permissions granting either are permissions of the resource.
+func (s *Service) queryActions(ctx context.Context) []string {
+	actions := s.actions
+	if s.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+		for _, action := range s.actions {
+			actionSets := s.actionSetSvc.ResolveAction(action)
+			for _, actionSet := range actionSets {
+				if !slices.Contains(actions, actionSet) {
+					actions = append(actions, actionSet)
+				}
+			}
+		}
+	}
+	return actions
 }
 
 func (s *Service) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetUserPermission")
 	defer span.End()
 
-	actions, err := s.mapPermission(permission)
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.mapPermission(orgID, permission)
 	if err != nil {
 		return nil, err
 	}
@@ -225,20 +405,39 @@ func (s *Service) SetUserPermission(ctx context.Context, orgID int64, user acces
 		return nil, err
 	}
 
-	return s.store.SetUserResourcePermission(ctx, orgID, user, SetResourcePermissionCommand{
+	if err := s.checkQuota(ctx, orgID, grantedRoles(permission, accesscontrol.ManagedUserRoleName(user.ID))...); err != nil {
+		return nil, err
+	}
+
+	resourcePermission, err := s.store.SetUserResourcePermission(ctx, orgID, user, SetResourcePermissionCommand{
 		Actions:           actions,
 		Permission:        permission,
 		Resource:          s.options.Resource,
 		ResourceID:        resourceID,
 		ResourceAttribute: s.options.ResourceAttribute,
+		Deny:              permission == DenyPermission,
 	}, s.options.OnSetUser)
+	if err != nil {
+		return nil, err
+	}
+
+	// The permission was explicitly set, it must not be revoked when a previous temporary grant expires
+	if err := s.deleteTemporaryPermission(ctx, orgID, resourceID, user.ID); err != nil {
+		return nil, err
+	}
+
+	return resourcePermission, nil
 }
 
 func (s *Service) SetTeamPermission(ctx context.Context, orgID, teamID int64, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetTeamPermission")
 	defer span.End()
 
-	actions, err := s.mapPermission(permission)
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.mapPermission(orgID, permission)
 	if err != nil {
 		return nil, err
 	}
@@ -251,12 +450,17 @@ func (s *Service) SetTeamPermission(ctx context.Context, orgID, teamID int64, re
 		return nil, err
 	}
 
+	if err := s.checkQuota(ctx, orgID, grantedRoles(permission, accesscontrol.ManagedTeamRoleName(teamID))...); err != nil {
+		return nil, err
+	}
+
 	return s.store.SetTeamResourcePermission(ctx, orgID, teamID, SetResourcePermissionCommand{
 		Actions:           actions,
 		Permission:        permission,
 		Resource:          s.options.Resource,
 		ResourceID:        resourceID,
 		ResourceAttribute: s.options.ResourceAttribute,
+		Deny:              permission == DenyPermission,
 	}, s.options.OnSetTeam)
 }
 
@@ -264,7 +468,11 @@ func (s *Service) SetBuiltInRolePermission(ctx context.Context, orgID int64, bui
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetBuiltInRolePermission")
 	defer span.End()
 
-	actions, err := s.mapPermission(permission)
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	actions, err := s.mapPermission(orgID, permission)
 	if err != nil {
 		return nil, err
 	}
@@ -277,15 +485,57 @@ func (s *Service) SetBuiltInRolePermission(ctx context.Context, orgID int64, bui
 		return nil, err
 	}
 
+	if err := s.checkQuota(ctx, orgID, grantedRoles(permission, accesscontrol.ManagedBuiltInRoleName(builtInRole))...); err != nil {
+		return nil, err
+	}
+
 	return s.store.SetBuiltInResourcePermission(ctx, orgID, builtInRole, SetResourcePermissionCommand{
 		Actions:           actions,
 		Permission:        permission,
 		Resource:          s.options.Resource,
 		ResourceID:        resourceID,
 		ResourceAttribute: s.options.ResourceAttribute,
+		Deny:              permission == DenyPermission,
 	}, s.options.OnSetBuiltInRole)
 }
 
+// SetGroupPermission sets the permission on the resource for the members of the external group.
+// Membership is synced on login, members are granted the permission without it being copied to their managed roles.
+func (s *Service) SetGroupPermission(ctx context.Context, orgID int64, groupID, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetGroupPermission")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if !s.options.Assignments.Groups {
+		return nil, ErrInvalidAssignment.Build(ErrInvalidAssignmentData("groups"))
+	}
+
+	actions, err := s.mapPermission(orgID, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkQuota(ctx, orgID, grantedRoles(permission, accesscontrol.ManagedGroupRoleName(groupID))...); err != nil {
+		return nil, err
+	}
+
+	return s.store.SetGroupResourcePermission(ctx, orgID, groupID, SetResourcePermissionCommand{
+		Actions:           actions,
+		Permission:        permission,
+		Resource:          s.options.Resource,
+		ResourceID:        resourceID,
+		ResourceAttribute: s.options.ResourceAttribute,
+		Deny:              permission == DenyPermission,
+	}, s.options.OnSetGroup)
+}
+
 func (s *Service) SetPermissions(
 	ctx context.Context, orgID int64, resourceID string,
 	commands ...accesscontrol.SetResourcePermissionCommand,
@@ -293,10 +543,71 @@ func (s *Service) SetPermissions(
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetPermissions")
 	defer span.End()
 
+	if err := s.checkWritable(); err != nil {
+		return nil, err
+	}
+
 	if err := s.validateResource(ctx, orgID, resourceID); err != nil {
 		return nil, err
 	}
 
+	idempotencyKey, hash, err := s.idempotency(ctx, resourceID, commands)
+	if err != nil {
+		return nil, err
+	}
+	if idempotencyKey != "" {
+		previous, found, err := s.claimIdempotencyKey(ctx, orgID, idempotencyKey, hash)
+		if err != nil || found {
+			return previous, err
+		}
+	}
+
+	var resourcePermissions []accesscontrol.ResourcePermission
+	dbCommands, err := s.toDBCommands(ctx, orgID, resourceID, commands)
+	if err == nil {
+		resourcePermissions, err = s.store.SetResourcePermissions(ctx, orgID, dbCommands, s.resourceHooks())
+	}
+	if err != nil {
+		if idempotencyKey != "" {
+			s.releaseIdempotencyKey(ctx, orgID, idempotencyKey)
+		}
+		return nil, err
+	}
+
+	if idempotencyKey != "" {
+		// The permissions are set, failing to remember the key only makes a retry apply them again
+		if err := s.setIdempotentResult(ctx, orgID, idempotencyKey, hash, resourcePermissions); err != nil {
+			s.log.Warn("Failed to store the result of an idempotent permission write", "resource", s.options.Resource, "resourceID", resourceID, "error", err)
+		}
+	}
+
+	// The permissions are committed, failing hooks can't undo them and are only logged
+	if err := s.options.PostCommitHooks.run(ctx, orgID, dbCommands, s.options.PostCommitHookConcurrency); err != nil {
+		s.log.Warn("Post commit hooks failed", "resource", s.options.Resource, "resourceID", resourceID, "error", err)
+	}
+	return resourcePermissions, nil
+}
+
+// toDBCommands validates the commands setting the permissions of the resource and maps their permission to the
+// actions stored.
+func (s *Service) toDBCommands(
+	ctx context.Context, orgID int64, resourceID string, commands []accesscontrol.SetResourcePermissionCommand,
+) ([]SetResourcePermissionsCommand, error) {
+	roles := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		switch {
+		case cmd.UserID != 0:
+			roles = append(roles, grantedRoles(cmd.Permission, accesscontrol.ManagedUserRoleName(cmd.UserID))...)
+		case cmd.TeamID != 0:
+			roles = append(roles, grantedRoles(cmd.Permission, accesscontrol.ManagedTeamRoleName(cmd.TeamID))...)
+		default:
+			roles = append(roles, grantedRoles(cmd.Permission, accesscontrol.ManagedBuiltInRoleName(cmd.BuiltinRole))...)
+		}
+	}
+	if err := s.checkQuota(ctx, orgID, roles...); err != nil {
+		return nil, err
+	}
+
 	dbCommands := make([]SetResourcePermissionsCommand, 0, len(commands))
 	for _, cmd := range commands {
 		if cmd.UserID != 0 {
@@ -313,7 +624,7 @@ func (s *Service) SetPermissions(
 			}
 		}
 
-		actions, err := s.mapPermission(cmd.Permission)
+		actions, err := s.mapPermission(orgID, cmd.Permission)
 		if err != nil {
 			return nil, err
 		}
@@ -328,18 +639,29 @@ func (s *Service) SetPermissions(
 				ResourceID:        resourceID,
 				ResourceAttribute: s.options.ResourceAttribute,
 				Permission:        cmd.Permission,
+				Deny:              cmd.Permission == DenyPermission,
 			},
 		})
 	}
+	return dbCommands, nil
+}
 
-	return s.store.SetResourcePermissions(ctx, orgID, dbCommands, ResourceHooks{
+func (s *Service) resourceHooks() ResourceHooks {
+	return ResourceHooks{
 		User:        s.options.OnSetUser,
 		Team:        s.options.OnSetTeam,
 		BuiltInRole: s.options.OnSetBuiltInRole,
-	})
+		Group:       s.options.OnSetGroup,
+	}
 }
 
 func (s *Service) MapActions(permission accesscontrol.ResourcePermission) string {
+	if permission.Deny {
+		return DenyPermission
+	}
+	if _, ok := s.options.PermissionsToActions[permission.Permission]; ok {
+		return permission.Permission
+	}
 	for _, p := range s.permissions {
 		if permission.Contains(s.options.PermissionsToActions[p]) {
 			return p
@@ -349,6 +671,10 @@ func (s *Service) MapActions(permission accesscontrol.ResourcePermission) string
 }
 
 func (s *Service) DeleteResourcePermissions(ctx context.Context, orgID int64, resourceID string) error {
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
 	return s.store.DeleteResourcePermissions(ctx, orgID, &DeleteResourcePermissionsCmd{
 		Resource:          s.options.Resource,
 		ResourceAttribute: s.options.ResourceAttribute,
@@ -356,15 +682,95 @@ func (s *Service) DeleteResourcePermissions(ctx context.Context, orgID int64, re
 	})
 }
 
-func (s *Service) mapPermission(permission string) ([]string, error) {
+// RenameResourcePermissions moves the permissions of the resource from oldResourceID to newResourceID so they survive
+// a change of the resource identifier (e.g. a renamed contact point), the zanzana tuples of the moved permissions are
+// rewritten through the outbox once the rename is committed.
+func (s *Service) RenameResourcePermissions(ctx context.Context, orgID int64, oldResourceID, newResourceID string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RenameResourcePermissions")
+	defer span.End()
+
+	if err := s.checkWritable(); err != nil {
+		return err
+	}
+
+	if oldResourceID == newResourceID {
+		return nil
+	}
+
+	if err := s.validateResource(ctx, orgID, newResourceID); err != nil {
+		return err
+	}
+
+	return s.store.RenameResourcePermissions(ctx, orgID, &RenameResourcePermissionsCmd{
+		Resource:          s.options.Resource,
+		ResourceAttribute: s.options.ResourceAttribute,
+		OldResourceID:     oldResourceID,
+		NewResourceID:     newResourceID,
+	})
+}
+
+// checkWritable returns ErrReadOnly when permission changes are disabled by the rbac permissions_read_only setting.
+func (s *Service) checkWritable() error {
+	if s.cfg != nil && s.cfg.RBAC.PermissionsReadOnly {
+		return ErrReadOnly.Errorf("permission changes are disabled")
+	}
+	return nil
+}
+
+// checkQuota returns accesscontrol.ErrQuotaReached when one of the managed roles granted a permission doesn't exist
+// yet and the org has reached its quota of roles or role assignments. Only a new managed role adds a role and a role
+// assignment, granting more permissions to an assignee that has one or removing permissions is always allowed.
+func (s *Service) checkQuota(ctx context.Context, orgID int64, roles ...string) error {
+	checker, ok := s.service.(accesscontrol.QuotaChecker)
+	if !ok || len(roles) == 0 {
+		return nil
+	}
+
+	missing, err := s.store.HasMissingManagedRoles(ctx, orgID, roles)
+	if err != nil || !missing {
+		return err
+	}
+	return checker.CheckOrgQuota(ctx, orgID)
+}
+
+// grantedRoles returns the managed role when the permission grants access, removing a permission doesn't count
+// towards the quotas.
+func grantedRoles(permission, role string) []string {
+	if permission == "" {
+		return nil
+	}
+	return []string{role}
+}
+
+// storedPermission returns the permission whose action set is among the stored actions, the largest one when there
+// are several. Resources without action sets don't record the permission.
+func (s *Service) storedPermission(actions []string) string {
+	for _, p := range s.permissions {
+		if slices.Contains(actions, GetActionSetName(s.options.Resource, p)) {
+			return p
+		}
+	}
+	return ""
+}
+
+// mapPermission returns the actions granted by the permission in the org.
+func (s *Service) mapPermission(orgID int64, permission string) ([]string, error) {
 	if permission == "" {
 		return []string{}, nil
 	}
 
+	if permission == DenyPermission && s.options.AllowDeny {
+		return s.actions, nil
+	}
+
 	for k, v := range s.options.PermissionsToActions {
-		if permission == k {
-			return v, nil
+		if permission != k {
+			continue
 		}
+		if permission == editPermission && s.cfg != nil && s.cfg.RBAC.EditPermissionCanShare(orgID) {
+			return appendMissing(v, permissionsManagementActions(s.options.Resource)...), nil
+		}
+		return v, nil
 	}
 	return nil, ErrInvalidPermission.Build(ErrInvalidPermissionData(permission))
 }
@@ -423,6 +829,15 @@ func (s *Service) validateBuiltinRole(ctx context.Context, builtinRole string) e
 		return ErrInvalidAssignment.Build(ErrInvalidAssignmentData("builtInRoles"))
 	}
 
+	// Unlike fixed role grants, managed resource permissions can be assigned to the None basic role
+	if org.RoleType(builtinRole) == org.RoleNone {
+		return nil
+	}
+
+	if builtinRole == accesscontrol.RoleGrafanaAdmin && !s.options.AllowGrafanaAdmin {
+		return ErrInvalidAssignment.Build(ErrInvalidAssignmentData(accesscontrol.RoleGrafanaAdmin))
+	}
+
 	if err := accesscontrol.ValidateBuiltInRoles([]string{builtinRole}); err != nil {
 		return err
 	}
@@ -492,19 +907,29 @@ type ActionSetStore interface {
 	// ExpandActionSetsWithFilter takes a set of permissions that might include some action set permissions, and returns a set of permissions with action sets expanded into underlying permissions.
 	// When action sets are expanded into the underlying permissions only those permissions whose action is matched by actionMatcher are included.
 	ExpandActionSetsWithFilter(permissions []accesscontrol.Permission, actionMatcher func(action string) bool) []accesscontrol.Permission
+	// ActionSets returns the names of the stored action sets.
+	ActionSets() []string
 }
 
+var _ accesscontrol.ActionSetLister = &ActionSetSvc{}
+
 type ActionSetSvc struct {
 	features featuremgmt.FeatureToggles
 	store    ActionSetStore
+	// pluginActionSets are the action sets declared by plugins for their own resources. Plugins register them while
+	// permissions are checked, the set is replaced by an updated copy under pluginActionSetsMu and read without lock.
+	pluginActionSetsMu sync.Mutex
+	pluginActionSets   atomic.Pointer[map[string]struct{}]
 }
 
 // NewActionSetService returns a new instance of InMemoryActionSetService.
 func NewActionSetService(features featuremgmt.FeatureToggles) ActionSetService {
-	return &ActionSetSvc{
+	svc := &ActionSetSvc{
 		features: features,
 		store:    NewInMemoryActionSetStore(features),
 	}
+	svc.pluginActionSets.Store(&map[string]struct{}{})
+	return svc
 }
 
 // ResolveAction returns all the action sets that the action belongs to.
@@ -512,9 +937,7 @@ func (a *ActionSetSvc) ResolveAction(action string) []string {
 	sets := a.store.ResolveAction(action)
 	filteredSets := make([]string, 0, len(sets))
 	for _, set := range sets {
-		// Only use action sets for folders and dashboards for now
-		// We need to verify that action sets for other resources do not share names with actions (eg, `datasources:read`)
-		if !isFolderOrDashboardAction(set) {
+		if !a.isSupportedActionSet(set) {
 			continue
 		}
 		filteredSets = append(filteredSets, set)
@@ -528,9 +951,7 @@ func (a *ActionSetSvc) ResolveActionPrefix(actionPrefix string) []string {
 	sets := a.store.ResolveActionPrefix(actionPrefix)
 	filteredSets := make([]string, 0, len(sets))
 	for _, set := range sets {
-		// Only use action sets for folders and dashboards for now
-		// We need to verify that action sets for other resources do not share names with actions (eg, `datasources:read`)
-		if !isFolderOrDashboardAction(set) {
+		if !a.isSupportedActionSet(set) {
 			continue
 		}
 		filteredSets = append(filteredSets, set)
@@ -541,9 +962,7 @@ func (a *ActionSetSvc) ResolveActionPrefix(actionPrefix string) []string {
 
 // ResolveActionSet resolves an action set to a list of corresponding actions.
 func (a *ActionSetSvc) ResolveActionSet(actionSet string) []string {
-	// Only use action sets for folders and dashboards for now
-	// We need to verify that action sets for other resources do not share names with actions (eg, `datasources:read`)
-	if !isFolderOrDashboardAction(actionSet) {
+	if !a.isSupportedActionSet(actionSet) {
 		return nil
 	}
 	return a.store.ResolveActionSet(actionSet)
@@ -563,6 +982,18 @@ func (a *ActionSetSvc) StoreActionSet(name string, actions []string) {
 	a.store.StoreActionSet(name, actions)
 }
 
+// ListActionSets returns the actions of the supported action sets, included action sets are resolved to their actions.
+func (a *ActionSetSvc) ListActionSets() map[string][]string {
+	sets := map[string][]string{}
+	for _, set := range a.store.ActionSets() {
+		if !a.isSupportedActionSet(set) {
+			continue
+		}
+		sets[set] = a.store.ResolveActionSet(set)
+	}
+	return sets
+}
+
 // ExpandActionSets takes a set of permissions that might include some action set permissions, and returns a set of permissions with action sets expanded into underlying permissions
 func (a *ActionSetSvc) ExpandActionSets(permissions []accesscontrol.Permission) []accesscontrol.Permission {
 	actionMatcher := func(_ string) bool {
@@ -590,13 +1021,133 @@ func (a *ActionSetSvc) RegisterActionSets(ctx context.Context, pluginID string,
 		if err := pluginutils.ValidatePluginActionSet(pluginID, reg); err != nil {
 			return err
 		}
+		if pluginutils.IsPluginActionSet(pluginID, reg.Action) {
+			a.addPluginActionSet(reg.Action)
+		}
 		a.StoreActionSet(reg.Action, reg.Actions)
 	}
 	return nil
 }
 
-func isFolderOrDashboardAction(action string) bool {
-	return strings.HasPrefix(action, dashboards.ScopeDashboardsRoot) || strings.HasPrefix(action, dashboards.ScopeFoldersRoot)
+// isSupportedActionSet returns true for the action sets of folders, dashboards, library panels and plugin resources.
+// We need to verify that action sets for other resources do not share names with actions (eg, `datasources:read`)
+// before using them, plugin action sets are explicitly declared as such by the plugin.
+func (a *ActionSetSvc) isSupportedActionSet(actionSet string) bool {
+	if isActionSetResourceAction(actionSet) {
+		return true
+	}
+	_, ok := (*a.pluginActionSets.Load())[actionSet]
+	return ok
+}
+
+func (a *ActionSetSvc) addPluginActionSet(actionSet string) {
+	a.pluginActionSetsMu.Lock()
+	defer a.pluginActionSetsMu.Unlock()
+
+	current := *a.pluginActionSets.Load()
+	if _, ok := current[actionSet]; ok {
+		return
+	}
+	updated := maps.Clone(current)
+	updated[actionSet] = struct{}{}
+	a.pluginActionSets.Store(&updated)
+}
+
+// PermissionsToActionsFromActionSets returns the actions of the action set of each permission of the resource, to
+// manage the permissions of plugin resources with the action sets declared by the plugin. Permissions without
+// an action set are left out.
+func PermissionsToActionsFromActionSets(actionSets ActionSetService, resource string, permissions ...string) map[string][]string {
+	permissionsToActions := make(map[string][]string, len(permissions))
+	for _, permission := range permissions {
+		if actions := actionSets.ResolveActionSet(GetActionSetName(resource, permission)); len(actions) > 0 {
+			permissionsToActions[permission] = actions
+		}
+	}
+	return permissionsToActions
+}
+
+// isActionSetResourceAction returns true for the actions of the resources whose managed permissions are granted with
+// action sets: folders, dashboards and library panels.
+func isActionSetResourceAction(action string) bool {
+	return strings.HasPrefix(action, dashboards.ScopeDashboardsRoot) || strings.HasPrefix(action, dashboards.ScopeFoldersRoot) ||
+		strings.HasPrefix(action, libraryelements.ScopeLibraryPanelsRoot)
+}
+
+// composeActionSets returns the action set of each permission, defined with the action set of the largest permission
+// it includes followed by its additional actions. For example folders:admin includes folders:edit and the permission
+// management actions, so actions added to folders:edit are granted by folders:admin as well.
+func composeActionSets(resource string, permissionsToActions map[string][]string) map[string][]string {
+	sets := make(map[string][]string, len(permissionsToActions))
+	for permission, actions := range permissionsToActions {
+		included := ""
+		for other, otherActions := range permissionsToActions {
+			if other == permission || len(otherActions) >= len(actions) || !isSubset(otherActions, actions) {
+				continue
+			}
+			if included == "" || len(otherActions) > len(permissionsToActions[included]) ||
+				(len(otherActions) == len(permissionsToActions[included]) && other < included) {
+				included = other
+			}
+		}
+
+		if included == "" {
+			sets[permission] = append([]string{}, actions...)
+			continue
+		}
+
+		composed := []string{GetActionSetName(resource, included)}
+		for _, action := range actions {
+			if !slices.Contains(permissionsToActions[included], action) {
+				composed = append(composed, action)
+			}
+		}
+		sets[permission] = composed
+	}
+	return sets
+}
+
+func isSubset(actions, of []string) bool {
+	for _, action := range actions {
+		if !slices.Contains(of, action) {
+			return false
+		}
+	}
+	return true
+}
+
+// permissionsManagementActions returns the actions required by the resource permissions endpoints of the resource.
+func permissionsManagementActions(resource string) []string {
+	return []string{
+		fmt.Sprintf("%s.permissions:read", resource),
+		fmt.Sprintf("%s.permissions:write", resource),
+	}
+}
+
+// withPermissionsManagementActions returns a copy of permissionsToActions where the Admin permission grants managing
+// the permissions of the resource, so that editing a resource doesn't imply being able to share it.
+func withPermissionsManagementActions(resource string, permissionsToActions map[string][]string) map[string][]string {
+	if _, ok := permissionsToActions[adminPermission]; !ok {
+		return permissionsToActions
+	}
+
+	withActions := make(map[string][]string, len(permissionsToActions))
+	for permission, actions := range permissionsToActions {
+		withActions[permission] = actions
+	}
+	withActions[adminPermission] = appendMissing(permissionsToActions[adminPermission], permissionsManagementActions(resource)...)
+	return withActions
+}
+
+// appendMissing returns a new slice with the actions that are not already in the slice appended.
+func appendMissing(actions []string, missing ...string) []string {
+	result := make([]string, len(actions), len(actions)+len(missing))
+	copy(result, actions)
+	for _, action := range missing {
+		if !slices.Contains(result, action) {
+			result = append(result, action)
+		}
+	}
+	return result
 }
 
 // GetActionSetName function creates an action set from a list of actions and stores it inmemory.
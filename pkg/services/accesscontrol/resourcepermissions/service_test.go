@@ -2,13 +2,21 @@ package resourcepermissions
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -71,6 +79,362 @@ func TestService_SetUserPermission(t *testing.T) {
 	}
 }
 
+func TestService_SetUserPermissionHooksV2(t *testing.T) {
+	var changes []PermissionChange
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:    "dashboards",
+		Assignments: Assignments{Users: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write", "dashboards:delete"},
+		},
+		HooksV2: ResourceHooksV2{
+			User: func(session *db.Session, orgID int64, user accesscontrol.User, change PermissionChange) error {
+				changes = append(changes, change)
+				return nil
+			},
+		},
+	})
+
+	user, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "test", OrgID: 1})
+	require.NoError(t, err)
+
+	for _, permission := range []string{"Edit", "View", ""} {
+		_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", permission)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, changes, 3)
+	assert.Equal(t, accesscontrol.ManagedUserRoleName(user.ID), changes[0].Role.Name)
+	assert.Equal(t, "1", changes[0].ResourceID)
+	assert.Equal(t, "Edit", changes[0].Permission)
+	assert.Equal(t, []string{"dashboards:delete", "dashboards:read", "dashboards:write"}, changes[0].Added)
+	assert.Empty(t, changes[0].Removed)
+
+	assert.Empty(t, changes[1].Added)
+	assert.Equal(t, []string{"dashboards:delete", "dashboards:write"}, changes[1].Removed)
+
+	assert.Empty(t, changes[2].Added)
+	assert.Equal(t, []string{"dashboards:read"}, changes[2].Removed)
+}
+
+func TestService_SetPermissionsIdempotency(t *testing.T) {
+	calls := 0
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:    "dashboards",
+		Assignments: Assignments{Users: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write"},
+		},
+		OnSetUser: func(session *db.Session, orgID int64, user accesscontrol.User, resourceID, permission string) error {
+			calls++
+			return nil
+		},
+	})
+	service.cfg.RBAC.PermissionIdempotencyWindow = time.Hour
+	caller := &user.SignedInUser{UserID: 1000, OrgID: 1}
+
+	user, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "test", OrgID: 1})
+	require.NoError(t, err)
+
+	ctx := WithIdempotencyKey(context.Background(), "key")
+	view := accesscontrol.SetResourcePermissionCommand{UserID: user.ID, Permission: "View"}
+	first, err := service.SetPermissions(ctx, user.OrgID, "1", view)
+	require.NoError(t, err)
+
+	// A retry returns the first result without running the hooks again
+	retry, err := service.SetPermissions(ctx, user.OrgID, "1", view)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	require.Len(t, retry, 1)
+	assert.Equal(t, first[0].Actions, retry[0].Actions)
+
+	_, err = service.SetPermissions(ctx, user.OrgID, "1", accesscontrol.SetResourcePermissionCommand{UserID: user.ID, Permission: "Edit"})
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	// The key is scoped to the resource
+	_, err = service.SetPermissions(ctx, user.OrgID, "2", view)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	_, err = service.SetPermissions(context.Background(), user.OrgID, "1", view)
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// The key is scoped to the caller, another caller reusing it applies its own write
+	_, err = service.SetPermissions(identity.WithRequester(ctx, caller), user.OrgID, "1", view)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestService_IdempotencyKeyClaim(t *testing.T) {
+	service, _, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		Assignments:          Assignments{Users: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+	})
+	service.cfg.RBAC.PermissionIdempotencyWindow = time.Hour
+	ctx := context.Background()
+
+	_, found, err := service.claimIdempotencyKey(ctx, 1, "key", "hash")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// A concurrent write with the same key can't claim it until the first one is done
+	_, _, err = service.claimIdempotencyKey(ctx, 1, "key", "hash")
+	assert.ErrorIs(t, err, ErrIdempotentWriteInProgress)
+
+	service.releaseIdempotencyKey(ctx, 1, "key")
+	_, found, err = service.claimIdempotencyKey(ctx, 1, "key", "hash")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// The claim of a write that outlived its lease is taken over
+	stale, err := json.Marshal(idempotentResult{Hash: "hash", Created: time.Now().Add(-2 * idempotencyPendingLease), Pending: true})
+	require.NoError(t, err)
+	require.NoError(t, service.kv.Set(ctx, 1, service.idempotencyNamespace(), "stale", string(stale)))
+	_, found, err = service.claimIdempotencyKey(ctx, 1, "stale", "hash")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, _, err = service.claimIdempotencyKey(ctx, 1, "stale", "hash")
+	assert.ErrorIs(t, err, ErrIdempotentWriteInProgress)
+
+	require.NoError(t, service.deleteExpiredIdempotencyKeys(ctx, time.Now()))
+	_, ok, err := service.kv.Get(ctx, 1, service.idempotencyNamespace(), "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, service.deleteExpiredIdempotencyKeys(ctx, time.Now().Add(2*time.Hour)))
+	_, ok, err = service.kv.Get(ctx, 1, service.idempotencyNamespace(), "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestService_SetPermissionsPostCommitHooks(t *testing.T) {
+	var mu sync.Mutex
+	users := map[int64]string{}
+	service, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+		Resource:    "dashboards",
+		Assignments: Assignments{Users: true, Teams: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+		},
+		PostCommitHooks: PostCommitHooks{
+			User: func(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				users[user.ID] = permission
+				return nil
+			},
+			Team: func(ctx context.Context, orgID, teamID int64, resourceID, permission string) error {
+				return errors.New("team hook failed")
+			},
+		},
+	})
+
+	first, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "first", OrgID: 1})
+	require.NoError(t, err)
+	second, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "second", OrgID: 1})
+	require.NoError(t, err)
+	tm, err := teamSvc.CreateTeam(context.Background(), "team", "", first.OrgID)
+	require.NoError(t, err)
+
+	permissions, err := service.SetPermissions(context.Background(), first.OrgID, "1",
+		accesscontrol.SetResourcePermissionCommand{UserID: first.ID, Permission: "View"},
+		accesscontrol.SetResourcePermissionCommand{UserID: second.ID, Permission: "View"},
+		accesscontrol.SetResourcePermissionCommand{TeamID: tm.ID, Permission: "View"},
+	)
+	// The permissions are committed even though a hook failed
+	require.NoError(t, err)
+	assert.Len(t, permissions, 3)
+	assert.Equal(t, map[int64]string{first.ID: "View", second.ID: "View"}, users)
+
+	stored, err := service.GetPermissions(context.Background(), &user.SignedInUser{
+		OrgID:       first.OrgID,
+		Permissions: map[int64]map[string][]string{first.OrgID: {"dashboards.permissions:read": {"dashboards:*"}}},
+	}, "1")
+	require.NoError(t, err)
+	assert.Len(t, stored, 3)
+}
+
+func TestSetBulkPermissions(t *testing.T) {
+	dashboards, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true, Teams: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+		},
+	})
+	folders, err := New(
+		dashboards.cfg, Options{
+			Resource:          "folders",
+			ResourceAttribute: "uid",
+			Assignments:       Assignments{Users: true, Teams: true},
+			PermissionsToActions: map[string][]string{
+				"View": {"folders:read"},
+			},
+		}, dashboards.features, routing.NewRouteRegister(), dashboards.license,
+		dashboards.ac, dashboards.service, dashboards.sqlStore, teamSvc, usrSvc, dashboards.actionSetSvc, dashboards.defaultPolicy,
+		dashboards.kv,
+	)
+	require.NoError(t, err)
+
+	u, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "user", OrgID: 1})
+	require.NoError(t, err)
+	tm, err := teamSvc.CreateTeam(context.Background(), "team", "", 1)
+	require.NoError(t, err)
+
+	signedInUser := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		"dashboards.permissions:read": {"dashboards:*"},
+		"folders.permissions:read":    {"folders:*"},
+	}}}
+
+	t.Run("should set the permissions of all the targets", func(t *testing.T) {
+		permissions, err := SetBulkPermissions(context.Background(), 1,
+			BulkPermissionsTarget{Service: folders, ResourceID: "f1", Commands: []accesscontrol.SetResourcePermissionCommand{
+				{UserID: u.ID, Permission: "View"},
+			}},
+			BulkPermissionsTarget{Service: dashboards, ResourceID: "d1", Commands: []accesscontrol.SetResourcePermissionCommand{
+				{TeamID: tm.ID, Permission: "View"},
+			}},
+			BulkPermissionsTarget{Service: dashboards, ResourceID: "d2", Commands: []accesscontrol.SetResourcePermissionCommand{
+				{UserID: u.ID, Permission: "View"},
+				{TeamID: tm.ID, Permission: "View"},
+			}},
+		)
+		require.NoError(t, err)
+		assert.Len(t, permissions, 4)
+
+		for _, tt := range []struct {
+			service    *Service
+			resourceID string
+			expected   int
+		}{{folders, "f1", 1}, {dashboards, "d1", 1}, {dashboards, "d2", 2}} {
+			stored, err := tt.service.GetPermissions(context.Background(), signedInUser, tt.resourceID)
+			require.NoError(t, err)
+			assert.Len(t, stored, tt.expected, tt.resourceID)
+		}
+	})
+
+	t.Run("should not set any permission when a target is invalid", func(t *testing.T) {
+		_, err := SetBulkPermissions(context.Background(), 1,
+			BulkPermissionsTarget{Service: folders, ResourceID: "f2", Commands: []accesscontrol.SetResourcePermissionCommand{
+				{UserID: u.ID, Permission: "View"},
+			}},
+			BulkPermissionsTarget{Service: dashboards, ResourceID: "d3", Commands: []accesscontrol.SetResourcePermissionCommand{
+				{UserID: u.ID, Permission: "Not real permission"},
+			}},
+		)
+		require.Error(t, err)
+
+		stored, err := folders.GetPermissions(context.Background(), signedInUser, "f2")
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+	})
+}
+
+func TestService_MoveResource(t *testing.T) {
+	service, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+		Resource:          "dashboards",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true, Teams: true},
+		PermissionsToActions: map[string][]string{
+			"View": {"dashboards:read"},
+			"Edit": {"dashboards:read", "dashboards:write"},
+		},
+		InheritedScopesSolver: func(ctx context.Context, orgID int64, resourceID string) ([]string, error) {
+			return []string{"folders:uid:new"}, nil
+		},
+	})
+
+	u, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "user", OrgID: 1})
+	require.NoError(t, err)
+	other, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "other", OrgID: 1})
+	require.NoError(t, err)
+	tm, err := teamSvc.CreateTeam(context.Background(), "team", "", 1)
+	require.NoError(t, err)
+
+	// The new folder grants the user and the team View on its dashboards
+	for _, cmd := range []SetResourcePermissionsCommand{{User: accesscontrol.User{ID: u.ID}}, {TeamID: tm.ID}} {
+		cmd.SetResourcePermissionCommand = SetResourcePermissionCommand{
+			Actions: []string{"dashboards:read"}, Resource: "folders", ResourceAttribute: "uid", ResourceID: "new", Permission: "View",
+		}
+		_, err := service.store.SetResourcePermissions(context.Background(), 1, []SetResourcePermissionsCommand{cmd}, ResourceHooks{})
+		require.NoError(t, err)
+	}
+
+	_, err = service.SetPermissions(context.Background(), 1, "d1",
+		accesscontrol.SetResourcePermissionCommand{UserID: u.ID, Permission: "View"},
+		accesscontrol.SetResourcePermissionCommand{TeamID: tm.ID, Permission: "Edit"},
+		accesscontrol.SetResourcePermissionCommand{UserID: other.ID, Permission: "View"},
+	)
+	require.NoError(t, err)
+
+	t.Run("should keep direct permissions when pruning is not requested", func(t *testing.T) {
+		pruned, err := service.MoveResource(context.Background(), 1, accesscontrol.MoveResourceCommand{
+			ResourceID: "d1", OldParentUID: "old", NewParentUID: "new",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, pruned)
+	})
+
+	t.Run("should only prune the direct permissions granted by the new folder", func(t *testing.T) {
+		pruned, err := service.MoveResource(context.Background(), 1, accesscontrol.MoveResourceCommand{
+			ResourceID: "d1", OldParentUID: "old", NewParentUID: "new", PruneRedundant: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, pruned, 1)
+		assert.Equal(t, u.ID, pruned[0].UserId)
+
+		stored, err := service.GetPermissions(context.Background(), &user.SignedInUser{
+			OrgID:       1,
+			Permissions: map[int64]map[string][]string{1: {"dashboards.permissions:read": {"dashboards:*"}}},
+		}, "d1")
+		require.NoError(t, err)
+		for _, p := range stored {
+			if p.UserId == u.ID {
+				assert.True(t, p.IsInherited, "the user should only keep its inherited permission")
+			}
+		}
+	})
+}
+
+func TestPostCommitHooks_Run(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	hooks := PostCommitHooks{
+		BuiltInRole: func(ctx context.Context, orgID int64, builtInRole, resourceID, permission string) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return fmt.Errorf("%s failed", builtInRole)
+		},
+	}
+
+	commands := make([]SetResourcePermissionsCommand, 0, 8)
+	for i := 0; i < 8; i++ {
+		commands = append(commands, SetResourcePermissionsCommand{BuiltinRole: fmt.Sprintf("role%d", i)})
+	}
+	// Commands without a hook are skipped
+	commands = append(commands, SetResourcePermissionsCommand{TeamID: 1})
+
+	err := hooks.run(context.Background(), 1, commands, 3)
+	require.Error(t, err)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+	for i := 0; i < 8; i++ {
+		assert.ErrorContains(t, err, fmt.Sprintf("role%d failed", i))
+	}
+
+	assert.NoError(t, PostCommitHooks{}.run(context.Background(), 1, commands, 0))
+}
+
 type setTeamPermissionTest struct {
 	desc     string
 	callHook bool
@@ -155,6 +519,161 @@ func TestService_SetBuiltInRolePermission(t *testing.T) {
 	}
 }
 
+func TestService_SetGrafanaAdminPermission(t *testing.T) {
+	for _, allow := range []bool{true, false} {
+		t.Run(fmt.Sprintf("allow Grafana Admin %t", allow), func(t *testing.T) {
+			service, _, _ := setupTestEnvironment(t, Options{
+				Resource:             "dashboards",
+				Assignments:          Assignments{BuiltInRoles: true},
+				AllowGrafanaAdmin:    allow,
+				PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+			})
+
+			// Both ways of setting a permission apply the same rule
+			_, err := service.SetBuiltInRolePermission(context.Background(), 1, accesscontrol.RoleGrafanaAdmin, "1", "View")
+			_, errSet := service.SetPermissions(context.Background(), 1, "2", accesscontrol.SetResourcePermissionCommand{
+				BuiltinRole: accesscontrol.RoleGrafanaAdmin, Permission: "View",
+			})
+			if !allow {
+				assert.ErrorIs(t, err, ErrInvalidAssignment)
+				assert.ErrorIs(t, errSet, ErrInvalidAssignment)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, errSet)
+
+			// The assignment is stored in the org of the resource rather than in the global org
+			permissions, err := service.GetPermissions(context.Background(), &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+				1: {"dashboards.permissions:read": {"dashboards:*"}},
+			}}, "1")
+			require.NoError(t, err)
+			require.Len(t, permissions, 1)
+			assert.Equal(t, accesscontrol.RoleGrafanaAdmin, permissions[0].BuiltInRole)
+		})
+	}
+}
+
+func TestService_SetGroupPermission(t *testing.T) {
+	service, _, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		Assignments:          Assignments{Groups: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+	})
+
+	var hookGroup string
+	service.options.OnSetGroup = func(session *db.Session, orgID int64, groupID, resourceID, permission string) error {
+		hookGroup = groupID
+		return nil
+	}
+
+	_, err := service.SetGroupPermission(context.Background(), 1, "cn=oncall,ou=groups", "1", "View")
+	require.NoError(t, err)
+	assert.Equal(t, "cn=oncall,ou=groups", hookGroup)
+
+	permissions, err := service.GetPermissions(context.Background(), &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {"dashboards.permissions:read": {"dashboards:*"}},
+	}}, "1")
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "cn=oncall,ou=groups", permissions[0].GroupID)
+	assert.Equal(t, []string{"dashboards:read"}, permissions[0].Actions)
+	assert.Equal(t, permissions[0].Scope+"/group:cn=oncall,ou=groups", permissions[0].StableID())
+
+	// Services that don't accept groups reject them
+	service.options.Assignments.Groups = false
+	_, err = service.SetGroupPermission(context.Background(), 1, "cn=oncall,ou=groups", "1", "View")
+	assert.ErrorIs(t, err, ErrInvalidAssignment)
+}
+
+func TestService_PermissionsManagementActions(t *testing.T) {
+	service, _, _ := setupTestEnvironment(t, Options{
+		Resource:    "dashboards",
+		Assignments: Assignments{BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{
+			"View":  {"dashboards:read"},
+			"Edit":  {"dashboards:read", "dashboards:write"},
+			"Admin": {"dashboards:read", "dashboards:write", "dashboards:delete"},
+		},
+	})
+	cfg, err := setting.NewCfgFromBytes([]byte("[rbac]\nedit_permission_can_share_orgs = 2\n"))
+	require.NoError(t, err)
+	service.cfg.RBAC = cfg.RBAC
+
+	// Admin is the only permission granting the management of permissions
+	actions, err := service.mapPermission(1, "Admin")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dashboards:read", "dashboards:write", "dashboards:delete", "dashboards.permissions:read", "dashboards.permissions:write"}, actions)
+
+	actions, err = service.mapPermission(1, "Edit")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dashboards:read", "dashboards:write"}, actions)
+
+	// Edit also grants it in the orgs configured to let editors share resources
+	actions, err = service.mapPermission(2, "Edit")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dashboards:read", "dashboards:write", "dashboards.permissions:read", "dashboards.permissions:write"}, actions)
+
+	actions, err = service.mapPermission(2, "View")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dashboards:read"}, actions)
+}
+
+func TestService_EditPermissionCanShareRoundTrip(t *testing.T) {
+	for _, onlyActionSets := range []bool{false, true} {
+		t.Run(fmt.Sprintf("only store action sets %t", onlyActionSets), func(t *testing.T) {
+			dashboards, usrSvc, teamSvc := setupTestEnvironment(t, Options{
+				Resource:             "dashboards",
+				PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+			})
+			cfg, err := setting.NewCfgFromBytes([]byte("[rbac]\nedit_permission_can_share_orgs = 1\n"))
+			require.NoError(t, err)
+			cfg.RBAC.OnlyStoreAccessActionSets = onlyActionSets
+
+			features := featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets)
+			service, err := New(
+				cfg, Options{
+					Resource:          "dashboards",
+					ResourceAttribute: "uid",
+					Assignments:       Assignments{Users: true},
+					PermissionsToActions: map[string][]string{
+						"View":  {"dashboards:read"},
+						"Edit":  {"dashboards:read", "dashboards:write", "dashboards:delete"},
+						"Admin": {"dashboards:read", "dashboards:write", "dashboards:delete"},
+					},
+				}, features, routing.NewRouteRegister(), dashboards.license,
+				dashboards.ac, dashboards.service, dashboards.sqlStore, teamSvc, usrSvc, NewActionSetService(features), dashboards.defaultPolicy,
+				dashboards.kv,
+			)
+			require.NoError(t, err)
+
+			usr, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "editor", OrgID: 1})
+			require.NoError(t, err)
+			signedInUser := &user.SignedInUser{
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{1: {"dashboards.permissions:read": {"dashboards:*"}}},
+			}
+
+			// Edit grants the same actions as Admin in the org, it is still reported and saved again as Edit
+			_, err = service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: usr.ID}, "1", "Edit")
+			require.NoError(t, err)
+
+			stored, err := service.GetPermissions(context.Background(), signedInUser, "1")
+			require.NoError(t, err)
+			require.Len(t, stored, 1)
+			require.Equal(t, "Edit", service.MapActions(stored[0]))
+
+			_, err = service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: usr.ID}, "1", service.MapActions(stored[0]))
+			require.NoError(t, err)
+
+			stored, err = service.GetPermissions(context.Background(), signedInUser, "1")
+			require.NoError(t, err)
+			require.Len(t, stored, 1)
+			assert.Equal(t, "Edit", service.MapActions(stored[0]))
+			assert.Subset(t, stored[0].Actions, []string{"dashboards:write", "dashboards.permissions:read", "dashboards.permissions:write"})
+		})
+	}
+}
+
 type setPermissionsTest struct {
 	desc      string
 	options   Options
@@ -183,6 +702,21 @@ func TestService_SetPermissions(t *testing.T) {
 				{BuiltinRole: "Editor", Permission: "View"},
 			},
 		},
+		{
+			desc: "should set permissions for None basic role",
+			options: Options{
+				Resource: "dashboards",
+				Assignments: Assignments{
+					BuiltInRoles: true,
+				},
+				PermissionsToActions: map[string][]string{
+					"View": {"dashboards:read"},
+				},
+			},
+			commands: []accesscontrol.SetResourcePermissionCommand{
+				{BuiltinRole: "None", Permission: "View"},
+			},
+		},
 		{
 			desc: "should return error for invalid permission",
 			options: Options{
@@ -226,6 +760,102 @@ func TestService_SetPermissions(t *testing.T) {
 	}
 }
 
+func TestService_ReadOnly(t *testing.T) {
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		Assignments:          Assignments{Users: true, BuiltInRoles: true},
+		PermissionsToActions: nil,
+	})
+	service.cfg.RBAC.PermissionsReadOnly = true
+
+	user, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "test", OrgID: 1})
+	require.NoError(t, err)
+
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", "")
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = service.SetBuiltInRolePermission(context.Background(), user.OrgID, "Viewer", "1", "")
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = service.SetPermissions(context.Background(), user.OrgID, "1", accesscontrol.SetResourcePermissionCommand{UserID: user.ID})
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	assert.ErrorIs(t, service.DeleteResourcePermissions(context.Background(), user.OrgID, "1"), ErrReadOnly)
+
+	service.cfg.RBAC.PermissionsReadOnly = false
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", "")
+	require.NoError(t, err)
+}
+
+func TestService_AllowDeny(t *testing.T) {
+	features := featuremgmt.WithFeatures()
+	_, err := New(
+		setting.NewCfg(), Options{Resource: "datasources", AllowDeny: true}, features, routing.NewRouteRegister(), licensingtest.NewFakeLicensing(),
+		acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &actest.FakeService{}, nil, nil, nil, NewActionSetService(features), nil, nil,
+	)
+	assert.ErrorContains(t, err, "deny permissions are not supported on datasources")
+}
+
+type fakeQuotaService struct {
+	actest.FakeService
+	reached bool
+}
+
+func (s *fakeQuotaService) CheckOrgQuota(ctx context.Context, orgID int64) error {
+	if s.reached {
+		return accesscontrol.ErrQuotaReached.Errorf("quota reached")
+	}
+	return nil
+}
+
+func TestService_Quota(t *testing.T) {
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		Assignments:          Assignments{Users: true, BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+	})
+	service.service = &fakeQuotaService{reached: true}
+
+	user, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "test", OrgID: 1})
+	require.NoError(t, err)
+
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", "View")
+	assert.ErrorIs(t, err, accesscontrol.ErrQuotaReached)
+
+	_, err = service.SetBuiltInRolePermission(context.Background(), user.OrgID, "Viewer", "1", "View")
+	assert.ErrorIs(t, err, accesscontrol.ErrQuotaReached)
+
+	_, err = service.SetPermissions(context.Background(), user.OrgID, "1",
+		accesscontrol.SetResourcePermissionCommand{UserID: user.ID},
+		accesscontrol.SetResourcePermissionCommand{BuiltinRole: "Viewer", Permission: "View"},
+	)
+	assert.ErrorIs(t, err, accesscontrol.ErrQuotaReached)
+
+	// removing permissions is allowed when the quota is reached
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", "")
+	require.NoError(t, err)
+
+	service.service = &fakeQuotaService{}
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "1", "View")
+	require.NoError(t, err)
+
+	// the managed role of the user exists, granting it more permissions adds no role or role assignment
+	service.service = &fakeQuotaService{reached: true}
+	_, err = service.SetUserPermission(context.Background(), user.OrgID, accesscontrol.User{ID: user.ID}, "2", "View")
+	require.NoError(t, err)
+
+	_, err = service.SetPermissions(context.Background(), user.OrgID, "3",
+		accesscontrol.SetResourcePermissionCommand{UserID: user.ID, Permission: "View"},
+	)
+	require.NoError(t, err)
+
+	_, err = service.SetPermissions(context.Background(), user.OrgID, "3",
+		accesscontrol.SetResourcePermissionCommand{UserID: user.ID, Permission: "View"},
+		accesscontrol.SetResourcePermissionCommand{BuiltinRole: "Viewer", Permission: "View"},
+	)
+	assert.ErrorIs(t, err, accesscontrol.ErrQuotaReached)
+}
+
 func TestService_RegisterActionSets(t *testing.T) {
 	type registerActionSetsTest struct {
 		desc               string
@@ -293,9 +923,10 @@ func TestService_RegisterActionSets(t *testing.T) {
 			}
 			ac := acimpl.ProvideAccessControl(features, zanzana.NewNoopClient())
 			actionSets := NewActionSetService(features)
+			sql := db.InitTestDB(t)
 			_, err := New(
 				setting.NewCfg(), tt.options, features, routing.NewRouteRegister(), licensingtest.NewFakeLicensing(),
-				ac, &actest.FakeService{}, db.InitTestDB(t), nil, nil, actionSets,
+				ac, &actest.FakeService{}, sql, nil, nil, actionSets, nil, kvstore.ProvideService(sql),
 			)
 			require.NoError(t, err)
 
@@ -440,6 +1071,31 @@ func TestStore_RegisterActionSet(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			desc:     "should be able to register action sets for the plugin resources",
+			features: featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets, featuremgmt.FlagAccessControlOnCall),
+			pluginID: "test-app",
+			pluginActions: []plugins.ActionSet{
+				{
+					Action:  "test-app.projects:view",
+					Actions: []string{"test-app.projects:read"},
+				},
+				{
+					Action:  "test-app.projects:edit",
+					Actions: []string{"test-app.projects:view", "test-app.projects:write"},
+				},
+			},
+			expectedActionSets: []ActionSet{
+				{
+					Action:  "test-app.projects:view",
+					Actions: []string{"test-app.projects:read"},
+				},
+				{
+					Action:  "test-app.projects:edit",
+					Actions: []string{"test-app.projects:read", "test-app.projects:write"},
+				},
+			},
+		},
 		{
 			desc:     "should not be able to register action set that is not in the allow list",
 			features: featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets, featuremgmt.FlagAccessControlOnCall),
@@ -510,11 +1166,109 @@ func setupTestEnvironment(t *testing.T, ops Options) (*Service, user.Service, te
 	acService := &actest.FakeService{}
 	features := featuremgmt.WithFeatures()
 	ac := acimpl.ProvideAccessControl(features, zanzana.NewNoopClient())
+	defaultPolicy, err := ProvideDefaultPermissionsPolicy(cfg)
+	require.NoError(t, err)
 	service, err := New(
 		cfg, ops, features, routing.NewRouteRegister(), license,
-		ac, acService, sql, teamSvc, userSvc, NewActionSetService(features),
+		ac, acService, sql, teamSvc, userSvc, NewActionSetService(features), defaultPolicy, kvstore.ProvideService(sql),
 	)
 	require.NoError(t, err)
 
 	return service, userSvc, teamSvc
 }
+
+func TestService_InheritedWildcards(t *testing.T) {
+	service, _, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		ResourceAttribute:    "uid",
+		Assignments:          Assignments{Users: true},
+		PermissionsToActions: map[string][]string{"View": {"dashboards:read"}},
+		InheritedWildcards:   []string{"folders:*", "folders:uid:*"},
+	})
+
+	assert.Equal(t, accesscontrol.Wildcards{"*", "dashboards:*", "dashboards:uid:*", "folders:*", "folders:uid:*"}, service.wildcards.ForScope("dashboards:uid:1"))
+	assert.Equal(t, accesscontrol.Wildcards{"*", "folders:*", "folders:uid:*"}, service.wildcards.ForScope("folders:uid:1"))
+}
+
+func TestComposeActionSets(t *testing.T) {
+	sets := composeActionSets("folders", map[string][]string{
+		"View":  {"folders:read", "dashboards:read"},
+		"Edit":  {"folders:read", "dashboards:read", "folders:write"},
+		"Admin": {"folders:read", "dashboards:read", "folders:write", "folders.permissions:read"},
+		"Other": {"folders:delete"},
+	})
+
+	assert.Equal(t, map[string][]string{
+		"View":  {"folders:read", "dashboards:read"},
+		"Edit":  {"folders:view", "folders:write"},
+		"Admin": {"folders:edit", "folders.permissions:read"},
+		"Other": {"folders:delete"},
+	}, sets)
+
+	actionSets := NewInMemoryActionSetStore(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	for permission, actions := range sets {
+		actionSets.StoreActionSet(GetActionSetName("folders", permission), actions)
+	}
+	assert.ElementsMatch(t, []string{"folders:read", "dashboards:read", "folders:write", "folders.permissions:read"}, actionSets.ResolveActionSet("folders:admin"))
+}
+
+func TestPermissionsToActionsFromActionSets(t *testing.T) {
+	features := featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets, featuremgmt.FlagAccessControlOnCall)
+	actionSets := NewActionSetService(features)
+	err := actionSets.RegisterActionSets(context.Background(), "test-app", []plugins.ActionSet{
+		{Action: "test-app.projects:view", Actions: []string{"test-app.projects:read"}},
+		{Action: "test-app.projects:edit", Actions: []string{"test-app.projects:view", "test-app.projects:write"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"View": {"test-app.projects:read"},
+		"Edit": {"test-app.projects:read", "test-app.projects:write"},
+	}, PermissionsToActionsFromActionSets(actionSets, "test-app.projects", "View", "Edit", "Admin"))
+	assert.Equal(t, []string{"test-app.projects:edit"}, actionSets.ResolveAction("test-app.projects:write"))
+}
+
+func TestActionSetSvc_ListActionSets(t *testing.T) {
+	actionSets := NewActionSetService(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSets.StoreActionSet("dashboards:view", []string{"dashboards:read"})
+	actionSets.StoreActionSet("dashboards:edit", []string{"dashboards:view", "dashboards:write"})
+	// Action sets of other resources are not supported
+	actionSets.StoreActionSet("datasources:read", []string{"datasources:query"})
+
+	lister, ok := actionSets.(accesscontrol.ActionSetLister)
+	require.True(t, ok)
+	assert.Equal(t, map[string][]string{
+		"dashboards:view": {"dashboards:read"},
+		"dashboards:edit": {"dashboards:read", "dashboards:write"},
+	}, lister.ListActionSets())
+}
+
+func TestActionSetSvc_ConcurrentRegisterActionSets(t *testing.T) {
+	features := featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets, featuremgmt.FlagAccessControlOnCall)
+	actionSets := NewActionSetService(features)
+
+	const registrations = 10
+	var wg sync.WaitGroup
+	for i := 0; i < registrations; i++ {
+		pluginID := fmt.Sprintf("app-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := actionSets.RegisterActionSets(context.Background(), pluginID, []plugins.ActionSet{
+				{Action: pluginID + ".projects:view", Actions: []string{pluginID + ".projects:read"}},
+			})
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			actionSets.ResolveAction(pluginID + ".projects:read")
+			actionSets.ResolveActionSet(pluginID + ".projects:view")
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < registrations; i++ {
+		pluginID := fmt.Sprintf("app-%d", i)
+		assert.Equal(t, []string{pluginID + ".projects:read"}, actionSets.ResolveActionSet(pluginID+".projects:view"))
+	}
+}
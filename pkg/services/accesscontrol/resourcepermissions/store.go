@@ -1,25 +1,52 @@
 package resourcepermissions
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/dualwrite"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/slowquery"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/webhook"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	maxTransactionRetries = 3
+	transactionRetryDelay = 50 * time.Millisecond
+	// mysqlCollation is the collation of the tables created by the migrations
+	mysqlCollation = "utf8mb4_unicode_ci"
+)
+
 func NewStore(cfg *setting.Cfg, sql db.DB, features featuremgmt.FeatureToggles) *store {
-	store := &store{cfg: cfg, sql: sql, features: features}
+	store := &store{
+		cfg:         cfg,
+		sql:         sql,
+		features:    features,
+		slowQueries: slowquery.ProvideLogger(cfg, sql),
+	}
 	return store
 }
 
@@ -27,6 +54,19 @@ type store struct {
 	cfg      *setting.Cfg
 	sql      db.DB
 	features featuremgmt.FeatureToggles
+	// actionSets is used to expand action sets when requested by GetResourcePermissionsQuery.ExpandActionSets.
+	// It is optional and expansion is skipped when not set.
+	actionSets ActionSetService
+	// hooksV2 are called with the change of each permission set, after the hooks passed by the caller
+	hooksV2 ResourceHooksV2
+	// webhook is notified about committed permission changes, nil when no webhook is configured.
+	// It is shared with the access control service so that a single notifier delivers the events.
+	webhook *webhook.Notifier
+	// slowQueries logs the plan of slow permission queries, nil when disabled
+	slowQueries *slowquery.Logger
+	// wildcards holds the wildcards declared by the resources, only the wildcards derived from the scopes are
+	// matched when it is nil
+	wildcards *accesscontrol.WildcardRegistry
 }
 
 type flatResourcePermission struct {
@@ -41,7 +81,9 @@ type flatResourcePermission struct {
 	TeamEmail        string
 	Team             string
 	BuiltInRole      string
-	IsServiceAccount bool `xorm:"is_service_account"`
+	GroupID          string `xorm:"group_id"`
+	IsServiceAccount bool   `xorm:"is_service_account"`
+	Deny             bool
 	Created          time.Time
 	Updated          time.Time
 }
@@ -68,22 +110,203 @@ func (s *store) DeleteResourcePermissions(ctx context.Context, orgID int64, cmd
 
 	scope := accesscontrol.Scope(cmd.Resource, cmd.ResourceAttribute, cmd.ResourceID)
 
-	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	getPermissionIDs := func(sess *db.Session) ([]int64, error) {
 		var permissionIDs []int64
 		err := sess.SQL(
 			"SELECT permission.id FROM permission INNER JOIN role ON permission.role_id = role.id WHERE permission.scope = ? AND role.org_id = ?",
 			scope, orgID).Find(&permissionIDs)
-		if err != nil {
+		return permissionIDs, err
+	}
+
+	var err error
+	if s.commitPerChunk() {
+		// Deleting the permissions again removes the ones left by a failed chunk, committing each chunk is safe
+		var permissionIDs []int64
+		err = s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+			permissionIDs, err = getPermissionIDs(sess)
 			return err
+		})
+		if err == nil {
+			err = sqlstore.InBatches(permissionIDs, s.bulkSettings(), func(batch any) error {
+				return s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+					return deletePermissions(sess, batch.([]int64), s.bulkSettings())
+				})
+			})
+		}
+		if err == nil {
+			err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+				return deleteResourceAudit(sess, orgID, cmd, scope)
+			})
 		}
+	} else {
+		err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			permissionIDs, err := getPermissionIDs(sess)
+			if err != nil {
+				return err
+			}
+			if err := deletePermissions(sess, permissionIDs, s.bulkSettings()); err != nil {
+				return err
+			}
+			return deleteResourceAudit(sess, orgID, cmd, scope)
+		})
+	}
+
+	if err == nil {
+		s.webhook.Notify(ctx, webhook.Event{
+			Type:       webhook.EventResourcePermissionDeleted,
+			OrgID:      orgID,
+			Resource:   cmd.Resource,
+			ResourceID: cmd.ResourceID,
+		})
+	}
+
+	return err
+}
+
+// deleteResourceAudit records the removal of the permissions of the deleted resource in the audit log and removes
+// their metadata.
+func deleteResourceAudit(sess *db.Session, orgID int64, cmd *DeleteResourcePermissionsCmd, scope string) error {
+	if _, err := sess.Exec("DELETE FROM permission_metadata WHERE org_id = ? AND scope = ?", orgID, scope); err != nil {
+		return err
+	}
+	return database.RecordPermissionRemovals(sess, database.PermissionAuditFilter{
+		OrgID: orgID, Resource: cmd.Resource, ResourceID: cmd.ResourceID,
+	})
+}
+
+type RenameResourcePermissionsCmd struct {
+	Resource          string
+	ResourceAttribute string
+	OldResourceID     string
+	NewResourceID     string
+}
 
-		if err := deletePermissions(sess, permissionIDs); err != nil {
+// RenameResourcePermissions moves the managed permissions of the resource, their metadata, temporary grants and
+// pending requests from the old resource id to the new one in a single transaction. When an assignee already has
+// the same grant on the new resource id, the one of the old resource id is dropped.
+func (s *store) RenameResourcePermissions(ctx context.Context, orgID int64, cmd *RenameResourcePermissionsCmd) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RenameResourcePermissions")
+	defer span.End()
+
+	oldScope := accesscontrol.Scope(cmd.Resource, cmd.ResourceAttribute, cmd.OldResourceID)
+	newScope := accesscontrol.Scope(cmd.Resource, cmd.ResourceAttribute, cmd.NewResourceID)
+	orgRoles := "SELECT id FROM role WHERE org_id = ?"
+
+	return s.inTransaction(ctx, func(sess *db.Session) error {
+		type grant struct {
+			ID     int64  `xorm:"id"`
+			RoleID int64  `xorm:"role_id"`
+			Action string `xorm:"action"`
+			Scope  string `xorm:"scope"`
+		}
+		var grants []grant
+		if err := sess.SQL(
+			"SELECT id, role_id, action, scope FROM permission WHERE scope IN (?, ?) AND role_id IN ("+orgRoles+")",
+			oldScope, newScope, orgID,
+		).Find(&grants); err != nil {
 			return err
 		}
-		return err
+
+		granted := make(map[grant]bool, len(grants))
+		for _, g := range grants {
+			if g.Scope == newScope {
+				granted[grant{RoleID: g.RoleID, Action: g.Action}] = true
+			}
+		}
+		var duplicates []int64
+		for _, g := range grants {
+			if g.Scope == oldScope && granted[grant{RoleID: g.RoleID, Action: g.Action}] {
+				duplicates = append(duplicates, g.ID)
+			}
+		}
+
+		// The tuples of the permissions moved to the new resource id are rewritten once the rename is committed
+		if s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+			assignments, err := renamedAssignments(sess, orgID, oldScope)
+			if err != nil {
+				return err
+			}
+			queued := make([]accesscontrol.ResourceAssignment, 0, 2*len(assignments))
+			for _, a := range assignments {
+				for _, resourceID := range []string{cmd.OldResourceID, cmd.NewResourceID} {
+					a.Resource, a.ResourceID = cmd.Resource, resourceID
+					queued = append(queued, a)
+				}
+			}
+			if err := dualwrite.EnqueueResourcePermissionTuples(sess, orgID, queued...); err != nil {
+				return err
+			}
+		}
+		if err := deletePermissions(sess, duplicates, s.bulkSettings()); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec(
+			"UPDATE permission SET scope = ?, identifier = ?, updated = ? WHERE scope = ? AND role_id IN ("+orgRoles+")",
+			newScope, cmd.NewResourceID, time.Now(), oldScope, orgID,
+		); err != nil {
+			return err
+		}
+
+		// The metadata and temporary grants of the new resource id are kept when both exist for an assignee
+		if _, err := sess.Exec(
+			"DELETE FROM permission_metadata WHERE org_id = ? AND scope = ? AND role_name IN (SELECT role_name FROM (SELECT role_name FROM permission_metadata WHERE org_id = ? AND scope = ?) AS renamed)",
+			orgID, oldScope, orgID, newScope,
+		); err != nil {
+			return err
+		}
+		if _, err := sess.Exec("UPDATE permission_metadata SET scope = ? WHERE org_id = ? AND scope = ?", newScope, orgID, oldScope); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec(
+			"DELETE FROM temporary_permission WHERE org_id = ? AND resource = ? AND resource_id = ? AND user_id IN (SELECT user_id FROM (SELECT user_id FROM temporary_permission WHERE org_id = ? AND resource = ? AND resource_id = ?) AS renamed)",
+			orgID, cmd.Resource, cmd.OldResourceID, orgID, cmd.Resource, cmd.NewResourceID,
+		); err != nil {
+			return err
+		}
+		for _, table := range []string{"temporary_permission", "permission_request"} {
+			if _, err := sess.Exec(
+				"UPDATE "+table+" SET resource_id = ? WHERE org_id = ? AND resource = ? AND resource_id = ?",
+				cmd.NewResourceID, orgID, cmd.Resource, cmd.OldResourceID,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+}
 
-	return err
+// renamedAssignments returns the assignees of the managed roles with a permission on the scope.
+func renamedAssignments(sess *db.Session, orgID int64, scope string) ([]accesscontrol.ResourceAssignment, error) {
+	var assignees []struct {
+		UserID      int64  `xorm:"user_id"`
+		TeamID      int64  `xorm:"team_id"`
+		BuiltInRole string `xorm:"builtin_role"`
+		GroupID     string `xorm:"group_id"`
+	}
+	err := sess.SQL(
+		"SELECT DISTINCT COALESCE(ur.user_id, 0) AS user_id, COALESCE(tr.team_id, 0) AS team_id, COALESCE(br.role, '') AS builtin_role,"+
+			" COALESCE(gr.group_id, '') AS group_id FROM role"+
+			" LEFT JOIN user_role ur ON ur.role_id = role.id"+
+			" LEFT JOIN team_role tr ON tr.role_id = role.id"+
+			" LEFT JOIN builtin_role br ON br.role_id = role.id"+
+			" LEFT JOIN group_role gr ON gr.role_id = role.id"+
+			" WHERE role.org_id = ? AND role.name LIKE ? AND role.id IN (SELECT role_id FROM permission WHERE scope = ?)",
+		orgID, accesscontrol.ManagedRolePrefix+"%", scope,
+	).Find(&assignees)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]accesscontrol.ResourceAssignment, 0, len(assignees))
+	for _, a := range assignees {
+		if a.UserID == 0 && a.TeamID == 0 && a.BuiltInRole == "" && a.GroupID == "" {
+			continue
+		}
+		assignments = append(assignments, accesscontrol.ResourceAssignment{UserID: a.UserID, TeamID: a.TeamID, BuiltInRole: a.BuiltInRole, GroupID: a.GroupID})
+	}
+	return assignments, nil
 }
 
 func (s *store) SetUserResourcePermission(
@@ -100,11 +323,15 @@ func (s *store) SetUserResourcePermission(
 
 	var err error
 	var permission *accesscontrol.ResourcePermission
-	err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	err = s.inTransaction(ctx, func(sess *db.Session) error {
 		permission, err = s.setUserResourcePermission(sess, orgID, usr, cmd, hook)
 		return err
 	})
 
+	if err == nil {
+		s.webhook.Notify(ctx, permissionSetEvent(orgID, SetResourcePermissionsCommand{User: usr, SetResourcePermissionCommand: cmd}))
+	}
+
 	return permission, err
 }
 func (s *store) setUserResourcePermission(
@@ -112,17 +339,31 @@ func (s *store) setUserResourcePermission(
 	cmd SetResourcePermissionCommand,
 	hook UserResourceHookFunc,
 ) (*accesscontrol.ResourcePermission, error) {
-	permission, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedUserRoleName(user.ID), s.userAdder(sess, orgID, user.ID), cmd)
+	permission, change, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedUserRoleName(user.ID), s.userAdder(sess, orgID, user.ID), cmd)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := recordPermissionChange(sess, orgID, PermissionAuditEntry{UserID: user.ID}, cmd); err != nil {
+		return nil, err
+	}
+
+	if err := s.queueTupleWrite(sess, orgID, accesscontrol.ResourceAssignment{UserID: user.ID, Resource: cmd.Resource, ResourceID: cmd.ResourceID}); err != nil {
+		return nil, err
+	}
+
 	if hook != nil {
 		if err := hook(sess, orgID, user, cmd.ResourceID, cmd.Permission); err != nil {
 			return nil, err
 		}
 	}
 
+	if s.hooksV2.User != nil {
+		if err := s.hooksV2.User(sess, orgID, user, change); err != nil {
+			return nil, err
+		}
+	}
+
 	return permission, nil
 }
 
@@ -141,11 +382,15 @@ func (s *store) SetTeamResourcePermission(
 	var err error
 	var permission *accesscontrol.ResourcePermission
 
-	err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	err = s.inTransaction(ctx, func(sess *db.Session) error {
 		permission, err = s.setTeamResourcePermission(sess, orgID, teamID, cmd, hook)
 		return err
 	})
 
+	if err == nil {
+		s.webhook.Notify(ctx, permissionSetEvent(orgID, SetResourcePermissionsCommand{TeamID: teamID, SetResourcePermissionCommand: cmd}))
+	}
+
 	return permission, err
 }
 
@@ -154,17 +399,31 @@ func (s *store) setTeamResourcePermission(
 	cmd SetResourcePermissionCommand,
 	hook TeamResourceHookFunc,
 ) (*accesscontrol.ResourcePermission, error) {
-	permission, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedTeamRoleName(teamID), s.teamAdder(sess, orgID, teamID), cmd)
+	permission, change, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedTeamRoleName(teamID), s.teamAdder(sess, orgID, teamID), cmd)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := recordPermissionChange(sess, orgID, PermissionAuditEntry{TeamID: teamID}, cmd); err != nil {
+		return nil, err
+	}
+
+	if err := s.queueTupleWrite(sess, orgID, accesscontrol.ResourceAssignment{TeamID: teamID, Resource: cmd.Resource, ResourceID: cmd.ResourceID}); err != nil {
+		return nil, err
+	}
+
 	if hook != nil {
 		if err := hook(sess, orgID, teamID, cmd.ResourceID, cmd.Permission); err != nil {
 			return nil, err
 		}
 	}
 
+	if s.hooksV2.Team != nil {
+		if err := s.hooksV2.Team(sess, orgID, teamID, change); err != nil {
+			return nil, err
+		}
+	}
+
 	return permission, nil
 }
 
@@ -176,14 +435,14 @@ func (s *store) SetBuiltInResourcePermission(
 	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetBuiltInResourcePermission")
 	defer span.End()
 
-	if !org.RoleType(builtInRole).IsValid() || builtInRole == accesscontrol.RoleGrafanaAdmin {
+	if !isAssignableBuiltInRole(builtInRole) {
 		return nil, fmt.Errorf("invalid role: %s", builtInRole)
 	}
 
 	var err error
 	var permission *accesscontrol.ResourcePermission
 
-	err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+	err = s.inTransaction(ctx, func(sess *db.Session) error {
 		permission, err = s.setBuiltInResourcePermission(sess, orgID, builtInRole, cmd, hook)
 		return err
 	})
@@ -192,6 +451,8 @@ func (s *store) SetBuiltInResourcePermission(
 		return nil, err
 	}
 
+	s.webhook.Notify(ctx, permissionSetEvent(orgID, SetResourcePermissionsCommand{BuiltinRole: builtInRole, SetResourcePermissionCommand: cmd}))
+
 	return permission, nil
 }
 
@@ -200,165 +461,792 @@ func (s *store) setBuiltInResourcePermission(
 	cmd SetResourcePermissionCommand,
 	hook BuiltinResourceHookFunc,
 ) (*accesscontrol.ResourcePermission, error) {
-	permission, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedBuiltInRoleName(builtInRole), s.builtInRoleAdder(sess, orgID, builtInRole), cmd)
+	permission, change, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedBuiltInRoleName(builtInRole), s.builtInRoleAdder(sess, orgID, builtInRole), cmd)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := recordPermissionChange(sess, orgID, PermissionAuditEntry{BuiltInRole: builtInRole}, cmd); err != nil {
+		return nil, err
+	}
+
+	if err := s.queueTupleWrite(sess, orgID, accesscontrol.ResourceAssignment{BuiltInRole: builtInRole, Resource: cmd.Resource, ResourceID: cmd.ResourceID}); err != nil {
+		return nil, err
+	}
+
 	if hook != nil {
 		if err := hook(sess, orgID, builtInRole, cmd.ResourceID, cmd.Permission); err != nil {
 			return nil, err
 		}
 	}
 
-	return permission, nil
+	if s.hooksV2.BuiltInRole != nil {
+		if err := s.hooksV2.BuiltInRole(sess, orgID, builtInRole, change); err != nil {
+			return nil, err
+		}
+	}
+
+	return permission, nil
+}
+
+func (s *store) SetGroupResourcePermission(
+	ctx context.Context, orgID int64, groupID string,
+	cmd SetResourcePermissionCommand,
+	hook GroupResourceHookFunc,
+) (*accesscontrol.ResourcePermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetGroupResourcePermission")
+	defer span.End()
+
+	if groupID == "" {
+		return nil, fmt.Errorf("missing group")
+	}
+
+	var err error
+	var permission *accesscontrol.ResourcePermission
+
+	err = s.inTransaction(ctx, func(sess *db.Session) error {
+		permission, err = s.setGroupResourcePermission(sess, orgID, groupID, cmd, hook)
+		return err
+	})
+
+	if err == nil {
+		s.webhook.Notify(ctx, permissionSetEvent(orgID, SetResourcePermissionsCommand{GroupID: groupID, SetResourcePermissionCommand: cmd}))
+	}
+
+	return permission, err
+}
+
+func (s *store) setGroupResourcePermission(
+	sess *db.Session, orgID int64, groupID string,
+	cmd SetResourcePermissionCommand,
+	hook GroupResourceHookFunc,
+) (*accesscontrol.ResourcePermission, error) {
+	permission, change, err := s.setResourcePermission(sess, orgID, accesscontrol.ManagedGroupRoleName(groupID), s.groupAdder(sess, orgID, groupID), cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordPermissionChange(sess, orgID, PermissionAuditEntry{GroupID: groupID}, cmd); err != nil {
+		return nil, err
+	}
+
+	if err := s.queueTupleWrite(sess, orgID, accesscontrol.ResourceAssignment{GroupID: groupID, Resource: cmd.Resource, ResourceID: cmd.ResourceID}); err != nil {
+		return nil, err
+	}
+
+	if hook != nil {
+		if err := hook(sess, orgID, groupID, cmd.ResourceID, cmd.Permission); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.hooksV2.Group != nil {
+		if err := s.hooksV2.Group(sess, orgID, groupID, change); err != nil {
+			return nil, err
+		}
+	}
+
+	return permission, nil
+}
+
+func (s *store) SetResourcePermissions(
+	ctx context.Context, orgID int64,
+	commands []SetResourcePermissionsCommand,
+	hooks ResourceHooks,
+) ([]accesscontrol.ResourcePermission, error) {
+	return s.SetResourcePermissionsBatches(ctx, orgID, []ResourcePermissionsBatch{{Commands: commands, Hooks: hooks}})
+}
+
+// SetResourcePermissionsBatches sets the permissions of all the batches in a single transaction, each batch calls
+// its own hooks so permissions of resources managed by different services can be set together.
+func (s *store) SetResourcePermissionsBatches(ctx context.Context, orgID int64, batches []ResourcePermissionsBatch) ([]accesscontrol.ResourcePermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetResourcePermissions")
+	defer span.End()
+
+	var err error
+	var permissions []accesscontrol.ResourcePermission
+
+	err = s.inTransaction(ctx, func(sess *db.Session) error {
+		permissions = nil
+		for _, b := range batches {
+			for _, cmd := range b.Commands {
+				var p *accesscontrol.ResourcePermission
+				if cmd.User.ID != 0 {
+					p, err = s.setUserResourcePermission(sess, orgID, cmd.User, cmd.SetResourcePermissionCommand, b.Hooks.User)
+				} else if cmd.TeamID != 0 {
+					p, err = s.setTeamResourcePermission(sess, orgID, cmd.TeamID, cmd.SetResourcePermissionCommand, b.Hooks.Team)
+				} else if isAssignableBuiltInRole(cmd.BuiltinRole) {
+					p, err = s.setBuiltInResourcePermission(sess, orgID, cmd.BuiltinRole, cmd.SetResourcePermissionCommand, b.Hooks.BuiltInRole)
+				} else if cmd.GroupID != "" {
+					p, err = s.setGroupResourcePermission(sess, orgID, cmd.GroupID, cmd.SetResourcePermissionCommand, b.Hooks.Group)
+				}
+				if err != nil {
+					return err
+				}
+				if p != nil {
+					permissions = append(permissions, *p)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err == nil {
+		var events []webhook.Event
+		for _, b := range batches {
+			for _, cmd := range b.Commands {
+				events = append(events, permissionSetEvent(orgID, cmd))
+			}
+		}
+		s.webhook.Notify(ctx, events...)
+	}
+
+	return permissions, err
+}
+
+// isAssignableBuiltInRole returns true for the basic roles and Grafana Admin. Whether a resource accepts permissions
+// for Grafana Admin is decided by its service, see Options.AllowGrafanaAdmin.
+func isAssignableBuiltInRole(role string) bool {
+	return org.RoleType(role).IsValid() || role == accesscontrol.RoleGrafanaAdmin
+}
+
+func permissionSetEvent(orgID int64, cmd SetResourcePermissionsCommand) webhook.Event {
+	return webhook.Event{
+		Type:        webhook.EventResourcePermissionSet,
+		OrgID:       orgID,
+		Resource:    cmd.Resource,
+		ResourceID:  cmd.ResourceID,
+		UserID:      cmd.User.ID,
+		TeamID:      cmd.TeamID,
+		BuiltInRole: cmd.BuiltinRole,
+		GroupID:     cmd.GroupID,
+		Permission:  cmd.Permission,
+		Actions:     cmd.Actions,
+	}
+}
+
+// queueTupleWrite adds the permission of the assignee on the resource to the outbox of the zanzana tuple writes within
+// the transaction setting it, its tuples are written once the transaction is committed.
+func (s *store) queueTupleWrite(sess *db.Session, orgID int64, assignment accesscontrol.ResourceAssignment) error {
+	if !s.features.IsEnabledGlobally(featuremgmt.FlagZanzana) {
+		return nil
+	}
+	return dualwrite.EnqueueResourcePermissionTuples(sess, orgID, assignment)
+}
+
+type roleAdder func(roleID int64) error
+
+// setResourcePermission replaces the permissions of the managed role on the resource with the actions of cmd,
+// it returns the resulting permission and the change applied to the role.
+func (s *store) setResourcePermission(
+	sess *db.Session, orgID int64, roleName string, adder roleAdder, cmd SetResourcePermissionCommand,
+) (*accesscontrol.ResourcePermission, PermissionChange, error) {
+	role, err := s.getOrCreateManagedRole(sess, orgID, roleName, adder)
+	if err != nil {
+		return nil, PermissionChange{}, err
+	}
+
+	rawSQL := `SELECT p.* FROM permission as p INNER JOIN role r on r.id = p.role_id WHERE r.id = ? AND p.scope = ?`
+
+	var current []accesscontrol.Permission
+	scope := accesscontrol.Scope(cmd.Resource, cmd.ResourceAttribute, cmd.ResourceID)
+	if err := sess.SQL(rawSQL, role.ID, scope).Find(&current); err != nil {
+		return nil, PermissionChange{}, err
+	}
+
+	missing := make(map[string]struct{}, len(cmd.Actions))
+	for _, a := range cmd.Actions {
+		missing[a] = struct{}{}
+	}
+
+	change := PermissionChange{ResourceID: cmd.ResourceID, Permission: cmd.Permission, Role: *role}
+	var remove []int64
+	for _, p := range current {
+		if _, ok := missing[p.Action]; ok && p.Deny == cmd.Deny {
+			delete(missing, p.Action)
+		} else {
+			remove = append(remove, p.ID)
+			change.Removed = append(change.Removed, p.Action)
+		}
+	}
+	for action := range missing {
+		change.Added = append(change.Added, action)
+	}
+	slices.Sort(change.Added)
+	slices.Sort(change.Removed)
+
+	if err := deletePermissions(sess, remove, s.bulkSettings()); err != nil {
+		return nil, PermissionChange{}, err
+	}
+
+	if err := s.createPermissions(sess, role.ID, cmd, missing); err != nil {
+		return nil, PermissionChange{}, err
+	}
+
+	permissions, err := s.getPermissions(sess, cmd.Resource, cmd.ResourceID, cmd.ResourceAttribute, role.ID)
+	if err != nil {
+		return nil, PermissionChange{}, err
+	}
+
+	permission := flatPermissionsToResourcePermission(scope, permissions)
+	if permission == nil {
+		if err := deletePermissionMetadata(sess, orgID, roleName, scope); err != nil {
+			return nil, PermissionChange{}, err
+		}
+		return &accesscontrol.ResourcePermission{}, change, nil
+	}
+
+	return permission, change, nil
+}
+
+func (s *store) GetResourcePermissions(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) ([]accesscontrol.ResourcePermission, error) {
+	page, err := s.GetResourcePermissionsPage(ctx, orgID, query)
+	if err != nil {
+		return nil, err
+	}
+	return page.Permissions, nil
+}
+
+// GetResourcePermissionsPage returns the assignments of the page selected by the limit and offset of the query
+// together with the number of assignments across all pages. Assignments are filtered before they are counted, and
+// when a page is requested they are grouped, filtered, counted and paged by the database in the order of the
+// assignments returned without paging, so pages are stable for a given state of the permissions.
+func (s *store) GetResourcePermissionsPage(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) (*ResourcePermissionsPage, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetResourcePermissions")
+	defer span.End()
+
+	if query.Limit < 0 {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("limit", fmt.Errorf("limit can't be negative")))
+	}
+	if query.Offset < 0 {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("offset", fmt.Errorf("offset can't be negative")))
+	}
+
+	inheritedScopes, err := s.withAncestorScopes(ctx, orgID, query.InheritedScopes)
+	if err != nil {
+		return nil, err
+	}
+	query.InheritedScopes = inheritedScopes
+
+	branches, err := s.resourcePermissionsBranches(orgID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit == 0 && query.Offset == 0 {
+		result, err := s.queryResourcePermissions(ctx, query, branches)
+		if err != nil {
+			return nil, err
+		}
+		return &ResourcePermissionsPage{Permissions: result, TotalCount: int64(len(result))}, nil
+	}
+	return s.pageResourcePermissions(ctx, query, branches)
+}
+
+// queryResourcePermissions returns the assignments of the rows selected by the branches.
+func (s *store) queryResourcePermissions(ctx context.Context, query GetResourcePermissionsQuery, branches []permissionsBranch) ([]accesscontrol.ResourcePermission, error) {
+	if len(branches) == 0 {
+		return nil, nil
+	}
+	if s.parallelBranches(ctx) {
+		return s.getResourcePermissionsParallel(ctx, query, branches)
+	}
+
+	var result []accesscontrol.ResourcePermission
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		result, err = s.getResourcePermissions(ctx, sess, query, branches)
+		return err
+	})
+	return result, err
+}
+
+// assignmentKey identifies an assignment, the permissions of an assignee in one category.
+type assignmentKey struct {
+	Kind        int    `xorm:"kind"`
+	UserID      int64  `xorm:"user_id"`
+	TeamID      int64  `xorm:"team_id"`
+	BuiltInRole string `xorm:"built_in_role"`
+	GroupID     string `xorm:"group_id"`
+	Category    int    `xorm:"category"`
+}
+
+func toAssignmentKey(p accesscontrol.ResourcePermission) assignmentKey {
+	key := assignmentKey{UserID: p.UserId, TeamID: p.TeamId, BuiltInRole: p.BuiltInRole, GroupID: p.GroupID, Category: provisionedPermissions}
+	switch {
+	case p.UserId != 0:
+		key.Kind = 0
+	case p.TeamId != 0:
+		key.Kind = 1
+	case p.BuiltInRole != "":
+		key.Kind = 2
+	default:
+		key.Kind = 3
+	}
+	if p.IsManaged {
+		key.Category = managedPermissions
+	} else if p.IsInherited {
+		key.Category = inheritedPermissions
+	}
+	return key
+}
+
+// pageResourcePermissions selects the assignments of the page in the database and only loads the rows of their
+// assignees. The database groups the rows like flatPermissionsToResourcePermissions: assignees are ordered by kind and
+// by their first permission, their managed, inherited and provisioned assignments follow each other.
+func (s *store) pageResourcePermissions(ctx context.Context, query GetResourcePermissionsQuery, branches []permissionsBranch) (*ResourcePermissionsPage, error) {
+	page := &ResourcePermissionsPage{Permissions: []accesscontrol.ResourcePermission{}}
+	if len(branches) == 0 {
+		return page, nil
+	}
+
+	scope := accesscontrol.Scope(query.Resource, query.ResourceAttribute, query.ResourceID)
+	category := fmt.Sprintf("CASE WHEN r.name LIKE '%s%%' AND p.scope = ? THEN %d WHEN r.name LIKE '%s%%' THEN %d ELSE %d END",
+		accesscontrol.ManagedRolePrefix, managedPermissions, accesscontrol.ManagedRolePrefix, inheritedPermissions, provisionedPermissions)
+	queries := make([]string, 0, len(branches))
+	var rowArgs []any
+	for _, b := range branches {
+		queries = append(queries, fmt.Sprintf("SELECT %d AS kind, %s, %s AS category, p.id AS id, p.action AS action, p.deny AS deny", b.kind, b.assignee, category)+b.from)
+		rowArgs = append(rowArgs, scope)
+		rowArgs = append(rowArgs, b.args...)
+	}
+	rows := "(" + strings.Join(queries, " UNION ALL ") + ") pr"
+
+	aggregates, where, filterArgs := s.assignmentFilter(query)
+	groups := "SELECT kind, user_id, team_id, built_in_role, group_id, category, MIN(id) AS first_id" + aggregates + " FROM " + rows +
+		" GROUP BY kind, user_id, team_id, built_in_role, group_id, category"
+	groupArgs := slices.Concat(filterArgs, rowArgs)
+	if where != "" {
+		// The deny of an assignment is the one of its first permission
+		groups = "SELECT a.kind, a.user_id, a.team_id, a.built_in_role, a.group_id, a.category FROM (" + groups + ") a" +
+			" INNER JOIN permission fp ON fp.id = a.first_id WHERE " + where
+	}
+	// Assignees are ordered by their first permission before their assignments are filtered, like without paging
+	firsts := "SELECT kind, user_id, team_id, built_in_role, group_id, MIN(id) AS first_id FROM " + rows +
+		" GROUP BY kind, user_id, team_id, built_in_role, group_id"
+
+	var keys []assignmentKey
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.SQL("SELECT COUNT(*) FROM ("+groups+") g", groupArgs...).Get(&page.TotalCount); err != nil {
+			return err
+		}
+		if int64(query.Offset) >= page.TotalCount {
+			return nil
+		}
+
+		limit := int64(query.Limit)
+		if limit == 0 {
+			limit = page.TotalCount
+		}
+		sql := "SELECT g.kind, g.user_id, g.team_id, g.built_in_role, g.group_id, g.category FROM (" + groups + ") g" +
+			" INNER JOIN (" + firsts + ") f ON f.kind = g.kind AND f.user_id = g.user_id AND f.team_id = g.team_id" +
+			" AND f.built_in_role = g.built_in_role AND f.group_id = g.group_id" +
+			" ORDER BY g.kind, f.first_id, g.category" + s.sql.GetDialect().LimitOffset(limit, int64(query.Offset))
+		return s.slowQueries.Find(ctx, sess, "getResourcePermissionsPage", &keys, sql, slices.Concat(groupArgs, rowArgs)...)
+	})
+	if err != nil || len(keys) == 0 {
+		return page, err
+	}
+
+	wanted := make(map[assignmentKey]bool, len(keys))
+	assignees := make([][]any, len(branches))
+	seen := make(map[assignmentKey]bool, len(keys))
+	for _, k := range keys {
+		wanted[k] = true
+		assignee := assignmentKey{Kind: k.Kind, UserID: k.UserID, TeamID: k.TeamID, BuiltInRole: k.BuiltInRole, GroupID: k.GroupID}
+		if seen[assignee] {
+			continue
+		}
+		seen[assignee] = true
+		for i, b := range branches {
+			if b.kind != k.Kind {
+				continue
+			}
+			switch k.Kind {
+			case 0:
+				assignees[i] = append(assignees[i], k.UserID)
+			case 1:
+				assignees[i] = append(assignees[i], k.TeamID)
+			case 2:
+				assignees[i] = append(assignees[i], k.BuiltInRole)
+			default:
+				assignees[i] = append(assignees[i], k.GroupID)
+			}
+		}
+	}
+
+	restricted := make([]permissionsBranch, 0, len(branches))
+	for i, b := range branches {
+		if b, ok := b.restrict(assignees[i]); ok {
+			restricted = append(restricted, b)
+		}
+	}
+	result, err := s.queryResourcePermissions(ctx, query, restricted)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range result {
+		if wanted[toAssignmentKey(p)] {
+			page.Permissions = append(page.Permissions, p)
+		}
+	}
+	return page, nil
+}
+
+// assignmentFilter returns the filter keeping the assignments that toResourcePermissions keeps, so they are counted
+// and paged by the database. The aggregates are added to the select list of the assignments, each counts the
+// permissions granting an action, and the condition uses them together with the deny of the first permission, fp.
+func (s *store) assignmentFilter(query GetResourcePermissionsQuery) (string, string, []any) {
+	var aggregates strings.Builder
+	var filters []string
+	var args []any
+	grantsAll := func(actions []string) string {
+		conditions := make([]string, 0, len(actions))
+		for _, action := range actions {
+			granting := []any{action}
+			if s.actionSets != nil {
+				for _, set := range s.actionSets.ResolveAction(action) {
+					granting = append(granting, set)
+				}
+			}
+			column := fmt.Sprintf("grants_%d", len(args))
+			fmt.Fprintf(&aggregates, ", SUM(CASE WHEN action IN (?%s) THEN 1 ELSE 0 END) AS %s", strings.Repeat(",?", len(granting)-1), column)
+			args = append(args, granting...)
+			conditions = append(conditions, "a."+column+" > 0")
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")"
+	}
+	denied := "fp.deny = " + s.sql.GetDialect().BooleanStr(true)
+
+	if required := s.permissionActions(query.Resource, query.Permission); len(required) > 0 {
+		filters = append(filters, "NOT "+denied+" AND "+grantsAll(required))
+	}
+
+	if len(query.GrantingOneOf) > 0 {
+		alternatives := []string{denied}
+		for _, actions := range query.GrantingOneOf {
+			alternatives = append(alternatives, grantsAll(actions))
+		}
+		filters = append(filters, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	return aggregates.String(), strings.Join(filters, " AND "), args
+}
+
+// withAncestorScopes adds the scopes of the ancestors of the folders found in the inherited scopes, callers often only
+// pass the scope of the direct parent of a resource. Ancestors are resolved up to the maximum depth of nested folders.
+func (s *store) withAncestorScopes(ctx context.Context, orgID int64, scopes []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(scopes))
+	uids := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		seen[scope] = struct{}{}
+		uid, ok := strings.CutPrefix(scope, dashboards.ScopeFoldersPrefix)
+		if !ok || uid == "" || uid == "*" || uid == folder.GeneralFolderUID {
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	if len(uids) == 0 {
+		return scopes, nil
+	}
+
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.withAncestorScopes")
+	defer span.End()
+
+	result := slices.Clone(scopes)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		for depth := 0; depth < folder.MaxNestedFolderDepth && len(uids) > 0; depth++ {
+			args := []any{orgID}
+			for _, uid := range uids {
+				args = append(args, uid)
+			}
+
+			var parents []string
+			sql := "SELECT DISTINCT parent_uid FROM folder WHERE org_id = ? AND parent_uid IS NOT NULL AND uid IN (?" + strings.Repeat(",?", len(uids)-1) + ")"
+			if err := sess.SQL(sql, args...).Find(&parents); err != nil {
+				return err
+			}
+
+			uids = uids[:0]
+			for _, parent := range parents {
+				scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent)
+				if _, ok := seen[scope]; ok || parent == "" {
+					continue
+				}
+				seen[scope] = struct{}{}
+				result = append(result, scope)
+				uids = append(uids, parent)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
-func (s *store) SetResourcePermissions(
-	ctx context.Context, orgID int64,
-	commands []SetResourcePermissionsCommand,
-	hooks ResourceHooks,
-) ([]accesscontrol.ResourcePermission, error) {
-	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.SetResourcePermissions")
+// GetResourcePermissionsVersion returns a cheap version of the permissions of a resource, computed from the number
+// of permissions on the scopes of the resource, its ancestors and its wildcards, of the assignments of their roles and
+// from their latest update. The version changes whenever such a permission, an assignment of its role or its
+// metadata is added, removed or updated. Renaming an assignee doesn't change it. The counts are read in a single
+// transaction.
+func (s *store) GetResourcePermissionsVersion(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) (string, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetResourcePermissionsVersion")
 	defer span.End()
 
-	var err error
-	var permissions []accesscontrol.ResourcePermission
+	inheritedScopes, err := s.withAncestorScopes(ctx, orgID, query.InheritedScopes)
+	if err != nil {
+		return "", err
+	}
+
+	scope := accesscontrol.Scope(query.Resource, query.ResourceAttribute, query.ResourceID)
+	scopes := append([]string{scope}, inheritedScopes...)
+	scopes = append(scopes, s.wildcards.ForScope(scope)...)
+
+	args := []any{orgID, accesscontrol.GlobalOrgID}
+	for _, sc := range scopes {
+		args = append(args, sc)
+	}
+	from := " FROM permission p INNER JOIN role r ON r.id = p.role_id WHERE r.org_id IN (?, ?) AND p.scope IN (?" + strings.Repeat(",?", len(scopes)-1) + ")"
+
+	// Roles are assigned through one of these tables, assignments are only ever added or removed
+	roles := " WHERE role_id IN (SELECT p.role_id" + from + ")"
+	parts := []struct {
+		updatedColumn string
+		from          string
+		args          []any
+	}{
+		{updatedColumn: "p.updated", from: from, args: args},
+		{updatedColumn: "created", from: " FROM user_role" + roles, args: args},
+		{updatedColumn: "created", from: " FROM team_role" + roles, args: args},
+		{updatedColumn: "updated", from: " FROM builtin_role" + roles, args: args},
+		{updatedColumn: "updated", from: " FROM permission_metadata WHERE org_id = ? AND scope = ?", args: []any{orgID, scope}},
+	}
 
+	var version string
 	err = s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		for _, cmd := range commands {
-			var p *accesscontrol.ResourcePermission
-			if cmd.User.ID != 0 {
-				p, err = s.setUserResourcePermission(sess, orgID, cmd.User, cmd.SetResourcePermissionCommand, hooks.User)
-			} else if cmd.TeamID != 0 {
-				p, err = s.setTeamResourcePermission(sess, orgID, cmd.TeamID, cmd.SetResourcePermissionCommand, hooks.Team)
-			} else if org.RoleType(cmd.BuiltinRole).IsValid() || cmd.BuiltinRole == accesscontrol.RoleGrafanaAdmin {
-				p, err = s.setBuiltInResourcePermission(sess, orgID, cmd.BuiltinRole, cmd.SetResourcePermissionCommand, hooks.BuiltInRole)
-			}
+		versions := make([]string, 0, len(parts))
+		for _, part := range parts {
+			v, err := countAndLatestUpdate(sess, part.updatedColumn, part.from, part.args)
 			if err != nil {
 				return err
 			}
-			if p != nil {
-				permissions = append(permissions, *p)
-			}
+			versions = append(versions, v)
 		}
-
+		version = strings.Join(versions, "/")
 		return nil
 	})
-
-	return permissions, err
+	return version, err
 }
 
-type roleAdder func(roleID int64) error
+// countAndLatestUpdate returns the number of rows selected by from and their latest update as "<count>-<unix nano>".
+// The latest update is selected as a column rather than with MAX, so that it is read as a time on every database.
+func countAndLatestUpdate(sess *db.Session, updatedColumn, from string, args []any) (string, error) {
+	var count int64
+	if _, err := sess.SQL("SELECT COUNT(*)"+from, args...).Get(&count); err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return "0-0", nil
+	}
 
-func (s *store) setResourcePermission(
-	sess *db.Session, orgID int64, roleName string, adder roleAdder, cmd SetResourcePermissionCommand,
-) (*accesscontrol.ResourcePermission, error) {
-	role, err := s.getOrCreateManagedRole(sess, orgID, roleName, adder)
-	if err != nil {
-		return nil, err
+	var latest struct {
+		Updated time.Time `xorm:"updated"`
+	}
+	if _, err := sess.SQL("SELECT "+updatedColumn+" AS updated"+from+" ORDER BY "+updatedColumn+" DESC LIMIT 1", args...).Get(&latest); err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("%d-%d", count, latest.Updated.UnixNano()), nil
+}
 
-	rawSQL := `SELECT p.* FROM permission as p INNER JOIN role r on r.id = p.role_id WHERE r.id = ? AND p.scope = ?`
+// HasMissingManagedRoles returns true when one of the managed roles doesn't exist in the org yet. Setting a permission
+// for its assignee then adds a role and a role assignment.
+func (s *store) HasMissingManagedRoles(ctx context.Context, orgID int64, names []string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.HasMissingManagedRoles")
+	defer span.End()
 
-	var current []accesscontrol.Permission
-	scope := accesscontrol.Scope(cmd.Resource, cmd.ResourceAttribute, cmd.ResourceID)
-	if err := sess.SQL(rawSQL, role.ID, scope).Find(&current); err != nil {
-		return nil, err
+	names = slices.Compact(slices.Sorted(slices.Values(names)))
+	if len(names) == 0 {
+		return false, nil
 	}
 
-	missing := make(map[string]struct{}, len(cmd.Actions))
-	for _, a := range cmd.Actions {
-		missing[a] = struct{}{}
+	args := []any{orgID}
+	for _, name := range names {
+		args = append(args, name)
 	}
 
-	var remove []int64
-	for _, p := range current {
-		if _, ok := missing[p.Action]; ok {
-			delete(missing, p.Action)
-		} else if !ok {
-			remove = append(remove, p.ID)
-		}
+	var count int
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL("SELECT COUNT(*) FROM role WHERE org_id = ? AND name IN (?"+strings.Repeat(",?", len(names)-1)+")", args...).Get(&count)
+		return err
+	})
+	return count < len(names), err
+}
+
+// parallelBranches returns true when the user, team and basic role branches of the permissions query are executed
+// as concurrent queries instead of a single UNION, the Postgres and MySQL planners struggle with the combined query.
+// SQLite serializes the connections anyway and a session opened by the caller, e.g. a transaction, can't be shared.
+func (s *store) parallelBranches(ctx context.Context) bool {
+	if s.sql.GetDialect().DriverName() == migrator.SQLite {
+		return false
 	}
+	return !hasCallerSession(ctx)
+}
 
-	if err := deletePermissions(sess, remove); err != nil {
-		return nil, err
+// hasCallerSession returns true when ctx carries a session opened by the caller, e.g. a transaction started with
+// InTransaction. The sessions opened with ctx then join it.
+func hasCallerSession(ctx context.Context) bool {
+	_, ok := ctx.Value(sqlstore.ContextSessionKey{}).(*sqlstore.DBSession)
+	return ok
+}
+
+func (s *store) getResourcePermissions(ctx context.Context, sess *db.Session, query GetResourcePermissionsQuery, branches []permissionsBranch) ([]accesscontrol.ResourcePermission, error) {
+	queries := make([]string, 0, len(branches))
+	args := make([]any, 0)
+	for _, b := range branches {
+		queries = append(queries, "SELECT"+b.sql)
+		args = append(args, b.args...)
 	}
 
-	if err := s.createPermissions(sess, role.ID, cmd, missing); err != nil {
+	// Rows of a UNION have no defined order, ordering by id returns the assignees in the order they got their permissions.
+	// A permission belongs to the managed role of a single assignee, the assignee columns only make the order total.
+	sql := strings.Join(queries, " UNION ") + " ORDER BY id, user_id, team_id, built_in_role, group_id"
+	queryResults := make([]flatResourcePermission, 0)
+	if err := s.slowQueries.Find(ctx, sess, "getResourcePermissions", &queryResults, sql, args...); err != nil {
 		return nil, err
 	}
 
-	permissions, err := s.getPermissions(sess, cmd.Resource, cmd.ResourceID, cmd.ResourceAttribute, role.ID)
-	if err != nil {
+	return s.toResourcePermissions(query, queryResults)
+}
+
+// getResourcePermissionsParallel executes each branch of the permissions query in its own session and merges
+// the rows in the order of the single query.
+func (s *store) getResourcePermissionsParallel(ctx context.Context, query GetResourcePermissionsQuery, branches []permissionsBranch) ([]accesscontrol.ResourcePermission, error) {
+	results := make([][]flatResourcePermission, len(branches))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, b := range branches {
+		g.Go(func() error {
+			return s.sql.WithDbSession(gctx, func(sess *db.Session) error {
+				results[i] = make([]flatResourcePermission, 0)
+				// Like the UNION, the rows of a role assigned both globally and in the org are only returned once
+				return s.slowQueries.Find(gctx, sess, "getResourcePermissions."+b.name, &results[i], "SELECT DISTINCT"+b.sql, b.args...)
+			})
+		})
+	}
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	permission := flatPermissionsToResourcePermission(scope, permissions)
-	if permission == nil {
-		return &accesscontrol.ResourcePermission{}, nil
-	}
+	queryResults := slices.Concat(results...)
+	slices.SortStableFunc(queryResults, func(a, b flatResourcePermission) int {
+		return cmp.Or(
+			cmp.Compare(a.ID, b.ID),
+			cmp.Compare(a.UserId, b.UserId),
+			cmp.Compare(a.TeamId, b.TeamId),
+			strings.Compare(a.BuiltInRole, b.BuiltInRole),
+			strings.Compare(a.GroupID, b.GroupID),
+		)
+	})
 
-	return permission, nil
+	return s.toResourcePermissions(query, queryResults)
 }
 
-func (s *store) GetResourcePermissions(ctx context.Context, orgID int64, query GetResourcePermissionsQuery) ([]accesscontrol.ResourcePermission, error) {
-	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetResourcePermissions")
-	defer span.End()
-
-	var result []accesscontrol.ResourcePermission
-
-	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
-		var err error
-		result, err = s.getResourcePermissions(sess, orgID, query)
-		return err
-	})
+// permissionsBranch is the query of the permissions of one kind of assignee, without the SELECT keyword.
+type permissionsBranch struct {
+	name string
+	sql  string
+	args []any
+	// kind orders the branches, users come first, then teams, basic roles and external groups
+	kind int
+	// from is the query without its select list, assignee selects the user_id, team_id, built_in_role and group_id columns and
+	// assigneeColumn identifies the assignee of a row in the query
+	from           string
+	assignee       string
+	assigneeColumn string
+}
 
-	return result, err
+// restrict limits the branch to the assignees with the identifiers, a branch without identifiers is dropped.
+func (b permissionsBranch) restrict(ids []any) (permissionsBranch, bool) {
+	if len(ids) == 0 {
+		return b, false
+	}
+	filter := " AND " + b.assigneeColumn + " IN (?" + strings.Repeat(",?", len(ids)-1) + ")"
+	b.sql += filter
+	b.from += filter
+	b.args = append(slices.Clone(b.args), ids...)
+	return b, true
 }
 
-func (s *store) getResourcePermissions(sess *db.Session, orgID int64, query GetResourcePermissionsQuery) ([]accesscontrol.ResourcePermission, error) {
+// resourcePermissionsBranches returns the user, team, basic role and external group branches of the permissions query.
+func (s *store) resourcePermissionsBranches(orgID int64, query GetResourcePermissionsQuery) ([]permissionsBranch, error) {
 	if len(query.Actions) == 0 {
 		return nil, nil
 	}
 
+	// The string columns of the UNION get the same collation, MySQL fails to combine the collation of
+	// literals and columns when the server and the tables use different defaults, e.g. on MySQL 8.
+	collate := func(expr string) string {
+		if s.sql.GetDialect().DriverName() == migrator.MySQL {
+			return expr + " COLLATE " + mysqlCollation
+		}
+		return expr
+	}
+	empty := collate("''")
+
 	rawSelect := `
-	SELECT
 		p.*,
-		r.name as role_name,
+		` + collate("r.name") + ` as role_name,
 	`
 
 	userSelect := rawSelect + `
 		ur.user_id AS user_id,
-		u.login AS user_login,
+		` + collate("u.login") + ` AS user_login,
 		u.is_service_account AS is_service_account,
-		u.email AS user_email,
+		` + collate("u.email") + ` AS user_email,
 		0 AS team_id,
-		'' AS team,
-		'' AS team_email,
-		'' AS built_in_role
+		` + empty + ` AS team,
+		` + empty + ` AS team_email,
+		` + empty + ` AS built_in_role,
+		` + empty + ` AS group_id
 	`
 
 	teamSelect := rawSelect + `
 		0 AS user_id,
-		'' AS user_login,
+		` + empty + ` AS user_login,
 		` + s.sql.GetDialect().BooleanStr(false) + ` AS is_service_account,
-		'' AS user_email,
+		` + empty + ` AS user_email,
 		tr.team_id AS team_id,
-		t.name AS team,
-		t.email AS team_email,
-		'' AS built_in_role
+		` + collate("t.name") + ` AS team,
+		` + collate("t.email") + ` AS team_email,
+		` + empty + ` AS built_in_role,
+		` + empty + ` AS group_id
 	`
 
 	builtinSelect := rawSelect + `
 		0 AS user_id,
-		'' AS user_login,
+		` + empty + ` AS user_login,
+		` + s.sql.GetDialect().BooleanStr(false) + ` AS is_service_account,
+		` + empty + ` AS user_email,
+		0 as team_id,
+		` + empty + ` AS team,
+		` + empty + ` AS team_email,
+		` + collate("br.role") + ` AS built_in_role,
+		` + empty + ` AS group_id
+	`
+
+	groupSelect := rawSelect + `
+		0 AS user_id,
+		` + empty + ` AS user_login,
 		` + s.sql.GetDialect().BooleanStr(false) + ` AS is_service_account,
-		'' AS user_email,
+		` + empty + ` AS user_email,
 		0 as team_id,
-		'' AS team,
-		'' AS team_email,
-		br.role AS built_in_role
+		` + empty + ` AS team,
+		` + empty + ` AS team_email,
+		` + empty + ` AS built_in_role,
+		` + collate("gr.group_id") + ` AS group_id
 	`
 
 	rawFrom := `
@@ -377,17 +1265,18 @@ func (s *store) getResourcePermissions(sess *db.Session, orgID int64, query GetR
 	builtinFrom := rawFrom + `
 		INNER JOIN builtin_role br ON r.id = br.role_id AND (br.org_id = 0 OR br.org_id = ?)
 	`
-
-	where := `WHERE (r.org_id = ? OR r.org_id = 0) AND (p.scope = '*' OR p.scope = ? OR p.scope = ? OR p.scope = ?`
+	groupFrom := rawFrom + `
+		INNER JOIN group_role gr ON r.id = gr.role_id AND gr.org_id = ?
+	`
 
 	scope := accesscontrol.Scope(query.Resource, query.ResourceAttribute, query.ResourceID)
+	scopes := append(s.wildcards.ForScope(scope), scope)
 
-	args := []any{
-		orgID,
-		orgID,
-		accesscontrol.Scope(query.Resource, "*"),
-		accesscontrol.Scope(query.Resource, query.ResourceAttribute, "*"),
-		scope,
+	where := `WHERE (r.org_id = ? OR r.org_id = 0) AND (p.scope IN (?` + strings.Repeat(",?", len(scopes)-1) + `)`
+
+	args := []any{orgID, orgID}
+	for _, sc := range scopes {
+		args = append(args, sc)
 	}
 
 	if len(query.InheritedScopes) > 0 {
@@ -407,8 +1296,8 @@ func (s *store) getResourcePermissions(sess *db.Session, orgID int64, query GetR
 		args = append(args, a)
 	}
 
-	initialLength := len(args)
-	userQuery := userSelect + userFrom + where
+	userQuery := userFrom + where
+	userArgs := slices.Clone(args)
 	if query.EnforceAccessControl {
 		userFilter, err := accesscontrol.Filter(query.User, "u.id", "users:id:", accesscontrol.ActionOrgUsersRead)
 		if err != nil {
@@ -425,8 +1314,8 @@ func (s *store) getResourcePermissions(sess *db.Session, orgID int64, query GetR
 		filter += " OR (" + saFilter.Where + " AND u.is_service_account))"
 
 		userQuery += " AND " + filter
-		args = append(args, userFilter.Args...)
-		args = append(args, saFilter.Args...)
+		userArgs = append(userArgs, userFilter.Args...)
+		userArgs = append(userArgs, saFilter.Args...)
 	}
 
 	teamFilter, err := accesscontrol.Filter(query.User, "t.id", "teams:id:", accesscontrol.ActionTeamsRead)
@@ -434,73 +1323,256 @@ func (s *store) getResourcePermissions(sess *db.Session, orgID int64, query GetR
 		return nil, err
 	}
 
-	team := teamSelect + teamFrom + where + " AND " + teamFilter.Where
-	args = append(args, args[:initialLength]...)
-	args = append(args, teamFilter.Args...)
+	team := teamFrom + where + " AND " + teamFilter.Where
+	teamArgs := append(slices.Clone(args), teamFilter.Args...)
+
+	builtin := builtinFrom + where
+	group := groupFrom + where
+
+	return []permissionsBranch{
+		{
+			name: "users", sql: userSelect + userQuery, args: userArgs, kind: 0, from: userQuery,
+			assignee: "ur.user_id AS user_id, 0 AS team_id, " + empty + " AS built_in_role, " + empty + " AS group_id", assigneeColumn: "ur.user_id",
+		},
+		{
+			name: "teams", sql: teamSelect + team, args: teamArgs, kind: 1, from: team,
+			assignee: "0 AS user_id, tr.team_id AS team_id, " + empty + " AS built_in_role, " + empty + " AS group_id", assigneeColumn: "tr.team_id",
+		},
+		{
+			name: "builtins", sql: builtinSelect + builtin, args: args, kind: 2, from: builtin,
+			assignee: "0 AS user_id, 0 AS team_id, " + collate("br.role") + " AS built_in_role, " + empty + " AS group_id", assigneeColumn: "br.role",
+		},
+		{
+			name: "groups", sql: groupSelect + group, args: args, kind: 3, from: group,
+			assignee: "0 AS user_id, 0 AS team_id, " + empty + " AS built_in_role, " + collate("gr.group_id") + " AS group_id", assigneeColumn: "gr.group_id",
+		},
+	}, nil
+}
 
-	builtin := builtinSelect + builtinFrom + where
-	args = append(args, args[:initialLength]...)
+// toResourcePermissions groups the rows of the permissions query by assignee and applies the options of the query.
+func (s *store) toResourcePermissions(query GetResourcePermissionsQuery, queryResults []flatResourcePermission) ([]accesscontrol.ResourcePermission, error) {
+	scope := accesscontrol.Scope(query.Resource, query.ResourceAttribute, query.ResourceID)
 
-	sql := userQuery + " UNION " + team + " UNION " + builtin
-	queryResults := make([]flatResourcePermission, 0)
-	if err := sess.SQL(sql, args...).Find(&queryResults); err != nil {
-		return nil, err
-	}
+	result := flatPermissionsToResourcePermissions(scope, queryResults)
 
-	var result []accesscontrol.ResourcePermission
-	users, teams, builtins := groupPermissionsByAssignment(queryResults)
-	for _, p := range users {
-		result = append(result, flatPermissionsToResourcePermissions(scope, p)...)
+	if query.Permission != "" {
+		result = s.filterByPermission(result, query.Resource, query.Permission)
 	}
-	for _, p := range teams {
-		result = append(result, flatPermissionsToResourcePermissions(scope, p)...)
+
+	if len(query.GrantingOneOf) > 0 {
+		result = slices.DeleteFunc(result, func(p accesscontrol.ResourcePermission) bool {
+			if p.Deny {
+				return false
+			}
+			granted := s.grantedActions(p)
+			return !slices.ContainsFunc(query.GrantingOneOf, func(actions []string) bool {
+				return !slices.ContainsFunc(actions, func(action string) bool { return !granted[action] })
+			})
+		})
 	}
-	for _, p := range builtins {
-		result = append(result, flatPermissionsToResourcePermissions(scope, p)...)
+
+	if query.ExpandActionSets {
+		s.expandActionSets(result)
 	}
 
 	return result, nil
 }
 
-func groupPermissionsByAssignment(permissions []flatResourcePermission) (map[int64][]flatResourcePermission, map[int64][]flatResourcePermission, map[string][]flatResourcePermission) {
-	users := make(map[int64][]flatResourcePermission)
-	teams := make(map[int64][]flatResourcePermission)
-	builtins := make(map[string][]flatResourcePermission)
+// filterByPermission keeps the assignments granting at least the actions of permission. Action sets found in the
+// assignments are resolved so an assignment of a higher permission, e.g. folders:admin, also grants folders:edit.
+// Resources without action sets can't be filtered, their permissions are returned unfiltered.
+func (s *store) filterByPermission(permissions []accesscontrol.ResourcePermission, resource, permission string) []accesscontrol.ResourcePermission {
+	required := s.permissionActions(resource, permission)
+	if len(required) == 0 {
+		return permissions
+	}
 
+	filtered := make([]accesscontrol.ResourcePermission, 0, len(permissions))
 	for _, p := range permissions {
-		if p.UserId != 0 {
-			users[p.UserId] = append(users[p.UserId], p)
-		} else if p.TeamId != 0 {
-			teams[p.TeamId] = append(teams[p.TeamId], p)
-		} else if p.BuiltInRole != "" {
-			builtins[p.BuiltInRole] = append(builtins[p.BuiltInRole], p)
+		if p.Deny {
+			continue
+		}
+
+		granted := s.grantedActions(p)
+		if !slices.ContainsFunc(required, func(action string) bool { return !granted[action] }) {
+			filtered = append(filtered, p)
 		}
 	}
+	return filtered
+}
 
-	return users, teams, builtins
+// permissionActions returns the actions of the action set of the permission, none for resources without action sets.
+func (s *store) permissionActions(resource, permission string) []string {
+	if s.actionSets == nil || permission == "" {
+		return nil
+	}
+	return s.actionSets.ResolveActionSet(GetActionSetName(resource, permission))
 }
 
-func flatPermissionsToResourcePermissions(scope string, permissions []flatResourcePermission) []accesscontrol.ResourcePermission {
-	var managed, inherited, provisioned []flatResourcePermission
-	for _, p := range permissions {
+// grantedActions returns the actions of the assignment with the actions of its action sets.
+func (s *store) grantedActions(p accesscontrol.ResourcePermission) map[string]bool {
+	granted := make(map[string]bool, len(p.Actions))
+	for _, action := range p.Actions {
+		granted[action] = true
+		if s.actionSets == nil {
+			continue
+		}
+		for _, a := range s.actionSets.ResolveActionSet(action) {
+			granted[a] = true
+		}
+	}
+	return granted
+}
+
+// expandActionSets populates ActionSets for every permission that contains one or more action sets
+func (s *store) expandActionSets(permissions []accesscontrol.ResourcePermission) {
+	if s.actionSets == nil {
+		return
+	}
+
+	for i := range permissions {
+		for _, action := range permissions[i].Actions {
+			actions := s.actionSets.ResolveActionSet(action)
+			if len(actions) == 0 {
+				continue
+			}
+			if permissions[i].ActionSets == nil {
+				permissions[i].ActionSets = make(map[string][]string)
+			}
+			permissions[i].ActionSets[action] = actions
+		}
+	}
+}
+
+const (
+	managedPermissions = iota
+	inheritedPermissions
+	provisionedPermissions
+	permissionCategories
+)
+
+// assigneeKey identifies the user, team, basic role or external group a permission is assigned to.
+type assigneeKey struct {
+	userID      int64
+	teamID      int64
+	builtInRole string
+	groupID     string
+}
+
+// permissionGroup is the set of rows of an assignee in one category (managed, inherited or provisioned).
+type permissionGroup struct {
+	// first is the row of the first permission of the group, it holds the assignee and role of the group
+	first int
+	count int
+	// offset is the position of the actions of the group in the actions shared by all groups
+	offset int
+	filled int
+}
+
+// rowsPerAssigneeEstimate is used to size the groups, an assignee usually has several actions on a resource
+const rowsPerAssigneeEstimate = 4
+
+// rowGroupsPool holds the slices mapping rows to their group, folders can have thousands of permissions.
+var rowGroupsPool = sync.Pool{New: func() any { return new([]int) }}
+
+// flatPermissionsToResourcePermissions groups the permissions of each user, team, basic role and external group into
+// managed, inherited and provisioned permissions. Groups of users come first, then teams, basic roles and external
+// groups, each ordered by the first permission of the assignee. The rows are grouped in a single pass and the actions
+// of all groups share one slice, so the cost does not depend on the number of assignees.
+func flatPermissionsToResourcePermissions(scope string, rows []flatResourcePermission) []accesscontrol.ResourcePermission {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	rowGroupsPtr := rowGroupsPool.Get().(*[]int)
+	defer rowGroupsPool.Put(rowGroupsPtr)
+	if cap(*rowGroupsPtr) < len(rows) {
+		*rowGroupsPtr = make([]int, len(rows))
+	}
+	rowGroups := (*rowGroupsPtr)[:len(rows)]
+
+	// groups holds permissionCategories consecutive groups per assignee, assignees holds the first of them
+	estimatedAssignees := len(rows)/rowsPerAssigneeEstimate + 1
+	groups := make([]permissionGroup, 0, permissionCategories*estimatedAssignees)
+	assignees := make(map[assigneeKey]int, estimatedAssignees)
+	// order lists the assignees of users, teams, basic roles and groups in the order of their first permission
+	var order [4][]int
+
+	for i := range rows {
+		p := &rows[i]
+		var key assigneeKey
+		var kind int
+		switch {
+		case p.UserId != 0:
+			key, kind = assigneeKey{userID: p.UserId}, 0
+		case p.TeamId != 0:
+			key, kind = assigneeKey{teamID: p.TeamId}, 1
+		case p.BuiltInRole != "":
+			key, kind = assigneeKey{builtInRole: p.BuiltInRole}, 2
+		case p.GroupID != "":
+			key, kind = assigneeKey{groupID: p.GroupID}, 3
+		default:
+			rowGroups[i] = -1
+			continue
+		}
+
+		base, ok := assignees[key]
+		if !ok {
+			base = len(groups)
+			assignees[key] = base
+			for c := 0; c < permissionCategories; c++ {
+				groups = append(groups, permissionGroup{})
+			}
+			order[kind] = append(order[kind], base)
+		}
+
+		g := base + provisionedPermissions
 		if p.IsManaged(scope) {
-			managed = append(managed, p)
+			g = base + managedPermissions
 		} else if p.IsInherited(scope) {
-			inherited = append(inherited, p)
-		} else {
-			provisioned = append(provisioned, p)
+			g = base + inheritedPermissions
+		}
+		if groups[g].count == 0 {
+			groups[g].first = i
 		}
+		groups[g].count++
+		rowGroups[i] = g
 	}
 
-	var result []accesscontrol.ResourcePermission
-	if g := flatPermissionsToResourcePermission(scope, managed); g != nil {
-		result = append(result, *g)
+	offset, count := 0, 0
+	for _, bases := range order {
+		for _, base := range bases {
+			for g := base; g < base+permissionCategories; g++ {
+				if groups[g].count > 0 {
+					groups[g].offset = offset
+					offset += groups[g].count
+					count++
+				}
+			}
+		}
 	}
-	if g := flatPermissionsToResourcePermission(scope, inherited); g != nil {
-		result = append(result, *g)
+
+	actions := make([]string, offset)
+	for i, g := range rowGroups {
+		if g < 0 {
+			continue
+		}
+		group := &groups[g]
+		actions[group.offset+group.filled] = rows[i].Action
+		group.filled++
 	}
-	if g := flatPermissionsToResourcePermission(scope, provisioned); g != nil {
-		result = append(result, *g)
+
+	result := make([]accesscontrol.ResourcePermission, 0, count)
+	for _, bases := range order {
+		for _, base := range bases {
+			for g := base; g < base+permissionCategories; g++ {
+				if group := groups[g]; group.count > 0 {
+					// Capped so that appending to the actions of a permission can't overwrite the next one
+					end := group.offset + group.count
+					result = append(result, toResourcePermission(scope, &rows[group.first], actions[group.offset:end:end]))
+				}
+			}
+		}
 	}
 
 	return result
@@ -512,15 +1584,21 @@ func flatPermissionsToResourcePermission(scope string, permissions []flatResourc
 	}
 
 	actions := make([]string, 0, len(permissions))
-	for _, p := range permissions {
-		actions = append(actions, p.Action)
+	for i := range permissions {
+		actions = append(actions, permissions[i].Action)
 	}
 
-	first := permissions[0]
-	return &accesscontrol.ResourcePermission{
+	permission := toResourcePermission(scope, &permissions[0], actions)
+	return &permission
+}
+
+// toResourcePermission returns the permission with actions of the assignee and role of first.
+func toResourcePermission(scope string, first *flatResourcePermission, actions []string) accesscontrol.ResourcePermission {
+	return accesscontrol.ResourcePermission{
 		ID:               first.ID,
 		RoleName:         first.RoleName,
 		Actions:          actions,
+		Deny:             first.Deny,
 		Scope:            first.Scope,
 		UserId:           first.UserId,
 		UserLogin:        first.UserLogin,
@@ -529,6 +1607,7 @@ func flatPermissionsToResourcePermission(scope string, permissions []flatResourc
 		TeamEmail:        first.TeamEmail,
 		Team:             first.Team,
 		BuiltInRole:      first.BuiltInRole,
+		GroupID:          first.GroupID,
 		Created:          first.Created,
 		Updated:          first.Updated,
 		IsManaged:        first.IsManaged(scope),
@@ -538,63 +1617,65 @@ func flatPermissionsToResourcePermission(scope string, permissions []flatResourc
 }
 
 func (s *store) userAdder(sess *db.Session, orgID, userID int64) roleAdder {
-	return func(roleID int64) error {
-		if res, err := sess.Query("SELECT 1 FROM user_role WHERE org_id=? AND user_id=? AND role_id=?", orgID, userID, roleID); err != nil {
-			return err
-		} else if len(res) == 1 {
-			return fmt.Errorf("role is already added to this user")
-		}
+	return assignmentAdder(sess, "user_role", "org_id = ? AND user_id = ?", []any{orgID, userID}, func(roleID int64) any {
+		return &accesscontrol.UserRole{OrgID: orgID, UserID: userID, RoleID: roleID, Created: time.Now()}
+	})
+}
 
-		userRole := &accesscontrol.UserRole{
-			OrgID:   orgID,
-			UserID:  userID,
-			RoleID:  roleID,
-			Created: time.Now(),
-		}
+func (s *store) teamAdder(sess *db.Session, orgID, teamID int64) roleAdder {
+	return assignmentAdder(sess, "team_role", "org_id = ? AND team_id = ?", []any{orgID, teamID}, func(roleID int64) any {
+		return &accesscontrol.TeamRole{OrgID: orgID, TeamID: teamID, RoleID: roleID, Created: time.Now()}
+	})
+}
 
-		_, err := sess.Insert(userRole)
+func (s *store) builtInRoleAdder(sess *db.Session, orgID int64, builtinRole string) roleAdder {
+	return assignmentAdder(sess, "builtin_role", "org_id = ? AND role = ?", []any{orgID, builtinRole}, func(roleID int64) any {
+		return &accesscontrol.BuiltinRole{OrgID: orgID, Role: builtinRole, RoleID: roleID, Created: time.Now(), Updated: time.Now()}
+	})
+}
 
-		return err
-	}
+func (s *store) groupAdder(sess *db.Session, orgID int64, groupID string) roleAdder {
+	return assignmentAdder(sess, "group_role", "org_id = ? AND group_id = ?", []any{orgID, groupID}, func(roleID int64) any {
+		return &accesscontrol.GroupRole{OrgID: orgID, GroupID: groupID, RoleID: roleID, Created: time.Now()}
+	})
 }
 
-func (s *store) teamAdder(sess *db.Session, orgID, teamID int64) roleAdder {
+// assignmentAdder returns a roleAdder inserting the assignment built by newAssignment into table, unless the subject
+// matching where already has the role. An insert racing with a concurrent request fails on the unique index of the
+// table, the transaction is then retried by inTransaction and finds the existing assignment.
+func assignmentAdder(sess *db.Session, table, where string, args []any, newAssignment func(roleID int64) any) roleAdder {
 	return func(roleID int64) error {
-		if res, err := sess.Query("SELECT 1 FROM team_role WHERE org_id=? AND team_id=? AND role_id=?", orgID, teamID, roleID); err != nil {
+		exists, err := sess.Table(table).Where(where, args...).And("role_id = ?", roleID).Exist()
+		if err != nil || exists {
 			return err
-		} else if len(res) == 1 {
-			return fmt.Errorf("role is already added to this team")
 		}
 
-		teamRole := &accesscontrol.TeamRole{
-			OrgID:   orgID,
-			TeamID:  teamID,
-			RoleID:  roleID,
-			Created: time.Now(),
-		}
-
-		_, err := sess.Insert(teamRole)
+		_, err = sess.Table(table).Insert(newAssignment(roleID))
 		return err
 	}
 }
 
-func (s *store) builtInRoleAdder(sess *db.Session, orgID int64, builtinRole string) roleAdder {
-	return func(roleID int64) error {
-		if res, err := sess.Query("SELECT 1 FROM builtin_role WHERE role_id=? AND role=? AND org_id=?", roleID, builtinRole, orgID); err != nil {
+// inTransaction runs fn in a transaction and retries it, with a growing delay, when it fails on a deadlock or on a
+// unique index because a concurrent request created the same managed role or assignment first. When fn joins the
+// transaction of the caller it is not retried, the failure aborted the whole transaction and only the caller can
+// run it again.
+func (s *store) inTransaction(ctx context.Context, fn func(sess *db.Session) error) error {
+	if hasCallerSession(ctx) {
+		return s.sql.WithTransactionalDbSession(ctx, fn)
+	}
+
+	dialect := s.sql.GetDialect()
+	for retry := 1; ; retry++ {
+		err := s.sql.WithTransactionalDbSession(ctx, fn)
+		if err == nil || retry > maxTransactionRetries || !(dialect.IsDeadlock(err) || dialect.IsUniqueConstraintViolation(err)) {
 			return err
-		} else if len(res) == 1 {
-			return fmt.Errorf("built-in role already has the role granted")
 		}
 
-		_, err := sess.Table("builtin_role").Insert(accesscontrol.BuiltinRole{
-			RoleID:  roleID,
-			OrgID:   orgID,
-			Role:    builtinRole,
-			Updated: time.Now(),
-			Created: time.Now(),
-		})
-
-		return err
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(retry) * transactionRetryDelay):
+		}
 	}
 }
 
@@ -662,7 +1743,8 @@ func (s *store) getPermissions(sess *db.Session, resource, resourceID, resourceA
 		t.name AS team,
 		t.email AS team_email,
 		r.name as role_name,
-		br.role AS built_in_role
+		br.role AS built_in_role,
+		gr.group_id AS group_id
 	FROM permission p
 		INNER JOIN role r ON p.role_id = r.id
 		LEFT JOIN team_role tr ON r.id = tr.role_id
@@ -670,6 +1752,7 @@ func (s *store) getPermissions(sess *db.Session, resource, resourceID, resourceA
 		LEFT JOIN user_role ur ON r.id = ur.role_id
 		LEFT JOIN ` + s.sql.GetDialect().Quote("user") + ` u ON ur.user_id = u.id
 		LEFT JOIN builtin_role br ON r.id = br.role_id
+		LEFT JOIN group_role gr ON r.id = gr.role_id
 	WHERE r.id = ? AND p.scope = ?
 	`
 	if err := sess.SQL(rawSql, roleID, accesscontrol.Scope(resource, resourceAttribute, resourceID)).Find(&result); err != nil {
@@ -706,11 +1789,21 @@ func (s *store) createPermissions(sess *db.Session, roleID int64, cmd SetResourc
 	}
 
 	// if we have actionset feature enabled and are only working with action sets
-	// skip adding the missing actions to the permissions table
-	if !(s.shouldStoreActionSet(resource, permission) && s.cfg.RBAC.OnlyStoreAccessActionSets) {
+	// skip adding the missing actions granted by the action set to the permissions table. The actions the permission
+	// grants on top of its action set, e.g. the sharing actions of Edit in the orgs where editors share, are still stored
+	onlyActionSet := s.shouldStoreActionSet(resource, permission) && s.cfg.RBAC.OnlyStoreAccessActionSets
+	if !onlyActionSet || s.actionSets != nil {
+		var inActionSet []string
+		if onlyActionSet {
+			inActionSet = s.actionSets.ResolveActionSet(GetActionSetName(resource, permission))
+		}
 		for action := range missingActions {
+			if slices.Contains(inActionSet, action) {
+				continue
+			}
 			p := managedPermission(action, resource, resourceID, resourceAttribute)
 			p.RoleID = roleID
+			p.Deny = cmd.Deny
 			p.Created = time.Now()
 			p.Updated = time.Now()
 			p.Kind, p.Attribute, p.Identifier = p.SplitScope()
@@ -718,38 +1811,45 @@ func (s *store) createPermissions(sess *db.Session, roleID int64, cmd SetResourc
 		}
 	}
 
-	if _, err := sess.InsertMulti(&permissions); err != nil {
+	if _, err := sess.BulkInsert("permission", permissions, s.bulkSettings()); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (s *store) shouldStoreActionSet(resource, permission string) bool {
-	if permission == "" {
+	if permission == "" || permission == DenyPermission {
 		return false
 	}
 	actionSetName := GetActionSetName(resource, permission)
-	return isFolderOrDashboardAction(actionSetName)
+	return isActionSetResourceAction(actionSetName)
 }
 
-func deletePermissions(sess *db.Session, ids []int64) error {
-	if len(ids) == 0 {
-		return nil
-	}
+// bulkSettings returns the batch size of bulk statements supported by the dialect.
+// SQLite gets small batches so a large write doesn't lock the database for long.
+func (s *store) bulkSettings() sqlstore.BulkOpSettings {
+	return sqlstore.NativeSettingsForDialect(s.sql.GetDialect())
+}
 
-	rawSQL := "DELETE FROM permission WHERE id IN(?" + strings.Repeat(",?", len(ids)-1) + ")"
-	args := make([]any, 0, len(ids)+1)
-	args = append(args, rawSQL)
-	for _, id := range ids {
-		args = append(args, id)
-	}
+// commitPerChunk reports whether bulk writes safe to partially apply are committed chunk by chunk.
+// It only applies to SQLite, where a single large transaction blocks all other writers.
+func (s *store) commitPerChunk() bool {
+	return s.cfg != nil && s.cfg.RBAC.SQLiteCommitPerChunk && s.sql.GetDialect().DriverName() == migrator.SQLite
+}
 
-	_, err := sess.Exec(args...)
-	if err != nil {
-		return err
-	}
+func deletePermissions(sess *db.Session, ids []int64, opts sqlstore.BulkOpSettings) error {
+	return sqlstore.InBatches(ids, opts, func(batch any) error {
+		chunk := batch.([]int64)
+		rawSQL := "DELETE FROM permission WHERE id IN(?" + strings.Repeat(",?", len(chunk)-1) + ")"
+		args := make([]any, 0, len(chunk)+1)
+		args = append(args, rawSQL)
+		for _, id := range chunk {
+			args = append(args, id)
+		}
 
-	return nil
+		_, err := sess.Exec(args...)
+		return err
+	})
 }
 
 func managedPermission(action, resource string, resourceID, resourceAttribute string) accesscontrol.Permission {
@@ -759,21 +1859,33 @@ func managedPermission(action, resource string, resourceID, resourceAttribute st
 	}
 }
 
-// InMemoryActionSets is an in-memory implementation of the ActionSetStore.
+// InMemoryActionSets is an in-memory implementation of the ActionSetStore. Action sets are read on every permission
+// check while plugins can store new ones at any time, so reads use an immutable snapshot of the action sets without
+// locking and writes replace the snapshot with an updated copy.
 type InMemoryActionSets struct {
-	features           featuremgmt.FeatureToggles
-	log                log.Logger
+	features featuremgmt.FeatureToggles
+	log      log.Logger
+	// mu serializes the writes, so that concurrent writes don't overwrite each other's snapshot
+	mu       sync.Mutex
+	snapshot atomic.Pointer[actionSetsSnapshot]
+}
+
+// actionSetsSnapshot holds the action sets at a point in time, it must not be modified once published.
+type actionSetsSnapshot struct {
 	actionSetToActions map[string][]string
 	actionToActionSets map[string][]string
 }
 
 func NewInMemoryActionSetStore(features featuremgmt.FeatureToggles) *InMemoryActionSets {
-	return &InMemoryActionSets{
+	s := &InMemoryActionSets{
+		log:      log.New("resourcepermissions.actionsets"),
+		features: features,
+	}
+	s.snapshot.Store(&actionSetsSnapshot{
 		actionSetToActions: make(map[string][]string),
 		actionToActionSets: make(map[string][]string),
-		log:                log.New("resourcepermissions.actionsets"),
-		features:           features,
-	}
+	})
+	return s
 }
 
 // ResolveActionPrefix returns all action sets that include at least one action with the specified prefix
@@ -782,10 +1894,11 @@ func (s *InMemoryActionSets) ResolveActionPrefix(prefix string) []string {
 		return []string{}
 	}
 
-	sets := make([]string, 0, len(s.actionSetToActions))
+	snapshot := s.snapshot.Load()
+	sets := make([]string, 0, len(snapshot.actionSetToActions))
 
-	for set, actions := range s.actionSetToActions {
-		for _, action := range actions {
+	for set := range snapshot.actionSetToActions {
+		for _, action := range snapshot.resolveActionSet(set) {
 			if strings.HasPrefix(action, prefix) {
 				sets = append(sets, set)
 				break
@@ -796,18 +1909,70 @@ func (s *InMemoryActionSets) ResolveActionPrefix(prefix string) []string {
 	return sets
 }
 
+// ResolveAction returns the action sets including the action, directly or through another action set.
 func (s *InMemoryActionSets) ResolveAction(action string) []string {
-	return s.actionToActionSets[action]
+	snapshot := s.snapshot.Load()
+	var sets []string
+	seen := map[string]bool{action: true}
+	queue := []string{action}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, set := range snapshot.actionToActionSets[current] {
+			if seen[set] {
+				continue
+			}
+			seen[set] = true
+			sets = append(sets, set)
+			queue = append(queue, set)
+		}
+	}
+	return sets
 }
 
+// ResolveActionSet returns the actions of the action set, the actions of the action sets it includes are
+// resolved transitively and returned in place of their name.
 func (s *InMemoryActionSets) ResolveActionSet(actionSet string) []string {
-	return s.actionSetToActions[actionSet]
+	return s.snapshot.Load().resolveActionSet(actionSet)
+}
+
+func (s *actionSetsSnapshot) resolveActionSet(actionSet string) []string {
+	members, ok := s.actionSetToActions[actionSet]
+	if !ok {
+		return nil
+	}
+
+	actions := make([]string, 0, len(members))
+	seen := map[string]bool{}
+	expanded := map[string]bool{actionSet: true}
+	var resolve func(set string, members []string)
+	resolve = func(set string, members []string) {
+		for _, member := range members {
+			// A set sharing its name with one of its actions, e.g. a plugin action set, includes that action
+			if _, ok := s.actionSetToActions[member]; ok && member != set {
+				if !expanded[member] {
+					expanded[member] = true
+					resolve(member, s.actionSetToActions[member])
+				}
+				continue
+			}
+			if !seen[member] {
+				seen[member] = true
+				actions = append(actions, member)
+			}
+		}
+	}
+	resolve(actionSet, members)
+	return actions
 }
 
+// ExpandActionSetsWithFilter returns the permissions with the action sets among them replaced by a permission for each
+// of their actions matched by actionMatcher.
 func (s *InMemoryActionSets) ExpandActionSetsWithFilter(permissions []accesscontrol.Permission, actionMatcher func(action string) bool) []accesscontrol.Permission {
+	snapshot := s.snapshot.Load()
 	var expandedPermissions []accesscontrol.Permission
 	for _, permission := range permissions {
-		resolvedActions := s.ResolveActionSet(permission.Action)
+		resolvedActions := snapshot.resolveActionSet(permission.Action)
 		if len(resolvedActions) == 0 {
 			expandedPermissions = append(expandedPermissions, permission)
 			continue
@@ -823,14 +1988,70 @@ func (s *InMemoryActionSets) ExpandActionSetsWithFilter(permissions []accesscont
 	return expandedPermissions
 }
 
+// ActionSets returns the names of the stored action sets, sorted.
+func (s *InMemoryActionSets) ActionSets() []string {
+	snapshot := s.snapshot.Load()
+	sets := make([]string, 0, len(snapshot.actionSetToActions))
+	for set := range snapshot.actionSetToActions {
+		sets = append(sets, set)
+	}
+	slices.Sort(sets)
+	return sets
+}
+
+// StoreActionSet stores the actions of an action set. Actions can be other action sets, they are then resolved
+// transitively. Action sets that would include the action set itself are ignored.
 func (s *InMemoryActionSets) StoreActionSet(name string, actions []string) {
-	s.actionSetToActions[name] = append(s.actionSetToActions[name], actions...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.snapshot.Load()
+	actions = slices.DeleteFunc(slices.Clone(actions), func(action string) bool {
+		// Sets stored again, e.g. by a service managing the permissions of a plugin resource, are not duplicated
+		if slices.Contains(current.actionSetToActions[name], action) {
+			return true
+		}
+		if action == name || !current.includesActionSet(action, name) {
+			return false
+		}
+		s.log.Error("Ignoring action set creating a cycle", "action set name", name, "included action set", action)
+		return true
+	})
 
+	// The slices of the current snapshot are shared with its readers, they are clipped so that appending to them
+	// allocates new ones
+	next := &actionSetsSnapshot{
+		actionSetToActions: maps.Clone(current.actionSetToActions),
+		actionToActionSets: maps.Clone(current.actionToActionSets),
+	}
+	next.actionSetToActions[name] = append(slices.Clip(current.actionSetToActions[name]), actions...)
 	for _, action := range actions {
-		if _, ok := s.actionToActionSets[action]; !ok {
-			s.actionToActionSets[action] = []string{}
-		}
-		s.actionToActionSets[action] = append(s.actionToActionSets[action], name)
+		next.actionToActionSets[action] = append(slices.Clip(next.actionToActionSets[action]), name)
 	}
+	s.snapshot.Store(next)
+
 	s.log.Debug("stored action set", "action set name", name)
 }
+
+// includesActionSet returns true when the action set includes target, directly or through another action set.
+func (s *actionSetsSnapshot) includesActionSet(actionSet, target string) bool {
+	visited := map[string]bool{}
+	queue := []string{actionSet}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, member := range s.actionSetToActions[current] {
+			if member == target {
+				return true
+			}
+			if _, ok := s.actionSetToActions[member]; ok && member != current {
+				queue = append(queue, member)
+			}
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	datasourcesService "github.com/grafana/grafana/pkg/services/datasources/service"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
@@ -183,3 +184,94 @@ func generateTeamsAndUsers(b *testing.B, store db.DB, cfg *setting.Cfg, users in
 
 	return userIds, teamIds
 }
+
+func BenchmarkSeededSetResourcePermission_1K_100_1K(b *testing.B) {
+	benchSeededSetResourcePermission(b, 1000, 100, 1000)
+}
+
+func BenchmarkSeededSetResourcePermission_10K_1K_10K(b *testing.B) {
+	benchSeededSetResourcePermission(b, 10000, 1000, 10000)
+}
+
+// benchSeededSetResourcePermission measures updating a user permission on the dashboard with the most
+// permissions, alternating between two permissions so every iteration writes. Run with GRAFANA_TEST_DB to compare dialects.
+func benchSeededSetResourcePermission(b *testing.B, usersCount, teamsCount, resourceCount int) {
+	if testing.Short() {
+		b.Skip("Skipping benchmark in short mode")
+	}
+
+	s, sql, _ := setupTestEnv(b)
+	seeded := actest.Seed(b, sql, actest.SeedOptions{
+		Users:                  usersCount,
+		Teams:                  teamsCount,
+		Resources:              resourceCount,
+		TeamsPerUser:           5,
+		PermissionsPerAssignee: 20,
+		Skew:                   1.2,
+		RandSeed:               1,
+	})
+
+	actions := [][]string{
+		{actest.SeedReadAction},
+		{actest.SeedReadAction, actest.SeedWriteAction},
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := s.SetUserResourcePermission(context.Background(), seeded.OrgID, accesscontrol.User{ID: seeded.UserIDs[0]},
+			SetResourcePermissionCommand{
+				Actions:           actions[i%len(actions)],
+				Resource:          actest.SeedResource,
+				ResourceID:        seeded.ResourceIDs[0],
+				ResourceAttribute: "uid",
+			}, nil)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkFlatPermissionsToResourcePermissions_100(b *testing.B) {
+	benchFlatPermissionsToResourcePermissions(b, 100)
+}
+
+func BenchmarkFlatPermissionsToResourcePermissions_1K(b *testing.B) {
+	benchFlatPermissionsToResourcePermissions(b, 1000)
+}
+
+func BenchmarkFlatPermissionsToResourcePermissions_10K(b *testing.B) {
+	benchFlatPermissionsToResourcePermissions(b, 10000)
+}
+
+// benchFlatPermissionsToResourcePermissions measures grouping the rows of a folder with assignees users, teams and
+// basic roles, each with managed and inherited permissions.
+func benchFlatPermissionsToResourcePermissions(b *testing.B, assignees int) {
+	scope := "folders:uid:f1"
+	rows := make([]flatResourcePermission, 0, assignees*4)
+	for i := 0; i < assignees; i++ {
+		p := flatResourcePermission{ID: int64(i), RoleName: fmt.Sprintf("managed:users:%d:permissions", i), Scope: scope}
+		switch i % 3 {
+		case 0:
+			p.UserId = int64(i + 1)
+		case 1:
+			p.TeamId = int64(i + 1)
+			p.RoleName = fmt.Sprintf("managed:teams:%d:permissions", i)
+		default:
+			p.BuiltInRole = fmt.Sprintf("role-%d", i)
+			p.RoleName = fmt.Sprintf("managed:builtins:%d:permissions", i)
+		}
+		for _, action := range []string{"folders:read", "folders:write", "dashboards:read"} {
+			p.Action = action
+			rows = append(rows, p)
+		}
+		inherited := p
+		inherited.Scope = "folders:uid:parent"
+		inherited.Action = "folders:read"
+		rows = append(rows, inherited)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		result := flatPermissionsToResourcePermissions(scope, rows)
+		require.Len(b, result, assignees*2)
+	}
+}
@@ -3,6 +3,7 @@ package resourcepermissions
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -19,10 +20,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
+	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type setUserResourcePermissionTest struct {
@@ -114,6 +117,34 @@ func TestIntegrationStore_SetUserResourcePermission(t *testing.T) {
 	}
 }
 
+func TestIntegrationStore_SetUserResourcePermissionDeny(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store, _, _ := setupTestEnv(t)
+
+	cmd := SetResourcePermissionCommand{
+		Actions:           []string{"datasources:query"},
+		Resource:          "datasources",
+		ResourceID:        "1",
+		ResourceAttribute: "uid",
+		Permission:        DenyPermission,
+		Deny:              true,
+	}
+	denied, err := store.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: 1}, cmd, nil)
+	require.NoError(t, err)
+	assert.True(t, denied.Deny)
+	assert.Equal(t, []string{"datasources:query"}, denied.Actions)
+
+	// switching the same actions from deny to allow replaces the permissions
+	cmd.Permission = "Query"
+	cmd.Deny = false
+	allowed, err := store.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: 1}, cmd, nil)
+	require.NoError(t, err)
+	assert.False(t, allowed.Deny)
+	assert.Equal(t, []string{"datasources:query"}, allowed.Actions)
+}
+
 type setTeamResourcePermissionTest struct {
 	desc              string
 	orgID             int64
@@ -244,6 +275,15 @@ func TestIntegrationStore_SetBuiltInResourcePermission(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:              "should add new resource permission for Grafana Admin in the org of the resource",
+			orgID:             1,
+			builtInRole:       accesscontrol.RoleGrafanaAdmin,
+			actions:           []string{"datasources:query"},
+			resource:          "datasources",
+			resourceID:        "1",
+			resourceAttribute: "uid",
+		},
 		{
 			desc:              "should remove permissions for builtin role",
 			orgID:             1,
@@ -519,6 +559,263 @@ func TestIntegrationStore_GetResourcePermissions(t *testing.T) {
 	}
 }
 
+func TestIntegrationStore_GetResourcePermissionsOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store, sql, cfg := setupTestEnv(t)
+	orgService, err := orgimpl.ProvideService(sql, cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+	usrSvc, err := userimpl.ProvideService(
+		sql, orgService, cfg, nil, nil, tracing.InitializeTracerForTest(),
+		quotatest.New(false, nil), supportbundlestest.NewFakeBundleService(),
+	)
+	require.NoError(t, err)
+	orgID, err := orgService.GetOrCreate(context.Background(), "test")
+	require.NoError(t, err)
+
+	cmd := SetResourcePermissionCommand{
+		Actions:           []string{"datasources:query"},
+		Resource:          "datasources",
+		ResourceID:        "1",
+		ResourceAttribute: "uid",
+	}
+
+	users := map[string]int64{}
+	for _, login := range []string{"a", "b", "c"} {
+		u, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: login, OrgID: orgID})
+		require.NoError(t, err)
+		users[login] = u.ID
+	}
+
+	// Users are granted the permission in a different order than they were created
+	for _, login := range []string{"c", "a", "b"} {
+		_, err := store.SetUserResourcePermission(context.Background(), orgID, accesscontrol.User{ID: users[login]}, cmd, nil)
+		require.NoError(t, err)
+	}
+	for _, role := range []string{"Viewer", "Editor"} {
+		_, err := store.SetBuiltInResourcePermission(context.Background(), orgID, role, cmd, nil)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		permissions, err := store.GetResourcePermissions(context.Background(), orgID, GetResourcePermissionsQuery{
+			User:              &user.SignedInUser{OrgID: orgID},
+			Actions:           cmd.Actions,
+			Resource:          cmd.Resource,
+			ResourceID:        cmd.ResourceID,
+			ResourceAttribute: cmd.ResourceAttribute,
+		})
+		require.NoError(t, err)
+
+		assignees := make([]string, 0, len(permissions))
+		for _, p := range permissions {
+			if p.UserId != 0 {
+				assignees = append(assignees, p.UserLogin)
+			} else {
+				assignees = append(assignees, p.BuiltInRole)
+			}
+		}
+		assert.Equal(t, []string{"c", "a", "b", "Viewer", "Editor"}, assignees)
+	}
+
+	t.Run("should page through the permissions in order", func(t *testing.T) {
+		var ids []int64
+		for offset := 0; offset < 6; offset += 2 {
+			page, err := store.GetResourcePermissionsPage(context.Background(), orgID, GetResourcePermissionsQuery{
+				User:              &user.SignedInUser{OrgID: orgID},
+				Actions:           cmd.Actions,
+				Resource:          cmd.Resource,
+				ResourceID:        cmd.ResourceID,
+				ResourceAttribute: cmd.ResourceAttribute,
+				Limit:             2,
+				Offset:            offset,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), page.TotalCount)
+			assert.LessOrEqual(t, len(page.Permissions), 2)
+			for _, p := range page.Permissions {
+				ids = append(ids, p.ID)
+			}
+		}
+
+		all, err := store.GetResourcePermissions(context.Background(), orgID, GetResourcePermissionsQuery{
+			User:              &user.SignedInUser{OrgID: orgID},
+			Actions:           cmd.Actions,
+			Resource:          cmd.Resource,
+			ResourceID:        cmd.ResourceID,
+			ResourceAttribute: cmd.ResourceAttribute,
+		})
+		require.NoError(t, err)
+		expected := make([]int64, 0, len(all))
+		for _, p := range all {
+			expected = append(expected, p.ID)
+		}
+		assert.Equal(t, expected, ids)
+	})
+
+	t.Run("should filter the permissions before counting them", func(t *testing.T) {
+		for _, tc := range []struct {
+			grantingOneOf [][]string
+			expected      int64
+		}{
+			{grantingOneOf: [][]string{cmd.Actions}, expected: 5},
+			{grantingOneOf: [][]string{{"unknown:read"}}, expected: 0},
+		} {
+			page, err := store.GetResourcePermissionsPage(context.Background(), orgID, GetResourcePermissionsQuery{
+				User:              &user.SignedInUser{OrgID: orgID},
+				Actions:           cmd.Actions,
+				Resource:          cmd.Resource,
+				ResourceID:        cmd.ResourceID,
+				ResourceAttribute: cmd.ResourceAttribute,
+				GrantingOneOf:     tc.grantingOneOf,
+				Limit:             2,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, page.TotalCount)
+			assert.Len(t, page.Permissions, int(min(tc.expected, 2)))
+		}
+	})
+
+	t.Run("should fail for a negative limit", func(t *testing.T) {
+		_, err := store.GetResourcePermissionsPage(context.Background(), orgID, GetResourcePermissionsQuery{
+			Resource: cmd.Resource, ResourceID: cmd.ResourceID, ResourceAttribute: cmd.ResourceAttribute, Limit: -1,
+		})
+		require.ErrorIs(t, err, ErrInvalidParam)
+	})
+}
+
+func TestIntegrationStore_GetResourcePermissionsMySQLCollation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	if !db.IsTestDbMySQL() {
+		t.Skip("skipping MySQL only test")
+	}
+
+	store, sql, cfg := setupTestEnv(t)
+	orgService, err := orgimpl.ProvideService(sql, cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+	usrSvc, err := userimpl.ProvideService(
+		sql, orgService, cfg, nil, nil, tracing.InitializeTracerForTest(),
+		quotatest.New(false, nil), supportbundlestest.NewFakeBundleService(),
+	)
+	require.NoError(t, err)
+	orgID, err := orgService.GetOrCreate(context.Background(), "test")
+	require.NoError(t, err)
+
+	cmd := SetResourcePermissionCommand{
+		Actions:           []string{"datasources:query"},
+		Resource:          "datasources",
+		ResourceID:        "1",
+		ResourceAttribute: "uid",
+	}
+
+	usr, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "Ünïcode", OrgID: orgID})
+	require.NoError(t, err)
+	_, err = store.SetUserResourcePermission(context.Background(), orgID, accesscontrol.User{ID: usr.ID}, cmd, nil)
+	require.NoError(t, err)
+
+	tm := &team.Team{OrgID: orgID, UID: "collation", Name: "Tëam", Email: "team@example.org", Created: time.Now(), Updated: time.Now()}
+	err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Insert(tm)
+		return err
+	})
+	require.NoError(t, err)
+	_, err = store.SetTeamResourcePermission(context.Background(), orgID, tm.ID, cmd, nil)
+	require.NoError(t, err)
+
+	_, err = store.SetBuiltInResourcePermission(context.Background(), orgID, "Viewer", cmd, nil)
+	require.NoError(t, err)
+
+	_, err = store.SetGroupResourcePermission(context.Background(), orgID, "cn=Grüppe", cmd, nil)
+	require.NoError(t, err)
+
+	query := GetResourcePermissionsQuery{
+		User:              &user.SignedInUser{OrgID: orgID},
+		Actions:           cmd.Actions,
+		Resource:          cmd.Resource,
+		ResourceID:        cmd.ResourceID,
+		ResourceAttribute: cmd.ResourceAttribute,
+	}
+	branches, err := store.resourcePermissionsBranches(orgID, query)
+	require.NoError(t, err)
+
+	for _, collation := range []string{"utf8mb4_general_ci", "utf8mb4_bin"} {
+		t.Run("should combine the branches when the connection uses "+collation, func(t *testing.T) {
+			var permissions []accesscontrol.ResourcePermission
+			// The literals of the query get the collation of the connection, the transaction keeps the session on a
+			// single connection
+			err := sql.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+				if _, err := sess.Exec("SET SESSION collation_connection = '" + collation + "'"); err != nil {
+					return err
+				}
+				defer func() {
+					_, _ = sess.Exec("SET SESSION collation_connection = @@GLOBAL.collation_connection")
+				}()
+
+				var err error
+				permissions, err = store.getResourcePermissions(context.Background(), sess, query, branches)
+				return err
+			})
+			require.NoError(t, err)
+
+			require.Len(t, permissions, 4)
+			assert.Equal(t, "Ünïcode", permissions[0].UserLogin)
+			assert.Equal(t, "Tëam", permissions[1].Team)
+			assert.Equal(t, "Viewer", permissions[2].BuiltInRole)
+			assert.Equal(t, "cn=Grüppe", permissions[3].GroupID)
+		})
+	}
+}
+
+func TestIntegrationStore_GetResourcePermissionsNestedFolders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store, sql, _ := setupTestEnv(t)
+	orgID := int64(1)
+
+	// a > b > c, only the direct parent of the dashboard is passed as inherited scope
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		for _, f := range []struct{ uid, parent string }{{"a", ""}, {"b", "a"}, {"c", "b"}} {
+			var parent any
+			if f.parent != "" {
+				parent = f.parent
+			}
+			if _, err := sess.Exec("INSERT INTO folder (org_id, uid, parent_uid, title, created, updated) VALUES (?, ?, ?, ?, ?, ?)",
+				orgID, f.uid, parent, f.uid, time.Now(), time.Now()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = store.SetBuiltInResourcePermission(context.Background(), orgID, "Viewer", SetResourcePermissionCommand{
+		Actions:           []string{"dashboards:read"},
+		Resource:          "folders",
+		ResourceID:        "a",
+		ResourceAttribute: "uid",
+	}, nil)
+	require.NoError(t, err)
+
+	permissions, err := store.GetResourcePermissions(context.Background(), orgID, GetResourcePermissionsQuery{
+		User:              &user.SignedInUser{OrgID: orgID},
+		Actions:           []string{"dashboards:read"},
+		Resource:          "dashboards",
+		ResourceID:        "d1",
+		ResourceAttribute: "uid",
+		InheritedScopes:   []string{"folders:uid:c"},
+	})
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "Viewer", permissions[0].BuiltInRole)
+	assert.Equal(t, "folders:uid:a", permissions[0].Scope)
+}
+
 func seedResourcePermissions(
 	t *testing.T, store *store, sql db.DB, cfg *setting.Cfg, orgService org.Service,
 	actions []string, resource, resourceID, resourceAttribute string, numUsers, numServiceAccounts int,
@@ -621,6 +918,201 @@ func TestStore_IsInherited(t *testing.T) {
 	}
 }
 
+func TestFlatPermissionsToResourcePermissions(t *testing.T) {
+	scope := "folders:uid:f1"
+	managed := accesscontrol.ManagedRolePrefix + "role"
+	rows := []flatResourcePermission{
+		{RoleName: "basic:viewer", BuiltInRole: "Viewer", Scope: "folders:*", Action: "folders:read"},
+		{RoleName: managed, TeamId: 3, Scope: scope, Action: "folders:read"},
+		{RoleName: managed, UserId: 2, Scope: scope, Action: "folders:read"},
+		{RoleName: managed, UserId: 1, Scope: "folders:uid:parent", Action: "folders:read"},
+		{RoleName: managed, UserId: 1, Scope: scope, Action: "folders:read"},
+		{RoleName: managed, UserId: 2, Scope: scope, Action: "folders:write"},
+		{RoleName: managed, UserId: 1, Scope: scope, Action: "folders:write"},
+		{RoleName: managed, Scope: scope, Action: "folders:read"},
+	}
+
+	result := flatPermissionsToResourcePermissions(scope, rows)
+	require.Len(t, result, 5)
+
+	// Users first, in the order of their first permission, managed permissions before inherited ones
+	assert.Equal(t, int64(2), result[0].UserId)
+	assert.Equal(t, []string{"folders:read", "folders:write"}, result[0].Actions)
+	assert.True(t, result[0].IsManaged)
+	assert.Equal(t, int64(1), result[1].UserId)
+	assert.Equal(t, []string{"folders:read", "folders:write"}, result[1].Actions)
+	assert.True(t, result[1].IsManaged)
+	assert.Equal(t, int64(1), result[2].UserId)
+	assert.Equal(t, []string{"folders:read"}, result[2].Actions)
+	assert.True(t, result[2].IsInherited)
+	assert.Equal(t, int64(3), result[3].TeamId)
+	assert.Equal(t, "Viewer", result[4].BuiltInRole)
+	assert.False(t, result[4].IsManaged)
+
+	// The actions of a permission can be extended without changing the next permission
+	result[0].Actions = append(result[0].Actions, "folders:delete")
+	assert.Equal(t, []string{"folders:read", "folders:write"}, result[1].Actions)
+}
+
+func TestIntegrationStore_RoleAdders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store, sql, _ := setupTestEnv(t)
+
+	adders := map[string]func(sess *db.Session) roleAdder{
+		"user_role":    func(sess *db.Session) roleAdder { return store.userAdder(sess, 1, 1) },
+		"team_role":    func(sess *db.Session) roleAdder { return store.teamAdder(sess, 1, 1) },
+		"builtin_role": func(sess *db.Session) roleAdder { return store.builtInRoleAdder(sess, 1, "Viewer") },
+		"group_role":   func(sess *db.Session) roleAdder { return store.groupAdder(sess, 1, "group") },
+	}
+
+	for table, adder := range adders {
+		t.Run("should add the role once to "+table, func(t *testing.T) {
+			err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+				for i := 0; i < 2; i++ {
+					if err := adder(sess)(1); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			count, err := countRows(sql, table)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+		})
+	}
+}
+
+func TestIntegrationStore_BulkPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	for _, commitPerChunk := range []bool{false, true} {
+		t.Run(fmt.Sprintf("should write and delete more permissions than the batch size with commit per chunk %v", commitPerChunk), func(t *testing.T) {
+			store, sql, cfg := setupTestEnv(t)
+			cfg.RBAC.SQLiteCommitPerChunk = commitPerChunk
+
+			// More actions than fit in three batches of the dialect
+			actions := make([]string, 0, 3*sql.GetDialect().BatchSize()+1)
+			for i := 0; i < cap(actions); i++ {
+				actions = append(actions, fmt.Sprintf("dashboards:action%d", i))
+			}
+
+			permission, err := store.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: 1}, SetResourcePermissionCommand{
+				Actions:           actions,
+				Resource:          "dashboards",
+				ResourceID:        "1",
+				ResourceAttribute: "uid",
+			}, nil)
+			require.NoError(t, err)
+			assert.Len(t, permission.Actions, len(actions))
+
+			err = store.DeleteResourcePermissions(context.Background(), 1, &DeleteResourcePermissionsCmd{
+				Resource:          "dashboards",
+				ResourceAttribute: "uid",
+				ResourceID:        "1",
+			})
+			require.NoError(t, err)
+
+			count, err := countRows(sql, "permission")
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), count)
+		})
+	}
+}
+
+func TestIntegrationStore_RenameResourcePermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store, sql, _ := setupTestEnv(t)
+	ctx := context.Background()
+
+	set := func(orgID, userID int64, resourceID string, actions ...string) {
+		_, err := store.SetUserResourcePermission(ctx, orgID, accesscontrol.User{ID: userID}, SetResourcePermissionCommand{
+			Actions:           actions,
+			Resource:          "datasources",
+			ResourceID:        resourceID,
+			ResourceAttribute: "uid",
+		}, nil)
+		require.NoError(t, err)
+	}
+	set(1, 1, "old", "datasources:query", "datasources:write")
+	set(1, 2, "old", "datasources:query")
+	// user 1 was already granted query on the new uid, the grant on the old uid is dropped
+	set(1, 1, "new", "datasources:query")
+	set(2, 1, "old", "datasources:query")
+
+	// The tuples of these assignees are rewritten through the outbox when zanzana is enabled
+	err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+		assignments, err := renamedAssignments(sess, 1, "datasources:uid:old")
+		assert.ElementsMatch(t, []accesscontrol.ResourceAssignment{{UserID: 1}, {UserID: 2}}, assignments)
+		return err
+	})
+	require.NoError(t, err)
+
+	err = store.RenameResourcePermissions(ctx, 1, &RenameResourcePermissionsCmd{
+		Resource:          "datasources",
+		ResourceAttribute: "uid",
+		OldResourceID:     "old",
+		NewResourceID:     "new",
+	})
+	require.NoError(t, err)
+
+	var permissions []orgPermission
+	err = sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(
+			"SELECT role.org_id, permission.action, permission.scope FROM permission INNER JOIN role ON role.id = permission.role_id WHERE permission.identifier IN (?, ?)",
+			"old", "new",
+		).Find(&permissions)
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []orgPermission{
+		{OrgID: 1, Action: "datasources:query", Scope: "datasources:uid:new"},
+		{OrgID: 1, Action: "datasources:write", Scope: "datasources:uid:new"},
+		{OrgID: 1, Action: "datasources:query", Scope: "datasources:uid:new"},
+		{OrgID: 2, Action: "datasources:query", Scope: "datasources:uid:old"},
+	}, permissions)
+}
+
+func TestIntegrationStore_InTransactionOfCaller(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store, sql, _ := setupTestEnv(t)
+	insertRole := func(sess *db.Session) error {
+		_, err := sess.Insert(&accesscontrol.Role{OrgID: 1, Name: "managed:conflict", UID: util.GenerateShortUID(), Created: time.Now(), Updated: time.Now()})
+		return err
+	}
+	require.NoError(t, sql.WithDbSession(context.Background(), insertRole))
+
+	var calls int
+	err := sql.InTransaction(context.Background(), func(ctx context.Context) error {
+		return store.inTransaction(ctx, func(sess *db.Session) error {
+			calls++
+			return insertRole(sess)
+		})
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls, "the transaction of the caller can't be retried")
+}
+
+func countRows(sql db.DB, table string) (int64, error) {
+	var count int64
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		var err error
+		count, err = sess.Table(table).Count()
+		return err
+	})
+	return count, err
+}
+
 type orgPermission struct {
 	OrgID  int64  `xorm:"org_id"`
 	Action string `json:"action"`
@@ -930,3 +1422,131 @@ func TestStore_ExpandActions(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_ExpandActionSetsInResourcePermissions(t *testing.T) {
+	actionSetService := NewActionSetService(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSetService.StoreActionSet("dashboards:view", []string{"dashboards:read"})
+	actionSetService.StoreActionSet("dashboards:edit", []string{"dashboards:read", "dashboards:write"})
+
+	s := &store{actionSets: actionSetService}
+	permissions := []accesscontrol.ResourcePermission{
+		{Actions: []string{"dashboards:edit", "dashboards:read"}},
+		{Actions: []string{"dashboards:read"}},
+	}
+
+	s.expandActionSets(permissions)
+
+	assert.Equal(t, map[string][]string{"dashboards:edit": {"dashboards:read", "dashboards:write"}}, permissions[0].ActionSets)
+	assert.Nil(t, permissions[1].ActionSets)
+}
+
+func TestStore_FilterByPermission(t *testing.T) {
+	actionSetService := NewActionSetService(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSetService.StoreActionSet("dashboards:view", []string{"dashboards:read"})
+	actionSetService.StoreActionSet("dashboards:edit", []string{"dashboards:read", "dashboards:write"})
+	actionSetService.StoreActionSet("dashboards:admin", []string{"dashboards:read", "dashboards:write", "dashboards.permissions:write"})
+
+	s := &store{actionSets: actionSetService}
+	permissions := []accesscontrol.ResourcePermission{
+		{UserId: 1, Actions: []string{"dashboards:view", "dashboards:read"}},
+		{UserId: 2, Actions: []string{"dashboards:edit"}},
+		{UserId: 3, Actions: []string{"dashboards:read", "dashboards:write", "dashboards.permissions:write"}},
+		{UserId: 4, Actions: []string{"dashboards:admin"}, Deny: true},
+	}
+
+	userIDs := func(permissions []accesscontrol.ResourcePermission) []int64 {
+		ids := make([]int64, 0, len(permissions))
+		for _, p := range permissions {
+			ids = append(ids, p.UserId)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{1, 2, 3}, userIDs(s.filterByPermission(permissions, "dashboards", "View")))
+	assert.Equal(t, []int64{2, 3}, userIDs(s.filterByPermission(permissions, "dashboards", "Edit")))
+	assert.Equal(t, []int64{3}, userIDs(s.filterByPermission(permissions, "dashboards", "Admin")))
+
+	// Resources without action sets are not filtered
+	assert.Equal(t, []int64{1, 2, 3, 4}, userIDs(s.filterByPermission(permissions, "teams", "Admin")))
+}
+
+func TestStore_NestedActionSets(t *testing.T) {
+	actionSets := NewInMemoryActionSetStore(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSets.StoreActionSet("folders:admin", []string{"folders:edit", "folders.permissions:read", "folders.permissions:write"})
+	actionSets.StoreActionSet("folders:edit", []string{"folders:view", "folders:write"})
+	actionSets.StoreActionSet("folders:view", []string{"folders:read", "dashboards:read"})
+
+	t.Run("should resolve included action sets transitively", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"folders:read", "dashboards:read", "folders:write", "folders.permissions:read", "folders.permissions:write"}, actionSets.ResolveActionSet("folders:admin"))
+		assert.ElementsMatch(t, []string{"folders:read", "dashboards:read", "folders:write"}, actionSets.ResolveActionSet("folders:edit"))
+	})
+
+	t.Run("should resolve the action sets including an action transitively", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"folders:view", "folders:edit", "folders:admin"}, actionSets.ResolveAction("dashboards:read"))
+		assert.ElementsMatch(t, []string{"folders:edit", "folders:admin"}, actionSets.ResolveAction("folders:write"))
+		assert.ElementsMatch(t, []string{"folders:edit", "folders:admin"}, actionSets.ResolveActionPrefix("folders:w"))
+	})
+
+	t.Run("should expand included action sets", func(t *testing.T) {
+		expanded := actionSets.ExpandActionSetsWithFilter([]accesscontrol.Permission{{Action: "folders:edit", Scope: "folders:uid:1"}}, func(string) bool { return true })
+		assert.ElementsMatch(t, []accesscontrol.Permission{
+			{Action: "folders:read", Scope: "folders:uid:1"},
+			{Action: "dashboards:read", Scope: "folders:uid:1"},
+			{Action: "folders:write", Scope: "folders:uid:1"},
+		}, expanded)
+	})
+
+	t.Run("should ignore action sets creating a cycle", func(t *testing.T) {
+		actionSets.StoreActionSet("folders:view", []string{"folders:admin", "folders:list"})
+		assert.ElementsMatch(t, []string{"folders:read", "dashboards:read", "folders:list"}, actionSets.ResolveActionSet("folders:view"))
+	})
+
+	t.Run("should resolve an action sharing the name of its action set", func(t *testing.T) {
+		actionSets.StoreActionSet("datasources:query", []string{"datasources:query", "datasources:read"})
+		actionSets.StoreActionSet("datasources:edit", []string{"datasources:query", "datasources:write"})
+		assert.ElementsMatch(t, []string{"datasources:query", "datasources:read", "datasources:write"}, actionSets.ResolveActionSet("datasources:edit"))
+	})
+}
+
+func TestStore_ConcurrentActionSets(t *testing.T) {
+	actionSets := NewInMemoryActionSetStore(featuremgmt.WithFeatures(featuremgmt.FlagAccessActionSets))
+	actionSets.StoreActionSet("folders:view", []string{"folders:read"})
+
+	t.Run("should read action sets while they are stored", func(t *testing.T) {
+		const writers = 10
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				actionSets.StoreActionSet("folders:view", []string{fmt.Sprintf("plugin-%d.folders:read", i)})
+				actionSets.StoreActionSet(fmt.Sprintf("plugin-%d:view", i), []string{"folders:view"})
+			}(i)
+			go func() {
+				defer wg.Done()
+				assert.Contains(t, actionSets.ResolveActionSet("folders:view"), "folders:read")
+				assert.Contains(t, actionSets.ResolveAction("folders:read"), "folders:view")
+				actionSets.ResolveActionPrefix("plugin-")
+				actionSets.ActionSets()
+				actionSets.ExpandActionSetsWithFilter([]accesscontrol.Permission{{Action: "folders:view", Scope: "folders:uid:1"}}, func(string) bool { return true })
+			}()
+		}
+		wg.Wait()
+
+		actions := actionSets.ResolveActionSet("folders:view")
+		assert.Len(t, actions, writers+1)
+		for i := 0; i < writers; i++ {
+			assert.Contains(t, actions, fmt.Sprintf("plugin-%d.folders:read", i))
+			assert.ElementsMatch(t, actions, actionSets.ResolveActionSet(fmt.Sprintf("plugin-%d:view", i)))
+		}
+	})
+
+	t.Run("should not change the actions already returned when storing an action set", func(t *testing.T) {
+		actionSets.StoreActionSet("dashboards:view", []string{"dashboards:read"})
+		before := actionSets.ResolveAction("dashboards:read")
+		actionSets.StoreActionSet("dashboards:edit", []string{"dashboards:read", "dashboards:write"})
+
+		assert.Equal(t, []string{"dashboards:view"}, before)
+		assert.ElementsMatch(t, []string{"dashboards:view", "dashboards:edit"}, actionSets.ResolveAction("dashboards:read"))
+	})
+}
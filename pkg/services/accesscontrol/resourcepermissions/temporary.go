@@ -0,0 +1,97 @@
+package resourcepermissions
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// GrantTemporaryUserPermission sets the permission for the user on the resource and schedules its revocation after duration.
+// When the grant expires the user loses the permission, any permission previously set for the user on the resource is not restored.
+func (s *Service) GrantTemporaryUserPermission(
+	ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string,
+	duration time.Duration, grantedBy int64,
+) (*accesscontrol.TemporaryPermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GrantTemporaryUserPermission")
+	defer span.End()
+
+	if permission == "" || permission == DenyPermission {
+		return nil, ErrInvalidPermission.Build(ErrInvalidPermissionData(permission))
+	}
+
+	if duration <= 0 || (s.cfg != nil && s.cfg.RBAC.TemporaryPermissionMaxDuration > 0 && duration > s.cfg.RBAC.TemporaryPermissionMaxDuration) {
+		return nil, ErrInvalidParam.Build(ErrInvalidParamData("duration", nil))
+	}
+
+	now := time.Now()
+	grant := &accesscontrol.TemporaryPermission{
+		OrgID:             orgID,
+		Resource:          s.options.Resource,
+		ResourceAttribute: s.options.ResourceAttribute,
+		ResourceID:        resourceID,
+		UserID:            user.ID,
+		Permission:        permission,
+		GrantedBy:         grantedBy,
+		Expires:           now.Add(duration),
+		Created:           now,
+	}
+
+	// The grant is recorded in the transaction setting the permission, a permission without its grant would never
+	// be revoked
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.SetUserPermission(ctx, orgID, user, resourceID, permission); err != nil {
+			return err
+		}
+		return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Insert(grant)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The permission is recorded in the permission audit log by the store, its removal once the grant expires too
+	s.log.Debug("Granted temporary permission", "orgID", orgID, "userID", user.ID, "scope", grant.Scope(),
+		"permission", permission, "grantedBy", grantedBy, "expires", grant.Expires)
+
+	return grant, nil
+}
+
+func (s *Service) deleteTemporaryPermission(ctx context.Context, orgID int64, resourceID string, userID int64) error {
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(
+			"DELETE FROM temporary_permission WHERE org_id = ? AND resource = ? AND resource_id = ? AND user_id = ?",
+			orgID, s.options.Resource, resourceID, userID,
+		)
+		return err
+	})
+}
+
+// setTemporaryPermissionExpiry sets the expiry of the managed user permissions granted temporarily.
+func (s *Service) setTemporaryPermissionExpiry(ctx context.Context, orgID int64, resourceID string, permissions []accesscontrol.ResourcePermission) error {
+	var grants []accesscontrol.TemporaryPermission
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND resource = ? AND resource_id = ?", orgID, s.options.Resource, resourceID).Find(&grants)
+	})
+	if err != nil || len(grants) == 0 {
+		return err
+	}
+
+	expires := make(map[int64]time.Time, len(grants))
+	for _, g := range grants {
+		expires[g.UserID] = g.Expires
+	}
+
+	for i, p := range permissions {
+		if !p.IsManaged || p.IsInherited || p.UserId == 0 {
+			continue
+		}
+		if e, ok := expires[p.UserId]; ok {
+			permissions[i].Expires = e
+		}
+	}
+	return nil
+}
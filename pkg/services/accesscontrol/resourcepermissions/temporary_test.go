@@ -0,0 +1,85 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_GrantTemporaryUserPermission(t *testing.T) {
+	ctx := context.Background()
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:             "dashboards",
+		ResourceAttribute:    "uid",
+		Assignments:          Assignments{Users: true},
+		PermissionsToActions: map[string][]string{"Admin": {"dashboards:read", "dashboards:write"}},
+	})
+	service.cfg.RBAC.TemporaryPermissionMaxDuration = 2 * time.Hour
+
+	usr, err := usrSvc.Create(ctx, &user.CreateUserCommand{Login: "oncall", OrgID: 1})
+	require.NoError(t, err)
+
+	reader := &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {accesscontrol.ActionOrgUsersRead: {accesscontrol.ScopeUsersAll}},
+		},
+	}
+	store := database.ProvideService(service.sqlStore)
+
+	_, err = service.GrantTemporaryUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "Admin", 3*time.Hour, 1)
+	assert.ErrorIs(t, err, ErrInvalidParam)
+
+	grant, err := service.GrantTemporaryUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "Admin", time.Hour, 1)
+	require.NoError(t, err)
+
+	permissions, err := service.GetPermissions(ctx, reader, "dash")
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.WithinDuration(t, grant.Expires, permissions[0].Expires, time.Second)
+
+	t.Run("should not revoke grants that have not expired", func(t *testing.T) {
+		revoked, err := store.RevokeExpiredTemporaryPermissions(ctx, time.Now(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, revoked)
+	})
+
+	t.Run("should revoke the permission once the grant expires", func(t *testing.T) {
+		var onRevoke []accesscontrol.TemporaryPermission
+		revoked, err := store.RevokeExpiredTemporaryPermissions(ctx, grant.Expires, func(ctx context.Context, revoked []accesscontrol.TemporaryPermission) error {
+			onRevoke = revoked
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, revoked, 1)
+		assert.Equal(t, usr.ID, revoked[0].UserID)
+		assert.Equal(t, revoked, onRevoke)
+
+		permissions, err := service.GetPermissions(ctx, reader, "dash")
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+	})
+
+	t.Run("should keep the permission when it is explicitly set before the grant expires", func(t *testing.T) {
+		grant, err := service.GrantTemporaryUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "Admin", time.Hour, 1)
+		require.NoError(t, err)
+		_, err = service.SetUserPermission(ctx, 1, accesscontrol.User{ID: usr.ID}, "dash", "Admin")
+		require.NoError(t, err)
+
+		revoked, err := store.RevokeExpiredTemporaryPermissions(ctx, grant.Expires, nil)
+		require.NoError(t, err)
+		assert.Empty(t, revoked)
+
+		permissions, err := service.GetPermissions(ctx, reader, "dash")
+		require.NoError(t, err)
+		require.Len(t, permissions, 1)
+		assert.True(t, permissions[0].Expires.IsZero())
+	})
+}
@@ -293,6 +293,17 @@ var (
 		},
 	}
 
+	rolesSnapshotWriterRole = RoleDTO{
+		Name:        "fixed:roles.snapshot:writer",
+		DisplayName: "Access control snapshot writer",
+		Description: "Export and restore the roles, permissions and assignments of an organization.",
+		Group:       "Access control",
+		Permissions: []Permission{
+			{Action: ActionRolesSnapshotRead},
+			{Action: ActionRolesSnapshotWrite},
+		},
+	}
+
 	usagestatsReaderRole = RoleDTO{
 		Name:        "fixed:usagestats:reader",
 		DisplayName: "Usage stats report reader",
@@ -348,6 +359,11 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 		Grants: []string{RoleGrafanaAdmin},
 	}
 
+	rolesSnapshotWriter := RoleRegistration{
+		Role:   rolesSnapshotWriterRole,
+		Grants: []string{RoleGrafanaAdmin, string(org.RoleAdmin)},
+	}
+
 	usageStatsReader := RoleRegistration{
 		Role:   usagestatsReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
@@ -357,6 +373,7 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 		ldapReader, ldapWriter, orgUsersReader, orgUsersWriter,
 		settingsReader, statsReader, usersReader, usersWriter,
 		authenticationConfigWriter, generalAuthConfigWriter, usageStatsReader,
+		rolesSnapshotWriter,
 	)
 }
 
@@ -385,6 +402,11 @@ func PrefixedRoleUID(roleName string) string {
 	return fmt.Sprintf("%s%s", prefix, base64.RawURLEncoding.EncodeToString(hasher.Sum(nil)))
 }
 
+// ExternalServiceRoleUID returns the uid of the global role holding the permissions of an external service.
+func ExternalServiceRoleUID(externalServiceID string) string {
+	return PrefixedRoleUID(fmt.Sprintf("%s%s:permissions", ExternalServiceRolePrefix, externalServiceID))
+}
+
 // ValidateFixedRole errors when a fixed role does not match expected pattern
 func ValidateFixedRole(role RoleDTO) error {
 	if !strings.HasPrefix(role.Name, FixedRolePrefix) {
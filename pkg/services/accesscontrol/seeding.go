@@ -0,0 +1,96 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+)
+
+// BasicRoleSeeder is implemented by stores persisting the roles granted to the basic roles, each basic role has a
+// version of its grants so the startup only applies the grants of the basic roles that changed.
+type BasicRoleSeeder interface {
+	// SeedBasicRoleGrants stores the grants of the basic roles whose version changed since they were last seeded.
+	SeedBasicRoleGrants(ctx context.Context, grants map[string]BasicRoleGrants) (*SeedReport, error)
+	// GetBasicRoleOverrides returns the permissions of the basic roles stored in the orgs that differ from defaults.
+	GetBasicRoleOverrides(ctx context.Context, defaults map[string][]Permission) ([]BasicRoleOverride, error)
+}
+
+// BasicRoleGrants are the roles granted to a basic role by default.
+type BasicRoleGrants struct {
+	// Version identifies the roles and their permissions, it changes whenever one of them changes
+	Version string
+	// Roles are the sorted names of the granted roles
+	Roles []string
+}
+
+// NewBasicRoleGrants returns the grants of the roles.
+func NewBasicRoleGrants(roles []RoleDTO) BasicRoleGrants {
+	roles = slices.Clone(roles)
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	hash := sha256.New()
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		if len(names) > 0 && names[len(names)-1] == r.Name {
+			continue
+		}
+		names = append(names, r.Name)
+
+		permissions := make([]string, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			permissions = append(permissions, p.Action+"\x00"+p.Scope)
+		}
+		sort.Strings(permissions)
+
+		_, _ = hash.Write([]byte(r.Name + "\n"))
+		for _, p := range permissions {
+			_, _ = hash.Write([]byte(p + "\n"))
+		}
+	}
+	return BasicRoleGrants{Version: hex.EncodeToString(hash.Sum(nil)), Roles: names}
+}
+
+// FixedRolesVersion returns the version of the registrations, it changes whenever a role, its permissions or the
+// basic roles it is granted to change.
+func FixedRolesVersion(registrations []RoleRegistration) string {
+	roles := make([]RoleDTO, 0, len(registrations))
+	grants := make([]string, 0, len(registrations))
+	for _, r := range registrations {
+		roles = append(roles, r.Role)
+		granted := slices.Clone(r.Grants)
+		sort.Strings(granted)
+		for _, br := range granted {
+			grants = append(grants, r.Role.Name+"\x00"+br)
+		}
+	}
+	sort.Strings(grants)
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(NewBasicRoleGrants(roles).Version + "\n"))
+	for _, g := range grants {
+		_, _ = hash.Write([]byte(g + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SeedReport describes the grants applied by SeedBasicRoleGrants.
+type SeedReport struct {
+	// Unchanged are the basic roles whose version didn't change, their grants were not read
+	Unchanged []string `json:"unchanged"`
+	// Added are the roles granted to each basic role since it was last seeded
+	Added map[string][]string `json:"added"`
+	// Removed are the roles no longer granted to each basic role since it was last seeded
+	Removed map[string][]string `json:"removed"`
+}
+
+// BasicRoleOverride are the permissions of a basic role of an org that differ from the defaults of the basic role.
+type BasicRoleOverride struct {
+	OrgID     int64  `json:"orgId"`
+	BasicRole string `json:"basicRole"`
+	// Added are the permissions of the org that are not part of the defaults
+	Added []Permission `json:"added"`
+	// Removed are the permissions of the defaults that the org doesn't have
+	Removed []Permission `json:"removed"`
+}
@@ -0,0 +1,37 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedRolesVersion(t *testing.T) {
+	reader := RoleRegistration{
+		Role:   RoleDTO{Name: "fixed:dashboards:reader", Permissions: []Permission{{Action: "dashboards:read", Scope: "dashboards:*"}}},
+		Grants: []string{"Viewer", "Editor"},
+	}
+	writer := RoleRegistration{
+		Role:   RoleDTO{Name: "fixed:dashboards:writer", Permissions: []Permission{{Action: "dashboards:write", Scope: "dashboards:*"}}},
+		Grants: []string{"Editor"},
+	}
+	version := FixedRolesVersion([]RoleRegistration{reader, writer})
+
+	t.Run("should not depend on the order of the registrations and grants", func(t *testing.T) {
+		reordered := reader
+		reordered.Grants = []string{"Editor", "Viewer"}
+		assert.Equal(t, version, FixedRolesVersion([]RoleRegistration{writer, reordered}))
+	})
+
+	t.Run("should change when a grant changes", func(t *testing.T) {
+		changed := writer
+		changed.Grants = []string{"Editor", "Admin"}
+		assert.NotEqual(t, version, FixedRolesVersion([]RoleRegistration{reader, changed}))
+	})
+
+	t.Run("should change when a permission changes", func(t *testing.T) {
+		changed := writer
+		changed.Role.Permissions = []Permission{{Action: "dashboards:delete", Scope: "dashboards:*"}}
+		assert.NotEqual(t, version, FixedRolesVersion([]RoleRegistration{reader, changed}))
+	})
+}
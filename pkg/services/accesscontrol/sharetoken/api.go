@@ -0,0 +1,166 @@
+package sharetoken
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type api struct {
+	service *Service
+	ac      accesscontrol.AccessControl
+}
+
+func newAPI(service *Service, ac accesscontrol.AccessControl) *api {
+	return &api{service: service, ac: ac}
+}
+
+func (a *api) registerEndpoints(router routing.RouteRegister) {
+	router.Group("/api/access-control/share-tokens", func(rr routing.RouteRegister) {
+		rr.Get("/", middleware.ReqSignedIn, routing.Wrap(a.listTokens))
+		rr.Post("/", middleware.ReqSignedIn, routing.Wrap(a.issueToken))
+		rr.Post("/revoke", middleware.ReqSignedIn, routing.Wrap(a.revokeToken))
+		rr.Delete("/:id", middleware.ReqSignedIn, routing.Wrap(a.revokeTokenByID))
+	}, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+type issueTokenCommand struct {
+	// Scope is the uid scope of the shared resource, e.g. dashboards:uid:<uid>
+	Scope string `json:"scope"`
+	// ExpiresIn is the duration of the token, e.g. 1h, a day when empty
+	ExpiresIn string `json:"expiresIn"`
+}
+
+type revokeTokenCommand struct {
+	Token string `json:"token"`
+}
+
+type tokenDTO struct {
+	Token   string    `json:"token,omitempty"`
+	ID      string    `json:"id"`
+	Scope   string    `json:"scope"`
+	Expires time.Time `json:"expires"`
+}
+
+// POST /api/access-control/share-tokens
+func (a *api) issueToken(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.sharetoken.issueToken")
+	defer span.End()
+
+	cmd := issueTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	var ttl time.Duration
+	if cmd.ExpiresIn != "" {
+		var err error
+		if ttl, err = time.ParseDuration(cmd.ExpiresIn); err != nil {
+			return response.Error(http.StatusBadRequest, "expiresIn is invalid", err)
+		}
+	}
+
+	if resp := a.authorize(c, cmd.Scope); resp != nil {
+		return resp
+	}
+
+	token, claims, err := a.service.Issue(ctx, c.SignedInUser.GetOrgID(), cmd.Scope, ttl)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not issue share token", err)
+	}
+
+	return response.JSON(http.StatusOK, tokenDTO{Token: token, ID: claims.ID, Scope: claims.Scope, Expires: claims.Expires})
+}
+
+// POST /api/access-control/share-tokens/revoke
+func (a *api) revokeToken(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.sharetoken.revokeToken")
+	defer span.End()
+
+	cmd := revokeTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	claims, err := a.service.parse(cmd.Token)
+	if err != nil {
+		return response.Err(err)
+	}
+	if claims.OrgID != c.SignedInUser.GetOrgID() {
+		return response.Error(http.StatusForbidden, "share token belongs to another organization", nil)
+	}
+	if resp := a.authorize(c, claims.Scope); resp != nil {
+		return resp
+	}
+
+	if _, err := a.service.Revoke(ctx, cmd.Token); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not revoke share token", err)
+	}
+
+	return response.JSON(http.StatusOK, tokenDTO{ID: claims.ID, Scope: claims.Scope, Expires: claims.Expires})
+}
+
+// GET /api/access-control/share-tokens?scope=<scope>
+func (a *api) listTokens(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.sharetoken.listTokens")
+	defer span.End()
+
+	scope := c.Query("scope")
+	if resp := a.authorize(c, scope); resp != nil {
+		return resp
+	}
+
+	tokens, err := a.service.List(ctx, c.SignedInUser.GetOrgID(), scope)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not list share tokens", err)
+	}
+
+	dtos := make([]tokenDTO, 0, len(tokens))
+	for _, claims := range tokens {
+		dtos = append(dtos, tokenDTO{ID: claims.ID, Scope: claims.Scope, Expires: claims.Expires})
+	}
+	return response.JSON(http.StatusOK, dtos)
+}
+
+// DELETE /api/access-control/share-tokens/:id?scope=<scope>
+func (a *api) revokeTokenByID(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.sharetoken.revokeTokenByID")
+	defer span.End()
+
+	scope := c.Query("scope")
+	if resp := a.authorize(c, scope); resp != nil {
+		return resp
+	}
+
+	claims, err := a.service.RevokeByID(ctx, c.SignedInUser.GetOrgID(), scope, web.Params(c.Req)[":id"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not revoke share token", err)
+	}
+
+	return response.JSON(http.StatusOK, tokenDTO{ID: claims.ID, Scope: claims.Scope, Expires: claims.Expires})
+}
+
+// authorize returns an error response unless the user can manage the permissions of the resource of the scope,
+// sharing a resource with a link is granting permissions on it.
+func (a *api) authorize(c *contextmodel.ReqContext, scope string) response.Response {
+	kind, _, _ := accesscontrol.SplitScope(scope)
+	if kind == "" {
+		return response.Error(http.StatusBadRequest, "scope is invalid", nil)
+	}
+
+	ok, err := a.ac.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(kind+".permissions:write", scope))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "could not check permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "not allowed to share the resource", nil)
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+// Package sharetoken issues signed tokens sharing a resource with anyone holding a link. Each token is backed by a
+// zanzana tuple conditioned on the hash of the token, so the requests carrying a token are authorized by the same
+// checks as the permissions of the users instead of a parallel mechanism.
+package sharetoken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/sharetoken")
+
+// HeaderName is the request header carrying the share token of a request.
+const HeaderName = "X-Grafana-Share-Token"
+
+const (
+	defaultTTL = 24 * time.Hour
+	maxTTL     = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidToken     = errutil.Unauthorized("sharetoken.invalid", errutil.WithPublicMessage("invalid or expired share token"))
+	ErrUnsupportedScope = errutil.BadRequest("sharetoken.unsupportedScope", errutil.WithPublicMessage("scope can't be shared with a token"))
+	ErrInvalidTTL       = errutil.BadRequest("sharetoken.invalidTTL", errutil.WithPublicMessage("share tokens expire within 30 days"))
+	ErrTokenNotFound    = errutil.NotFound("sharetoken.notFound", errutil.WithPublicMessage("share token not found"))
+)
+
+// revokeBatchSize is the number of tokens whose tuples are deleted by a single write, openfga limits the number of
+// tuples of a write to 100
+const revokeBatchSize = 50
+
+// Claims are the signed content of a share token.
+type Claims struct {
+	ID      string    `json:"id"`
+	OrgID   int64     `json:"orgId"`
+	Scope   string    `json:"scope"`
+	Expires time.Time `json:"expires"`
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, router routing.RouteRegister,
+	ac accesscontrol.AccessControl, zclient zanzana.Client) *Service {
+	s := &Service{
+		secret:  []byte(cfg.SecretKey),
+		zclient: zclient,
+		log:     log.New("accesscontrol.sharetoken"),
+	}
+
+	// Share tokens are only granted by zanzana checks, they grant nothing while access is evaluated by rbac
+	if features.IsEnabledGlobally(featuremgmt.FlagZanzana) && cfg.Zanzana.ZanzanaOnlyEvaluation {
+		s.enabled = true
+		newAPI(s, ac).registerEndpoints(router)
+	}
+	return s
+}
+
+var _ accesscontrol.ShareTokenRevoker = &Service{}
+
+// Service issues and verifies share tokens.
+type Service struct {
+	enabled bool
+	secret  []byte
+	zclient zanzana.Client
+	log     log.Logger
+}
+
+// Issue returns a token granting read access to the resource of the scope until it expires. The scope must be the
+// uid scope of a single resource of a kind that can be shared, e.g. dashboards:uid:<uid>. A ttl of 0 uses the
+// default ttl of a day.
+func (s *Service) Issue(ctx context.Context, orgID int64, scope string, ttl time.Duration) (string, *Claims, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.sharetoken.Issue")
+	defer span.End()
+
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	if ttl < 0 || ttl > maxTTL {
+		return "", nil, ErrInvalidTTL.Errorf("ttl %s is not within 30 days", ttl)
+	}
+
+	kind, attribute, identifier := accesscontrol.SplitScope(scope)
+	if attribute != "uid" || identifier == "" || identifier == "*" {
+		return "", nil, ErrUnsupportedScope.Errorf("scope %s does not identify a single resource by uid", scope)
+	}
+
+	claims := &Claims{
+		ID:      util.GenerateShortUID(),
+		OrgID:   orgID,
+		Scope:   scope,
+		Expires: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+	token, err := s.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tuples, ok := zanzana.NewShareTokenTuples(claims.ID, Hash(token), claims.Expires, kind, identifier, orgID)
+	if !ok {
+		return "", nil, ErrUnsupportedScope.Errorf("resources of kind %s can't be shared with a token", kind)
+	}
+	if err := s.zclient.Write(ctx, &openfgav1.WriteRequest{
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+	}); err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// Revoke revokes the token by deleting its tuples, the token can be expired. Tokens not signed by the instance are
+// rejected.
+func (s *Service) Revoke(ctx context.Context, token string) (*Claims, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.sharetoken.Revoke")
+	defer span.End()
+
+	claims, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deleteTuples(ctx, claims.OrgID, claims.Scope, claims.ID); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RevokeByID revokes the token with the id sharing the resource of the scope, it lets the tokens be revoked by the
+// users who don't hold them, e.g. the admins of the resource.
+func (s *Service) RevokeByID(ctx context.Context, orgID int64, scope, id string) (*Claims, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.sharetoken.RevokeByID")
+	defer span.End()
+
+	tokens, err := s.List(ctx, orgID, scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, claims := range tokens {
+		if claims.ID != id {
+			continue
+		}
+		if err := s.deleteTuples(ctx, orgID, scope, id); err != nil {
+			return nil, err
+		}
+		return claims, nil
+	}
+	return nil, ErrTokenNotFound.Errorf("share token %s not found on %s", id, scope)
+}
+
+// RevokeResourceTokens implements accesscontrol.ShareTokenRevoker, it revokes all the tokens sharing the resource of
+// the scope. Scopes of resources that can't be shared are ignored.
+func (s *Service) RevokeResourceTokens(ctx context.Context, orgID int64, scope string) error {
+	ctx, span := tracer.Start(ctx, "accesscontrol.sharetoken.RevokeResourceTokens")
+	defer span.End()
+
+	if !s.enabled {
+		return nil
+	}
+	kind, attribute, identifier := accesscontrol.SplitScope(scope)
+	if attribute != "uid" || !zanzana.SupportsShareTokens(kind) {
+		return nil
+	}
+
+	tokens, err := s.List(ctx, orgID, scope)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(tokens); start += revokeBatchSize {
+		end := min(start+revokeBatchSize, len(tokens))
+		var deletes []*openfgav1.TupleKeyWithoutCondition
+		for _, claims := range tokens[start:end] {
+			tuples, _ := zanzana.NewShareTokenDeletes(claims.ID, kind, identifier, orgID)
+			deletes = append(deletes, tuples...)
+		}
+		if err := s.zclient.Write(ctx, &openfgav1.WriteRequest{
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: deletes},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the claims of the tokens sharing the resource of the scope, including the expired tokens that were not
+// revoked. The tokens themselves are not stored and can't be returned.
+func (s *Service) List(ctx context.Context, orgID int64, scope string) ([]*Claims, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.sharetoken.List")
+	defer span.End()
+
+	kind, attribute, identifier := accesscontrol.SplitScope(scope)
+	if attribute != "uid" || identifier == "" || identifier == "*" {
+		return nil, ErrUnsupportedScope.Errorf("scope %s does not identify a single resource by uid", scope)
+	}
+	object, ok := zanzana.TranslateToObject(kind, identifier, orgID)
+	if !ok || !zanzana.SupportsShareTokens(kind) {
+		return nil, ErrUnsupportedScope.Errorf("resources of kind %s can't be shared with a token", kind)
+	}
+
+	var ids []string
+	err := s.read(ctx, &openfgav1.ReadRequestTupleKey{Relation: zanzana.RelationLinkViewer, Object: object}, func(t *openfgav1.TupleKey) {
+		if id, ok := zanzana.ShareTokenID(t.GetUser(), orgID); ok {
+			ids = append(ids, id)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*Claims, 0, len(ids))
+	for _, id := range ids {
+		claims := &Claims{ID: id, OrgID: orgID, Scope: scope}
+		err := s.read(ctx, &openfgav1.ReadRequestTupleKey{
+			Relation: zanzana.RelationShareTokenHolder,
+			Object:   zanzana.NewShareTokenObject(id, orgID),
+		}, func(t *openfgav1.TupleKey) {
+			if expires, ok := zanzana.ShareTokenExpiry(t); ok {
+				claims.Expires = expires
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, claims)
+	}
+	return tokens, nil
+}
+
+// read calls fn with each tuple matching the key.
+func (s *Service) read(ctx context.Context, key *openfgav1.ReadRequestTupleKey, fn func(t *openfgav1.TupleKey)) error {
+	token := ""
+	for {
+		res, err := s.zclient.Read(ctx, &openfgav1.ReadRequest{TupleKey: key, ContinuationToken: token})
+		if err != nil {
+			return err
+		}
+		for _, t := range res.GetTuples() {
+			fn(t.GetKey())
+		}
+		token = res.GetContinuationToken()
+		if token == "" {
+			return nil
+		}
+	}
+}
+
+// deleteTuples deletes the tuples of the token with the id sharing the resource of the scope.
+func (s *Service) deleteTuples(ctx context.Context, orgID int64, scope, id string) error {
+	kind, _, identifier := accesscontrol.SplitScope(scope)
+	tuples, ok := zanzana.NewShareTokenDeletes(id, kind, identifier, orgID)
+	if !ok {
+		return ErrUnsupportedScope.Errorf("resources of kind %s can't be shared with a token", kind)
+	}
+	return s.zclient.Write(ctx, &openfgav1.WriteRequest{
+		Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: tuples},
+	})
+}
+
+// WithToken returns a context making the access control checks grant the access shared with the token.
+// Revoked tokens are not detected, the checks made with them grant nothing.
+func (s *Service) WithToken(ctx context.Context, token string) (context.Context, *Claims, error) {
+	if !s.enabled {
+		return ctx, nil, ErrInvalidToken.Errorf("share tokens are disabled")
+	}
+
+	claims, err := s.parse(token)
+	if err != nil {
+		return ctx, nil, err
+	}
+	if time.Now().After(claims.Expires) {
+		return ctx, nil, ErrInvalidToken.Errorf("share token %s expired", claims.ID)
+	}
+	return zanzana.WithShareTokenHash(ctx, Hash(token)), claims, nil
+}
+
+// Hash returns the hash of the token, it is the only part of the token stored in zanzana.
+func Hash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// sign returns the token of the claims: <base64 claims>.<base64 signature of the claims>
+func (s *Service) sign(claims *Claims) (string, error) {
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.signature(payload)), nil
+}
+
+// parse returns the claims of the token when it was signed by the instance, the expiry is not checked.
+func (s *Service) parse(token string) (*Claims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken.Errorf("malformed share token")
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, s.signature(payload)) {
+		return nil, ErrInvalidToken.Errorf("invalid share token signature")
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken.Errorf("malformed share token")
+	}
+	var claims Claims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, ErrInvalidToken.Errorf("malformed share token")
+	}
+	return &claims, nil
+}
+
+func (s *Service) signature(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Middleware makes the access control checks of the requests sent with the X-Grafana-Share-Token header grant the
+// access shared with the token, requests with an invalid or expired token are rejected. The requests are still
+// authenticated as usual, the token only grants access to the identity of the request, e.g. an anonymous user.
+// It needs to be after the context handler.
+func (s *Service) Middleware() web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get(HeaderName)
+			reqContext := contexthandler.FromContext(r.Context())
+			if token == "" || reqContext == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, _, err := s.WithToken(r.Context(), token)
+			if err != nil {
+				reqContext.JsonApiErr(http.StatusUnauthorized, "Invalid share token", err)
+				return
+			}
+			// This modifies both r and reqContext.Req since they point to the same value
+			*reqContext.Req = *reqContext.Req.WithContext(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package sharetoken
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+)
+
+type fakeWriteClient struct {
+	zanzana.Client
+	writes  []*openfgav1.TupleKey
+	deletes []*openfgav1.TupleKeyWithoutCondition
+	// stored are the written tuples that were not deleted
+	stored []*openfgav1.TupleKey
+}
+
+func (c *fakeWriteClient) Write(_ context.Context, in *openfgav1.WriteRequest) error {
+	c.writes = append(c.writes, in.GetWrites().GetTupleKeys()...)
+	c.deletes = append(c.deletes, in.GetDeletes().GetTupleKeys()...)
+	c.stored = append(c.stored, in.GetWrites().GetTupleKeys()...)
+	for _, d := range in.GetDeletes().GetTupleKeys() {
+		c.stored = slices.DeleteFunc(c.stored, func(t *openfgav1.TupleKey) bool {
+			return t.GetUser() == d.GetUser() && t.GetRelation() == d.GetRelation() && t.GetObject() == d.GetObject()
+		})
+	}
+	return nil
+}
+
+func (c *fakeWriteClient) Read(_ context.Context, in *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
+	res := &openfgav1.ReadResponse{}
+	for _, t := range c.stored {
+		if t.GetObject() == in.GetTupleKey().GetObject() && t.GetRelation() == in.GetTupleKey().GetRelation() {
+			res.Tuples = append(res.Tuples, &openfgav1.Tuple{Key: t})
+		}
+	}
+	return res, nil
+}
+
+func TestService_Tokens(t *testing.T) {
+	s := &Service{enabled: true, secret: []byte("secret"), zclient: zanzana.NewNoopClient()}
+
+	t.Run("should issue tokens verified by the service", func(t *testing.T) {
+		token, claims, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "dashboards:uid:dash", claims.Scope)
+		assert.Equal(t, int64(1), claims.OrgID)
+
+		_, verified, err := s.WithToken(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, claims, verified)
+	})
+
+	t.Run("should reject tokens signed with another secret", func(t *testing.T) {
+		other := &Service{enabled: true, secret: []byte("other"), zclient: zanzana.NewNoopClient()}
+		token, _, err := other.Issue(context.Background(), 1, "dashboards:uid:dash", time.Hour)
+		require.NoError(t, err)
+
+		_, _, err = s.WithToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		token, err := s.sign(&Claims{ID: "expired", OrgID: 1, Scope: "dashboards:uid:dash", Expires: time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+
+		_, _, err = s.WithToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		// Expired tokens can still be revoked
+		_, err = s.Revoke(context.Background(), token)
+		require.NoError(t, err)
+	})
+
+	t.Run("should delete the tuples of revoked tokens", func(t *testing.T) {
+		client := &fakeWriteClient{Client: zanzana.NewNoopClient()}
+		s := &Service{enabled: true, secret: []byte("secret"), zclient: client}
+		token, _, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", time.Hour)
+		require.NoError(t, err)
+
+		_, err = s.Revoke(context.Background(), token)
+		require.NoError(t, err)
+		require.Len(t, client.deletes, len(client.writes))
+		for i, written := range client.writes {
+			assert.Equal(t, written.GetUser(), client.deletes[i].GetUser())
+			assert.Equal(t, written.GetRelation(), client.deletes[i].GetRelation())
+			assert.Equal(t, written.GetObject(), client.deletes[i].GetObject())
+		}
+	})
+
+	t.Run("should list and revoke the tokens of a resource by id", func(t *testing.T) {
+		client := &fakeWriteClient{Client: zanzana.NewNoopClient()}
+		s := &Service{enabled: true, secret: []byte("secret"), zclient: client}
+		_, first, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", time.Hour)
+		require.NoError(t, err)
+		_, second, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", 2*time.Hour)
+		require.NoError(t, err)
+		_, _, err = s.Issue(context.Background(), 1, "dashboards:uid:other", time.Hour)
+		require.NoError(t, err)
+
+		tokens, err := s.List(context.Background(), 1, "dashboards:uid:dash")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*Claims{first, second}, tokens)
+
+		revoked, err := s.RevokeByID(context.Background(), 1, "dashboards:uid:dash", first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first, revoked)
+
+		tokens, err = s.List(context.Background(), 1, "dashboards:uid:dash")
+		require.NoError(t, err)
+		assert.Equal(t, []*Claims{second}, tokens)
+
+		_, err = s.RevokeByID(context.Background(), 1, "dashboards:uid:dash", first.ID)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+		_, err = s.RevokeByID(context.Background(), 1, "dashboards:uid:other", second.ID)
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+
+	t.Run("should revoke all the tokens of a resource", func(t *testing.T) {
+		client := &fakeWriteClient{Client: zanzana.NewNoopClient()}
+		s := &Service{enabled: true, secret: []byte("secret"), zclient: client}
+		for i := 0; i < revokeBatchSize+1; i++ {
+			_, _, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", time.Hour)
+			require.NoError(t, err)
+		}
+		_, other, err := s.Issue(context.Background(), 1, "dashboards:uid:other", time.Hour)
+		require.NoError(t, err)
+
+		require.NoError(t, s.RevokeResourceTokens(context.Background(), 1, "dashboards:uid:dash"))
+		tokens, err := s.List(context.Background(), 1, "dashboards:uid:dash")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+
+		tokens, err = s.List(context.Background(), 1, "dashboards:uid:other")
+		require.NoError(t, err)
+		assert.Equal(t, []*Claims{other}, tokens)
+	})
+
+	t.Run("should reject scopes that can't be shared", func(t *testing.T) {
+		for _, scope := range []string{"dashboards:uid:*", "dashboards:id:1", "datasources:uid:ds"} {
+			_, _, err := s.Issue(context.Background(), 1, scope, time.Hour)
+			assert.ErrorIs(t, err, ErrUnsupportedScope, scope)
+		}
+	})
+
+	t.Run("should reject ttls over 30 days", func(t *testing.T) {
+		_, _, err := s.Issue(context.Background(), 1, "dashboards:uid:dash", 31*24*time.Hour)
+		assert.ErrorIs(t, err, ErrInvalidTTL)
+	})
+}
@@ -0,0 +1,83 @@
+// Package slowquery logs the query plan of permission queries slower than the configured threshold,
+// to diagnose slow permission lookups on a given database without access to it.
+package slowquery
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProvideLogger returns a logger for the threshold configured in the rbac section.
+// It returns nil when no threshold is configured, a nil logger is valid and only runs the queries.
+func ProvideLogger(cfg *setting.Cfg, sql db.DB) *Logger {
+	if cfg == nil || cfg.RBAC.PermissionSlowQueryThreshold <= 0 {
+		return nil
+	}
+	return NewLogger(cfg.RBAC.PermissionSlowQueryThreshold, sql.GetDialect().DriverName())
+}
+
+func NewLogger(threshold time.Duration, driver string) *Logger {
+	return &Logger{threshold: threshold, driver: driver, log: log.New("accesscontrol.slowquery")}
+}
+
+type Logger struct {
+	threshold time.Duration
+	driver    string
+	log       log.Logger
+}
+
+// Find runs the query into dest, a pointer to a slice. When the query is slower than the threshold
+// the duration, the number of rows and the plan of the query are logged under name.
+func (l *Logger) Find(ctx context.Context, sess *db.Session, name string, dest any, query string, args ...any) error {
+	if l == nil {
+		return sess.SQL(query, args...).Find(dest)
+	}
+
+	start := time.Now()
+	if err := sess.SQL(query, args...).Find(dest); err != nil {
+		return err
+	}
+	duration := time.Since(start)
+	if duration < l.threshold {
+		return nil
+	}
+
+	rows := 0
+	if v := reflect.ValueOf(dest); v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Slice {
+		rows = v.Elem().Len()
+	}
+
+	logger := l.log.FromContext(ctx)
+	plan, err := l.explain(sess, query, args)
+	if err != nil {
+		logger.Warn("Slow permission query", "query", name, "duration", duration, "rows", rows, "explainError", err)
+		return nil
+	}
+	logger.Warn("Slow permission query", "query", name, "duration", duration, "rows", rows, "plan", plan)
+	return nil
+}
+
+func (l *Logger) explain(sess *db.Session, query string, args []any) (string, error) {
+	prefix := "EXPLAIN "
+	if l.driver == migrator.SQLite {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+
+	res, err := sess.QuerySliceString(append([]any{prefix + query}, args...)...)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(res))
+	for _, row := range res {
+		lines = append(lines, strings.Join(row, " | "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
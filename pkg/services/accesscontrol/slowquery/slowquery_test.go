@@ -0,0 +1,56 @@
+package slowquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationLogger_Find(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sql := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	assert.Nil(t, ProvideLogger(cfg, sql))
+
+	cfg.RBAC.PermissionSlowQueryThreshold = 1
+	logger := ProvideLogger(cfg, sql)
+	require.NotNil(t, logger)
+
+	var ids []int64
+	var plan string
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		if err := logger.Find(context.Background(), sess, "test", &ids, "SELECT id FROM role WHERE org_id = ?", 1); err != nil {
+			return err
+		}
+		var err error
+		plan, err = logger.explain(sess, "SELECT id FROM role WHERE org_id = ?", []any{1})
+		return err
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, plan)
+}
+
+func TestLogger_Find_Nil(t *testing.T) {
+	var logger *Logger
+	sql := db.InitTestDB(t)
+
+	var ids []int64
+	err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return logger.Find(context.Background(), sess, "test", &ids, "SELECT id FROM role")
+	})
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,60 @@
+package accesscontrol
+
+import (
+	"context"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format produced by ExportSnapshot.
+const SnapshotVersion = 1
+
+// Snapshotter is implemented by services and stores that can export and restore the access control state of an org.
+type Snapshotter interface {
+	// ExportSnapshot returns the roles of the org together with their permissions and assignments.
+	ExportSnapshot(ctx context.Context, orgID int64) (*Snapshot, error)
+	// ImportSnapshot replaces the roles of the org with the ones in the snapshot.
+	// The snapshot is validated before anything is written, assigned users and teams must exist in the org.
+	ImportSnapshot(ctx context.Context, orgID int64, snapshot *Snapshot) error
+}
+
+// OrgCopier is implemented by services and stores that can copy the access control state of an org into another org.
+type OrgCopier interface {
+	// CopyOrgPermissions adds the roles, permissions and assignments of the source org that are missing in the
+	// destination org. Roles get new uids, team assignments are matched by team name and user assignments
+	// are only copied for users that are members of the destination org.
+	CopyOrgPermissions(ctx context.Context, srcOrgID, dstOrgID int64) error
+}
+
+// Snapshot is a portable archive of the access control state of an org.
+// Only roles stored in the org are part of the snapshot: global roles are declared on start-up and
+// Zanzana tuples are reconciled from the restored state. The assignments of global roles, e.g. fixed
+// roles, to the users and teams of the org are neither exported nor touched by an import, and snapshots
+// containing roles named after global roles are rejected.
+type Snapshot struct {
+	Version int            `json:"version"`
+	OrgID   int64          `json:"orgId"`
+	Created time.Time      `json:"created"`
+	Roles   []SnapshotRole `json:"roles"`
+}
+
+type SnapshotRole struct {
+	UID         string `json:"uid"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	Version     int64  `json:"version"`
+
+	Permissions  []SnapshotPermission `json:"permissions,omitempty"`
+	Users        []int64              `json:"users,omitempty"`
+	Teams        []int64              `json:"teams,omitempty"`
+	BuiltInRoles []string             `json:"builtInRoles,omitempty"`
+	Groups       []string             `json:"groups,omitempty"`
+}
+
+type SnapshotPermission struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	Deny   bool   `json:"deny,omitempty"`
+}
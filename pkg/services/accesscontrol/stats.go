@@ -0,0 +1,39 @@
+package accesscontrol
+
+import "context"
+
+// StatsReporter is implemented by services and stores that can report statistics of the roles and permissions of
+// all orgs, e.g. to include them in support bundles.
+type StatsReporter interface {
+	// GetStats returns the statistics of the roles and permissions of all orgs.
+	GetStats(ctx context.Context) (*Stats, error)
+}
+
+// Stats are anonymized statistics of the roles and permissions, they don't include the names or identifiers of
+// users, teams or resources.
+type Stats struct {
+	// RolesByKind is the number of roles by kind (managed, fixed, basic, plugins, extsvc and custom)
+	RolesByKind map[string]int64 `json:"rolesByKind"`
+	// Assignments is the number of role assignments by assignee (users, teams and basic roles)
+	Assignments map[string]int64 `json:"assignments"`
+	// PermissionsByKind is the number of permissions by kind of resource of their scope
+	PermissionsByKind map[string]int64 `json:"permissionsByKind"`
+	// LargestManagedRoles are the managed roles with the most permissions, from the largest
+	LargestManagedRoles []ManagedRoleStats `json:"largestManagedRoles"`
+	// LargestRolePermissions is the number of permissions of the role with the most permissions, of any kind
+	LargestRolePermissions int64 `json:"largestRolePermissions"`
+}
+
+type ManagedRoleStats struct {
+	OrgID int64 `json:"orgId"`
+	// Assignee is the kind of assignee of the managed role, e.g. users, teams or builtins
+	Assignee    string `json:"assignee"`
+	Permissions int64  `json:"permissions"`
+}
+
+// TupleCounter is implemented by components that can count the tuples stored in zanzana.
+type TupleCounter interface {
+	// CountTuples returns the number of tuples in the zanzana store, or ErrTupleCountUnavailable when the store
+	// cannot count them.
+	CountTuples(ctx context.Context) (int64, error)
+}
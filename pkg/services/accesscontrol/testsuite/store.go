@@ -0,0 +1,138 @@
+// Package testsuite contains a conformance test suite for implementations of accesscontrol.Store.
+// Every implementation (SQL, Zanzana backed, mocks used as fakes) is expected to pass it so that
+// they don't diverge in edge case semantics, like the handling of the global org (org 0).
+package testsuite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// StoreFactory returns a new and empty store for every call.
+type StoreFactory func(t *testing.T) accesscontrol.Store
+
+// RunStoreSuite runs the conformance suite against the stores created by newStore.
+func RunStoreSuite(t *testing.T, newStore StoreFactory) {
+	t.Run("GetUserPermissions without identity returns no permissions", func(t *testing.T) {
+		testGetUserPermissionsNoIdentity(t, newStore(t))
+	})
+	t.Run("GetUserPermissions returns permissions of an assigned role", func(t *testing.T) {
+		testGetUserPermissionsAssigned(t, newStore(t))
+	})
+	t.Run("Global org assignments are visible in every org", func(t *testing.T) {
+		testGlobalOrgAssignment(t, newStore(t))
+	})
+	t.Run("Org assignments are not visible in other orgs", func(t *testing.T) {
+		testOrgAssignmentIsolation(t, newStore(t))
+	})
+	t.Run("SearchUsersPermissions is indexed by user id", func(t *testing.T) {
+		testSearchUsersPermissions(t, newStore(t))
+	})
+	t.Run("DeleteUserPermissions in an org keeps other orgs", func(t *testing.T) {
+		testDeleteUserPermissionsInOrg(t, newStore(t))
+	})
+	t.Run("DeleteExternalServiceRole removes permissions and is idempotent", func(t *testing.T) {
+		testDeleteExternalServiceRole(t, newStore(t))
+	})
+}
+
+var (
+	readUsers  = accesscontrol.Permission{Action: "users:read", Scope: "users:id:1"}
+	writeUsers = accesscontrol.Permission{Action: "users:write", Scope: "users:id:1"}
+)
+
+func saveRole(t *testing.T, store accesscontrol.Store, serviceID string, orgID, userID int64, permissions ...accesscontrol.Permission) {
+	t.Helper()
+
+	err := store.SaveExternalServiceRole(context.Background(), accesscontrol.SaveExternalServiceRoleCommand{
+		AssignmentOrgID:   orgID,
+		ExternalServiceID: serviceID,
+		ServiceAccountID:  userID,
+		Permissions:       permissions,
+	})
+	require.NoError(t, err)
+}
+
+func getUserPermissions(t *testing.T, store accesscontrol.Store, orgID, userID int64) []accesscontrol.Permission {
+	t.Helper()
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+		OrgID:  orgID,
+		UserID: userID,
+	})
+	require.NoError(t, err)
+	return stripPermissions(permissions)
+}
+
+// stripPermissions only keeps action and scope so that implementations are free to set other fields
+func stripPermissions(permissions []accesscontrol.Permission) []accesscontrol.Permission {
+	stripped := make([]accesscontrol.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		stripped = append(stripped, accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+	}
+	return stripped
+}
+
+func testGetUserPermissionsNoIdentity(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app", 1, 1, readUsers)
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{OrgID: 1})
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+}
+
+func testGetUserPermissionsAssigned(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app", 1, 1, readUsers, writeUsers)
+
+	assert.ElementsMatch(t, []accesscontrol.Permission{readUsers, writeUsers}, getUserPermissions(t, store, 1, 1))
+	assert.Empty(t, getUserPermissions(t, store, 1, 2))
+}
+
+func testGlobalOrgAssignment(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app", accesscontrol.GlobalOrgID, 1, readUsers)
+
+	assert.ElementsMatch(t, []accesscontrol.Permission{readUsers}, getUserPermissions(t, store, 1, 1))
+	assert.ElementsMatch(t, []accesscontrol.Permission{readUsers}, getUserPermissions(t, store, 2, 1))
+}
+
+func testOrgAssignmentIsolation(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app", 1, 1, readUsers)
+
+	assert.ElementsMatch(t, []accesscontrol.Permission{readUsers}, getUserPermissions(t, store, 1, 1))
+	assert.Empty(t, getUserPermissions(t, store, 2, 1))
+}
+
+func testSearchUsersPermissions(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app1", 1, 1, readUsers)
+	saveRole(t, store, "app2", 1, 2, writeUsers)
+
+	permissions, err := store.SearchUsersPermissions(context.Background(), 1, accesscontrol.SearchOptions{ActionPrefix: "users:"})
+	require.NoError(t, err)
+	require.Len(t, permissions, 2)
+	assert.ElementsMatch(t, []accesscontrol.Permission{readUsers}, stripPermissions(permissions[1]))
+	assert.ElementsMatch(t, []accesscontrol.Permission{writeUsers}, stripPermissions(permissions[2]))
+}
+
+func testDeleteUserPermissionsInOrg(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app1", 1, 1, readUsers)
+	saveRole(t, store, "app2", 2, 1, writeUsers)
+
+	require.NoError(t, store.DeleteUserPermissions(context.Background(), 1, 1))
+
+	assert.Empty(t, getUserPermissions(t, store, 1, 1))
+	assert.ElementsMatch(t, []accesscontrol.Permission{writeUsers}, getUserPermissions(t, store, 2, 1))
+}
+
+func testDeleteExternalServiceRole(t *testing.T, store accesscontrol.Store) {
+	saveRole(t, store, "app", 1, 1, readUsers)
+
+	require.NoError(t, store.DeleteExternalServiceRole(context.Background(), "app"))
+	require.NoError(t, store.DeleteExternalServiceRole(context.Background(), "app"))
+
+	assert.Empty(t, getUserPermissions(t, store, 1, 1))
+}
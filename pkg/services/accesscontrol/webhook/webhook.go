@@ -0,0 +1,218 @@
+// Package webhook sends permission and role assignment changes to an external endpoint.
+// Payloads are JSON encoded and signed with HMAC-SHA256 using the configured secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// SignatureHeader contains the hex encoded HMAC-SHA256 signature of the request body.
+	SignatureHeader = "X-Grafana-Signature"
+	// EventHeader contains the type of the event sent in the request body.
+	EventHeader = "X-Grafana-Event"
+
+	sendTimeout = 10 * time.Second
+	// queueSize is the number of events waiting to be sent, events are dropped when the endpoint can't keep up
+	queueSize = 1000
+)
+
+type EventType string
+
+const (
+	EventResourcePermissionSet     EventType = "resource_permission.set"
+	EventResourcePermissionDeleted EventType = "resource_permission.deleted"
+	EventRoleAssignmentSet         EventType = "role_assignment.set"
+	EventRoleAssignmentDeleted     EventType = "role_assignment.deleted"
+)
+
+// Event describes a permission or role assignment change.
+// Only the fields relevant to the type of the event are set.
+type Event struct {
+	Type        EventType `json:"type"`
+	OrgID       int64     `json:"orgId"`
+	Timestamp   time.Time `json:"timestamp"`
+	Resource    string    `json:"resource,omitempty"`
+	ResourceID  string    `json:"resourceId,omitempty"`
+	UserID      int64     `json:"userId,omitempty"`
+	TeamID      int64     `json:"teamId,omitempty"`
+	BuiltInRole string    `json:"builtInRole,omitempty"`
+	GroupID     string    `json:"groupId,omitempty"`
+	Role        string    `json:"role,omitempty"`
+	Permission  string    `json:"permission,omitempty"`
+	Actions     []string  `json:"actions,omitempty"`
+}
+
+// ProvideNotifier returns a notifier for the webhook configured in the rbac section.
+// It returns nil when no webhook is configured, a nil notifier is valid and drops every event.
+func ProvideNotifier(cfg *setting.Cfg, sql db.DB) *Notifier {
+	if cfg == nil || cfg.RBAC.PermissionWebhookURL == "" {
+		return nil
+	}
+	n := NewNotifier(cfg.RBAC.PermissionWebhookURL, cfg.RBAC.PermissionWebhookSecret, cfg.RBAC.PermissionWebhookEvents)
+	if store, ok := sql.(interface{ Bus() bus.Bus }); ok && store.Bus() != nil {
+		listenCommits(store.Bus())
+		n.afterCommit = true
+	}
+	return n
+}
+
+func NewNotifier(url, secret string, events []string) *Notifier {
+	n := &Notifier{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: sendTimeout},
+		queue:  make(chan Event, queueSize),
+		log:    log.New("accesscontrol.webhook"),
+	}
+	if len(events) > 0 {
+		n.events = make(map[EventType]struct{}, len(events))
+		for _, e := range events {
+			n.events[EventType(e)] = struct{}{}
+		}
+	}
+	return n
+}
+
+type Notifier struct {
+	url    string
+	secret []byte
+	// events sent to the webhook, every event is sent when nil
+	events map[EventType]struct{}
+	// afterCommit is set when the events notified in a transaction of the caller are published on the bus of the
+	// sql store once it is committed
+	afterCommit bool
+	client      *http.Client
+	// queue holds the events until the worker sends them, the worker is started with the first event
+	queue  chan Event
+	worker sync.Once
+	log    log.Logger
+}
+
+// Notify sends the events in the background. Delivery is best effort, failures are logged.
+// It should be called once the change has been committed, events notified in a transaction of the caller are held
+// until the transaction is committed and dropped if it is rolled back.
+func (n *Notifier) Notify(ctx context.Context, events ...Event) {
+	if n == nil {
+		return
+	}
+
+	filtered := make([]Event, 0, len(events))
+	now := time.Now()
+	for _, e := range events {
+		if !n.accepts(e.Type) {
+			continue
+		}
+		if e.Timestamp.IsZero() {
+			e.Timestamp = now
+		}
+		filtered = append(filtered, e)
+	}
+	if len(filtered) == 0 {
+		return
+	}
+
+	if sess, ok := ctx.Value(sqlstore.ContextSessionKey{}).(*sqlstore.DBSession); ok && n.afterCommit {
+		sess.PublishAfterCommit(&PermissionWebhookEvents{notifier: n, events: filtered})
+		return
+	}
+	n.enqueue(filtered)
+}
+
+// PermissionWebhookEvents are published on the bus when the transaction they were notified in is committed.
+type PermissionWebhookEvents struct {
+	notifier *Notifier
+	events   []Event
+}
+
+// listeningBuses are the buses the committed events are listened on, stores sharing a bus share the listener
+var listeningBuses sync.Map
+
+func listenCommits(b bus.Bus) {
+	if _, loaded := listeningBuses.LoadOrStore(b, struct{}{}); loaded {
+		return
+	}
+	b.AddEventListener(func(_ context.Context, e *PermissionWebhookEvents) error {
+		e.notifier.enqueue(e.events)
+		return nil
+	})
+}
+
+func (n *Notifier) enqueue(events []Event) {
+	n.worker.Do(func() {
+		go n.run()
+	})
+	for _, e := range events {
+		select {
+		case n.queue <- e:
+		default:
+			n.log.Warn("Dropping permission webhook, the queue is full", "event", e.Type)
+		}
+	}
+}
+
+func (n *Notifier) run() {
+	for e := range n.queue {
+		if err := n.send(context.Background(), e); err != nil {
+			n.log.Warn("Failed to send permission webhook", "event", e.Type, "error", err)
+		}
+	}
+}
+
+func (n *Notifier) accepts(eventType EventType) bool {
+	if n.events == nil {
+		return true
+	}
+	_, ok := n.events[eventType]
+	return ok
+}
+
+func (n *Notifier) send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event.Type))
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature of payload in the format sent in the SignatureHeader.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
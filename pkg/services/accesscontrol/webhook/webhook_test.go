@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestNotifier_Send(t *testing.T) {
+	var (
+		body      []byte
+		signature string
+		eventType string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		signature = r.Header.Get(SignatureHeader)
+		eventType = r.Header.Get(EventHeader)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(server.URL, "secret", nil)
+	err := n.send(context.Background(), Event{
+		Type:       EventResourcePermissionSet,
+		OrgID:      1,
+		Resource:   "dashboards",
+		ResourceID: "abc",
+		UserID:     2,
+		Permission: "Edit",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, string(EventResourcePermissionSet), eventType)
+	assert.Equal(t, Sign([]byte("secret"), body), signature)
+
+	var received Event
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, "abc", received.ResourceID)
+	assert.Equal(t, int64(2), received.UserID)
+}
+
+func TestNotifier_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(server.URL, "", nil)
+	require.Error(t, n.send(context.Background(), Event{Type: EventRoleAssignmentDeleted}))
+}
+
+func TestNotifier_Accepts(t *testing.T) {
+	all := NewNotifier("http://localhost", "", nil)
+	assert.True(t, all.accepts(EventRoleAssignmentSet))
+
+	filtered := NewNotifier("http://localhost", "", []string{string(EventRoleAssignmentSet)})
+	assert.True(t, filtered.accepts(EventRoleAssignmentSet))
+	assert.False(t, filtered.accepts(EventResourcePermissionSet))
+}
+
+func TestProvideNotifier(t *testing.T) {
+	cfg := setting.NewCfg()
+	assert.Nil(t, ProvideNotifier(cfg, nil))
+
+	// a nil notifier drops events
+	ProvideNotifier(cfg, nil).Notify(context.Background(), Event{Type: EventRoleAssignmentSet})
+
+	cfg.RBAC.PermissionWebhookURL = "http://localhost"
+	assert.NotNil(t, ProvideNotifier(cfg, nil))
+}
+
+func TestIntegrationNotifier_NotifyAfterCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		received <- e.ResourceID
+	}))
+	defer server.Close()
+
+	cfg := setting.NewCfg()
+	cfg.RBAC.PermissionWebhookURL = server.URL
+	sql := db.InitTestDB(t)
+	n := ProvideNotifier(cfg, sql)
+
+	t.Run("should send the events of a transaction once it is committed", func(t *testing.T) {
+		err := sql.InTransaction(context.Background(), func(ctx context.Context) error {
+			n.Notify(ctx, Event{Type: EventResourcePermissionSet, ResourceID: "committed"})
+			select {
+			case <-received:
+				t.Fatal("event sent before the transaction was committed")
+			case <-time.After(100 * time.Millisecond):
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		select {
+		case id := <-received:
+			assert.Equal(t, "committed", id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not sent after the transaction was committed")
+		}
+	})
+
+	t.Run("should drop the events of a transaction rolled back", func(t *testing.T) {
+		err := sql.InTransaction(context.Background(), func(ctx context.Context) error {
+			n.Notify(ctx, Event{Type: EventResourcePermissionSet, ResourceID: "rolled back"})
+			return errors.New("rollback")
+		})
+		require.Error(t, err)
+
+		n.Notify(context.Background(), Event{Type: EventResourcePermissionSet, ResourceID: "outside"})
+		select {
+		case id := <-received:
+			assert.Equal(t, "outside", id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not sent")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package accesscontrol
+
+import (
+	"slices"
+	"sync"
+)
+
+// maxCachedWildcards bounds the number of prefixes whose wildcards are cached, scopes come from requests and the
+// number of distinct prefixes is not bounded by the resources known to the instance
+const maxCachedWildcards = 1000
+
+// WildcardRegistry computes and caches the wildcards that include scopes with a given prefix.
+// By default the hierarchy is derived from the prefix (see WildcardsFromPrefix), additional
+// wildcards can be declared per prefix by the resource owners whose scope hierarchy is not fully
+// described by the prefix itself. A nil registry only returns the wildcards derived from the prefix.
+type WildcardRegistry struct {
+	mx       sync.RWMutex
+	declared map[string][]string
+	cache    map[string]Wildcards
+}
+
+func ProvideWildcardRegistry() *WildcardRegistry {
+	return NewWildcardRegistry()
+}
+
+func NewWildcardRegistry() *WildcardRegistry {
+	return &WildcardRegistry{
+		declared: make(map[string][]string),
+		cache:    make(map[string]Wildcards),
+	}
+}
+
+// Register declares additional wildcards for prefix and invalidates the cached wildcards.
+func (r *WildcardRegistry) Register(prefix string, wildcards ...string) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	for _, w := range wildcards {
+		if !slices.Contains(r.declared[prefix], w) {
+			r.declared[prefix] = append(r.declared[prefix], w)
+		}
+	}
+	r.cache = make(map[string]Wildcards)
+}
+
+// ForScope returns the wildcards that include the scope.
+// datasources:uid:abc => "*", "datasources:*", "datasources:uid:*" + registered wildcards for "datasources:uid:"
+func (r *WildcardRegistry) ForScope(scope string) Wildcards {
+	return r.Wildcards(ScopePrefix(scope))
+}
+
+// Wildcards returns the wildcards that include scopes with prefix.
+// The returned slice is shared and must not be modified, appending to it is safe.
+func (r *WildcardRegistry) Wildcards(prefix string) Wildcards {
+	if r == nil {
+		return WildcardsFromPrefix(prefix)
+	}
+
+	r.mx.RLock()
+	wildcards, ok := r.cache[prefix]
+	r.mx.RUnlock()
+	if ok {
+		return wildcards
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	wildcards = WildcardsFromPrefix(prefix)
+	for _, w := range r.declared[prefix] {
+		if !wildcards.Contains(w) {
+			wildcards = append(wildcards, w)
+		}
+	}
+
+	// Clip the capacity so that callers appending to the result don't write into the cached slice
+	wildcards = slices.Clip(wildcards)
+	if len(r.cache) >= maxCachedWildcards {
+		r.cache = make(map[string]Wildcards)
+	}
+	r.cache[prefix] = wildcards
+	return wildcards
+}
@@ -0,0 +1,53 @@
+package accesscontrol
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWildcardRegistry(t *testing.T) {
+	registry := NewWildcardRegistry()
+
+	assert.Equal(t, Wildcards{"*", "datasources:*", "datasources:uid:*"}, registry.Wildcards("datasources:uid:"))
+
+	registry.Register("datasources:uid:", "datasources:type:*", "datasources:type:*")
+	assert.Equal(t, Wildcards{"*", "datasources:*", "datasources:uid:*", "datasources:type:*"}, registry.Wildcards("datasources:uid:"))
+	assert.Equal(t, Wildcards{"*", "dashboards:*", "dashboards:uid:*"}, registry.Wildcards("dashboards:uid:"))
+}
+
+func TestWildcardRegistry_AppendDoesNotModifyCache(t *testing.T) {
+	registry := NewWildcardRegistry()
+
+	wildcards := registry.Wildcards("teams:id:")
+	_ = append(wildcards, "teams:id:1")
+
+	assert.Equal(t, Wildcards{"*", "teams:*", "teams:id:*"}, registry.Wildcards("teams:id:"))
+}
+
+func TestWildcardRegistry_BoundedCache(t *testing.T) {
+	registry := NewWildcardRegistry()
+
+	for i := 0; i < 2*maxCachedWildcards; i++ {
+		registry.Wildcards("resource" + strconv.Itoa(i) + ":uid:")
+	}
+	assert.LessOrEqual(t, len(registry.cache), maxCachedWildcards)
+	assert.Equal(t, Wildcards{"*", "teams:*", "teams:id:*"}, registry.Wildcards("teams:id:"))
+}
+
+func TestWildcardRegistry_Nil(t *testing.T) {
+	var registry *WildcardRegistry
+	assert.Equal(t, Wildcards{"*", "dashboards:*", "dashboards:uid:*"}, registry.ForScope("dashboards:uid:1"))
+}
+
+func TestSearchOptions_ResolveWildcards(t *testing.T) {
+	registry := NewWildcardRegistry()
+	registry.Register("dashboards:uid:", "folders:*")
+
+	options := SearchOptions{Scope: "dashboards:uid:1"}
+	assert.Equal(t, []string{"*", "dashboards:*", "dashboards:uid:*"}, options.Wildcards())
+
+	options.ResolveWildcards(registry)
+	assert.Equal(t, []string{"*", "dashboards:*", "dashboards:uid:*", "folders:*"}, options.Wildcards())
+}
@@ -81,6 +81,7 @@ func (s *OrgSync) SyncOrgRolesHook(ctx context.Context, id *authn.Identity, _ *a
 				ctxLogger.Error("Failed to update active org user", "error", err)
 				return err
 			}
+			s.syncOrgRole(ctx, orga.OrgID, userID)
 		}
 	}
 
@@ -104,6 +105,7 @@ func (s *OrgSync) SyncOrgRolesHook(ctx context.Context, id *authn.Identity, _ *a
 			ctxLogger.Error("Failed to update active org for user", "error", err)
 			return err
 		}
+		s.syncOrgRole(ctx, orgId, userID)
 
 		orgIDs = append(orgIDs, orgId)
 	}
@@ -124,6 +126,7 @@ func (s *OrgSync) SyncOrgRolesHook(ctx context.Context, id *authn.Identity, _ *a
 		if err := s.accessControl.DeleteUserPermissions(ctx, orgID, cmd.UserID); err != nil {
 			ctxLogger.Error("Failed to delete permissions for user", "orgId", orgID, "error", err)
 		}
+		s.syncOrgRole(ctx, orgID, userID)
 	}
 
 	// Note: sort all org ids to not make it flaky, for now we default to the lowest id
@@ -142,6 +145,18 @@ func (s *OrgSync) SyncOrgRolesHook(ctx context.Context, id *authn.Identity, _ *a
 	return nil
 }
 
+// syncOrgRole syncs the zanzana basic role assignments of the user in the org once its membership was added, updated
+// or removed.
+func (s *OrgSync) syncOrgRole(ctx context.Context, orgID, userID int64) {
+	syncer, ok := s.accessControl.(accesscontrol.OrgRoleSyncer)
+	if !ok {
+		return
+	}
+	if err := syncer.OrgRoleChanged(ctx, orgID, userID); err != nil {
+		s.log.FromContext(ctx).Error("Failed to sync basic role assignment", "orgId", orgID, "userId", userID, "error", err)
+	}
+}
+
 func (s *OrgSync) SetDefaultOrgHook(ctx context.Context, currentIdentity *authn.Identity, r *authn.Request, err error) {
 	ctx, span := s.tracer.Start(ctx, "org.sync.SetDefaultOrgHook")
 	defer span.End()
@@ -186,3 +186,38 @@ func (s *RBACSync) SyncCloudRoles(ctx context.Context, ident *authn.Identity, r
 		RolesToRemove: rolesToRemove,
 	})
 }
+
+// SyncExternalGroupsHook stores the identity provider groups of the user on login.
+// Permissions granted to a group apply to its members until their next login without the group. Failing to store
+// the groups doesn't fail the login, the user keeps the groups of its previous login.
+func (s *RBACSync) SyncExternalGroupsHook(ctx context.Context, ident *authn.Identity, _ *authn.Request) error {
+	ctx, span := s.tracer.Start(ctx, "rbac.sync.SyncExternalGroupsHook")
+	defer span.End()
+
+	// Groups are only known when the user is synced from the identity provider
+	if !ident.ClientParams.SyncUser || !ident.IsIdentityType(claims.TypeUser) {
+		return nil
+	}
+
+	syncer, ok := s.ac.(accesscontrol.ExternalGroupSyncer)
+	if !ok {
+		return nil
+	}
+
+	userID, err := ident.GetInternalID()
+	if err != nil {
+		s.log.FromContext(ctx).Error("Failed to sync external groups", "error", err, "id", ident.ID)
+		return nil
+	}
+
+	changed, err := syncer.SyncUserExternalGroups(ctx, userID, ident.Groups)
+	if err != nil {
+		s.log.FromContext(ctx).Error("Failed to sync external groups", "error", err, "id", ident.ID)
+		return nil
+	}
+
+	if changed {
+		s.ac.ClearUserPermissionCache(ident)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/grafana/authlib/claims"
@@ -272,6 +273,41 @@ func TestRBACSync_SyncCloudRoles(t *testing.T) {
 	}
 }
 
+// groupSyncerMock is an access control service storing external groups.
+type groupSyncerMock struct {
+	*acmock.Mock
+	changed bool
+	err     error
+}
+
+func (m *groupSyncerMock) SyncUserExternalGroups(_ context.Context, _ int64, _ []string) (bool, error) {
+	return m.changed, m.err
+}
+
+func TestRBACSync_SyncExternalGroupsHook(t *testing.T) {
+	tests := []struct {
+		desc          string
+		changed       bool
+		err           error
+		expectCleared bool
+	}{
+		{desc: "should clear the permission cache when the groups changed", changed: true, expectCleared: true},
+		{desc: "should not clear the permission cache when the groups didn't change", changed: false},
+		{desc: "should not fail the login when the groups can't be stored", err: errors.New("database is locked")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ac := &groupSyncerMock{Mock: acmock.New(), changed: tt.changed, err: tt.err}
+			s := &RBACSync{ac: ac, log: log.NewNopLogger(), tracer: tracing.InitializeTracerForTest()}
+
+			ident := &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1, Groups: []string{"cn=oncall"}, ClientParams: authn.ClientParams{SyncUser: true}}
+			require.NoError(t, s.SyncExternalGroupsHook(context.Background(), ident, &authn.Request{}))
+			assert.Equal(t, tt.expectCleared, len(ac.Calls.ClearUserPermissionCache) == 1)
+		})
+	}
+}
+
 func TestRBACSync_cloudRolesToAddAndRemove(t *testing.T) {
 	type testCase struct {
 		desc                  string
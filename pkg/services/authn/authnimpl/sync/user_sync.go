@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -51,12 +52,13 @@ var (
 	errSignupNotAllowed  = errors.New("system administrator has disabled signup")
 )
 
-func ProvideUserSync(userService user.Service, userProtectionService login.UserProtectionService, authInfoService login.AuthInfoService, quotaService quota.Service, tracer tracing.Tracer) *UserSync {
+func ProvideUserSync(userService user.Service, userProtectionService login.UserProtectionService, authInfoService login.AuthInfoService, quotaService quota.Service, accessControl accesscontrol.Service, tracer tracing.Tracer) *UserSync {
 	return &UserSync{
 		userService:           userService,
 		authInfoService:       authInfoService,
 		userProtectionService: userProtectionService,
 		quotaService:          quotaService,
+		accessControl:         accessControl,
 		log:                   log.New("user.sync"),
 		tracer:                tracer,
 	}
@@ -67,6 +69,7 @@ type UserSync struct {
 	authInfoService       login.AuthInfoService
 	userProtectionService login.UserProtectionService
 	quotaService          quota.Service
+	accessControl         accesscontrol.Service
 	log                   log.Logger
 	tracer                tracing.Tracer
 }
@@ -289,6 +292,14 @@ func (s *UserSync) updateUserAttributes(ctx context.Context, usr *user.User, id
 		if err := s.userService.Update(ctx, updateCmd); err != nil {
 			return err
 		}
+
+		if updateCmd.IsGrafanaAdmin != nil {
+			if syncer, ok := s.accessControl.(accesscontrol.OrgRoleSyncer); ok {
+				if err := syncer.GrafanaAdminChanged(ctx, usr.ID); err != nil {
+					s.log.FromContext(ctx).Error("Failed to sync Grafana Admin role assignment", "id", id.ID, "error", err)
+				}
+			}
+		}
 	}
 
 	return s.upsertAuthConnection(ctx, usr.ID, id, userAuth == nil)
@@ -327,6 +338,13 @@ func (s *UserSync) createUser(ctx context.Context, id *authn.Identity) (*user.Us
 		return nil, errCreateUser
 	}
 
+	// Users created with org roles are added to their orgs by the org sync
+	if syncer, ok := s.accessControl.(accesscontrol.OrgRoleSyncer); ok && len(id.OrgRoles) == 0 {
+		if err := syncer.OrgRoleChanged(ctx, usr.OrgID, usr.ID); err != nil {
+			s.log.FromContext(ctx).Error("Failed to sync basic role assignment", "id", id.ID, "orgId", usr.OrgID, "error", err)
+		}
+	}
+
 	err := s.upsertAuthConnection(ctx, usr.ID, id, true)
 	if err != nil {
 		return nil, err
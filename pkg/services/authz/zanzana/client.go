@@ -18,15 +18,29 @@ type Client interface {
 	Check(ctx context.Context, in *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error)
 	Read(ctx context.Context, in *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error)
 	ListObjects(ctx context.Context, in *openfgav1.ListObjectsRequest) (*openfgav1.ListObjectsResponse, error)
+	ListUsers(ctx context.Context, in *openfgav1.ListUsersRequest) (*openfgav1.ListUsersResponse, error)
 	Write(ctx context.Context, in *openfgav1.WriteRequest) error
 }
 
+// WithShareTokenHash returns a context making the checks and lists grant the access shared with the token with the
+// hash, see NewShareTokenTuples.
+func WithShareTokenHash(ctx context.Context, hash string) context.Context {
+	return client.WithShareTokenHash(ctx, hash)
+}
+
+// WithoutWriteCache returns a context whose writes are never skipped by the write cache of the client.
+func WithoutWriteCache(ctx context.Context) context.Context {
+	return client.WithoutWriteCache(ctx)
+}
+
 func NewClient(ctx context.Context, cc grpc.ClientConnInterface, cfg *setting.Cfg) (*client.Client, error) {
 	return client.New(
 		ctx,
 		cc,
 		client.WithTenantID(fmt.Sprintf("stack-%s", cfg.StackID)),
 		client.WithLogger(log.New("zanzana-client")),
+		client.WithWriteCache(cfg.Zanzana.WriteCacheSize, cfg.Zanzana.WriteCacheTTL),
+		client.WithCallTimeout(cfg.Zanzana.CallTimeout),
 	)
 }
 
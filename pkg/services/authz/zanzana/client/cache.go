@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// The cache is disabled by default, a tuple removed by another instance would not be written again until its
+	// entry expires
+	defaultWriteCacheSize = 0
+	defaultWriteCacheTTL  = 10 * time.Minute
+)
+
+// writeCache keeps track of the tuples known to exist in the store so writing them again can be skipped.
+// Entries expire after a while so tuples removed by another instance are eventually written again. Writes made with
+// WithoutWriteCache are never skipped.
+type writeCache struct {
+	// tuples maps the tuples to their condition, OpenFGA stores a single tuple per user, relation and object
+	tuples *expirable.LRU[writeCacheKey, string]
+}
+
+func newWriteCache(size int, ttl time.Duration) *writeCache {
+	return &writeCache{tuples: expirable.NewLRU[writeCacheKey, string](size, nil, ttl)}
+}
+
+// has returns true when the tuple is known to exist with the same condition.
+func (c *writeCache) has(t *openfgav1.TupleKey) bool {
+	key, ok := conditionKey(t.GetCondition())
+	if !ok {
+		return false
+	}
+	condition, ok := c.tuples.Get(writeCacheKey{t.GetUser(), t.GetRelation(), t.GetObject()})
+	return ok && condition == key
+}
+
+func (c *writeCache) add(tuples ...*openfgav1.TupleKey) {
+	for _, t := range tuples {
+		key := writeCacheKey{t.GetUser(), t.GetRelation(), t.GetObject()}
+		condition, ok := conditionKey(t.GetCondition())
+		if !ok {
+			c.tuples.Remove(key)
+			continue
+		}
+		c.tuples.Add(key, condition)
+	}
+}
+
+// remove evicts the tuples whatever their condition.
+func (c *writeCache) remove(tuples ...*openfgav1.TupleKeyWithoutCondition) {
+	for _, t := range tuples {
+		c.tuples.Remove(writeCacheKey{t.GetUser(), t.GetRelation(), t.GetObject()})
+	}
+}
+
+// purge evicts all tuples, e.g. when the store they were written to is no longer found.
+func (c *writeCache) purge() {
+	c.tuples.Purge()
+}
+
+type skipWriteCacheKey struct{}
+
+// WithoutWriteCache returns a context whose writes are sent whatever the tuples known to exist, e.g. for the writes
+// of the reconciler which are computed from the tuples read from the store.
+func WithoutWriteCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipWriteCacheKey{}, true)
+}
+
+func skipsWriteCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipWriteCacheKey{}).(bool)
+	return skip
+}
+
+type writeCacheKey struct {
+	user     string
+	relation string
+	object   string
+}
+
+// conditionKey identifies the condition of a tuple, including its context. Tuples without condition have an empty
+// key, tuples whose condition can't be encoded are not cached.
+func conditionKey(condition *openfgav1.RelationshipCondition) (string, bool) {
+	if condition == nil {
+		return "", true
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(condition)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
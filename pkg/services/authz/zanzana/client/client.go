@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/openfga/language/pkg/go/transformer"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -38,18 +43,46 @@ func WithSchema(modules []transformer.ModuleFile) ClientOption {
 	}
 }
 
+// WithCallTimeout bounds every call to the server, a shorter deadline of the caller context is kept.
+// A timeout of 0 only relies on the caller context.
+func WithCallTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.callTimeout = timeout
+	}
+}
+
+// WithWriteCache configures the cache of tuples known to exist, a size of 0 disables it.
+func WithWriteCache(size int, ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.writeCacheSize = size
+		c.writeCacheTTL = ttl
+	}
+}
+
 type Client struct {
 	logger   log.Logger
 	client   openfgav1.OpenFGAServiceClient
 	modules  []transformer.ModuleFile
 	tenantID string
-	storeID  string
-	modelID  string
+
+	// idsMu guards the store and authorization model ids of the tenant, they are resolved again after the server
+	// reports them as not found
+	idsMu   sync.RWMutex
+	storeID string
+	modelID string
+
+	callTimeout time.Duration
+
+	writeCacheSize int
+	writeCacheTTL  time.Duration
+	writeCache     *writeCache
 }
 
 func New(ctx context.Context, cc grpc.ClientConnInterface, opts ...ClientOption) (*Client, error) {
 	c := &Client{
-		client: openfgav1.NewOpenFGAServiceClient(cc),
+		client:         openfgav1.NewOpenFGAServiceClient(cc),
+		writeCacheSize: defaultWriteCacheSize,
+		writeCacheTTL:  defaultWriteCacheTTL,
 	}
 
 	for _, o := range opts {
@@ -68,38 +101,101 @@ func New(ctx context.Context, cc grpc.ClientConnInterface, opts ...ClientOption)
 		c.modules = schema.SchemaModules
 	}
 
-	store, err := c.getOrCreateStore(ctx, c.tenantID)
-	if err != nil {
+	if c.writeCacheSize > 0 {
+		c.writeCache = newWriteCache(c.writeCacheSize, c.writeCacheTTL)
+	}
+
+	if _, _, err := c.resolveIDs(ctx); err != nil {
 		return nil, err
 	}
 
-	c.storeID = store.GetId()
+	return c, nil
+}
+
+// resolveIDs returns the store and authorization model ids of the tenant, creating them when they don't exist.
+func (c *Client) resolveIDs(ctx context.Context) (string, string, error) {
+	c.idsMu.RLock()
+	storeID, modelID := c.storeID, c.modelID
+	c.idsMu.RUnlock()
+	if storeID != "" && modelID != "" {
+		return storeID, modelID, nil
+	}
+
+	c.idsMu.Lock()
+	defer c.idsMu.Unlock()
+	if c.storeID != "" && c.modelID != "" {
+		return c.storeID, c.modelID, nil
+	}
 
-	modelID, err := c.loadModel(ctx, c.storeID, c.modules)
+	store, err := c.getOrCreateStore(ctx, c.tenantID)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
-	c.modelID = modelID
+	modelID, err = c.loadModel(ctx, store.GetId(), c.modules)
+	if err != nil {
+		return "", "", err
+	}
 
-	return c, nil
+	c.storeID, c.modelID = store.GetId(), modelID
+	return c.storeID, c.modelID, nil
+}
+
+// invalidateIDs forgets the ids resolved for the tenant when the server reports them as not found, e.g. after the
+// store was deleted, so the next call resolves them again instead of failing until restart.
+func (c *Client) invalidateIDs(storeID string, err error) {
+	if status.Code(err) != codes.NotFound {
+		return
+	}
+
+	c.idsMu.Lock()
+	defer c.idsMu.Unlock()
+	if c.storeID == storeID {
+		c.logger.Warn("Zanzana store not found, resolving it again", "tenant", c.tenantID, "storeID", storeID)
+		c.storeID, c.modelID = "", ""
+		// The tuples known to exist were written to the store that is gone
+		if c.writeCache != nil {
+			c.writeCache.purge()
+		}
+	}
 }
 
 func (c *Client) Check(ctx context.Context, in *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
 	ctx, span := tracer.Start(ctx, "authz.zanzana.client.Check")
 	defer span.End()
 
-	in.StoreId = c.storeID
-	in.AuthorizationModelId = c.modelID
-	return c.client.Check(ctx, in)
+	storeID, modelID, err := c.resolveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	in.StoreId = storeID
+	in.AuthorizationModelId = modelID
+	in.Context = conditionContext(ctx, in.Context)
+	res, err := c.client.Check(ctx, in)
+	c.invalidateIDs(storeID, err)
+	return res, err
 }
 
 func (c *Client) Read(ctx context.Context, in *openfgav1.ReadRequest) (*openfgav1.ReadResponse, error) {
 	ctx, span := tracer.Start(ctx, "authz.zanzana.client.Read")
 	defer span.End()
 
-	in.StoreId = c.storeID
-	return c.client.Read(ctx, in)
+	storeID, _, err := c.resolveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	in.StoreId = storeID
+	res, err := c.client.Read(ctx, in)
+	c.invalidateIDs(storeID, err)
+	return res, err
 }
 
 func (c *Client) ListObjects(ctx context.Context, in *openfgav1.ListObjectsRequest) (*openfgav1.ListObjectsResponse, error) {
@@ -107,18 +203,160 @@ func (c *Client) ListObjects(ctx context.Context, in *openfgav1.ListObjectsReque
 	span.SetAttributes(attribute.String("resource.type", in.Type))
 	defer span.End()
 
-	in.StoreId = c.storeID
-	in.AuthorizationModelId = c.modelID
-	return c.client.ListObjects(ctx, in)
+	storeID, modelID, err := c.resolveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	in.StoreId = storeID
+	in.AuthorizationModelId = modelID
+	in.Context = conditionContext(ctx, in.Context)
+	res, err := c.client.ListObjects(ctx, in)
+	c.invalidateIDs(storeID, err)
+	return res, err
+}
+
+func (c *Client) ListUsers(ctx context.Context, in *openfgav1.ListUsersRequest) (*openfgav1.ListUsersResponse, error) {
+	ctx, span := tracer.Start(ctx, "authz.zanzana.client.ListUsers")
+	span.SetAttributes(attribute.String("resource.type", in.GetObject().GetType()))
+	defer span.End()
+
+	storeID, modelID, err := c.resolveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	in.StoreId = storeID
+	in.AuthorizationModelId = modelID
+	in.Context = conditionContext(ctx, in.Context)
+	res, err := c.client.ListUsers(ctx, in)
+	c.invalidateIDs(storeID, err)
+	return res, err
 }
 
+// Write skips the tuples known to exist in the store with the same condition, unless ctx was returned by
+// WithoutWriteCache. OpenFGA rejects the whole request when one of the written tuples already exists, the existing
+// tuples are then read one by one and only the missing ones are written. The request is not modified.
 func (c *Client) Write(ctx context.Context, in *openfgav1.WriteRequest) error {
-	in.StoreId = c.storeID
-	in.AuthorizationModelId = c.modelID
-	_, err := c.client.Write(ctx, in)
+	ctx, span := tracer.Start(ctx, "authz.zanzana.client.Write")
+	defer span.End()
+
+	storeID, modelID, err := c.resolveIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &openfgav1.WriteRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Deletes:              in.GetDeletes(),
+	}
+	if c.writeCache == nil {
+		return c.write(ctx, req, in.GetWrites().GetTupleKeys())
+	}
+
+	// Deleted tuples are evicted before writing so a failed request can't leave them cached
+	c.writeCache.remove(in.GetDeletes().GetTupleKeys()...)
+
+	if skipsWriteCache(ctx) {
+		if err := c.write(ctx, req, in.GetWrites().GetTupleKeys()); err != nil {
+			return err
+		}
+		c.writeCache.add(in.GetWrites().GetTupleKeys()...)
+		return nil
+	}
+
+	writes := make([]*openfgav1.TupleKey, 0, len(in.GetWrites().GetTupleKeys()))
+	for _, t := range in.GetWrites().GetTupleKeys() {
+		if !c.writeCache.has(t) {
+			writes = append(writes, t)
+		}
+	}
+	span.SetAttributes(attribute.Int("writes.skipped", len(in.GetWrites().GetTupleKeys())-len(writes)))
+
+	err = c.write(ctx, req, writes)
+	if err != nil && isAlreadyExists(err) {
+		writes, err = c.missingTuples(ctx, writes)
+		if err != nil {
+			return err
+		}
+		err = c.write(ctx, req, writes)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.writeCache.add(writes...)
+	return nil
+}
+
+// write sends the deletes of req along with writes, req is only used for a single call.
+func (c *Client) write(ctx context.Context, req *openfgav1.WriteRequest, writes []*openfgav1.TupleKey) error {
+	if len(writes) == 0 && len(req.GetDeletes().GetTupleKeys()) == 0 {
+		return nil
+	}
+
+	// OpenFGA rejects empty writes
+	call := &openfgav1.WriteRequest{
+		StoreId:              req.GetStoreId(),
+		AuthorizationModelId: req.GetAuthorizationModelId(),
+		Deletes:              req.GetDeletes(),
+	}
+	if len(writes) > 0 {
+		call.Writes = &openfgav1.WriteRequestWrites{TupleKeys: writes}
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.client.Write(ctx, call)
+	c.invalidateIDs(call.GetStoreId(), err)
 	return err
 }
 
+// withTimeout bounds a single call to the server by the configured timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.callTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.callTimeout)
+}
+
+// missingTuples returns the tuples that don't exist in the store and caches the existing ones.
+func (c *Client) missingTuples(ctx context.Context, tuples []*openfgav1.TupleKey) ([]*openfgav1.TupleKey, error) {
+	missing := make([]*openfgav1.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		res, err := c.Read(ctx, &openfgav1.ReadRequest{
+			TupleKey: &openfgav1.ReadRequestTupleKey{
+				User:     t.GetUser(),
+				Relation: t.GetRelation(),
+				Object:   t.GetObject(),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(res.GetTuples()) > 0 {
+			// The stored tuple is cached with its own condition, it may differ from the written one
+			c.writeCache.add(res.GetTuples()[0].GetKey())
+			continue
+		}
+		missing = append(missing, t)
+	}
+	return missing, nil
+}
+
+func isAlreadyExists(err error) bool {
+	return strings.Contains(err.Error(), "cannot write a tuple which already exists")
+}
+
 func (c *Client) getOrCreateStore(ctx context.Context, name string) (*openfgav1.Store, error) {
 	store, err := c.getStore(ctx, name)
 
@@ -207,7 +445,7 @@ func (c *Client) loadModel(ctx context.Context, storeID string, modules []transf
 	}
 
 	writeRes, err := c.client.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
-		StoreId:         c.storeID,
+		StoreId:         storeID,
 		TypeDefinitions: model.GetTypeDefinitions(),
 		SchemaVersion:   model.GetSchemaVersion(),
 		Conditions:      model.GetConditions(),
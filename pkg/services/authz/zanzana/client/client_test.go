@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"testing"
+	"time"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/openfga/language/pkg/go/transformer"
@@ -10,6 +11,9 @@ import (
 	"github.com/fullstorydev/grpchan/inprocgrpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -86,6 +90,95 @@ type user
 		assert.Equal(t, prevStoreID, c.storeID)
 		assert.Equal(t, prevModelID, c.modelID)
 	})
+
+	t.Run("should bound calls by the call timeout", func(t *testing.T) {
+		c, err := New(context.Background(), conn, WithCallTimeout(time.Nanosecond))
+		require.NoError(t, err)
+
+		_, err = c.Read(context.Background(), &openfgav1.ReadRequest{})
+		require.Error(t, err)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+}
+
+func TestIntegrationClientWrite(t *testing.T) {
+	conn := zanzanaServerIntegrationTest(t)
+
+	tuple := func(user string) *openfgav1.TupleKey {
+		return &openfgav1.TupleKey{User: "user:" + user, Relation: "member", Object: "team:1"}
+	}
+	write := func(c *Client, tuples ...*openfgav1.TupleKey) error {
+		return c.Write(context.Background(), &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+		})
+	}
+	users := func(c *Client) []string {
+		res, err := c.Read(context.Background(), &openfgav1.ReadRequest{
+			TupleKey: &openfgav1.ReadRequestTupleKey{Relation: "member", Object: "team:1"},
+		})
+		require.NoError(t, err)
+		var users []string
+		for _, r := range res.GetTuples() {
+			users = append(users, r.GetKey().GetUser())
+		}
+		return users
+	}
+
+	c, err := New(context.Background(), conn, WithTenantID("write"), WithWriteCache(10, time.Minute))
+	require.NoError(t, err)
+
+	t.Run("should skip tuples written by the client", func(t *testing.T) {
+		require.NoError(t, write(c, tuple("1")))
+		require.NoError(t, write(c, tuple("1")))
+		assert.True(t, c.writeCache.has(tuple("1")))
+		assert.ElementsMatch(t, []string{"user:1"}, users(c))
+	})
+
+	t.Run("should only write missing tuples when some already exist", func(t *testing.T) {
+		fresh, err := New(context.Background(), conn, WithTenantID("write"), WithWriteCache(10, time.Minute))
+		require.NoError(t, err)
+
+		require.NoError(t, write(fresh, tuple("1"), tuple("2")))
+		assert.ElementsMatch(t, []string{"user:1", "user:2"}, users(fresh))
+	})
+
+	t.Run("should write deleted tuples again", func(t *testing.T) {
+		err := c.Write(context.Background(), &openfgav1.WriteRequest{
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+				{User: "user:1", Relation: "member", Object: "team:1"},
+			}},
+		})
+		require.NoError(t, err)
+		assert.False(t, c.writeCache.has(tuple("1")))
+
+		require.NoError(t, write(c, tuple("1")))
+		assert.ElementsMatch(t, []string{"user:1", "user:2"}, users(c))
+	})
+
+	t.Run("should not skip the writes made without the write cache", func(t *testing.T) {
+		// The tuple is removed by another instance, the cache of c still has it
+		other, err := New(context.Background(), conn, WithTenantID("write"))
+		require.NoError(t, err)
+		err = other.Write(context.Background(), &openfgav1.WriteRequest{
+			Deletes: &openfgav1.WriteRequestDeletes{TupleKeys: []*openfgav1.TupleKeyWithoutCondition{
+				{User: "user:1", Relation: "member", Object: "team:1"},
+			}},
+		})
+		require.NoError(t, err)
+
+		err = c.Write(WithoutWriteCache(context.Background()), &openfgav1.WriteRequest{
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{tuple("1")}},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user:1", "user:2"}, users(c))
+	})
+
+	t.Run("should fail on existing tuples when the cache is disabled", func(t *testing.T) {
+		uncached, err := New(context.Background(), conn, WithTenantID("write"))
+		require.NoError(t, err)
+
+		require.Error(t, write(uncached, tuple("1")))
+	})
 }
 
 func zanzanaServerIntegrationTest(tb testing.TB) *inprocgrpc.Channel {
@@ -115,3 +208,90 @@ func zanzanaServerIntegrationTest(tb testing.TB) *inprocgrpc.Channel {
 
 	return channel
 }
+
+func TestIntegrationClientShareToken(t *testing.T) {
+	conn := zanzanaServerIntegrationTest(t)
+
+	c, err := New(context.Background(), conn, WithTenantID("share-token"))
+	require.NoError(t, err)
+
+	err = c.Write(context.Background(), &openfgav1.WriteRequest{
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			{
+				User:     "user:*",
+				Relation: "holder",
+				Object:   "share_token:1-token",
+				Condition: &openfgav1.RelationshipCondition{
+					Name: "share_token_valid",
+					Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+						"token_hash": structpb.NewStringValue("hash"),
+						"expires":    structpb.NewStringValue(time.Now().Add(time.Hour).UTC().Format(time.RFC3339)),
+					}},
+				},
+			},
+			{User: "share_token:1-token#holder", Relation: "link_viewer", Object: "dashboard:1-dash"},
+		}},
+	})
+	require.NoError(t, err)
+
+	check := func(ctx context.Context) bool {
+		res, err := c.Check(ctx, &openfgav1.CheckRequest{
+			TupleKey: &openfgav1.CheckRequestTupleKey{User: "user:anonymous", Relation: "read", Object: "dashboard:1-dash"},
+		})
+		require.NoError(t, err)
+		return res.GetAllowed()
+	}
+
+	assert.False(t, check(context.Background()))
+	assert.False(t, check(WithShareTokenHash(context.Background(), "other")))
+	assert.True(t, check(WithShareTokenHash(context.Background(), "hash")))
+}
+
+func TestWriteCache(t *testing.T) {
+	condition := func(ids ...string) *openfgav1.RelationshipCondition {
+		values := make([]*structpb.Value, 0, len(ids))
+		for _, id := range ids {
+			values = append(values, structpb.NewStringValue(id))
+		}
+		return &openfgav1.RelationshipCondition{
+			Name:    "folder_filter",
+			Context: &structpb.Struct{Fields: map[string]*structpb.Value{"ids": structpb.NewListValue(&structpb.ListValue{Values: values})}},
+		}
+	}
+	tuple := func(c *openfgav1.RelationshipCondition) *openfgav1.TupleKey {
+		return &openfgav1.TupleKey{User: "user:1", Relation: "member", Object: "team:1", Condition: c}
+	}
+
+	cache := newWriteCache(10, time.Minute)
+	cache.add(tuple(condition("a")))
+	assert.True(t, cache.has(tuple(condition("a"))))
+	assert.False(t, cache.has(tuple(condition("b"))), "a tuple with another condition context must be written")
+	assert.False(t, cache.has(tuple(nil)), "a tuple without condition must be written")
+
+	cache.add(tuple(nil))
+	assert.True(t, cache.has(tuple(nil)))
+	assert.False(t, cache.has(tuple(condition("a"))), "the tuple is stored once, with the last condition written")
+
+	cache.remove(&openfgav1.TupleKeyWithoutCondition{User: "user:1", Relation: "member", Object: "team:1"})
+	assert.False(t, cache.has(tuple(nil)))
+}
+
+func TestClient_InvalidateIDs(t *testing.T) {
+	c := &Client{logger: log.NewNopLogger(), storeID: "store", modelID: "model"}
+
+	c.invalidateIDs("store", status.Error(codes.Unavailable, "unavailable"))
+	assert.Equal(t, "store", c.storeID)
+
+	// Ids resolved again since the failed call are kept
+	c.invalidateIDs("previous", status.Error(codes.NotFound, "store not found"))
+	assert.Equal(t, "store", c.storeID)
+
+	c.writeCache = newWriteCache(10, time.Minute)
+	written := &openfgav1.TupleKey{User: "user:1", Relation: "member", Object: "team:1"}
+	c.writeCache.add(written)
+
+	c.invalidateIDs("store", status.Error(codes.NotFound, "store not found"))
+	assert.Empty(t, c.storeID)
+	assert.Empty(t, c.modelID)
+	assert.False(t, c.writeCache.has(written), "tuples written to the missing store must be written again")
+}
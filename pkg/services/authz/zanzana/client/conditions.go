@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Parameters of the conditions of the schema that are set by the client, see the share_token type.
+const (
+	ConditionParamRequestTokenHash = "request_token_hash"
+	ConditionParamCurrentTime      = "current_time"
+)
+
+type shareTokenHashKey struct{}
+
+// WithShareTokenHash returns a context making the checks and lists grant the access shared with the token.
+func WithShareTokenHash(ctx context.Context, hash string) context.Context {
+	if hash == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, shareTokenHashKey{}, hash)
+}
+
+// conditionContext returns the context of a check or list made with ctx. OpenFGA fails the evaluation of conditional
+// tuples when parameters are missing, so the parameters set by the client are always present. Parameters set by the
+// caller are kept.
+func conditionContext(ctx context.Context, in *structpb.Struct) *structpb.Struct {
+	hash, _ := ctx.Value(shareTokenHashKey{}).(string)
+	fields := map[string]*structpb.Value{
+		// An empty hash never matches the hash of a token
+		ConditionParamRequestTokenHash: structpb.NewStringValue(hash),
+		ConditionParamCurrentTime:      structpb.NewStringValue(time.Now().UTC().Format(time.RFC3339)),
+	}
+	for name, value := range in.GetFields() {
+		fields[name] = value
+	}
+	return &structpb.Struct{Fields: fields}
+}
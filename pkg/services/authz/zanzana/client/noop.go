@@ -24,6 +24,10 @@ func (nc NoopClient) ListObjects(ctx context.Context, in *openfgav1.ListObjectsR
 	return nil, nil
 }
 
+func (nc NoopClient) ListUsers(ctx context.Context, in *openfgav1.ListUsersRequest) (*openfgav1.ListUsersResponse, error) {
+	return nil, nil
+}
+
 func (nc NoopClient) Write(ctx context.Context, in *openfgav1.WriteRequest) error {
 	return nil
 }
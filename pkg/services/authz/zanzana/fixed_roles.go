@@ -0,0 +1,56 @@
+package zanzana
+
+import (
+	"strconv"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+)
+
+// schemaFixedRoles are the fixed roles defined as relations of orgs in the schema. Their permissions are part of the
+// schema, only their assignments are written.
+var schemaFixedRoles = map[string]struct{}{
+	"fixed_dashboards_creator":            {},
+	"fixed_dashboards_reader":             {},
+	"fixed_dashboards_writer":             {},
+	"fixed_dashboards_insights_reader":    {},
+	"fixed_dashboards_public_writer":      {},
+	"fixed_dashboards_permissions_reader": {},
+	"fixed_dashboards_permissions_writer": {},
+	"fixed_folders_creator":               {},
+	"fixed_folders_reader":                {},
+	"fixed_folders_writer":                {},
+	"fixed_folders_permissions_reader":    {},
+	"fixed_folders_permissions_writer":    {},
+	"fixed_folders_general_reader":        {},
+}
+
+// IsSchemaFixedRole returns true when the fixed role, e.g. fixed:dashboards:writer, is a relation of orgs in the schema.
+func IsSchemaFixedRole(role string) bool {
+	_, ok := schemaFixedRoles[TranslateFixedRole(role)]
+	return ok
+}
+
+// GenerateFixedRoleResource returns the entry of the fixed or plugin role in the org, followed by relation when set.
+// The fixed roles that are not part of the schema are written as roles of every org together with the tuples of
+// their permissions, like the roles stored in the database.
+func GenerateFixedRoleResource(role string, orgID int64, relation string) string {
+	return NewScopedTupleEntry(TypeRole, TranslateFixedRole(role), relation, strconv.FormatInt(orgID, 10))
+}
+
+// TranslateFixedRoleAssignment returns the tuple assigning the fixed or plugin role of the org to subject, e.g.
+// user:<uid> or role:<org>-basic_viewer#assignee. Fixed roles of the schema are assigned with their relation on the
+// org, the other ones with the assignee relation of their role.
+func TranslateFixedRoleAssignment(subject, role string, orgID int64) *openfgav1.TupleKey {
+	if IsSchemaFixedRole(role) {
+		return &openfgav1.TupleKey{
+			User:     subject,
+			Relation: TranslateFixedRole(role),
+			Object:   NewTupleEntry(TypeOrg, strconv.FormatInt(orgID, 10), ""),
+		}
+	}
+	return &openfgav1.TupleKey{
+		User:     subject,
+		Relation: RelationAssignee,
+		Object:   GenerateFixedRoleResource(role, orgID, ""),
+	}
+}
@@ -15,6 +15,15 @@ var dashboardDSL string
 //go:embed folder.fga
 var folderDSL string
 
+//go:embed alert_rule.fga
+var alertRuleDSL string
+
+//go:embed library_panel.fga
+var libraryPanelDSL string
+
+//go:embed plugin_resource.fga
+var pluginResourceDSL string
+
 var SchemaModules = []transformer.ModuleFile{
 	{
 		Name:     "core.fga",
@@ -28,4 +37,16 @@ var SchemaModules = []transformer.ModuleFile{
 		Name:     "folder.fga",
 		Contents: folderDSL,
 	},
+	{
+		Name:     "alert_rule.fga",
+		Contents: alertRuleDSL,
+	},
+	{
+		Name:     "library_panel.fga",
+		Contents: libraryPanelDSL,
+	},
+	{
+		Name:     "plugin_resource.fga",
+		Contents: pluginResourceDSL,
+	},
 }
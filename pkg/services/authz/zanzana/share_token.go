@@ -0,0 +1,118 @@
+package zanzana
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// TypeShareToken is the type of the tokens sharing a resource with anyone holding a link
+	TypeShareToken string = "share_token"
+
+	RelationShareTokenHolder string = "holder"
+	RelationLinkViewer       string = "link_viewer"
+
+	// ConditionShareToken grants the access of a share token to the checks made with the hash of the token
+	// until the token expires.
+	ConditionShareToken string = "share_token_valid"
+)
+
+// shareTokenKinds are the kinds of resources with a link_viewer relation, they can be shared with a token
+var shareTokenKinds = map[string]bool{
+	KindDashboards: true,
+}
+
+// SupportsShareTokens returns true when the resources of the kind can be shared with a token.
+func SupportsShareTokens(kind string) bool {
+	return shareTokenKinds[kind]
+}
+
+// NewShareTokenObject returns the object of the share token with the id.
+func NewShareTokenObject(id string, orgID int64) string {
+	return NewScopedTupleEntry(TypeShareToken, id, "", strconv.FormatInt(orgID, 10))
+}
+
+// NewShareTokenTuples returns the tuples granting read access to the resource of the kind with the identifier to
+// the checks made with the hash of the token until it expires. All users are holders of the token, the condition
+// restricts them to the requests made with the token. The second return value is false when the kind can't be shared.
+func NewShareTokenTuples(id, tokenHash string, expires time.Time, kind, identifier string, orgID int64) ([]*openfgav1.TupleKey, bool) {
+	if !SupportsShareTokens(kind) {
+		return nil, false
+	}
+	object, ok := TranslateToObject(kind, identifier, orgID)
+	if !ok {
+		return nil, false
+	}
+
+	token := NewShareTokenObject(id, orgID)
+	return []*openfgav1.TupleKey{
+		{
+			User:     NewTupleEntry(TypeUser, "*", ""),
+			Relation: RelationShareTokenHolder,
+			Object:   token,
+			Condition: &openfgav1.RelationshipCondition{
+				Name: ConditionShareToken,
+				Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+					"token_hash": structpb.NewStringValue(tokenHash),
+					"expires":    structpb.NewStringValue(expires.UTC().Format(time.RFC3339)),
+				}},
+			},
+		},
+		{
+			User:     NewScopedTupleEntry(TypeShareToken, id, RelationShareTokenHolder, strconv.FormatInt(orgID, 10)),
+			Relation: RelationLinkViewer,
+			Object:   object,
+		},
+	}, true
+}
+
+// NewShareTokenDeletes returns the tuples of the share token with the id sharing the resource of the kind with the
+// identifier, deleting them revokes the token. The second return value is false when the kind can't be shared.
+func NewShareTokenDeletes(id, kind, identifier string, orgID int64) ([]*openfgav1.TupleKeyWithoutCondition, bool) {
+	if !SupportsShareTokens(kind) {
+		return nil, false
+	}
+	object, ok := TranslateToObject(kind, identifier, orgID)
+	if !ok {
+		return nil, false
+	}
+
+	return []*openfgav1.TupleKeyWithoutCondition{
+		{
+			User:     NewTupleEntry(TypeUser, "*", ""),
+			Relation: RelationShareTokenHolder,
+			Object:   NewShareTokenObject(id, orgID),
+		},
+		{
+			User:     NewScopedTupleEntry(TypeShareToken, id, RelationShareTokenHolder, strconv.FormatInt(orgID, 10)),
+			Relation: RelationLinkViewer,
+			Object:   object,
+		},
+	}, true
+}
+
+// ShareTokenID returns the id of the share token of the holder user of a link_viewer tuple, see NewShareTokenTuples.
+// The second return value is false when the user isn't the holder of a share token of the org.
+func ShareTokenID(user string, orgID int64) (string, bool) {
+	rest, ok := strings.CutPrefix(user, TypeShareToken+":"+strconv.FormatInt(orgID, 10)+"-")
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(rest, "#"+RelationShareTokenHolder)
+}
+
+// ShareTokenExpiry returns the expiry recorded in the condition of the holder tuple of a share token.
+func ShareTokenExpiry(tuple *openfgav1.TupleKey) (time.Time, bool) {
+	if tuple.GetCondition().GetName() != ConditionShareToken {
+		return time.Time{}, false
+	}
+	expires, err := time.Parse(time.RFC3339, tuple.GetCondition().GetContext().GetFields()["expires"].GetStringValue())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expires, true
+}
@@ -1,8 +1,16 @@
 package zanzana
 
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
 type actionKindTranslation struct {
-	objectType   string
-	orgScoped    bool
+	objectType string
+	orgScoped  bool
+	// kindScoped prefixes the identifier of the objects with their kind, for object types shared by several kinds
+	kindScoped   bool
 	translations map[string]string
 }
 
@@ -46,6 +54,22 @@ var dashboardActions = map[string]string{
 	"dashboards.permissions:write": "permissions_write",
 }
 
+var alertRuleActions = map[string]string{
+	"alert.rules:read":              "read",
+	"alert.rules:write":             "write",
+	"alert.rules:delete":            "delete",
+	"alert.rules.permissions:read":  "permissions_read",
+	"alert.rules.permissions:write": "permissions_write",
+}
+
+var libraryPanelActions = map[string]string{
+	"library.panels:read":              "read",
+	"library.panels:write":             "write",
+	"library.panels:delete":            "delete",
+	"library.panels.permissions:read":  "permissions_read",
+	"library.panels.permissions:write": "permissions_write",
+}
+
 var orgActions = map[string]string{
 	"folders:create":            "folder_create",
 	"folders:read":              "folder_read",
@@ -66,11 +90,17 @@ var orgActions = map[string]string{
 	"library.panels:write":  "library_panel_write",
 	"library.panels:delete": "library_panel_delete",
 
+	"library.panels.permissions:read":  "library_panel_permissions_read",
+	"library.panels.permissions:write": "library_panel_permissions_write",
+
 	"alert.rules:create": "alert_rule_create",
 	"alert.rules:read":   "alert_rule_read",
 	"alert.rules:write":  "alert_rule_write",
 	"alert.rules:delete": "alert_rule_delete",
 
+	"alert.rules.permissions:read":  "alert_rule_permissions_read",
+	"alert.rules.permissions:write": "alert_rule_permissions_write",
+
 	"alert.silences:create": "alert_silence_create",
 	"alert.silences:read":   "alert_silence_read",
 	"alert.silences:write":  "alert_silence_write",
@@ -93,6 +123,77 @@ var actionKindTranslations = map[string]actionKindTranslation{
 		orgScoped:    true,
 		translations: dashboardActions,
 	},
+	KindAlertRules: {
+		objectType:   TypeAlertRule,
+		orgScoped:    true,
+		translations: alertRuleActions,
+	},
+	KindLibraryPanels: {
+		objectType:   TypeLibraryPanel,
+		orgScoped:    true,
+		translations: libraryPanelActions,
+	},
+}
+
+// Relations of the plugin_resource type, ordered from the lowest level of access
+var pluginResourceRelations = []string{"view", "edit", "admin"}
+
+var (
+	pluginKindTranslationsMu sync.RWMutex
+	// RBAC to OpenFGA translations of the resources registered by plugins at runtime
+	pluginKindTranslations = map[string]actionKindTranslation{}
+)
+
+// RegisterPluginResourceKind maps the actions on the resources of kind, registered by an app plugin, to the view, edit
+// and admin relations of the plugin_resource type. Registering a kind again replaces its translations.
+func RegisterPluginResourceKind(kind string, actionsToRelations map[string]string) error {
+	if _, ok := actionKindTranslations[kind]; ok {
+		return fmt.Errorf("kind %s is already part of the schema", kind)
+	}
+
+	translations := make(map[string]string, len(actionsToRelations))
+	for action, relation := range actionsToRelations {
+		if !slices.Contains(pluginResourceRelations, relation) {
+			return fmt.Errorf("%w: %s is mapped to %s, expected one of %v", ErrUnmappedAction, action, relation, pluginResourceRelations)
+		}
+		translations[action] = relation
+	}
+
+	pluginKindTranslationsMu.Lock()
+	defer pluginKindTranslationsMu.Unlock()
+	pluginKindTranslations[kind] = actionKindTranslation{
+		objectType:   TypePluginResource,
+		orgScoped:    true,
+		kindScoped:   true,
+		translations: translations,
+	}
+	return nil
+}
+
+// kindTranslation returns the translations of kind, from the schema or registered by a plugin.
+func kindTranslation(kind string) (actionKindTranslation, bool) {
+	if typeTranslation, ok := actionKindTranslations[kind]; ok {
+		return typeTranslation, true
+	}
+
+	pluginKindTranslationsMu.RLock()
+	defer pluginKindTranslationsMu.RUnlock()
+	typeTranslation, ok := pluginKindTranslations[kind]
+	return typeTranslation, ok
+}
+
+// Kinds of the containers of the object types. Access granted on a container is inherited by the objects it contains
+// through the container relation prefixed by their type, dashboard_read on a folder grants read on its dashboards.
+var resourceContainers = map[string]string{
+	TypeDashboard:    KindFolders,
+	TypeLibraryPanel: KindFolders,
+	TypeAlertRule:    KindFolders,
+}
+
+// Object types whose deny permissions are written as a deny tuple. The deny relation excludes its subjects from the
+// other relations of the object, including the ones inherited from its container.
+var denyTypes = map[string]struct{}{
+	TypeDashboard: {},
 }
 
 var basicRolesTranslations = map[string]string{
@@ -1,6 +1,7 @@
 package zanzana
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,26 +10,36 @@ import (
 )
 
 const (
-	TypeUser      string = "user"
-	TypeTeam      string = "team"
-	TypeRole      string = "role"
-	TypeFolder    string = "folder"
-	TypeDashboard string = "dashboard"
-	TypeOrg       string = "org"
+	TypeUser         string = "user"
+	TypeTeam         string = "team"
+	TypeGroup        string = "group"
+	TypeRole         string = "role"
+	TypeFolder       string = "folder"
+	TypeDashboard    string = "dashboard"
+	TypeOrg          string = "org"
+	TypeAlertRule    string = "alert_rule"
+	TypeLibraryPanel string = "library_panel"
+
+	// TypePluginResource is the type of the objects of all the resources registered by app plugins
+	TypePluginResource string = "plugin_resource"
 )
 
 const (
-	RelationTeamMember string = "member"
-	RelationTeamAdmin  string = "admin"
-	RelationParent     string = "parent"
-	RelationAssignee   string = "assignee"
-	RelationOrg        string = "org"
+	RelationTeamMember  string = "member"
+	RelationTeamAdmin   string = "admin"
+	RelationGroupMember string = "member"
+	RelationParent      string = "parent"
+	RelationAssignee    string = "assignee"
+	RelationOrg         string = "org"
+	RelationDeny        string = "deny"
 )
 
 const (
-	KindOrg        string = "org"
-	KindDashboards string = "dashboards"
-	KindFolders    string = "folders"
+	KindOrg           string = "org"
+	KindDashboards    string = "dashboards"
+	KindFolders       string = "folders"
+	KindAlertRules    string = "alert.rules"
+	KindLibraryPanels string = "library.panels"
 )
 
 const (
@@ -44,6 +55,13 @@ const (
 	GlobalOrgID = 0
 )
 
+var (
+	// ErrUnknownKind is returned when a kind of resource is not part of the schema.
+	ErrUnknownKind = errors.New("kind is not part of the schema")
+	// ErrUnmappedAction is returned when an action on a kind of resource that is part of the schema has no relation.
+	ErrUnmappedAction = errors.New("action is not mapped to a relation")
+)
+
 // NewTupleEntry constructs new openfga entry type:id[#relation].
 // Relation allows to specify group of users (subjects) related to type:id
 // (for example, team:devs#member refers to users which are members of team devs)
@@ -62,7 +80,7 @@ func NewScopedTupleEntry(objectType, id, relation, scope string) string {
 }
 
 func TranslateToTuple(user string, action, kind, identifier string, orgID int64) (*openfgav1.TupleKey, bool) {
-	typeTranslation, ok := actionKindTranslations[kind]
+	typeTranslation, ok := kindTranslation(kind)
 	if !ok {
 		return nil, false
 	}
@@ -78,15 +96,30 @@ func TranslateToTuple(user string, action, kind, identifier string, orgID int64)
 
 	tuple.User = user
 	tuple.Relation = relation
+	tuple.Object = translateToObject(typeTranslation, kind, identifier, orgID)
+
+	return tuple, true
+}
+
+// TranslateToObject returns the object of the resource of kind with identifier.
+func TranslateToObject(kind, identifier string, orgID int64) (string, bool) {
+	typeTranslation, ok := kindTranslation(kind)
+	if !ok {
+		return "", false
+	}
+	return translateToObject(typeTranslation, kind, identifier, orgID), true
+}
+
+func translateToObject(typeTranslation actionKindTranslation, kind, identifier string, orgID int64) string {
+	if typeTranslation.kindScoped {
+		identifier = fmt.Sprintf("%s/%s", kind, identifier)
+	}
 
 	// Some uid:s in grafana are not guarantee to be unique across orgs so we need to scope them.
 	if typeTranslation.orgScoped {
-		tuple.Object = NewScopedTupleEntry(typeTranslation.objectType, identifier, "", strconv.FormatInt(orgID, 10))
-	} else {
-		tuple.Object = NewTupleEntry(typeTranslation.objectType, identifier, "")
+		return NewScopedTupleEntry(typeTranslation.objectType, identifier, "", strconv.FormatInt(orgID, 10))
 	}
-
-	return tuple, true
+	return NewTupleEntry(typeTranslation.objectType, identifier, "")
 }
 
 func TranslateToOrgTuple(user string, action string, orgID int64) (*openfgav1.TupleKey, bool) {
@@ -109,10 +142,87 @@ func TranslateToOrgTuple(user string, action string, orgID int64) (*openfgav1.Tu
 	return tuple, true
 }
 
+// TranslateKindToType returns the object type of the resources of kind.
+func TranslateKindToType(kind string) (string, bool) {
+	typeTranslation, ok := kindTranslation(kind)
+	if !ok {
+		return "", false
+	}
+	return typeTranslation.objectType, true
+}
+
+// SupportsDeny returns true when deny permissions on resources of kind translate to a deny tuple.
+func SupportsDeny(kind string) bool {
+	objectType, ok := TranslateKindToType(kind)
+	return ok && HasDenyRelation(objectType)
+}
+
+// HasDenyRelation returns true when the deny tuples of objects of objectType are written, access to them then needs
+// to be checked against the deny relation before being inherited from their container.
+func HasDenyRelation(objectType string) bool {
+	_, ok := denyTypes[objectType]
+	return ok
+}
+
+// TranslateToContainerTuple translates the relation on an object of objectType into the relation granting it on the
+// container identified by containerKind and containerID. It returns false when objects of the type are not stored in
+// containers of the kind.
+func TranslateToContainerTuple(user, relation, objectType, containerKind, containerID string, orgID int64) (*openfgav1.TupleKey, bool) {
+	if kind, ok := resourceContainers[objectType]; !ok || kind != containerKind {
+		return nil, false
+	}
+
+	containerType, ok := TranslateKindToType(containerKind)
+	if !ok {
+		return nil, false
+	}
+
+	return &openfgav1.TupleKey{
+		User:     user,
+		Relation: TranslateToFolderRelation(relation, objectType),
+		Object:   NewScopedTupleEntry(containerType, containerID, "", strconv.FormatInt(orgID, 10)),
+	}, true
+}
+
+// ValidateAction returns an error when a permission with action on a resource of kind can't be translated by TranslateToTuple.
+func ValidateAction(action, kind string) error {
+	typeTranslation, ok := kindTranslation(kind)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	if _, ok := typeTranslation.translations[action]; !ok {
+		return fmt.Errorf("%w: %s on %s", ErrUnmappedAction, action, kind)
+	}
+
+	return nil
+}
+
+// ValidateOrgAction returns an error when a permission with action on all resources of kind can't be translated
+// by TranslateToOrgTuple.
+func ValidateOrgAction(action, kind string) error {
+	if _, ok := kindTranslation(kind); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+	return ValidateAction(action, KindOrg)
+}
+
 func TranslateBasicRole(role string) string {
 	return basicRolesTranslations[role]
 }
 
+// GenerateBasicRoleResource returns the entry of the basic role in the org, followed by relation when set. Basic roles,
+// Grafana Admin included, are defined in every org: server admins are assignees of the Grafana Admin role of each org
+// they are member of. There is no role in the global org: managed permissions and basic role assignments are always
+// stored in an org, anything found in the global org has no tuple.
+func GenerateBasicRoleResource(role string, orgID int64, relation string) (string, bool) {
+	basicRole := TranslateBasicRole(role)
+	if basicRole == "" || orgID == GlobalOrgID {
+		return "", false
+	}
+	return NewScopedTupleEntry(TypeRole, basicRole, relation, strconv.FormatInt(orgID, 10)), true
+}
+
 func TranslateFixedRole(role string) string {
 	role = strings.ReplaceAll(role, ":", "_")
 	role = strings.ReplaceAll(role, ".", "_")
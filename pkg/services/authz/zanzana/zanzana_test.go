@@ -0,0 +1,111 @@
+package zanzana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateToContainerTuple(t *testing.T) {
+	t.Run("should translate relation of contained types to the folder relation", func(t *testing.T) {
+		for objectType, relation := range map[string]string{
+			TypeDashboard:    "dashboard_read",
+			TypeLibraryPanel: "library_panel_read",
+			TypeAlertRule:    "alert_rule_read",
+		} {
+			tuple, ok := TranslateToContainerTuple("user:1", "read", objectType, KindFolders, "f1", 2)
+			require.True(t, ok)
+			assert.Equal(t, "user:1", tuple.User)
+			assert.Equal(t, relation, tuple.Relation)
+			assert.Equal(t, "folder:2-f1", tuple.Object)
+		}
+	})
+
+	t.Run("should not translate types without container", func(t *testing.T) {
+		_, ok := TranslateToContainerTuple("user:1", "read", TypeFolder, KindFolders, "f1", 2)
+		assert.False(t, ok)
+	})
+
+	t.Run("should not translate containers of another kind", func(t *testing.T) {
+		_, ok := TranslateToContainerTuple("user:1", "read", TypeDashboard, KindDashboards, "d1", 2)
+		assert.False(t, ok)
+	})
+}
+
+func TestTranslateToTuple(t *testing.T) {
+	t.Run("should translate alert rule actions to alert rule relations", func(t *testing.T) {
+		tuple, ok := TranslateToTuple("user:1", "alert.rules.permissions:write", KindAlertRules, "r1", 2)
+		require.True(t, ok)
+		assert.Equal(t, "permissions_write", tuple.Relation)
+		assert.Equal(t, "alert_rule:2-r1", tuple.Object)
+	})
+
+	t.Run("should not translate alert rule creation on a rule", func(t *testing.T) {
+		_, ok := TranslateToTuple("user:1", "alert.rules:create", KindAlertRules, "r1", 2)
+		assert.False(t, ok)
+	})
+
+	t.Run("should translate library panel actions to library panel relations", func(t *testing.T) {
+		tuple, ok := TranslateToTuple("user:1", "library.panels:delete", KindLibraryPanels, "p1", 2)
+		require.True(t, ok)
+		assert.Equal(t, "delete", tuple.Relation)
+		assert.Equal(t, "library_panel:2-p1", tuple.Object)
+	})
+}
+
+func TestRegisterPluginResourceKind(t *testing.T) {
+	err := RegisterPluginResourceKind("test-app.things", map[string]string{
+		"test-app.things:read":              "view",
+		"test-app.things:write":             "edit",
+		"test-app.things.permissions:write": "admin",
+	})
+	require.NoError(t, err)
+
+	t.Run("should translate plugin actions to plugin resource relations", func(t *testing.T) {
+		tuple, ok := TranslateToTuple("user:1", "test-app.things:write", "test-app.things", "t1", 2)
+		require.True(t, ok)
+		assert.Equal(t, "edit", tuple.Relation)
+		assert.Equal(t, "plugin_resource:2-test-app.things/t1", tuple.Object)
+
+		objectType, ok := TranslateKindToType("test-app.things")
+		require.True(t, ok)
+		assert.Equal(t, TypePluginResource, objectType)
+	})
+
+	t.Run("should not translate actions that are not registered", func(t *testing.T) {
+		assert.ErrorIs(t, ValidateAction("test-app.things:delete", "test-app.things"), ErrUnmappedAction)
+	})
+
+	t.Run("should reject relations that are not part of the plugin resource type", func(t *testing.T) {
+		err := RegisterPluginResourceKind("test-app.other", map[string]string{"test-app.other:read": "read"})
+		assert.ErrorIs(t, err, ErrUnmappedAction)
+	})
+
+	t.Run("should reject kinds that are part of the schema", func(t *testing.T) {
+		err := RegisterPluginResourceKind(KindDashboards, map[string]string{"dashboards:read": "view"})
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateBasicRoleResource(t *testing.T) {
+	t.Run("should scope Grafana Admin to the org like the other basic roles", func(t *testing.T) {
+		role, ok := GenerateBasicRoleResource(RoleGrafanaAdmin, 2, RelationAssignee)
+		require.True(t, ok)
+		assert.Equal(t, "role:2-basic_grafana_admin#assignee", role)
+
+		role, ok = GenerateBasicRoleResource(RoleViewer, 2, "")
+		require.True(t, ok)
+		assert.Equal(t, "role:2-basic_viewer", role)
+	})
+
+	t.Run("should not generate roles in the global org", func(t *testing.T) {
+		_, ok := GenerateBasicRoleResource(RoleGrafanaAdmin, GlobalOrgID, RelationAssignee)
+		assert.False(t, ok)
+	})
+
+	t.Run("should not generate unknown roles", func(t *testing.T) {
+		_, ok := GenerateBasicRoleResource("Owner", 2, "")
+		assert.False(t, ok)
+	})
+}
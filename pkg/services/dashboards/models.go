@@ -206,6 +206,9 @@ type SaveDashboardCommand struct {
 	FolderID  int64  `json:"folderId" xorm:"folder_id"`
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
 	IsFolder  bool   `json:"isFolder"`
+	// PruneRedundantPermissions removes the permissions set directly on a dashboard moved to another folder that
+	// the new folder already grants
+	PruneRedundantPermissions bool `json:"pruneRedundantPermissions" xorm:"-"`
 
 	UpdatedAt time.Time
 }
@@ -297,6 +300,8 @@ type SaveDashboardDTO struct {
 	Message   string
 	Overwrite bool
 	Dashboard *Dashboard
+	// PruneRedundantPermissions removes the direct permissions the new folder of a moved dashboard already grants
+	PruneRedundantPermissions bool
 }
 
 type DashboardSearchProjection struct {
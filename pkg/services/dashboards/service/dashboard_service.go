@@ -20,7 +20,6 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
-	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -60,6 +59,8 @@ type DashboardServiceImpl struct {
 	dashboardPermissions accesscontrol.DashboardPermissionsService
 	ac                   accesscontrol.AccessControl
 	metrics              *dashboardsMetrics
+	// shareTokens revokes the share tokens of the deleted dashboards, nil when share tokens are not supported
+	shareTokens accesscontrol.ShareTokenRevoker
 }
 
 // This is the uber service that implements a three smaller services
@@ -67,7 +68,7 @@ func ProvideDashboardServiceImpl(
 	cfg *setting.Cfg, dashboardStore dashboards.Store, folderStore folder.FolderStore,
 	features featuremgmt.FeatureToggles, folderPermissionsService accesscontrol.FolderPermissionsService,
 	dashboardPermissionsService accesscontrol.DashboardPermissionsService, ac accesscontrol.AccessControl,
-	folderSvc folder.Service, fStore folder.Store, r prometheus.Registerer,
+	folderSvc folder.Service, fStore folder.Store, r prometheus.Registerer, shareTokens accesscontrol.ShareTokenRevoker,
 ) (*DashboardServiceImpl, error) {
 	dashSvc := &DashboardServiceImpl{
 		cfg:                  cfg,
@@ -80,6 +81,7 @@ func ProvideDashboardServiceImpl(
 		folderStore:          folderStore,
 		folderService:        folderSvc,
 		metrics:              newDashboardsMetrics(r),
+		shareTokens:          shareTokens,
 	}
 
 	ac.RegisterScopeAttributeResolver(dashboards.NewDashboardIDScopeResolver(folderStore, dashSvc, fStore))
@@ -104,9 +106,18 @@ func (dr *DashboardServiceImpl) GetProvisionedDashboardDataByDashboardUID(ctx co
 	return dr.dashboardStore.GetProvisionedDataByDashboardUID(ctx, orgID, dashboardUID)
 }
 
-//nolint:gocyclo
 func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, dto *dashboards.SaveDashboardDTO,
 	validateProvisionedDashboard bool) (*dashboards.SaveDashboardCommand, error) {
+	cmd, _, err := dr.buildSaveDashboardCommand(ctx, dto, validateProvisionedDashboard)
+	return cmd, err
+}
+
+// buildSaveDashboardCommand returns the command saving the dashboard and whether the dashboard moved to another
+// folder.
+//
+//nolint:gocyclo
+func (dr *DashboardServiceImpl) buildSaveDashboardCommand(ctx context.Context, dto *dashboards.SaveDashboardDTO,
+	validateProvisionedDashboard bool) (*dashboards.SaveDashboardCommand, bool, error) {
 	ctx, span := tracer.Start(ctx, "dashboards.service.BuildSaveDashboardcommand")
 	defer span.End()
 
@@ -118,34 +129,34 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 	dash.SetUID(strings.TrimSpace(dash.UID))
 
 	if dash.Title == "" {
-		return nil, dashboards.ErrDashboardTitleEmpty
+		return nil, false, dashboards.ErrDashboardTitleEmpty
 	}
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
 	// nolint:staticcheck
 	if dash.IsFolder && dash.FolderID > 0 {
-		return nil, dashboards.ErrDashboardFolderCannotHaveParent
+		return nil, false, dashboards.ErrDashboardFolderCannotHaveParent
 	}
 
 	if dash.IsFolder && strings.EqualFold(dash.Title, dashboards.RootFolderName) {
-		return nil, dashboards.ErrDashboardFolderNameExists
+		return nil, false, dashboards.ErrDashboardFolderNameExists
 	}
 
 	if !util.IsValidShortUID(dash.UID) {
-		return nil, dashboards.ErrDashboardInvalidUid
+		return nil, false, dashboards.ErrDashboardInvalidUid
 	} else if util.IsShortUIDTooLong(dash.UID) {
-		return nil, dashboards.ErrDashboardUidTooLong
+		return nil, false, dashboards.ErrDashboardUidTooLong
 	}
 
 	if err := validateDashboardRefreshInterval(dr.cfg.MinRefreshInterval, dash); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Validate folder
 	if dash.FolderUID != "" {
 		folder, err := dr.folderStore.GetFolderByUID(ctx, dash.OrgID, dash.FolderUID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
 		// nolint:staticcheck
@@ -155,46 +166,46 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		// nolint:staticcheck
 		folder, err := dr.folderStore.GetFolderByID(ctx, dash.OrgID, dash.FolderID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		dash.FolderUID = folder.UID
 	}
 
 	isParentFolderChanged, err := dr.dashboardStore.ValidateDashboardBeforeSave(ctx, dash, dto.Overwrite)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if isParentFolderChanged {
 		// Check that the user is allowed to add a dashboard to the folder
 		guardian, err := guardian.NewByDashboard(ctx, dash, dto.OrgID, dto.User)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
 		// nolint:staticcheck
 		if canSave, err := guardian.CanCreate(dash.FolderID, dash.IsFolder); err != nil || !canSave {
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			return nil, dashboards.ErrDashboardUpdateAccessDenied
+			return nil, false, dashboards.ErrDashboardUpdateAccessDenied
 		}
 	}
 
 	if validateProvisionedDashboard {
 		provisionedData, err := dr.GetProvisionedDashboardDataByDashboardID(ctx, dash.ID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		if provisionedData != nil {
-			return nil, dashboards.ErrDashboardCannotSaveProvisionedDashboard
+			return nil, false, dashboards.ErrDashboardCannotSaveProvisionedDashboard
 		}
 	}
 
 	guard, err := getGuardianForSavePermissionCheck(ctx, dash, dto.User)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if dash.ID == 0 {
@@ -202,16 +213,16 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		// nolint:staticcheck
 		if canCreate, err := guard.CanCreate(dash.FolderID, dash.IsFolder); err != nil || !canCreate {
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			return nil, dashboards.ErrDashboardUpdateAccessDenied
+			return nil, false, dashboards.ErrDashboardUpdateAccessDenied
 		}
 	} else {
 		if canSave, err := guard.CanSave(); err != nil || !canSave {
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			return nil, dashboards.ErrDashboardUpdateAccessDenied
+			return nil, false, dashboards.ErrDashboardUpdateAccessDenied
 		}
 	}
 
@@ -239,7 +250,7 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		cmd.UpdatedAt = dto.UpdatedAt
 	}
 
-	return cmd, nil
+	return cmd, isParentFolderChanged, nil
 }
 
 func (dr *DashboardServiceImpl) DeleteOrphanedProvisionedDashboards(ctx context.Context, cmd *dashboards.DeleteOrphanedProvisionedDashboardsCommand) error {
@@ -356,11 +367,19 @@ func (dr *DashboardServiceImpl) SaveDashboard(ctx context.Context, dto *dashboar
 		dto.Dashboard.Data.Set("refresh", dr.cfg.MinRefreshInterval)
 	}
 
-	cmd, err := dr.BuildSaveDashboardCommand(ctx, dto, !allowUiUpdate)
+	cmd, isParentFolderChanged, err := dr.buildSaveDashboardCommand(ctx, dto, !allowUiUpdate)
 	if err != nil {
 		return nil, err
 	}
 
+	// The folder the dashboard moves from is read before the dashboard is saved in the new one
+	var previous *dashboards.Dashboard
+	if isParentFolderChanged && dto.Dashboard.ID != 0 && !dto.Dashboard.IsFolder && dr.dashboardPermissions != nil {
+		if previous, err = dr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dto.Dashboard.ID, OrgID: dto.OrgID}); err != nil {
+			return nil, err
+		}
+	}
+
 	dash, err := dr.dashboardStore.SaveDashboard(ctx, *cmd)
 	if err != nil {
 		return nil, fmt.Errorf("saving dashboard failed: %w", err)
@@ -371,8 +390,28 @@ func (dr *DashboardServiceImpl) SaveDashboard(ctx context.Context, dto *dashboar
 		dr.setDefaultPermissions(ctx, dto, dash, false)
 	}
 
+	if previous != nil && previous.FolderUID != dash.FolderUID {
+		dr.moveDashboardPermissions(ctx, dto, dash, previous.FolderUID)
+	}
+
 	return dash, nil
 }
+
+// moveDashboardPermissions updates the permissions of a dashboard moved to another folder. The dashboard is saved,
+// failing to update its permissions only leaves the direct permissions its new folder already grants.
+func (dr *DashboardServiceImpl) moveDashboardPermissions(ctx context.Context, dto *dashboards.SaveDashboardDTO, dash *dashboards.Dashboard, previousFolderUID string) {
+	ctx, span := tracer.Start(ctx, "dashboards.service.moveDashboardPermissions")
+	defer span.End()
+
+	if _, err := dr.dashboardPermissions.MoveResource(ctx, dto.OrgID, accesscontrol.MoveResourceCommand{
+		ResourceID:     dash.UID,
+		OldParentUID:   previousFolderUID,
+		NewParentUID:   dash.FolderUID,
+		PruneRedundant: dto.PruneRedundantPermissions,
+	}); err != nil {
+		dr.log.Warn("Failed to update the permissions of the moved dashboard", "dashboardUID", dash.UID, "orgID", dto.OrgID, "error", err)
+	}
+}
 func (dr *DashboardServiceImpl) GetSoftDeletedDashboard(ctx context.Context, orgID int64, uid string) (*dashboards.Dashboard, error) {
 	return dr.dashboardStore.GetSoftDeletedDashboard(ctx, orgID, uid)
 }
@@ -464,6 +503,18 @@ func (dr *DashboardServiceImpl) deleteDashboard(ctx context.Context, dashboardId
 			return dashboards.ErrDashboardCannotDeleteProvisionedDashboard
 		}
 	}
+
+	if dr.shareTokens != nil {
+		// The tokens are revoked first so that a failure leaves the dashboard in place and the deletion can be retried
+		dash, err := dr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dashboardId, OrgID: orgId})
+		if err != nil {
+			return err
+		}
+		if err := dr.shareTokens.RevokeResourceTokens(ctx, orgId, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.UID)); err != nil {
+			return fmt.Errorf("failed to revoke the share tokens of the dashboard: %w", err)
+		}
+	}
+
 	cmd := &dashboards.DeleteDashboardCommand{OrgID: orgId, ID: dashboardId}
 	return dr.dashboardStore.DeleteDashboard(ctx, cmd)
 }
@@ -519,33 +570,32 @@ func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *
 	}
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
+	attrs := accesscontrol.DefaultPermissionsAttributes{Provisioned: provisioned}
 	// nolint:staticcheck
-	inFolder := dash.FolderID > 0
-	var permissions []accesscontrol.SetResourcePermissionCommand
+	if dash.FolderID > 0 {
+		attrs.ParentUID = dash.FolderUID
+	}
 
 	if !provisioned && dto.User.IsIdentityType(claims.TypeUser) {
 		userID, err := dto.User.GetInternalID()
 		if err != nil {
 			dr.log.Error("Could not make user admin", "dashboard", dash.Title, "id", dto.User.GetID(), "error", err)
 		} else {
-			permissions = append(permissions, accesscontrol.SetResourcePermissionCommand{
-				UserID: userID, Permission: dashboardaccess.PERMISSION_ADMIN.String(),
-			})
+			attrs.CreatorID = userID
 		}
 	}
 
-	if !inFolder {
-		permissions = append(permissions, []accesscontrol.SetResourcePermissionCommand{
-			{BuiltinRole: string(org.RoleEditor), Permission: dashboardaccess.PERMISSION_EDIT.String()},
-			{BuiltinRole: string(org.RoleViewer), Permission: dashboardaccess.PERMISSION_VIEW.String()},
-		}...)
-	}
-
 	svc := dr.dashboardPermissions
 	if dash.IsFolder {
 		svc = dr.folderPermissions
 	}
 
+	permissions, err := svc.DefaultPermissions(ctx, dto.OrgID, attrs)
+	if err != nil {
+		dr.log.Error("Could not get default permissions", "dashboard", dash.Title, "error", err)
+		return
+	}
+
 	if _, err := svc.SetPermissions(ctx, dto.OrgID, dash.UID, permissions...); err != nil {
 		dr.log.Error("Could not set default permissions", "dashboard", dash.Title, "error", err)
 	}
@@ -559,25 +609,21 @@ func (dr *DashboardServiceImpl) setDefaultFolderPermissions(ctx context.Context,
 		return
 	}
 
-	inFolder := f.ParentUID != ""
-	var permissions []accesscontrol.SetResourcePermissionCommand
+	attrs := accesscontrol.DefaultPermissionsAttributes{ParentUID: f.ParentUID, Provisioned: provisioned}
 
 	if !provisioned && cmd.SignedInUser.IsIdentityType(claims.TypeUser) {
 		userID, err := cmd.SignedInUser.GetInternalID()
 		if err != nil {
 			dr.log.Error("Could not make user admin", "folder", cmd.Title, "id", cmd.SignedInUser.GetID())
 		} else {
-			permissions = append(permissions, accesscontrol.SetResourcePermissionCommand{
-				UserID: userID, Permission: dashboardaccess.PERMISSION_ADMIN.String(),
-			})
+			attrs.CreatorID = userID
 		}
 	}
 
-	if !inFolder {
-		permissions = append(permissions, []accesscontrol.SetResourcePermissionCommand{
-			{BuiltinRole: string(org.RoleEditor), Permission: dashboardaccess.PERMISSION_EDIT.String()},
-			{BuiltinRole: string(org.RoleViewer), Permission: dashboardaccess.PERMISSION_VIEW.String()},
-		}...)
+	permissions, err := dr.folderPermissions.DefaultPermissions(ctx, cmd.OrgID, attrs)
+	if err != nil {
+		dr.log.Error("Could not get default folder permissions", "folder", f.Title, "error", err)
+		return
 	}
 
 	if _, err := dr.folderPermissions.SetPermissions(ctx, cmd.OrgID, f.UID, permissions...); err != nil {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -194,6 +195,29 @@ func TestDashboardService(t *testing.T) {
 				err := service.DeleteDashboard(context.Background(), 1, 1)
 				require.NoError(t, err)
 			})
+
+			t.Run("DeleteDashboard should revoke its share tokens", func(t *testing.T) {
+				revoker := &fakeShareTokenRevoker{}
+				service.shareTokens = revoker
+				defer func() { service.shareTokens = nil }()
+
+				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
+				fakeStore.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{ID: 1, OrgID: 1}).Return(&dashboards.Dashboard{ID: 1, UID: "dash", OrgID: 1}, nil).Once()
+				fakeStore.On("DeleteDashboard", mock.Anything, &dashboards.DeleteDashboardCommand{OrgID: 1, ID: 1}).Return(nil).Once()
+				err := service.DeleteDashboard(context.Background(), 1, 1)
+				require.NoError(t, err)
+				require.Equal(t, []string{"dashboards:uid:dash"}, revoker.scopes)
+			})
+
+			t.Run("DeleteDashboard should keep the dashboard when its share tokens can't be revoked", func(t *testing.T) {
+				service.shareTokens = &fakeShareTokenRevoker{err: errors.New("unavailable")}
+				defer func() { service.shareTokens = nil }()
+
+				fakeStore.On("GetProvisionedDataByDashboardID", mock.Anything, mock.AnythingOfType("int64")).Return(nil, nil).Once()
+				fakeStore.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{ID: 1, OrgID: 1}).Return(&dashboards.Dashboard{ID: 1, UID: "dash", OrgID: 1}, nil).Once()
+				err := service.DeleteDashboard(context.Background(), 1, 1)
+				require.Error(t, err)
+			})
 		})
 
 		t.Run("Count dashboards in folder", func(t *testing.T) {
@@ -223,3 +247,13 @@ func TestDashboardService(t *testing.T) {
 		})
 	})
 }
+
+type fakeShareTokenRevoker struct {
+	scopes []string
+	err    error
+}
+
+func (f *fakeShareTokenRevoker) RevokeResourceTokens(_ context.Context, _ int64, scope string) error {
+	f.scopes = append(f.scopes, scope)
+	return f.err
+}
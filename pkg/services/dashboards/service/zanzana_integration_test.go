@@ -65,6 +65,7 @@ func TestIntegrationDashboardServiceZanzana(t *testing.T) {
 			foldertest.NewFakeService(),
 			fStore,
 			nil,
+			nil,
 		)
 		require.NoError(t, err)
 
@@ -77,7 +78,7 @@ func TestIntegrationDashboardServiceZanzana(t *testing.T) {
 		createDashboards(t, service, 100, "test-b")
 
 		// Sync Grafana DB with zanzana (migrate data)
-		zanzanaSyncronizer := dualwrite.NewZanzanaReconciler(zclient, db, nil)
+		zanzanaSyncronizer := dualwrite.NewZanzanaReconciler(cfg, zclient, db, nil)
 		err = zanzanaSyncronizer.Sync(context.Background())
 		require.NoError(t, err)
 
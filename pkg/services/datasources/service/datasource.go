@@ -286,12 +286,9 @@ func (s *Service) AddDataSource(ctx context.Context, cmd *datasources.AddDataSou
 			// We can't use events, because there's no way to communicate
 			// failure, and we want "not being able to set default perms"
 			// to fail the creation.
-			permissions := []accesscontrol.SetResourcePermissionCommand{
-				{BuiltinRole: "Viewer", Permission: "Query"},
-				{BuiltinRole: "Editor", Permission: "Query"},
-			}
-			if cmd.UserID != 0 {
-				permissions = append(permissions, accesscontrol.SetResourcePermissionCommand{UserID: cmd.UserID, Permission: "Admin"})
+			permissions, err := s.permissionsService.DefaultPermissions(ctx, cmd.OrgID, accesscontrol.DefaultPermissionsAttributes{CreatorID: cmd.UserID})
+			if err != nil {
+				return err
 			}
 			if _, err = s.permissionsService.SetPermissions(ctx, cmd.OrgID, dataSource.UID, permissions...); err != nil {
 				return err
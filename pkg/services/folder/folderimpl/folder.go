@@ -30,7 +30,6 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
-	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/store/entity"
@@ -711,28 +710,26 @@ func (s *Service) setDefaultFolderPermissions(ctx context.Context, orgID int64,
 		return nil
 	}
 
-	var permissions []accesscontrol.SetResourcePermissionCommand
-
+	var attrs accesscontrol.DefaultPermissionsAttributes
 	if user.IsIdentityType(claims.TypeUser) {
 		userID, err := user.GetInternalID()
 		if err != nil {
 			return err
 		}
+		attrs.CreatorID = userID
+	}
 
-		permissions = append(permissions, accesscontrol.SetResourcePermissionCommand{
-			UserID: userID, Permission: dashboardaccess.PERMISSION_ADMIN.String(),
-		})
+	// Nested folders only inherit the permissions of their parent with nested folders enabled
+	if s.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) {
+		attrs.ParentUID = folder.ParentUID
 	}
 
-	isNested := folder.ParentUID != ""
-	if !isNested || !s.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) {
-		permissions = append(permissions, []accesscontrol.SetResourcePermissionCommand{
-			{BuiltinRole: string(org.RoleEditor), Permission: dashboardaccess.PERMISSION_EDIT.String()},
-			{BuiltinRole: string(org.RoleViewer), Permission: dashboardaccess.PERMISSION_VIEW.String()},
-		}...)
+	permissions, err := s.folderPermissions.DefaultPermissions(ctx, orgID, attrs)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.folderPermissions.SetPermissions(ctx, orgID, folder.UID, permissions...)
+	_, err = s.folderPermissions.SetPermissions(ctx, orgID, folder.UID, permissions...)
 	return err
 }
 
@@ -963,10 +960,14 @@ func (s *Service) Move(ctx context.Context, cmd *folder.MoveFolderCommand) (*fol
 	if cmd.UID == accesscontrol.K6FolderUID {
 		return nil, folder.ErrBadRequest.Errorf("k6 project may not be moved")
 	}
+	oldParentUID := ""
 	if f, err := s.store.Get(ctx, folder.GetFolderQuery{UID: &cmd.UID, OrgID: cmd.OrgID}); err != nil {
 		return nil, err
-	} else if f != nil && f.ParentUID == accesscontrol.K6FolderUID {
-		return nil, folder.ErrBadRequest.Errorf("k6 project may not be moved")
+	} else if f != nil {
+		if f.ParentUID == accesscontrol.K6FolderUID {
+			return nil, folder.ErrBadRequest.Errorf("k6 project may not be moved")
+		}
+		oldParentUID = f.ParentUID
 	}
 
 	// Check that the user is allowed to move the folder to the destination folder
@@ -1033,10 +1034,24 @@ func (s *Service) Move(ctx context.Context, cmd *folder.MoveFolderCommand) (*fol
 			return err
 		}
 
+		// The permissions of the folder are updated with the move, so its tuples are only related to the new parent
+		// once the move is committed
+		if s.folderPermissions != nil {
+			if _, err := s.folderPermissions.MoveResource(ctx, cmd.OrgID, accesscontrol.MoveResourceCommand{
+				ResourceID:     cmd.UID,
+				OldParentUID:   oldParentUID,
+				NewParentUID:   cmd.NewParentUID,
+				PruneRedundant: cmd.PruneRedundantPermissions,
+			}); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}); err != nil {
 		return nil, err
 	}
+
 	return f, nil
 }
 
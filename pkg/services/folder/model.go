@@ -129,6 +129,8 @@ type MoveFolderCommand struct {
 	UID          string `json:"-"`
 	NewParentUID string `json:"parentUid"`
 	OrgID        int64  `json:"-"`
+	// PruneRedundantPermissions removes the permissions set directly on the folder that its new parents already grant
+	PruneRedundantPermissions bool `json:"pruneRedundantPermissions"`
 
 	SignedInUser identity.Requester `json:"-"`
 }
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/grafana/authlib/claims"
+
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 )
@@ -20,6 +23,9 @@ const (
 	ActionLibraryPanelsRead   = "library.panels:read"
 	ActionLibraryPanelsWrite  = "library.panels:write"
 	ActionLibraryPanelsDelete = "library.panels:delete"
+
+	ActionLibraryPanelsPermissionsRead  = "library.panels.permissions:read"
+	ActionLibraryPanelsPermissionsWrite = "library.panels.permissions:write"
 )
 
 var (
@@ -67,3 +73,45 @@ func LibraryPanelUIDScopeResolver(l *LibraryElementService, folderStore folder.S
 		return append(inheritedScopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(libElDTO.FolderUID), ScopeLibraryPanelsProvider.GetResourceScopeUID(uid)), nil
 	})
 }
+
+// setDefaultPermissions sets the permissions the default permissions policy grants on a library panel created by a user
+// when library-panel is one of the rbac resources_with_managed_permissions_on_creation, by default its creator is made
+// its admin and the access of the other users is inherited from the folder.
+func (l *LibraryElementService) setDefaultPermissions(ctx context.Context, user identity.Requester, uid string) {
+	if !l.features.IsEnabled(ctx, featuremgmt.FlagLibraryPanelRBAC) || !l.Cfg.RBAC.PermissionsOnCreation("library-panel") {
+		return
+	}
+	if l.permissionsService == nil || !user.IsIdentityType(claims.TypeUser) {
+		return
+	}
+
+	userID, err := user.GetInternalID()
+	if err != nil {
+		l.log.Error("Could not make user admin", "library_panel_uid", uid, "id", user.GetID(), "error", err)
+		return
+	}
+
+	permissions, err := l.permissionsService.DefaultPermissions(ctx, user.GetOrgID(), ac.DefaultPermissionsAttributes{CreatorID: userID})
+	if err != nil {
+		l.log.Error("Could not get default permissions", "library_panel_uid", uid, "error", err)
+		return
+	}
+
+	if _, err := l.permissionsService.SetPermissions(ctx, user.GetOrgID(), uid, permissions...); err != nil {
+		l.log.Error("Could not set default permissions", "library_panel_uid", uid, "error", err)
+	}
+}
+
+// deletePermissions removes the managed permissions of deleted library panels, it is called in the transaction
+// deleting them so permissions of a library panel aren't left behind for a new one reusing its uid.
+func (l *LibraryElementService) deletePermissions(ctx context.Context, orgID int64, uids ...string) error {
+	if l.permissionsService == nil {
+		return nil
+	}
+	for _, uid := range uids {
+		if err := l.permissionsService.DeleteResourcePermissions(ctx, orgID, uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -194,6 +194,9 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 		}
 		return nil
 	})
+	if err == nil {
+		l.setDefaultPermissions(c, signedInUser, element.UID)
+	}
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.LibraryElements).Inc()
 	dto := model.LibraryElementDTO{
@@ -230,7 +233,17 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 // deleteLibraryElement deletes a library element.
 func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedInUser identity.Requester, uid string) (int64, error) {
 	var elementID int64
-	err := l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
+	err := l.SQLStore.InTransaction(c, func(ctx context.Context) error {
+		if err := l.deleteLibraryElementInTransaction(ctx, signedInUser, uid, &elementID); err != nil {
+			return err
+		}
+		return l.deletePermissions(ctx, signedInUser.GetOrgID(), uid)
+	})
+	return elementID, err
+}
+
+func (l *LibraryElementService) deleteLibraryElementInTransaction(c context.Context, signedInUser identity.Requester, uid string, elementID *int64) error {
+	return l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
 		element, err := GetLibraryElement(l.SQLStore.GetDialect(), session, uid, signedInUser.GetOrgID())
 		if err != nil {
 			return err
@@ -269,10 +282,9 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 			return model.ErrLibraryElementNotFound
 		}
 
-		elementID = element.ID
+		*elementID = element.ID
 		return nil
 	})
-	return elementID, err
 }
 
 // getLibraryElements gets a Library Element where param == value
@@ -829,7 +841,18 @@ func (l *LibraryElementService) disconnectElementsFromDashboardID(c context.Cont
 
 // deleteLibraryElementsInFolderUID deletes all Library Elements in a folder.
 func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Context, signedInUser identity.Requester, folderUID string) error {
-	return l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
+	return l.SQLStore.InTransaction(c, func(ctx context.Context) error {
+		uids, err := l.deleteLibraryElementsInFolderUIDInTransaction(ctx, signedInUser, folderUID)
+		if err != nil {
+			return err
+		}
+		return l.deletePermissions(ctx, signedInUser.GetOrgID(), uids...)
+	})
+}
+
+func (l *LibraryElementService) deleteLibraryElementsInFolderUIDInTransaction(c context.Context, signedInUser identity.Requester, folderUID string) ([]string, error) {
+	var uids []string
+	err := l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
 		var folderUIDs []struct {
 			ID int64 `xorm:"id"`
 		}
@@ -866,9 +889,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		}
 
 		var elementIDs []struct {
-			ID int64 `xorm:"id"`
+			ID  int64  `xorm:"id"`
+			UID string `xorm:"uid"`
 		}
-		err = session.SQL("SELECT id from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()).Find(&elementIDs)
+		err = session.SQL("SELECT id, uid from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()).Find(&elementIDs)
 		if err != nil {
 			return err
 		}
@@ -877,6 +901,7 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			uids = append(uids, elementID.UID)
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()); err != nil {
 			return err
@@ -884,4 +909,5 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 
 		return nil
 	})
+	return uids, err
 }
@@ -15,15 +15,16 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, folderService folder.Service, folderStore folder.Store, features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl) *LibraryElementService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, folderService folder.Service, folderStore folder.Store, features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl, permissionsService accesscontrol.LibraryPanelPermissionsService) *LibraryElementService {
 	l := &LibraryElementService{
-		Cfg:           cfg,
-		SQLStore:      sqlStore,
-		RouteRegister: routeRegister,
-		folderService: folderService,
-		log:           log.New("library-elements"),
-		features:      features,
-		AccessControl: ac,
+		Cfg:                cfg,
+		SQLStore:           sqlStore,
+		RouteRegister:      routeRegister,
+		folderService:      folderService,
+		log:                log.New("library-elements"),
+		features:           features,
+		AccessControl:      ac,
+		permissionsService: permissionsService,
 	}
 
 	l.registerAPIEndpoints()
@@ -52,6 +53,8 @@ type LibraryElementService struct {
 	log           log.Logger
 	features      featuremgmt.FeatureToggles
 	AccessControl accesscontrol.AccessControl
+
+	permissionsService accesscontrol.LibraryPanelPermissionsService
 }
 
 var _ Service = (*LibraryElementService)(nil)
@@ -368,6 +368,11 @@ func (s ReceiverAccess[T]) AuthorizeReadSome(ctx context.Context, user identity.
 	return s.read.AuthorizePreConditions(ctx, user)
 }
 
+// AuthorizeDeleteSome checks if user has access to delete some receivers. Returns an error if user does not have access.
+func (s ReceiverAccess[T]) AuthorizeDeleteSome(ctx context.Context, user identity.Requester) error {
+	return s.delete.AuthorizePreConditions(ctx, user)
+}
+
 // All access permissions for a given receiver.
 
 // Access returns the permission sets for a slice of receivers. The permission set includes secrets, write, and
@@ -22,6 +22,22 @@ const (
 	ruleDelete = accesscontrol.ActionAlertingRuleDelete
 )
 
+const ScopeRulesRoot = "alert.rules"
+
+var (
+	ScopeRulesProvider = accesscontrol.NewScopeProvider(ScopeRulesRoot)
+	ScopeRulesAll      = ScopeRulesProvider.GetResourceAllScope()
+)
+
+// RulePermission is a type for representing an alert rule permission.
+type RulePermission string
+
+const (
+	RulePermissionView  RulePermission = "View"
+	RulePermissionEdit  RulePermission = "Edit"
+	RulePermissionAdmin RulePermission = "Admin"
+)
+
 type RuleService struct {
 	genericService
 	notificationSettingsAuth notificationSettingsAuth
@@ -46,16 +62,26 @@ func getReadFolderAccessEvaluator(folderUID string) accesscontrol.Evaluator {
 	)
 }
 
+// getRuleAccessEvaluator constructs accesscontrol.Evaluator that checks the action is granted on the folder of the rule,
+// or on the rule itself by its managed permissions
+func getRuleAccessEvaluator(action string, rule *models.AlertRule) accesscontrol.Evaluator {
+	folderEval := accesscontrol.EvalPermission(action, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID))
+	if rule.UID == "" {
+		return folderEval
+	}
+	return accesscontrol.EvalAny(folderEval, accesscontrol.EvalPermission(action, ScopeRulesProvider.GetResourceScopeUID(rule.UID)))
+}
+
 // getRulesReadEvaluator constructs accesscontrol.Evaluator that checks all permissions required to access provided rules
 func (r *RuleService) getRulesReadEvaluator(rules ...*models.AlertRule) accesscontrol.Evaluator {
 	added := make(map[string]struct{}, 1)
 	evals := make([]accesscontrol.Evaluator, 0, 1)
 	for _, rule := range rules {
-		if _, ok := added[rule.NamespaceUID]; ok {
-			continue
+		if _, ok := added[rule.NamespaceUID]; !ok {
+			added[rule.NamespaceUID] = struct{}{}
+			evals = append(evals, accesscontrol.EvalPermission(dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID)))
 		}
-		added[rule.NamespaceUID] = struct{}{}
-		evals = append(evals, getReadFolderAccessEvaluator(rule.NamespaceUID))
+		evals = append(evals, getRuleAccessEvaluator(ruleRead, rule))
 	}
 	return accesscontrol.EvalAll(evals...)
 }
@@ -174,22 +200,20 @@ func (r *RuleService) AuthorizeRuleChanges(ctx context.Context, user identity.Re
 		return fmt.Errorf("failed to authorize changes in rule group %s. Detected %d deletes but group was not provided", change.GroupKey.RuleGroup, len(change.Delete))
 	}
 
-	if len(change.Delete) > 0 {
-		if err := r.HasAccessOrError(ctx, user, accesscontrol.EvalPermission(ruleDelete, namespaceScope), func() string {
-			return fmt.Sprintf("delete alert rules that belong to folder %s", change.GroupKey.NamespaceUID)
+	for _, rule := range change.Delete {
+		if err := r.HasAccessOrError(ctx, user, getRuleAccessEvaluator(ruleDelete, rule), func() string {
+			return fmt.Sprintf("delete alert rule '%s' that belongs to folder %s", rule.UID, change.GroupKey.NamespaceUID)
 		}); err != nil {
 			return err
 		}
-		for _, rule := range change.Delete {
-			if err := r.HasAccessOrError(ctx, user, r.getRulesQueryEvaluator(rule), func() string {
-				return fmt.Sprintf("delete an alert rule '%s'", rule.UID)
-			}); err != nil {
-				return err
-			}
+		if err := r.HasAccessOrError(ctx, user, r.getRulesQueryEvaluator(rule), func() string {
+			return fmt.Sprintf("delete an alert rule '%s'", rule.UID)
+		}); err != nil {
+			return err
 		}
 	}
 
-	var addAuthorized, updateAuthorized bool // these are needed to check authorization for the rule create\update only once
+	var addAuthorized bool // this is needed to check authorization for the rule create only once
 	if len(change.New) > 0 {
 		if err := r.HasAccessOrError(ctx, user, accesscontrol.EvalPermission(ruleCreate, namespaceScope), func() string {
 			return fmt.Sprintf("create alert rules in the folder %s", change.GroupKey.NamespaceUID)
@@ -225,7 +249,7 @@ func (r *RuleService) AuthorizeRuleChanges(ctx context.Context, user identity.Re
 
 		// Check if the rule is moved from one folder to the current. If yes, then the user must have the authorization to delete rules from the source folder and add rules to the target folder.
 		if rule.Existing.NamespaceUID != rule.New.NamespaceUID {
-			ev := accesscontrol.EvalPermission(ruleDelete, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.Existing.NamespaceUID))
+			ev := getRuleAccessEvaluator(ruleDelete, rule.Existing)
 			if err := r.HasAccessOrError(ctx, user, ev, func() string {
 				return fmt.Sprintf("move alert rules from folder %s", rule.Existing.NamespaceUID)
 			}); err != nil {
@@ -240,13 +264,12 @@ func (r *RuleService) AuthorizeRuleChanges(ctx context.Context, user identity.Re
 				}
 				addAuthorized = true
 			}
-		} else if !updateAuthorized { // if it is false then the authorization was not checked. If it is true then the user is authorized to update rules
-			if err := r.HasAccessOrError(ctx, user, accesscontrol.EvalPermission(ruleUpdate, namespaceScope), func() string {
-				return fmt.Sprintf("update alert rules that belongs to folder '%s'", change.GroupKey.NamespaceUID)
+		} else {
+			if err := r.HasAccessOrError(ctx, user, getRuleAccessEvaluator(ruleUpdate, rule.Existing), func() string {
+				return fmt.Sprintf("update alert rule '%s' that belongs to folder '%s'", rule.Existing.UID, change.GroupKey.NamespaceUID)
 			}); err != nil {
 				return err
 			}
-			updateAuthorized = true
 		}
 
 		if !slices.EqualFunc(rule.Existing.NotificationSettings, rule.New.NotificationSettings, func(settings models.NotificationSettings, settings2 models.NotificationSettings) bool {
@@ -221,6 +221,43 @@ func TestAuthorizeRuleChanges(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "if there are rules to update or delete with permissions on the rules it should check the actions on the rules",
+			changes: func() *store.GroupDelta {
+				rules := genWithGroupKey.GenerateManyRef(2)
+				cp := models.CopyRule(rules[0])
+				cp.Data = []models.AlertQuery{models.GenerateAlertQuery()}
+
+				return &store.GroupDelta{
+					GroupKey: groupKey,
+					AffectedGroups: map[models.AlertRuleGroupKey]models.RulesGroup{
+						groupKey: rules,
+					},
+					New:    nil,
+					Update: []store.RuleDelta{{Existing: rules[0], New: cp}},
+					Delete: rules[1:],
+				}
+			},
+			permissions: func(c *store.GroupDelta) map[string][]string {
+				var readScopes []string
+				for _, rule := range c.AffectedGroups[c.GroupKey] {
+					readScopes = append(readScopes, ScopeRulesProvider.GetResourceScopeUID(rule.UID))
+				}
+				return map[string][]string{
+					ruleRead: readScopes,
+					dashboards.ActionFoldersRead: {
+						namespaceIdScope,
+					},
+					ruleUpdate: {
+						ScopeRulesProvider.GetResourceScopeUID(c.Update[0].Existing.UID),
+					},
+					ruleDelete: {
+						ScopeRulesProvider.GetResourceScopeUID(c.Delete[0].UID),
+					},
+					datasources.ActionQuery: append(getDatasourceScopesForRules(c.Delete), getDatasourceScopesForRules(models.RulesGroup{c.Update[0].New})...),
+				}
+			},
+		},
 		{
 			name: "if there are rules that are moved between namespaces it should check delete+add action and access to group where rules come from",
 			changes: func() *store.GroupDelta {
@@ -44,4 +44,8 @@ func (a *recordingAccessControlFake) ListObjects(ctx context.Context, in accessc
 	return nil, nil
 }
 
+func (a *recordingAccessControlFake) ListUsers(ctx context.Context, in accesscontrol.ListUsersRequest) ([]string, error) {
+	return nil, nil
+}
+
 var _ accesscontrol.AccessControl = &recordingAccessControlFake{}
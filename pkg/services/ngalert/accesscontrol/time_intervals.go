@@ -0,0 +1,69 @@
+package accesscontrol
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	// asserts read access to time intervals
+	readTimeIntervalsEvaluator = ac.EvalPermission(ac.ActionAlertingNotificationsTimeIntervalsRead)
+	// asserts write access to time intervals
+	writeTimeIntervalsEvaluator = ac.EvalAll(
+		readTimeIntervalsEvaluator,
+		ac.EvalPermission(ac.ActionAlertingNotificationsTimeIntervalsWrite),
+	)
+	// asserts delete access to time intervals
+	deleteTimeIntervalsEvaluator = ac.EvalAll(
+		readTimeIntervalsEvaluator,
+		ac.EvalPermission(ac.ActionAlertingNotificationsTimeIntervalsDelete),
+	)
+)
+
+// TimeIntervalAccess provides access control for time intervals. Time intervals are not scoped, the actions apply
+// to all time intervals of the organization. The coarse notifications actions do not grant access.
+type TimeIntervalAccess struct {
+	genericService
+}
+
+func NewTimeIntervalAccess(a ac.AccessControl) *TimeIntervalAccess {
+	return &TimeIntervalAccess{
+		genericService: genericService{
+			ac: a,
+		},
+	}
+}
+
+// AuthorizeRead checks if user has access to read time intervals. Returns an error if user does not have access.
+func (s TimeIntervalAccess) AuthorizeRead(ctx context.Context, user identity.Requester) error {
+	return s.HasAccessOrError(ctx, user, readTimeIntervalsEvaluator, func() string {
+		return "read time intervals"
+	})
+}
+
+// FilterRead filters the given list of time intervals based on the read access control permissions of the user.
+// Time intervals are not scoped yet, therefore the user can read either all of them or none. Returns an error if
+// user does not have access to any time interval.
+func (s TimeIntervalAccess) FilterRead(ctx context.Context, user identity.Requester, intervals ...definitions.MuteTimeInterval) ([]definitions.MuteTimeInterval, error) {
+	if err := s.AuthorizeRead(ctx, user); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+// AuthorizeWrite checks if user has access to create and update time intervals. Returns an error if user does not have access.
+func (s TimeIntervalAccess) AuthorizeWrite(ctx context.Context, user identity.Requester) error {
+	return s.HasAccessOrError(ctx, user, writeTimeIntervalsEvaluator, func() string {
+		return "write time intervals"
+	})
+}
+
+// AuthorizeDelete checks if user has access to delete time intervals. Returns an error if user does not have access.
+func (s TimeIntervalAccess) AuthorizeDelete(ctx context.Context, user identity.Requester) error {
+	return s.HasAccessOrError(ctx, user, deleteTimeIntervalsEvaluator, func() string {
+		return "delete time intervals"
+	})
+}
@@ -0,0 +1,92 @@
+package accesscontrol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestTimeIntervalAccess(t *testing.T) {
+	testCases := []struct {
+		name      string
+		user      identity.Requester
+		canRead   bool
+		canWrite  bool
+		canDelete bool
+	}{
+		{
+			name: "no permissions",
+			user: newUser(),
+		},
+		{
+			name:    "time intervals reader",
+			user:    newUser(ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsRead}),
+			canRead: true,
+		},
+		{
+			name: "time intervals writer",
+			user: newUser(
+				ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsRead},
+				ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsWrite},
+			),
+			canRead:  true,
+			canWrite: true,
+		},
+		{
+			name: "time intervals deleter",
+			user: newUser(
+				ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsRead},
+				ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsDelete},
+			),
+			canRead:   true,
+			canDelete: true,
+		},
+		{
+			name:     "write without read",
+			user:     newUser(ac.Permission{Action: ac.ActionAlertingNotificationsTimeIntervalsWrite}),
+			canWrite: false,
+		},
+		{
+			name: "coarse notifications actions",
+			user: newUser(
+				ac.Permission{Action: ac.ActionAlertingNotificationsRead},
+				ac.Permission{Action: ac.ActionAlertingNotificationsWrite},
+			),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewTimeIntervalAccess(acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()))
+			ctx := context.Background()
+
+			check := func(expected bool, err error) {
+				if expected {
+					require.NoError(t, err)
+					return
+				}
+				require.ErrorIs(t, err, ErrAuthorizationBase)
+			}
+			check(tc.canRead, svc.AuthorizeRead(ctx, tc.user))
+			check(tc.canWrite, svc.AuthorizeWrite(ctx, tc.user))
+			check(tc.canDelete, svc.AuthorizeDelete(ctx, tc.user))
+
+			intervals := []definitions.MuteTimeInterval{{UID: "a"}, {UID: "b"}}
+			filtered, err := svc.FilterRead(ctx, tc.user, intervals...)
+			check(tc.canRead, err)
+			if tc.canRead {
+				require.Equal(t, intervals, filtered)
+			} else {
+				require.Empty(t, filtered)
+			}
+		})
+	}
+}
@@ -71,6 +71,7 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	RulePermissions      ac.AlertRulePermissionsService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
 	ConditionValidator   *eval.ConditionValidator
@@ -135,6 +136,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amConfigStore:      api.AlertingStore,
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
+			rulePermissions:    api.RulePermissions,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -57,6 +58,8 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+
+	rulePermissions ac.AlertRulePermissionsService
 }
 
 var (
@@ -158,6 +161,9 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 			if err != nil {
 				return err
 			}
+			if err := srv.deleteRulePermissions(ctx, c.SignedInUser.GetOrgID(), rulesToDelete); err != nil {
+				return err
+			}
 			logger.Info("Alert rules were deleted", "ruleUid", strings.Join(rulesToDelete, ","))
 			return nil
 		}
@@ -432,6 +438,9 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
 				return fmt.Errorf("failed to delete rules: %w", err)
 			}
+			if err = srv.deleteRulePermissions(tranCtx, c.SignedInUser.GetOrgID(), UIDs); err != nil {
+				return err
+			}
 		}
 
 		if len(finalChanges.Update) > 0 {
@@ -507,9 +516,28 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 	}
 
+	if srv.rulePermissions != nil {
+		for _, rule := range finalChanges.New {
+			srv.rulePermissions.SetDefaultPermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), c.SignedInUser, rule.UID)
+		}
+	}
+
 	return changesToResponse(finalChanges)
 }
 
+// deleteRulePermissions removes the managed permissions of the deleted rules in the transaction deleting them.
+func (srv RulerSrv) deleteRulePermissions(ctx context.Context, orgID int64, uids []string) error {
+	if srv.rulePermissions == nil {
+		return nil
+	}
+	for _, uid := range uids {
+		if err := srv.rulePermissions.DeleteResourcePermissions(ctx, orgID, uid); err != nil {
+			return fmt.Errorf("failed to delete permissions of rule %s: %w", uid, err)
+		}
+	}
+	return nil
+}
+
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
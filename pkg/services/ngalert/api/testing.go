@@ -145,6 +145,10 @@ func (a *recordingAccessControlFake) ListObjects(ctx context.Context, in ac.List
 	return nil, nil
 }
 
+func (a *recordingAccessControlFake) ListUsers(ctx context.Context, in ac.ListUsersRequest) ([]string, error) {
+	return nil, nil
+}
+
 var _ ac.AccessControl = &recordingAccessControlFake{}
 
 type fakeRuleAccessControlService struct {
@@ -78,6 +78,7 @@ func ProvideService(
 	ruleStore *store.DBstore,
 	httpClientProvider httpclient.Provider,
 	resourcePermissions accesscontrol.ReceiverPermissionsService,
+	ruleResourcePermissions accesscontrol.AlertRulePermissionsService,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -106,6 +107,7 @@ func ProvideService(
 		store:                ruleStore,
 		httpClientProvider:   httpClientProvider,
 		ResourcePermissions:  resourcePermissions,
+		RulePermissions:      ruleResourcePermissions,
 	}
 
 	if ng.IsDisabled() {
@@ -151,6 +153,7 @@ type AlertNG struct {
 	accesscontrol        accesscontrol.AccessControl
 	AccesscontrolService accesscontrol.Service
 	ResourcePermissions  accesscontrol.ReceiverPermissionsService
+	RulePermissions      accesscontrol.AlertRulePermissionsService
 	annotationsRepo      annotations.Repository
 	store                *store.DBstore
 
@@ -488,6 +491,7 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		RulePermissions:      ng.RulePermissions,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
 		ConditionValidator:   conditionValidator,
@@ -102,6 +102,7 @@ func (moa *MultiOrgAlertmanager) ApplyConfig(ctx context.Context, orgId int64, d
 	}
 
 	err = am.ApplyConfig(ctx, dbConfig)
+	moa.applyStatuses.set(orgId, dbConfig.ID, err)
 	if err != nil {
 		return fmt.Errorf("failed to apply configuration: %w", err)
 	}
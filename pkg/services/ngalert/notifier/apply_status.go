@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+// ApplyStatus is the result of the last apply of the Alertmanager configuration of an organization.
+type ApplyStatus struct {
+	// ConfigID is the ID of the applied configuration, 0 for the default configuration.
+	ConfigID int64
+	Time     time.Time
+	// Error is the error of the last apply, empty when the apply succeeded.
+	Error string
+}
+
+// applyStatuses keeps the status of the last apply per organization.
+type applyStatuses struct {
+	mtx      sync.RWMutex
+	statuses map[int64]ApplyStatus
+}
+
+func (s *applyStatuses) set(orgID, configID int64, err error) {
+	status := ApplyStatus{ConfigID: configID, Time: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.statuses == nil {
+		s.statuses = make(map[int64]ApplyStatus)
+	}
+	s.statuses[orgID] = status
+}
+
+func (s *applyStatuses) get(orgID int64) (ApplyStatus, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	status, ok := s.statuses[orgID]
+	return status, ok
+}
+
+func (s *applyStatuses) remove(orgID int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.statuses, orgID)
+}
+
+// ApplyStatus returns the status of the last apply of the Alertmanager configuration of the organization. It returns
+// false if the configuration was not applied since the start of this instance.
+func (moa *MultiOrgAlertmanager) ApplyStatus(orgID int64) (ApplyStatus, bool) {
+	return moa.applyStatuses.get(orgID)
+}
@@ -0,0 +1,146 @@
+package channels_config
+
+import (
+	"fmt"
+
+	alertingPagerduty "github.com/grafana/alerting/receivers/pagerduty"
+	alertingTemplates "github.com/grafana/alerting/templates"
+)
+
+// IntegrationSchemaVersion is the version of the settings of the integrations. All the integrations are at version v1.
+const IntegrationSchemaVersion = "v1"
+
+// IntegrationSchema describes the settings of an integration type. It is generated from the notifier plugins and lets
+// clients build forms and validate settings without knowledge of each type.
+type IntegrationSchema struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	// SecureFields are the paths of the settings that are stored encrypted, e.g. sigv4.secret_key.
+	SecureFields []string `json:"secureFields"`
+	// Schema is the JSON schema of the settings.
+	Schema map[string]any `json:"schema"`
+}
+
+// defaultPlaceholders are the placeholders of options that are also the value used when the option is empty.
+var defaultPlaceholders = map[string]struct{}{
+	alertingTemplates.DefaultMessageEmbed:      {},
+	alertingTemplates.DefaultMessageTitleEmbed: {},
+	alertingPagerduty.DefaultURL:               {},
+	`{{ template "slack.default.title" . }}`:   {},
+	`{{ template "slack.default.text" . }}`:    {},
+}
+
+// GetIntegrationSchemas returns the schemas of all the integration types that can be configured.
+func GetIntegrationSchemas() []IntegrationSchema {
+	notifiers := GetAvailableNotifiers()
+	result := make([]IntegrationSchema, 0, len(notifiers))
+	for _, n := range notifiers {
+		result = append(result, newIntegrationSchema(n))
+	}
+	return result
+}
+
+// GetIntegrationSchema returns the schema of the given integration type. Returns error if integration type is not known.
+func GetIntegrationSchema(integrationType string) (IntegrationSchema, error) {
+	n, err := ConfigForIntegrationType(integrationType)
+	if err != nil {
+		return IntegrationSchema{}, err
+	}
+	return newIntegrationSchema(n), nil
+}
+
+func newIntegrationSchema(n *NotifierPlugin) IntegrationSchema {
+	secureFields := getSecretFields("", n.Options)
+	if secureFields == nil {
+		secureFields = []string{}
+	}
+	schema := objectSchema(n.Options)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = n.Name
+	return IntegrationSchema{
+		Type:         n.Type,
+		Name:         n.Name,
+		Description:  n.Description,
+		Version:      IntegrationSchemaVersion,
+		SecureFields: secureFields,
+		Schema:       schema,
+	}
+}
+
+func objectSchema(options []NotifierOption) map[string]any {
+	properties := make(map[string]any, len(options))
+	required := make([]string, 0)
+	for _, option := range options {
+		properties[option.PropertyName] = optionSchema(option)
+		if option.Required {
+			required = append(required, option.PropertyName)
+		}
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func optionSchema(option NotifierOption) map[string]any {
+	var schema map[string]any
+	switch option.Element {
+	case ElementTypeCheckbox:
+		schema = map[string]any{"type": "boolean", "default": false}
+	case ElementTypeKeyValueMap:
+		schema = map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": "string"},
+		}
+	case ElementTypeSubform:
+		schema = objectSchema(option.SubformOptions)
+	case ElementSubformArray:
+		schema = map[string]any{
+			"type":  "array",
+			"items": objectSchema(option.SubformOptions),
+		}
+	case ElementTypeSelect:
+		schema = map[string]any{"type": "string"}
+		if len(option.SelectOptions) > 0 {
+			values := make([]string, 0, len(option.SelectOptions))
+			for _, o := range option.SelectOptions {
+				values = append(values, o.Value)
+			}
+			schema["enum"] = values
+		}
+	default:
+		schema = map[string]any{"type": "string"}
+		if option.ValidationRule != "" {
+			schema["pattern"] = fmt.Sprintf("^(?:%s)$", option.ValidationRule)
+		}
+		if _, ok := defaultPlaceholders[option.Placeholder]; ok && option.Placeholder != "" {
+			schema["default"] = option.Placeholder
+		}
+	}
+	if option.Label != "" {
+		schema["title"] = option.Label
+	}
+	if option.Description != "" {
+		schema["description"] = option.Description
+	}
+	if option.Secure {
+		schema["writeOnly"] = true
+	}
+	if option.DependsOn != "" {
+		schema["x-depends-on"] = option.DependsOn
+	}
+	if option.ShowWhen.Field != "" {
+		schema["x-show-when"] = map[string]string{
+			"field": option.ShowWhen.Field,
+			"is":    option.ShowWhen.Is,
+		}
+	}
+	schema["x-element"] = string(option.Element)
+	return schema
+}
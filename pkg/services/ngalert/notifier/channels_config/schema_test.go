@@ -0,0 +1,55 @@
+package channels_config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIntegrationSchemas(t *testing.T) {
+	schemas := GetIntegrationSchemas()
+	require.Len(t, schemas, len(GetAvailableNotifiers()))
+	for _, schema := range schemas {
+		require.Equal(t, IntegrationSchemaVersion, schema.Version)
+		require.Equal(t, "object", schema.Schema["type"])
+		_, err := json.Marshal(schema)
+		require.NoError(t, err)
+	}
+}
+
+func TestGetIntegrationSchema(t *testing.T) {
+	t.Run("secure and nested fields", func(t *testing.T) {
+		schema, err := GetIntegrationSchema("sns")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"sigv4.access_key", "sigv4.secret_key"}, schema.SecureFields)
+
+		properties := schema.Schema["properties"].(map[string]any)
+		sigv4 := properties["sigv4"].(map[string]any)
+		require.Equal(t, "object", sigv4["type"])
+		secretKey := sigv4["properties"].(map[string]any)["secret_key"].(map[string]any)
+		require.Equal(t, true, secretKey["writeOnly"])
+	})
+
+	t.Run("required fields and defaults", func(t *testing.T) {
+		schema, err := GetIntegrationSchema("slack")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"token", "url"}, schema.SecureFields)
+
+		properties := schema.Schema["properties"].(map[string]any)
+		title := properties["title"].(map[string]any)
+		require.NotEmpty(t, title["default"])
+	})
+
+	t.Run("select options are an enum", func(t *testing.T) {
+		schema, err := GetIntegrationSchema("pushover")
+		require.NoError(t, err)
+		sound := schema.Schema["properties"].(map[string]any)["sound"].(map[string]any)
+		require.Contains(t, sound["enum"], "pushover")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := GetIntegrationSchema("unknown")
+		require.Error(t, err)
+	})
+}
@@ -83,6 +83,7 @@ type MultiOrgAlertmanager struct {
 
 	alertmanagersMtx sync.RWMutex
 	alertmanagers    map[int64]Alertmanager
+	applyStatuses    applyStatuses
 
 	settings       *setting.Cfg
 	featureManager featuremgmt.FeatureToggles
@@ -321,6 +322,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 				moa.logger.Warn("Alertmanager exists for org but the configuration is gone. Applying the default configuration", "org", orgID)
 			}
 			err := alertmanager.SaveAndApplyDefaultConfig(ctx)
+			moa.applyStatuses.set(orgID, 0, err)
 			if err != nil {
 				moa.logger.Error("Failed to apply the default Alertmanager configuration", "org", orgID)
 				continue
@@ -330,6 +332,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 		}
 
 		err := alertmanager.ApplyConfig(ctx, dbConfig)
+		moa.applyStatuses.set(orgID, dbConfig.ID, err)
 		if err != nil {
 			moa.logger.Error("Failed to apply Alertmanager config for org", "org", orgID, "id", dbConfig.ID, "error", err)
 			continue
@@ -343,6 +346,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 			amsToStop[orgId] = am
 			delete(moa.alertmanagers, orgId)
 			moa.metrics.RemoveOrgRegistry(orgId)
+			moa.applyStatuses.remove(orgId)
 		}
 	}
 	moa.metrics.ActiveConfigurations.Set(float64(len(moa.alertmanagers)))
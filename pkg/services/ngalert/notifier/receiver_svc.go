@@ -533,14 +533,7 @@ func (rs *ReceiverService) UpdateReceiver(ctx context.Context, r *models.Receive
 				return err
 			}
 			// Update receiver permissions
-			permissionsUpdated, err := rs.resourcePermissions.CopyPermissions(ctx, orgID, user, legacy_storage.NameToUid(existing.Name), legacy_storage.NameToUid(r.Name))
-			if err != nil {
-				return err
-			}
-			if permissionsUpdated > 0 {
-				logger.Info("Moved custom receiver permissions", "oldName", existing.Name, "count", permissionsUpdated)
-			}
-			if err := rs.resourcePermissions.DeleteResourcePermissions(ctx, orgID, legacy_storage.NameToUid(existing.Name)); err != nil {
+			if err := rs.resourcePermissions.RenamePermissions(ctx, orgID, user, legacy_storage.NameToUid(existing.Name), legacy_storage.NameToUid(r.Name)); err != nil {
 				return err
 			}
 		}
@@ -312,24 +312,32 @@ func (ecp *ContactPointService) UpdateContactPoint(ctx context.Context, orgID in
 
 	err = ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
 		if mergedReceiver.Name != oldReceiverName {
-			if newReceiverCreated {
+			oldUID, newUID := legacy_storage.NameToUid(oldReceiverName), legacy_storage.NameToUid(mergedReceiver.Name)
+			switch {
+			case newReceiverCreated && fullRemoval:
+				// The receiver was renamed, its permissions are moved to the new uid
+				if err := ecp.resourcePermissions.RenamePermissions(ctx, orgID, nil, oldUID, newUID); err != nil {
+					return err
+				}
+			case newReceiverCreated:
 				// Copy receiver permissions
-				permissionsUpdated, err := ecp.resourcePermissions.CopyPermissions(ctx, orgID, nil, legacy_storage.NameToUid(oldReceiverName), legacy_storage.NameToUid(mergedReceiver.Name))
+				permissionsUpdated, err := ecp.resourcePermissions.CopyPermissions(ctx, orgID, nil, oldUID, newUID)
 				if err != nil {
 					return err
 				}
 				if permissionsUpdated > 0 {
 					ecp.log.FromContext(ctx).Debug("Moved custom receiver permissions", "oldName", oldReceiverName, "newName", mergedReceiver.Name, "count", permissionsUpdated)
 				}
+			case fullRemoval:
+				if err := ecp.resourcePermissions.DeleteResourcePermissions(ctx, orgID, oldUID); err != nil {
+					return err
+				}
 			}
 
 			if fullRemoval {
 				if err := ecp.receiverService.RenameReceiverInDependentResources(ctx, orgID, revision.Config.AlertmanagerConfig.Route, oldReceiverName, mergedReceiver.Name, provenance); err != nil {
 					return err
 				}
-				if err := ecp.resourcePermissions.DeleteResourcePermissions(ctx, orgID, legacy_storage.NameToUid(oldReceiverName)); err != nil {
-					return err
-				}
 			}
 		}
 		if err := ecp.configStore.Save(ctx, revision, orgID); err != nil {
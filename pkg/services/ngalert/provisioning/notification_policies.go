@@ -91,12 +91,6 @@ func (nps *NotificationPolicyService) UpdatePolicyTree(ctx context.Context, orgI
 		return err
 	}
 
-	receivers[""] = struct{}{} // Allow empty receiver (inheriting from parent)
-	err = tree.ValidateReceivers(receivers)
-	if err != nil {
-		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
-	}
-
 	timeIntervals := map[string]struct{}{}
 	for _, mt := range revision.Config.AlertmanagerConfig.MuteTimeIntervals {
 		timeIntervals[mt.Name] = struct{}{}
@@ -104,9 +98,9 @@ func (nps *NotificationPolicyService) UpdatePolicyTree(ctx context.Context, orgI
 	for _, mt := range revision.Config.AlertmanagerConfig.TimeIntervals {
 		timeIntervals[mt.Name] = struct{}{}
 	}
-	err = tree.ValidateMuteTimes(timeIntervals)
-	if err != nil {
-		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	// All the missing references are reported at once instead of failing when the configuration is applied
+	if refs := ValidateRouteReferences(&tree, receivers, timeIntervals); len(refs) > 0 {
+		return MakeErrRouteInvalidReferences(refs)
 	}
 
 	revision.Config.AlertmanagerConfig.Config.Route = &tree
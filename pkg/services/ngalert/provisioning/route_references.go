@@ -0,0 +1,88 @@
+package provisioning
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// RouteReferenceError is a reference of a route to a receiver or a time interval that doesn't exist.
+type RouteReferenceError struct {
+	// Field is the path of the reference in the route tree, e.g. routes[0].mute_time_intervals[1]
+	Field string `json:"field"`
+	// Kind is either receiver or time interval
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+const (
+	RouteReferenceKindReceiver     = "receiver"
+	RouteReferenceKindTimeInterval = "timeInterval"
+)
+
+// ValidateRouteReferences returns all the references of the route tree to receivers and time intervals that are not
+// in the sets. Empty receivers of nested routes are inherited from the parent and are not references.
+func ValidateRouteReferences(route *definitions.Route, receivers, timeIntervals map[string]struct{}) []RouteReferenceError {
+	var errs []RouteReferenceError
+	var walk func(r *definitions.Route, path string, root bool)
+	walk = func(r *definitions.Route, path string, root bool) {
+		if r == nil {
+			return
+		}
+		if _, ok := receivers[r.Receiver]; !ok && (root || r.Receiver != "") {
+			errs = append(errs, RouteReferenceError{Field: path + "receiver", Kind: RouteReferenceKindReceiver, Name: r.Receiver})
+		}
+		for i, name := range r.MuteTimeIntervals {
+			if _, ok := timeIntervals[name]; !ok {
+				errs = append(errs, RouteReferenceError{Field: fmt.Sprintf("%smute_time_intervals[%d]", path, i), Kind: RouteReferenceKindTimeInterval, Name: name})
+			}
+		}
+		for i, name := range r.ActiveTimeIntervals {
+			if _, ok := timeIntervals[name]; !ok {
+				errs = append(errs, RouteReferenceError{Field: fmt.Sprintf("%sactive_time_intervals[%d]", path, i), Kind: RouteReferenceKindTimeInterval, Name: name})
+			}
+		}
+		for i, child := range r.Routes {
+			walk(child, fmt.Sprintf("%sroutes[%d].", path, i), false)
+		}
+	}
+	walk(route, "", true)
+	return errs
+}
+
+// MakeErrRouteInvalidReferences creates an error with the ErrRouteInvalidReferences template. The error wraps
+// ErrValidation.
+func MakeErrRouteInvalidReferences(refs []RouteReferenceError) error {
+	missingReceivers := missingNames(refs, RouteReferenceKindReceiver)
+	missingIntervals := missingNames(refs, RouteReferenceKindTimeInterval)
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"References": refs,
+		},
+		Error: fmt.Errorf("%w: route references missing receivers %v and time intervals %v", ErrValidation, missingReceivers, missingIntervals),
+	}
+	if len(missingReceivers) > 0 {
+		data.Public["MissingReceivers"] = missingReceivers
+	}
+	if len(missingIntervals) > 0 {
+		data.Public["MissingTimeIntervals"] = missingIntervals
+	}
+	return ErrRouteInvalidReferences.Build(data)
+}
+
+func missingNames(refs []RouteReferenceError, kind string) []string {
+	names := map[string]struct{}{}
+	for _, ref := range refs {
+		if ref.Kind == kind {
+			names[ref.Name] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,65 @@
+package provisioning
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestValidateRouteReferences(t *testing.T) {
+	receivers := map[string]struct{}{"team-a": {}, "team-b": {}}
+	timeIntervals := map[string]struct{}{"weekends": {}}
+
+	t.Run("no errors when all references exist", func(t *testing.T) {
+		route := &definitions.Route{
+			Receiver: "team-a",
+			Routes: []*definitions.Route{
+				{Receiver: "team-b", MuteTimeIntervals: []string{"weekends"}},
+				{ActiveTimeIntervals: []string{"weekends"}},
+			},
+		}
+		require.Empty(t, ValidateRouteReferences(route, receivers, timeIntervals))
+	})
+
+	t.Run("returns all the missing references", func(t *testing.T) {
+		route := &definitions.Route{
+			Receiver: "unknown",
+			Routes: []*definitions.Route{
+				{
+					Receiver:          "team-b",
+					MuteTimeIntervals: []string{"weekends", "holidays"},
+					Routes: []*definitions.Route{
+						{Receiver: "other", ActiveTimeIntervals: []string{"nights"}},
+					},
+				},
+			},
+		}
+		require.Equal(t, []RouteReferenceError{
+			{Field: "receiver", Kind: RouteReferenceKindReceiver, Name: "unknown"},
+			{Field: "routes[0].mute_time_intervals[1]", Kind: RouteReferenceKindTimeInterval, Name: "holidays"},
+			{Field: "routes[0].routes[0].receiver", Kind: RouteReferenceKindReceiver, Name: "other"},
+			{Field: "routes[0].routes[0].active_time_intervals[0]", Kind: RouteReferenceKindTimeInterval, Name: "nights"},
+		}, ValidateRouteReferences(route, receivers, timeIntervals))
+	})
+}
+
+func TestMakeErrRouteInvalidReferences(t *testing.T) {
+	err := MakeErrRouteInvalidReferences([]RouteReferenceError{
+		{Field: "receiver", Kind: RouteReferenceKindReceiver, Name: "unknown"},
+		{Field: "routes[0].receiver", Kind: RouteReferenceKindReceiver, Name: "unknown"},
+		{Field: "routes[0].mute_time_intervals[0]", Kind: RouteReferenceKindTimeInterval, Name: "holidays"},
+	})
+	require.ErrorIs(t, err, ErrRouteInvalidReferences)
+	require.ErrorIs(t, err, ErrValidation)
+
+	var utilErr errutil.Error
+	require.True(t, errors.As(err, &utilErr))
+	require.Equal(t, []string{"unknown"}, utilErr.PublicPayload["MissingReceivers"])
+	require.Equal(t, []string{"holidays"}, utilErr.PublicPayload["MissingTimeIntervals"])
+	require.Len(t, utilErr.PublicPayload["References"], 3)
+	require.Equal(t, "Notification policy references receivers [unknown] and time intervals [holidays] that do not exist. Create them or remove the references and try again.", utilErr.PublicMessage)
+}
@@ -25,4 +25,23 @@ func (f FakeReceiverPermissionsService) CopyPermissions(ctx context.Context, org
 	return 0, nil
 }
 
+func (f FakeReceiverPermissionsService) RenamePermissions(ctx context.Context, orgID int64, user identity.Requester, oldUID, newUID string) error {
+	return nil
+}
+
 var _ accesscontrol.ReceiverPermissionsService = new(FakeReceiverPermissionsService)
+
+type FakeAlertRulePermissionsService struct {
+	*actest.FakePermissionsService
+}
+
+func NewFakeAlertRulePermissionsService() *FakeAlertRulePermissionsService {
+	return &FakeAlertRulePermissionsService{
+		FakePermissionsService: &actest.FakePermissionsService{},
+	}
+}
+
+func (f FakeAlertRulePermissionsService) SetDefaultPermissions(ctx context.Context, orgID int64, user identity.Requester, uid string) {
+}
+
+var _ accesscontrol.AlertRulePermissionsService = new(FakeAlertRulePermissionsService)
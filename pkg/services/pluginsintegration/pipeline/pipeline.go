@@ -63,6 +63,7 @@ func ProvideInitializationStage(cfg *config.PluginManagementCfg, pr registry.Ser
 	pm process.Manager, externalServiceRegistry auth.ExternalServiceRegistry,
 	roleRegistry pluginaccesscontrol.RoleRegistry,
 	actionSetRegistry pluginaccesscontrol.ActionSetRegistry,
+	resourcePermissionsRegistry pluginaccesscontrol.ResourcePermissionsRegistry,
 	pluginEnvProvider envvars.Provider,
 	tracer tracing.Tracer) *initialization.Initialize {
 	return initialization.New(cfg, initialization.Opts{
@@ -72,6 +73,7 @@ func ProvideInitializationStage(cfg *config.PluginManagementCfg, pr registry.Ser
 			initialization.BackendProcessStartStep(pm),
 			RegisterPluginRolesStep(roleRegistry),
 			RegisterActionSetsStep(actionSetRegistry),
+			RegisterResourcePermissionsStep(resourcePermissionsRegistry),
 			ReportBuildMetrics,
 			initialization.PluginRegistrationStep(pr),
 		},
@@ -122,6 +122,33 @@ func (r *RegisterActionSets) Register(ctx context.Context, p *plugins.Plugin) (*
 	return p, nil
 }
 
+// RegisterResourcePermissions implements an InitializeFunc for registering the plugin resources with managed permissions.
+type RegisterResourcePermissions struct {
+	log                         log.Logger
+	resourcePermissionsRegistry pluginaccesscontrol.ResourcePermissionsRegistry
+}
+
+// RegisterResourcePermissionsStep returns a new InitializeFunc for registering the plugin resources with managed permissions.
+func RegisterResourcePermissionsStep(registry pluginaccesscontrol.ResourcePermissionsRegistry) initialization.InitializeFunc {
+	return newRegisterResourcePermissions(registry).Register
+}
+
+func newRegisterResourcePermissions(registry pluginaccesscontrol.ResourcePermissionsRegistry) *RegisterResourcePermissions {
+	return &RegisterResourcePermissions{
+		log:                         log.New("plugins.resourcepermissions.registration"),
+		resourcePermissionsRegistry: registry,
+	}
+}
+
+// Register registers the plugin resources with managed permissions.
+func (r *RegisterResourcePermissions) Register(ctx context.Context, p *plugins.Plugin) (*plugins.Plugin, error) {
+	if err := r.resourcePermissionsRegistry.RegisterResourcePermissions(ctx, p.ID, p.ResourcePermissions); err != nil {
+		r.log.Warn("Plugin resource permissions registration failed", "pluginId", p.ID, "error", err)
+		return nil, err
+	}
+	return p, nil
+}
+
 // ReportBuildMetrics reports build information for all plugins, except core and bundled plugins.
 func ReportBuildMetrics(_ context.Context, p *plugins.Plugin) (*plugins.Plugin, error) {
 	if !p.IsCorePlugin() && !p.IsBundledPlugin() {
@@ -37,6 +37,11 @@ type ActionSetRegistry interface {
 	RegisterActionSets(ctx context.Context, ID string, registrations []plugins.ActionSet) error
 }
 
+// ResourcePermissionsRegistry handles the plugin resources with managed permissions
+type ResourcePermissionsRegistry interface {
+	RegisterResourcePermissions(ctx context.Context, ID string, registrations []plugins.ResourcePermissionRegistration) error
+}
+
 func ReqCanAdminPlugins(cfg *setting.Cfg) func(rc *contextmodel.ReqContext) bool {
 	// Legacy handler that protects access to the Configuration > Plugins page
 	return func(rc *contextmodel.ReqContext) bool {
@@ -35,24 +35,7 @@ func (m *actionNameMigrator) Exec(sess *xorm.Session, migrator *migrator.Migrato
 }
 
 func (m *actionNameMigrator) migrateActionNames() error {
-	actionNameMapping := map[string]string{
-		"licensing:update":         "licensing:write",
-		"reports.admin:create":     "reports:create",
-		"reports.admin:write":      "reports:write",
-		"org.users.role:update":    accesscontrol.ActionOrgUsersWrite,
-		"users.authtoken:update":   accesscontrol.ActionUsersAuthTokenUpdate,
-		"users.password:update":    accesscontrol.ActionUsersPasswordUpdate,
-		"users.permissions:update": accesscontrol.ActionUsersPermissionsUpdate,
-		"users.quotas:update":      accesscontrol.ActionUsersQuotasUpdate,
-		"roles:list":               "roles:read",
-		"teams.roles:list":         "teams.roles:read",
-		"users.roles:list":         "users.roles:read",
-		"users.authtoken:list":     accesscontrol.ActionUsersAuthTokenList,
-		"users.quotas:list":        accesscontrol.ActionUsersQuotasList,
-		"users.permissions:list":   "users.permissions:read",
-		"alert.instances:update":   accesscontrol.ActionAlertingInstanceUpdate,
-		"alert.rules:update":       accesscontrol.ActionAlertingRuleUpdate,
-	}
+	actionNameMapping := accesscontrol.ActionAliases()
 
 	oldActionNames := make([]any, 0, len(actionNameMapping))
 	newActionNames := make([]any, 0, len(actionNameMapping))
@@ -210,4 +210,284 @@ func AddMigration(mg *migrator.Migrator) {
 		Type: migrator.UniqueIndex,
 		Cols: []string{"org_id", "user_id", "role_id"},
 	}))
+
+	mg.AddMigration("add column deny to permission table", migrator.NewAddColumnMigration(permissionV1, &migrator.Column{
+		Name: "deny", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	permissionRequestV1 := migrator.Table{
+		Name: "permission_request",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_attribute", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "permission", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "reviewer_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "resource", "resource_id", "status"}},
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create permission request table", migrator.NewAddTableMigration(permissionRequestV1))
+	mg.AddMigration("add index permission_request.org_id_resource_resource_id_status", migrator.NewAddIndexMigration(permissionRequestV1, permissionRequestV1.Indices[0]))
+	mg.AddMigration("add index permission_request.user_id", migrator.NewAddIndexMigration(permissionRequestV1, permissionRequestV1.Indices[1]))
+
+	temporaryPermissionV1 := migrator.Table{
+		Name: "temporary_permission",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_attribute", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "permission", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "granted_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "expires", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "resource", "resource_id", "user_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create temporary permission table", migrator.NewAddTableMigration(temporaryPermissionV1))
+	mg.AddMigration("add unique index temporary_permission.org_id_resource_resource_id_user_id", migrator.NewAddIndexMigration(temporaryPermissionV1, temporaryPermissionV1.Indices[0]))
+	mg.AddMigration("add index temporary_permission.expires", migrator.NewAddIndexMigration(temporaryPermissionV1, temporaryPermissionV1.Indices[1]))
+
+	groupRoleV1 := migrator.Table{
+		Name: "group_role",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt},
+			{Name: "group_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "role_id", Type: migrator.DB_BigInt},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "group_id", "role_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"group_id"}},
+		},
+	}
+
+	mg.AddMigration("create group role table", migrator.NewAddTableMigration(groupRoleV1))
+	mg.AddMigration("add unique index group_role_org_id_group_id_role_id", migrator.NewAddIndexMigration(groupRoleV1, groupRoleV1.Indices[0]))
+	mg.AddMigration("add index group_role.group_id", migrator.NewAddIndexMigration(groupRoleV1, groupRoleV1.Indices[1]))
+
+	userExternalGroupV1 := migrator.Table{
+		Name: "user_external_group",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "group_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"user_id", "group_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"group_id"}},
+		},
+	}
+
+	mg.AddMigration("create user external group table", migrator.NewAddTableMigration(userExternalGroupV1))
+	mg.AddMigration("add unique index user_external_group_user_id_group_id", migrator.NewAddIndexMigration(userExternalGroupV1, userExternalGroupV1.Indices[0]))
+	mg.AddMigration("add index user_external_group.group_id", migrator.NewAddIndexMigration(userExternalGroupV1, userExternalGroupV1.Indices[1]))
+
+	permissionJobV1 := migrator.Table{
+		Name: "permission_job",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "total", Type: migrator.DB_Int, Nullable: false},
+			{Name: "processed", Type: migrator.DB_Int, Nullable: false},
+			{Name: "failed", Type: migrator.DB_Int, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "resource"}},
+		},
+	}
+
+	mg.AddMigration("create permission job table", migrator.NewAddTableMigration(permissionJobV1))
+	mg.AddMigration("add index permission_job.org_id_resource", migrator.NewAddIndexMigration(permissionJobV1, permissionJobV1.Indices[0]))
+
+	permissionJobItemV1 := migrator.Table{
+		Name: "permission_job_item",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "job_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"job_id", "status"}},
+		},
+	}
+
+	mg.AddMigration("create permission job item table", migrator.NewAddTableMigration(permissionJobItemV1))
+	mg.AddMigration("add index permission_job_item.job_id_status", migrator.NewAddIndexMigration(permissionJobItemV1, permissionJobItemV1.Indices[0]))
+
+	permissionAuditV1 := migrator.Table{
+		Name: "permission_audit",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "builtin_role", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "group_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "permission", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "actions", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "resource", "resource_id", "created"}},
+		},
+	}
+
+	mg.AddMigration("create permission audit table", migrator.NewAddTableMigration(permissionAuditV1))
+	mg.AddMigration("add index permission_audit.org_id_resource_resource_id_created", migrator.NewAddIndexMigration(permissionAuditV1, permissionAuditV1.Indices[0]))
+
+	permissionMetadataV1 := migrator.Table{
+		Name: "permission_metadata",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "role_name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "scope", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "key", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "value", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "role_name", "scope", "key"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create permission metadata table", migrator.NewAddTableMigration(permissionMetadataV1))
+	mg.AddMigration("add unique index permission_metadata.org_id_role_name_scope_key", migrator.NewAddIndexMigration(permissionMetadataV1, permissionMetadataV1.Indices[0]))
+
+	seedVersionV1 := migrator.Table{
+		Name: "seed_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "builtin_role", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "version", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"builtin_role"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create seed version table", migrator.NewAddTableMigration(seedVersionV1))
+	mg.AddMigration("add unique index seed_version.builtin_role", migrator.NewAddIndexMigration(seedVersionV1, seedVersionV1.Indices[0]))
+
+	zanzanaOutboxV1 := migrator.Table{
+		Name: "zanzana_outbox",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "builtin_role", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "last_error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "next_attempt", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"next_attempt"}},
+		},
+	}
+
+	mg.AddMigration("create zanzana outbox table", migrator.NewAddTableMigration(zanzanaOutboxV1))
+	mg.AddMigration("add index zanzana_outbox.next_attempt", migrator.NewAddIndexMigration(zanzanaOutboxV1, zanzanaOutboxV1.Indices[0]))
+
+	// The roles granted to the basic roles when they were last seeded, seed_assignment is owned by the role seeding
+	// of Grafana Enterprise
+	seedGrantV1 := migrator.Table{
+		Name: "seed_grant",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "builtin_role", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "role_name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"builtin_role", "role_name"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create seed grant table", migrator.NewAddTableMigration(seedGrantV1))
+	mg.AddMigration("add unique index seed_grant.builtin_role_role_name", migrator.NewAddIndexMigration(seedGrantV1, seedGrantV1.Indices[0]))
+
+	// The version of the fixed roles whose tuples were last reconciled in each org
+	zanzanaFixedRoleVersionV1 := migrator.Table{
+		Name: "zanzana_fixed_role_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create zanzana fixed role version table", migrator.NewAddTableMigration(zanzanaFixedRoleVersionV1))
+	mg.AddMigration("add unique index zanzana_fixed_role_version.org_id", migrator.NewAddIndexMigration(zanzanaFixedRoleVersionV1, zanzanaFixedRoleVersionV1.Indices[0]))
+
+	// Container entries of the outbox write the tuple relating a moved resource to its folder
+	mg.AddMigration("add column container to zanzana_outbox table", migrator.NewAddColumnMigration(zanzanaOutboxV1, &migrator.Column{
+		Name: "container", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	// The commands of a permission job are stored so the job can be resumed after a restart
+	mg.AddMigration("add column commands to permission_job table", migrator.NewAddColumnMigration(permissionJobV1, &migrator.Column{
+		Name: "commands", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	// The fixed roles declared at each version of the fixed roles, the tuples of an org are updated with the changes
+	// between the version it was reconciled at and the declared one
+	zanzanaFixedRoleDeclarationV1 := migrator.Table{
+		Name: "zanzana_fixed_role_declaration",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "version", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "roles", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create zanzana fixed role declaration table", migrator.NewAddTableMigration(zanzanaFixedRoleDeclarationV1))
+	mg.AddMigration("add unique index zanzana_fixed_role_declaration.version", migrator.NewAddIndexMigration(zanzanaFixedRoleDeclarationV1, zanzanaFixedRoleDeclarationV1.Indices[0]))
+
+	// Group entries of the outbox write the tuples of the permissions granted to the members of an external group
+	mg.AddMigration("add column group_id to zanzana_outbox table", migrator.NewAddColumnMigration(zanzanaOutboxV1, &migrator.Column{
+		Name: "group_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
 }
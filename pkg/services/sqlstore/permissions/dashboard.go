@@ -172,7 +172,9 @@ func (f *accessControlDashboardPermissionFilter) buildClauses() {
 
 	orgID := f.user.GetOrgID()
 	filter, params := accesscontrol.UserRolesFilter(orgID, userID, f.user.GetTeams(), accesscontrol.GetOrgRoles(f.user))
-	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") "
+	// Deny permissions never grant the action
+	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") AND deny = ? "
+	params = append(params, false)
 	var args []any
 	builder := strings.Builder{}
 	builder.WriteRune('(')
@@ -350,7 +352,7 @@ func (f *accessControlDashboardPermissionFilter) buildClauses() {
 
 	builder.WriteRune(')')
 
-	f.where = clause{string: builder.String(), params: args}
+	f.where = f.excludeDenied(clause{string: builder.String(), params: args})
 }
 
 // With returns:
@@ -388,6 +390,22 @@ func (f *accessControlDashboardPermissionFilter) addRecQry(queryName string, whe
 	})
 }
 
+// excludeDenied restricts the where clause to the dashboards the user isn't denied the dashboard action on, whatever
+// the access granted on them, their folders or by wildcards.
+func (f *accessControlDashboardPermissionFilter) excludeDenied(where clause) clause {
+	if f.dashboardAction == "" {
+		return where
+	}
+	denied := getAllowedUIDs(accesscontrol.DenyAction(f.dashboardAction), f.user, dashboards.ScopeDashboardsPrefix)
+	if len(denied) == 0 {
+		return where
+	}
+	return clause{
+		string: "(" + where.string + " AND NOT (dashboard.uid IN (?" + strings.Repeat(", ?", len(denied)-1) + ") AND NOT dashboard.is_folder))",
+		params: append(where.params, denied...),
+	}
+}
+
 func actionsToCheck(action string, actionSets []string, permissions map[string][]string, wildcards ...accesscontrol.Wildcards) []any {
 	for _, scope := range permissions[action] {
 		for _, w := range wildcards {
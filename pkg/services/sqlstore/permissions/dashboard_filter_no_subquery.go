@@ -40,7 +40,9 @@ func (f *accessControlDashboardPermissionFilterNoFolderSubquery) buildClauses()
 
 	orgID := f.user.GetOrgID()
 	filter, params := accesscontrol.UserRolesFilter(orgID, userID, f.user.GetTeams(), accesscontrol.GetOrgRoles(f.user))
-	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") "
+	// Deny permissions never grant the action
+	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") AND deny = ? "
+	params = append(params, false)
 	var args []any
 	builder := strings.Builder{}
 	builder.WriteRune('(')
@@ -215,7 +217,7 @@ func (f *accessControlDashboardPermissionFilterNoFolderSubquery) buildClauses()
 	}
 	builder.WriteRune(')')
 
-	f.where = clause{string: builder.String(), params: args}
+	f.where = f.excludeDenied(clause{string: builder.String(), params: args})
 }
 
 func (f *accessControlDashboardPermissionFilterNoFolderSubquery) nestedFoldersSelectors(permSelector string, permSelectorArgs []any, leftTable string, leftCol string, _ string, orgID int64) (string, []any) {
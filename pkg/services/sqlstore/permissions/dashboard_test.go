@@ -91,6 +91,25 @@ func TestIntegration_DashboardPermissionFilter(t *testing.T) {
 			},
 			expectedResult: 6,
 		},
+		{
+			desc:       "Should not be able to view the dashboards denied with wildcard scope",
+			permission: dashboardaccess.PERMISSION_VIEW,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsAll},
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:110", Deny: true},
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:40", Deny: true},
+			},
+			expectedResult: 108,
+		},
+		{
+			desc:       "Should not be able to view dashboards with deny permissions",
+			permission: dashboardaccess.PERMISSION_VIEW,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:110", Deny: true},
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:40", Deny: true},
+			},
+			expectedResult: 0,
+		},
 		{
 			desc:       "Should be able to view all folders with folder wildcard",
 			permission: dashboardaccess.PERMISSION_VIEW,
@@ -173,6 +192,7 @@ func TestIntegration_DashboardPermissionFilter(t *testing.T) {
 		store := setupTest(t, 10, 110, tt.permissions)
 		recursiveQueriesAreSupported, err := store.RecursiveQueriesAreSupported()
 		require.NoError(t, err)
+		accesscontrol.PrefixDenyActions(tt.permissions)
 
 		usr := &user.SignedInUser{OrgID: 1, OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)}}
 
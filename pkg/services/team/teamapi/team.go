@@ -114,6 +114,12 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
 	}
 
+	// The team uid is needed to remove the tuples of the team once it is deleted
+	var teamUID string
+	if existing, err := tapi.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{OrgID: orgID, ID: teamID}); err == nil && existing != nil {
+		teamUID = existing.UID
+	}
+
 	if err := tapi.teamService.DeleteTeam(c.Req.Context(), &team.DeleteTeamCommand{OrgID: orgID, ID: teamID}); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
 			return response.Error(http.StatusNotFound, "Failed to delete Team. ID not found", nil)
@@ -122,7 +128,11 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 	}
 
 	// Clear associated team assignments, managed role and permissions
-	if err := tapi.ac.DeleteTeamPermissions(c.Req.Context(), orgID, teamID); err != nil {
+	if deprovisioner, ok := tapi.ac.(accesscontrol.Deprovisioner); ok {
+		if err := deprovisioner.DeprovisionTeam(c.Req.Context(), orgID, teamID, teamUID); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to delete Team permissions", err)
+		}
+	} else if err := tapi.ac.DeleteTeamPermissions(c.Req.Context(), orgID, teamID); err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete Team permissions", err)
 	}
 
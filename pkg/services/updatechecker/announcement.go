@@ -0,0 +1,174 @@
+package updatechecker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	announcementNamespace = "updatechecker.announcements"
+	grafanaUpdateKey      = "grafana_update"
+	dismissedKeyPrefix    = "dismissed."
+)
+
+var ErrAnnouncementNotFound = errutil.NotFound("updatechecker.announcementNotFound", errutil.WithPublicMessage("Announcement not found"))
+
+type AnnouncementSeverity string
+
+const (
+	AnnouncementSeverityInfo    AnnouncementSeverity = "info"
+	AnnouncementSeverityWarning AnnouncementSeverity = "warning"
+)
+
+// Announcement is a server side record of an available update. Admins dismiss announcements by ID, a new target
+// version creates a new announcement that is shown again.
+type Announcement struct {
+	ID             string               `json:"id"`
+	Severity       AnnouncementSeverity `json:"severity"`
+	CurrentVersion string               `json:"currentVersion"`
+	TargetVersion  string               `json:"targetVersion"`
+	Message        string               `json:"message"`
+	Created        time.Time            `json:"created"`
+	Updated        time.Time            `json:"updated"`
+}
+
+type AnnouncementStatus struct {
+	Announcement *Announcement `json:"announcement"`
+	Dismissed    bool          `json:"dismissed"`
+}
+
+// newGrafanaUpdateAnnouncement returns the announcement of an update of Grafana. grafana.com doesn't flag security
+// releases, patch releases of the running minor version are announced as warnings since they ship the fixes.
+func newGrafanaUpdateAnnouncement(current, target string, now time.Time) *Announcement {
+	severity := AnnouncementSeverityInfo
+	currVersion, err1 := version.NewVersion(current)
+	targetVersion, err2 := version.NewVersion(target)
+	if err1 == nil && err2 == nil {
+		curr, next := currVersion.Segments(), targetVersion.Segments()
+		if len(curr) >= 2 && len(next) >= 2 && curr[0] == next[0] && curr[1] == next[1] {
+			severity = AnnouncementSeverityWarning
+		}
+	}
+
+	return &Announcement{
+		ID:             "grafana-" + target,
+		Severity:       severity,
+		CurrentVersion: current,
+		TargetVersion:  target,
+		Message:        fmt.Sprintf("Grafana %s is available, you are running %s", target, current),
+		Created:        now,
+		Updated:        now,
+	}
+}
+
+type announcementStore struct {
+	kv *kvstore.NamespacedKVStore
+}
+
+func newAnnouncementStore(kv kvstore.KVStore) *announcementStore {
+	return &announcementStore{kv: kvstore.WithNamespace(kv, 0, announcementNamespace)}
+}
+
+func (s *announcementStore) get(ctx context.Context) (*Announcement, error) {
+	value, ok, err := s.kv.Get(ctx, grafanaUpdateKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var a Announcement
+	if err := json.Unmarshal([]byte(value), &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *announcementStore) save(ctx context.Context, a *Announcement) error {
+	value, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, grafanaUpdateKey, string(value))
+}
+
+func (s *announcementStore) delete(ctx context.Context) error {
+	return s.kv.Del(ctx, grafanaUpdateKey)
+}
+
+func (s *announcementStore) dismiss(ctx context.Context, userID int64, id string) error {
+	return s.kv.Set(ctx, dismissedKeyPrefix+strconv.FormatInt(userID, 10), id)
+}
+
+func (s *announcementStore) isDismissed(ctx context.Context, userID int64, id string) (bool, error) {
+	value, ok, err := s.kv.Get(ctx, dismissedKeyPrefix+strconv.FormatInt(userID, 10))
+	if err != nil {
+		return false, err
+	}
+	return ok && value == id, nil
+}
+
+// updateAnnouncement records the result of the last check. The announcement is kept while the target version doesn't
+// change, and removed once Grafana is up to date.
+func (s *GrafanaService) updateAnnouncement(ctx context.Context) error {
+	if s.announcements == nil {
+		return nil
+	}
+	if !s.UpdateAvailable() {
+		return s.announcements.delete(ctx)
+	}
+
+	target := s.LatestVersion()
+	existing, err := s.announcements.get(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	a := newGrafanaUpdateAnnouncement(s.grafanaVersion, target, now)
+	if existing != nil && existing.TargetVersion == target && existing.CurrentVersion == s.grafanaVersion {
+		a = existing
+		a.Updated = now
+	}
+	if err := s.announcements.save(ctx, a); err != nil {
+		return err
+	}
+	return s.notify(ctx, a)
+}
+
+// Announcement returns the current announcement of a Grafana update, if any, and whether the user dismissed it.
+func (s *GrafanaService) Announcement(ctx context.Context, userID int64) (*AnnouncementStatus, error) {
+	status := &AnnouncementStatus{}
+	if s.announcements == nil {
+		return status, nil
+	}
+	a, err := s.announcements.get(ctx)
+	if err != nil || a == nil {
+		return status, err
+	}
+	status.Announcement = a
+	status.Dismissed, err = s.announcements.isDismissed(ctx, userID, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// DismissAnnouncement dismisses the current announcement for the user.
+func (s *GrafanaService) DismissAnnouncement(ctx context.Context, userID int64, id string) error {
+	if s.announcements == nil {
+		return ErrAnnouncementNotFound.Errorf("announcements are not stored")
+	}
+	a, err := s.announcements.get(ctx)
+	if err != nil {
+		return err
+	}
+	if a == nil || a.ID != id {
+		return ErrAnnouncementNotFound.Errorf("announcement %s not found", id)
+	}
+	return s.announcements.dismiss(ctx, userID, id)
+}
@@ -0,0 +1,123 @@
+package updatechecker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+func TestGrafanaService_Announcement(t *testing.T) {
+	ctx := context.Background()
+	s := &GrafanaService{
+		grafanaVersion: "11.0.0",
+		announcements:  newAnnouncementStore(kvstore.NewFakeKVStore()),
+	}
+
+	status, err := s.Announcement(ctx, 1)
+	require.NoError(t, err)
+	require.Nil(t, status.Announcement)
+
+	s.latestVersion, s.hasUpdate = "11.0.1", true
+	require.NoError(t, s.updateAnnouncement(ctx))
+
+	status, err = s.Announcement(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "grafana-11.0.1", status.Announcement.ID)
+	require.Equal(t, AnnouncementSeverityWarning, status.Announcement.Severity)
+	require.False(t, status.Dismissed)
+
+	require.ErrorIs(t, s.DismissAnnouncement(ctx, 1, "grafana-10.0.0"), ErrAnnouncementNotFound)
+	require.NoError(t, s.DismissAnnouncement(ctx, 1, "grafana-11.0.1"))
+
+	status, err = s.Announcement(ctx, 1)
+	require.NoError(t, err)
+	require.True(t, status.Dismissed)
+	status, err = s.Announcement(ctx, 2)
+	require.NoError(t, err)
+	require.False(t, status.Dismissed)
+
+	// A new target version is announced again
+	s.latestVersion = "12.0.0"
+	require.NoError(t, s.updateAnnouncement(ctx))
+	status, err = s.Announcement(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "grafana-12.0.0", status.Announcement.ID)
+	require.Equal(t, AnnouncementSeverityInfo, status.Announcement.Severity)
+	require.False(t, status.Dismissed)
+
+	// The announcement is removed once Grafana is up to date
+	s.hasUpdate = false
+	require.NoError(t, s.updateAnnouncement(ctx))
+	status, err = s.Announcement(ctx, 1)
+	require.NoError(t, err)
+	require.Nil(t, status.Announcement)
+}
+
+func TestNewGrafanaUpdateAnnouncement(t *testing.T) {
+	now := time.Now()
+	require.Equal(t, AnnouncementSeverityWarning, newGrafanaUpdateAnnouncement("11.2.0", "11.2.3", now).Severity)
+	require.Equal(t, AnnouncementSeverityInfo, newGrafanaUpdateAnnouncement("11.2.0", "11.3.0", now).Severity)
+	require.Equal(t, AnnouncementSeverityInfo, newGrafanaUpdateAnnouncement("invalid", "11.3.0", now).Severity)
+}
+
+type countingNotifier struct {
+	name     string
+	versions []string
+	err      error
+}
+
+func (n *countingNotifier) Name() string {
+	return n.name
+}
+
+func (n *countingNotifier) NotifyUpdate(_ context.Context, a *Announcement) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.versions = append(n.versions, a.TargetVersion)
+	return nil
+}
+
+func TestGrafanaService_NotifyOncePerVersion(t *testing.T) {
+	ctx := context.Background()
+	kv := kvstore.NewFakeKVStore()
+	notifier := &countingNotifier{name: "failing", err: errors.New("unavailable")}
+	other := &countingNotifier{name: "other"}
+
+	// Replicas share the announcement state
+	replicas := make([]*GrafanaService, 2)
+	for i := range replicas {
+		replicas[i] = &GrafanaService{
+			grafanaVersion: "11.0.0",
+			latestVersion:  "11.0.1",
+			hasUpdate:      true,
+			announcements:  newAnnouncementStore(kv),
+		}
+		replicas[i].RegisterNotifier(notifier)
+		replicas[i].RegisterNotifier(other)
+	}
+
+	// Failed notifications are retried, the notifiers that succeeded are not notified again
+	require.Error(t, replicas[0].updateAnnouncement(ctx))
+	require.Equal(t, []string{"11.0.1"}, other.versions)
+	notifier.err = nil
+
+	for _, r := range replicas {
+		require.NoError(t, r.updateAnnouncement(ctx))
+		require.NoError(t, r.updateAnnouncement(ctx))
+	}
+	require.Equal(t, []string{"11.0.1"}, notifier.versions)
+	require.Equal(t, []string{"11.0.1"}, other.versions)
+
+	for _, r := range replicas {
+		r.latestVersion = "11.0.2"
+		require.NoError(t, r.updateAnnouncement(ctx))
+	}
+	require.Equal(t, []string{"11.0.1", "11.0.2"}, notifier.versions)
+	require.Equal(t, []string{"11.0.1", "11.0.2"}, other.versions)
+}
@@ -6,16 +6,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
-	"github.com/hashicorp/go-version"
 	"go.opentelemetry.io/otel/codes"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient/httpclientprovider"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -25,6 +25,7 @@ const grafanaStableVersionURL = "https://grafana.com/api/grafana/versions/stable
 type GrafanaService struct {
 	hasUpdate     bool
 	latestVersion string
+	cohort        *RolloutCohort
 
 	enabled        bool
 	grafanaVersion string
@@ -32,9 +33,17 @@ type GrafanaService struct {
 	mutex          sync.RWMutex
 	log            log.Logger
 	tracer         tracing.Tracer
+	schedule       checkSchedule
+	announcements  *announcementStore
+	rollout        *rolloutStore
+	parseOutcome   ParseOutcome
+	lock           *serverlock.ServerLockService
+	notifiers      []UpdateNotifier
+	notifiersMu    sync.RWMutex
 }
 
-func ProvideGrafanaService(cfg *setting.Cfg, tracer tracing.Tracer) (*GrafanaService, error) {
+func ProvideGrafanaService(cfg *setting.Cfg, tracer tracing.Tracer, kvStore kvstore.KVStore,
+	lock *serverlock.ServerLockService) (*GrafanaService, error) {
 	logger := log.New("grafana.update.checker")
 	cl, err := httpclient.New(httpclient.Options{
 		Middlewares: []httpclient.Middleware{
@@ -44,13 +53,30 @@ func ProvideGrafanaService(cfg *setting.Cfg, tracer tracing.Tracer) (*GrafanaSer
 	if err != nil {
 		return nil, err
 	}
-	return &GrafanaService{
+
+	schedule, err := newCheckSchedule(cfg.UpdateCheckSchedule, time.Hour*24)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := ParseUpdateChannel(cfg.UpdateCheckChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &GrafanaService{
 		enabled:        cfg.CheckForGrafanaUpdates,
 		grafanaVersion: cfg.BuildVersion,
 		httpClient:     cl,
 		log:            logger,
 		tracer:         tracer,
-	}, nil
+		schedule:       schedule,
+		announcements:  newAnnouncementStore(kvStore),
+		rollout:        newRolloutStore(kvStore),
+		lock:           lock,
+		parseOutcome:   parseVersion(cfg.BuildVersion, channel),
+	}
+	s.RegisterNotifier(logNotifier{log: logger})
+	return s, nil
 }
 
 func (s *GrafanaService) IsDisabled() bool {
@@ -58,21 +84,7 @@ func (s *GrafanaService) IsDisabled() bool {
 }
 
 func (s *GrafanaService) Run(ctx context.Context) error {
-	s.instrumentedCheckForUpdates(ctx)
-
-	ticker := time.NewTicker(time.Hour * 24)
-	run := true
-
-	for run {
-		select {
-		case <-ticker.C:
-			s.instrumentedCheckForUpdates(ctx)
-		case <-ctx.Done():
-			run = false
-		}
-	}
-
-	return ctx.Err()
+	return runScheduled(ctx, s.schedule, s.instrumentedCheckForUpdates)
 }
 
 func (s *GrafanaService) instrumentedCheckForUpdates(ctx context.Context) {
@@ -87,6 +99,7 @@ func (s *GrafanaService) instrumentedCheckForUpdates(ctx context.Context) {
 		return
 	}
 	ctxLogger.Info("Update check succeeded", "duration", time.Since(start))
+	s.publishUpdate(ctx)
 }
 
 func (s *GrafanaService) checkForUpdates(ctx context.Context) error {
@@ -111,7 +124,8 @@ func (s *GrafanaService) checkForUpdates(ctx context.Context) error {
 	}
 
 	type grafanaVersionJSON struct {
-		Version string `json:"version"`
+		Version string  `json:"version"`
+		Rollout Rollout `json:"rollout,omitempty"`
 	}
 	var latest grafanaVersionJSON
 	err = json.Unmarshal(body, &latest)
@@ -119,18 +133,32 @@ func (s *GrafanaService) checkForUpdates(ctx context.Context) error {
 		return fmt.Errorf("failed to unmarshal response from grafana.com: %w", err)
 	}
 
+	var cohort *RolloutCohort
+	if _, staged := latest.Rollout[latest.Version]; staged && s.rollout != nil {
+		instanceID, err := s.rollout.getInstanceID(ctx)
+		if err != nil {
+			// The update is surfaced rather than hidden until the instance can be assigned to a cohort.
+			ctxLogger.Warn("Failed to assign the instance to a rollout cohort", "error", err)
+		} else {
+			cohort = newRolloutCohort(latest.Rollout, latest.Version, instanceID)
+		}
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-	// only check for updates in stable versions
-	if !strings.Contains(s.grafanaVersion, "-") {
-		s.latestVersion = latest.Version
-		s.hasUpdate = latest.Version != s.grafanaVersion
+	if !s.parseOutcome.Compare {
+		return nil
 	}
-
-	currVersion, err1 := version.NewVersion(s.grafanaVersion)
-	latestVersion, err2 := version.NewVersion(s.latestVersion)
-	if err1 == nil && err2 == nil {
-		s.hasUpdate = currVersion.LessThan(latestVersion)
+	s.latestVersion = latest.Version
+	s.hasUpdate, err = s.parseOutcome.isOutdated(latest.Version)
+	if err != nil {
+		ctxLogger.Debug("Failed to compare versions", "error", err)
+		s.hasUpdate = latest.Version != s.parseOutcome.Release
+	}
+	s.cohort = cohort
+	if s.hasUpdate && cohort != nil && !cohort.Included {
+		ctxLogger.Debug("Update is not rolled out to the instance yet", "version", latest.Version, "percent", cohort.Percent, "bucket", cohort.Bucket)
+		s.hasUpdate = false
 	}
 
 	return nil
@@ -142,6 +170,19 @@ func (s *GrafanaService) UpdateAvailable() bool {
 	return s.hasUpdate
 }
 
+// ParseOutcome explains how the running version is compared to the latest release.
+func (s *GrafanaService) ParseOutcome() ParseOutcome {
+	return s.parseOutcome
+}
+
+// RolloutCohort returns the decision whether the instance is in the cohort the latest version is announced to, nil if
+// the latest version isn't rolled out in stages.
+func (s *GrafanaService) RolloutCohort() *RolloutCohort {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.cohort
+}
+
 func (s *GrafanaService) LatestVersion() string {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -0,0 +1,135 @@
+package updatechecker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+)
+
+const (
+	notifiedKey = "notified"
+	// announcementLockName is the server lock held while the announcement is updated and notified, only one replica
+	// of the instance does it at a time.
+	announcementLockName = "updatechecker-grafana-announcement"
+)
+
+// UpdateNotifier is notified once of each new Grafana version across the replicas of the instance.
+type UpdateNotifier interface {
+	// Name identifies the notifier in the versions recorded as notified, it must not change between releases.
+	Name() string
+	NotifyUpdate(ctx context.Context, announcement *Announcement) error
+}
+
+// logNotifier logs the new Grafana versions, it is registered by default so operators reading the logs of any
+// replica see each new version once.
+type logNotifier struct {
+	log log.Logger
+}
+
+func (n logNotifier) Name() string {
+	return "log"
+}
+
+func (n logNotifier) NotifyUpdate(_ context.Context, a *Announcement) error {
+	n.log.Info("A new Grafana version is available", "currentVersion", a.CurrentVersion, "targetVersion", a.TargetVersion, "severity", a.Severity)
+	return nil
+}
+
+// RegisterNotifier registers a notifier of the new Grafana versions.
+func (s *GrafanaService) RegisterNotifier(notifier UpdateNotifier) {
+	s.notifiersMu.Lock()
+	defer s.notifiersMu.Unlock()
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// publishUpdate updates the announcement and notifies the new version while holding the server lock. The replicas
+// that can't acquire the lock skip it, the replica holding it publishes the same result.
+func (s *GrafanaService) publishUpdate(ctx context.Context) {
+	ctxLogger := s.log.FromContext(ctx)
+	publish := func(ctx context.Context) {
+		if err := s.updateAnnouncement(ctx); err != nil {
+			ctxLogger.Warn("Failed to update announcement", "error", err)
+		}
+	}
+
+	if s.lock == nil {
+		publish(ctx)
+		return
+	}
+
+	err := s.lock.LockExecuteAndRelease(ctx, announcementLockName, 10*time.Minute, publish)
+	var lockedErr *serverlock.ServerLockExistsError
+	if errors.As(err, &lockedErr) {
+		ctxLogger.Debug("Announcement is published by another replica")
+		return
+	}
+	if err != nil {
+		ctxLogger.Warn("Failed to acquire the announcement lock", "error", err)
+	}
+}
+
+// notify notifies the announcement to the notifiers that didn't notify its target version yet. The version is
+// recorded per notifier once it succeeded, the notifiers that failed are retried after the next check without
+// notifying the others again.
+func (s *GrafanaService) notify(ctx context.Context, a *Announcement) error {
+	s.notifiersMu.RLock()
+	notifiers := slices.Clone(s.notifiers)
+	s.notifiersMu.RUnlock()
+	if len(notifiers) == 0 {
+		return nil
+	}
+
+	notified, err := s.announcements.notified(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs    []error
+		changed bool
+	)
+	for _, n := range notifiers {
+		if slices.Contains(notified[n.Name()], a.TargetVersion) {
+			continue
+		}
+		if err := n.NotifyUpdate(ctx, a); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			continue
+		}
+		notified[n.Name()] = append(notified[n.Name()], a.TargetVersion)
+		changed = true
+	}
+	if changed {
+		if err := s.announcements.setNotified(ctx, notified); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notified returns the versions notified by each notifier.
+func (s *announcementStore) notified(ctx context.Context) (map[string][]string, error) {
+	versions := make(map[string][]string)
+	value, ok, err := s.kv.Get(ctx, notifiedKey)
+	if err != nil || !ok {
+		return versions, err
+	}
+	if err := json.Unmarshal([]byte(value), &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *announcementStore) setNotified(ctx context.Context, versions map[string][]string) error {
+	value, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, notifiedKey, string(value))
+}
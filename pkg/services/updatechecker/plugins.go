@@ -32,6 +32,7 @@ type PluginsService struct {
 	mutex          sync.RWMutex
 	log            log.Logger
 	tracer         tracing.Tracer
+	schedule       checkSchedule
 	updateCheckURL *url.URL
 }
 
@@ -46,6 +47,11 @@ func ProvidePluginsService(cfg *setting.Cfg, pluginStore pluginstore.Store, trac
 		return nil, err
 	}
 
+	schedule, err := newCheckSchedule(cfg.UpdateCheckSchedule, time.Minute*10)
+	if err != nil {
+		return nil, err
+	}
+
 	updateCheckURL, err := url.JoinPath(cfg.GrafanaComAPIURL, "plugins", "versioncheck")
 	if err != nil {
 		return nil, err
@@ -62,6 +68,7 @@ func ProvidePluginsService(cfg *setting.Cfg, pluginStore pluginstore.Store, trac
 		httpClient:       cl,
 		log:              logger,
 		tracer:           tracer,
+		schedule:         schedule,
 		pluginStore:      pluginStore,
 		availableUpdates: make(map[string]string),
 		updateCheckURL:   parsedUpdateCheckURL,
@@ -73,21 +80,7 @@ func (s *PluginsService) IsDisabled() bool {
 }
 
 func (s *PluginsService) Run(ctx context.Context) error {
-	s.instrumentedCheckForUpdates(ctx)
-
-	ticker := time.NewTicker(time.Minute * 10)
-	run := true
-
-	for run {
-		select {
-		case <-ticker.C:
-			s.instrumentedCheckForUpdates(ctx)
-		case <-ctx.Done():
-			run = false
-		}
-	}
-
-	return ctx.Err()
+	return runScheduled(ctx, s.schedule, s.instrumentedCheckForUpdates)
 }
 
 func (s *PluginsService) HasUpdate(ctx context.Context, pluginID string) (string, bool) {
@@ -0,0 +1,91 @@
+package updatechecker
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const (
+	rolloutNamespace = "updatechecker.rollout"
+	instanceIDKey    = "instance_id"
+)
+
+// Rollout is the optional staged rollout section of the manifest. It maps versions to the percent of the instances
+// they are announced to, versions that aren't listed are announced to every instance.
+type Rollout map[string]float64
+
+// RolloutCohort is the decision whether the instance is in the cohort an update is announced to. The bucket of an
+// instance is stable for a version, the cohort grows with the percent of the rollout.
+type RolloutCohort struct {
+	Version string `json:"version"`
+	// Percent is the percent of the instances the version is announced to
+	Percent float64 `json:"percent"`
+	// Bucket of the instance for the version, from 0 to 99
+	Bucket   int  `json:"bucket"`
+	Included bool `json:"included"`
+}
+
+// newRolloutCohort returns the cohort decision of the instance for the version of the rollout, nil if the version
+// isn't staged.
+func newRolloutCohort(rollout Rollout, version, instanceID string) *RolloutCohort {
+	percent, ok := rollout[version]
+	if !ok {
+		return nil
+	}
+	bucket := rolloutBucket(instanceID, version)
+	return &RolloutCohort{
+		Version:  version,
+		Percent:  percent,
+		Bucket:   bucket,
+		Included: float64(bucket) < percent,
+	}
+}
+
+// rolloutBucket hashes the instance and version to a bucket from 0 to 99. The version is part of the hash so the same
+// instances aren't always the first ones to be notified.
+func rolloutBucket(instanceID, version string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(instanceID + ":" + version))
+	return int(h.Sum32() % 100)
+}
+
+// rolloutStore persists the anonymous ID of the instance, it is shared by the replicas and only used to assign the
+// instance to rollout cohorts.
+type rolloutStore struct {
+	kv *kvstore.NamespacedKVStore
+
+	mu         sync.Mutex
+	instanceID string
+}
+
+func newRolloutStore(kv kvstore.KVStore) *rolloutStore {
+	return &rolloutStore{kv: kvstore.WithNamespace(kv, 0, rolloutNamespace)}
+}
+
+// getInstanceID returns the anonymous ID of the instance, the ID is created on first use.
+func (s *rolloutStore) getInstanceID(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.instanceID != "" {
+		return s.instanceID, nil
+	}
+
+	id, ok, err := s.kv.Get(ctx, instanceIDKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance id: %w", err)
+	}
+	if !ok || id == "" {
+		id = uuid.NewString()
+		if err := s.kv.Set(ctx, instanceIDKey, id); err != nil {
+			return "", fmt.Errorf("failed to save instance id: %w", err)
+		}
+	}
+	s.instanceID = id
+	return id, nil
+}
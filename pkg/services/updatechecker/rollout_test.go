@@ -0,0 +1,93 @@
+package updatechecker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestNewRolloutCohort(t *testing.T) {
+	rollout := Rollout{"11.1.0": 50, "11.2.0": 0, "11.3.0": 100}
+
+	require.Nil(t, newRolloutCohort(rollout, "11.0.0", "instance"), "versions that aren't staged have no cohort")
+
+	cohort := newRolloutCohort(rollout, "11.1.0", "instance")
+	require.Equal(t, cohort, newRolloutCohort(rollout, "11.1.0", "instance"), "the bucket of an instance is stable")
+	require.Equal(t, rolloutBucket("instance", "11.1.0"), cohort.Bucket)
+	require.Equal(t, cohort.Bucket < 50, cohort.Included)
+
+	require.False(t, newRolloutCohort(rollout, "11.2.0", "instance").Included)
+	require.True(t, newRolloutCohort(rollout, "11.3.0", "instance").Included)
+}
+
+func TestRolloutBucket(t *testing.T) {
+	included := 0
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		bucket := rolloutBucket(id, "11.1.0")
+		require.GreaterOrEqual(t, bucket, 0)
+		require.Less(t, bucket, 100)
+		if bucket < 50 {
+			included++
+		}
+	}
+	require.Greater(t, included, 0, "instances are spread across buckets")
+	require.Less(t, included, 10, "instances are spread across buckets")
+}
+
+func TestRolloutStore_InstanceID(t *testing.T) {
+	ctx := context.Background()
+	kv := kvstore.NewFakeKVStore()
+
+	id, err := newRolloutStore(kv).getInstanceID(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	other, err := newRolloutStore(kv).getInstanceID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, id, other, "replicas share the instance id")
+}
+
+func TestGrafanaService_checkForUpdatesRollout(t *testing.T) {
+	ctx := context.Background()
+	newService := func(t *testing.T, resp string) *GrafanaService {
+		t.Helper()
+		rollout := newRolloutStore(kvstore.NewFakeKVStore())
+		rollout.instanceID = "instance"
+		return &GrafanaService{
+			enabled:      true,
+			httpClient:   &fakeHTTPClient{fakeResp: resp},
+			log:          log.NewNopLogger(),
+			tracer:       tracing.InitializeTracerForTest(),
+			rollout:      rollout,
+			parseOutcome: parseVersion("11.0.0", UpdateChannelAuto),
+		}
+	}
+
+	t.Run("should surface updates without rollout", func(t *testing.T) {
+		s := newService(t, `{"version": "11.1.0"}`)
+		require.NoError(t, s.checkForUpdates(ctx))
+		require.True(t, s.UpdateAvailable())
+		require.Nil(t, s.RolloutCohort())
+	})
+
+	t.Run("should not surface updates outside of the cohort", func(t *testing.T) {
+		s := newService(t, `{"version": "11.1.0", "rollout": {"11.1.0": 0}}`)
+		require.NoError(t, s.checkForUpdates(ctx))
+		require.False(t, s.UpdateAvailable())
+		require.Equal(t, "11.1.0", s.LatestVersion())
+		require.NotNil(t, s.RolloutCohort())
+		require.False(t, s.RolloutCohort().Included)
+	})
+
+	t.Run("should surface updates in the cohort", func(t *testing.T) {
+		s := newService(t, `{"version": "11.1.0", "rollout": {"11.1.0": 100, "11.0.5": 0}}`)
+		require.NoError(t, s.checkForUpdates(ctx))
+		require.True(t, s.UpdateAvailable())
+		require.True(t, s.RolloutCohort().Included)
+	})
+}
@@ -0,0 +1,121 @@
+package updatechecker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var windowRegex = regexp.MustCompile(`^(\d{1,2}):(\d{2})-(\d{1,2}):(\d{2})$`)
+
+// checkSchedule decides when update checks may run.
+type checkSchedule interface {
+	// allows returns true if a check may run at the time, checks are run on startup when allowed.
+	allows(now time.Time) bool
+	// next returns the time of the next check after the time.
+	next(now time.Time) time.Time
+}
+
+// newCheckSchedule parses the update check schedule of the configuration, either a time window in the server time
+// zone, e.g. 22:00-06:00, in which checks run at the interval of the checker, or a cron expression. An empty schedule
+// runs the checks at the interval of the checker.
+func newCheckSchedule(schedule string, interval time.Duration) (checkSchedule, error) {
+	if schedule == "" {
+		return intervalSchedule{interval: interval}, nil
+	}
+
+	if m := windowRegex.FindStringSubmatch(schedule); m != nil {
+		start, err := minuteOfDay(m[1], m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid update check window %q: %w", schedule, err)
+		}
+		end, err := minuteOfDay(m[3], m[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid update check window %q: %w", schedule, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("invalid update check window %q: start and end are equal", schedule)
+		}
+		return windowSchedule{start: start, end: end, interval: interval}, nil
+	}
+
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update check schedule %q: %w", schedule, err)
+	}
+	return cronSchedule{schedule: s}, nil
+}
+
+func minuteOfDay(hours, minutes string) (int, error) {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	if h > 23 || m > 59 {
+		return 0, fmt.Errorf("%s:%s is not a time of day", hours, minutes)
+	}
+	return h*60 + m, nil
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+func (s intervalSchedule) allows(time.Time) bool { return true }
+
+func (s intervalSchedule) next(now time.Time) time.Time { return now.Add(s.interval) }
+
+// windowSchedule runs the checks at the interval inside the window, the window wraps around midnight when the end is
+// before the start.
+type windowSchedule struct {
+	start, end int
+	interval   time.Duration
+}
+
+func (s windowSchedule) allows(now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	if s.start < s.end {
+		return minute >= s.start && minute < s.end
+	}
+	return minute >= s.start || minute < s.end
+}
+
+func (s windowSchedule) next(now time.Time) time.Time {
+	if next := now.Add(s.interval); s.allows(next) {
+		return next
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), s.start/60, s.start%60, 0, 0, now.Location())
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// cronSchedule runs the checks at the times of the cron expression only.
+type cronSchedule struct {
+	schedule cron.Schedule
+}
+
+func (s cronSchedule) allows(time.Time) bool { return false }
+
+func (s cronSchedule) next(now time.Time) time.Time { return s.schedule.Next(now) }
+
+// runScheduled runs the check on the schedule until the context is done.
+func runScheduled(ctx context.Context, schedule checkSchedule, check func(ctx context.Context)) error {
+	if schedule.allows(time.Now()) {
+		check(ctx)
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(schedule.next(time.Now())))
+		select {
+		case <-timer.C:
+			check(ctx)
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
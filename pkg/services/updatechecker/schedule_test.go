@@ -0,0 +1,57 @@
+package updatechecker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCheckSchedule(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	t.Run("empty schedule runs at the interval", func(t *testing.T) {
+		s, err := newCheckSchedule("", 10*time.Minute)
+		require.NoError(t, err)
+		require.True(t, s.allows(at(12, 0)))
+		require.Equal(t, at(12, 10), s.next(at(12, 0)))
+	})
+
+	t.Run("window runs at the interval inside the window", func(t *testing.T) {
+		s, err := newCheckSchedule("22:00-06:00", time.Hour)
+		require.NoError(t, err)
+		require.True(t, s.allows(at(23, 0)))
+		require.True(t, s.allows(at(1, 0)))
+		require.False(t, s.allows(at(6, 0)))
+		require.False(t, s.allows(at(12, 0)))
+
+		require.Equal(t, at(2, 0), s.next(at(1, 0)))
+		require.Equal(t, at(22, 0), s.next(at(5, 30)))
+		require.Equal(t, at(22, 0), s.next(at(12, 0)))
+	})
+
+	t.Run("window within a day", func(t *testing.T) {
+		s, err := newCheckSchedule("01:30-02:00", time.Hour)
+		require.NoError(t, err)
+		require.True(t, s.allows(at(1, 45)))
+		require.False(t, s.allows(at(2, 0)))
+		require.Equal(t, at(1, 30).AddDate(0, 0, 1), s.next(at(1, 45)))
+	})
+
+	t.Run("cron runs at the scheduled times only", func(t *testing.T) {
+		s, err := newCheckSchedule("0 2 * * *", time.Hour)
+		require.NoError(t, err)
+		require.False(t, s.allows(at(2, 0)))
+		require.Equal(t, at(2, 0), s.next(at(1, 0)))
+		require.Equal(t, at(2, 0).AddDate(0, 0, 1), s.next(at(2, 0)))
+	})
+
+	t.Run("invalid schedules", func(t *testing.T) {
+		for _, schedule := range []string{"25:00-06:00", "06:00-06:00", "every night"} {
+			_, err := newCheckSchedule(schedule, time.Hour)
+			require.Error(t, err, schedule)
+		}
+	})
+}
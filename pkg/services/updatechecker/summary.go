@@ -0,0 +1,203 @@
+package updatechecker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/plugins"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/supportbundles"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// Summary aggregates everything that can be updated on the instance.
+type Summary struct {
+	Grafana GrafanaUpdate  `json:"grafana"`
+	Plugins []PluginUpdate `json:"plugins"`
+	// UntrustedPlugins are the installed plugins whose signature can't be trusted, they should be reinstalled from
+	// a trusted source. They are not security advisories, grafana.com doesn't publish advisories for plugins.
+	UntrustedPlugins []UntrustedPlugin `json:"untrustedPlugins"`
+	Counts           SummaryCounts     `json:"counts"`
+}
+
+type GrafanaUpdate struct {
+	// Enabled is false when checking for Grafana updates is disabled, the latest version is then unknown
+	Enabled         bool   `json:"enabled"`
+	CurrentVersion  string `json:"currentVersion"`
+	LatestVersion   string `json:"latestVersion,omitempty"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+	// ParseOutcome explains why the running version is or isn't compared to the latest version
+	ParseOutcome ParseOutcome `json:"parseOutcome"`
+	// RolloutCohort tells whether the instance is in the cohort of a staged rollout of the latest version
+	RolloutCohort *RolloutCohort `json:"rolloutCohort,omitempty"`
+}
+
+type PluginUpdate struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	CurrentVersion string `json:"currentVersion"`
+	LatestVersion  string `json:"latestVersion"`
+}
+
+type UntrustedPlugin struct {
+	ID        string                  `json:"id"`
+	Name      string                  `json:"name"`
+	Version   string                  `json:"version"`
+	Signature plugins.SignatureStatus `json:"signature"`
+	Reason    string                  `json:"reason"`
+}
+
+type SummaryCounts struct {
+	Total            int `json:"total"`
+	Grafana          int `json:"grafana"`
+	Plugins          int `json:"plugins"`
+	UntrustedPlugins int `json:"untrustedPlugins"`
+}
+
+// SummaryService summarizes the updates found by the Grafana and plugins update checkers.
+type SummaryService struct {
+	grafana     *GrafanaService
+	plugins     *PluginsService
+	pluginStore pluginstore.Store
+}
+
+func ProvideSummaryService(grafana *GrafanaService, plugins *PluginsService, pluginStore pluginstore.Store,
+	router routing.RouteRegister, accessControl ac.AccessControl, bundleRegistry supportbundles.Service) *SummaryService {
+	s := &SummaryService{
+		grafana:     grafana,
+		plugins:     plugins,
+		pluginStore: pluginStore,
+	}
+
+	authorize := ac.Middleware(accessControl)
+	router.Group("/api/admin", func(adminRoute routing.RouteRegister) {
+		adminRoute.Get("/updates", authorize(ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(s.getSummary))
+		adminRoute.Get("/updates/announcement", authorize(ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(s.getAnnouncement))
+		adminRoute.Post("/updates/announcement/:id/dismiss", authorize(ac.EvalPermission(ac.ActionSettingsWrite)), routing.Wrap(s.dismissAnnouncement))
+	}, middleware.ReqSignedIn)
+
+	bundleRegistry.RegisterSupportItemCollector(supportbundles.Collector{
+		UID:               "updates",
+		DisplayName:       "Updates",
+		Description:       "Available updates of Grafana and of the installed plugins",
+		IncludedByDefault: false,
+		Default:           true,
+		Fn:                s.supportBundleCollector,
+	})
+
+	return s
+}
+
+// Summary returns the updates found by the last checks. Plugins updated since then are not reported.
+func (s *SummaryService) Summary(ctx context.Context) *Summary {
+	summary := &Summary{
+		Grafana: GrafanaUpdate{
+			Enabled:        !s.grafana.IsDisabled(),
+			CurrentVersion: s.grafana.grafanaVersion,
+			ParseOutcome:   s.grafana.ParseOutcome(),
+		},
+		Plugins:          []PluginUpdate{},
+		UntrustedPlugins: []UntrustedPlugin{},
+	}
+	if summary.Grafana.Enabled {
+		summary.Grafana.LatestVersion = s.grafana.LatestVersion()
+		summary.Grafana.UpdateAvailable = s.grafana.UpdateAvailable()
+		summary.Grafana.RolloutCohort = s.grafana.RolloutCohort()
+	}
+
+	for _, p := range s.pluginStore.Plugins(ctx) {
+		if p.IsCorePlugin() {
+			continue
+		}
+		if latest, ok := s.plugins.HasUpdate(ctx, p.ID); ok {
+			summary.Plugins = append(summary.Plugins, PluginUpdate{
+				ID:             p.ID,
+				Name:           p.Name,
+				CurrentVersion: p.Info.Version,
+				LatestVersion:  latest,
+			})
+		}
+		if reason, ok := untrustedSignature(p.Signature); ok {
+			summary.UntrustedPlugins = append(summary.UntrustedPlugins, UntrustedPlugin{
+				ID:        p.ID,
+				Name:      p.Name,
+				Version:   p.Info.Version,
+				Signature: p.Signature,
+				Reason:    reason,
+			})
+		}
+	}
+	sort.Slice(summary.Plugins, func(i, j int) bool { return summary.Plugins[i].ID < summary.Plugins[j].ID })
+	sort.Slice(summary.UntrustedPlugins, func(i, j int) bool {
+		return summary.UntrustedPlugins[i].ID < summary.UntrustedPlugins[j].ID
+	})
+
+	if summary.Grafana.UpdateAvailable {
+		summary.Counts.Grafana = 1
+	}
+	summary.Counts.Plugins = len(summary.Plugins)
+	summary.Counts.UntrustedPlugins = len(summary.UntrustedPlugins)
+	summary.Counts.Total = summary.Counts.Grafana + summary.Counts.Plugins + summary.Counts.UntrustedPlugins
+	return summary
+}
+
+// untrustedSignature returns why a plugin with the signature status should be reinstalled from a trusted source.
+func untrustedSignature(status plugins.SignatureStatus) (string, bool) {
+	switch status {
+	case plugins.SignatureStatusInvalid:
+		return "The signature of the plugin is invalid", true
+	case plugins.SignatureStatusModified:
+		return "The files of the plugin were modified after it was signed", true
+	case plugins.SignatureStatusUnsigned:
+		return "The plugin is not signed", true
+	}
+	return "", false
+}
+
+// GET /api/admin/updates
+func (s *SummaryService) getSummary(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, s.Summary(c.Req.Context()))
+}
+
+// GET /api/admin/updates/announcement
+func (s *SummaryService) getAnnouncement(c *contextmodel.ReqContext) response.Response {
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to get user id", err)
+	}
+	status, err := s.grafana.Announcement(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get announcement", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// POST /api/admin/updates/announcement/:id/dismiss
+func (s *SummaryService) dismissAnnouncement(c *contextmodel.ReqContext) response.Response {
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to get user id", err)
+	}
+	if err := s.grafana.DismissAnnouncement(c.Req.Context(), userID, web.Params(c.Req)[":id"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to dismiss announcement", err)
+	}
+	return response.Success("Announcement dismissed")
+}
+
+func (s *SummaryService) supportBundleCollector(ctx context.Context) (*supportbundles.SupportItem, error) {
+	data, err := json.MarshalIndent(s.Summary(ctx), "", " ")
+	if err != nil {
+		return nil, err
+	}
+	return &supportbundles.SupportItem{
+		Filename:  "updates.json",
+		FileBytes: data,
+	}, nil
+}
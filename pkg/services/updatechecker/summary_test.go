@@ -0,0 +1,62 @@
+package updatechecker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+)
+
+func TestSummaryService_Summary(t *testing.T) {
+	store := &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{
+				JSONData:  plugins.JSONData{ID: "test-ds", Name: "Test DS", Info: plugins.Info{Version: "0.9.0"}},
+				Signature: plugins.SignatureStatusValid,
+			},
+			{
+				JSONData:  plugins.JSONData{ID: "test-panel", Name: "Test Panel", Info: plugins.Info{Version: "1.0.0"}},
+				Signature: plugins.SignatureStatusModified,
+			},
+			{
+				JSONData: plugins.JSONData{ID: "core-ds", Name: "Core DS", Info: plugins.Info{Version: "0.0.1"}},
+				Class:    plugins.ClassCore,
+			},
+		},
+	}
+
+	t.Run("should report available updates and untrusted plugins", func(t *testing.T) {
+		s := &SummaryService{
+			grafana: &GrafanaService{enabled: true, grafanaVersion: "11.0.0", latestVersion: "11.1.0", hasUpdate: true},
+			plugins: &PluginsService{
+				availableUpdates: map[string]string{"test-ds": "1.0.0", "core-ds": "1.0.0"},
+				pluginStore:      store,
+			},
+			pluginStore: store,
+		}
+
+		summary := s.Summary(context.Background())
+		require.Equal(t, GrafanaUpdate{Enabled: true, CurrentVersion: "11.0.0", LatestVersion: "11.1.0", UpdateAvailable: true}, summary.Grafana)
+		require.Equal(t, []PluginUpdate{{ID: "test-ds", Name: "Test DS", CurrentVersion: "0.9.0", LatestVersion: "1.0.0"}}, summary.Plugins)
+		require.Len(t, summary.UntrustedPlugins, 1)
+		require.Equal(t, "test-panel", summary.UntrustedPlugins[0].ID)
+		require.Equal(t, plugins.SignatureStatusModified, summary.UntrustedPlugins[0].Signature)
+		require.Equal(t, SummaryCounts{Total: 3, Grafana: 1, Plugins: 1, UntrustedPlugins: 1}, summary.Counts)
+	})
+
+	t.Run("should not report the latest Grafana version when checks are disabled", func(t *testing.T) {
+		s := &SummaryService{
+			grafana:     &GrafanaService{grafanaVersion: "11.0.0", latestVersion: "11.1.0", hasUpdate: true},
+			plugins:     &PluginsService{availableUpdates: map[string]string{}, pluginStore: store},
+			pluginStore: store,
+		}
+
+		summary := s.Summary(context.Background())
+		require.Equal(t, GrafanaUpdate{CurrentVersion: "11.0.0"}, summary.Grafana)
+		require.Empty(t, summary.Plugins)
+		require.Equal(t, SummaryCounts{Total: 1, UntrustedPlugins: 1}, summary.Counts)
+	})
+}
@@ -0,0 +1,108 @@
+package updatechecker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// UpdateChannel selects the releases the running version is compared to.
+type UpdateChannel string
+
+const (
+	// UpdateChannelAuto compares releases and custom builds of releases to the stable release, pre-releases are not
+	// compared.
+	UpdateChannelAuto UpdateChannel = "auto"
+	// UpdateChannelStable compares every build, including pre-releases, to the stable release.
+	UpdateChannelStable UpdateChannel = "stable"
+)
+
+type VersionKind string
+
+const (
+	VersionKindRelease    VersionKind = "release"
+	VersionKindPrerelease VersionKind = "prerelease"
+	VersionKindCustom     VersionKind = "custom"
+	VersionKindInvalid    VersionKind = "invalid"
+)
+
+// prereleaseRegex matches the pre-release suffixes of Grafana builds, e.g. beta1, rc2 or the 12345pre of nightly builds.
+var prereleaseRegex = regexp.MustCompile(`^(alpha|beta|rc|pre|preview|test|nightly|canary|\d+pre)[\w.]*$`)
+
+// ParseOutcome explains how the running version is compared to the latest release.
+type ParseOutcome struct {
+	Version string        `json:"version"`
+	Kind    VersionKind   `json:"kind"`
+	Channel UpdateChannel `json:"channel"`
+	// Release is the version of the release the build is compared as, e.g. 11.1.0 for 11.1.0-custom-abc
+	Release string `json:"release,omitempty"`
+	Compare bool   `json:"compare"`
+	Reason  string `json:"reason"`
+}
+
+// ParseUpdateChannel validates the update channel of the configuration, an empty channel is auto.
+func ParseUpdateChannel(channel string) (UpdateChannel, error) {
+	switch c := UpdateChannel(strings.ToLower(strings.TrimSpace(channel))); c {
+	case "":
+		return UpdateChannelAuto, nil
+	case UpdateChannelAuto, UpdateChannelStable:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid update check channel %q, expected %s or %s", channel, UpdateChannelAuto, UpdateChannelStable)
+}
+
+// parseVersion parses the running version. Build metadata is ignored, pre-release suffixes that aren't known Grafana
+// pre-releases are custom builds of the release, e.g. 11.1.0-custom-abc.
+func parseVersion(raw string, channel UpdateChannel) ParseOutcome {
+	outcome := ParseOutcome{Version: raw, Channel: channel}
+
+	v, err := version.NewSemver(strings.TrimPrefix(raw, "v"))
+	if err != nil {
+		outcome.Kind = VersionKindInvalid
+		outcome.Reason = fmt.Sprintf("%s is not a semantic version, it can't be compared to the latest release", raw)
+		return outcome
+	}
+	outcome.Release = v.Core().String()
+
+	switch pre := v.Prerelease(); {
+	case pre == "":
+		outcome.Kind = VersionKindRelease
+	case prereleaseRegex.MatchString(strings.ToLower(pre)):
+		outcome.Kind = VersionKindPrerelease
+	default:
+		outcome.Kind = VersionKindCustom
+	}
+
+	switch {
+	case outcome.Kind == VersionKindPrerelease && channel != UpdateChannelStable:
+		outcome.Reason = fmt.Sprintf("%s is a pre-release, set the stable channel to compare it to the latest release", raw)
+	case outcome.Kind == VersionKindCustom:
+		outcome.Compare = true
+		outcome.Reason = fmt.Sprintf("%s is a custom build of %s, it is compared as %s", raw, outcome.Release, outcome.Release)
+	case outcome.Kind == VersionKindPrerelease:
+		outcome.Compare = true
+		outcome.Reason = fmt.Sprintf("%s is a pre-release of %s, it is compared as %s on the stable channel", raw, outcome.Release, outcome.Release)
+	default:
+		outcome.Compare = true
+		outcome.Reason = fmt.Sprintf("%s is a release", outcome.Release)
+	}
+	return outcome
+}
+
+// isOutdated returns true if the release of the outcome is older than the latest version.
+func (o ParseOutcome) isOutdated(latest string) (bool, error) {
+	if !o.Compare {
+		return false, nil
+	}
+	current, err := version.NewVersion(o.Release)
+	if err != nil {
+		return false, err
+	}
+	latestVersion, err := version.NewSemver(strings.TrimPrefix(latest, "v"))
+	if err != nil {
+		return false, fmt.Errorf("invalid latest version %q: %w", latest, err)
+	}
+	return current.LessThan(latestVersion.Core()), nil
+}
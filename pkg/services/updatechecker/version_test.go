@@ -0,0 +1,66 @@
+package updatechecker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		version string
+		channel UpdateChannel
+		kind    VersionKind
+		release string
+		compare bool
+	}{
+		{version: "11.1.0", channel: UpdateChannelAuto, kind: VersionKindRelease, release: "11.1.0", compare: true},
+		{version: "v11.1.0", channel: UpdateChannelAuto, kind: VersionKindRelease, release: "11.1.0", compare: true},
+		{version: "11.1.3+security-01", channel: UpdateChannelAuto, kind: VersionKindRelease, release: "11.1.3", compare: true},
+		{version: "11.1.0-custom-abc", channel: UpdateChannelAuto, kind: VersionKindCustom, release: "11.1.0", compare: true},
+		{version: "11.1.0-beta1", channel: UpdateChannelAuto, kind: VersionKindPrerelease, release: "11.1.0", compare: false},
+		{version: "11.2.0-75321pre", channel: UpdateChannelAuto, kind: VersionKindPrerelease, release: "11.2.0", compare: false},
+		{version: "11.2.0-75321pre", channel: UpdateChannelStable, kind: VersionKindPrerelease, release: "11.2.0", compare: true},
+		{version: "main", channel: UpdateChannelStable, kind: VersionKindInvalid, compare: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version+" on "+string(tc.channel), func(t *testing.T) {
+			outcome := parseVersion(tc.version, tc.channel)
+			require.Equal(t, tc.kind, outcome.Kind)
+			require.Equal(t, tc.release, outcome.Release)
+			require.Equal(t, tc.compare, outcome.Compare)
+			require.NotEmpty(t, outcome.Reason)
+		})
+	}
+}
+
+func TestParseOutcome_IsOutdated(t *testing.T) {
+	outdated, err := parseVersion("11.1.0-custom-abc", UpdateChannelAuto).isOutdated("11.1.1")
+	require.NoError(t, err)
+	require.True(t, outdated)
+
+	outdated, err = parseVersion("11.1.0-custom-abc", UpdateChannelAuto).isOutdated("11.1.0")
+	require.NoError(t, err)
+	require.False(t, outdated)
+
+	outdated, err = parseVersion("11.2.0-75321pre", UpdateChannelAuto).isOutdated("11.3.0")
+	require.NoError(t, err)
+	require.False(t, outdated)
+
+	_, err = parseVersion("11.1.0", UpdateChannelAuto).isOutdated("latest")
+	require.Error(t, err)
+}
+
+func TestParseUpdateChannel(t *testing.T) {
+	channel, err := ParseUpdateChannel("")
+	require.NoError(t, err)
+	require.Equal(t, UpdateChannelAuto, channel)
+
+	channel, err = ParseUpdateChannel("Stable")
+	require.NoError(t, err)
+	require.Equal(t, UpdateChannelStable, channel)
+
+	_, err = ParseUpdateChannel("nightly")
+	require.Error(t, err)
+}
@@ -364,6 +364,8 @@ type Cfg struct {
 	// Analytics
 	CheckForGrafanaUpdates              bool
 	CheckForPluginUpdates               bool
+	UpdateCheckSchedule                 string
+	UpdateCheckChannel                  string
 	ReportingDistributor                string
 	ReportingEnabled                    bool
 	ApplicationInsightsConnectionString string
@@ -1146,6 +1148,8 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	analytics := iniFile.Section("analytics")
 	cfg.CheckForGrafanaUpdates = analytics.Key("check_for_updates").MustBool(true)
 	cfg.CheckForPluginUpdates = analytics.Key("check_for_plugin_updates").MustBool(true)
+	cfg.UpdateCheckSchedule = analytics.Key("update_check_schedule").String()
+	cfg.UpdateCheckChannel = analytics.Key("update_check_channel").MustString("auto")
 
 	cfg.GoogleAnalyticsID = analytics.Key("google_analytics_ua_id").String()
 	cfg.GoogleAnalytics4ID = analytics.Key("google_analytics_4_id").String()
@@ -6,6 +6,9 @@ type OrgQuota struct {
 	Dashboard  int64 `target:"dashboard"`
 	ApiKey     int64 `target:"api_key"`
 	AlertRule  int64 `target:"alert_rule"`
+	// Role and RoleAssignment limit the roles and role assignments created by managed permissions
+	Role           int64 `target:"role"`
+	RoleAssignment int64 `target:"role_assignment"`
 }
 
 type UserQuota struct {
@@ -43,6 +46,9 @@ func (cfg *Cfg) readQuotaSettings() {
 		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
 		ApiKey:     quota.Key("org_api_key").MustInt64(10),
 		AlertRule:  quota.Key("org_alert_rule").MustInt64(100),
+
+		Role:           quota.Key("org_role").MustInt64(-1),
+		RoleAssignment: quota.Key("org_role_assignment").MustInt64(-1),
 	}
 
 	// per User limits
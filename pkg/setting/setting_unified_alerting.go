@@ -67,6 +67,11 @@ const (
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
 )
 
+const (
+	notificationsAPIDefaultWriteRateLimit = 0
+	notificationsAPIDefaultWriteBurst     = 20
+)
+
 type UnifiedAlertingSettings struct {
 	AdminConfigPollInterval         time.Duration
 	AlertmanagerConfigPollInterval  time.Duration
@@ -126,6 +131,12 @@ type UnifiedAlertingSettings struct {
 	// should be stored in the database for each alert_rule in an organization including the current one.
 	// 0 value means no limit
 	RuleVersionRecordLimit int
+
+	// NotificationsAPIWriteRateLimit is the number of writes per second of each organization to the notification
+	// resources of the Kubernetes-style API. 0 value means no limit
+	NotificationsAPIWriteRateLimit float64
+	// NotificationsAPIWriteBurst is the number of writes of an organization that are allowed at once.
+	NotificationsAPIWriteBurst int
 }
 
 type RecordingRuleSettings struct {
@@ -465,6 +476,15 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return fmt.Errorf("setting 'rule_version_record_limit' is invalid, only 0 or a positive integer are allowed")
 	}
 
+	uaCfg.NotificationsAPIWriteRateLimit = ua.Key("notifications_api_write_rate_limit").MustFloat64(notificationsAPIDefaultWriteRateLimit)
+	if uaCfg.NotificationsAPIWriteRateLimit < 0 {
+		return fmt.Errorf("setting 'notifications_api_write_rate_limit' is invalid, only 0 or a positive number are allowed")
+	}
+	uaCfg.NotificationsAPIWriteBurst = ua.Key("notifications_api_write_burst").MustInt(notificationsAPIDefaultWriteBurst)
+	if uaCfg.NotificationsAPIWriteBurst < 1 {
+		return fmt.Errorf("setting 'notifications_api_write_burst' is invalid, only positive integers are allowed")
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
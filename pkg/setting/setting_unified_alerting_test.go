@@ -73,6 +73,16 @@ func TestCfg_ReadUnifiedAlertingSettings(t *testing.T) {
 			require.Equal(t, SchedulerBaseInterval, cfg.UnifiedAlerting.BaseInterval)
 		})
 	})
+
+	t.Run("should reject a 'notifications_api_write_burst' below 1", func(t *testing.T) {
+		s, err := cfg.Raw.NewSection("unified_alerting")
+		require.NoError(t, err)
+		_, err = s.NewKey("notifications_api_write_burst", "0")
+		require.NoError(t, err)
+		t.Cleanup(func() { s.DeleteKey("notifications_api_write_burst") })
+
+		require.Error(t, cfg.ReadUnifiedAlertingSettings(cfg.Raw))
+	})
 }
 
 func TestUnifiedAlertingSettings(t *testing.T) {
@@ -1,9 +1,16 @@
 package setting
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// debugSessionMinTTL is the shortest duration debug sessions are kept for, shorter ones expire before admins can
+// retrieve them.
+const debugSessionMinTTL = time.Minute
+
 type RBACSettings struct {
 	// Enable permission cache
 	PermissionCache bool
@@ -16,6 +23,41 @@ type RBACSettings struct {
 
 	OnlyStoreAccessActionSets bool
 
+	// Reject resource permission changes while still serving reads, used to freeze access control state during maintenance
+	PermissionsReadOnly bool
+
+	// Webhook receiving permission and role assignment changes, disabled when the url is empty
+	PermissionWebhookURL string
+	// Secret used to sign the webhook payloads
+	PermissionWebhookSecret string
+	// Events sent to the webhook, all events are sent when empty
+	PermissionWebhookEvents []string
+
+	// Longest duration allowed for temporary permission grants
+	TemporaryPermissionMaxDuration time.Duration
+
+	// Permission queries slower than this duration are logged with their query plan, disabled when zero
+	PermissionSlowQueryThreshold time.Duration
+
+	// Commit bulk permission writes chunk by chunk on SQLite instead of in a single transaction locking the database
+	SQLiteCommitPerChunk bool
+
+	// Duration the result of a permission write made with an idempotency key is returned again for retries of the
+	// write with the same key, disabled when zero
+	PermissionIdempotencyWindow time.Duration
+
+	// YAML file of the rules deciding the permissions of newly created resources, they are evaluated before the
+	// built-in rules
+	DefaultPermissionsPolicyFile string
+
+	// Capture the access control decisions of requests sent with the X-Grafana-RBAC-Debug header
+	DebugSessions bool
+	// Duration debug sessions can be retrieved for after the request, at least a minute
+	DebugSessionTTL time.Duration
+
+	// set of orgs where the Edit permission also grants managing the permissions of resources
+	editPermissionCanShareOrgs map[int64]struct{}
+
 	// set of resources that should generate managed permissions when created
 	resourcesWithPermissionsOnCreation map[string]struct{}
 
@@ -32,8 +74,34 @@ func (cfg *Cfg) readRBACSettings() {
 	s.ResetBasicRoles = rbac.Key("reset_basic_roles").MustBool(false)
 	s.SingleOrganization = rbac.Key("single_organization").MustBool(false)
 	s.OnlyStoreAccessActionSets = rbac.Key("only_store_access_action_sets").MustBool(false)
+	s.PermissionsReadOnly = rbac.Key("permissions_read_only").MustBool(false)
+	s.PermissionWebhookURL = rbac.Key("permission_webhook_url").MustString("")
+	s.PermissionWebhookSecret = rbac.Key("permission_webhook_secret").MustString("")
+	s.PermissionWebhookEvents = util.SplitString(rbac.Key("permission_webhook_events").MustString(""))
+	s.TemporaryPermissionMaxDuration = rbac.Key("temporary_permission_max_duration").MustDuration(24 * time.Hour)
+	s.PermissionSlowQueryThreshold = rbac.Key("permission_slow_query_threshold").MustDuration(0)
+	s.SQLiteCommitPerChunk = rbac.Key("sqlite_commit_per_chunk").MustBool(false)
+	s.PermissionIdempotencyWindow = rbac.Key("permission_idempotency_window").MustDuration(24 * time.Hour)
+	s.DefaultPermissionsPolicyFile = rbac.Key("default_permissions_policy_file").MustString("")
+	s.DebugSessions = rbac.Key("debug_sessions").MustBool(false)
+	s.DebugSessionTTL = rbac.Key("debug_session_ttl").MustDuration(10 * time.Minute)
+	if s.DebugSessionTTL < debugSessionMinTTL {
+		cfg.Logger.Warn("rbac debug_session_ttl is too short to retrieve sessions, using the minimum", "ttl", s.DebugSessionTTL, "minimum", debugSessionMinTTL)
+		s.DebugSessionTTL = debugSessionMinTTL
+	}
+
+	// List of orgs where the Edit permission of resources also allows sharing them
+	s.editPermissionCanShareOrgs = map[int64]struct{}{}
+	for _, id := range util.SplitString(rbac.Key("edit_permission_can_share_orgs").MustString("")) {
+		orgID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			cfg.Logger.Warn("Invalid org id in rbac edit_permission_can_share_orgs", "orgID", id)
+			continue
+		}
+		s.editPermissionCanShareOrgs[orgID] = struct{}{}
+	}
 
-	// List of resources to generate managed permissions for upon resource creation (dashboard, folder, service-account, datasource)
+	// List of resources to generate managed permissions for upon resource creation (dashboard, folder, service-account, datasource, library-panel)
 	resources := util.SplitString(rbac.Key("resources_with_managed_permissions_on_creation").MustString("dashboard, folder, service-account, datasource"))
 	s.resourcesWithPermissionsOnCreation = map[string]struct{}{}
 	for _, resource := range resources {
@@ -59,3 +127,10 @@ func (r RBACSettings) PermissionsWildcardSeed(resource string) bool {
 	_, ok := r.resourcesWithWildcardSeed[resource]
 	return ok
 }
+
+// EditPermissionCanShare returns true when the Edit permission of resources also grants managing their permissions
+// in the org.
+func (r RBACSettings) EditPermissionCanShare(orgID int64) bool {
+	_, ok := r.editPermissionCanShareOrgs[orgID]
+	return ok
+}
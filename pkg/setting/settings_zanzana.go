@@ -34,6 +34,20 @@ type ZanzanaSettings struct {
 	ListObjectsMaxResults uint32
 	// Deadline for the ListObjects() query. Default is 3 seconds.
 	ListObjectsDeadline time.Duration
+	// Max duration of a single call to the zanzana server, the deadline of the request is used when shorter.
+	// Default is 10 seconds, 0 disables it.
+	CallTimeout time.Duration
+	// Max number of tuples known to exist kept by the client to skip writing them again. Default is 0, which disables
+	// it. The writes of the reconciler are never skipped.
+	WriteCacheSize int
+	// TTL of the tuples known to exist. Default is 10 minutes.
+	WriteCacheTTL time.Duration
+	// If enabled, the sync fails on permissions whose action is not mapped to a relation of a resource kind
+	// part of the schema instead of only counting them.
+	StrictTranslation bool
+	// If enabled, the periodic reconciliation is split by org between the replicas. Each replica reconciles its orgs
+	// in turn under the reconciliation lock instead of a single replica reconciling all orgs.
+	ShardedReconciliation bool
 }
 
 func (cfg *Cfg) readZanzanaSettings() {
@@ -58,6 +72,11 @@ func (cfg *Cfg) readZanzanaSettings() {
 	s.CheckQueryCacheTTL = sec.Key("check_query_cache_ttl").MustDuration(10 * time.Second)
 	s.ListObjectsDeadline = sec.Key("list_objects_deadline").MustDuration(3 * time.Second)
 	s.ListObjectsMaxResults = uint32(sec.Key("list_objects_max_results").MustUint(1000))
+	s.CallTimeout = sec.Key("call_timeout").MustDuration(10 * time.Second)
+	s.WriteCacheSize = sec.Key("write_cache_size").MustInt(0)
+	s.WriteCacheTTL = sec.Key("write_cache_ttl").MustDuration(10 * time.Minute)
+	s.StrictTranslation = sec.Key("strict_translation").MustBool(false)
+	s.ShardedReconciliation = sec.Key("sharded_reconciliation").MustBool(false)
 
 	cfg.Zanzana = s
 }
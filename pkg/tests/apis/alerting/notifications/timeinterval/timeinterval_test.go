@@ -18,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1"
+	"github.com/grafana/grafana/pkg/apis/alerting_notifications/v0alpha1/fake"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/generated/clientset/versioned"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -71,7 +72,7 @@ func TestIntegrationResourceIdentifier(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "time-newInterval",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}
 
@@ -161,6 +162,15 @@ func TestIntegrationTimeIntervalAccessControl(t *testing.T) {
 		},
 	})
 
+	legacyWriter := helper.CreateUser("NotificationsWriter", apis.Org1, org.RoleNone, []resourcepermissions.SetResourcePermissionCommand{
+		{
+			Actions: []string{
+				accesscontrol.ActionAlertingNotificationsRead,
+				accesscontrol.ActionAlertingNotificationsWrite,
+			},
+		},
+	})
+
 	testCases := []testCase{
 		{
 			user:      org1.Admin,
@@ -195,6 +205,9 @@ func TestIntegrationTimeIntervalAccessControl(t *testing.T) {
 			canRead:   true,
 			canDelete: true,
 		},
+		{
+			user: legacyWriter,
+		},
 	}
 
 	admin := org1.Admin
@@ -214,7 +227,7 @@ func TestIntegrationTimeIntervalAccessControl(t *testing.T) {
 				},
 				Spec: v0alpha1.TimeIntervalSpec{
 					Name:          fmt.Sprintf("time-interval-1-%s", tc.user.Identity.GetLogin()),
-					TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+					TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 				},
 			}
 			expected.SetProvenanceStatus("")
@@ -281,7 +294,7 @@ func TestIntegrationTimeIntervalAccessControl(t *testing.T) {
 			}
 
 			updatedExpected := expected.DeepCopy()
-			updatedExpected.Spec.TimeIntervals = v0alpha1.IntervalGenerator{}.GenerateMany(2)
+			updatedExpected.Spec.TimeIntervals = fake.IntervalGenerator{}.GenerateMany(2)
 
 			d, err = json.Marshal(updatedExpected)
 			require.NoError(t, err)
@@ -376,7 +389,7 @@ func TestIntegrationTimeIntervalProvisioning(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "time-interval-1",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}, v1.CreateOptions{})
 	require.NoError(t, err)
@@ -395,7 +408,7 @@ func TestIntegrationTimeIntervalProvisioning(t *testing.T) {
 	})
 	t.Run("should not let update if provisioned", func(t *testing.T) {
 		updated := created.DeepCopy()
-		updated.Spec.TimeIntervals = v0alpha1.IntervalGenerator{}.GenerateMany(2)
+		updated.Spec.TimeIntervals = fake.IntervalGenerator{}.GenerateMany(2)
 
 		_, err := adminClient.Update(ctx, updated, v1.UpdateOptions{})
 		require.Truef(t, errors.IsForbidden(err), "should get Forbidden error but got %s", err)
@@ -425,7 +438,7 @@ func TestIntegrationTimeIntervalOptimisticConcurrency(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "time-interval",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}
 
@@ -442,7 +455,7 @@ func TestIntegrationTimeIntervalOptimisticConcurrency(t *testing.T) {
 	})
 	t.Run("should update if version matches", func(t *testing.T) {
 		updated := created.DeepCopy()
-		updated.Spec.TimeIntervals = v0alpha1.IntervalGenerator{}.GenerateMany(2)
+		updated.Spec.TimeIntervals = fake.IntervalGenerator{}.GenerateMany(2)
 		actualUpdated, err := adminClient.Update(ctx, updated, v1.UpdateOptions{})
 		require.NoError(t, err)
 		require.EqualValues(t, updated.Spec, actualUpdated.Spec)
@@ -451,7 +464,7 @@ func TestIntegrationTimeIntervalOptimisticConcurrency(t *testing.T) {
 	t.Run("should update if version is empty", func(t *testing.T) {
 		updated := created.DeepCopy()
 		updated.ResourceVersion = ""
-		updated.Spec.TimeIntervals = v0alpha1.IntervalGenerator{}.GenerateMany(2)
+		updated.Spec.TimeIntervals = fake.IntervalGenerator{}.GenerateMany(2)
 
 		actualUpdated, err := adminClient.Update(ctx, updated, v1.UpdateOptions{})
 		require.NoError(t, err)
@@ -511,7 +524,7 @@ func TestIntegrationTimeIntervalPatch(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "time-interval",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}
 
@@ -534,7 +547,7 @@ func TestIntegrationTimeIntervalPatch(t *testing.T) {
 	})
 
 	t.Run("should patch with json patch", func(t *testing.T) {
-		expected := v0alpha1.IntervalGenerator{}.Generate()
+		expected := fake.IntervalGenerator{}.Generate()
 
 		patch := []map[string]interface{}{
 			{
@@ -576,7 +589,7 @@ func TestIntegrationTimeIntervalListSelector(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "test1",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}
 	interval1, err = adminClient.Create(ctx, interval1, v1.CreateOptions{})
@@ -588,7 +601,7 @@ func TestIntegrationTimeIntervalListSelector(t *testing.T) {
 		},
 		Spec: v0alpha1.TimeIntervalSpec{
 			Name:          "test2",
-			TimeIntervals: v0alpha1.IntervalGenerator{}.GenerateMany(2),
+			TimeIntervals: fake.IntervalGenerator{}.GenerateMany(2),
 		},
 	}
 	interval2, err = adminClient.Create(ctx, interval2, v1.CreateOptions{})
@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/server"
@@ -546,6 +547,9 @@ func (c *K8sTestHelper) AddOrUpdateTeamMember(user User, teamID int64, permissio
 		supportbundlestest.NewFakeBundleService())
 	require.NoError(c.t, err)
 
+	defaultPolicy, err := resourcepermissions.ProvideDefaultPermissionsPolicy(c.env.Cfg)
+	require.NoError(c.t, err)
+
 	teampermissionSvc, err := ossaccesscontrol.ProvideTeamPermissions(
 		c.env.Cfg,
 		c.env.FeatureToggles,
@@ -557,6 +561,8 @@ func (c *K8sTestHelper) AddOrUpdateTeamMember(user User, teamID int64, permissio
 		teamSvc,
 		userSvc,
 		resourcepermissions.NewActionSetService(c.env.FeatureToggles),
+		defaultPolicy,
+		kvstore.ProvideService(c.env.SQLStore),
 	)
 	require.NoError(c.t, err)
 